@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"github.com/vavelour/chat/configs"
 	"github.com/vavelour/chat/internal/domain/entities"
@@ -10,12 +11,18 @@ import (
 	"github.com/vavelour/chat/internal/repository/inmemorydb/repos"
 	"github.com/vavelour/chat/internal/repository/postgres"
 	repossql "github.com/vavelour/chat/internal/repository/postgres/repos"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+	repossqlite "github.com/vavelour/chat/internal/repository/sqlite/repos"
+	"github.com/vavelour/chat/pkg/blobstore"
 	postgresdb "github.com/vavelour/chat/pkg/database_utils/postgres"
+	sqlitedb "github.com/vavelour/chat/pkg/database_utils/sqlite"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 
@@ -39,13 +46,113 @@ type AuthRepository interface {
 
 type PublicRepository interface {
 	InsertMessage(m entities.Message) error
-	GetMessages(limit, offset int) ([]entities.Message, error)
+	GetMessages(limit, offset int, order entities.Order) ([]entities.Message, error)
+	GetAttachment(id string) (entities.Attachment, entities.Message, error)
+	SearchMessages(q entities.SearchQuery) ([]entities.SearchResult, error)
 }
 
 type PrivateRepository interface {
 	InsertMessage(m entities.Message) error
-	GetMessages(sender, recipient string, limit, offset int) ([]entities.Message, error)
+	GetMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error)
 	GetUsers(user string) ([]string, error)
+	MarkAsRead(reader, partner string, messageID int) error
+	GetConversations(user string, partners []string) ([]entities.Conversation, error)
+	GetInbox(user string, limit, offset int) ([]entities.Conversation, error)
+	GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error)
+	AcceptMessageRequest(user, partner string) error
+	DeclineMessageRequest(user, partner string) error
+	GetAttachment(id string) (entities.Attachment, entities.Message, error)
+	SearchMessages(user string, q entities.SearchQuery) ([]entities.SearchResult, error)
+	PurgeExpiredMessages(now time.Time, limit int) (int, []string, error)
+}
+
+type PrivacyRepository interface {
+	GetPrivacySettings(user string) (entities.PrivacySettings, error)
+	UpdatePrivacySettings(user string, settings entities.PrivacySettings) error
+	BlockUser(user, blocked string) error
+	UnblockUser(user, blocked string) error
+	GetBlockedUsers(user string) ([]entities.BlockedUser, error)
+	IsBlocked(user, other string) (bool, error)
+	IsContact(user, other string) (bool, error)
+}
+
+type ModerationRepository interface {
+	InsertReport(report entities.Report) (entities.Report, error)
+	GetReports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error)
+	CloseReport(id int, status entities.ReportStatus, entry entities.AuditEntry) (entities.Report, error)
+	DeletePublicMessage(id int, entry entities.AuditEntry) ([]string, error)
+	SetSanction(s entities.Sanction, entry entities.AuditEntry) error
+	LiftSanction(username string, kind entities.SanctionKind, entry entities.AuditEntry) error
+	GetActiveSanctions(username string, now time.Time) ([]entities.Sanction, error)
+	GetSanctions(now time.Time) ([]entities.Sanction, error)
+	GetAuditLog(limit, offset int) ([]entities.AuditEntry, error)
+}
+
+type PresenceRepository interface {
+	UpdateLastSeen(username string, lastSeen time.Time) error
+	GetLastSeen(usernames []string) (map[string]time.Time, error)
+}
+
+type AvatarRepository interface {
+	SetAvatar(avatar entities.Avatar) (string, error)
+	GetAvatar(username string) (entities.Avatar, error)
+}
+
+type NotificationRepository interface {
+	GetNotifications(user string, unreadOnly bool, limit, offset int) ([]entities.Notification, error)
+	CountUnread(user string) (int, error)
+	MarkNotificationsRead(user string, ids []int) (int, error)
+}
+
+type WebhookRepository interface {
+	InsertWebhook(w entities.Webhook) (entities.Webhook, error)
+	GetWebhooks() ([]entities.Webhook, error)
+	GetWebhook(id int) (entities.Webhook, error)
+	DeleteWebhook(id int) error
+	SetWebhookActive(id int, active bool) error
+	GetDeliveries(webhookID, limit, offset int) ([]entities.WebhookDelivery, error)
+	ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]entities.WebhookDelivery, error)
+	RecordAttempt(d entities.WebhookDelivery, disableAfter int) error
+}
+
+type IncomingWebhookRepository interface {
+	InsertIncomingWebhook(w entities.IncomingWebhook, tokenHash, botPassword string) (entities.IncomingWebhook, error)
+	GetIncomingWebhooks() ([]entities.IncomingWebhook, error)
+	GetIncomingWebhookByToken(tokenHash string) (entities.IncomingWebhook, error)
+	DeleteIncomingWebhook(id int) error
+	TouchIncomingWebhook(id int, usedAt time.Time) error
+}
+
+type BotCommandRepository interface {
+	InsertBotCommand(c entities.BotCommand, botPassword string) (entities.BotCommand, error)
+	GetBotCommands() ([]entities.BotCommand, error)
+	GetBotCommand(command string) (entities.BotCommand, error)
+	DeleteBotCommand(id int) error
+}
+
+type ScheduledMessageRepository interface {
+	InsertScheduledMessage(m entities.ScheduledMessage) (entities.ScheduledMessage, error)
+	GetScheduledMessages(sender string, limit, offset int) ([]entities.ScheduledMessage, error)
+	DeleteScheduledMessage(sender string, id int) error
+	ClaimScheduledMessages(now time.Time, limit int) ([]entities.ScheduledMessage, error)
+	FinishScheduledMessage(id int, status entities.ScheduledStatus, lastError string) error
+}
+
+type ReminderRepository interface {
+	InsertReminder(rem entities.Reminder, senderPassword string) (entities.Reminder, error)
+	GetReminders() ([]entities.Reminder, error)
+	GetReminder(id int) (entities.Reminder, error)
+	PauseReminder(id int) (entities.Reminder, error)
+	ResumeReminder(id int, nextRunAt time.Time) (entities.Reminder, error)
+	DeleteReminder(id int) error
+	GetDueReminders(now time.Time, limit int) ([]entities.Reminder, error)
+	AdvanceReminder(id int, due, next time.Time, posted bool) (bool, error)
+}
+
+// Migrator is a database with a schema of its own to keep up to date.
+type Migrator interface {
+	PendingMigrations() ([]string, error)
+	Migrate() ([]string, error)
 }
 
 type AuthService interface {
@@ -68,6 +175,10 @@ type IdentityService interface {
 // @in							header
 // @name						Authorization
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "apply the migrations of the database and exit")
+	migrateDryRun := flag.Bool("migrate-dry-run", false, "list the migrations that would be applied and exit")
+	flag.Parse()
+
 	cfg, err := configs.InitConfig()
 	if err != nil {
 		log.Println(err)
@@ -78,6 +189,26 @@ func main() {
 		authRepo     AuthRepository
 		publicRepo   PublicRepository
 		privateRepo  PrivateRepository
+		privacyRepo  PrivacyRepository
+		modRepo      ModerationRepository
+		presenceRepo PresenceRepository
+		avatarRepo   AvatarRepository
+		notifyRepo   NotificationRepository
+		webhookRepo  WebhookRepository
+		incomingRepo IncomingWebhookRepository
+		botRepo      BotCommandRepository
+		schedRepo    ScheduledMessageRepository
+		reminderRepo ReminderRepository
+		retainRepo   service.RetentionRepository
+		transferRepo service.TransferRepository
+		exportRepo   service.ExportRepository
+		blobStore    blobstore.BlobStore
+		memDB        *inmemorydb.MemoryDB
+		liteDB       *sqlite.SqliteDB
+		migrator     Migrator
+		healthRepo   service.HealthRepository
+		pgDB         *postgres.SqlPostgresDB
+		units        service.UnitOfWork
 		authService  AuthService
 		userIdentity IdentityService
 		logInMW      func(next http.Handler) http.Handler
@@ -85,10 +216,40 @@ func main() {
 
 	switch cfg.DB.Type {
 	case "in_memory_db":
-		db := inmemorydb.NewDB()
+		db, err := inmemorydb.Open(inmemorydb.PersistenceOptions{
+			Dir:              cfg.DB.DataDir,
+			Fsync:            inmemorydb.FsyncPolicy(cfg.DB.Fsync),
+			FsyncInterval:    cfg.DB.FsyncInterval,
+			SnapshotInterval: cfg.DB.SnapshotInterval})
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		memDB = db
 		authRepo = repos.NewAuthRepos(db)
 		publicRepo = repos.NewPublicRepos(db)
 		privateRepo = repos.NewPrivateRepos(db)
+		privacyRepo = repos.NewPrivacyRepos(db)
+		modRepo = repos.NewModerationRepos(db)
+		presenceRepo = repos.NewPresenceRepos(db)
+		avatarRepo = repos.NewAvatarRepos(db)
+		notifyRepo = repos.NewNotificationRepos(db)
+		webhookRepo = repos.NewWebhookRepos(db)
+		incomingRepo = repos.NewIncomingWebhookRepos(db)
+		botRepo = repos.NewBotCommandRepos(db)
+		schedRepo = repos.NewScheduledMessageRepos(db)
+		reminderRepo = repos.NewReminderRepos(db)
+		retainRepo = repos.NewRetentionRepos(db)
+		transferRepo = repos.NewTransferRepos(db)
+		exportRepo = repos.NewExportRepos(db)
+		units = service.UnitOfWorkFunc(func(fn func(r service.Repositories) error) error {
+			return db.Unit(func(tx *inmemorydb.MemoryDB) error {
+				return fn(service.Repositories{
+					Auth:    repos.NewAuthRepos(tx),
+					Privacy: repos.NewPrivacyRepos(tx),
+					Private: repos.NewPrivateRepos(tx)})
+			})
+		})
 	case "postgres":
 		db, err := postgres.NewSqlPostgresDB(postgresdb.SqlPostgresConfig{
 			Host:     cfg.DB.Host,
@@ -96,28 +257,158 @@ func main() {
 			User:     cfg.DB.User,
 			DBName:   cfg.DB.DBName,
 			Password: cfg.DB.Password,
-			SSLMode:  cfg.DB.SSLMode})
+			SSLMode:  cfg.DB.SSLMode,
+
+			MaxOpenConns:     cfg.DB.MaxOpenConns,
+			MaxIdleConns:     cfg.DB.MaxIdleConns,
+			ConnMaxLifetime:  cfg.DB.ConnMaxLifetime,
+			ConnMaxIdleTime:  cfg.DB.ConnMaxIdleTime,
+			StatementTimeout: cfg.DB.StatementTimeout,
+			ConnectAttempts:  cfg.DB.ConnectAttempts,
+			ConnectBackoff:   cfg.DB.ConnectBackoff})
 		if err != nil {
 			log.Println(err)
 			return
 		}
+		pgDB = db
+		migrator = db
+		healthRepo = db
 		authRepo = repossql.NewAuthSqlRepos(db)
 		publicRepo = repossql.NewPublicSqlRepos(db)
 		privateRepo = repossql.NewPrivateSqlRepos(db)
+		privacyRepo = repossql.NewPrivacySqlRepos(db)
+		modRepo = repossql.NewModerationSqlRepos(db)
+		presenceRepo = repossql.NewPresenceSqlRepos(db)
+		avatarRepo = repossql.NewAvatarSqlRepos(db)
+		notifyRepo = repossql.NewNotificationSqlRepos(db)
+		webhookRepo = repossql.NewWebhookSqlRepos(db)
+		incomingRepo = repossql.NewIncomingWebhookSqlRepos(db)
+		botRepo = repossql.NewBotCommandSqlRepos(db)
+		schedRepo = repossql.NewScheduledMessageSqlRepos(db)
+		reminderRepo = repossql.NewReminderSqlRepos(db)
+		retainRepo = repossql.NewRetentionSqlRepos(db)
+		transferRepo = repossql.NewTransferSqlRepos(db)
+		exportRepo = repossql.NewExportSqlRepos(db)
+		units = service.UnitOfWorkFunc(func(fn func(r service.Repositories) error) error {
+			return db.Tx(func(tx *postgres.Tx) error {
+				return fn(service.Repositories{
+					Auth:    repossql.NewAuthSqlRepos(tx),
+					Privacy: repossql.NewPrivacySqlRepos(tx),
+					Private: repossql.NewPrivateSqlRepos(tx)})
+			})
+		})
+	case "sqlite":
+		db, err := sqlite.NewSqliteDB(sqlitedb.SqliteConfig{Path: cfg.DB.Path})
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		liteDB = db
+		migrator = db
+		healthRepo = db
+		authRepo = repossqlite.NewAuthSqliteRepos(db)
+		publicRepo = repossqlite.NewPublicSqliteRepos(db)
+		privateRepo = repossqlite.NewPrivateSqliteRepos(db)
+		privacyRepo = repossqlite.NewPrivacySqliteRepos(db)
+		modRepo = repossqlite.NewModerationSqliteRepos(db)
+		presenceRepo = repossqlite.NewPresenceSqliteRepos(db)
+		avatarRepo = repossqlite.NewAvatarSqliteRepos(db)
+		notifyRepo = repossqlite.NewNotificationSqliteRepos(db)
+		webhookRepo = repossqlite.NewWebhookSqliteRepos(db)
+		incomingRepo = repossqlite.NewIncomingWebhookSqliteRepos(db)
+		botRepo = repossqlite.NewBotCommandSqliteRepos(db)
+		schedRepo = repossqlite.NewScheduledMessageSqliteRepos(db)
+		reminderRepo = repossqlite.NewReminderSqliteRepos(db)
+		retainRepo = repossqlite.NewRetentionSqliteRepos(db)
+		transferRepo = repossqlite.NewTransferSqliteRepos(db)
+		exportRepo = repossqlite.NewExportSqliteRepos(db)
+		units = service.UnitOfWorkFunc(func(fn func(r service.Repositories) error) error {
+			return db.Tx(func(tx *sqlite.Tx) error {
+				return fn(service.Repositories{
+					Auth:    repossqlite.NewAuthSqliteRepos(tx),
+					Privacy: repossqlite.NewPrivacySqliteRepos(tx),
+					Private: repossqlite.NewPrivateSqliteRepos(tx)})
+			})
+		})
+	default:
+		log.Println("в конфиге написана хуйня")
+		return
+	}
+
+	if *migrateDryRun {
+		if migrator == nil {
+			log.Println("no migrations for", cfg.DB.Type)
+			return
+		}
+
+		pending, err := migrator.PendingMigrations()
+		if err != nil {
+			log.Println(err)
+			return
+		}
+
+		log.Printf("%d pending migrations", len(pending))
+		for _, name := range pending {
+			log.Println("pending:", name)
+		}
+
+		return
+	}
+
+	if migrator != nil {
+		applied, err := migrator.Migrate()
+		if err != nil {
+			log.Println(err)
+			return
+		}
+
+		for _, name := range applied {
+			log.Println("migrated:", name)
+		}
+	}
+
+	if *migrateOnly {
+		return
+	}
+
+	switch cfg.Attachments.Storage {
+	case "local":
+		blobStore, err = blobstore.NewLocalStore(cfg.Attachments.LocalDir)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+	case "s3":
+		blobStore = blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:  cfg.Attachments.S3.Endpoint,
+			Region:    cfg.Attachments.S3.Region,
+			Bucket:    cfg.Attachments.S3.Bucket,
+			AccessKey: cfg.Attachments.S3.AccessKey,
+			SecretKey: cfg.Attachments.S3.SecretKey}, nil)
 	default:
 		log.Println("в конфиге написана хуйня")
 		return
 	}
 
+	avatarStore, err := blobstore.NewLocalStore(cfg.Avatars.Dir)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
 	validate := validator.New()
 
+	moderationService := service.NewModerationService(modRepo, blobStore)
+	moderationHandler := handler.NewModerationHandler(moderationService, validate)
+	moderatorGuard := middlewares.NewModeratorGuard(cfg.Auth.Admins, cfg.Auth.Moderators)
+
 	switch cfg.Auth.Type {
 	case "basic_auth":
-		authService = service.NewAuthService(authRepo)
+		authService = service.NewAuthService(authRepo, units, moderationService)
 		userIdentity = middlewares.NewBasicUserIdentity(authService, validate)
 		logInMW = userIdentity.Identify
 	case "bearer_jwt":
-		authService = service.NewJWTService(authRepo)
+		authService = service.NewJWTService(authRepo, units, moderationService)
 		userIdentity = middlewares.NewJWTUserIdentity(authService, validate)
 		logInMW = userIdentity.Identify
 	default:
@@ -127,17 +418,130 @@ func main() {
 
 	authHandler := handler.NewAuthHandler(authService, validate)
 
-	publicService := service.NewPublicService(publicRepo)
-	publicHandler := handler.NewPublicHandler(publicService, validate)
+	presenceService := service.NewPresenceService(presenceRepo, cfg.Presence.AwayTimeout, cfg.Presence.TypingTTL)
+	presenceHandler := handler.NewPresenceHandler(presenceService, validate, cfg.Presence.HeartbeatInterval)
+	presenceMW := middlewares.NewPresenceTracker(presenceService).Track
+
+	commandRouter := service.NewCommandRouter(botRepo, presenceService, &http.Client{Timeout: cfg.Commands.Timeout})
+	botService := service.NewBotService(botRepo, commandRouter)
+	botHandler := handler.NewBotHandler(botService, validate)
+
+	privacyService := service.NewPrivacyService(privacyRepo)
+	privacyHandler := handler.NewPrivacyHandler(privacyService, validate)
+
+	publicService := service.NewPublicService(publicRepo, authRepo, privacyService, moderationService, commandRouter)
+	privateService := service.NewPrivateService(privateRepo, privacyService, commandRouter)
+
+	scheduledService := service.NewScheduledService(schedRepo, authRepo, publicService, privateService, service.ScheduledOptions{
+		PollInterval: cfg.Scheduler.PollInterval,
+		BatchSize:    cfg.Scheduler.BatchSize,
+		MaxDelay:     cfg.Scheduler.MaxDelay})
+	scheduledHandler := handler.NewScheduledHandler(scheduledService, validate)
+
+	publicHandler := handler.NewPublicHandler(publicService, scheduledService, validate)
+	privateHandler := handler.NewPrivateHAndler(privateService, scheduledService, validate)
+
+	attachmentService := service.NewAttachmentService(publicRepo, privateRepo, publicService, privateService, blobStore, cfg.Attachments.MaxSize, cfg.Attachments.AllowedTypes)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, validate)
 
-	privateService := service.NewPrivateService(privateRepo)
-	privateHandler := handler.NewPrivateHAndler(privateService, validate)
+	avatarService := service.NewAvatarService(avatarRepo, avatarStore, service.AvatarOptions{
+		MaxSize:     cfg.Avatars.MaxSize,
+		MaxSide:     cfg.Avatars.MaxSide,
+		Sizes:       cfg.Avatars.Sizes,
+		DefaultSize: cfg.Avatars.DefaultSize,
+		Workers:     cfg.Avatars.Workers,
+		QueueSize:   cfg.Avatars.QueueSize})
+	avatarHandler := handler.NewAvatarHandler(avatarService, int(cfg.Avatars.CacheMaxAge.Seconds()))
+
+	searchService := service.NewSearchService(publicRepo, privateRepo)
+	searchHandler := handler.NewSearchHandler(searchService, validate)
+
+	notificationService := service.NewNotificationService(notifyRepo)
+	notificationHandler := handler.NewNotificationHandler(notificationService, validate)
+
+	webhookService := service.NewWebhookService(webhookRepo, nil, service.WebhookOptions{
+		PollInterval: cfg.Webhooks.PollInterval,
+		Timeout:      cfg.Webhooks.Timeout,
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		BackoffBase:  cfg.Webhooks.BackoffBase,
+		BackoffMax:   cfg.Webhooks.BackoffMax,
+		DisableAfter: cfg.Webhooks.DisableAfter,
+		BatchSize:    cfg.Webhooks.BatchSize,
+		Lease:        cfg.Webhooks.Lease})
+	webhookHandler := handler.NewWebhookHandler(webhookService, validate)
+	adminGuard := middlewares.NewAdminGuard(cfg.Auth.Admins)
+
+	incomingService := service.NewIncomingWebhookService(incomingRepo, publicService)
+	incomingHandler := handler.NewIncomingWebhookHandler(incomingService, validate)
+
+	reminderService := service.NewReminderService(reminderRepo, publicService, service.ReminderOptions{
+		Sender:       cfg.Reminders.Sender,
+		PollInterval: cfg.Reminders.PollInterval,
+		BatchSize:    cfg.Reminders.BatchSize,
+		CatchUp:      cfg.Reminders.CatchUp})
+	reminderHandler := handler.NewReminderHandler(reminderService, validate)
+
+	reaperService := service.NewReaperService(privateRepo, blobStore, service.ReaperOptions{
+		PollInterval: cfg.Reaper.PollInterval,
+		BatchSize:    cfg.Reaper.BatchSize})
+
+	retentionService := service.NewRetentionService(retainRepo, blobStore, service.RetentionOptions{
+		PublicMaxAge:  time.Duration(cfg.Retention.PublicDays) * 24 * time.Hour,
+		PrivateMaxAge: time.Duration(cfg.Retention.PrivateDays) * 24 * time.Hour,
+		PollInterval:  cfg.Retention.PollInterval,
+		BatchSize:     cfg.Retention.BatchSize,
+		ArchiveDir:    cfg.Retention.ArchiveDir})
+	retentionHandler := handler.NewRetentionHandler(retentionService)
+
+	transferService := service.NewTransferService(transferRepo, service.TransferOptions{
+		BatchSize:  cfg.Exports.BatchSize,
+		MailDomain: cfg.Exports.MailDomain})
+	exportService := service.NewExportService(exportRepo, transferService, blobStore, service.ExportOptions{
+		Workers:   cfg.Exports.Workers,
+		QueueSize: cfg.Exports.QueueSize,
+		TTL:       cfg.Exports.TTL})
+	exportHandler := handler.NewExportHandler(exportService)
+
+	healthService := service.NewHealthService(healthRepo)
+	healthHandler := handler.NewHealthHandler(healthService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stopWorkers := runWorkers(ctx, presenceService.Run, avatarService.Run, webhookService.Run, exportService.Run)
+	go scheduledService.Run(ctx)
+	go reminderService.Run(ctx)
+	go reaperService.Run(ctx)
+	go retentionService.Run(ctx)
+	if memDB != nil {
+		go memDB.Run(ctx)
+	}
 
 	mainRouter := chi.NewRouter()
 
 	authHandler.AuthRoutes(mainRouter, middlewares.MyLogger, middlewares.MyRecoverer)
-	publicHandler.PublicRoutes(mainRouter, logInMW, middlewares.MyLogger, middlewares.MyRecoverer)
-	privateHandler.PrivateRoutes(mainRouter, logInMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	publicHandler.PublicRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	privateHandler.PrivateRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	privacyHandler.PrivacyRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	moderationHandler.ReportRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	scheduledHandler.ScheduledRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	presenceHandler.PresenceRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	attachmentHandler.AttachmentRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	avatarHandler.AvatarRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	exportHandler.ExportRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	searchHandler.SearchRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	notificationHandler.NotificationRoutes(mainRouter, logInMW, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	webhookHandler.WebhookRoutes(mainRouter, logInMW, adminGuard.Require, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	botHandler.BotRoutes(mainRouter, logInMW, adminGuard.Require, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	incomingHandler.IncomingWebhookRoutes(mainRouter, logInMW, adminGuard.Require, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	reminderHandler.ReminderRoutes(mainRouter, logInMW, adminGuard.Require, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	moderationHandler.ModerationRoutes(mainRouter, logInMW, moderatorGuard.Require, presenceMW, middlewares.MyLogger, middlewares.MyRecoverer)
+	healthHandler.PoolStatsRoutes(mainRouter, logInMW, adminGuard.Require, middlewares.MyLogger, middlewares.MyRecoverer)
+	retentionHandler.RetentionRoutes(mainRouter, logInMW, adminGuard.Require, middlewares.MyLogger, middlewares.MyRecoverer)
+	// No request logger here: the probes would flood the log.
+	healthHandler.HealthRoutes(mainRouter, middlewares.MyRecoverer)
+	// No request logger here: the URL holds the webhook token.
+	incomingHandler.HookRoutes(mainRouter, middlewares.MyRecoverer)
 	mainRouter.Get("/v1/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:8080/swagger/doc.json"),
 	))
@@ -149,6 +553,24 @@ func main() {
 		WriteTimeout:   cfg.Server.WriteTimeout,
 		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
 	}, mainRouter)
+	// The workers write to the database and the blob store, so they stop
+	// before the database closes.
+	srv.RegisterOnShutdown(stopWorkers)
+	srv.RegisterOnShutdown(scheduledService.Shutdown)
+	srv.RegisterOnShutdown(reminderService.Shutdown)
+	srv.RegisterOnShutdown(reaperService.Shutdown)
+	srv.RegisterOnShutdown(retentionService.Shutdown)
+	if memDB != nil {
+		// Last, so the writes of the services above reach the log.
+		srv.RegisterOnShutdown(memDB.Shutdown)
+	}
+	if liteDB != nil {
+		// Last as well, for the same reason.
+		srv.RegisterOnShutdown(liteDB.Shutdown)
+	}
+	if pgDB != nil {
+		srv.RegisterOnShutdown(pgDB.Shutdown)
+	}
 	go func() {
 		err := srv.Run()
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -166,5 +588,39 @@ func main() {
 		fmt.Printf("server stop: %s", err)
 	}
 
+	cancel()
+
 	log.Println("Server stop.")
 }
+
+// runWorkers runs every worker in its own goroutine until ctx is done, and
+// returns a shutdown hook which stops them and waits until they return.
+func runWorkers(ctx context.Context, workers ...func(ctx context.Context)) func(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	for _, run := range workers {
+		wg.Add(1)
+		go func(run func(ctx context.Context)) {
+			defer wg.Done()
+			run(ctx)
+		}(run)
+	}
+
+	return func(shutdownCtx context.Context) error {
+		cancel()
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	}
+}
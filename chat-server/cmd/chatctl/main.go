@@ -0,0 +1,237 @@
+// Command chatctl moves the data of the chat between the databases:
+//
+//	chatctl [-db type] export [-o file] [-format jsonl|mbox] [-user name]
+//	chatctl [-db type] import [-i file]
+//
+// The database is the one of configs/config.yaml, unless -db names another
+// type. An export in JSON lines holds the users, the public messages and the
+// private conversations and is read back by import; an mbox export holds the
+// private conversations of a single user. Stop the server before an export
+// of the in-memory database, whose data is read from its data dir.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/vavelour/chat/configs"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/repos"
+	"github.com/vavelour/chat/internal/repository/postgres"
+	repossql "github.com/vavelour/chat/internal/repository/postgres/repos"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+	repossqlite "github.com/vavelour/chat/internal/repository/sqlite/repos"
+	"github.com/vavelour/chat/internal/service"
+	postgresdb "github.com/vavelour/chat/pkg/database_utils/postgres"
+	sqlitedb "github.com/vavelour/chat/pkg/database_utils/sqlite"
+)
+
+// database is the transfer repository of the database and what closes it.
+type database struct {
+	repo  service.TransferRepository
+	close func(ctx context.Context) error
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("chatctl: ")
+
+	dbType := flag.String("db", "", "the type of the database: in_memory_db, postgres or sqlite; the one of the config by default")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var run func(s *service.TransferService, args []string) error
+	switch flag.Arg(0) {
+	case "export":
+		run = export
+	case "import":
+		run = importData
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := configs.InitConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dbType != "" {
+		cfg.DB.Type = *dbType
+	}
+
+	db, err := open(cfg.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := service.NewTransferService(db.repo, service.TransferOptions{
+		BatchSize:  cfg.Exports.BatchSize,
+		MailDomain: cfg.Exports.MailDomain})
+
+	err = run(s, flag.Args()[1:])
+	if closeErr := db.close(context.Background()); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage:
+  chatctl [-db type] export [-o file] [-format jsonl|mbox] [-user name]
+  chatctl [-db type] import [-i file]
+
+`)
+	flag.PrintDefaults()
+}
+
+func export(s *service.TransferService, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("o", "-", "the file to write the export to, - for the standard output")
+	format := fs.String("format", "jsonl", "the format of the export: jsonl or mbox")
+	username := fs.String("user", "", "export the data of this user only; required for mbox")
+	_ = fs.Parse(args)
+
+	var write func(w io.Writer) error
+	switch {
+	case *format == "mbox" && *username != "":
+		write = func(w io.Writer) error { return s.ExportMbox(w, *username) }
+	case *format == "mbox":
+		return errors.New("an mbox export needs -user")
+	case *format == "jsonl" && *username != "":
+		write = func(w io.Writer) error { return s.ExportUser(w, *username) }
+	case *format == "jsonl":
+		write = func(w io.Writer) error {
+			summary, err := s.Export(w)
+			if err == nil {
+				log.Printf("exported %d users, %d public messages and %d private messages",
+					summary.Users, summary.PublicMessages, summary.PrivateMessages)
+			}
+
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	out := os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	w := bufio.NewWriter(out)
+	if err := write(w); err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+func importData(s *service.TransferService, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	input := fs.String("i", "-", "the file to read the export from, - for the standard input")
+	_ = fs.Parse(args)
+
+	in := os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	summary, err := s.Import(bufio.NewReader(in))
+	log.Printf("imported %d users, %d public messages and %d private messages",
+		summary.Users, summary.PublicMessages, summary.PrivateMessages)
+
+	return err
+}
+
+// open opens the database of the config, bringing its schema up to date.
+func open(cfg configs.DBConfig) (database, error) {
+	switch cfg.Type {
+	case "in_memory_db":
+		if cfg.DataDir == "" {
+			return database{}, errors.New("the in-memory database keeps no data without db.data_dir")
+		}
+
+		db, err := inmemorydb.Open(inmemorydb.PersistenceOptions{
+			Dir:              cfg.DataDir,
+			Fsync:            inmemorydb.FsyncPolicy(cfg.Fsync),
+			FsyncInterval:    cfg.FsyncInterval,
+			SnapshotInterval: cfg.SnapshotInterval})
+		if err != nil {
+			return database{}, err
+		}
+		go db.Run(context.Background())
+
+		return database{repo: repos.NewTransferRepos(db), close: db.Shutdown}, nil
+	case "postgres":
+		db, err := postgres.NewSqlPostgresDB(postgresdb.SqlPostgresConfig{
+			Host:     cfg.Host,
+			Port:     cfg.Port,
+			User:     cfg.User,
+			DBName:   cfg.DBName,
+			Password: cfg.Password,
+			SSLMode:  cfg.SSLMode,
+
+			MaxOpenConns:     cfg.MaxOpenConns,
+			MaxIdleConns:     cfg.MaxIdleConns,
+			ConnMaxLifetime:  cfg.ConnMaxLifetime,
+			ConnMaxIdleTime:  cfg.ConnMaxIdleTime,
+			StatementTimeout: cfg.StatementTimeout,
+			ConnectAttempts:  cfg.ConnectAttempts,
+			ConnectBackoff:   cfg.ConnectBackoff})
+		if err != nil {
+			return database{}, err
+		}
+
+		if _, err := db.Migrate(); err != nil {
+			_ = db.Shutdown(context.Background())
+			return database{}, err
+		}
+
+		return database{repo: repossql.NewTransferSqlRepos(db), close: db.Shutdown}, nil
+	case "sqlite":
+		db, err := sqlite.NewSqliteDB(sqlitedb.SqliteConfig{Path: cfg.Path})
+		if err != nil {
+			return database{}, err
+		}
+
+		if _, err := db.Migrate(); err != nil {
+			_ = db.Shutdown(context.Background())
+			return database{}, err
+		}
+
+		return database{repo: repossqlite.NewTransferSqliteRepos(db), close: db.Shutdown}, nil
+	default:
+		return database{}, fmt.Errorf("unknown database type %q", cfg.Type)
+	}
+}
@@ -10,6 +10,23 @@ type DBConfig struct {
 	DBName   string
 	Password string
 	SSLMode  string
+	// The pool of connections to Postgres and how long to wait for the
+	// database at startup.
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StatementTimeout time.Duration
+	ConnectAttempts  int
+	ConnectBackoff   time.Duration
+	// DataDir, Fsync, FsyncInterval and SnapshotInterval make the in-memory
+	// database durable; an empty DataDir keeps it in memory only.
+	DataDir          string
+	Fsync            string
+	FsyncInterval    time.Duration
+	SnapshotInterval time.Duration
+	// Path is the file of the SQLite database.
+	Path string
 }
 
 type ServerConfig struct {
@@ -20,5 +37,95 @@ type ServerConfig struct {
 }
 
 type AuthConfig struct {
-	Type string
+	Type       string
+	Admins     []string
+	Moderators []string
+}
+
+type PresenceConfig struct {
+	AwayTimeout       time.Duration
+	TypingTTL         time.Duration
+	HeartbeatInterval time.Duration
+}
+
+type AttachmentsConfig struct {
+	Storage      string
+	LocalDir     string
+	MaxSize      int64
+	AllowedTypes []string
+	S3           S3Config
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+type AvatarsConfig struct {
+	Dir         string
+	MaxSize     int64
+	MaxSide     int
+	Sizes       []int
+	DefaultSize int
+	Workers     int
+	QueueSize   int
+	CacheMaxAge time.Duration
+}
+
+type WebhooksConfig struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	DisableAfter int
+	BatchSize    int
+	Lease        time.Duration
+}
+
+type CommandsConfig struct {
+	Timeout time.Duration
+}
+
+type SchedulerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxDelay     time.Duration
+}
+
+type ReaperConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// RetentionConfig keeps the public messages for PublicDays and the private
+// ones for PrivateDays; zero days keeps them forever. An empty ArchiveDir
+// removes the messages without archiving them.
+type RetentionConfig struct {
+	PublicDays   int
+	PrivateDays  int
+	PollInterval time.Duration
+	BatchSize    int
+	ArchiveDir   string
+}
+
+// ExportsConfig sets up the exports of the users' own data: Workers prepare
+// them in the background and a ready one can be downloaded for TTL.
+// BatchSize and MailDomain are shared with chatctl export and import.
+type ExportsConfig struct {
+	Workers    int
+	QueueSize  int
+	TTL        time.Duration
+	BatchSize  int
+	MailDomain string
+}
+
+type RemindersConfig struct {
+	Sender       string
+	PollInterval time.Duration
+	BatchSize    int
+	CatchUp      time.Duration
 }
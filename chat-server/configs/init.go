@@ -3,9 +3,19 @@ package configs
 import "github.com/spf13/viper"
 
 type Config struct {
-	DB     DBConfig
-	Server ServerConfig
-	Auth   AuthConfig
+	DB          DBConfig
+	Server      ServerConfig
+	Auth        AuthConfig
+	Presence    PresenceConfig
+	Attachments AttachmentsConfig
+	Avatars     AvatarsConfig
+	Webhooks    WebhooksConfig
+	Commands    CommandsConfig
+	Scheduler   SchedulerConfig
+	Reminders   RemindersConfig
+	Reaper      ReaperConfig
+	Retention   RetentionConfig
+	Exports     ExportsConfig
 }
 
 func InitConfig() (Config, error) {
@@ -26,6 +36,20 @@ func InitConfig() (Config, error) {
 			DBName:   viper.GetString("db.db_name"),
 			Password: viper.GetString("db.password"),
 			SSLMode:  viper.GetString("db.ssl_mode"),
+
+			MaxOpenConns:     viper.GetInt("db.max_open_conns"),
+			MaxIdleConns:     viper.GetInt("db.max_idle_conns"),
+			ConnMaxLifetime:  viper.GetDuration("db.conn_max_lifetime"),
+			ConnMaxIdleTime:  viper.GetDuration("db.conn_max_idle_time"),
+			StatementTimeout: viper.GetDuration("db.statement_timeout"),
+			ConnectAttempts:  viper.GetInt("db.connect_attempts"),
+			ConnectBackoff:   viper.GetDuration("db.connect_backoff"),
+
+			DataDir:          viper.GetString("db.data_dir"),
+			Fsync:            viper.GetString("db.fsync"),
+			FsyncInterval:    viper.GetDuration("db.fsync_interval"),
+			SnapshotInterval: viper.GetDuration("db.snapshot_interval"),
+			Path:             viper.GetString("db.path"),
 		},
 		Server: ServerConfig{
 			Addr:           viper.GetString("server.addr"),
@@ -33,7 +57,83 @@ func InitConfig() (Config, error) {
 			WriteTimeout:   viper.GetDuration("server.write_timeout"),
 			MaxHeaderBytes: viper.GetInt("server.max_header_bytes"),
 		},
-		Auth: AuthConfig{Type: viper.GetString("auth.type")},
+		Auth: AuthConfig{
+			Type:       viper.GetString("auth.type"),
+			Admins:     viper.GetStringSlice("auth.admins"),
+			Moderators: viper.GetStringSlice("auth.moderators"),
+		},
+		Presence: PresenceConfig{
+			AwayTimeout:       viper.GetDuration("presence.away_timeout"),
+			TypingTTL:         viper.GetDuration("presence.typing_ttl"),
+			HeartbeatInterval: viper.GetDuration("presence.heartbeat_interval"),
+		},
+		Attachments: AttachmentsConfig{
+			Storage:      viper.GetString("attachments.storage"),
+			LocalDir:     viper.GetString("attachments.local_dir"),
+			MaxSize:      viper.GetInt64("attachments.max_size"),
+			AllowedTypes: viper.GetStringSlice("attachments.allowed_types"),
+			S3: S3Config{
+				Endpoint:  viper.GetString("attachments.s3.endpoint"),
+				Region:    viper.GetString("attachments.s3.region"),
+				Bucket:    viper.GetString("attachments.s3.bucket"),
+				AccessKey: viper.GetString("attachments.s3.access_key"),
+				SecretKey: viper.GetString("attachments.s3.secret_key"),
+			},
+		},
+		Avatars: AvatarsConfig{
+			Dir:         viper.GetString("avatars.dir"),
+			MaxSize:     viper.GetInt64("avatars.max_size"),
+			MaxSide:     viper.GetInt("avatars.max_side"),
+			Sizes:       viper.GetIntSlice("avatars.sizes"),
+			DefaultSize: viper.GetInt("avatars.default_size"),
+			Workers:     viper.GetInt("avatars.workers"),
+			QueueSize:   viper.GetInt("avatars.queue_size"),
+			CacheMaxAge: viper.GetDuration("avatars.cache_max_age"),
+		},
+		Webhooks: WebhooksConfig{
+			PollInterval: viper.GetDuration("webhooks.poll_interval"),
+			Timeout:      viper.GetDuration("webhooks.timeout"),
+			MaxAttempts:  viper.GetInt("webhooks.max_attempts"),
+			BackoffBase:  viper.GetDuration("webhooks.backoff_base"),
+			BackoffMax:   viper.GetDuration("webhooks.backoff_max"),
+			DisableAfter: viper.GetInt("webhooks.disable_after"),
+			BatchSize:    viper.GetInt("webhooks.batch_size"),
+			Lease:        viper.GetDuration("webhooks.lease"),
+		},
+		Commands: CommandsConfig{Timeout: viper.GetDuration("commands.timeout")},
+		Scheduler: SchedulerConfig{
+			PollInterval: viper.GetDuration("scheduler.poll_interval"),
+			BatchSize:    viper.GetInt("scheduler.batch_size"),
+			MaxDelay:     viper.GetDuration("scheduler.max_delay"),
+		},
+		Reminders: RemindersConfig{
+			Sender:       viper.GetString("reminders.sender"),
+			PollInterval: viper.GetDuration("reminders.poll_interval"),
+			BatchSize:    viper.GetInt("reminders.batch_size"),
+			CatchUp:      viper.GetDuration("reminders.catch_up"),
+		},
+		Reaper: ReaperConfig{
+			PollInterval: viper.GetDuration("reaper.poll_interval"),
+			BatchSize:    viper.GetInt("reaper.batch_size"),
+		},
+		Retention: RetentionConfig{
+			PublicDays:   viper.GetInt("retention.public_days"),
+			PrivateDays:  viper.GetInt("retention.private_days"),
+			PollInterval: viper.GetDuration("retention.poll_interval"),
+			BatchSize:    viper.GetInt("retention.batch_size"),
+			ArchiveDir:   viper.GetString("retention.archive_dir"),
+		},
+		Exports: ExportsConfig{
+			Workers:    viper.GetInt("exports.workers"),
+			QueueSize:  viper.GetInt("exports.queue_size"),
+			TTL:        viper.GetDuration("exports.ttl"),
+			BatchSize:  viper.GetInt("exports.batch_size"),
+			MailDomain: viper.GetString("exports.mail_domain"),
+		},
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
 	}
 
 	return cfg, nil
@@ -0,0 +1,39 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// validate rejects the settings the server cannot run with: the tickers
+// panic on an interval which is not positive, and the background jobs never
+// get through an empty batch.
+func (c Config) validate() error {
+	return errors.Join(
+		positive("presence.away_timeout", c.Presence.AwayTimeout),
+		positive("presence.typing_ttl", c.Presence.TypingTTL),
+		positive("presence.heartbeat_interval", c.Presence.HeartbeatInterval),
+		positive("webhooks.poll_interval", c.Webhooks.PollInterval),
+		positive("webhooks.batch_size", c.Webhooks.BatchSize),
+		positive("scheduler.poll_interval", c.Scheduler.PollInterval),
+		positive("scheduler.batch_size", c.Scheduler.BatchSize),
+		positive("reminders.poll_interval", c.Reminders.PollInterval),
+		positive("reminders.batch_size", c.Reminders.BatchSize),
+		positive("reaper.poll_interval", c.Reaper.PollInterval),
+		positive("reaper.batch_size", c.Reaper.BatchSize),
+		positive("retention.poll_interval", c.Retention.PollInterval),
+		positive("retention.batch_size", c.Retention.BatchSize),
+		positive("exports.workers", c.Exports.Workers),
+		positive("exports.ttl", c.Exports.TTL),
+		positive("exports.batch_size", c.Exports.BatchSize),
+	)
+}
+
+func positive[T int | time.Duration](key string, value T) error {
+	if value <= 0 {
+		return fmt.Errorf("configs: %s must be positive, got %v", key, value)
+	}
+
+	return nil
+}
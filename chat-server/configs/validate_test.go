@@ -0,0 +1,127 @@
+package configs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() Config {
+	return Config{
+		Presence:  PresenceConfig{AwayTimeout: 5 * time.Minute, TypingTTL: 5 * time.Second, HeartbeatInterval: 30 * time.Second},
+		Webhooks:  WebhooksConfig{PollInterval: 2 * time.Second, BatchSize: 50},
+		Scheduler: SchedulerConfig{PollInterval: time.Second, BatchSize: 100, MaxDelay: time.Hour},
+		Reminders: RemindersConfig{Sender: "reminders", PollInterval: 15 * time.Second, BatchSize: 50},
+		Reaper:    ReaperConfig{PollInterval: 5 * time.Second, BatchSize: 500},
+		Retention: RetentionConfig{PollInterval: time.Hour, BatchSize: 500},
+		Exports:   ExportsConfig{Workers: 1, QueueSize: 16, TTL: 24 * time.Hour, BatchSize: 500, MailDomain: "chat.local"},
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	testTable := []struct {
+		name   string
+		change func(cfg *Config)
+		key    string
+	}{
+		{
+			name:   "OK",
+			change: func(cfg *Config) {},
+		},
+		{
+			name:   "No away timeout",
+			change: func(cfg *Config) { cfg.Presence.AwayTimeout = 0 },
+			key:    "presence.away_timeout",
+		},
+		{
+			name:   "No typing TTL",
+			change: func(cfg *Config) { cfg.Presence.TypingTTL = 0 },
+			key:    "presence.typing_ttl",
+		},
+		{
+			name:   "Negative heartbeat",
+			change: func(cfg *Config) { cfg.Presence.HeartbeatInterval = -time.Second },
+			key:    "presence.heartbeat_interval",
+		},
+		{
+			name:   "No webhook poll interval",
+			change: func(cfg *Config) { cfg.Webhooks.PollInterval = 0 },
+			key:    "webhooks.poll_interval",
+		},
+		{
+			name:   "No webhook batch size",
+			change: func(cfg *Config) { cfg.Webhooks.BatchSize = 0 },
+			key:    "webhooks.batch_size",
+		},
+		{
+			name:   "No scheduler poll interval",
+			change: func(cfg *Config) { cfg.Scheduler.PollInterval = 0 },
+			key:    "scheduler.poll_interval",
+		},
+		{
+			name:   "No scheduler batch size",
+			change: func(cfg *Config) { cfg.Scheduler.BatchSize = 0 },
+			key:    "scheduler.batch_size",
+		},
+		{
+			name:   "No reminder poll interval",
+			change: func(cfg *Config) { cfg.Reminders.PollInterval = 0 },
+			key:    "reminders.poll_interval",
+		},
+		{
+			name:   "No reminder batch size",
+			change: func(cfg *Config) { cfg.Reminders.BatchSize = 0 },
+			key:    "reminders.batch_size",
+		},
+		{
+			name:   "No reaper poll interval",
+			change: func(cfg *Config) { cfg.Reaper.PollInterval = 0 },
+			key:    "reaper.poll_interval",
+		},
+		{
+			name:   "No reaper batch size",
+			change: func(cfg *Config) { cfg.Reaper.BatchSize = 0 },
+			key:    "reaper.batch_size",
+		},
+		{
+			name:   "No retention poll interval",
+			change: func(cfg *Config) { cfg.Retention.PollInterval = 0 },
+			key:    "retention.poll_interval",
+		},
+		{
+			name:   "No retention batch size",
+			change: func(cfg *Config) { cfg.Retention.BatchSize = 0 },
+			key:    "retention.batch_size",
+		},
+		{
+			name:   "No export workers",
+			change: func(cfg *Config) { cfg.Exports.Workers = 0 },
+			key:    "exports.workers",
+		},
+		{
+			name:   "No export TTL",
+			change: func(cfg *Config) { cfg.Exports.TTL = 0 },
+			key:    "exports.ttl",
+		},
+		{
+			name:   "No export batch size",
+			change: func(cfg *Config) { cfg.Exports.BatchSize = 0 },
+			key:    "exports.batch_size",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfg := validConfig()
+			testCase.change(&cfg)
+
+			err := cfg.validate()
+			if testCase.key == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, testCase.key)
+			}
+		})
+	}
+}
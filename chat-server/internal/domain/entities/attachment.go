@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrUnsupportedType    = errors.New("attachment type is not allowed")
+	ErrEmptyAttachment    = errors.New("attachment is empty")
+)
+
+type Attachment struct {
+	ID          string
+	FileName    string
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+}
@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAvatarNotFound    = errors.New("avatar not found")
+	ErrAvatarTooLarge    = errors.New("avatar is too large")
+	ErrInvalidAvatar     = errors.New("avatar is not a supported image")
+	ErrInvalidAvatarSize = errors.New("unsupported avatar size")
+	ErrAvatarBusy        = errors.New("too many avatars are being processed, try again later")
+)
+
+type Avatar struct {
+	Username    string
+	ID          string
+	ContentType string
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// PrivateChannel marks commands sent in a private conversation.
+const PrivateChannel = "private"
+
+type CommandVisibility string
+
+const (
+	VisibilityEphemeral CommandVisibility = "ephemeral"
+	VisibilityInChannel CommandVisibility = "in_channel"
+)
+
+var (
+	ErrBotCommandNotFound = errors.New("bot command not found")
+	ErrCommandTaken       = errors.New("command is already registered")
+)
+
+// Command is a message starting with a slash, Args is the rest of it.
+type Command struct {
+	Name      string
+	Args      string
+	Sender    string
+	Channel   string
+	Recipient string
+}
+
+// CommandReply is the answer to a command. Ephemeral replies are shown only
+// to the invoker, in-channel ones are posted as Sender.
+type CommandReply struct {
+	Sender     string
+	Text       string
+	Visibility CommandVisibility
+}
+
+// BotCommand is a slash command served by an external bot over HTTP. The
+// bot replies as BotName.
+type BotCommand struct {
+	ID          int
+	Command     string
+	URL         string
+	Description string
+	BotName     string
+	Secret      string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// SendResult tells the sender what became of a message. A command is not
+// posted itself and may answer with replies only the sender sees.
+type SendResult struct {
+	Command   bool
+	Ephemeral []Message
+}
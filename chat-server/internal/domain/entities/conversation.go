@@ -0,0 +1,8 @@
+package entities
+
+type Conversation struct {
+	Partner       string
+	LastMessage   Message
+	UnreadCount   int
+	SeenByPartner bool
+}
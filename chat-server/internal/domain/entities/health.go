@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoConnectionPool = errors.New("the database has no connection pool")
+
+// PoolStats is the state of the connection pool of the database.
+type PoolStats struct {
+	MaxOpenConnections int
+	OpenConnections    int
+	InUse              int
+	Idle               int
+	WaitCount          int64
+	WaitDuration       time.Duration
+	MaxIdleClosed      int64
+	MaxIdleTimeClosed  int64
+	MaxLifetimeClosed  int64
+}
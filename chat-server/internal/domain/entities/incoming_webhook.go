@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrIncomingWebhookNotFound = errors.New("incoming webhook not found")
+	ErrBotNameTaken            = errors.New("bot name is taken by a user")
+	ErrNoText                  = errors.New("no_text")
+)
+
+// IncomingWebhook lets external tools post to a channel as a bot. The token
+// is known only when the webhook is created; afterwards just its hash is
+// kept.
+type IncomingWebhook struct {
+	ID         int
+	Name       string
+	Channel    string
+	BotName    string
+	Token      string
+	CreatedBy  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
@@ -1,7 +1,55 @@
 package entities
 
+import "time"
+
+// Order is the order of the messages of a chat. Both orders go by message
+// ID, which grows with every message: the pages of the oldest first stay put
+// as new messages come, while the offsets of the newest first count from the
+// latest message.
+type Order string
+
+const (
+	OrderOldestFirst Order = "asc"
+	OrderNewestFirst Order = "desc"
+)
+
 type Message struct {
-	Sender    string
-	Recipient string
-	Content   string
+	ID          int
+	Sender      string
+	Recipient   string
+	Content     string
+	CreatedAt   time.Time
+	Attachments []Attachment
+	Mentions    []string
+	// TTL makes a private message self-destruct: its content is purged TTL
+	// after it is sent or, with ExpireAfterRead, after the recipient first
+	// reads it. ExpiresAt is zero until the countdown starts.
+	TTL             time.Duration
+	ExpireAfterRead bool
+	ExpiresAt       time.Time
+	// Expired marks a tombstone: the message is still listed, but its
+	// content and attachments are gone.
+	Expired bool
+	// Deleted marks a public message removed by a moderator. Like an
+	// expired one, it is listed without its content.
+	Deleted bool
+}
+
+// ExpiredAt tells whether the content of the message is gone at now, even
+// if the reaper has not purged it yet.
+func (m Message) ExpiredAt(now time.Time) bool {
+	return m.Expired || !m.ExpiresAt.IsZero() && !m.ExpiresAt.After(now)
+}
+
+// Tombstone returns the message without its content.
+func (m Message) Tombstone() Message {
+	return Message{
+		ID:        m.ID,
+		Sender:    m.Sender,
+		Recipient: m.Recipient,
+		CreatedAt: m.CreatedAt,
+		TTL:       m.TTL,
+		ExpiresAt: m.ExpiresAt,
+		Expired:   true,
+	}
 }
@@ -0,0 +1,111 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrMessageNotFound     = errors.New("message not found")
+	ErrReportNotFound      = errors.New("report not found")
+	ErrReportOwnMessage    = errors.New("you can not report your own message")
+	ErrAlreadyReported     = errors.New("you have already reported this message")
+	ErrSanctionNotFound    = errors.New("the user has no such sanction")
+	ErrSanctionSelf        = errors.New("you can not sanction yourself")
+	ErrDurationRequired    = errors.New("a mute needs a duration")
+	ErrMuted               = errors.New("you are muted in the public chat")
+	ErrBanned              = errors.New("you are banned from the public chat")
+	ErrAccountSuspended    = errors.New("account is suspended")
+	ErrInvalidReportStatus = errors.New("status must be open, resolved or dismissed")
+)
+
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportResolved  ReportStatus = "resolved"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+func (s ReportStatus) Valid() bool {
+	return s == ReportOpen || s == ReportResolved || s == ReportDismissed
+}
+
+// Report is a complaint of a user about a public message. The open reports
+// make the review queue of the moderators.
+type Report struct {
+	ID        int
+	MessageID int
+	Reporter  string
+	Reason    string
+	Status    ReportStatus
+	CreatedAt time.Time
+	// Message is the reported message as it is now, empty once deleted.
+	Message    Message
+	ResolvedBy string
+	ResolvedAt time.Time
+}
+
+type SanctionKind string
+
+const (
+	// SanctionMute keeps the user from posting to the public chat for a
+	// while.
+	SanctionMute SanctionKind = "mute"
+	// SanctionBan keeps the user from posting to the public chat until it
+	// is lifted or runs out.
+	SanctionBan SanctionKind = "ban"
+	// SanctionSuspend locks the user out of their account.
+	SanctionSuspend SanctionKind = "suspend"
+)
+
+func (k SanctionKind) Valid() bool {
+	return k == SanctionMute || k == SanctionBan || k == SanctionSuspend
+}
+
+// Sanction is a restriction put on a user by a moderator. A user has at
+// most one sanction of each kind; a new one replaces the old one.
+type Sanction struct {
+	Username string
+	Kind     SanctionKind
+	Reason   string
+	// Until is when the sanction runs out, zero for a permanent one.
+	Until     time.Time
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// ActiveAt tells whether the sanction is in force at now.
+func (s Sanction) ActiveAt(now time.Time) bool {
+	return s.Until.IsZero() || s.Until.After(now)
+}
+
+type ModerationAction string
+
+const (
+	ActionDeleteMessage ModerationAction = "delete_message"
+	ActionResolveReport ModerationAction = "resolve_report"
+	ActionDismissReport ModerationAction = "dismiss_report"
+	ActionMute          ModerationAction = "mute"
+	ActionUnmute        ModerationAction = "unmute"
+	ActionBan           ModerationAction = "ban"
+	ActionUnban         ModerationAction = "unban"
+	ActionSuspend       ModerationAction = "suspend"
+	ActionUnsuspend     ModerationAction = "unsuspend"
+)
+
+// AuditEntry records one moderation action. It is stored together with the
+// action, so the log never misses one.
+type AuditEntry struct {
+	ID        int
+	Moderator string
+	Action    ModerationAction
+	// Target is the user the action is about: the sanctioned user or the
+	// sender of the deleted or reported message.
+	Target    string
+	MessageID int
+	ReportID  int
+	Reason    string
+	Until     time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationMention        NotificationKind = "mention"
+	NotificationPrivateMessage NotificationKind = "private_message"
+)
+
+// Notification tells the user about a message addressed to them: a mention
+// in the public chat or a new private message.
+type Notification struct {
+	ID        int
+	Kind      NotificationKind
+	Sender    string
+	MessageID int
+	Content   string
+	Read      bool
+	CreatedAt time.Time
+}
+
+type NotificationInbox struct {
+	Notifications []Notification
+	UnreadCount   int
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+const (
+	StatusOnline  = "online"
+	StatusAway    = "away"
+	StatusOffline = "offline"
+)
+
+type Presence struct {
+	Username string
+	Status   string
+	LastSeen time.Time
+}
+
+type TypingEvent struct {
+	Sender    string
+	Recipient string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrMessageNotAllowed      = errors.New("this user does not accept messages from you")
+	ErrUserNotFound           = errors.New("user does not exist")
+	ErrBlockSelf              = errors.New("you can not block yourself")
+	ErrInvalidDMPolicy        = errors.New("dm_policy must be anyone, contacts or nobody")
+	ErrMessageRequestNotFound = errors.New("message request not found")
+)
+
+// DMPolicy tells who may send private messages to the user. Blocked users
+// never may, whatever the policy.
+type DMPolicy string
+
+const (
+	// DMPolicyAnyone lets everybody write; the first messages of a stranger
+	// go to the message requests until the user accepts them.
+	DMPolicyAnyone DMPolicy = "anyone"
+	// DMPolicyContacts lets only the people the user has talked to write:
+	// the ones they sent a message to or accepted a request from.
+	DMPolicyContacts DMPolicy = "contacts"
+	DMPolicyNobody   DMPolicy = "nobody"
+)
+
+func (p DMPolicy) Valid() bool {
+	return p == DMPolicyAnyone || p == DMPolicyContacts || p == DMPolicyNobody
+}
+
+type PrivacySettings struct {
+	DMPolicy DMPolicy
+	// HideBlockedInFeed hides the public messages of the blocked users.
+	HideBlockedInFeed bool
+}
+
+// DefaultPrivacySettings are the settings of a user who has not changed
+// them.
+func DefaultPrivacySettings() PrivacySettings {
+	return PrivacySettings{DMPolicy: DMPolicyAnyone, HideBlockedInFeed: true}
+}
+
+type BlockedUser struct {
+	Username  string
+	CreatedAt time.Time
+}
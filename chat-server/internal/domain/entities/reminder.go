@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrReminderNotFound   = errors.New("reminder not found")
+	ErrInvalidTimeZone    = errors.New("unknown time zone")
+	ErrReminderNeverFires = errors.New("schedule never fires")
+)
+
+// Reminder posts Content to the public chat as Sender every time its cron
+// Schedule fires in TimeZone. LastRunAt is the due time of the last post,
+// zero if there was none yet.
+type Reminder struct {
+	ID        int
+	Name      string
+	Content   string
+	Schedule  string
+	TimeZone  string
+	Sender    string
+	Paused    bool
+	NextRunAt time.Time
+	LastRunAt time.Time
+	CreatedBy string
+	CreatedAt time.Time
+}
@@ -0,0 +1,16 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrLegalHoldNotFound = errors.New("the user has no legal hold")
+
+// LegalHold exempts the conversations of a user from the retention
+// policies: their public messages and their private conversations, both
+// the sent and the received messages, are kept until the hold is lifted.
+type LegalHold struct {
+	Username string
+	PlacedAt time.Time
+}
@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+type ScheduledStatus string
+
+const (
+	ScheduledPending ScheduledStatus = "pending"
+	ScheduledSending ScheduledStatus = "sending"
+	ScheduledSent    ScheduledStatus = "sent"
+	ScheduledFailed  ScheduledStatus = "failed"
+)
+
+var (
+	ErrScheduledMessageNotFound = errors.New("scheduled message not found")
+	ErrSendAtInPast             = errors.New("send_at must be in the future")
+	ErrSendAtTooFar             = errors.New("send_at is too far in the future")
+	ErrRecipientNotFound        = errors.New("recipient does not exist")
+	ErrScheduledCommand         = errors.New("commands can not be scheduled, start the message with // to send it as text")
+)
+
+// ScheduledMessage is a message posted to Channel at SendAt. Recipient is
+// set for private messages only.
+type ScheduledMessage struct {
+	ID        int
+	Channel   string
+	Sender    string
+	Recipient string
+	Content   string
+	SendAt    time.Time
+	Status    ScheduledStatus
+	LastError string
+	CreatedAt time.Time
+}
+
+// Message returns the message to post.
+func (m ScheduledMessage) Message() Message {
+	return Message{Sender: m.Sender, Recipient: m.Recipient, Content: m.Content}
+}
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vavelour/chat/pkg/textsearch"
+)
+
+var (
+	ErrEmptySearchQuery = errors.New("search query has no words")
+	ErrInvalidCursor    = errors.New("invalid search cursor")
+)
+
+type SearchQuery struct {
+	Text   string
+	Terms  []textsearch.Term
+	Sender string
+	From   time.Time
+	To     time.Time
+	After  *SearchCursor
+	Limit  int
+}
+
+// SearchCursor is the position of a result in the search order: newest
+// messages first, ties broken by chat and then by message ID.
+type SearchCursor struct {
+	CreatedAt time.Time
+	Chat      string
+	ID        int
+}
+
+// Before reports whether c comes before other in the search order.
+func (c SearchCursor) Before(other SearchCursor) bool {
+	if !c.CreatedAt.Equal(other.CreatedAt) {
+		return c.CreatedAt.After(other.CreatedAt)
+	}
+
+	if c.Chat != other.Chat {
+		return c.Chat > other.Chat
+	}
+
+	return c.ID > other.ID
+}
+
+type SearchResult struct {
+	Message Message
+	Snippet string
+	Cursor  SearchCursor
+}
+
+type SearchPage struct {
+	Results []SearchResult
+	Next    *SearchCursor
+}
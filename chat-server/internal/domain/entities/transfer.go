@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidExportFile  = errors.New("invalid export file")
+	ErrUnsupportedExport  = errors.New("unsupported export version")
+	ErrDataExportNotFound = errors.New("no export of your data, request one first")
+	ErrDataExportNotReady = errors.New("the export of your data is not ready yet")
+	ErrDataExportBusy     = errors.New("too many exports are being prepared, try again later")
+)
+
+// TransferSummary counts the records an export or an import went through.
+type TransferSummary struct {
+	Users           int
+	PublicMessages  int
+	PrivateMessages int
+}
+
+type DataExportStatus string
+
+const (
+	DataExportPending DataExportStatus = "pending"
+	DataExportReady   DataExportStatus = "ready"
+	DataExportFailed  DataExportStatus = "failed"
+)
+
+// DataExport is an archive of the data of a user, prepared in the
+// background. A ready one can be downloaded until ExpiresAt.
+type DataExport struct {
+	ID          string
+	Username    string
+	Status      DataExportStatus
+	RequestedAt time.Time
+	ExpiresAt   time.Time
+	Size        int64
+}
@@ -0,0 +1,79 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// PublicChannel is the public chat, the only channel webhooks are sent for.
+// Private conversations never leave the server.
+const PublicChannel = "public"
+
+type WebhookEventType string
+
+const (
+	EventMessageCreated WebhookEventType = "message.created"
+	EventMessageDeleted WebhookEventType = "message.deleted"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// Webhook is a subscription of an external URL to message events. An empty
+// Channel matches every channel.
+type Webhook struct {
+	ID        int
+	URL       string
+	Events    []WebhookEventType
+	Channel   string
+	Secret    string
+	Active    bool
+	Failures  int
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// Subscribed reports whether the webhook wants the event of the channel.
+func (w Webhook) Subscribed(event WebhookEventType, channel string) bool {
+	if !w.Active || (w.Channel != "" && w.Channel != channel) {
+		return false
+	}
+
+	for _, val := range w.Events {
+		if val == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookEvent keeps a snapshot of the message, so the payload stays the
+// same for every attempt even if the message changes later.
+type WebhookEvent struct {
+	ID        int
+	Type      WebhookEventType
+	Channel   string
+	Message   Message
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int
+	Webhook        Webhook
+	Event          WebhookEvent
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	ResponseStatus int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
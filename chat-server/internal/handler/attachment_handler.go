@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	attachmentsSent = "message with attachments sent"
+	maxContentSize  = 64 << 10
+	maxAttachments  = 10
+)
+
+var (
+	errContentTooLarge    = errors.New("message content is too large")
+	errTooManyAttachments = errors.New("too many attachments")
+	errUnexpectedPart     = errors.New("unexpected form field")
+)
+
+//go:generate mockgen -source=attachment_handler.go -destination=mocks/attachment_service_mock.go
+
+type AttachmentService interface {
+	Upload(ctx context.Context, fileName string, r io.Reader) (entities.Attachment, error)
+	Discard(ctx context.Context, attachments []entities.Attachment)
+	SendMessage(ctx context.Context, m entities.Message) (entities.SendResult, error)
+	Open(ctx context.Context, user, id string) (entities.Attachment, io.ReadCloser, error)
+}
+
+type AttachmentHandler struct {
+	service  AttachmentService
+	validate *validator.Validate
+}
+
+func NewAttachmentHandler(s AttachmentService, v *validator.Validate) *AttachmentHandler {
+	return &AttachmentHandler{service: s, validate: v}
+}
+
+func (h *AttachmentHandler) AttachmentRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/attachments", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Post("/{chat}", h.SendAttachmentMessage)
+		r.Get("/{id}", h.DownloadAttachment)
+	})
+}
+
+// SendAttachmentMessage @summary		Отправка сообщения с вложениями
+//
+//	@description	Отправляет сообщение с файлами в публичный чат или указанному получателю. Тело запроса — multipart/form-data с полем content и до 10 полей file. Тип файла определяется по содержимому. Сообщение, начинающееся с /, выполняется как команда, как и без вложений: файлы при этом не сохраняются, а ответы, видимые только отправителю, возвращаются в поле ephemeral.
+//	@tags			attachments
+//	@accept			mpfd
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			chat		path		string									true	"Тип чата: private или public"
+//	@param			username	query		string									false	"Имя получателя для приватного чата"
+//	@param			content		formData	string									false	"Текст сообщения"
+//	@param			file		formData	file									false	"Вложение"
+//	@success		200			{object}	response.SendAttachmentMessageResponse	"Сообщение успешно отправлено"
+//	@failure		400			{object}	baseresponse.ResponseError				"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError				"Получатель не принимает сообщения от отправителя или отправитель наказан в публичном чате"
+//	@failure		413			{object}	baseresponse.ResponseError				"Файл слишком большой"
+//	@failure		415			{object}	baseresponse.ResponseError				"Недопустимый тип файла"
+//	@failure		500			{object}	baseresponse.ResponseError				"Ошибка при сохранении файла"
+//	@router			/v1/attachments/{chat} [post]
+func (h *AttachmentHandler) SendAttachmentMessage(w http.ResponseWriter, r *http.Request) {
+	sender, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input := request.SendAttachmentMessageRequest{Sender: sender, Chat: chi.URLParam(r, "chat")}
+	if input.Chat == privateChat {
+		input.Recipient = r.URL.Query().Get("username")
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	attachments, status, err := h.readParts(r.Context(), reader, &input)
+	if err == nil {
+		input.Files = len(attachments)
+		status, err = http.StatusBadRequest, input.Validate(h.validate)
+	}
+
+	if err != nil {
+		h.service.Discard(r.Context(), attachments)
+		baseresponse.ReturnErrorResponse(w, r, status, err)
+		return
+	}
+
+	res, err := h.service.SendMessage(r.Context(), mapper.SendAttachmentMessageRequestToEntities(input, attachments))
+	if errors.Is(err, entities.ErrMessageNotAllowed) || sanctioned(err) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusForbidden, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if res.Command {
+		render.JSON(w, r, response.SendAttachmentMessageResponse{Response: commandExecuted, Attachments: []response.AttachmentItem{}, Ephemeral: mapper.SendResultToEphemeral(res)})
+		return
+	}
+
+	render.JSON(w, r, mapper.AttachmentEntitiesToResponse(attachmentsSent, attachments))
+}
+
+// DownloadAttachment @summary		Скачивание вложения
+//
+//	@description	Возвращает содержимое вложения, если пользователь видит сообщение, к которому оно прикреплено.
+//	@tags			attachments
+//	@produce		octet-stream
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		string						true	"Идентификатор вложения"
+//	@success		200	{file}		file						"Содержимое файла"
+//	@failure		404	{object}	baseresponse.ResponseError	"Вложение не найдено"
+//	@failure		500	{object}	baseresponse.ResponseError	"Ошибка при получении файла"
+//	@router			/v1/attachments/{id} [get]
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	attachment, content, err := h.service.Open(r.Context(), user, chi.URLParam(r, "id"))
+	if errors.Is(err, entities.ErrAttachmentNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = io.Copy(w, content)
+}
+
+// readParts uploads the files of the form as they are streamed and fills in
+// the message content. Already uploaded files are returned even on error so
+// that the caller can discard them.
+func (h *AttachmentHandler) readParts(ctx context.Context, reader *multipart.Reader, input *request.SendAttachmentMessageRequest) ([]entities.Attachment, int, error) {
+	var attachments []entities.Attachment
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return attachments, http.StatusOK, nil
+		} else if err != nil {
+			return attachments, http.StatusBadRequest, err
+		}
+
+		switch part.FormName() {
+		case "content":
+			content, err := io.ReadAll(io.LimitReader(part, maxContentSize+1))
+			if err != nil {
+				return attachments, http.StatusBadRequest, err
+			}
+
+			if len(content) > maxContentSize {
+				return attachments, http.StatusRequestEntityTooLarge, errContentTooLarge
+			}
+
+			input.Content = string(content)
+		case "file":
+			if len(attachments) == maxAttachments {
+				return attachments, http.StatusBadRequest, errTooManyAttachments
+			}
+
+			attachment, err := h.service.Upload(ctx, part.FileName(), part)
+			if err != nil {
+				return attachments, uploadErrorStatus(err), err
+			}
+
+			attachments = append(attachments, attachment)
+		default:
+			return attachments, http.StatusBadRequest, errUnexpectedPart
+		}
+
+		part.Close()
+	}
+}
+
+func uploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entities.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, entities.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, entities.ErrEmptyAttachment):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func multipartBody(t *testing.T, content string, files map[string]string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if content != "" {
+		assert.NoError(t, writer.WriteField("content", content))
+	}
+
+	for name, data := range files {
+		part, err := writer.CreateFormFile("file", name)
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(data))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, writer.Close())
+
+	return body, writer.FormDataContentType()
+}
+
+func TestAttachmentHandler_SendAttachmentMessage(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockAttachmentService)
+
+	attachment := entities.Attachment{ID: "a1", FileName: "cat.png", ContentType: "image/png", Size: 3}
+
+	testTable := []struct {
+		name                string
+		target              string
+		content             string
+		files               map[string]string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:    "ok_public",
+			target:  "/attachments/public",
+			content: "look at this",
+			files:   map[string]string{"cat.png": "png"},
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Upload(gomock.Any(), "cat.png", gomock.Any()).Return(attachment, nil)
+				s.EXPECT().SendMessage(gomock.Any(), entities.Message{Sender: "tester", Content: "look at this", Attachments: []entities.Attachment{attachment}}).Return(entities.SendResult{}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"message with attachments sent","attachments":[{"id":"a1","file_name":"cat.png","content_type":"image/png","size":3}]}`,
+		},
+		{
+			name:   "ok_private",
+			target: "/attachments/private?username=valera",
+			files:  map[string]string{"cat.png": "png"},
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Upload(gomock.Any(), "cat.png", gomock.Any()).Return(attachment, nil)
+				s.EXPECT().SendMessage(gomock.Any(), entities.Message{Sender: "tester", Recipient: "valera", Attachments: []entities.Attachment{attachment}}).Return(entities.SendResult{}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"message with attachments sent","attachments":[{"id":"a1","file_name":"cat.png","content_type":"image/png","size":3}]}`,
+		},
+		{
+			name:    "command",
+			target:  "/attachments/public",
+			content: "/help",
+			files:   map[string]string{"cat.png": "png"},
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Upload(gomock.Any(), "cat.png", gomock.Any()).Return(attachment, nil)
+				s.EXPECT().SendMessage(gomock.Any(), entities.Message{Sender: "tester", Content: "/help", Attachments: []entities.Attachment{attachment}}).
+					Return(entities.SendResult{Command: true, Ephemeral: []entities.Message{{Sender: "chatbot", Recipient: "tester", Content: "commands"}}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"command executed","attachments":[],"ephemeral":[{"sender":"chatbot","content":"commands","created_at":"0001-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name:   "missing_recipient",
+			target: "/attachments/private",
+			files:  map[string]string{"cat.png": "png"},
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Upload(gomock.Any(), "cat.png", gomock.Any()).Return(attachment, nil)
+				s.EXPECT().Discard(gomock.Any(), []entities.Attachment{attachment})
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'SendAttachmentMessageRequest.Recipient' Error:Field validation for 'Recipient' failed on the 'required_if' tag"}`,
+		},
+		{
+			name:   "empty_message",
+			target: "/attachments/public",
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Discard(gomock.Any(), nil)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'SendAttachmentMessageRequest.Content' Error:Field validation for 'Content' failed on the 'required_without' tag"}`,
+		},
+		{
+			name:   "unsupported_type",
+			target: "/attachments/public",
+			files:  map[string]string{"run.exe": "MZ"},
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Upload(gomock.Any(), "run.exe", gomock.Any()).Return(entities.Attachment{}, entities.ErrUnsupportedType)
+				s.EXPECT().Discard(gomock.Any(), nil)
+			},
+			expectedStatusCode:  415,
+			expectedRequestBody: `{"error":"attachment type is not allowed"}`,
+		},
+		{
+			name:   "too_large",
+			target: "/attachments/public",
+			files:  map[string]string{"big.png": "png"},
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Upload(gomock.Any(), "big.png", gomock.Any()).Return(entities.Attachment{}, entities.ErrAttachmentTooLarge)
+				s.EXPECT().Discard(gomock.Any(), nil)
+			},
+			expectedStatusCode:  413,
+			expectedRequestBody: `{"error":"attachment is too large"}`,
+		},
+		{
+			name:    "service_error",
+			target:  "/attachments/private?username=valera",
+			content: "hi",
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().SendMessage(gomock.Any(), entities.Message{Sender: "tester", Recipient: "valera", Content: "hi"}).Return(entities.SendResult{}, errors.New("this user is not exist"))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"this user is not exist"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			attachments := mock_handler.NewMockAttachmentService(ctrl)
+			validate := validator.New()
+
+			attachmentHandler := NewAttachmentHandler(attachments, validate)
+
+			r := chi.NewRouter()
+			r.Post("/attachments/{chat}", attachmentHandler.SendAttachmentMessage)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			body, contentType := multipartBody(t, testCase.content, testCase.files)
+			req := httptest.NewRequest("POST", testCase.target, body)
+			req.Header.Set("Content-Type", contentType)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(attachments)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestAttachmentHandler_DownloadAttachment(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockAttachmentService)
+
+	testTable := []struct {
+		name                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedHeaders     map[string]string
+		expectedRequestBody string
+	}{
+		{
+			name: "ok",
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Open(gomock.Any(), "tester", "a1").Return(
+					entities.Attachment{ID: "a1", FileName: "отчёт.pdf", ContentType: "application/pdf", Size: 7},
+					io.NopCloser(strings.NewReader("content")), nil)
+			},
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type":           "application/pdf",
+				"Content-Length":         "7",
+				"Content-Disposition":    "attachment; filename*=utf-8''%D0%BE%D1%82%D1%87%D1%91%D1%82.pdf",
+				"X-Content-Type-Options": "nosniff",
+			},
+			expectedRequestBody: "content",
+		},
+		{
+			name: "not_found",
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Open(gomock.Any(), "tester", "a1").Return(entities.Attachment{}, nil, entities.ErrAttachmentNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"attachment not found"}`,
+		},
+		{
+			name: "service_error",
+			mockBehavior: func(s *mock_handler.MockAttachmentService) {
+				s.EXPECT().Open(gomock.Any(), "tester", "a1").Return(entities.Attachment{}, nil, errors.New("storage error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"storage error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			attachments := mock_handler.NewMockAttachmentService(ctrl)
+			validate := validator.New()
+
+			attachmentHandler := NewAttachmentHandler(attachments, validate)
+
+			r := chi.NewRouter()
+			r.Get("/attachments/{id}", attachmentHandler.DownloadAttachment)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("GET", "/attachments/a1", nil)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(attachments)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+			for key, val := range testCase.expectedHeaders {
+				assert.Equal(t, val, w.Header().Get(key))
+			}
+		})
+	}
+}
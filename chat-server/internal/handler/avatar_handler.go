@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	avatarProcessing = "avatar is being processed"
+	avatarDeleted    = "avatar deleted"
+)
+
+var errMissingAvatarFile = errors.New("avatar file is missing")
+
+//go:generate mockgen -source=avatar_handler.go -destination=mocks/avatar_service_mock.go
+
+type AvatarService interface {
+	Upload(user string, r io.Reader) (entities.Avatar, error)
+	Delete(ctx context.Context, user string) error
+	Open(ctx context.Context, username string, size int) (entities.Avatar, io.ReadCloser, error)
+}
+
+type AvatarHandler struct {
+	service AvatarService
+	maxAge  int
+}
+
+func NewAvatarHandler(s AvatarService, maxAge int) *AvatarHandler {
+	return &AvatarHandler{service: s, maxAge: maxAge}
+}
+
+func (h *AvatarHandler) AvatarRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/users", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/me/avatar", h.ShowAvatar)
+		r.Post("/me/avatar", h.UploadAvatar)
+		r.Delete("/me/avatar", h.DeleteAvatar)
+		r.Get("/{username}/avatar", h.ShowAvatar)
+	})
+}
+
+// UploadAvatar @summary		Загрузка аватара
+//
+//	@description	Принимает изображение (JPEG, PNG, GIF или WebP) в поле file формы multipart/form-data. Метаданные удаляются, миниатюры создаются в фоне, после чего аватар становится доступен.
+//	@tags			users
+//	@accept			mpfd
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			file	formData	file							true	"Изображение"
+//	@success		202		{object}	response.UploadAvatarResponse	"Аватар принят в обработку"
+//	@failure		400		{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		413		{object}	baseresponse.ResponseError		"Изображение слишком большое"
+//	@failure		415		{object}	baseresponse.ResponseError		"Неподдерживаемый формат изображения"
+//	@failure		503		{object}	baseresponse.ResponseError		"Очередь обработки переполнена"
+//	@router			/v1/users/me/avatar [post]
+func (h *AvatarHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errMissingAvatarFile)
+			return
+		} else if err != nil {
+			baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		_, err = h.service.Upload(user, part)
+		if err != nil {
+			baseresponse.ReturnErrorResponse(w, r, avatarErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		render.JSON(w, r, response.UploadAvatarResponse{Response: avatarProcessing, AvatarURL: mapper.AvatarURL(user)})
+
+		return
+	}
+}
+
+// DeleteAvatar @summary		Удаление аватара
+//
+//	@description	Удаляет аватар текущего пользователя вместе с миниатюрами.
+//	@tags			users
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.DeleteAvatarResponse	"Аватар удален"
+//	@failure		500	{object}	baseresponse.ResponseError		"Ошибка при удалении аватара"
+//	@router			/v1/users/me/avatar [delete]
+func (h *AvatarHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), user); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.DeleteAvatarResponse{Response: avatarDeleted})
+}
+
+// ShowAvatar @summary		Получение аватара
+//
+//	@description	Возвращает миниатюру аватара пользователя. Ответ кэшируется клиентом и проверяется по ETag.
+//	@tags			users
+//	@produce		image/jpeg,image/png
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string						true	"Имя пользователя или me"
+//	@param			size		query		int							false	"Размер миниатюры в пикселях"
+//	@success		200			{file}		file						"Миниатюра"
+//	@success		304			{string}	string						"Не изменилось"
+//	@failure		400			{object}	baseresponse.ResponseError	"Неподдерживаемый размер"
+//	@failure		404			{object}	baseresponse.ResponseError	"Аватар не найден"
+//	@router			/v1/users/{username}/avatar [get]
+func (h *AvatarHandler) ShowAvatar(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		user, ok := r.Context().Value("Sender").(string)
+		if !ok {
+			baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+			return
+		}
+
+		username = user
+	}
+
+	var size int
+	if val := r.URL.Query().Get("size"); val != "" {
+		var err error
+		if size, err = strconv.Atoi(val); err != nil {
+			baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, entities.ErrInvalidAvatarSize)
+			return
+		}
+	}
+
+	avatar, content, err := h.service.Open(r.Context(), username, size)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, avatarErrorStatus(err), err)
+		return
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", avatar.ContentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", h.maxAge))
+	w.Header().Set("ETag", fmt.Sprintf(`"%s-%d"`, avatar.ID, size))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(w, r, "", avatar.UpdatedAt, bytes.NewReader(data))
+}
+
+func avatarErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entities.ErrAvatarNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entities.ErrInvalidAvatarSize):
+		return http.StatusBadRequest
+	case errors.Is(err, entities.ErrAvatarTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, entities.ErrInvalidAvatar):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, entities.ErrAvatarBusy):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
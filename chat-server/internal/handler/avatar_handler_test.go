@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAvatarHandler_UploadAvatar(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockAvatarService)
+
+	testTable := []struct {
+		name                string
+		files               map[string]string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "ok",
+			files: map[string]string{"me.png": "png"},
+			mockBehavior: func(s *mock_handler.MockAvatarService) {
+				s.EXPECT().Upload("tester", gomock.Any()).Return(entities.Avatar{Username: "tester", ID: "a1"}, nil)
+			},
+			expectedStatusCode:  202,
+			expectedRequestBody: `{"response":"avatar is being processed","avatar_url":"/v1/users/tester/avatar"}`,
+		},
+		{
+			name:                "missing_file",
+			mockBehavior:        func(s *mock_handler.MockAvatarService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"avatar file is missing"}`,
+		},
+		{
+			name:  "invalid_image",
+			files: map[string]string{"me.pdf": "%PDF"},
+			mockBehavior: func(s *mock_handler.MockAvatarService) {
+				s.EXPECT().Upload("tester", gomock.Any()).Return(entities.Avatar{}, entities.ErrInvalidAvatar)
+			},
+			expectedStatusCode:  415,
+			expectedRequestBody: `{"error":"avatar is not a supported image"}`,
+		},
+		{
+			name:  "queue_full",
+			files: map[string]string{"me.png": "png"},
+			mockBehavior: func(s *mock_handler.MockAvatarService) {
+				s.EXPECT().Upload("tester", gomock.Any()).Return(entities.Avatar{}, entities.ErrAvatarBusy)
+			},
+			expectedStatusCode:  503,
+			expectedRequestBody: `{"error":"too many avatars are being processed, try again later"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			avatars := mock_handler.NewMockAvatarService(ctrl)
+			avatarHandler := NewAvatarHandler(avatars, 3600)
+
+			r := chi.NewRouter()
+			r.Post("/users/me/avatar", avatarHandler.UploadAvatar)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			body, contentType := multipartBody(t, "", testCase.files)
+			req := httptest.NewRequest("POST", "/users/me/avatar", body)
+			req.Header.Set("Content-Type", contentType)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(avatars)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestAvatarHandler_ShowAvatar(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockAvatarService)
+
+	avatar := entities.Avatar{Username: "valera", ID: "a1", ContentType: "image/jpeg", UpdatedAt: time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)}
+
+	testTable := []struct {
+		name                string
+		target              string
+		ifNoneMatch         string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedHeaders     map[string]string
+		expectedRequestBody string
+	}{
+		{
+			name:   "ok",
+			target: "/users/valera/avatar?size=64",
+			mockBehavior: func(s *mock_handler.MockAvatarService) {
+				s.EXPECT().Open(gomock.Any(), "valera", 64).Return(avatar, io.NopCloser(strings.NewReader("jpeg")), nil)
+			},
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type":  "image/jpeg",
+				"Cache-Control": "private, max-age=3600",
+				"ETag":          `"a1-64"`,
+				"Last-Modified": "Mon, 19 Oct 2026 09:00:00 GMT",
+			},
+			expectedRequestBody: "jpeg",
+		},
+		{
+			name:   "own_avatar",
+			target: "/users/me/avatar",
+			mockBehavior: func(s *mock_handler.MockAvatarService) {
+				s.EXPECT().Open(gomock.Any(), "tester", 0).Return(avatar, io.NopCloser(strings.NewReader("jpeg")), nil)
+			},
+			expectedStatusCode:  200,
+			expectedHeaders:     map[string]string{"ETag": `"a1-0"`},
+			expectedRequestBody: "jpeg",
+		},
+		{
+			name:        "not_modified",
+			target:      "/users/valera/avatar?size=64",
+			ifNoneMatch: `"a1-64"`,
+			mockBehavior: func(s *mock_handler.MockAvatarService) {
+				s.EXPECT().Open(gomock.Any(), "valera", 64).Return(avatar, io.NopCloser(strings.NewReader("jpeg")), nil)
+			},
+			expectedStatusCode:  304,
+			expectedRequestBody: "",
+		},
+		{
+			name:                "invalid_size",
+			target:              "/users/valera/avatar?size=big",
+			mockBehavior:        func(s *mock_handler.MockAvatarService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"unsupported avatar size"}`,
+		},
+		{
+			name:   "not_found",
+			target: "/users/valera/avatar",
+			mockBehavior: func(s *mock_handler.MockAvatarService) {
+				s.EXPECT().Open(gomock.Any(), "valera", 0).Return(entities.Avatar{}, nil, entities.ErrAvatarNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"avatar not found"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			avatars := mock_handler.NewMockAvatarService(ctrl)
+			avatarHandler := NewAvatarHandler(avatars, 3600)
+
+			r := chi.NewRouter()
+			r.Get("/users/me/avatar", avatarHandler.ShowAvatar)
+			r.Get("/users/{username}/avatar", avatarHandler.ShowAvatar)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("GET", testCase.target, nil)
+			if testCase.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", testCase.ifNoneMatch)
+			}
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(avatars)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+			for key, val := range testCase.expectedHeaders {
+				assert.Equal(t, val, w.Header().Get(key))
+			}
+		})
+	}
+}
+
+func TestAvatarHandler_DeleteAvatar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	avatars := mock_handler.NewMockAvatarService(ctrl)
+	avatarHandler := NewAvatarHandler(avatars, 3600)
+
+	ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+	avatars.EXPECT().Delete(gomock.Any(), "tester").Return(nil)
+	w := httptest.NewRecorder()
+	avatarHandler.DeleteAvatar(w, httptest.NewRequest("DELETE", "/users/me/avatar", nil).WithContext(ctx))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"response":"avatar deleted"}`, strings.TrimSpace(w.Body.String()))
+
+	avatars.EXPECT().Delete(gomock.Any(), "tester").Return(errors.New("storage error"))
+	w = httptest.NewRecorder()
+	avatarHandler.DeleteAvatar(w, httptest.NewRequest("DELETE", "/users/me/avatar", nil).WithContext(ctx))
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, `{"error":"storage error"}`, strings.TrimSpace(w.Body.String()))
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	botCommandCreated   = "bot command created"
+	botCommandsReceived = "bot commands received"
+	botCommandDeleted   = "bot command deleted"
+)
+
+var errInvalidBotCommandID = errors.New("invalid bot command id")
+
+//go:generate mockgen -source=bot_handler.go -destination=mocks/bot_service_mock.go
+
+type BotService interface {
+	Create(c entities.BotCommand) (entities.BotCommand, error)
+	List() ([]entities.BotCommand, error)
+	Delete(id int) error
+}
+
+type BotHandler struct {
+	service  BotService
+	validate *validator.Validate
+}
+
+func NewBotHandler(s BotService, v *validator.Validate) *BotHandler {
+	return &BotHandler{service: s, validate: v}
+}
+
+// BotRoutes registers the admin API. The middlewares have to include the
+// admin guard.
+func (h *BotHandler) BotRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/admin/bots", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ListBotCommands)
+		r.Post("/", h.CreateBotCommand)
+		r.Delete("/{id}", h.DeleteBotCommand)
+	})
+}
+
+// CreateBotCommand @summary		Регистрация команды бота
+//
+//	@description	Регистрирует внешнего бота, который обслуживает команду. Бот получает POST с JSON {command, text, user_name, channel, recipient}, подписанный HMAC-SHA256 в заголовке X-Chat-Signature, и отвечает {"response_type": "ephemeral"|"in_channel", "text": ...}. Секрет возвращается один раз.
+//	@tags			admin
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.CreateBotCommandRequest		true	"Параметры команды"
+//	@success		201			{object}	response.CreateBotCommandResponse	"Команда зарегистрирована"
+//	@failure		400			{object}	baseresponse.ResponseError			"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError			"Доступно только администраторам"
+//	@failure		409			{object}	baseresponse.ResponseError			"Команда или имя бота заняты"
+//	@router			/v1/admin/bots [post]
+func (h *BotHandler) CreateBotCommand(w http.ResponseWriter, r *http.Request) {
+	var input request.CreateBotCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	admin, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.CreatedBy = admin
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	command, err := h.service.Create(mapper.CreateBotCommandRequestToEntity(input))
+	if errors.Is(err, entities.ErrCommandTaken) || errors.Is(err, entities.ErrBotNameTaken) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	render.JSON(w, r, response.CreateBotCommandResponse{Response: botCommandCreated, Command: mapper.BotCommandToResponse(command), Secret: command.Secret})
+}
+
+// ListBotCommands @summary		Список команд ботов
+//
+//	@description	Возвращает команды внешних ботов. Секреты не возвращаются.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.ListBotCommandsResponse	"Команды получены"
+//	@failure		403	{object}	baseresponse.ResponseError			"Доступно только администраторам"
+//	@router			/v1/admin/bots [get]
+func (h *BotHandler) ListBotCommands(w http.ResponseWriter, r *http.Request) {
+	commands, err := h.service.List()
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.BotCommandsToResponse(botCommandsReceived, commands))
+}
+
+// DeleteBotCommand @summary		Удаление команды бота
+//
+//	@description	Удаляет команду. Бот и его сообщения остаются.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		int							true	"Идентификатор команды"
+//	@success		200	{object}	response.WebhookResponse	"Команда удалена"
+//	@failure		404	{object}	baseresponse.ResponseError	"Команда не найдена"
+//	@router			/v1/admin/bots/{id} [delete]
+func (h *BotHandler) DeleteBotCommand(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidBotCommandID)
+		return
+	}
+
+	err = h.service.Delete(id)
+	if errors.Is(err, entities.ErrBotCommandNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.WebhookResponse{Response: botCommandDeleted})
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBotHandler_CreateBotCommand(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockBotService)
+
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"command": "deploy", "url": "https://bots.example/deploy", "bot_name": "deployer"}`,
+			mockBehavior: func(s *mock_handler.MockBotService) {
+				s.EXPECT().Create(entities.BotCommand{Command: "deploy", URL: "https://bots.example/deploy", BotName: "deployer", CreatedBy: "tester"}).Return(entities.BotCommand{
+					ID: 1, Command: "deploy", URL: "https://bots.example/deploy", BotName: "deployer", Secret: "secret", CreatedBy: "tester", CreatedAt: createdAt,
+				}, nil)
+			},
+			expectedStatusCode: 201,
+			expectedRequestBody: `{"response":"bot command created","command":{"id":1,"command":"deploy","url":"https://bots.example/deploy",` +
+				`"bot_name":"deployer","created_by":"tester","created_at":"2026-10-19T09:00:00Z"},"secret":"secret"}`,
+		},
+		{
+			name:                "invalid_command",
+			inputBody:           `{"command": "/deploy", "url": "https://bots.example/deploy", "bot_name": "deployer"}`,
+			mockBehavior:        func(s *mock_handler.MockBotService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"command must start with a letter and contain only lowercase letters, digits, '_' and '-'"}`,
+		},
+		{
+			name:      "command_taken",
+			inputBody: `{"command": "who", "url": "https://bots.example/who", "bot_name": "deployer"}`,
+			mockBehavior: func(s *mock_handler.MockBotService) {
+				s.EXPECT().Create(entities.BotCommand{Command: "who", URL: "https://bots.example/who", BotName: "deployer", CreatedBy: "tester"}).Return(entities.BotCommand{}, entities.ErrCommandTaken)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"error":"command is already registered"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bots := mock_handler.NewMockBotService(ctrl)
+			botHandler := NewBotHandler(bots, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/bots", botHandler.CreateBotCommand)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("POST", "/bots", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(bots)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	dataExportPending = "export is being prepared"
+	dataExportReady   = "export is ready"
+)
+
+//go:generate mockgen -source=export_handler.go -destination=mocks/export_service_mock.go
+
+type ExportService interface {
+	Request(ctx context.Context, username string) (entities.DataExport, error)
+	Open(ctx context.Context, username string) (entities.DataExport, io.ReadCloser, error)
+}
+
+type ExportHandler struct {
+	service ExportService
+}
+
+func NewExportHandler(s ExportService) *ExportHandler {
+	return &ExportHandler{service: s}
+}
+
+func (h *ExportHandler) ExportRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/users/me/export", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.RequestExport)
+		r.Get("/download", h.DownloadExport)
+	})
+}
+
+// RequestExport @summary		Экспорт своих данных
+//
+//	@description	Готовит в фоне архив с данными текущего пользователя: его публичными сообщениями и личными переписками в формате JSON Lines, а также личными переписками в формате mbox. Пока архив готовится, возвращает 202; готовый архив доступен по ссылке download_url до expires_at, после чего запрос готовит новый.
+//	@tags			users
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.DataExportResponse	"Архив готов"
+//	@success		202	{object}	response.DataExportResponse	"Архив готовится"
+//	@failure		503	{object}	baseresponse.ResponseError	"Очередь экспорта переполнена"
+//	@router			/v1/users/me/export [get]
+func (h *ExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	export, err := h.service.Request(r.Context(), user)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, exportErrorStatus(err), err)
+		return
+	}
+
+	if export.Status != entities.DataExportReady {
+		w.WriteHeader(http.StatusAccepted)
+		render.JSON(w, r, mapper.DataExportToResponse(dataExportPending, export))
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.DataExportToResponse(dataExportReady, export))
+}
+
+// DownloadExport @summary		Скачивание экспорта своих данных
+//
+//	@description	Возвращает zip-архив с данными текущего пользователя, подготовленный запросом GET /v1/users/me/export.
+//	@tags			users
+//	@produce		application/zip
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{file}		file						"Архив"
+//	@failure		404	{object}	baseresponse.ResponseError	"Экспорт не запрошен или устарел"
+//	@failure		409	{object}	baseresponse.ResponseError	"Архив еще готовится"
+//	@router			/v1/users/me/export/download [get]
+func (h *ExportHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	export, content, err := h.service.Open(r.Context(), user)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, exportErrorStatus(err), err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Length", strconv.FormatInt(export.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": "chat-export-" + export.RequestedAt.UTC().Format("20060102") + ".zip",
+	}))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = io.Copy(w, content)
+}
+
+func exportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entities.ErrDataExportNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entities.ErrDataExportNotReady):
+		return http.StatusConflict
+	case errors.Is(err, entities.ErrDataExportBusy):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
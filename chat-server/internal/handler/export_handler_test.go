@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+)
+
+func TestExportHandler_RequestExport(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockExportService)
+
+	requestedAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "pending",
+			mockBehavior: func(s *mock_handler.MockExportService) {
+				s.EXPECT().Request(gomock.Any(), "tester").Return(entities.DataExport{
+					ID: "e1", Username: "tester", Status: entities.DataExportPending, RequestedAt: requestedAt,
+				}, nil)
+			},
+			expectedStatusCode:  202,
+			expectedRequestBody: `{"response":"export is being prepared","status":"pending","requested_at":"2026-10-19T09:00:00Z"}`,
+		},
+		{
+			name: "ready",
+			mockBehavior: func(s *mock_handler.MockExportService) {
+				s.EXPECT().Request(gomock.Any(), "tester").Return(entities.DataExport{
+					ID: "e1", Username: "tester", Status: entities.DataExportReady, RequestedAt: requestedAt,
+					ExpiresAt: requestedAt.Add(24 * time.Hour), Size: 512,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"response":"export is ready","status":"ready","requested_at":"2026-10-19T09:00:00Z",` +
+				`"expires_at":"2026-10-20T09:00:00Z","size":512,"download_url":"/v1/users/me/export/download"}`,
+		},
+		{
+			name: "queue_full",
+			mockBehavior: func(s *mock_handler.MockExportService) {
+				s.EXPECT().Request(gomock.Any(), "tester").Return(entities.DataExport{}, entities.ErrDataExportBusy)
+			},
+			expectedStatusCode:  503,
+			expectedRequestBody: `{"error":"too many exports are being prepared, try again later"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			exports := mock_handler.NewMockExportService(ctrl)
+			exportHandler := NewExportHandler(exports)
+
+			r := chi.NewRouter()
+			r.Get("/users/me/export", exportHandler.RequestExport)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("GET", "/users/me/export", nil)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(exports)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestExportHandler_DownloadExport(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockExportService)
+
+	export := entities.DataExport{
+		ID: "e1", Username: "tester", Status: entities.DataExportReady,
+		RequestedAt: time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC), Size: 3,
+	}
+
+	testTable := []struct {
+		name                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedHeaders     map[string]string
+		expectedRequestBody string
+	}{
+		{
+			name: "ok",
+			mockBehavior: func(s *mock_handler.MockExportService) {
+				s.EXPECT().Open(gomock.Any(), "tester").Return(export, io.NopCloser(strings.NewReader("zip")), nil)
+			},
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type":        "application/zip",
+				"Content-Length":      "3",
+				"Content-Disposition": `attachment; filename=chat-export-20261019.zip`,
+			},
+			expectedRequestBody: "zip",
+		},
+		{
+			name: "not_ready",
+			mockBehavior: func(s *mock_handler.MockExportService) {
+				s.EXPECT().Open(gomock.Any(), "tester").Return(entities.DataExport{}, nil, entities.ErrDataExportNotReady)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"error":"the export of your data is not ready yet"}`,
+		},
+		{
+			name: "not_requested",
+			mockBehavior: func(s *mock_handler.MockExportService) {
+				s.EXPECT().Open(gomock.Any(), "tester").Return(entities.DataExport{}, nil, entities.ErrDataExportNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"no export of your data, request one first"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			exports := mock_handler.NewMockExportService(ctrl)
+			exportHandler := NewExportHandler(exports)
+
+			r := chi.NewRouter()
+			r.Get("/users/me/export/download", exportHandler.DownloadExport)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("GET", "/users/me/export/download", nil)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(exports)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			for key, val := range testCase.expectedHeaders {
+				assert.Equal(t, val, w.Header().Get(key))
+			}
+			assert.Equal(t, testCase.expectedRequestBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+// The export routes share their prefix with the avatar ones.
+func TestExportHandler_ExportRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exports := mock_handler.NewMockExportService(ctrl)
+	exports.EXPECT().Request(gomock.Any(), "tester").Return(entities.DataExport{Status: entities.DataExportPending}, nil)
+
+	avatars := mock_handler.NewMockAvatarService(ctrl)
+	avatars.EXPECT().Open(gomock.Any(), "tester", 0).Return(entities.Avatar{ContentType: "image/png"}, io.NopCloser(strings.NewReader("png")), nil)
+
+	sender := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "Sender", "tester")))
+		})
+	}
+
+	r := chi.NewRouter()
+	NewAvatarHandler(avatars, 3600).AvatarRoutes(r, sender)
+	NewExportHandler(exports).ExportRoutes(r, sender)
+
+	for target, status := range map[string]int{"/v1/users/me/export": 202, "/v1/users/me/avatar": 200} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, status, w.Code, target)
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	databaseAvailable = "database is available"
+	poolStatsReceived = "pool stats received"
+)
+
+//go:generate mockgen -source=health_handler.go -destination=mocks/health_service_mock.go
+
+type HealthService interface {
+	Check(ctx context.Context) error
+	PoolStats() (entities.PoolStats, error)
+}
+
+type HealthHandler struct {
+	service HealthService
+}
+
+func NewHealthHandler(s HealthService) *HealthHandler {
+	return &HealthHandler{service: s}
+}
+
+// HealthRoutes registers the health check, which needs no authentication.
+func (h *HealthHandler) HealthRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/health", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.Health)
+	})
+}
+
+// PoolStatsRoutes registers the admin API. The middlewares have to include
+// the admin guard.
+func (h *HealthHandler) PoolStatsRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/admin/db/stats", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ShowPoolStats)
+	})
+}
+
+// Health @summary		Проверка работоспособности
+//
+//	@description	Проверяет, что база данных отвечает. Подходит для проверок балансировщика и оркестратора.
+//	@tags			health
+//	@produce		json
+//
+//	@success		200	{object}	response.HealthResponse		"База данных доступна"
+//	@failure		503	{object}	baseresponse.ResponseError	"База данных недоступна"
+//	@router			/v1/health [get]
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Check(r.Context()); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.HealthResponse{Response: databaseAvailable})
+}
+
+// ShowPoolStats @summary		Статистика пула соединений
+//
+//	@description	Возвращает состояние пула соединений с базой данных: открытые, занятые и простаивающие соединения, ожидания и закрытые по лимитам. Доступно только администраторам.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.PoolStatsResponse	"Статистика получена"
+//	@failure		403	{object}	baseresponse.ResponseError	"Недостаточно прав"
+//	@failure		404	{object}	baseresponse.ResponseError	"У базы данных нет пула соединений"
+//	@router			/v1/admin/db/stats [get]
+func (h *HealthHandler) ShowPoolStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.PoolStats()
+	if errors.Is(err, entities.ErrNoConnectionPool) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.PoolStatsToResponse(poolStatsReceived, stats))
+}
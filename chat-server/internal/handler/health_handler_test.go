@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler_Health(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockHealthService)
+
+	testTable := []struct {
+		name                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "ok",
+			mockBehavior: func(s *mock_handler.MockHealthService) {
+				s.EXPECT().Check(gomock.Any()).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"database is available"}`,
+		},
+		{
+			name: "unavailable",
+			mockBehavior: func(s *mock_handler.MockHealthService) {
+				s.EXPECT().Check(gomock.Any()).Return(errors.New("connection refused"))
+			},
+			expectedStatusCode:  503,
+			expectedRequestBody: `{"error":"connection refused"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			health := mock_handler.NewMockHealthService(ctrl)
+			healthHandler := NewHealthHandler(health)
+
+			r := chi.NewRouter()
+			r.Get("/health", healthHandler.Health)
+
+			// Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/health", nil)
+
+			testCase.mockBehavior(health)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestHealthHandler_ShowPoolStats(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockHealthService)
+
+	testTable := []struct {
+		name                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "ok",
+			mockBehavior: func(s *mock_handler.MockHealthService) {
+				s.EXPECT().PoolStats().Return(entities.PoolStats{
+					MaxOpenConnections: 20, OpenConnections: 5, InUse: 2, Idle: 3, WaitCount: 4, WaitDuration: 1500 * time.Millisecond,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"response":"pool stats received","max_open_connections":20,"open_connections":5,"in_use":2,"idle":3,` +
+				`"wait_count":4,"wait_duration_ms":1500,"max_idle_closed":0,"max_idle_time_closed":0,"max_lifetime_closed":0}`,
+		},
+		{
+			name: "no_pool",
+			mockBehavior: func(s *mock_handler.MockHealthService) {
+				s.EXPECT().PoolStats().Return(entities.PoolStats{}, entities.ErrNoConnectionPool)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"the database has no connection pool"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			health := mock_handler.NewMockHealthService(ctrl)
+			healthHandler := NewHealthHandler(health)
+
+			r := chi.NewRouter()
+			r.Get("/stats", healthHandler.ShowPoolStats)
+
+			// Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/stats", nil)
+
+			testCase.mockBehavior(health)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
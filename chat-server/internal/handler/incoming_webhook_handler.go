@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+	"github.com/vavelour/chat/pkg/slackfmt"
+)
+
+const (
+	incomingWebhookCreated   = "incoming webhook created"
+	incomingWebhooksReceived = "incoming webhooks received"
+	incomingWebhookRevoked   = "incoming webhook revoked"
+	hookPath                 = "/v1/hooks/"
+	maxHookBody              = 1 << 20
+
+	// Slack answers incoming webhooks with plain text codes, tools check
+	// for them.
+	hookOK            = "ok"
+	hookNoService     = "no_service"
+	hookInternalError = "internal_error"
+)
+
+//go:generate mockgen -source=incoming_webhook_handler.go -destination=mocks/incoming_webhook_service_mock.go
+
+type IncomingWebhookService interface {
+	Create(w entities.IncomingWebhook) (entities.IncomingWebhook, error)
+	List() ([]entities.IncomingWebhook, error)
+	Revoke(id int) error
+	Post(token string, p slackfmt.Payload) error
+}
+
+type IncomingWebhookHandler struct {
+	service  IncomingWebhookService
+	validate *validator.Validate
+}
+
+func NewIncomingWebhookHandler(s IncomingWebhookService, v *validator.Validate) *IncomingWebhookHandler {
+	return &IncomingWebhookHandler{service: s, validate: v}
+}
+
+// IncomingWebhookRoutes registers the admin API. The middlewares have to
+// include the admin guard.
+func (h *IncomingWebhookHandler) IncomingWebhookRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/admin/incoming-webhooks", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ListIncomingWebhooks)
+		r.Post("/", h.CreateIncomingWebhook)
+		r.Delete("/{id}", h.RevokeIncomingWebhook)
+	})
+}
+
+// HookRoutes registers the URL the webhooks post to. The token in it is the
+// only authentication.
+func (h *IncomingWebhookHandler) HookRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/hooks", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Post("/{token}", h.PostHook)
+	})
+}
+
+// CreateIncomingWebhook @summary		Создание входящего вебхука
+//
+//	@description	Создает URL, через который внешние инструменты пишут в публичный чат от имени бота в формате входящих вебхуков Slack. URL показывается один раз.
+//	@tags			admin
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.CreateIncomingWebhookRequest	true	"Параметры вебхука"
+//	@success		201			{object}	response.CreateIncomingWebhookResponse	"Вебхук создан"
+//	@failure		400			{object}	baseresponse.ResponseError				"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError				"Доступно только администраторам"
+//	@failure		409			{object}	baseresponse.ResponseError				"Имя бота занято пользователем"
+//	@router			/v1/admin/incoming-webhooks [post]
+func (h *IncomingWebhookHandler) CreateIncomingWebhook(w http.ResponseWriter, r *http.Request) {
+	var input request.CreateIncomingWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	admin, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.CreatedBy = admin
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	webhook, err := h.service.Create(mapper.CreateIncomingWebhookRequestToEntity(input))
+	if errors.Is(err, entities.ErrBotNameTaken) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	render.JSON(w, r, response.CreateIncomingWebhookResponse{
+		Response: incomingWebhookCreated,
+		Webhook:  mapper.IncomingWebhookToResponse(webhook),
+		URL:      hookPath + webhook.Token,
+	})
+}
+
+// ListIncomingWebhooks @summary		Список входящих вебхуков
+//
+//	@description	Возвращает входящие вебхуки с их ботами и временем последнего использования. Токены не возвращаются.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.ListIncomingWebhooksResponse	"Вебхуки получены"
+//	@failure		403	{object}	baseresponse.ResponseError				"Доступно только администраторам"
+//	@router			/v1/admin/incoming-webhooks [get]
+func (h *IncomingWebhookHandler) ListIncomingWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.List()
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.IncomingWebhooksToResponse(incomingWebhooksReceived, webhooks))
+}
+
+// RevokeIncomingWebhook @summary		Отзыв входящего вебхука
+//
+//	@description	Отзывает вебхук: его URL перестает работать. Бот и его сообщения остаются.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		int							true	"Идентификатор вебхука"
+//	@success		200	{object}	response.WebhookResponse	"Вебхук отозван"
+//	@failure		404	{object}	baseresponse.ResponseError	"Вебхук не найден"
+//	@router			/v1/admin/incoming-webhooks/{id} [delete]
+func (h *IncomingWebhookHandler) RevokeIncomingWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidWebhookID)
+		return
+	}
+
+	err = h.service.Revoke(id)
+	if errors.Is(err, entities.ErrIncomingWebhookNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.WebhookResponse{Response: incomingWebhookRevoked})
+}
+
+// PostHook @summary		Сообщение через входящий вебхук
+//
+//	@description	Принимает сообщение в формате Slack: JSON {"text": ..., "username": ...} или форму с полем payload. Разметка Slack переводится в обычный текст, username игнорируется. Отвечает текстом, как Slack.
+//	@tags			hooks
+//	@accept			json
+//	@produce		plain
+//
+//	@param			token		path		string				true	"Токен вебхука"
+//	@param			requestBody	body		slackfmt.Payload	true	"Сообщение"
+//	@success		200			{string}	string				"ok"
+//	@failure		400			{string}	string				"invalid_payload или no_text"
+//	@failure		404			{string}	string				"no_service"
+//	@router			/v1/hooks/{token} [post]
+func (h *IncomingWebhookHandler) PostHook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHookBody))
+	if err != nil {
+		hookResponse(w, http.StatusBadRequest, slackfmt.ErrInvalidPayload.Error())
+		return
+	}
+
+	payload, err := slackfmt.Decode(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		hookResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.service.Post(chi.URLParam(r, "token"), payload)
+	switch {
+	case err == nil:
+		hookResponse(w, http.StatusOK, hookOK)
+	case errors.Is(err, entities.ErrIncomingWebhookNotFound):
+		hookResponse(w, http.StatusNotFound, hookNoService)
+	case errors.Is(err, entities.ErrNoText):
+		hookResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		// The route goes without the request logger, which would write the
+		// token down, so the error is logged here.
+		logrus.WithError(err).Error("Incoming webhook failed.")
+		hookResponse(w, http.StatusInternalServerError, hookInternalError)
+	}
+}
+
+func hookResponse(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(text))
+}
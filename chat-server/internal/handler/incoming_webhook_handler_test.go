@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"github.com/vavelour/chat/pkg/slackfmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIncomingWebhookHandler_CreateIncomingWebhook(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockIncomingWebhookService)
+
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"name": "CI", "bot_name": "ci-bot"}`,
+			mockBehavior: func(s *mock_handler.MockIncomingWebhookService) {
+				s.EXPECT().Create(entities.IncomingWebhook{Name: "CI", BotName: "ci-bot", CreatedBy: "tester"}).Return(entities.IncomingWebhook{
+					ID: 1, Name: "CI", Channel: "public", BotName: "ci-bot", Token: "secret-token", CreatedBy: "tester", CreatedAt: createdAt,
+				}, nil)
+			},
+			expectedStatusCode: 201,
+			expectedRequestBody: `{"response":"incoming webhook created","webhook":{"id":1,"name":"CI","channel":"public","bot_name":"ci-bot",` +
+				`"created_by":"tester","created_at":"2026-10-19T09:00:00Z"},"url":"/v1/hooks/secret-token"}`,
+		},
+		{
+			name:                "invalid_bot_name",
+			inputBody:           `{"name": "CI", "bot_name": "ci bot"}`,
+			mockBehavior:        func(s *mock_handler.MockIncomingWebhookService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"bot name may contain only letters, digits, '_', '.' and '-'"}`,
+		},
+		{
+			name:      "bot_name_taken",
+			inputBody: `{"name": "CI", "bot_name": "valera"}`,
+			mockBehavior: func(s *mock_handler.MockIncomingWebhookService) {
+				s.EXPECT().Create(entities.IncomingWebhook{Name: "CI", BotName: "valera", CreatedBy: "tester"}).Return(entities.IncomingWebhook{}, entities.ErrBotNameTaken)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"error":"bot name is taken by a user"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			webhooks := mock_handler.NewMockIncomingWebhookService(ctrl)
+			incomingHandler := NewIncomingWebhookHandler(webhooks, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/incoming-webhooks", incomingHandler.CreateIncomingWebhook)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("POST", "/incoming-webhooks", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(webhooks)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestIncomingWebhookHandler_PostHook(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockIncomingWebhookService)
+
+	testTable := []struct {
+		name                string
+		contentType         string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			inputBody:   `{"text": "deploy finished", "username": "deployer"}`,
+			mockBehavior: func(s *mock_handler.MockIncomingWebhookService) {
+				s.EXPECT().Post("abc", slackfmt.Payload{Text: "deploy finished", Username: "deployer"}).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `ok`,
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			inputBody:   `payload=%7B%22text%22%3A%22hi%22%7D`,
+			mockBehavior: func(s *mock_handler.MockIncomingWebhookService) {
+				s.EXPECT().Post("abc", slackfmt.Payload{Text: "hi"}).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `ok`,
+		},
+		{
+			name:                "invalid_payload",
+			contentType:         "application/json",
+			inputBody:           `text=hi`,
+			mockBehavior:        func(s *mock_handler.MockIncomingWebhookService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `invalid_payload`,
+		},
+		{
+			name:        "revoked",
+			contentType: "application/json",
+			inputBody:   `{"text": "hi"}`,
+			mockBehavior: func(s *mock_handler.MockIncomingWebhookService) {
+				s.EXPECT().Post("abc", slackfmt.Payload{Text: "hi"}).Return(entities.ErrIncomingWebhookNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `no_service`,
+		},
+		{
+			name:        "no_text",
+			contentType: "application/json",
+			inputBody:   `{"text": ""}`,
+			mockBehavior: func(s *mock_handler.MockIncomingWebhookService) {
+				s.EXPECT().Post("abc", slackfmt.Payload{}).Return(entities.ErrNoText)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `no_text`,
+		},
+		{
+			name:        "service_error",
+			contentType: "application/json",
+			inputBody:   `{"text": "hi"}`,
+			mockBehavior: func(s *mock_handler.MockIncomingWebhookService) {
+				s.EXPECT().Post("abc", slackfmt.Payload{Text: "hi"}).Return(errors.New("storage error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `internal_error`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			webhooks := mock_handler.NewMockIncomingWebhookService(ctrl)
+			incomingHandler := NewIncomingWebhookHandler(webhooks, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/hooks/{token}", incomingHandler.PostHook)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest("POST", "/hooks/abc", bytes.NewBufferString(testCase.inputBody))
+			req.Header.Set("Content-Type", testCase.contentType)
+
+			testCase.mockBehavior(webhooks)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+			assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		})
+	}
+}
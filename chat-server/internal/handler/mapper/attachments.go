@@ -0,0 +1,35 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func AttachmentEntitiesToResponse(resp string, attachments []entities.Attachment) response.SendAttachmentMessageResponse {
+	res := response.SendAttachmentMessageResponse{Response: resp, Attachments: make([]response.AttachmentItem, 0, len(attachments))}
+
+	for _, a := range attachments {
+		res.Attachments = append(res.Attachments, AttachmentEntityToResponse(a))
+	}
+
+	return res
+}
+
+func AttachmentEntityToResponse(a entities.Attachment) response.AttachmentItem {
+	return response.AttachmentItem{ID: a.ID, FileName: a.FileName, ContentType: a.ContentType, Size: a.Size}
+}
+
+func MessageAttachmentsToResponse(index int, attachments []entities.Attachment) []response.MessageAttachmentItem {
+	items := make([]response.MessageAttachmentItem, 0, len(attachments))
+
+	for _, a := range attachments {
+		items = append(items, response.MessageAttachmentItem{MessageIndex: index, AttachmentItem: AttachmentEntityToResponse(a)})
+	}
+
+	return items
+}
+
+func SendAttachmentMessageRequestToEntities(req request.SendAttachmentMessageRequest, attachments []entities.Attachment) entities.Message {
+	return entities.Message{Sender: req.Sender, Recipient: req.Recipient, Content: req.Content, Attachments: attachments}
+}
@@ -0,0 +1,19 @@
+package mapper
+
+import "net/url"
+
+// AvatarURL is where the avatar of the user is served. It does not depend on
+// whether the user has uploaded one, the endpoint answers 404 until then.
+func AvatarURL(username string) string {
+	return "/v1/users/" + url.PathEscape(username) + "/avatar"
+}
+
+func AvatarURLs(usernames []string) map[string]string {
+	urls := make(map[string]string, len(usernames))
+
+	for _, username := range usernames {
+		urls[username] = AvatarURL(username)
+	}
+
+	return urls
+}
@@ -0,0 +1,43 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func SendResultToEphemeral(res entities.SendResult) []response.EphemeralItem {
+	var items []response.EphemeralItem
+	for _, m := range res.Ephemeral {
+		items = append(items, response.EphemeralItem{Sender: m.Sender, Content: m.Content, CreatedAt: m.CreatedAt})
+	}
+
+	return items
+}
+
+func CreateBotCommandRequestToEntity(req request.CreateBotCommandRequest) entities.BotCommand {
+	return entities.BotCommand{Command: req.Command, URL: req.URL, Description: req.Description, BotName: req.BotName, CreatedBy: req.CreatedBy}
+}
+
+// BotCommandToResponse leaves the secret out, it is shown only once when
+// the command is created.
+func BotCommandToResponse(c entities.BotCommand) response.BotCommandItem {
+	return response.BotCommandItem{
+		ID:          c.ID,
+		Command:     c.Command,
+		URL:         c.URL,
+		Description: c.Description,
+		BotName:     c.BotName,
+		CreatedBy:   c.CreatedBy,
+		CreatedAt:   c.CreatedAt,
+	}
+}
+
+func BotCommandsToResponse(resp string, commands []entities.BotCommand) response.ListBotCommandsResponse {
+	res := response.ListBotCommandsResponse{Response: resp, Commands: make([]response.BotCommandItem, 0, len(commands))}
+	for _, c := range commands {
+		res.Commands = append(res.Commands, BotCommandToResponse(c))
+	}
+
+	return res
+}
@@ -0,0 +1,20 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+const dataExportURL = "/v1/users/me/export/download"
+
+func DataExportToResponse(resp string, export entities.DataExport) response.DataExportResponse {
+	res := response.DataExportResponse{Response: resp, Status: string(export.Status), RequestedAt: export.RequestedAt}
+
+	if export.Status == entities.DataExportReady {
+		res.ExpiresAt = &export.ExpiresAt
+		res.Size = export.Size
+		res.DownloadURL = dataExportURL
+	}
+
+	return res
+}
@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func CreateIncomingWebhookRequestToEntity(req request.CreateIncomingWebhookRequest) entities.IncomingWebhook {
+	return entities.IncomingWebhook{Name: req.Name, Channel: req.Channel, BotName: req.BotName, CreatedBy: req.CreatedBy}
+}
+
+func IncomingWebhookToResponse(w entities.IncomingWebhook) response.IncomingWebhookItem {
+	item := response.IncomingWebhookItem{
+		ID:        w.ID,
+		Name:      w.Name,
+		Channel:   w.Channel,
+		BotName:   w.BotName,
+		CreatedBy: w.CreatedBy,
+		CreatedAt: w.CreatedAt,
+	}
+
+	if !w.LastUsedAt.IsZero() {
+		item.LastUsedAt = &w.LastUsedAt
+	}
+
+	return item
+}
+
+func IncomingWebhooksToResponse(resp string, webhooks []entities.IncomingWebhook) response.ListIncomingWebhooksResponse {
+	res := response.ListIncomingWebhooksResponse{Response: resp, Webhooks: make([]response.IncomingWebhookItem, 0, len(webhooks))}
+	for _, w := range webhooks {
+		res.Webhooks = append(res.Webhooks, IncomingWebhookToResponse(w))
+	}
+
+	return res
+}
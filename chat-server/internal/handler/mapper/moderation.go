@@ -0,0 +1,79 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func ReportToResponse(report entities.Report) response.ReportItem {
+	return response.ReportItem{
+		ID:        report.ID,
+		MessageID: report.MessageID,
+		Reporter:  report.Reporter,
+		Reason:    report.Reason,
+		Status:    string(report.Status),
+		CreatedAt: report.CreatedAt,
+		Message: response.ReportedMessageItem{
+			Sender:    report.Message.Sender,
+			Content:   report.Message.Content,
+			CreatedAt: report.Message.CreatedAt,
+			Deleted:   report.Message.Deleted,
+		},
+		ResolvedBy: report.ResolvedBy,
+		ResolvedAt: optionalTime(report.ResolvedAt),
+	}
+}
+
+func ReportsToResponse(resp string, reports []entities.Report) response.ListReportsResponse {
+	res := response.ListReportsResponse{Response: resp, Reports: make([]response.ReportItem, 0, len(reports))}
+	for _, report := range reports {
+		res.Reports = append(res.Reports, ReportToResponse(report))
+	}
+
+	return res
+}
+
+func SanctionsToResponse(resp string, sanctions []entities.Sanction) response.ListSanctionsResponse {
+	res := response.ListSanctionsResponse{Response: resp, Sanctions: make([]response.SanctionItem, 0, len(sanctions))}
+	for _, s := range sanctions {
+		res.Sanctions = append(res.Sanctions, response.SanctionItem{
+			Username:  s.Username,
+			Kind:      string(s.Kind),
+			Reason:    s.Reason,
+			Until:     optionalTime(s.Until),
+			CreatedBy: s.CreatedBy,
+			CreatedAt: s.CreatedAt,
+		})
+	}
+
+	return res
+}
+
+func AuditLogToResponse(resp string, entries []entities.AuditEntry) response.AuditLogResponse {
+	res := response.AuditLogResponse{Response: resp, Entries: make([]response.AuditEntryItem, 0, len(entries))}
+	for _, e := range entries {
+		res.Entries = append(res.Entries, response.AuditEntryItem{
+			ID:        e.ID,
+			Moderator: e.Moderator,
+			Action:    string(e.Action),
+			Target:    e.Target,
+			MessageID: e.MessageID,
+			ReportID:  e.ReportID,
+			Reason:    e.Reason,
+			Until:     optionalTime(e.Until),
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return res
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,29 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func NotificationInboxToResponse(resp string, inbox entities.NotificationInbox) response.ShowNotificationsResponse {
+	res := response.ShowNotificationsResponse{
+		Response:      resp,
+		UnreadCount:   inbox.UnreadCount,
+		Notifications: make([]response.NotificationItem, 0, len(inbox.Notifications)),
+	}
+
+	for _, val := range inbox.Notifications {
+		res.Notifications = append(res.Notifications, response.NotificationItem{
+			ID:              val.ID,
+			Kind:            string(val.Kind),
+			Sender:          val.Sender,
+			SenderAvatarURL: AvatarURL(val.Sender),
+			MessageID:       val.MessageID,
+			Content:         val.Content,
+			Read:            val.Read,
+			CreatedAt:       val.CreatedAt,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,27 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func PresenceEntitiesToResponse(resp string, presence []entities.Presence) response.ShowPresenceResponse {
+	var res response.ShowPresenceResponse
+	res.Response = resp
+
+	for _, p := range presence {
+		item := response.PresenceItem{Username: p.Username, AvatarURL: AvatarURL(p.Username), Status: p.Status}
+		if !p.LastSeen.IsZero() {
+			lastSeen := p.LastSeen
+			item.LastSeen = &lastSeen
+		}
+
+		res.Presence = append(res.Presence, item)
+	}
+
+	return res
+}
+
+func TypingEventEntityToResponse(event entities.TypingEvent) response.TypingEventItem {
+	return response.TypingEventItem{Sender: event.Sender, SenderAvatarURL: AvatarURL(event.Sender), Recipient: event.Recipient, ExpiresAt: event.ExpiresAt}
+}
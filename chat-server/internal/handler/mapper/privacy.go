@@ -0,0 +1,27 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func UpdatePrivacySettingsRequestToEntity(req request.UpdatePrivacySettingsRequest) entities.PrivacySettings {
+	return entities.PrivacySettings{DMPolicy: entities.DMPolicy(req.DMPolicy), HideBlockedInFeed: *req.HideBlockedInFeed}
+}
+
+func PrivacySettingsToResponse(resp string, settings entities.PrivacySettings) response.PrivacySettingsResponse {
+	return response.PrivacySettingsResponse{
+		Response: resp,
+		Settings: response.PrivacySettingsItem{DMPolicy: string(settings.DMPolicy), HideBlockedInFeed: settings.HideBlockedInFeed},
+	}
+}
+
+func BlockedUsersToResponse(resp string, blocked []entities.BlockedUser) response.BlockedUsersResponse {
+	res := response.BlockedUsersResponse{Response: resp, Users: make([]response.BlockedUserItem, 0, len(blocked))}
+	for _, b := range blocked {
+		res.Users = append(res.Users, response.BlockedUserItem{Username: b.Username, CreatedAt: b.CreatedAt})
+	}
+
+	return res
+}
@@ -1,6 +1,8 @@
 package mapper
 
 import (
+	"time"
+
 	"github.com/vavelour/chat/internal/domain/entities"
 	"github.com/vavelour/chat/internal/handler/request"
 	"github.com/vavelour/chat/internal/handler/response"
@@ -10,21 +12,58 @@ func PrivateMessageEntitiesToResponse(resp string, messages []entities.Message)
 	var res response.ShowPrivateMessageResponse
 	res.Response = resp
 
-	for _, content := range messages {
+	for i, content := range messages {
 		res.Messages = append(res.Messages, content.Content)
+		res.Attachments = append(res.Attachments, MessageAttachmentsToResponse(i, content.Attachments)...)
+		if content.Expired {
+			res.Expired = append(res.Expired, i)
+		}
 	}
 
 	return res
 }
 
-func UserListEntitiesToResponse(resp string, users []string) response.ViewUserListResponse {
+func UserListEntitiesToResponse(resp string, conversations []entities.Conversation) response.ViewUserListResponse {
 	var res response.ViewUserListResponse
 	res.Response = resp
-	res.Messages = append(res.Messages, users...)
+
+	for _, c := range conversations {
+		res.Users = append(res.Users, response.UserListItem{Username: c.Partner, AvatarURL: AvatarURL(c.Partner), UnreadCount: c.UnreadCount, SeenByRecipient: c.SeenByPartner})
+	}
+
+	return res
+}
+
+func InboxEntitiesToResponse(resp string, conversations []entities.Conversation) response.ShowInboxResponse {
+	var res response.ShowInboxResponse
+	res.Response = resp
+
+	for _, c := range conversations {
+		res.Conversations = append(res.Conversations, response.ConversationItem{
+			Username:  c.Partner,
+			AvatarURL: AvatarURL(c.Partner),
+			LastMessage: response.LastMessageItem{
+				ID:              c.LastMessage.ID,
+				Sender:          c.LastMessage.Sender,
+				SenderAvatarURL: AvatarURL(c.LastMessage.Sender),
+				Content:         c.LastMessage.Content,
+				CreatedAt:       c.LastMessage.CreatedAt,
+				Expired:         c.LastMessage.Expired,
+			},
+			UnreadCount:     c.UnreadCount,
+			SeenByRecipient: c.SeenByPartner,
+		})
+	}
 
 	return res
 }
 
 func SendPrivateMessageRequestToEntities(req request.SendPrivateMessageRequest) entities.Message {
-	return entities.Message{Sender: req.Sender, Recipient: req.Recipient, Content: req.Content}
+	return entities.Message{
+		Sender:          req.Sender,
+		Recipient:       req.Recipient,
+		Content:         req.Content,
+		TTL:             time.Duration(req.TTLSeconds) * time.Second,
+		ExpireAfterRead: req.ExpireAfterRead,
+	}
 }
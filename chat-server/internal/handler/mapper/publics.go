@@ -10,8 +10,13 @@ func PublicMessageEntitiesToResponse(resp string, messages []entities.Message) r
 	var res response.ShowPublicMessageResponse
 	res.Response = resp
 
-	for _, content := range messages {
+	for i, content := range messages {
 		res.Messages = append(res.Messages, content.Content)
+		res.IDs = append(res.IDs, content.ID)
+		res.Attachments = append(res.Attachments, MessageAttachmentsToResponse(i, content.Attachments)...)
+		if content.Deleted {
+			res.Deleted = append(res.Deleted, i)
+		}
 	}
 
 	return res
@@ -0,0 +1,47 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func CreateReminderRequestToEntity(req request.CreateReminderRequest) entities.Reminder {
+	return entities.Reminder{
+		Name:      req.Name,
+		Content:   req.Content,
+		Schedule:  req.Schedule,
+		TimeZone:  req.TimeZone,
+		CreatedBy: req.CreatedBy,
+	}
+}
+
+func ReminderToResponse(rem entities.Reminder) response.ReminderItem {
+	item := response.ReminderItem{
+		ID:        rem.ID,
+		Name:      rem.Name,
+		Content:   rem.Content,
+		Schedule:  rem.Schedule,
+		TimeZone:  rem.TimeZone,
+		Sender:    rem.Sender,
+		Paused:    rem.Paused,
+		NextRunAt: rem.NextRunAt,
+		CreatedBy: rem.CreatedBy,
+		CreatedAt: rem.CreatedAt,
+	}
+
+	if !rem.LastRunAt.IsZero() {
+		item.LastRunAt = &rem.LastRunAt
+	}
+
+	return item
+}
+
+func RemindersToResponse(resp string, reminders []entities.Reminder) response.ListRemindersResponse {
+	res := response.ListRemindersResponse{Response: resp, Reminders: make([]response.ReminderItem, 0, len(reminders))}
+	for _, rem := range reminders {
+		res.Reminders = append(res.Reminders, ReminderToResponse(rem))
+	}
+
+	return res
+}
@@ -0,0 +1,15 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func LegalHoldsToResponse(resp string, holds []entities.LegalHold) response.ListLegalHoldsResponse {
+	res := response.ListLegalHoldsResponse{Response: resp, Holds: make([]response.LegalHoldItem, 0, len(holds))}
+	for _, hold := range holds {
+		res.Holds = append(res.Holds, response.LegalHoldItem{Username: hold.Username, PlacedAt: hold.PlacedAt})
+	}
+
+	return res
+}
@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func SendPublicMessageRequestToScheduled(req request.SendPublicMessageRequest) entities.ScheduledMessage {
+	return entities.ScheduledMessage{Channel: entities.PublicChannel, Sender: req.Sender, Content: req.Content, SendAt: *req.SendAt}
+}
+
+func SendPrivateMessageRequestToScheduled(req request.SendPrivateMessageRequest) entities.ScheduledMessage {
+	return entities.ScheduledMessage{Channel: entities.PrivateChannel, Sender: req.Sender, Recipient: req.Recipient, Content: req.Content, SendAt: *req.SendAt}
+}
+
+func ScheduledMessageToResponse(m entities.ScheduledMessage) response.ScheduledMessageItem {
+	return response.ScheduledMessageItem{
+		ID:        m.ID,
+		Channel:   m.Channel,
+		Recipient: m.Recipient,
+		Content:   m.Content,
+		SendAt:    m.SendAt,
+		Status:    string(m.Status),
+		LastError: m.LastError,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func ScheduledMessagesToResponse(resp string, messages []entities.ScheduledMessage) response.ListScheduledMessagesResponse {
+	res := response.ListScheduledMessagesResponse{Response: resp, Messages: make([]response.ScheduledMessageItem, 0, len(messages))}
+	for _, m := range messages {
+		res.Messages = append(res.Messages, ScheduledMessageToResponse(m))
+	}
+
+	return res
+}
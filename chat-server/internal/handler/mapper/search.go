@@ -0,0 +1,111 @@
+package mapper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+const (
+	searchDateLayout  = "2006-01-02"
+	publicChatResult  = "public"
+	privateChatResult = "private"
+)
+
+type searchCursor struct {
+	CreatedAt time.Time `json:"t"`
+	Chat      string    `json:"c"`
+	ID        int       `json:"i"`
+}
+
+// SearchMessagesRequestToQuery converts the request into a query. The To
+// date is inclusive, so the query ends at the start of the next day.
+func SearchMessagesRequestToQuery(req request.SearchMessagesRequest) (entities.SearchQuery, error) {
+	q := entities.SearchQuery{Text: req.Query, Sender: req.Sender, Limit: req.Limit}
+
+	if req.From != "" {
+		from, err := time.Parse(searchDateLayout, req.From)
+		if err != nil {
+			return entities.SearchQuery{}, err
+		}
+
+		q.From = from
+	}
+
+	if req.To != "" {
+		to, err := time.Parse(searchDateLayout, req.To)
+		if err != nil {
+			return entities.SearchQuery{}, err
+		}
+
+		q.To = to.AddDate(0, 0, 1)
+	}
+
+	if req.Cursor != "" {
+		cursor, err := DecodeSearchCursor(req.Cursor)
+		if err != nil {
+			return entities.SearchQuery{}, err
+		}
+
+		q.After = &cursor
+	}
+
+	return q, nil
+}
+
+func SearchPageToResponse(resp, user string, page entities.SearchPage) response.SearchMessagesResponse {
+	res := response.SearchMessagesResponse{Response: resp, Results: make([]response.SearchResultItem, 0, len(page.Results))}
+
+	for _, val := range page.Results {
+		item := response.SearchResultItem{
+			Chat:            publicChatResult,
+			ID:              val.Message.ID,
+			Sender:          val.Message.Sender,
+			SenderAvatarURL: AvatarURL(val.Message.Sender),
+			Snippet:         val.Snippet,
+			CreatedAt:       val.Message.CreatedAt,
+		}
+
+		if val.Message.Recipient != "" {
+			item.Chat = privateChatResult
+			item.Partner = val.Message.Recipient
+			if item.Partner == user {
+				item.Partner = val.Message.Sender
+			}
+		}
+
+		res.Results = append(res.Results, item)
+	}
+
+	if page.Next != nil {
+		res.NextCursor = EncodeSearchCursor(*page.Next)
+	}
+
+	return res
+}
+
+// EncodeSearchCursor makes an opaque token out of the cursor, safe to be
+// passed back in a query string.
+func EncodeSearchCursor(c entities.SearchCursor) string {
+	data, _ := json.Marshal(searchCursor{CreatedAt: c.CreatedAt, Chat: c.Chat, ID: c.ID})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeSearchCursor(token string) (entities.SearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return entities.SearchCursor{}, entities.ErrInvalidCursor
+	}
+
+	var c searchCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Chat == "" {
+		return entities.SearchCursor{}, entities.ErrInvalidCursor
+	}
+
+	return entities.SearchCursor{CreatedAt: c.CreatedAt, Chat: c.Chat, ID: c.ID}, nil
+}
@@ -0,0 +1,67 @@
+package mapper
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+)
+
+func CreateWebhookRequestToEntity(req request.CreateWebhookRequest) entities.Webhook {
+	w := entities.Webhook{URL: req.URL, Channel: req.Channel, Secret: req.Secret, CreatedBy: req.CreatedBy}
+	for _, val := range req.Events {
+		w.Events = append(w.Events, entities.WebhookEventType(val))
+	}
+
+	return w
+}
+
+// WebhookToResponse leaves the secret out, it is shown only once when the
+// webhook is created.
+func WebhookToResponse(w entities.Webhook) response.WebhookItem {
+	item := response.WebhookItem{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    make([]string, 0, len(w.Events)),
+		Channel:   w.Channel,
+		Active:    w.Active,
+		Failures:  w.Failures,
+		CreatedBy: w.CreatedBy,
+		CreatedAt: w.CreatedAt,
+	}
+
+	for _, val := range w.Events {
+		item.Events = append(item.Events, string(val))
+	}
+
+	return item
+}
+
+func WebhooksToResponse(resp string, webhooks []entities.Webhook) response.ListWebhooksResponse {
+	res := response.ListWebhooksResponse{Response: resp, Webhooks: make([]response.WebhookItem, 0, len(webhooks))}
+	for _, w := range webhooks {
+		res.Webhooks = append(res.Webhooks, WebhookToResponse(w))
+	}
+
+	return res
+}
+
+func DeliveriesToResponse(resp string, deliveries []entities.WebhookDelivery) response.ShowDeliveriesResponse {
+	res := response.ShowDeliveriesResponse{Response: resp, Deliveries: make([]response.DeliveryItem, 0, len(deliveries))}
+	for _, d := range deliveries {
+		res.Deliveries = append(res.Deliveries, response.DeliveryItem{
+			ID:             d.ID,
+			Event:          string(d.Event.Type),
+			EventID:        d.Event.ID,
+			MessageID:      d.Event.Message.ID,
+			Status:         string(d.Status),
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			ResponseStatus: d.ResponseStatus,
+			LastError:      d.LastError,
+			CreatedAt:      d.CreatedAt,
+			UpdatedAt:      d.UpdatedAt,
+		})
+	}
+
+	return res
+}
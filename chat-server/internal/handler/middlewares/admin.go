@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+var (
+	errAdminOnly     = errors.New("only administrators can do this")
+	errModeratorOnly = errors.New("only moderators can do this")
+)
+
+// AdminGuard lets through only the users listed as administrators in the
+// config. It has to run after the authentication middleware.
+type AdminGuard struct {
+	admins map[string]bool
+	denied error
+}
+
+func NewAdminGuard(admins []string) *AdminGuard {
+	g := &AdminGuard{admins: make(map[string]bool, len(admins)), denied: errAdminOnly}
+	for _, admin := range admins {
+		g.admins[admin] = true
+	}
+
+	return g
+}
+
+// NewModeratorGuard returns the guard that lets through the moderators and
+// the administrators, who can moderate too.
+func NewModeratorGuard(admins, moderators []string) *AdminGuard {
+	g := NewAdminGuard(append(append([]string(nil), admins...), moderators...))
+	g.denied = errModeratorOnly
+
+	return g
+}
+
+func (g *AdminGuard) IsAdmin(user string) bool {
+	return g.admins[user]
+}
+
+func (g *AdminGuard) Require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := r.Context().Value("Sender").(string)
+		if !ok || !g.admins[user] {
+			baseresponse.ReturnErrorResponse(w, r, http.StatusForbidden, g.denied)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminGuard_Require(t *testing.T) {
+	testTable := []struct {
+		name                string
+		user                string
+		withUser            bool
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "admin",
+			user:                "root",
+			withUser:            true,
+			expectedStatusCode:  200,
+			expectedRequestBody: "ok",
+		},
+		{
+			name:                "not_admin",
+			user:                "tester",
+			withUser:            true,
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"only administrators can do this"}`,
+		},
+		{
+			name:                "anonymous",
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"only administrators can do this"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			guard := NewAdminGuard([]string{"root"})
+
+			handler := guard.Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if testCase.withUser {
+				req = req.WithContext(context.WithValue(context.Background(), "Sender", testCase.user))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestModeratorGuard_Require(t *testing.T) {
+	testTable := []struct {
+		name                string
+		user                string
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "moderator",
+			user:                "mod",
+			expectedStatusCode:  200,
+			expectedRequestBody: "ok",
+		},
+		{
+			name:                "admin",
+			user:                "root",
+			expectedStatusCode:  200,
+			expectedRequestBody: "ok",
+		},
+		{
+			name:                "user",
+			user:                "tester",
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"only moderators can do this"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			guard := NewModeratorGuard([]string{"root"}, []string{"mod"})
+
+			handler := guard.Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req = req.WithContext(context.WithValue(context.Background(), "Sender", testCase.user))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
 	"github.com/vavelour/chat/internal/handler/mapper"
 
 	"github.com/vavelour/chat/internal/handler/request"
@@ -76,7 +77,7 @@ func (h *BasicUserIdentity) Identify(next http.Handler) http.Handler {
 
 		username, err := h.service.UserIdentity(mapper.BasicLogInRequestToEntities(req))
 		if err != nil {
-			baseresponse.ReturnErrorResponse(w, r, http.StatusUnauthorized, err)
+			baseresponse.ReturnErrorResponse(w, r, identityStatus(err), err)
 			return
 		}
 
@@ -84,3 +85,13 @@ func (h *BasicUserIdentity) Identify(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// identityStatus returns the status for a failed identification: the user
+// of a suspended account is known but not let in.
+func identityStatus(err error) int {
+	if errors.Is(err, entities.ErrAccountSuspended) {
+		return http.StatusForbidden
+	}
+
+	return http.StatusUnauthorized
+}
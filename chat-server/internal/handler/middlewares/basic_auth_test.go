@@ -48,6 +48,17 @@ func TestBasicUserIdentity_Identify(t *testing.T) {
 			expectedStatusCode:  401,
 			expectedRequestBody: `{"error":"incorrect login or password"}`,
 		},
+		{
+			name:        "suspended",
+			headerName:  "Authorization",
+			headerValue: "Basic dGVzdGVyOjEyMw==",
+			inputUser:   request.BasicAuthLogInRequest{Username: "tester", Password: "123"},
+			mockBehavior: func(s *mock_middlewares.MockBasicAuthService, u entities.User) {
+				s.EXPECT().UserIdentity(u).Return("", entities.ErrAccountSuspended)
+			},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"account is suspended"}`,
+		},
 		{
 			name:                "failed_header",
 			headerName:          "Nothing",
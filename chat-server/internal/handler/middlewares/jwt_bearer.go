@@ -48,7 +48,7 @@ func (h *JWTUserIdentity) Identify(next http.Handler) http.Handler {
 
 		username, err := h.service.UserIdentity(req.Token)
 		if err != nil {
-			baseresponse.ReturnErrorResponse(w, r, http.StatusUnauthorized, err)
+			baseresponse.ReturnErrorResponse(w, r, identityStatus(err), err)
 			return
 		}
 
@@ -5,6 +5,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
 	mock_middlewares "github.com/vavelour/chat/internal/handler/middlewares/mocks"
 	"net/http"
 	"net/http/httptest"
@@ -46,6 +47,17 @@ func TestJWTUserIdentity_Identify(t *testing.T) {
 			expectedStatusCode:  401,
 			expectedRequestBody: `{"error":"invalid token"}`,
 		},
+		{
+			name:        "suspended",
+			headerName:  "Authorization",
+			headerValue: "Bearer token",
+			token:       "token",
+			mockBehavior: func(s *mock_middlewares.MockJWTBearerService, token string) {
+				s.EXPECT().UserIdentity("token").Return("", entities.ErrAccountSuspended)
+			},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"account is suspended"}`,
+		},
 		{
 			name:                "empty_header",
 			headerName:          "",
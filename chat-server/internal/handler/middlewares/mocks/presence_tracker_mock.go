@@ -0,0 +1,46 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presence_tracker.go
+
+// Package mock_middlewares is a generated GoMock package.
+package mock_middlewares
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockActivityService is a mock of ActivityService interface.
+type MockActivityService struct {
+	ctrl     *gomock.Controller
+	recorder *MockActivityServiceMockRecorder
+}
+
+// MockActivityServiceMockRecorder is the mock recorder for MockActivityService.
+type MockActivityServiceMockRecorder struct {
+	mock *MockActivityService
+}
+
+// NewMockActivityService creates a new mock instance.
+func NewMockActivityService(ctrl *gomock.Controller) *MockActivityService {
+	mock := &MockActivityService{ctrl: ctrl}
+	mock.recorder = &MockActivityServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockActivityService) EXPECT() *MockActivityServiceMockRecorder {
+	return m.recorder
+}
+
+// Touch mocks base method.
+func (m *MockActivityService) Touch(user string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Touch", user)
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockActivityServiceMockRecorder) Touch(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockActivityService)(nil).Touch), user)
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import "net/http"
+
+//go:generate mockgen -source=presence_tracker.go -destination=mocks/presence_tracker_mock.go
+
+type ActivityService interface {
+	Touch(user string)
+}
+
+type PresenceTracker struct {
+	service ActivityService
+}
+
+func NewPresenceTracker(s ActivityService) *PresenceTracker {
+	return &PresenceTracker{service: s}
+}
+
+func (t *PresenceTracker) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, ok := r.Context().Value("Sender").(string); ok {
+			t.service.Touch(user)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
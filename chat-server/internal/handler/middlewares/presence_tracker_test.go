@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	mock_middlewares "github.com/vavelour/chat/internal/handler/middlewares/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPresenceTracker_Track(t *testing.T) {
+	type mockBehavior func(s *mock_middlewares.MockActivityService, user string)
+
+	testTable := []struct {
+		name               string
+		user               string
+		withUser           bool
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:     "ok",
+			user:     "tester",
+			withUser: true,
+			mockBehavior: func(s *mock_middlewares.MockActivityService, user string) {
+				s.EXPECT().Touch(user)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "anonymous",
+			withUser:           false,
+			mockBehavior:       func(s *mock_middlewares.MockActivityService, user string) {},
+			expectedStatusCode: 200,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			activity := mock_middlewares.NewMockActivityService(ctrl)
+			testCase.mockBehavior(activity, testCase.user)
+
+			tracker := NewPresenceTracker(activity)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if testCase.withUser {
+				req = req.WithContext(context.WithValue(req.Context(), "Sender", testCase.user))
+			}
+
+			w := httptest.NewRecorder()
+
+			dummyHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			tracker.Track(dummyHandler).ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: attachment_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockAttachmentService is a mock of AttachmentService interface.
+type MockAttachmentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentServiceMockRecorder
+}
+
+// MockAttachmentServiceMockRecorder is the mock recorder for MockAttachmentService.
+type MockAttachmentServiceMockRecorder struct {
+	mock *MockAttachmentService
+}
+
+// NewMockAttachmentService creates a new mock instance.
+func NewMockAttachmentService(ctrl *gomock.Controller) *MockAttachmentService {
+	mock := &MockAttachmentService{ctrl: ctrl}
+	mock.recorder = &MockAttachmentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentService) EXPECT() *MockAttachmentServiceMockRecorder {
+	return m.recorder
+}
+
+// Discard mocks base method.
+func (m *MockAttachmentService) Discard(ctx context.Context, attachments []entities.Attachment) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Discard", ctx, attachments)
+}
+
+// Discard indicates an expected call of Discard.
+func (mr *MockAttachmentServiceMockRecorder) Discard(ctx, attachments interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discard", reflect.TypeOf((*MockAttachmentService)(nil).Discard), ctx, attachments)
+}
+
+// Open mocks base method.
+func (m *MockAttachmentService) Open(ctx context.Context, user, id string) (entities.Attachment, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, user, id)
+	ret0, _ := ret[0].(entities.Attachment)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockAttachmentServiceMockRecorder) Open(ctx, user, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockAttachmentService)(nil).Open), ctx, user, id)
+}
+
+// SendMessage mocks base method.
+func (m_2 *MockAttachmentService) SendMessage(ctx context.Context, m entities.Message) (entities.SendResult, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMessage", ctx, m)
+	ret0, _ := ret[0].(entities.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockAttachmentServiceMockRecorder) SendMessage(ctx, m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockAttachmentService)(nil).SendMessage), ctx, m)
+}
+
+// Upload mocks base method.
+func (m *MockAttachmentService) Upload(ctx context.Context, fileName string, r io.Reader) (entities.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", ctx, fileName, r)
+	ret0, _ := ret[0].(entities.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockAttachmentServiceMockRecorder) Upload(ctx, fileName, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockAttachmentService)(nil).Upload), ctx, fileName, r)
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: avatar_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockAvatarService is a mock of AvatarService interface.
+type MockAvatarService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAvatarServiceMockRecorder
+}
+
+// MockAvatarServiceMockRecorder is the mock recorder for MockAvatarService.
+type MockAvatarServiceMockRecorder struct {
+	mock *MockAvatarService
+}
+
+// NewMockAvatarService creates a new mock instance.
+func NewMockAvatarService(ctrl *gomock.Controller) *MockAvatarService {
+	mock := &MockAvatarService{ctrl: ctrl}
+	mock.recorder = &MockAvatarServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAvatarService) EXPECT() *MockAvatarServiceMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockAvatarService) Delete(ctx context.Context, user string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAvatarServiceMockRecorder) Delete(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAvatarService)(nil).Delete), ctx, user)
+}
+
+// Open mocks base method.
+func (m *MockAvatarService) Open(ctx context.Context, username string, size int) (entities.Avatar, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, username, size)
+	ret0, _ := ret[0].(entities.Avatar)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockAvatarServiceMockRecorder) Open(ctx, username, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockAvatarService)(nil).Open), ctx, username, size)
+}
+
+// Upload mocks base method.
+func (m *MockAvatarService) Upload(user string, r io.Reader) (entities.Avatar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", user, r)
+	ret0, _ := ret[0].(entities.Avatar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockAvatarServiceMockRecorder) Upload(user, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockAvatarService)(nil).Upload), user, r)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: bot_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockBotService is a mock of BotService interface.
+type MockBotService struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotServiceMockRecorder
+}
+
+// MockBotServiceMockRecorder is the mock recorder for MockBotService.
+type MockBotServiceMockRecorder struct {
+	mock *MockBotService
+}
+
+// NewMockBotService creates a new mock instance.
+func NewMockBotService(ctrl *gomock.Controller) *MockBotService {
+	mock := &MockBotService{ctrl: ctrl}
+	mock.recorder = &MockBotServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotService) EXPECT() *MockBotServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockBotService) Create(c entities.BotCommand) (entities.BotCommand, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", c)
+	ret0, _ := ret[0].(entities.BotCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockBotServiceMockRecorder) Create(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBotService)(nil).Create), c)
+}
+
+// Delete mocks base method.
+func (m *MockBotService) Delete(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockBotServiceMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBotService)(nil).Delete), id)
+}
+
+// List mocks base method.
+func (m *MockBotService) List() ([]entities.BotCommand, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]entities.BotCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockBotServiceMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockBotService)(nil).List))
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: incoming_webhook_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+	slackfmt "github.com/vavelour/chat/pkg/slackfmt"
+)
+
+// MockIncomingWebhookService is a mock of IncomingWebhookService interface.
+type MockIncomingWebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIncomingWebhookServiceMockRecorder
+}
+
+// MockIncomingWebhookServiceMockRecorder is the mock recorder for MockIncomingWebhookService.
+type MockIncomingWebhookServiceMockRecorder struct {
+	mock *MockIncomingWebhookService
+}
+
+// NewMockIncomingWebhookService creates a new mock instance.
+func NewMockIncomingWebhookService(ctrl *gomock.Controller) *MockIncomingWebhookService {
+	mock := &MockIncomingWebhookService{ctrl: ctrl}
+	mock.recorder = &MockIncomingWebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIncomingWebhookService) EXPECT() *MockIncomingWebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIncomingWebhookService) Create(w entities.IncomingWebhook) (entities.IncomingWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", w)
+	ret0, _ := ret[0].(entities.IncomingWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIncomingWebhookServiceMockRecorder) Create(w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIncomingWebhookService)(nil).Create), w)
+}
+
+// List mocks base method.
+func (m *MockIncomingWebhookService) List() ([]entities.IncomingWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]entities.IncomingWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIncomingWebhookServiceMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIncomingWebhookService)(nil).List))
+}
+
+// Post mocks base method.
+func (m *MockIncomingWebhookService) Post(token string, p slackfmt.Payload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Post", token, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Post indicates an expected call of Post.
+func (mr *MockIncomingWebhookServiceMockRecorder) Post(token, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockIncomingWebhookService)(nil).Post), token, p)
+}
+
+// Revoke mocks base method.
+func (m *MockIncomingWebhookService) Revoke(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockIncomingWebhookServiceMockRecorder) Revoke(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockIncomingWebhookService)(nil).Revoke), id)
+}
@@ -0,0 +1,169 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: moderation_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockModerationService is a mock of ModerationService interface.
+type MockModerationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationServiceMockRecorder
+}
+
+// MockModerationServiceMockRecorder is the mock recorder for MockModerationService.
+type MockModerationServiceMockRecorder struct {
+	mock *MockModerationService
+}
+
+// NewMockModerationService creates a new mock instance.
+func NewMockModerationService(ctrl *gomock.Controller) *MockModerationService {
+	mock := &MockModerationService{ctrl: ctrl}
+	mock.recorder = &MockModerationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationService) EXPECT() *MockModerationServiceMockRecorder {
+	return m.recorder
+}
+
+// AuditLog mocks base method.
+func (m *MockModerationService) AuditLog(limit, offset int) ([]entities.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", limit, offset)
+	ret0, _ := ret[0].([]entities.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockModerationServiceMockRecorder) AuditLog(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockModerationService)(nil).AuditLog), limit, offset)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockModerationService) DeleteMessage(ctx context.Context, moderator string, id int, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", ctx, moderator, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockModerationServiceMockRecorder) DeleteMessage(ctx, moderator, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockModerationService)(nil).DeleteMessage), ctx, moderator, id, reason)
+}
+
+// DismissReport mocks base method.
+func (m *MockModerationService) DismissReport(moderator string, id int, reason string) (entities.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DismissReport", moderator, id, reason)
+	ret0, _ := ret[0].(entities.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DismissReport indicates an expected call of DismissReport.
+func (mr *MockModerationServiceMockRecorder) DismissReport(moderator, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DismissReport", reflect.TypeOf((*MockModerationService)(nil).DismissReport), moderator, id, reason)
+}
+
+// Lift mocks base method.
+func (m *MockModerationService) Lift(moderator, username string, kind entities.SanctionKind, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lift", moderator, username, kind, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lift indicates an expected call of Lift.
+func (mr *MockModerationServiceMockRecorder) Lift(moderator, username, kind, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lift", reflect.TypeOf((*MockModerationService)(nil).Lift), moderator, username, kind, reason)
+}
+
+// Report mocks base method.
+func (m *MockModerationService) Report(reporter string, messageID int, reason string) (entities.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", reporter, messageID, reason)
+	ret0, _ := ret[0].(entities.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockModerationServiceMockRecorder) Report(reporter, messageID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockModerationService)(nil).Report), reporter, messageID, reason)
+}
+
+// Reports mocks base method.
+func (m *MockModerationService) Reports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reports", status, limit, offset)
+	ret0, _ := ret[0].([]entities.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reports indicates an expected call of Reports.
+func (mr *MockModerationServiceMockRecorder) Reports(status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reports", reflect.TypeOf((*MockModerationService)(nil).Reports), status, limit, offset)
+}
+
+// ResolveReport mocks base method.
+func (m *MockModerationService) ResolveReport(moderator string, id int, reason string) (entities.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveReport", moderator, id, reason)
+	ret0, _ := ret[0].(entities.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveReport indicates an expected call of ResolveReport.
+func (mr *MockModerationServiceMockRecorder) ResolveReport(moderator, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveReport", reflect.TypeOf((*MockModerationService)(nil).ResolveReport), moderator, id, reason)
+}
+
+// Sanction mocks base method.
+func (m *MockModerationService) Sanction(moderator, username string, kind entities.SanctionKind, duration time.Duration, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sanction", moderator, username, kind, duration, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sanction indicates an expected call of Sanction.
+func (mr *MockModerationServiceMockRecorder) Sanction(moderator, username, kind, duration, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sanction", reflect.TypeOf((*MockModerationService)(nil).Sanction), moderator, username, kind, duration, reason)
+}
+
+// Sanctions mocks base method.
+func (m *MockModerationService) Sanctions() ([]entities.Sanction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sanctions")
+	ret0, _ := ret[0].([]entities.Sanction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sanctions indicates an expected call of Sanctions.
+func (mr *MockModerationServiceMockRecorder) Sanctions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sanctions", reflect.TypeOf((*MockModerationService)(nil).Sanctions))
+}
@@ -0,0 +1,91 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presence_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockPresenceService is a mock of PresenceService interface.
+type MockPresenceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceServiceMockRecorder
+}
+
+// MockPresenceServiceMockRecorder is the mock recorder for MockPresenceService.
+type MockPresenceServiceMockRecorder struct {
+	mock *MockPresenceService
+}
+
+// NewMockPresenceService creates a new mock instance.
+func NewMockPresenceService(ctrl *gomock.Controller) *MockPresenceService {
+	mock := &MockPresenceService{ctrl: ctrl}
+	mock.recorder = &MockPresenceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceService) EXPECT() *MockPresenceServiceMockRecorder {
+	return m.recorder
+}
+
+// Connect mocks base method.
+func (m *MockPresenceService) Connect(user string) (<-chan entities.TypingEvent, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Connect", user)
+	ret0, _ := ret[0].(<-chan entities.TypingEvent)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Connect indicates an expected call of Connect.
+func (mr *MockPresenceServiceMockRecorder) Connect(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connect", reflect.TypeOf((*MockPresenceService)(nil).Connect), user)
+}
+
+// GetPresence mocks base method.
+func (m *MockPresenceService) GetPresence(usernames []string) ([]entities.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPresence", usernames)
+	ret0, _ := ret[0].([]entities.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPresence indicates an expected call of GetPresence.
+func (mr *MockPresenceServiceMockRecorder) GetPresence(usernames interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPresence", reflect.TypeOf((*MockPresenceService)(nil).GetPresence), usernames)
+}
+
+// GetTyping mocks base method.
+func (m *MockPresenceService) GetTyping(user, partner string) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTyping", user, partner)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetTyping indicates an expected call of GetTyping.
+func (mr *MockPresenceServiceMockRecorder) GetTyping(user, partner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTyping", reflect.TypeOf((*MockPresenceService)(nil).GetTyping), user, partner)
+}
+
+// SetTyping mocks base method.
+func (m *MockPresenceService) SetTyping(user, recipient string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTyping", user, recipient)
+}
+
+// SetTyping indicates an expected call of SetTyping.
+func (mr *MockPresenceServiceMockRecorder) SetTyping(user, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTyping", reflect.TypeOf((*MockPresenceService)(nil).SetTyping), user, recipient)
+}
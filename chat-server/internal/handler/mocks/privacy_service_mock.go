@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: privacy_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockPrivacyService is a mock of PrivacyService interface.
+type MockPrivacyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrivacyServiceMockRecorder
+}
+
+// MockPrivacyServiceMockRecorder is the mock recorder for MockPrivacyService.
+type MockPrivacyServiceMockRecorder struct {
+	mock *MockPrivacyService
+}
+
+// NewMockPrivacyService creates a new mock instance.
+func NewMockPrivacyService(ctrl *gomock.Controller) *MockPrivacyService {
+	mock := &MockPrivacyService{ctrl: ctrl}
+	mock.recorder = &MockPrivacyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrivacyService) EXPECT() *MockPrivacyServiceMockRecorder {
+	return m.recorder
+}
+
+// Block mocks base method.
+func (m *MockPrivacyService) Block(user, blocked string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Block", user, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Block indicates an expected call of Block.
+func (mr *MockPrivacyServiceMockRecorder) Block(user, blocked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockPrivacyService)(nil).Block), user, blocked)
+}
+
+// GetBlocked mocks base method.
+func (m *MockPrivacyService) GetBlocked(user string) ([]entities.BlockedUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocked", user)
+	ret0, _ := ret[0].([]entities.BlockedUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocked indicates an expected call of GetBlocked.
+func (mr *MockPrivacyServiceMockRecorder) GetBlocked(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocked", reflect.TypeOf((*MockPrivacyService)(nil).GetBlocked), user)
+}
+
+// GetSettings mocks base method.
+func (m *MockPrivacyService) GetSettings(user string) (entities.PrivacySettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", user)
+	ret0, _ := ret[0].(entities.PrivacySettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MockPrivacyServiceMockRecorder) GetSettings(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockPrivacyService)(nil).GetSettings), user)
+}
+
+// Unblock mocks base method.
+func (m *MockPrivacyService) Unblock(user, blocked string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unblock", user, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unblock indicates an expected call of Unblock.
+func (mr *MockPrivacyServiceMockRecorder) Unblock(user, blocked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockPrivacyService)(nil).Unblock), user, blocked)
+}
+
+// UpdateSettings mocks base method.
+func (m *MockPrivacyService) UpdateSettings(user string, settings entities.PrivacySettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", user, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings.
+func (mr *MockPrivacyServiceMockRecorder) UpdateSettings(user, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockPrivacyService)(nil).UpdateSettings), user, settings)
+}
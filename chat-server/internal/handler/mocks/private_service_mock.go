@@ -34,27 +34,100 @@ func (m *MockPrivateService) EXPECT() *MockPrivateServiceMockRecorder {
 	return m.recorder
 }
 
+// AcceptMessageRequest mocks base method.
+func (m *MockPrivateService) AcceptMessageRequest(user, partner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptMessageRequest", user, partner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcceptMessageRequest indicates an expected call of AcceptMessageRequest.
+func (mr *MockPrivateServiceMockRecorder) AcceptMessageRequest(user, partner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptMessageRequest", reflect.TypeOf((*MockPrivateService)(nil).AcceptMessageRequest), user, partner)
+}
+
+// DeclineMessageRequest mocks base method.
+func (m *MockPrivateService) DeclineMessageRequest(user, partner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineMessageRequest", user, partner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeclineMessageRequest indicates an expected call of DeclineMessageRequest.
+func (mr *MockPrivateServiceMockRecorder) DeclineMessageRequest(user, partner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineMessageRequest", reflect.TypeOf((*MockPrivateService)(nil).DeclineMessageRequest), user, partner)
+}
+
+// GetInbox mocks base method.
+func (m *MockPrivateService) GetInbox(user string, limit, offset int) ([]entities.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInbox", user, limit, offset)
+	ret0, _ := ret[0].([]entities.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInbox indicates an expected call of GetInbox.
+func (mr *MockPrivateServiceMockRecorder) GetInbox(user, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInbox", reflect.TypeOf((*MockPrivateService)(nil).GetInbox), user, limit, offset)
+}
+
+// GetMessageRequests mocks base method.
+func (m *MockPrivateService) GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessageRequests", user, limit, offset)
+	ret0, _ := ret[0].([]entities.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessageRequests indicates an expected call of GetMessageRequests.
+func (mr *MockPrivateServiceMockRecorder) GetMessageRequests(user, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessageRequests", reflect.TypeOf((*MockPrivateService)(nil).GetMessageRequests), user, limit, offset)
+}
+
 // GetPrivateMessages mocks base method.
-func (m *MockPrivateService) GetPrivateMessages(sender, recipient string, limit, offset int) ([]entities.Message, error) {
+func (m *MockPrivateService) GetPrivateMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPrivateMessages", sender, recipient, limit, offset)
+	ret := m.ctrl.Call(m, "GetPrivateMessages", sender, recipient, limit, offset, order)
 	ret0, _ := ret[0].([]entities.Message)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPrivateMessages indicates an expected call of GetPrivateMessages.
-func (mr *MockPrivateServiceMockRecorder) GetPrivateMessages(sender, recipient, limit, offset interface{}) *gomock.Call {
+func (mr *MockPrivateServiceMockRecorder) GetPrivateMessages(sender, recipient, limit, offset, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivateMessages", reflect.TypeOf((*MockPrivateService)(nil).GetPrivateMessages), sender, recipient, limit, offset, order)
+}
+
+// ReadPrivateMessages mocks base method.
+func (m *MockPrivateService) ReadPrivateMessages(reader, partner string, messageID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPrivateMessages", reader, partner, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadPrivateMessages indicates an expected call of ReadPrivateMessages.
+func (mr *MockPrivateServiceMockRecorder) ReadPrivateMessages(reader, partner, messageID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivateMessages", reflect.TypeOf((*MockPrivateService)(nil).GetPrivateMessages), sender, recipient, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPrivateMessages", reflect.TypeOf((*MockPrivateService)(nil).ReadPrivateMessages), reader, partner, messageID)
 }
 
 // SendPrivateMessage mocks base method.
-func (m_2 *MockPrivateService) SendPrivateMessage(m entities.Message) error {
+func (m_2 *MockPrivateService) SendPrivateMessage(m entities.Message) (entities.SendResult, error) {
 	m_2.ctrl.T.Helper()
 	ret := m_2.ctrl.Call(m_2, "SendPrivateMessage", m)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(entities.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SendPrivateMessage indicates an expected call of SendPrivateMessage.
@@ -64,10 +137,10 @@ func (mr *MockPrivateServiceMockRecorder) SendPrivateMessage(m interface{}) *gom
 }
 
 // ViewUsers mocks base method.
-func (m *MockPrivateService) ViewUsers(user string) ([]string, error) {
+func (m *MockPrivateService) ViewUsers(user string) ([]entities.Conversation, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ViewUsers", user)
-	ret0, _ := ret[0].([]string)
+	ret0, _ := ret[0].([]entities.Conversation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
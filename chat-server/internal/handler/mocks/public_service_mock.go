@@ -35,26 +35,27 @@ func (m *MockPublicService) EXPECT() *MockPublicServiceMockRecorder {
 }
 
 // GetPublicMessages mocks base method.
-func (m *MockPublicService) GetPublicMessages(limit, offset int) ([]entities.Message, error) {
+func (m *MockPublicService) GetPublicMessages(viewer string, limit, offset int, order entities.Order) ([]entities.Message, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPublicMessages", limit, offset)
+	ret := m.ctrl.Call(m, "GetPublicMessages", viewer, limit, offset, order)
 	ret0, _ := ret[0].([]entities.Message)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPublicMessages indicates an expected call of GetPublicMessages.
-func (mr *MockPublicServiceMockRecorder) GetPublicMessages(limit, offset interface{}) *gomock.Call {
+func (mr *MockPublicServiceMockRecorder) GetPublicMessages(viewer, limit, offset, order interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicMessages", reflect.TypeOf((*MockPublicService)(nil).GetPublicMessages), limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicMessages", reflect.TypeOf((*MockPublicService)(nil).GetPublicMessages), viewer, limit, offset, order)
 }
 
 // SendPublicMessage mocks base method.
-func (m_2 *MockPublicService) SendPublicMessage(m entities.Message) error {
+func (m_2 *MockPublicService) SendPublicMessage(m entities.Message) (entities.SendResult, error) {
 	m_2.ctrl.T.Helper()
 	ret := m_2.ctrl.Call(m_2, "SendPublicMessage", m)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(entities.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SendPublicMessage indicates an expected call of SendPublicMessage.
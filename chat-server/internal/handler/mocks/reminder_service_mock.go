@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reminder_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockReminderService is a mock of ReminderService interface.
+type MockReminderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReminderServiceMockRecorder
+}
+
+// MockReminderServiceMockRecorder is the mock recorder for MockReminderService.
+type MockReminderServiceMockRecorder struct {
+	mock *MockReminderService
+}
+
+// NewMockReminderService creates a new mock instance.
+func NewMockReminderService(ctrl *gomock.Controller) *MockReminderService {
+	mock := &MockReminderService{ctrl: ctrl}
+	mock.recorder = &MockReminderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReminderService) EXPECT() *MockReminderServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReminderService) Create(rem entities.Reminder) (entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", rem)
+	ret0, _ := ret[0].(entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReminderServiceMockRecorder) Create(rem interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReminderService)(nil).Create), rem)
+}
+
+// Delete mocks base method.
+func (m *MockReminderService) Delete(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockReminderServiceMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReminderService)(nil).Delete), id)
+}
+
+// List mocks base method.
+func (m *MockReminderService) List() ([]entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockReminderServiceMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReminderService)(nil).List))
+}
+
+// Pause mocks base method.
+func (m *MockReminderService) Pause(id int) (entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", id)
+	ret0, _ := ret[0].(entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockReminderServiceMockRecorder) Pause(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockReminderService)(nil).Pause), id)
+}
+
+// Resume mocks base method.
+func (m *MockReminderService) Resume(id int) (entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume", id)
+	ret0, _ := ret[0].(entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockReminderServiceMockRecorder) Resume(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockReminderService)(nil).Resume), id)
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: retention_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockRetentionService is a mock of RetentionService interface.
+type MockRetentionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRetentionServiceMockRecorder
+}
+
+// MockRetentionServiceMockRecorder is the mock recorder for MockRetentionService.
+type MockRetentionServiceMockRecorder struct {
+	mock *MockRetentionService
+}
+
+// NewMockRetentionService creates a new mock instance.
+func NewMockRetentionService(ctrl *gomock.Controller) *MockRetentionService {
+	mock := &MockRetentionService{ctrl: ctrl}
+	mock.recorder = &MockRetentionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRetentionService) EXPECT() *MockRetentionServiceMockRecorder {
+	return m.recorder
+}
+
+// LegalHolds mocks base method.
+func (m *MockRetentionService) LegalHolds() ([]entities.LegalHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LegalHolds")
+	ret0, _ := ret[0].([]entities.LegalHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LegalHolds indicates an expected call of LegalHolds.
+func (mr *MockRetentionServiceMockRecorder) LegalHolds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LegalHolds", reflect.TypeOf((*MockRetentionService)(nil).LegalHolds))
+}
+
+// LiftLegalHold mocks base method.
+func (m *MockRetentionService) LiftLegalHold(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LiftLegalHold", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LiftLegalHold indicates an expected call of LiftLegalHold.
+func (mr *MockRetentionServiceMockRecorder) LiftLegalHold(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LiftLegalHold", reflect.TypeOf((*MockRetentionService)(nil).LiftLegalHold), username)
+}
+
+// PlaceLegalHold mocks base method.
+func (m *MockRetentionService) PlaceLegalHold(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceLegalHold", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PlaceLegalHold indicates an expected call of PlaceLegalHold.
+func (mr *MockRetentionServiceMockRecorder) PlaceLegalHold(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceLegalHold", reflect.TypeOf((*MockRetentionService)(nil).PlaceLegalHold), username)
+}
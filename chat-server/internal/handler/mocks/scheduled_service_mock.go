@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scheduled_handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockScheduledService is a mock of ScheduledService interface.
+type MockScheduledService struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduledServiceMockRecorder
+}
+
+// MockScheduledServiceMockRecorder is the mock recorder for MockScheduledService.
+type MockScheduledServiceMockRecorder struct {
+	mock *MockScheduledService
+}
+
+// NewMockScheduledService creates a new mock instance.
+func NewMockScheduledService(ctrl *gomock.Controller) *MockScheduledService {
+	mock := &MockScheduledService{ctrl: ctrl}
+	mock.recorder = &MockScheduledServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduledService) EXPECT() *MockScheduledServiceMockRecorder {
+	return m.recorder
+}
+
+// Cancel mocks base method.
+func (m *MockScheduledService) Cancel(sender string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", sender, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockScheduledServiceMockRecorder) Cancel(sender, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockScheduledService)(nil).Cancel), sender, id)
+}
+
+// List mocks base method.
+func (m *MockScheduledService) List(sender string, limit, offset int) ([]entities.ScheduledMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", sender, limit, offset)
+	ret0, _ := ret[0].([]entities.ScheduledMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockScheduledServiceMockRecorder) List(sender, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockScheduledService)(nil).List), sender, limit, offset)
+}
+
+// Schedule mocks base method.
+func (m_2 *MockScheduledService) Schedule(m entities.ScheduledMessage) (entities.ScheduledMessage, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Schedule", m)
+	ret0, _ := ret[0].(entities.ScheduledMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Schedule indicates an expected call of Schedule.
+func (mr *MockScheduledServiceMockRecorder) Schedule(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockScheduledService)(nil).Schedule), m)
+}
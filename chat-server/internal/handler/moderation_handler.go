@@ -0,0 +1,506 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+	"github.com/vavelour/chat/pkg/pagination"
+)
+
+const (
+	messageReported       = "message reported"
+	reportsReceived       = "reports received"
+	reportResolved        = "report resolved"
+	reportDismissed       = "report dismissed"
+	messageDeleted        = "message deleted"
+	sanctionSet           = "sanction set"
+	sanctionLifted        = "sanction lifted"
+	sanctionsReceived     = "sanctions received"
+	auditLogReceived      = "audit log received"
+	defaultModerationList = 20
+)
+
+var (
+	errInvalidReportID  = errors.New("report id must be a number")
+	errInvalidMessageID = errors.New("message id must be a number")
+)
+
+//go:generate mockgen -source=moderation_handler.go -destination=mocks/moderation_service_mock.go
+
+type ModerationService interface {
+	Report(reporter string, messageID int, reason string) (entities.Report, error)
+	Reports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error)
+	ResolveReport(moderator string, id int, reason string) (entities.Report, error)
+	DismissReport(moderator string, id int, reason string) (entities.Report, error)
+	DeleteMessage(ctx context.Context, moderator string, id int, reason string) error
+	Sanction(moderator, username string, kind entities.SanctionKind, duration time.Duration, reason string) error
+	Lift(moderator, username string, kind entities.SanctionKind, reason string) error
+	Sanctions() ([]entities.Sanction, error)
+	AuditLog(limit, offset int) ([]entities.AuditEntry, error)
+}
+
+type ModerationHandler struct {
+	service  ModerationService
+	validate *validator.Validate
+}
+
+func NewModerationHandler(s ModerationService, v *validator.Validate) *ModerationHandler {
+	return &ModerationHandler{service: s, validate: v}
+}
+
+// ReportRoutes mounts the routes every user has.
+func (h *ModerationHandler) ReportRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/reports", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Post("/", h.ReportMessage)
+	})
+}
+
+// ModerationRoutes mounts the routes of the moderators. The middlewares
+// have to include the moderator guard.
+func (h *ModerationHandler) ModerationRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/moderation", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/reports", h.ShowReports)
+		r.Post("/reports/{id}/resolve", h.ResolveReport)
+		r.Post("/reports/{id}/dismiss", h.DismissReport)
+		r.Delete("/messages/{id}", h.DeleteMessage)
+		r.Put("/users/{username}/{kind:mute|ban|suspend}", h.SanctionUser)
+		r.Delete("/users/{username}/{kind:mute|ban|suspend}", h.LiftSanction)
+		r.Get("/sanctions", h.ShowSanctions)
+		r.Get("/audit", h.ShowAuditLog)
+	})
+}
+
+// ReportMessage @summary		Жалоба на сообщение
+//
+//	@description	Отправляет модераторам жалобу на сообщение публичного чата. Идентификаторы сообщений возвращаются в поле ids при получении сообщений. На одно сообщение можно пожаловаться один раз, пока жалоба не рассмотрена.
+//	@tags			moderation
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.ReportMessageRequest	true	"Сообщение и причина жалобы"
+//	@success		201			{object}	response.ReportResponse			"Жалоба отправлена"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверный запрос или жалоба на своё сообщение"
+//	@failure		404			{object}	baseresponse.ResponseError		"Сообщение не найдено"
+//	@failure		409			{object}	baseresponse.ResponseError		"Жалоба на это сообщение уже отправлена"
+//	@failure		500			{object}	baseresponse.ResponseError		"Ошибка при отправке жалобы"
+//	@router			/v1/reports [post]
+func (h *ModerationHandler) ReportMessage(w http.ResponseWriter, r *http.Request) {
+	var input request.ReportMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.Reporter = username
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.service.Report(input.Reporter, input.MessageID, input.Reason)
+	switch {
+	case errors.Is(err, entities.ErrReportOwnMessage):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, entities.ErrMessageNotFound):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	case errors.Is(err, entities.ErrAlreadyReported):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusConflict, err)
+		return
+	case err != nil:
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	render.JSON(w, r, response.ReportResponse{Response: messageReported, Report: mapper.ReportToResponse(report)})
+}
+
+// ShowReports @summary		Очередь жалоб
+//
+//	@description	Возвращает жалобы с заданным статусом: открытые — начиная с самой старой, рассмотренные — начиная с последней. Доступно только модераторам и администраторам.
+//	@tags			moderation
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			status	query		string						false	"Статус жалоб: open (по умолчанию), resolved или dismissed"
+//	@param			limit	query		int							false	"Количество жалоб (1-100)"
+//	@param			offset	query		int							false	"Смещение"
+//	@success		200		{object}	response.ListReportsResponse	"Жалобы получены"
+//	@failure		400		{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		403		{object}	baseresponse.ResponseError		"Пользователь не модератор"
+//	@failure		500		{object}	baseresponse.ResponseError		"Ошибка при получении жалоб"
+//	@router			/v1/moderation/reports [get]
+func (h *ModerationHandler) ShowReports(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	input := request.ShowReportsRequest{Status: string(entities.ReportOpen), Limit: defaultModerationList}
+	if val := params.Get("status"); val != "" {
+		input.Status = val
+	}
+
+	if err := readPaging(params, &input.Limit, &input.Offset); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	reports, err := h.service.Reports(entities.ReportStatus(input.Status), input.Limit, input.Offset)
+	if err != nil && !errors.Is(err, pagination.ErrOffsetRange) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.ReportsToResponse(reportsReceived, reports))
+}
+
+// ResolveReport @summary		Рассмотрение жалобы
+//
+//	@description	Закрывает жалобу как обоснованную. Удаление сообщения и наказание отправителя выполняются отдельно. Доступно только модераторам и администраторам.
+//	@tags			moderation
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id			path		int								true	"Идентификатор жалобы"
+//	@param			requestBody	body		request.ModerationReasonRequest	false	"Комментарий для журнала модерации"
+//	@success		200			{object}	response.ReportResponse			"Жалоба рассмотрена"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError		"Пользователь не модератор"
+//	@failure		404			{object}	baseresponse.ResponseError		"Открытая жалоба не найдена"
+//	@failure		500			{object}	baseresponse.ResponseError		"Ошибка при рассмотрении жалобы"
+//	@router			/v1/moderation/reports/{id}/resolve [post]
+func (h *ModerationHandler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	h.closeReport(w, r, h.service.ResolveReport, reportResolved)
+}
+
+// DismissReport @summary		Отклонение жалобы
+//
+//	@description	Закрывает жалобу как необоснованную. Доступно только модераторам и администраторам.
+//	@tags			moderation
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id			path		int								true	"Идентификатор жалобы"
+//	@param			requestBody	body		request.ModerationReasonRequest	false	"Комментарий для журнала модерации"
+//	@success		200			{object}	response.ReportResponse			"Жалоба отклонена"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError		"Пользователь не модератор"
+//	@failure		404			{object}	baseresponse.ResponseError		"Открытая жалоба не найдена"
+//	@failure		500			{object}	baseresponse.ResponseError		"Ошибка при отклонении жалобы"
+//	@router			/v1/moderation/reports/{id}/dismiss [post]
+func (h *ModerationHandler) DismissReport(w http.ResponseWriter, r *http.Request) {
+	h.closeReport(w, r, h.service.DismissReport, reportDismissed)
+}
+
+// DeleteMessage @summary		Удаление сообщения
+//
+//	@description	Удаляет текст и вложения сообщения публичного чата и закрывает открытые жалобы на него. Сообщение остаётся в ленте пустым и помечается в поле deleted, чтобы не сдвигать страницы. Доступно только модераторам и администраторам.
+//	@tags			moderation
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id			path		int								true	"Идентификатор сообщения"
+//	@param			requestBody	body		request.ModerationReasonRequest	false	"Причина для журнала модерации"
+//	@success		200			{object}	response.ModerationResponse		"Сообщение удалено"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError		"Пользователь не модератор"
+//	@failure		404			{object}	baseresponse.ResponseError		"Сообщение не найдено"
+//	@failure		500			{object}	baseresponse.ResponseError		"Ошибка при удалении сообщения"
+//	@router			/v1/moderation/messages/{id} [delete]
+func (h *ModerationHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	moderator, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidMessageID)
+		return
+	}
+
+	var input request.ModerationReasonRequest
+	if err := h.decodeOptional(r, &input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.DeleteMessage(r.Context(), moderator, id, input.Reason)
+	if errors.Is(err, entities.ErrMessageNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.ModerationResponse{Response: messageDeleted})
+}
+
+// SanctionUser @summary		Наказание пользователя
+//
+//	@description	Накладывает на пользователя наказание: mute — запрет писать в публичный чат на время, ban — запрет писать в публичный чат, suspend — блокировка аккаунта. Новое наказание того же вида заменяет старое. Для mute длительность обязательна; ban и suspend без длительности бессрочные. Доступно только модераторам и администраторам.
+//	@tags			moderation
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string							true	"Имя пользователя"
+//	@param			kind		path		string							true	"Вид наказания: mute, ban или suspend"
+//	@param			requestBody	body		request.SanctionUserRequest		true	"Длительность и причина"
+//	@success		200			{object}	response.ModerationResponse		"Наказание наложено"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError		"Пользователь не модератор"
+//	@failure		404			{object}	baseresponse.ResponseError		"Пользователь не найден"
+//	@failure		500			{object}	baseresponse.ResponseError		"Ошибка при наложении наказания"
+//	@router			/v1/moderation/users/{username}/{kind} [put]
+func (h *ModerationHandler) SanctionUser(w http.ResponseWriter, r *http.Request) {
+	var input request.SanctionUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	moderator, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	kind := entities.SanctionKind(chi.URLParam(r, "kind"))
+	duration := time.Duration(input.DurationSeconds) * time.Second
+
+	err := h.service.Sanction(moderator, chi.URLParam(r, "username"), kind, duration, input.Reason)
+	switch {
+	case errors.Is(err, entities.ErrSanctionSelf), errors.Is(err, entities.ErrDurationRequired):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, entities.ErrUserNotFound):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	case err != nil:
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.ModerationResponse{Response: sanctionSet})
+}
+
+// LiftSanction @summary		Снятие наказания
+//
+//	@description	Досрочно снимает с пользователя наказание заданного вида. Доступно только модераторам и администраторам.
+//	@tags			moderation
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string							true	"Имя пользователя"
+//	@param			kind		path		string							true	"Вид наказания: mute, ban или suspend"
+//	@param			requestBody	body		request.ModerationReasonRequest	false	"Причина для журнала модерации"
+//	@success		200			{object}	response.ModerationResponse		"Наказание снято"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError		"Пользователь не модератор"
+//	@failure		404			{object}	baseresponse.ResponseError		"Действующее наказание не найдено"
+//	@failure		500			{object}	baseresponse.ResponseError		"Ошибка при снятии наказания"
+//	@router			/v1/moderation/users/{username}/{kind} [delete]
+func (h *ModerationHandler) LiftSanction(w http.ResponseWriter, r *http.Request) {
+	moderator, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	var input request.ModerationReasonRequest
+	if err := h.decodeOptional(r, &input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	kind := entities.SanctionKind(chi.URLParam(r, "kind"))
+
+	err := h.service.Lift(moderator, chi.URLParam(r, "username"), kind, input.Reason)
+	if errors.Is(err, entities.ErrSanctionNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.ModerationResponse{Response: sanctionLifted})
+}
+
+// ShowSanctions @summary		Действующие наказания
+//
+//	@description	Возвращает все действующие наказания, начиная с последнего. Доступно только модераторам и администраторам.
+//	@tags			moderation
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.ListSanctionsResponse	"Наказания получены"
+//	@failure		403	{object}	baseresponse.ResponseError		"Пользователь не модератор"
+//	@failure		500	{object}	baseresponse.ResponseError		"Ошибка при получении наказаний"
+//	@router			/v1/moderation/sanctions [get]
+func (h *ModerationHandler) ShowSanctions(w http.ResponseWriter, r *http.Request) {
+	sanctions, err := h.service.Sanctions()
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.SanctionsToResponse(sanctionsReceived, sanctions))
+}
+
+// ShowAuditLog @summary		Журнал модерации
+//
+//	@description	Возвращает действия модераторов, начиная с последнего. Доступно только модераторам и администраторам.
+//	@tags			moderation
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			limit	query		int							false	"Количество записей (1-100)"
+//	@param			offset	query		int							false	"Смещение"
+//	@success		200		{object}	response.AuditLogResponse	"Журнал получен"
+//	@failure		400		{object}	baseresponse.ResponseError	"Неверный запрос"
+//	@failure		403		{object}	baseresponse.ResponseError	"Пользователь не модератор"
+//	@failure		500		{object}	baseresponse.ResponseError	"Ошибка при получении журнала"
+//	@router			/v1/moderation/audit [get]
+func (h *ModerationHandler) ShowAuditLog(w http.ResponseWriter, r *http.Request) {
+	input := request.ShowAuditLogRequest{Limit: defaultModerationList}
+	if err := readPaging(r.URL.Query(), &input.Limit, &input.Offset); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := h.service.AuditLog(input.Limit, input.Offset)
+	if err != nil && !errors.Is(err, pagination.ErrOffsetRange) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.AuditLogToResponse(auditLogReceived, entries))
+}
+
+func (h *ModerationHandler) closeReport(w http.ResponseWriter, r *http.Request, action func(moderator string, id int, reason string) (entities.Report, error), resp string) {
+	moderator, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidReportID)
+		return
+	}
+
+	var input request.ModerationReasonRequest
+	if err := h.decodeOptional(r, &input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := action(moderator, id, input.Reason)
+	if errors.Is(err, entities.ErrReportNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.ReportResponse{Response: resp, Report: mapper.ReportToResponse(report)})
+}
+
+// decodeOptional reads the reason of the action from the body, which may be
+// left out.
+func (h *ModerationHandler) decodeOptional(r *http.Request, input *request.ModerationReasonRequest) error {
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return input.Validate(h.validate)
+}
+
+func readPaging(params url.Values, limit, offset *int) error {
+	var err error
+	if val := params.Get("limit"); val != "" {
+		*limit, err = strconv.Atoi(val)
+	}
+	if val := params.Get("offset"); val != "" && err == nil {
+		*offset, err = strconv.Atoi(val)
+	}
+	if err != nil {
+		return errInvalidPaging
+	}
+
+	return nil
+}
+
+// sanctioned tells whether the error is a sanction which keeps the user
+// from posting to the public chat.
+func sanctioned(err error) bool {
+	return errors.Is(err, entities.ErrMuted) || errors.Is(err, entities.ErrBanned) || errors.Is(err, entities.ErrAccountSuspended)
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModerationHandler_ReportMessage(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockModerationService)
+
+	created := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"message_id": 7, "reason": "spam"}`,
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().Report("tester", 7, "spam").Return(entities.Report{ID: 1, MessageID: 7, Reporter: "tester", Reason: "spam",
+					Status: entities.ReportOpen, CreatedAt: created, Message: entities.Message{ID: 7, Sender: "valera", Content: "buy", CreatedAt: created}}, nil)
+			},
+			expectedStatusCode: 201,
+			expectedRequestBody: `{"response":"message reported","report":{"id":1,"message_id":7,"reporter":"tester","reason":"spam","status":"open",` +
+				`"created_at":"2026-10-19T09:00:00Z","message":{"sender":"valera","content":"buy","created_at":"2026-10-19T09:00:00Z","deleted":false}}}`,
+		},
+		{
+			name:                "no_reason",
+			inputBody:           `{"message_id": 7}`,
+			mockBehavior:        func(s *mock_handler.MockModerationService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ReportMessageRequest.Reason' Error:Field validation for 'Reason' failed on the 'required' tag"}`,
+		},
+		{
+			name:      "own_message",
+			inputBody: `{"message_id": 7, "reason": "spam"}`,
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().Report("tester", 7, "spam").Return(entities.Report{}, entities.ErrReportOwnMessage)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"you can not report your own message"}`,
+		},
+		{
+			name:      "not_found",
+			inputBody: `{"message_id": 7, "reason": "spam"}`,
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().Report("tester", 7, "spam").Return(entities.Report{}, entities.ErrMessageNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"message not found"}`,
+		},
+		{
+			name:      "already_reported",
+			inputBody: `{"message_id": 7, "reason": "spam"}`,
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().Report("tester", 7, "spam").Return(entities.Report{}, entities.ErrAlreadyReported)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"error":"you have already reported this message"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			moderation := mock_handler.NewMockModerationService(ctrl)
+			testCase.mockBehavior(moderation)
+
+			moderationHandler := NewModerationHandler(moderation, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/reports", moderationHandler.ReportMessage)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/reports", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(context.WithValue(req.Context(), "Sender", "tester"))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestModerationHandler_Routes(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockModerationService)
+
+	testTable := []struct {
+		name                string
+		method              string
+		target              string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "reports_default_queue",
+			method: "GET",
+			target: "/v1/moderation/reports",
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().Reports(entities.ReportOpen, 20, 0).Return([]entities.Report{}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"reports received","reports":[]}`,
+		},
+		{
+			name:                "reports_unknown_status",
+			method:              "GET",
+			target:              "/v1/moderation/reports?status=closed",
+			mockBehavior:        func(s *mock_handler.MockModerationService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ShowReportsRequest.Status' Error:Field validation for 'Status' failed on the 'oneof' tag"}`,
+		},
+		{
+			name:   "resolve_without_body",
+			method: "POST",
+			target: "/v1/moderation/reports/3/resolve",
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().ResolveReport("admin", 3, "").Return(entities.Report{}, entities.ErrReportNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"report not found"}`,
+		},
+		{
+			name:      "delete_message",
+			method:    "DELETE",
+			target:    "/v1/moderation/messages/7",
+			inputBody: `{"reason": "spam"}`,
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().DeleteMessage(gomock.Any(), "admin", 7, "spam").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"message deleted"}`,
+		},
+		{
+			name:      "mute",
+			method:    "PUT",
+			target:    "/v1/moderation/users/valera/mute",
+			inputBody: `{"duration_seconds": 600, "reason": "flood"}`,
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().Sanction("admin", "valera", entities.SanctionMute, 10*time.Minute, "flood").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"sanction set"}`,
+		},
+		{
+			name:      "mute_without_duration",
+			method:    "PUT",
+			target:    "/v1/moderation/users/valera/mute",
+			inputBody: `{}`,
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().Sanction("admin", "valera", entities.SanctionMute, time.Duration(0), "").Return(entities.ErrDurationRequired)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"a mute needs a duration"}`,
+		},
+		{
+			name:                "unknown_sanction",
+			method:              "PUT",
+			target:              "/v1/moderation/users/valera/kick",
+			inputBody:           `{}`,
+			mockBehavior:        func(s *mock_handler.MockModerationService) {},
+			expectedStatusCode:  404,
+			expectedRequestBody: `404 page not found`,
+		},
+		{
+			name:   "lift_missing",
+			method: "DELETE",
+			target: "/v1/moderation/users/valera/ban",
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().Lift("admin", "valera", entities.SanctionBan, "").Return(entities.ErrSanctionNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"the user has no such sanction"}`,
+		},
+		{
+			name:   "audit_log",
+			method: "GET",
+			target: "/v1/moderation/audit?limit=5&offset=5",
+			mockBehavior: func(s *mock_handler.MockModerationService) {
+				s.EXPECT().AuditLog(5, 5).Return(nil, errors.New("db error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"db error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			moderation := mock_handler.NewMockModerationService(ctrl)
+			testCase.mockBehavior(moderation)
+
+			moderationHandler := NewModerationHandler(moderation, validator.New())
+
+			r := chi.NewRouter()
+			moderationHandler.ModerationRoutes(r)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.target, bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(context.WithValue(req.Context(), "Sender", "admin"))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	notificationsReceived    = "notifications received"
+	notificationsRead        = "notifications marked as read"
+	defaultNotificationLimit = 20
+)
+
+var errInvalidPaging = errors.New("limit and offset must be numbers")
+
+//go:generate mockgen -source=notification_handler.go -destination=mocks/notification_service_mock.go
+
+type NotificationService interface {
+	GetNotifications(user string, unreadOnly bool, limit, offset int) (entities.NotificationInbox, error)
+	MarkAsRead(user string, ids []int) (int, error)
+}
+
+type NotificationHandler struct {
+	service  NotificationService
+	validate *validator.Validate
+}
+
+func NewNotificationHandler(s NotificationService, v *validator.Validate) *NotificationHandler {
+	return &NotificationHandler{service: s, validate: v}
+}
+
+func (h *NotificationHandler) NotificationRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/notifications", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ShowNotifications)
+		r.Post("/read", h.ReadNotifications)
+	})
+}
+
+// ShowNotifications @summary		Получение уведомлений
+//
+//	@description	Получает уведомления пользователя об упоминаниях в публичном чате и новых приватных сообщениях, от новых к старым, вместе с количеством непрочитанных.
+//	@tags			notifications
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			unread	query		bool								false	"Только непрочитанные"
+//	@param			limit	query		int									false	"Количество уведомлений (1-100)"
+//	@param			offset	query		int									false	"Смещение"
+//	@success		200		{object}	response.ShowNotificationsResponse	"Уведомления успешно получены"
+//	@failure		400		{object}	baseresponse.ResponseError			"Неверный запрос"
+//	@failure		500		{object}	baseresponse.ResponseError			"Ошибка при получении уведомлений"
+//	@router			/v1/notifications [get]
+func (h *NotificationHandler) ShowNotifications(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	params := r.URL.Query()
+	input := request.ShowNotificationsRequest{
+		Username:   username,
+		UnreadOnly: params.Get("unread") == "true",
+		Limit:      defaultNotificationLimit,
+	}
+
+	var err error
+	if val := params.Get("limit"); val != "" {
+		input.Limit, err = strconv.Atoi(val)
+	}
+	if val := params.Get("offset"); val != "" && err == nil {
+		input.Offset, err = strconv.Atoi(val)
+	}
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidPaging)
+		return
+	}
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	inbox, err := h.service.GetNotifications(input.Username, input.UnreadOnly, input.Limit, input.Offset)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.NotificationInboxToResponse(notificationsReceived, inbox))
+}
+
+// ReadNotifications @summary		Отметка уведомлений прочитанными
+//
+//	@description	Отмечает прочитанными перечисленные уведомления пользователя или все уведомления сразу.
+//	@tags			notifications
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.ReadNotificationsRequest	true	"Идентификаторы уведомлений"
+//	@success		200			{object}	response.ReadNotificationsResponse	"Уведомления отмечены прочитанными"
+//	@failure		400			{object}	baseresponse.ResponseError			"Неверный запрос"
+//	@failure		500			{object}	baseresponse.ResponseError			"Ошибка при обновлении уведомлений"
+//	@router			/v1/notifications/read [post]
+func (h *NotificationHandler) ReadNotifications(w http.ResponseWriter, r *http.Request) {
+	var input request.ReadNotificationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.Username = username
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	ids := input.IDs
+	if input.All {
+		ids = nil
+	}
+
+	updated, err := h.service.MarkAsRead(input.Username, ids)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.ReadNotificationsResponse{Response: notificationsRead, Updated: updated})
+}
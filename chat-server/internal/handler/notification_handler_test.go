@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotificationHandler_ShowNotifications(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockNotificationService)
+
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		target              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "ok",
+			target: "/notifications?unread=true&limit=5&offset=5",
+			mockBehavior: func(s *mock_handler.MockNotificationService) {
+				s.EXPECT().GetNotifications("tester", true, 5, 5).Return(entities.NotificationInbox{
+					Notifications: []entities.Notification{
+						{ID: 7, Kind: entities.NotificationMention, Sender: "valera", MessageID: 3, Content: "hi @tester", CreatedAt: createdAt},
+					},
+					UnreadCount: 6,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"response":"notifications received","unread_count":6,"notifications":[` +
+				`{"id":7,"kind":"mention","sender":"valera","sender_avatar_url":"/v1/users/valera/avatar","message_id":3,"content":"hi @tester","read":false,"created_at":"2026-10-19T09:00:00Z"}]}`,
+		},
+		{
+			name:   "defaults",
+			target: "/notifications",
+			mockBehavior: func(s *mock_handler.MockNotificationService) {
+				s.EXPECT().GetNotifications("tester", false, 20, 0).Return(entities.NotificationInbox{}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"notifications received","unread_count":0,"notifications":[]}`,
+		},
+		{
+			name:                "invalid_limit",
+			target:              "/notifications?limit=all",
+			mockBehavior:        func(s *mock_handler.MockNotificationService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"limit and offset must be numbers"}`,
+		},
+		{
+			name:   "service_error",
+			target: "/notifications",
+			mockBehavior: func(s *mock_handler.MockNotificationService) {
+				s.EXPECT().GetNotifications("tester", false, 20, 0).Return(entities.NotificationInbox{}, errors.New("storage error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"storage error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notifications := mock_handler.NewMockNotificationService(ctrl)
+			notificationHandler := NewNotificationHandler(notifications, validator.New())
+
+			r := chi.NewRouter()
+			r.Get("/notifications", notificationHandler.ShowNotifications)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("GET", testCase.target, nil)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(notifications)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestNotificationHandler_ReadNotifications(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockNotificationService)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "selected",
+			inputBody: `{"ids": [1, 2]}`,
+			mockBehavior: func(s *mock_handler.MockNotificationService) {
+				s.EXPECT().MarkAsRead("tester", []int{1, 2}).Return(2, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"notifications marked as read","updated":2}`,
+		},
+		{
+			name:      "all",
+			inputBody: `{"all": true}`,
+			mockBehavior: func(s *mock_handler.MockNotificationService) {
+				s.EXPECT().MarkAsRead("tester", nil).Return(5, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"notifications marked as read","updated":5}`,
+		},
+		{
+			name:                "empty_ids",
+			inputBody:           `{"ids": []}`,
+			mockBehavior:        func(s *mock_handler.MockNotificationService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ReadNotificationsRequest.IDs' Error:Field validation for 'IDs' failed on the 'min' tag"}`,
+		},
+		{
+			name:                "nothing_selected",
+			inputBody:           `{}`,
+			mockBehavior:        func(s *mock_handler.MockNotificationService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ReadNotificationsRequest.IDs' Error:Field validation for 'IDs' failed on the 'required_without' tag"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notifications := mock_handler.NewMockNotificationService(ctrl)
+			notificationHandler := NewNotificationHandler(notifications, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/notifications/read", notificationHandler.ReadNotifications)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("POST", "/notifications/read", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(notifications)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
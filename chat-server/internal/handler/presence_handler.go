@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	presenceReceived = "presence received"
+	typingSent       = "typing sent"
+	typingReceived   = "typing users received"
+	privateChat      = "private"
+)
+
+//go:generate mockgen -source=presence_handler.go -destination=mocks/presence_service_mock.go
+
+type PresenceService interface {
+	Connect(user string) (<-chan entities.TypingEvent, func())
+	GetPresence(usernames []string) ([]entities.Presence, error)
+	SetTyping(user, recipient string)
+	GetTyping(user, partner string) []string
+}
+
+type PresenceHandler struct {
+	service   PresenceService
+	validate  *validator.Validate
+	heartbeat time.Duration
+}
+
+func NewPresenceHandler(s PresenceService, v *validator.Validate, heartbeat time.Duration) *PresenceHandler {
+	return &PresenceHandler{service: s, validate: v, heartbeat: heartbeat}
+}
+
+func (h *PresenceHandler) PresenceRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/presence", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ShowPresence)
+		r.Get("/events", h.Events)
+		r.Get("/typing/{chat}", h.ShowTyping)
+		r.Post("/typing/{chat}", h.SendTyping)
+	})
+}
+
+// ShowPresence @summary		Получение статуса присутствия пользователей
+//
+//	@description	Возвращает статус (online, away, offline) и время последней активности для переданных пользователей.
+//	@tags			presence
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.ShowPresenceRequest		true	"Список пользователей"
+//	@success		200			{object}	response.ShowPresenceResponse	"Статусы успешно получены"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		500			{object}	baseresponse.ResponseError		"Ошибка при получении статусов"
+//	@router			/v1/presence [get]
+func (h *PresenceHandler) ShowPresence(w http.ResponseWriter, r *http.Request) {
+	var input request.ShowPresenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := input.Validate(h.validate)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	presence, err := h.service.GetPresence(input.Usernames)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.PresenceEntitiesToResponse(presenceReceived, presence))
+}
+
+// Events @summary		Поток событий в реальном времени
+//
+//	@description	Держит соединение (Server-Sent Events), пока пользователь онлайн, и доставляет события набора текста.
+//	@tags			presence
+//	@produce		text/event-stream
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.TypingEventItem	"Поток событий"
+//	@failure		500	{object}	baseresponse.ResponseError	"Ошибка при получении пользователя"
+//	@router			/v1/presence/events [get]
+func (h *PresenceHandler) Events(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	events, disconnect := h.service.Connect(user)
+	defer disconnect()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(mapper.TypingEventEntityToResponse(event))
+			if err != nil {
+				return
+			}
+
+			if _, err := fmt.Fprintf(w, "event: typing\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// SendTyping @summary		Уведомление о наборе текста
+//
+//	@description	Сообщает, что пользователь набирает сообщение в приватной переписке или в публичном чате. Событие истекает через несколько секунд.
+//	@tags			presence
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			chat		path		string						true	"Тип чата: private или public"
+//	@param			username	query		string						false	"Имя собеседника для приватного чата"
+//	@success		200			{object}	response.SendTypingResponse	"Уведомление отправлено"
+//	@failure		400			{object}	baseresponse.ResponseError	"Неверный запрос"
+//	@router			/v1/presence/typing/{chat} [post]
+func (h *PresenceHandler) SendTyping(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input, err := h.typingRequest(username, r)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	h.service.SetTyping(input.Username, input.Partner)
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.SendTypingResponse{Response: typingSent})
+}
+
+// ShowTyping @summary		Получение списка набирающих текст
+//
+//	@description	Возвращает пользователей, которые сейчас набирают сообщение в приватной переписке или в публичном чате.
+//	@tags			presence
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			chat		path		string						true	"Тип чата: private или public"
+//	@param			username	query		string						false	"Имя собеседника для приватного чата"
+//	@success		200			{object}	response.ShowTypingResponse	"Список успешно получен"
+//	@failure		400			{object}	baseresponse.ResponseError	"Неверный запрос"
+//	@router			/v1/presence/typing/{chat} [get]
+func (h *PresenceHandler) ShowTyping(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input, err := h.typingRequest(username, r)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	users := h.service.GetTyping(input.Username, input.Partner)
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.ShowTypingResponse{Response: typingReceived, Users: users, AvatarURLs: mapper.AvatarURLs(users)})
+}
+
+func (h *PresenceHandler) typingRequest(username string, r *http.Request) (request.TypingRequest, error) {
+	input := request.TypingRequest{Username: username}
+	input.Chat = chi.URLParam(r, "chat")
+	if input.Chat == privateChat {
+		input.Partner = r.URL.Query().Get("username")
+	}
+
+	return input, input.Validate(h.validate)
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPresenceHandler_ShowPresence(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockPresenceService, usernames []string)
+
+	lastSeen := time.Date(2026, time.October, 19, 9, 55, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputUsernames      []string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:           "ok",
+			inputBody:      `{"usernames": ["valera", "vika"]}`,
+			inputUsernames: []string{"valera", "vika"},
+			mockBehavior: func(s *mock_handler.MockPresenceService, usernames []string) {
+				s.EXPECT().GetPresence(usernames).Return([]entities.Presence{
+					{Username: "valera", Status: entities.StatusOnline, LastSeen: lastSeen},
+					{Username: "vika", Status: entities.StatusOffline},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"presence received","presence":[{"username":"valera","avatar_url":"/v1/users/valera/avatar","status":"online","last_seen":"2026-10-19T09:55:00Z"},{"username":"vika","avatar_url":"/v1/users/vika/avatar","status":"offline"}]}`,
+		},
+		{
+			name:                "empty_usernames",
+			inputBody:           `{"usernames": []}`,
+			mockBehavior:        func(s *mock_handler.MockPresenceService, usernames []string) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ShowPresenceRequest.Usernames' Error:Field validation for 'Usernames' failed on the 'min' tag"}`,
+		},
+		{
+			name:                "invalid_input",
+			inputBody:           `{"usernames": invalid}`,
+			mockBehavior:        func(s *mock_handler.MockPresenceService, usernames []string) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid character 'i' looking for beginning of value"}`,
+		},
+		{
+			name:           "service_error",
+			inputBody:      `{"usernames": ["valera"]}`,
+			inputUsernames: []string{"valera"},
+			mockBehavior: func(s *mock_handler.MockPresenceService, usernames []string) {
+				s.EXPECT().GetPresence(usernames).Return(nil, errors.New("service error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"service error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			presence := mock_handler.NewMockPresenceService(ctrl)
+			validate := validator.New()
+
+			presenceHandler := NewPresenceHandler(presence, validate, time.Second)
+
+			r := chi.NewRouter()
+			r.Get("/presence", presenceHandler.ShowPresence)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("GET", "/presence", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(presence, testCase.inputUsernames)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestPresenceHandler_SendTyping(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockPresenceService)
+
+	testTable := []struct {
+		name                string
+		target              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "ok_private",
+			target: "/typing/private?username=valera",
+			mockBehavior: func(s *mock_handler.MockPresenceService) {
+				s.EXPECT().SetTyping("tester", "valera")
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"typing sent"}`,
+		},
+		{
+			name:   "ok_public",
+			target: "/typing/public",
+			mockBehavior: func(s *mock_handler.MockPresenceService) {
+				s.EXPECT().SetTyping("tester", "")
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"typing sent"}`,
+		},
+		{
+			name:                "missing_partner",
+			target:              "/typing/private",
+			mockBehavior:        func(s *mock_handler.MockPresenceService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'TypingRequest.Partner' Error:Field validation for 'Partner' failed on the 'required_if' tag"}`,
+		},
+		{
+			name:                "unknown_chat",
+			target:              "/typing/secret",
+			mockBehavior:        func(s *mock_handler.MockPresenceService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'TypingRequest.Chat' Error:Field validation for 'Chat' failed on the 'oneof' tag"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			presence := mock_handler.NewMockPresenceService(ctrl)
+			validate := validator.New()
+
+			presenceHandler := NewPresenceHandler(presence, validate, time.Second)
+
+			r := chi.NewRouter()
+			r.Post("/typing/{chat}", presenceHandler.SendTyping)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("POST", testCase.target, nil)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(presence)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestPresenceHandler_ShowTyping(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockPresenceService)
+
+	testTable := []struct {
+		name                string
+		target              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "ok_private",
+			target: "/typing/private?username=valera",
+			mockBehavior: func(s *mock_handler.MockPresenceService) {
+				s.EXPECT().GetTyping("tester", "valera").Return([]string{"valera"})
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"typing users received","users":["valera"],"avatar_urls":{"valera":"/v1/users/valera/avatar"}}`,
+		},
+		{
+			name:   "ok_public",
+			target: "/typing/public",
+			mockBehavior: func(s *mock_handler.MockPresenceService) {
+				s.EXPECT().GetTyping("tester", "").Return([]string{})
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"typing users received","users":[],"avatar_urls":{}}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			presence := mock_handler.NewMockPresenceService(ctrl)
+			validate := validator.New()
+
+			presenceHandler := NewPresenceHandler(presence, validate, time.Second)
+
+			r := chi.NewRouter()
+			r.Get("/typing/{chat}", presenceHandler.ShowTyping)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("GET", testCase.target, nil)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(presence)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestPresenceHandler_Events(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	presence := mock_handler.NewMockPresenceService(ctrl)
+	validate := validator.New()
+
+	events := make(chan entities.TypingEvent, 1)
+	events <- entities.TypingEvent{Sender: "valera", Recipient: "tester", ExpiresAt: time.Date(2026, time.October, 19, 9, 55, 5, 0, time.UTC)}
+	close(events)
+
+	disconnected := false
+	presence.EXPECT().Connect("tester").Return((<-chan entities.TypingEvent)(events), func() { disconnected = true })
+
+	presenceHandler := NewPresenceHandler(presence, validate, time.Hour)
+
+	r := chi.NewRouter()
+	r.Get("/events", presenceHandler.Events)
+
+	w := httptest.NewRecorder()
+
+	ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+	req := httptest.NewRequest("GET", "/events", nil)
+	req = req.WithContext(ctx)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "event: typing\ndata: {\"sender\":\"valera\",\"sender_avatar_url\":\"/v1/users/valera/avatar\",\"recipient\":\"tester\",\"expires_at\":\"2026-10-19T09:55:05Z\"}\n\n", w.Body.String())
+	assert.True(t, disconnected)
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	privacyReceived = "privacy settings received"
+	privacyUpdated  = "privacy settings updated"
+	blocksReceived  = "blocked users received"
+	userBlocked     = "user blocked"
+	userUnblocked   = "user unblocked"
+)
+
+//go:generate mockgen -source=privacy_handler.go -destination=mocks/privacy_service_mock.go
+
+type PrivacyService interface {
+	GetSettings(user string) (entities.PrivacySettings, error)
+	UpdateSettings(user string, settings entities.PrivacySettings) error
+	Block(user, blocked string) error
+	Unblock(user, blocked string) error
+	GetBlocked(user string) ([]entities.BlockedUser, error)
+}
+
+type PrivacyHandler struct {
+	service  PrivacyService
+	validate *validator.Validate
+}
+
+func NewPrivacyHandler(s PrivacyService, v *validator.Validate) *PrivacyHandler {
+	return &PrivacyHandler{service: s, validate: v}
+}
+
+func (h *PrivacyHandler) PrivacyRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/privacy", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.GetPrivacySettings)
+		r.Put("/", h.UpdatePrivacySettings)
+	})
+
+	router.Route("/v1/blocks", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ListBlockedUsers)
+		r.Put("/{username}", h.BlockUser)
+		r.Delete("/{username}", h.UnblockUser)
+	})
+}
+
+// GetPrivacySettings @summary		Получение настроек приватности
+//
+//	@description	Возвращает, кто может писать пользователю в личные сообщения (anyone — все, contacts — только те, кому пользователь писал или чей запрос на переписку принял, nobody — никто), и скрываются ли из публичного чата сообщения заблокированных пользователей.
+//	@tags			privacy
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.PrivacySettingsResponse	"Настройки получены"
+//	@failure		500	{object}	baseresponse.ResponseError			"Ошибка при получении настроек"
+//	@router			/v1/privacy [get]
+func (h *PrivacyHandler) GetPrivacySettings(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	settings, err := h.service.GetSettings(username)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.PrivacySettingsToResponse(privacyReceived, settings))
+}
+
+// UpdatePrivacySettings @summary		Изменение настроек приватности
+//
+//	@description	Заменяет настройки приватности пользователя. Заблокированные пользователи не могут писать ему при любых настройках.
+//	@tags			privacy
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.UpdatePrivacySettingsRequest	true	"Настройки приватности"
+//	@success		200			{object}	response.PrivacySettingsResponse		"Настройки изменены"
+//	@failure		400			{object}	baseresponse.ResponseError				"Неверный запрос"
+//	@failure		500			{object}	baseresponse.ResponseError				"Ошибка при сохранении настроек"
+//	@router			/v1/privacy [put]
+func (h *PrivacyHandler) UpdatePrivacySettings(w http.ResponseWriter, r *http.Request) {
+	var input request.UpdatePrivacySettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	settings := mapper.UpdatePrivacySettingsRequestToEntity(input)
+
+	err := h.service.UpdateSettings(username, settings)
+	if errors.Is(err, entities.ErrInvalidDMPolicy) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.PrivacySettingsToResponse(privacyUpdated, settings))
+}
+
+// ListBlockedUsers @summary		Список заблокированных пользователей
+//
+//	@description	Возвращает пользователей, заблокированных текущим пользователем, начиная с последних.
+//	@tags			privacy
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.BlockedUsersResponse	"Список получен"
+//	@failure		500	{object}	baseresponse.ResponseError		"Ошибка при получении списка"
+//	@router			/v1/blocks [get]
+func (h *PrivacyHandler) ListBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	blocked, err := h.service.GetBlocked(username)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.BlockedUsersToResponse(blocksReceived, blocked))
+}
+
+// BlockUser @summary		Блокировка пользователя
+//
+//	@description	Блокирует пользователя: он больше не может писать текущему пользователю в личные сообщения и не узнает о блокировке. Повторная блокировка ничего не меняет.
+//	@tags			privacy
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string						true	"Имя блокируемого пользователя"
+//	@success		200			{object}	response.BlockResponse		"Пользователь заблокирован"
+//	@failure		400			{object}	baseresponse.ResponseError	"Нельзя заблокировать себя"
+//	@failure		404			{object}	baseresponse.ResponseError	"Пользователь не найден"
+//	@router			/v1/blocks/{username} [put]
+func (h *PrivacyHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	err := h.service.Block(username, chi.URLParam(r, "username"))
+	switch {
+	case errors.Is(err, entities.ErrBlockSelf):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, entities.ErrUserNotFound):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	case err != nil:
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.BlockResponse{Response: userBlocked})
+}
+
+// UnblockUser @summary		Разблокировка пользователя
+//
+//	@description	Снимает блокировку с пользователя. Снятие несуществующей блокировки ничего не меняет.
+//	@tags			privacy
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string						true	"Имя разблокируемого пользователя"
+//	@success		200			{object}	response.BlockResponse		"Пользователь разблокирован"
+//	@failure		500			{object}	baseresponse.ResponseError	"Ошибка при снятии блокировки"
+//	@router			/v1/blocks/{username} [delete]
+func (h *PrivacyHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	if err := h.service.Unblock(username, chi.URLParam(r, "username")); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.BlockResponse{Response: userUnblocked})
+}
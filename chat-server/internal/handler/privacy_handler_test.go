@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrivacyHandler_UpdatePrivacySettings(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockPrivacyService)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"dm_policy": "contacts", "hide_blocked_in_feed": false}`,
+			mockBehavior: func(s *mock_handler.MockPrivacyService) {
+				s.EXPECT().UpdateSettings("tester", entities.PrivacySettings{DMPolicy: entities.DMPolicyContacts}).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"privacy settings updated","settings":{"dm_policy":"contacts","hide_blocked_in_feed":false}}`,
+		},
+		{
+			name:                "unknown_policy",
+			inputBody:           `{"dm_policy": "friends", "hide_blocked_in_feed": true}`,
+			mockBehavior:        func(s *mock_handler.MockPrivacyService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'UpdatePrivacySettingsRequest.DMPolicy' Error:Field validation for 'DMPolicy' failed on the 'oneof' tag"}`,
+		},
+		{
+			name:                "missing_hide_blocked_in_feed",
+			inputBody:           `{"dm_policy": "nobody"}`,
+			mockBehavior:        func(s *mock_handler.MockPrivacyService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'UpdatePrivacySettingsRequest.HideBlockedInFeed' Error:Field validation for 'HideBlockedInFeed' failed on the 'required' tag"}`,
+		},
+		{
+			name:      "service_error",
+			inputBody: `{"dm_policy": "anyone", "hide_blocked_in_feed": true}`,
+			mockBehavior: func(s *mock_handler.MockPrivacyService) {
+				s.EXPECT().UpdateSettings("tester", entities.DefaultPrivacySettings()).Return(errors.New("db error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"db error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			privacy := mock_handler.NewMockPrivacyService(ctrl)
+			testCase.mockBehavior(privacy)
+
+			privacyHandler := NewPrivacyHandler(privacy, validator.New())
+
+			r := chi.NewRouter()
+			r.Put("/privacy", privacyHandler.UpdatePrivacySettings)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/privacy", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(context.WithValue(req.Context(), "Sender", "tester"))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestPrivacyHandler_BlockUser(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockPrivacyService)
+
+	testTable := []struct {
+		name                string
+		method              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "block",
+			method: "PUT",
+			mockBehavior: func(s *mock_handler.MockPrivacyService) {
+				s.EXPECT().Block("tester", "valera").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"user blocked"}`,
+		},
+		{
+			name:   "block_self",
+			method: "PUT",
+			mockBehavior: func(s *mock_handler.MockPrivacyService) {
+				s.EXPECT().Block("tester", "valera").Return(entities.ErrBlockSelf)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"you can not block yourself"}`,
+		},
+		{
+			name:   "block_unknown_user",
+			method: "PUT",
+			mockBehavior: func(s *mock_handler.MockPrivacyService) {
+				s.EXPECT().Block("tester", "valera").Return(entities.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"user does not exist"}`,
+		},
+		{
+			name:   "unblock",
+			method: "DELETE",
+			mockBehavior: func(s *mock_handler.MockPrivacyService) {
+				s.EXPECT().Unblock("tester", "valera").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"user unblocked"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			privacy := mock_handler.NewMockPrivacyService(ctrl)
+			testCase.mockBehavior(privacy)
+
+			privacyHandler := NewPrivacyHandler(privacy, validator.New())
+
+			r := chi.NewRouter()
+			r.Put("/blocks/{username}", privacyHandler.BlockUser)
+			r.Delete("/blocks/{username}", privacyHandler.UnblockUser)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, "/blocks/valera", nil)
+			req = req.WithContext(context.WithValue(req.Context(), "Sender", "tester"))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
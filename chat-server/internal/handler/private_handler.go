@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/vavelour/chat/pkg/pagination"
+	"io"
 	"net/http"
 
 	"github.com/go-chi/render"
@@ -21,7 +22,15 @@ const (
 	usersReceived    = "users received"
 	messagesReceived = "messages received"
 	messageSent      = "message sent"
+	commandExecuted  = "command executed"
 	messagesNotFound = "no messages found"
+	messagesRead     = "messages marked as read"
+	inboxReceived    = "conversations received"
+	inboxNotFound    = "no conversations found"
+	requestsReceived = "message requests received"
+	requestsNotFound = "no message requests found"
+	requestAccepted  = "message request accepted"
+	requestDeclined  = "message request declined"
 )
 
 var errFailedGetSender = errors.New("failed to get sender")
@@ -29,18 +38,24 @@ var errFailedGetSender = errors.New("failed to get sender")
 //go:generate mockgen -source=private_handler.go -destination=mocks/private_service_mock.go
 
 type PrivateService interface {
-	SendPrivateMessage(m entities.Message) error
-	GetPrivateMessages(sender, recipient string, limit, offset int) ([]entities.Message, error)
-	ViewUsers(user string) ([]string, error)
+	SendPrivateMessage(m entities.Message) (entities.SendResult, error)
+	GetPrivateMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error)
+	ViewUsers(user string) ([]entities.Conversation, error)
+	ReadPrivateMessages(reader, partner string, messageID int) error
+	GetInbox(user string, limit, offset int) ([]entities.Conversation, error)
+	GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error)
+	AcceptMessageRequest(user, partner string) error
+	DeclineMessageRequest(user, partner string) error
 }
 
 type PrivateHandler struct {
-	service  PrivateService
-	validate *validator.Validate
+	service   PrivateService
+	scheduler ScheduledService
+	validate  *validator.Validate
 }
 
-func NewPrivateHAndler(s PrivateService, v *validator.Validate) *PrivateHandler {
-	return &PrivateHandler{service: s, validate: v}
+func NewPrivateHAndler(s PrivateService, scheduler ScheduledService, v *validator.Validate) *PrivateHandler {
+	return &PrivateHandler{service: s, scheduler: scheduler, validate: v}
 }
 
 func (h *PrivateHandler) PrivateRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
@@ -49,14 +64,19 @@ func (h *PrivateHandler) PrivateRoutes(router *chi.Mux, middlewares ...func(next
 			r.Use(mw)
 		}
 		r.Get("/users", h.ViewUserList)
+		r.Get("/conversations", h.ShowInbox)
 		r.Get("/messages", h.ShowPrivateMessages)
 		r.Post("/messages", h.SendPrivateMessage)
+		r.Post("/messages/read", h.ReadPrivateMessages)
+		r.Get("/requests", h.ShowMessageRequests)
+		r.Post("/requests/{username}/accept", h.AcceptMessageRequest)
+		r.Delete("/requests/{username}", h.DeclineMessageRequest)
 	})
 }
 
 // SendPrivateMessage @summary		Отправка приватного сообщения
 //
-//	@description	Отправляет приватное сообщение от имени отправителя указанному получателю.
+//	@description	Отправляет приватное сообщение от имени отправителя указанному получателю. Сообщение, начинающееся с /, выполняется как команда; ответы, видимые только отправителю, возвращаются в поле ephemeral. Чтобы отправить текст с / как есть, начните его с //. Если передано поле send_at, сообщение будет отправлено в указанное время; команды планировать нельзя. Поле ttl_seconds делает сообщение самоуничтожающимся: через указанное время после отправки (или после первого прочтения получателем, если задано expire_after_read) его содержимое удаляется, а в переписке остается пометка об удалении. Первые сообщения пользователю, который еще не писал отправителю, попадают в его запросы на переписку.
 //	@tags			private
 //	@accept			json
 //	@produce		json
@@ -66,7 +86,9 @@ func (h *PrivateHandler) PrivateRoutes(router *chi.Mux, middlewares ...func(next
 //	@param			username	query		string								true	"Имя получателя"
 //	@param			requestBody	body		request.SendPrivateMessageRequest	true	"Данные сообщения"
 //	@success		200			{object}	response.SendPrivateMessageResponse	"Сообщение успешно отправлено"
+//	@success		202			{object}	response.ScheduleMessageResponse	"Сообщение запланировано"
 //	@failure		400			{object}	baseresponse.ResponseError			"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError			"Получатель не принимает сообщения от отправителя"
 //	@router			/v1/private/messages [post]
 func (h *PrivateHandler) SendPrivateMessage(w http.ResponseWriter, r *http.Request) {
 	var input request.SendPrivateMessageRequest
@@ -92,19 +114,32 @@ func (h *PrivateHandler) SendPrivateMessage(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	err = h.service.SendPrivateMessage(mapper.SendPrivateMessageRequestToEntities(input))
-	if err != nil {
+	if input.SendAt != nil {
+		scheduleMessage(w, r, h.scheduler, mapper.SendPrivateMessageRequestToScheduled(input))
+		return
+	}
+
+	res, err := h.service.SendPrivateMessage(mapper.SendPrivateMessageRequestToEntities(input))
+	if errors.Is(err, entities.ErrMessageNotAllowed) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusForbidden, err)
+		return
+	} else if err != nil {
 		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
+	if res.Command {
+		render.JSON(w, r, response.SendPrivateMessageResponse{Response: commandExecuted, Ephemeral: mapper.SendResultToEphemeral(res)})
+		return
+	}
+
 	render.JSON(w, r, response.SendPrivateMessageResponse{Response: messageSent})
 }
 
 // ShowPrivateMessages @summary		Получение приватных сообщений
 //
-//	@description	Получает приватные сообщения между отправителем и получателем с заданным лимитом и смещением.
+//	@description	Получает приватные сообщения между отправителем и получателем с заданным лимитом и смещением. Самоуничтожившиеся сообщения возвращаются пустыми, их индексы перечислены в поле expired. Сообщения упорядочены по ID: order=asc (по умолчанию) возвращает сначала старые, и их страницы не сдвигаются при появлении новых сообщений; order=desc возвращает сначала новые, смещение отсчитывается от последнего сообщения.
 //	@tags			private
 //	@accept			json
 //	@produce		json
@@ -142,7 +177,7 @@ func (h *PrivateHandler) ShowPrivateMessages(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	messages, err := h.service.GetPrivateMessages(input.Sender, input.Recipient, input.Limit, input.Offset)
+	messages, err := h.service.GetPrivateMessages(input.Sender, input.Recipient, input.Limit, input.Offset, entities.Order(input.Order))
 	if err != nil && !errors.Is(err, pagination.ErrOffsetRange) {
 		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
 		return
@@ -158,7 +193,7 @@ func (h *PrivateHandler) ShowPrivateMessages(w http.ResponseWriter, r *http.Requ
 
 // ViewUserList @summary		Получение списка пользователей, от которых поступали сообщения
 //
-//	@description	Получает список пользователей.
+//	@description	Получает список пользователей с количеством непрочитанных сообщений и статусом прочтения последнего отправленного сообщения.
 //	@tags			private
 //	@accept			json
 //	@produce		json
@@ -195,3 +230,200 @@ func (h *PrivateHandler) ViewUserList(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	render.JSON(w, r, mapper.UserListEntitiesToResponse(usersReceived, users))
 }
+
+// ReadPrivateMessages @summary		Отметка приватных сообщений как прочитанных
+//
+//	@description	Сдвигает отметку прочтения переписки с указанным пользователем до заданного сообщения или до последнего, если message_id не передан.
+//	@tags			private
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	query		string									true	"Имя собеседника"
+//	@param			requestBody	body		request.ReadPrivateMessagesRequest		false	"Идентификатор последнего прочитанного сообщения"
+//	@success		200			{object}	response.ReadPrivateMessagesResponse	"Сообщения отмечены как прочитанные"
+//	@failure		400			{object}	baseresponse.ResponseError				"Неверный запрос"
+//	@failure		500			{object}	baseresponse.ResponseError				"Ошибка при получении пользователя"
+//	@router			/v1/private/messages/read [post]
+func (h *PrivateHandler) ReadPrivateMessages(w http.ResponseWriter, r *http.Request) {
+	var input request.ReadPrivateMessagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	reader, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.Reader = reader
+	input.Partner = r.URL.Query().Get("username")
+
+	err := input.Validate(h.validate)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.ReadPrivateMessages(input.Reader, input.Partner, input.MessageID)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.ReadPrivateMessagesResponse{Response: messagesRead})
+}
+
+// ShowInbox @summary		Получение списка переписок
+//
+//	@description	Получает переписки пользователя с последним сообщением и количеством непрочитанных, отсортированные по последней активности.
+//	@tags			private
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.ShowInboxRequest	true	"Параметры запроса переписок"
+//	@success		200			{object}	response.ShowInboxResponse	"Переписки успешно получены"
+//	@failure		400			{object}	baseresponse.ResponseError	"Неверный запрос"
+//	@failure		500			{object}	baseresponse.ResponseError	"Ошибка при получении пользователя"
+//	@router			/v1/private/conversations [get]
+func (h *PrivateHandler) ShowInbox(w http.ResponseWriter, r *http.Request) {
+	var input request.ShowInboxRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.Username = username
+
+	err := input.Validate(h.validate)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	conversations, err := h.service.GetInbox(input.Username, input.Limit, input.Offset)
+	if err != nil && !errors.Is(err, pagination.ErrOffsetRange) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, pagination.ErrOffsetRange) {
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, mapper.InboxEntitiesToResponse(inboxNotFound, conversations))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.InboxEntitiesToResponse(inboxReceived, conversations))
+}
+
+// ShowMessageRequests @summary		Получение запросов на переписку
+//
+//	@description	Получает переписки, начатые пользователями, которым пользователь еще не писал. Они не попадают в список переписок, пока запрос не принят или пока пользователь не ответит.
+//	@tags			private
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.ShowInboxRequest	true	"Параметры запроса"
+//	@success		200			{object}	response.ShowInboxResponse	"Запросы успешно получены"
+//	@failure		400			{object}	baseresponse.ResponseError	"Неверный запрос"
+//	@failure		500			{object}	baseresponse.ResponseError	"Ошибка при получении пользователя"
+//	@router			/v1/private/requests [get]
+func (h *PrivateHandler) ShowMessageRequests(w http.ResponseWriter, r *http.Request) {
+	var input request.ShowInboxRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.Username = username
+
+	err := input.Validate(h.validate)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	conversations, err := h.service.GetMessageRequests(input.Username, input.Limit, input.Offset)
+	if err != nil && !errors.Is(err, pagination.ErrOffsetRange) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, pagination.ErrOffsetRange) {
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, mapper.InboxEntitiesToResponse(requestsNotFound, conversations))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.InboxEntitiesToResponse(requestsReceived, conversations))
+}
+
+// AcceptMessageRequest @summary		Принятие запроса на переписку
+//
+//	@description	Переносит переписку с указанным пользователем в список переписок. После этого пользователь считается контактом и может писать, даже если разрешены сообщения только от контактов.
+//	@tags			private
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string							true	"Имя пользователя, отправившего запрос"
+//	@success		200			{object}	response.MessageRequestResponse	"Запрос принят"
+//	@failure		404			{object}	baseresponse.ResponseError		"Запрос не найден"
+//	@router			/v1/private/requests/{username}/accept [post]
+func (h *PrivateHandler) AcceptMessageRequest(w http.ResponseWriter, r *http.Request) {
+	h.changeMessageRequest(w, r, h.service.AcceptMessageRequest, requestAccepted)
+}
+
+// DeclineMessageRequest @summary		Отклонение запроса на переписку
+//
+//	@description	Удаляет запрос на переписку. Сообщения сохраняются; новое сообщение от того же пользователя создаст новый запрос. Чтобы больше не получать сообщения, пользователя нужно заблокировать.
+//	@tags			private
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string							true	"Имя пользователя, отправившего запрос"
+//	@success		200			{object}	response.MessageRequestResponse	"Запрос отклонен"
+//	@failure		404			{object}	baseresponse.ResponseError		"Запрос не найден"
+//	@router			/v1/private/requests/{username} [delete]
+func (h *PrivateHandler) DeclineMessageRequest(w http.ResponseWriter, r *http.Request) {
+	h.changeMessageRequest(w, r, h.service.DeclineMessageRequest, requestDeclined)
+}
+
+func (h *PrivateHandler) changeMessageRequest(w http.ResponseWriter, r *http.Request, change func(user, partner string) error, resp string) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	err := change(username, chi.URLParam(r, "username"))
+	if errors.Is(err, entities.ErrMessageRequestNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.MessageRequestResponse{Response: resp})
+}
@@ -15,6 +15,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestPrivateHandler_SendPrivateMessage(t *testing.T) {
@@ -33,7 +34,7 @@ func TestPrivateHandler_SendPrivateMessage(t *testing.T) {
 			inputBody:    `{"content": "hello, world!"}`,
 			inputMessage: request.SendPrivateMessageRequest{Sender: "tester", Content: "hello, world!"},
 			mockBehavior: func(s *mock_handler.MockPrivateService, m entities.Message) {
-				s.EXPECT().SendPrivateMessage(m).Return(nil)
+				s.EXPECT().SendPrivateMessage(m).Return(entities.SendResult{}, nil)
 			},
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"response":"message sent"}`,
@@ -59,11 +60,49 @@ func TestPrivateHandler_SendPrivateMessage(t *testing.T) {
 			inputBody:    `{"content": "hello, world!"}`,
 			inputMessage: request.SendPrivateMessageRequest{Sender: "tester", Content: "hello, world!"},
 			mockBehavior: func(s *mock_handler.MockPrivateService, m entities.Message) {
-				s.EXPECT().SendPrivateMessage(m).Return(errors.New("send error"))
+				s.EXPECT().SendPrivateMessage(m).Return(entities.SendResult{}, errors.New("send error"))
 			},
 			expectedStatusCode:  400,
 			expectedRequestBody: `{"error":"send error"}`,
 		},
+		{
+			name:         "not_allowed",
+			inputBody:    `{"content": "hello, world!"}`,
+			inputMessage: request.SendPrivateMessageRequest{Sender: "tester", Content: "hello, world!"},
+			mockBehavior: func(s *mock_handler.MockPrivateService, m entities.Message) {
+				s.EXPECT().SendPrivateMessage(m).Return(entities.SendResult{}, entities.ErrMessageNotAllowed)
+			},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"this user does not accept messages from you"}`,
+		},
+		{
+			name:         "ttl",
+			inputBody:    `{"content": "secret", "ttl_seconds": 60, "expire_after_read": true}`,
+			inputMessage: request.SendPrivateMessageRequest{Sender: "tester", Content: "secret"},
+			mockBehavior: func(s *mock_handler.MockPrivateService, m entities.Message) {
+				m.TTL = time.Minute
+				m.ExpireAfterRead = true
+				s.EXPECT().SendPrivateMessage(m).Return(entities.SendResult{}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"message sent"}`,
+		},
+		{
+			name:                "expire_after_read_without_ttl",
+			inputBody:           `{"content": "secret", "expire_after_read": true}`,
+			inputMessage:        request.SendPrivateMessageRequest{Sender: "tester", Content: "secret"},
+			mockBehavior:        func(s *mock_handler.MockPrivateService, m entities.Message) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"expire_after_read requires ttl_seconds"}`,
+		},
+		{
+			name:                "ttl_with_send_at",
+			inputBody:           `{"content": "secret", "ttl_seconds": 60, "send_at": "2026-10-20T09:00:00Z"}`,
+			inputMessage:        request.SendPrivateMessageRequest{Sender: "tester", Content: "secret"},
+			mockBehavior:        func(s *mock_handler.MockPrivateService, m entities.Message) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"ttl_seconds can not be combined with send_at"}`,
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -74,7 +113,7 @@ func TestPrivateHandler_SendPrivateMessage(t *testing.T) {
 			private := mock_handler.NewMockPrivateService(ctrl)
 			validate := validator.New()
 
-			privateHandler := NewPrivateHAndler(private, validate)
+			privateHandler := NewPrivateHAndler(private, mock_handler.NewMockScheduledService(ctrl), validate)
 
 			r := chi.NewRouter()
 			r.Post("/messages", privateHandler.SendPrivateMessage)
@@ -119,11 +158,45 @@ func TestPrivateHandler_ShowPrivateMessages(t *testing.T) {
 			inputBody:  `{"limit": 1,"offset": 0}`,
 			inputParam: request.ShowPrivateMessageRequest{Sender: "tester", Limit: 1, Offset: 0},
 			mockBehavior: func(s *mock_handler.MockPrivateService, sender, recipient string, limit int, offset int) {
-				s.EXPECT().GetPrivateMessages(sender, recipient, limit, offset).Return([]entities.Message{{Sender: "vika", Recipient: "valera", Content: "hello, world!"}}, nil)
+				s.EXPECT().GetPrivateMessages(sender, recipient, limit, offset, entities.Order("")).Return([]entities.Message{{Sender: "vika", Recipient: "valera", Content: "hello, world!"}}, nil)
 			},
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"response":"messages received","messages":["hello, world!"]}`,
 		},
+		{
+			name:       "expired",
+			inputBody:  `{"limit": 2,"offset": 0}`,
+			inputParam: request.ShowPrivateMessageRequest{Sender: "tester", Limit: 2, Offset: 0},
+			mockBehavior: func(s *mock_handler.MockPrivateService, sender, recipient string, limit int, offset int) {
+				s.EXPECT().GetPrivateMessages(sender, recipient, limit, offset, entities.Order("")).Return([]entities.Message{
+					{Sender: "vika", Recipient: "valera", Expired: true},
+					{Sender: "vika", Recipient: "valera", Content: "hello, world!"},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"messages received","messages":["","hello, world!"],"expired":[0]}`,
+		},
+		{
+			name:       "newest_first",
+			inputBody:  `{"limit": 2,"offset": 0,"order": "desc"}`,
+			inputParam: request.ShowPrivateMessageRequest{Sender: "tester", Limit: 2, Offset: 0},
+			mockBehavior: func(s *mock_handler.MockPrivateService, sender, recipient string, limit int, offset int) {
+				s.EXPECT().GetPrivateMessages(sender, recipient, limit, offset, entities.OrderNewestFirst).Return([]entities.Message{
+					{Sender: "valera", Recipient: "vika", Content: "hi"},
+					{Sender: "vika", Recipient: "valera", Content: "hello, world!"},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"messages received","messages":["hi","hello, world!"]}`,
+		},
+		{
+			name:                "invalid_order",
+			inputBody:           `{"limit": 2,"offset": 0,"order": "random"}`,
+			inputParam:          request.ShowPrivateMessageRequest{Sender: "tester", Limit: 2, Offset: 0},
+			mockBehavior:        func(s *mock_handler.MockPrivateService, sender, recipient string, limit int, offset int) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ShowPrivateMessageRequest.Order' Error:Field validation for 'Order' failed on the 'oneof' tag"}`,
+		},
 		{
 			name:                "invalid_input",
 			inputBody:           `{"limit":}`,
@@ -145,7 +218,7 @@ func TestPrivateHandler_ShowPrivateMessages(t *testing.T) {
 			inputBody:  `{"limit": 5,"offset": 0}`,
 			inputParam: request.ShowPrivateMessageRequest{Sender: "tester", Limit: 5, Offset: 0},
 			mockBehavior: func(s *mock_handler.MockPrivateService, sender, recipient string, limit int, offset int) {
-				s.EXPECT().GetPrivateMessages(sender, recipient, limit, offset).Return(nil, errors.New("service error"))
+				s.EXPECT().GetPrivateMessages(sender, recipient, limit, offset, entities.Order("")).Return(nil, errors.New("service error"))
 			},
 			expectedStatusCode:  400,
 			expectedRequestBody: `{"error":"service error"}`,
@@ -155,7 +228,7 @@ func TestPrivateHandler_ShowPrivateMessages(t *testing.T) {
 			inputBody:  `{"limit": 10, "offset": 1000000000}`,
 			inputParam: request.ShowPrivateMessageRequest{Sender: "tester", Limit: 10, Offset: 1000000000},
 			mockBehavior: func(s *mock_handler.MockPrivateService, sender, recipient string, limit int, offset int) {
-				s.EXPECT().GetPrivateMessages(sender, recipient, limit, offset).Return(nil, pagination.ErrOffsetRange)
+				s.EXPECT().GetPrivateMessages(sender, recipient, limit, offset, entities.Order("")).Return(nil, pagination.ErrOffsetRange)
 			},
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"response":"no messages found","messages":null}`,
@@ -170,7 +243,7 @@ func TestPrivateHandler_ShowPrivateMessages(t *testing.T) {
 			private := mock_handler.NewMockPrivateService(ctrl)
 			validate := validator.New()
 
-			privateHandler := NewPrivateHAndler(private, validate)
+			privateHandler := NewPrivateHAndler(private, mock_handler.NewMockScheduledService(ctrl), validate)
 
 			r := chi.NewRouter()
 			r.Get("/messages", privateHandler.ShowPrivateMessages)
@@ -215,10 +288,10 @@ func TestPrivateHandler_ViewUserList(t *testing.T) {
 			inputBody: "",
 			inputUser: request.ViewUserListRequest{Username: "tester"},
 			mockBehavior: func(s *mock_handler.MockPrivateService, user string) {
-				s.EXPECT().ViewUsers(user).Return([]string{"valera"}, nil)
+				s.EXPECT().ViewUsers(user).Return([]entities.Conversation{{Partner: "valera", UnreadCount: 2, SeenByPartner: true}}, nil)
 			},
 			expectedStatusCode:  200,
-			expectedRequestBody: `{"response":"users received","users":["valera"]}`,
+			expectedRequestBody: `{"response":"users received","users":[{"username":"valera","avatar_url":"/v1/users/valera/avatar","unread_count":2,"seen_by_recipient":true}]}`,
 		},
 		{
 			name:      "service_error",
@@ -256,7 +329,7 @@ func TestPrivateHandler_ViewUserList(t *testing.T) {
 			private := mock_handler.NewMockPrivateService(ctrl)
 			validate := validator.New()
 
-			privateHandler := NewPrivateHAndler(private, validate)
+			privateHandler := NewPrivateHAndler(private, mock_handler.NewMockScheduledService(ctrl), validate)
 
 			r := chi.NewRouter()
 			r.Get("/users", privateHandler.ViewUserList)
@@ -284,3 +357,270 @@ func TestPrivateHandler_ViewUserList(t *testing.T) {
 		})
 	}
 }
+
+func TestPrivateHandler_ReadPrivateMessages(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockPrivateService, reader, partner string, messageID int)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputParam          request.ReadPrivateMessagesRequest
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:       "ok",
+			inputBody:  `{"message_id": 3}`,
+			inputParam: request.ReadPrivateMessagesRequest{Reader: "tester", MessageID: 3},
+			mockBehavior: func(s *mock_handler.MockPrivateService, reader, partner string, messageID int) {
+				s.EXPECT().ReadPrivateMessages(reader, partner, messageID).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"messages marked as read"}`,
+		},
+		{
+			name:       "empty_body",
+			inputBody:  ``,
+			inputParam: request.ReadPrivateMessagesRequest{Reader: "tester", MessageID: 0},
+			mockBehavior: func(s *mock_handler.MockPrivateService, reader, partner string, messageID int) {
+				s.EXPECT().ReadPrivateMessages(reader, partner, messageID).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"messages marked as read"}`,
+		},
+		{
+			name:                "invalid_input",
+			inputBody:           `{"message_id":}`,
+			inputParam:          request.ReadPrivateMessagesRequest{Reader: "tester"},
+			mockBehavior:        func(s *mock_handler.MockPrivateService, reader, partner string, messageID int) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid character '}' looking for beginning of value"}`,
+		},
+		{
+			name:                "invalid_param",
+			inputBody:           `{"message_id": -1}`,
+			inputParam:          request.ReadPrivateMessagesRequest{Reader: "tester", MessageID: -1},
+			mockBehavior:        func(s *mock_handler.MockPrivateService, reader, partner string, messageID int) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ReadPrivateMessagesRequest.MessageID' Error:Field validation for 'MessageID' failed on the 'min' tag"}`,
+		},
+		{
+			name:       "service_error",
+			inputBody:  `{}`,
+			inputParam: request.ReadPrivateMessagesRequest{Reader: "tester"},
+			mockBehavior: func(s *mock_handler.MockPrivateService, reader, partner string, messageID int) {
+				s.EXPECT().ReadPrivateMessages(reader, partner, messageID).Return(errors.New("no chat with this user"))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"no chat with this user"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			private := mock_handler.NewMockPrivateService(ctrl)
+			validate := validator.New()
+
+			privateHandler := NewPrivateHAndler(private, mock_handler.NewMockScheduledService(ctrl), validate)
+
+			r := chi.NewRouter()
+			r.Post("/messages/read", privateHandler.ReadPrivateMessages)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", testCase.inputParam.Reader)
+
+			req := httptest.NewRequest("POST", "/messages/read?username=recipient",
+				bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			partner := req.URL.Query().Get("username")
+
+			testCase.mockBehavior(private, testCase.inputParam.Reader, partner, testCase.inputParam.MessageID)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestPrivateHandler_ShowInbox(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockPrivateService, user string, limit int, offset int)
+
+	createdAt := time.Date(2026, time.October, 19, 9, 55, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		inputParam          request.ShowInboxRequest
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:       "ok",
+			inputBody:  `{"limit": 1,"offset": 0}`,
+			inputParam: request.ShowInboxRequest{Username: "tester", Limit: 1, Offset: 0},
+			mockBehavior: func(s *mock_handler.MockPrivateService, user string, limit int, offset int) {
+				s.EXPECT().GetInbox(user, limit, offset).Return([]entities.Conversation{{
+					Partner:     "valera",
+					LastMessage: entities.Message{ID: 7, Sender: "valera", Recipient: "tester", Content: "hello", CreatedAt: createdAt},
+					UnreadCount: 1,
+				}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"conversations received","conversations":[{"username":"valera","avatar_url":"/v1/users/valera/avatar","last_message":{"id":7,"sender":"valera","sender_avatar_url":"/v1/users/valera/avatar","content":"hello","created_at":"2026-10-19T09:55:00Z"},"unread_count":1,"seen_by_recipient":false}]}`,
+		},
+		{
+			name:                "invalid_param",
+			inputBody:           `{"limit": 0,"offset": 0}`,
+			inputParam:          request.ShowInboxRequest{Username: "tester"},
+			mockBehavior:        func(s *mock_handler.MockPrivateService, user string, limit int, offset int) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ShowInboxRequest.Limit' Error:Field validation for 'Limit' failed on the 'min' tag"}`,
+		},
+		{
+			name:       "service_error",
+			inputBody:  `{"limit": 5,"offset": 0}`,
+			inputParam: request.ShowInboxRequest{Username: "tester", Limit: 5, Offset: 0},
+			mockBehavior: func(s *mock_handler.MockPrivateService, user string, limit int, offset int) {
+				s.EXPECT().GetInbox(user, limit, offset).Return(nil, errors.New("service error"))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"service error"}`,
+		},
+		{
+			name:       "offset_out_of_range",
+			inputBody:  `{"limit": 10, "offset": 100}`,
+			inputParam: request.ShowInboxRequest{Username: "tester", Limit: 10, Offset: 100},
+			mockBehavior: func(s *mock_handler.MockPrivateService, user string, limit int, offset int) {
+				s.EXPECT().GetInbox(user, limit, offset).Return(nil, pagination.ErrOffsetRange)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"no conversations found","conversations":null}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			private := mock_handler.NewMockPrivateService(ctrl)
+			validate := validator.New()
+
+			privateHandler := NewPrivateHAndler(private, mock_handler.NewMockScheduledService(ctrl), validate)
+
+			r := chi.NewRouter()
+			r.Get("/conversations", privateHandler.ShowInbox)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", testCase.inputParam.Username)
+
+			req := httptest.NewRequest("GET", "/conversations",
+				bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(private, testCase.inputParam.Username, testCase.inputParam.Limit, testCase.inputParam.Offset)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestPrivateHandler_AcceptMessageRequest(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockPrivateService)
+
+	testTable := []struct {
+		name                string
+		method              string
+		path                string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "accept",
+			method: "POST",
+			path:   "/requests/valera/accept",
+			mockBehavior: func(s *mock_handler.MockPrivateService) {
+				s.EXPECT().AcceptMessageRequest("tester", "valera").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"message request accepted"}`,
+		},
+		{
+			name:   "accept_not_found",
+			method: "POST",
+			path:   "/requests/valera/accept",
+			mockBehavior: func(s *mock_handler.MockPrivateService) {
+				s.EXPECT().AcceptMessageRequest("tester", "valera").Return(entities.ErrMessageRequestNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"message request not found"}`,
+		},
+		{
+			name:   "decline",
+			method: "DELETE",
+			path:   "/requests/valera",
+			mockBehavior: func(s *mock_handler.MockPrivateService) {
+				s.EXPECT().DeclineMessageRequest("tester", "valera").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"message request declined"}`,
+		},
+		{
+			name:   "decline_error",
+			method: "DELETE",
+			path:   "/requests/valera",
+			mockBehavior: func(s *mock_handler.MockPrivateService) {
+				s.EXPECT().DeclineMessageRequest("tester", "valera").Return(errors.New("db error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"db error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			private := mock_handler.NewMockPrivateService(ctrl)
+			testCase.mockBehavior(private)
+
+			privateHandler := NewPrivateHAndler(private, mock_handler.NewMockScheduledService(ctrl), validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/requests/{username}/accept", privateHandler.AcceptMessageRequest)
+			r.Delete("/requests/{username}", privateHandler.DeclineMessageRequest)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.path, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "Sender", "tester"))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
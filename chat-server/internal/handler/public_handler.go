@@ -21,17 +21,18 @@ import (
 //go:generate mockgen -source=public_handler.go -destination=mocks/public_service_mock.go
 
 type PublicService interface {
-	SendPublicMessage(m entities.Message) error
-	GetPublicMessages(limit, offset int) ([]entities.Message, error)
+	SendPublicMessage(m entities.Message) (entities.SendResult, error)
+	GetPublicMessages(viewer string, limit, offset int, order entities.Order) ([]entities.Message, error)
 }
 
 type PublicHandler struct {
-	service  PublicService
-	validate *validator.Validate
+	service   PublicService
+	scheduler ScheduledService
+	validate  *validator.Validate
 }
 
-func NewPublicHandler(h PublicService, v *validator.Validate) *PublicHandler {
-	return &PublicHandler{service: h, validate: v}
+func NewPublicHandler(h PublicService, scheduler ScheduledService, v *validator.Validate) *PublicHandler {
+	return &PublicHandler{service: h, scheduler: scheduler, validate: v}
 }
 
 func (h *PublicHandler) PublicRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
@@ -46,7 +47,7 @@ func (h *PublicHandler) PublicRoutes(router *chi.Mux, middlewares ...func(next h
 
 // SendPublicMessage @summary		Отправка сообщения в публичный чат
 //
-//	@description	Отправляет сообщение в публичный чат от имени пользователя.
+//	@description	Отправляет сообщение в публичный чат от имени пользователя. Сообщение, начинающееся с /, выполняется как команда (список — /help); ответы, видимые только отправителю, возвращаются в поле ephemeral. Чтобы отправить текст с / как есть, начните его с //. Если передано поле send_at, сообщение будет отправлено в указанное время; команды планировать нельзя.
 //	@tags			public
 //	@accept			json
 //	@produce		json
@@ -55,8 +56,10 @@ func (h *PublicHandler) PublicRoutes(router *chi.Mux, middlewares ...func(next h
 //
 //	@param			requestBody	body		request.SendPublicMessageRequest	true	"Данные сообщения"
 //	@success		200			{object}	response.SendPublicMessageResponse	"Сообщение успешно отправлено"
+//	@success		202			{object}	response.ScheduleMessageResponse	"Сообщение запланировано"
 //	@failure		500			{object}	baseresponse.ResponseError			"Ошибка при отправке сообщения"
 //	@failure		400			{object}	baseresponse.ResponseError			"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError			"Пользователь заглушён или заблокирован в публичном чате"
 //	@router			/v1/public/messages [post]
 func (h *PublicHandler) SendPublicMessage(w http.ResponseWriter, r *http.Request) {
 	var input request.SendPublicMessageRequest
@@ -79,19 +82,32 @@ func (h *PublicHandler) SendPublicMessage(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = h.service.SendPublicMessage(mapper.SendPublicMessageRequestToEntities(input))
-	if err != nil {
+	if input.SendAt != nil {
+		scheduleMessage(w, r, h.scheduler, mapper.SendPublicMessageRequestToScheduled(input))
+		return
+	}
+
+	res, err := h.service.SendPublicMessage(mapper.SendPublicMessageRequestToEntities(input))
+	if sanctioned(err) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusForbidden, err)
+		return
+	} else if err != nil {
 		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
+	if res.Command {
+		render.JSON(w, r, response.SendPublicMessageResponse{Response: commandExecuted, Ephemeral: mapper.SendResultToEphemeral(res)})
+		return
+	}
+
 	render.JSON(w, r, response.SendPublicMessageResponse{Response: messageSent})
 }
 
 // ShowPublicMessages @summary		Получение сообщений из публичного чата
 //
-//	@description	Получает сообщения из публичного чата с заданным лимитом и смещением.
+//	@description	Получает сообщения из публичного чата с заданным лимитом и смещением. Сообщения заблокированных пользователей скрываются, если это включено в настройках приватности; поэтому страница может содержать меньше сообщений, чем limit. Сообщения упорядочены по ID: order=asc (по умолчанию) возвращает сначала старые, и их страницы не сдвигаются при появлении новых сообщений; order=desc возвращает сначала новые, смещение отсчитывается от последнего сообщения.
 //	@tags			public
 //	@accept			json
 //	@produce		json
@@ -101,6 +117,7 @@ func (h *PublicHandler) SendPublicMessage(w http.ResponseWriter, r *http.Request
 //	@param			requestBody	body		request.ShowPublicMessageRequest	true	"Параметры запроса сообщений"
 //	@success		200			{object}	response.ShowPublicMessageResponse	"Сообщения успешно получены"
 //	@failure		400			{object}	baseresponse.ResponseError			"Неверный запрос"
+//	@failure		500			{object}	baseresponse.ResponseError			"Ошибка при получении пользователя"
 //	@failure		416			{object}	baseresponse.ResponseError			"Запрос содержит невыполнимый диапазон"
 //	@router			/v1/public/messages [get]
 func (h *PublicHandler) ShowPublicMessages(w http.ResponseWriter, r *http.Request) {
@@ -116,7 +133,13 @@ func (h *PublicHandler) ShowPublicMessages(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	messages, err := h.service.GetPublicMessages(input.Limit, input.Offset)
+	viewer, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	messages, err := h.service.GetPublicMessages(viewer, input.Limit, input.Offset, entities.Order(input.Order))
 	if err != nil && !errors.Is(err, pagination.ErrOffsetRange) {
 		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
 		return
@@ -15,6 +15,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestPublicHandler_SendPublicMessage(t *testing.T) {
@@ -33,11 +34,23 @@ func TestPublicHandler_SendPublicMessage(t *testing.T) {
 			inputBody:    `{"content": "hello, world!"}`,
 			inputMessage: request.SendPublicMessageRequest{Sender: "tester", Content: "hello, world!"},
 			mockBehavior: func(s *mock_handler.MockPublicService, m entities.Message) {
-				s.EXPECT().SendPublicMessage(m).Return(nil)
+				s.EXPECT().SendPublicMessage(m).Return(entities.SendResult{}, nil)
 			},
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"response":"message sent"}`,
 		},
+		{
+			name:         "command",
+			inputBody:    `{"content": "/who"}`,
+			inputMessage: request.SendPublicMessageRequest{Sender: "tester", Content: "/who"},
+			mockBehavior: func(s *mock_handler.MockPublicService, m entities.Message) {
+				s.EXPECT().SendPublicMessage(m).Return(entities.SendResult{Command: true, Ephemeral: []entities.Message{
+					{Recipient: "tester", Content: "Online: tester", CreatedAt: time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)},
+				}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"command executed","ephemeral":[{"content":"Online: tester","created_at":"2026-10-19T09:00:00Z"}]}`,
+		},
 		{
 			name:                "invalid_input",
 			inputBody:           `{"content": invalid}`,
@@ -59,11 +72,21 @@ func TestPublicHandler_SendPublicMessage(t *testing.T) {
 			inputBody:    `{"content": "hello, world!"}`,
 			inputMessage: request.SendPublicMessageRequest{Sender: "tester", Content: "hello, world!"},
 			mockBehavior: func(s *mock_handler.MockPublicService, m entities.Message) {
-				s.EXPECT().SendPublicMessage(m).Return(errors.New("send error"))
+				s.EXPECT().SendPublicMessage(m).Return(entities.SendResult{}, errors.New("send error"))
 			},
 			expectedStatusCode:  500,
 			expectedRequestBody: `{"error":"send error"}`,
 		},
+		{
+			name:         "muted",
+			inputBody:    `{"content": "hello, world!"}`,
+			inputMessage: request.SendPublicMessageRequest{Sender: "tester", Content: "hello, world!"},
+			mockBehavior: func(s *mock_handler.MockPublicService, m entities.Message) {
+				s.EXPECT().SendPublicMessage(m).Return(entities.SendResult{}, entities.ErrMuted)
+			},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"you are muted in the public chat"}`,
+		},
 		{
 			name:                "failed_get_sender",
 			inputBody:           `{"content": "hello, world!"}`,
@@ -83,7 +106,7 @@ func TestPublicHandler_SendPublicMessage(t *testing.T) {
 			validate := validator.New()
 			testCase.mockBehavior(public, entities.Message{Sender: testCase.inputMessage.Sender, Content: testCase.inputMessage.Content})
 
-			publicHandler := NewPublicHandler(public, validate)
+			publicHandler := NewPublicHandler(public, mock_handler.NewMockScheduledService(ctrl), validate)
 
 			r := chi.NewRouter()
 			r.Post("/messages", publicHandler.SendPublicMessage)
@@ -126,21 +149,68 @@ func TestPublicHandler_ShowPublicMessages(t *testing.T) {
 			inputBody:  `{"limit": 1,"offset": 0}`,
 			inputParam: request.ShowPublicMessageRequest{Limit: 1, Offset: 0},
 			mockBehavior: func(s *mock_handler.MockPublicService, limit int, offset int) {
-				s.EXPECT().GetPublicMessages(limit, offset).Return([]entities.Message{{Sender: "valera", Content: "hello, world!"}}, nil)
+				s.EXPECT().GetPublicMessages("tester", limit, offset, entities.Order("")).Return([]entities.Message{{ID: 1, Sender: "valera", Content: "hello, world!"}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"messages received","messages":["hello, world!"],"ids":[1]}`,
+		},
+		{
+			name:       "ok_with_attachments",
+			inputBody:  `{"limit": 2,"offset": 0}`,
+			inputParam: request.ShowPublicMessageRequest{Limit: 2, Offset: 0},
+			mockBehavior: func(s *mock_handler.MockPublicService, limit int, offset int) {
+				s.EXPECT().GetPublicMessages("tester", limit, offset, entities.Order("")).Return([]entities.Message{
+					{ID: 1, Sender: "valera", Content: "hello, world!"},
+					{ID: 2, Sender: "vika", Attachments: []entities.Attachment{{ID: "a1", FileName: "cat.png", ContentType: "image/png", Size: 42}}},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"messages received","messages":["hello, world!",""],"ids":[1,2],"attachments":[{"message_index":1,"id":"a1","file_name":"cat.png","content_type":"image/png","size":42}]}`,
+		},
+		{
+			name:       "ok_with_deleted",
+			inputBody:  `{"limit": 2,"offset": 0}`,
+			inputParam: request.ShowPublicMessageRequest{Limit: 2, Offset: 0},
+			mockBehavior: func(s *mock_handler.MockPublicService, limit int, offset int) {
+				s.EXPECT().GetPublicMessages("tester", limit, offset, entities.Order("")).Return([]entities.Message{
+					{ID: 1, Sender: "valera", Deleted: true},
+					{ID: 2, Sender: "vika", Content: "hi"},
+				}, nil)
 			},
 			expectedStatusCode:  200,
-			expectedRequestBody: `{"response":"messages received","messages":["hello, world!"]}`,
+			expectedRequestBody: `{"response":"messages received","messages":["","hi"],"ids":[1,2],"deleted":[0]}`,
 		},
 		{
 			name:       "offset_out_of_range",
 			inputBody:  `{"limit": 10, "offset": 1000000000}`,
 			inputParam: request.ShowPublicMessageRequest{Limit: 10, Offset: 1000000000},
 			mockBehavior: func(s *mock_handler.MockPublicService, limit int, offset int) {
-				s.EXPECT().GetPublicMessages(limit, offset).Return([]entities.Message{}, pagination.ErrOffsetRange)
+				s.EXPECT().GetPublicMessages("tester", limit, offset, entities.Order("")).Return([]entities.Message{}, pagination.ErrOffsetRange)
 			},
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"response":"no messages found","messages":null}`,
 		},
+		{
+			name:       "newest_first",
+			inputBody:  `{"limit": 2,"offset": 0,"order": "desc"}`,
+			inputParam: request.ShowPublicMessageRequest{Limit: 2, Offset: 0},
+			mockBehavior: func(s *mock_handler.MockPublicService, limit int, offset int) {
+				s.EXPECT().GetPublicMessages("tester", limit, offset, entities.OrderNewestFirst).Return([]entities.Message{
+					{ID: 2, Sender: "vika", Content: "hi"},
+					{ID: 1, Sender: "valera", Content: "hello, world!"},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"messages received","messages":["hi","hello, world!"],"ids":[2,1]}`,
+		},
+		{
+			name:                "invalid_order",
+			inputBody:           `{"limit": 2,"offset": 0,"order": "random"}`,
+			inputParam:          request.ShowPublicMessageRequest{Limit: 2, Offset: 0},
+			mockBehavior:        func(s *mock_handler.MockPublicService, limit int, offset int) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'ShowPublicMessageRequest.Order' Error:Field validation for 'Order' failed on the 'oneof' tag"}`,
+		},
 		{
 			name:                "invalid_input",
 			inputBody:           `{"limit": "invalid", "offset": "invalid"}`,
@@ -162,7 +232,7 @@ func TestPublicHandler_ShowPublicMessages(t *testing.T) {
 			inputBody:  `{"limit": 10, "offset": 0}`,
 			inputParam: request.ShowPublicMessageRequest{Limit: 10, Offset: 0},
 			mockBehavior: func(s *mock_handler.MockPublicService, limit int, offset int) {
-				s.EXPECT().GetPublicMessages(limit, offset).Return(nil, errors.New("service error"))
+				s.EXPECT().GetPublicMessages("tester", limit, offset, entities.Order("")).Return(nil, errors.New("service error"))
 			},
 			expectedStatusCode:  400,
 			expectedRequestBody: `{"error":"service error"}`,
@@ -178,7 +248,7 @@ func TestPublicHandler_ShowPublicMessages(t *testing.T) {
 			validate := validator.New()
 			testCase.mockBehavior(public, testCase.inputParam.Limit, testCase.inputParam.Offset)
 
-			publicHandler := NewPublicHandler(public, validate)
+			publicHandler := NewPublicHandler(public, mock_handler.NewMockScheduledService(ctrl), validate)
 
 			r := chi.NewRouter()
 			r.Get("/messages", publicHandler.ShowPublicMessages)
@@ -187,6 +257,7 @@ func TestPublicHandler_ShowPublicMessages(t *testing.T) {
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest("GET", "/messages",
 				bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(context.WithValue(req.Context(), "Sender", "tester"))
 
 			// Serve
 			r.ServeHTTP(w, req)
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/cron"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	reminderCreated   = "reminder created"
+	remindersReceived = "reminders received"
+	reminderPaused    = "reminder paused"
+	reminderResumed   = "reminder resumed"
+	reminderDeleted   = "reminder deleted"
+)
+
+var errInvalidReminderID = errors.New("reminder id must be a number")
+
+//go:generate mockgen -source=reminder_handler.go -destination=mocks/reminder_service_mock.go
+
+type ReminderService interface {
+	Create(rem entities.Reminder) (entities.Reminder, error)
+	List() ([]entities.Reminder, error)
+	Pause(id int) (entities.Reminder, error)
+	Resume(id int) (entities.Reminder, error)
+	Delete(id int) error
+}
+
+type ReminderHandler struct {
+	service  ReminderService
+	validate *validator.Validate
+}
+
+func NewReminderHandler(s ReminderService, v *validator.Validate) *ReminderHandler {
+	return &ReminderHandler{service: s, validate: v}
+}
+
+// ReminderRoutes registers the admin API. The middlewares have to include
+// the admin guard.
+func (h *ReminderHandler) ReminderRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/admin/reminders", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ListReminders)
+		r.Post("/", h.CreateReminder)
+		r.Post("/{id}/pause", h.PauseReminder)
+		r.Post("/{id}/resume", h.ResumeReminder)
+		r.Delete("/{id}", h.DeleteReminder)
+	})
+}
+
+// CreateReminder @summary		Создание напоминания
+//
+//	@description	Создает повторяющееся напоминание: текст публикуется в публичный чат от имени системного бота по расписанию cron (5 полей или @daily, @weekly и т.п.) в указанном часовом поясе, по умолчанию UTC.
+//	@tags			admin
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.CreateReminderRequest	true	"Параметры напоминания"
+//	@success		201			{object}	response.ReminderResponse		"Напоминание создано"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверное расписание или часовой пояс"
+//	@failure		403			{object}	baseresponse.ResponseError		"Доступно только администраторам"
+//	@failure		409			{object}	baseresponse.ResponseError		"Имя бота занято пользователем"
+//	@router			/v1/admin/reminders [post]
+func (h *ReminderHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
+	var input request.CreateReminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	admin, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.CreatedBy = admin
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	reminder, err := h.service.Create(mapper.CreateReminderRequestToEntity(input))
+	switch {
+	case errors.Is(err, cron.ErrInvalidExpression),
+		errors.Is(err, entities.ErrInvalidTimeZone),
+		errors.Is(err, entities.ErrReminderNeverFires):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, entities.ErrBotNameTaken):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusConflict, err)
+		return
+	case err != nil:
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	render.JSON(w, r, response.ReminderResponse{Response: reminderCreated, Reminder: mapper.ReminderToResponse(reminder)})
+}
+
+// ListReminders @summary		Список напоминаний
+//
+//	@description	Возвращает все напоминания со временем следующего и последнего запуска.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.ListRemindersResponse	"Напоминания получены"
+//	@failure		403	{object}	baseresponse.ResponseError		"Доступно только администраторам"
+//	@router			/v1/admin/reminders [get]
+func (h *ReminderHandler) ListReminders(w http.ResponseWriter, r *http.Request) {
+	reminders, err := h.service.List()
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.RemindersToResponse(remindersReceived, reminders))
+}
+
+// PauseReminder @summary		Приостановка напоминания
+//
+//	@description	Приостанавливает напоминание: оно не публикуется, пока его не возобновят.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		int							true	"Идентификатор напоминания"
+//	@success		200	{object}	response.ReminderResponse	"Напоминание приостановлено"
+//	@failure		404	{object}	baseresponse.ResponseError	"Напоминание не найдено"
+//	@router			/v1/admin/reminders/{id}/pause [post]
+func (h *ReminderHandler) PauseReminder(w http.ResponseWriter, r *http.Request) {
+	h.changeReminder(w, r, h.service.Pause, reminderPaused)
+}
+
+// ResumeReminder @summary		Возобновление напоминания
+//
+//	@description	Возобновляет напоминание со следующего времени по расписанию. Пропущенные за время паузы запуски не публикуются.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		int							true	"Идентификатор напоминания"
+//	@success		200	{object}	response.ReminderResponse	"Напоминание возобновлено"
+//	@failure		404	{object}	baseresponse.ResponseError	"Напоминание не найдено"
+//	@router			/v1/admin/reminders/{id}/resume [post]
+func (h *ReminderHandler) ResumeReminder(w http.ResponseWriter, r *http.Request) {
+	h.changeReminder(w, r, h.service.Resume, reminderResumed)
+}
+
+// DeleteReminder @summary		Удаление напоминания
+//
+//	@description	Удаляет напоминание. Уже опубликованные сообщения остаются.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		int							true	"Идентификатор напоминания"
+//	@success		200	{object}	response.WebhookResponse	"Напоминание удалено"
+//	@failure		404	{object}	baseresponse.ResponseError	"Напоминание не найдено"
+//	@router			/v1/admin/reminders/{id} [delete]
+func (h *ReminderHandler) DeleteReminder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidReminderID)
+		return
+	}
+
+	err = h.service.Delete(id)
+	if errors.Is(err, entities.ErrReminderNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.WebhookResponse{Response: reminderDeleted})
+}
+
+func (h *ReminderHandler) changeReminder(w http.ResponseWriter, r *http.Request, change func(id int) (entities.Reminder, error), resp string) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidReminderID)
+		return
+	}
+
+	reminder, err := change(id)
+	switch {
+	case errors.Is(err, entities.ErrReminderNotFound):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	case errors.Is(err, entities.ErrReminderNeverFires):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.ReminderResponse{Response: resp, Reminder: mapper.ReminderToResponse(reminder)})
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"github.com/vavelour/chat/pkg/cron"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReminderHandler_CreateReminder(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockReminderService)
+
+	nextRunAt := time.Date(2026, time.October, 20, 6, 55, 0, 0, time.UTC)
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"name": "stand-up", "content": "stand-up!", "schedule": "55 9 * * mon-fri", "time_zone": "Europe/Minsk"}`,
+			mockBehavior: func(s *mock_handler.MockReminderService) {
+				s.EXPECT().Create(entities.Reminder{Name: "stand-up", Content: "stand-up!", Schedule: "55 9 * * mon-fri", TimeZone: "Europe/Minsk", CreatedBy: "admin"}).
+					Return(entities.Reminder{
+						ID: 1, Name: "stand-up", Content: "stand-up!", Schedule: "55 9 * * mon-fri", TimeZone: "Europe/Minsk",
+						Sender: "reminders", NextRunAt: nextRunAt, CreatedBy: "admin", CreatedAt: createdAt,
+					}, nil)
+			},
+			expectedStatusCode: 201,
+			expectedRequestBody: `{"response":"reminder created","reminder":{"id":1,"name":"stand-up","content":"stand-up!",` +
+				`"schedule":"55 9 * * mon-fri","time_zone":"Europe/Minsk","sender":"reminders","paused":false,` +
+				`"next_run_at":"2026-10-20T06:55:00Z","created_by":"admin","created_at":"2026-10-19T09:00:00Z"}}`,
+		},
+		{
+			name:      "invalid_schedule",
+			inputBody: `{"name": "stand-up", "content": "stand-up!", "schedule": "every day"}`,
+			mockBehavior: func(s *mock_handler.MockReminderService) {
+				s.EXPECT().Create(gomock.Any()).Return(entities.Reminder{}, fmt.Errorf("%w: expected 5 fields, got 2", cron.ErrInvalidExpression))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid cron expression: expected 5 fields, got 2"}`,
+		},
+		{
+			name:      "invalid_time_zone",
+			inputBody: `{"name": "stand-up", "content": "stand-up!", "schedule": "@daily", "time_zone": "Mars/Olympus"}`,
+			mockBehavior: func(s *mock_handler.MockReminderService) {
+				s.EXPECT().Create(gomock.Any()).Return(entities.Reminder{}, entities.ErrInvalidTimeZone)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"unknown time zone"}`,
+		},
+		{
+			name:      "sender_taken",
+			inputBody: `{"name": "stand-up", "content": "stand-up!", "schedule": "@daily"}`,
+			mockBehavior: func(s *mock_handler.MockReminderService) {
+				s.EXPECT().Create(gomock.Any()).Return(entities.Reminder{}, entities.ErrBotNameTaken)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"error":"` + entities.ErrBotNameTaken.Error() + `"}`,
+		},
+		{
+			name:      "create_error",
+			inputBody: `{"name": "stand-up", "content": "stand-up!", "schedule": "@daily"}`,
+			mockBehavior: func(s *mock_handler.MockReminderService) {
+				s.EXPECT().Create(gomock.Any()).Return(entities.Reminder{}, errors.New("insert error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"insert error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reminders := mock_handler.NewMockReminderService(ctrl)
+			reminderHandler := NewReminderHandler(reminders, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/reminders", reminderHandler.CreateReminder)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "admin")
+
+			req := httptest.NewRequest("POST", "/reminders", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(reminders)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestReminderHandler_PauseReminder(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockReminderService)
+
+	testTable := []struct {
+		name                string
+		id                  string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "not_found",
+			id:   "8",
+			mockBehavior: func(s *mock_handler.MockReminderService) {
+				s.EXPECT().Pause(8).Return(entities.Reminder{}, entities.ErrReminderNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"reminder not found"}`,
+		},
+		{
+			name:                "invalid_id",
+			id:                  "abc",
+			mockBehavior:        func(s *mock_handler.MockReminderService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"reminder id must be a number"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reminders := mock_handler.NewMockReminderService(ctrl)
+			reminderHandler := NewReminderHandler(reminders, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/reminders/{id}/pause", reminderHandler.PauseReminder)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest("POST", "/reminders/"+testCase.id+"/pause", nil)
+
+			testCase.mockBehavior(reminders)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package request
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// commandPattern is the name of a command without the slash, as it is typed.
+var commandPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+var errInvalidCommand = errors.New("command must start with a letter and contain only lowercase letters, digits, '_' and '-'")
+
+type CreateBotCommandRequest struct {
+	CreatedBy   string `validate:"required"`
+	Command     string `json:"command" validate:"required"`
+	URL         string `json:"url" validate:"required,http_url,max=2048"`
+	Description string `json:"description" validate:"max=255"`
+	BotName     string `json:"bot_name" validate:"required,min=2,max=32"`
+}
+
+func (r *CreateBotCommandRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	if !commandPattern.MatchString(r.Command) {
+		return errInvalidCommand
+	}
+
+	if !botNamePattern.MatchString(r.BotName) {
+		return errInvalidBotName
+	}
+
+	return nil
+}
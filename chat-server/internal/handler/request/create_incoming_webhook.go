@@ -0,0 +1,33 @@
+package request
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// botNamePattern keeps bot names mentionable as @name.
+var botNamePattern = regexp.MustCompile(`^\w+(?:[.-]\w+)*$`)
+
+var errInvalidBotName = errors.New("bot name may contain only letters, digits, '_', '.' and '-'")
+
+type CreateIncomingWebhookRequest struct {
+	CreatedBy string `validate:"required"`
+	Name      string `json:"name" validate:"required,max=255"`
+	BotName   string `json:"bot_name" validate:"required,min=2,max=32"`
+	Channel   string `json:"channel" validate:"omitempty,oneof=public"`
+}
+
+func (r *CreateIncomingWebhookRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	if !botNamePattern.MatchString(r.BotName) {
+		return errInvalidBotName
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type CreateReminderRequest struct {
+	CreatedBy string `validate:"required"`
+	Name      string `json:"name" validate:"required,max=64"`
+	Content   string `json:"content" validate:"required,max=4096"`
+	Schedule  string `json:"schedule" validate:"required,max=128"`
+	TimeZone  string `json:"time_zone" validate:"max=64"`
+}
+
+func (r *CreateReminderRequest) Validate(v *validator.Validate) error {
+	return v.Struct(r)
+}
@@ -0,0 +1,20 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type CreateWebhookRequest struct {
+	CreatedBy string   `validate:"required"`
+	URL       string   `json:"url" validate:"required,http_url,max=2048"`
+	Events    []string `json:"events" validate:"required,min=1,dive,oneof=message.created message.deleted"`
+	Channel   string   `json:"channel" validate:"omitempty,oneof=public"`
+	Secret    string   `json:"secret" validate:"omitempty,min=16,max=255"`
+}
+
+func (r *CreateWebhookRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type ShowReportsRequest struct {
+	Status string `validate:"oneof=open resolved dismissed"`
+	Limit  int    `validate:"min=1,max=100"`
+	Offset int    `validate:"min=0"`
+}
+
+func (r *ShowReportsRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type ShowAuditLogRequest struct {
+	Limit  int `validate:"min=1,max=100"`
+	Offset int `validate:"min=0"`
+}
+
+func (r *ShowAuditLogRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ModerationReasonRequest is the optional body of the moderation actions
+// which need nothing but a reason for the audit log.
+type ModerationReasonRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+func (r *ModerationReasonRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type SanctionUserRequest struct {
+	// DurationSeconds is how long the sanction lasts, 0 for a permanent
+	// ban or suspension.
+	DurationSeconds int    `json:"duration_seconds" validate:"min=0,max=31536000"`
+	Reason          string `json:"reason" validate:"max=500"`
+}
+
+func (r *SanctionUserRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
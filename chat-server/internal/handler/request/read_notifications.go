@@ -0,0 +1,20 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+// ReadNotificationsRequest marks the listed notifications as read, or all
+// of them when All is set.
+type ReadNotificationsRequest struct {
+	Username string `validate:"required"`
+	IDs      []int  `json:"ids" validate:"required_without=All,omitempty,min=1,max=1000,dive,min=1"`
+	All      bool   `json:"all"`
+}
+
+func (r *ReadNotificationsRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type ReadPrivateMessagesRequest struct {
+	Reader    string `validate:"required"`
+	Partner   string `validate:"required"`
+	MessageID int    `json:"message_id" validate:"min=0"`
+}
+
+func (r *ReadPrivateMessagesRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type ReportMessageRequest struct {
+	Reporter  string `json:"-" validate:"required"`
+	MessageID int    `json:"message_id" validate:"min=1"`
+	Reason    string `json:"reason" validate:"required,max=500"`
+}
+
+func (r *ReportMessageRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
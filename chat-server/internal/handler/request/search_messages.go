@@ -0,0 +1,22 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type SearchMessagesRequest struct {
+	Username string `validate:"required"`
+	Query    string `validate:"required,max=256"`
+	Sender   string
+	From     string `validate:"omitempty,datetime=2006-01-02"`
+	To       string `validate:"omitempty,datetime=2006-01-02"`
+	Limit    int    `validate:"min=1,max=100"`
+	Cursor   string
+}
+
+func (r *SearchMessagesRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
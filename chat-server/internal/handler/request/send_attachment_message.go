@@ -0,0 +1,20 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type SendAttachmentMessageRequest struct {
+	Sender    string `validate:"required"`
+	Chat      string `validate:"oneof=private public"`
+	Recipient string `validate:"required_if=Chat private"`
+	Content   string `validate:"required_without=Files"`
+	Files     int
+}
+
+func (r *SendAttachmentMessageRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,11 +1,27 @@
 package request
 
-import "github.com/go-playground/validator/v10"
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	errExpireAfterReadWithoutTTL = errors.New("expire_after_read requires ttl_seconds")
+	errScheduledTTL              = errors.New("ttl_seconds can not be combined with send_at")
+)
 
 type SendPrivateMessageRequest struct {
 	Sender    string `validate:"required"`
 	Recipient string `validate:"required"`
 	Content   string `json:"content" validate:"required"`
+	// SendAt schedules the message instead of sending it at once.
+	SendAt *time.Time `json:"send_at"`
+	// TTLSeconds makes the message self-destruct that long after it is
+	// sent or, with ExpireAfterRead, after the recipient first reads it.
+	TTLSeconds      int  `json:"ttl_seconds" validate:"omitempty,min=1,max=2592000"`
+	ExpireAfterRead bool `json:"expire_after_read"`
 }
 
 func (r *SendPrivateMessageRequest) Validate(v *validator.Validate) error {
@@ -14,5 +30,13 @@ func (r *SendPrivateMessageRequest) Validate(v *validator.Validate) error {
 		return err
 	}
 
+	if r.ExpireAfterRead && r.TTLSeconds == 0 {
+		return errExpireAfterReadWithoutTTL
+	}
+
+	if r.SendAt != nil && r.TTLSeconds > 0 {
+		return errScheduledTTL
+	}
+
 	return nil
 }
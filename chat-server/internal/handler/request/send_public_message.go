@@ -1,11 +1,17 @@
 package request
 
-import "github.com/go-playground/validator/v10"
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type SendPublicMessageRequest struct {
 	Sender    string `validate:"required"`
 	Recipient string
 	Content   string `json:"content" validate:"required"`
+	// SendAt schedules the message instead of sending it at once.
+	SendAt *time.Time `json:"send_at"`
 }
 
 func (r *SendPublicMessageRequest) Validate(v *validator.Validate) error {
@@ -0,0 +1,18 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type ShowInboxRequest struct {
+	Username string `validate:"required"`
+	Limit    int    `json:"limit" validate:"min=1"`
+	Offset   int    `json:"offset" validate:"min=0"`
+}
+
+func (r *ShowInboxRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
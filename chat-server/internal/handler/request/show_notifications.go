@@ -0,0 +1,19 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type ShowNotificationsRequest struct {
+	Username   string `validate:"required"`
+	UnreadOnly bool
+	Limit      int `validate:"min=1,max=100"`
+	Offset     int `validate:"min=0"`
+}
+
+func (r *ShowNotificationsRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
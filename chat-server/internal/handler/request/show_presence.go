@@ -0,0 +1,16 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type ShowPresenceRequest struct {
+	Usernames []string `json:"usernames" validate:"required,min=1,max=100,dive,required"`
+}
+
+func (r *ShowPresenceRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ type ShowPrivateMessageRequest struct {
 	Recipient string `validate:"min=1"`
 	Limit     int    `json:"limit" validate:"min=1"`
 	Offset    int    `json:"offset" validate:"min=0"`
+	Order     string `json:"order" validate:"omitempty,oneof=asc desc"`
 }
 
 func (r *ShowPrivateMessageRequest) Validate(v *validator.Validate) error {
@@ -3,8 +3,9 @@ package request
 import "github.com/go-playground/validator/v10"
 
 type ShowPublicMessageRequest struct {
-	Limit  int `json:"limit" validate:"min=1"`
-	Offset int `json:"offset" validate:"min=0"`
+	Limit  int    `json:"limit" validate:"min=1"`
+	Offset int    `json:"offset" validate:"min=0"`
+	Order  string `json:"order" validate:"omitempty,oneof=asc desc"`
 }
 
 func (r *ShowPublicMessageRequest) Validate(v *validator.Validate) error {
@@ -0,0 +1,18 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type ShowScheduledMessagesRequest struct {
+	Username string `validate:"required"`
+	Limit    int    `validate:"min=1,max=100"`
+	Offset   int    `validate:"min=0"`
+}
+
+func (r *ShowScheduledMessagesRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type TypingRequest struct {
+	Username string `validate:"required"`
+	Chat     string `validate:"oneof=private public"`
+	Partner  string `validate:"required_if=Chat private"`
+}
+
+func (r *TypingRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type UpdatePrivacySettingsRequest struct {
+	DMPolicy          string `json:"dm_policy" validate:"required,oneof=anyone contacts nobody"`
+	HideBlockedInFeed *bool  `json:"hide_blocked_in_feed" validate:"required"`
+}
+
+func (r *UpdatePrivacySettingsRequest) Validate(v *validator.Validate) error {
+	return v.Struct(r)
+}
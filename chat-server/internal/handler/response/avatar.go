@@ -0,0 +1,10 @@
+package response
+
+type UploadAvatarResponse struct {
+	Response  string `json:"response"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type DeleteAvatarResponse struct {
+	Response string `json:"response"`
+}
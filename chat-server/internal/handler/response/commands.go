@@ -0,0 +1,32 @@
+package response
+
+import "time"
+
+// EphemeralItem is a reply to a command that only its sender sees. It is
+// not stored, so it comes only in the response to the command.
+type EphemeralItem struct {
+	Sender    string    `json:"sender,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BotCommandItem struct {
+	ID          int       `json:"id"`
+	Command     string    `json:"command"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	BotName     string    `json:"bot_name"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CreateBotCommandResponse struct {
+	Response string         `json:"response"`
+	Command  BotCommandItem `json:"command"`
+	Secret   string         `json:"secret"`
+}
+
+type ListBotCommandsResponse struct {
+	Response string           `json:"response"`
+	Commands []BotCommandItem `json:"commands"`
+}
@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+type DataExportResponse struct {
+	Response    string     `json:"response"`
+	Status      string     `json:"status"`
+	RequestedAt time.Time  `json:"requested_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+}
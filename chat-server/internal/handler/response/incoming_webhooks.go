@@ -0,0 +1,24 @@
+package response
+
+import "time"
+
+type IncomingWebhookItem struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Channel    string     `json:"channel"`
+	BotName    string     `json:"bot_name"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type CreateIncomingWebhookResponse struct {
+	Response string              `json:"response"`
+	Webhook  IncomingWebhookItem `json:"webhook"`
+	URL      string              `json:"url"`
+}
+
+type ListIncomingWebhooksResponse struct {
+	Response string                `json:"response"`
+	Webhooks []IncomingWebhookItem `json:"webhooks"`
+}
@@ -0,0 +1,5 @@
+package response
+
+type MessageRequestResponse struct {
+	Response string `json:"response"`
+}
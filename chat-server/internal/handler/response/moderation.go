@@ -0,0 +1,67 @@
+package response
+
+import "time"
+
+type ReportedMessageItem struct {
+	Sender    string    `json:"sender"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	Deleted   bool      `json:"deleted"`
+}
+
+type ReportItem struct {
+	ID         int                 `json:"id"`
+	MessageID  int                 `json:"message_id"`
+	Reporter   string              `json:"reporter"`
+	Reason     string              `json:"reason"`
+	Status     string              `json:"status"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Message    ReportedMessageItem `json:"message"`
+	ResolvedBy string              `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time          `json:"resolved_at,omitempty"`
+}
+
+type ReportResponse struct {
+	Response string     `json:"response"`
+	Report   ReportItem `json:"report"`
+}
+
+type ListReportsResponse struct {
+	Response string       `json:"response"`
+	Reports  []ReportItem `json:"reports"`
+}
+
+type SanctionItem struct {
+	Username  string     `json:"username"`
+	Kind      string     `json:"kind"`
+	Reason    string     `json:"reason"`
+	Until     *time.Time `json:"until,omitempty"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ListSanctionsResponse struct {
+	Response  string         `json:"response"`
+	Sanctions []SanctionItem `json:"sanctions"`
+}
+
+type AuditEntryItem struct {
+	ID        int        `json:"id"`
+	Moderator string     `json:"moderator"`
+	Action    string     `json:"action"`
+	Target    string     `json:"target,omitempty"`
+	MessageID int        `json:"message_id,omitempty"`
+	ReportID  int        `json:"report_id,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type AuditLogResponse struct {
+	Response string           `json:"response"`
+	Entries  []AuditEntryItem `json:"entries"`
+}
+
+type ModerationResponse struct {
+	Response string `json:"response"`
+}
@@ -0,0 +1,25 @@
+package response
+
+import "time"
+
+type ShowNotificationsResponse struct {
+	Response      string             `json:"response"`
+	UnreadCount   int                `json:"unread_count"`
+	Notifications []NotificationItem `json:"notifications"`
+}
+
+type NotificationItem struct {
+	ID              int       `json:"id"`
+	Kind            string    `json:"kind"`
+	Sender          string    `json:"sender"`
+	SenderAvatarURL string    `json:"sender_avatar_url"`
+	MessageID       int       `json:"message_id"`
+	Content         string    `json:"content"`
+	Read            bool      `json:"read"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ReadNotificationsResponse struct {
+	Response string `json:"response"`
+	Updated  int    `json:"updated"`
+}
@@ -0,0 +1,27 @@
+package response
+
+import "time"
+
+type PrivacySettingsItem struct {
+	DMPolicy          string `json:"dm_policy"`
+	HideBlockedInFeed bool   `json:"hide_blocked_in_feed"`
+}
+
+type PrivacySettingsResponse struct {
+	Response string              `json:"response"`
+	Settings PrivacySettingsItem `json:"settings"`
+}
+
+type BlockedUserItem struct {
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BlockedUsersResponse struct {
+	Response string            `json:"response"`
+	Users    []BlockedUserItem `json:"users"`
+}
+
+type BlockResponse struct {
+	Response string `json:"response"`
+}
@@ -0,0 +1,5 @@
+package response
+
+type ReadPrivateMessagesResponse struct {
+	Response string `json:"response"`
+}
@@ -0,0 +1,27 @@
+package response
+
+import "time"
+
+type ReminderItem struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Content   string     `json:"content"`
+	Schedule  string     `json:"schedule"`
+	TimeZone  string     `json:"time_zone"`
+	Sender    string     `json:"sender"`
+	Paused    bool       `json:"paused"`
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ReminderResponse struct {
+	Response string       `json:"response"`
+	Reminder ReminderItem `json:"reminder"`
+}
+
+type ListRemindersResponse struct {
+	Response  string         `json:"response"`
+	Reminders []ReminderItem `json:"reminders"`
+}
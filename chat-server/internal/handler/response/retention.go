@@ -0,0 +1,17 @@
+package response
+
+import "time"
+
+type RetentionResponse struct {
+	Response string `json:"response"`
+}
+
+type LegalHoldItem struct {
+	Username string    `json:"username"`
+	PlacedAt time.Time `json:"placed_at"`
+}
+
+type ListLegalHoldsResponse struct {
+	Response string          `json:"response"`
+	Holds    []LegalHoldItem `json:"holds"`
+}
@@ -0,0 +1,28 @@
+package response
+
+import "time"
+
+type ScheduledMessageItem struct {
+	ID        int       `json:"id"`
+	Channel   string    `json:"channel"`
+	Recipient string    `json:"recipient,omitempty"`
+	Content   string    `json:"content"`
+	SendAt    time.Time `json:"send_at"`
+	Status    string    `json:"status"`
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ScheduleMessageResponse struct {
+	Response  string               `json:"response"`
+	Scheduled ScheduledMessageItem `json:"scheduled"`
+}
+
+type ListScheduledMessagesResponse struct {
+	Response string                 `json:"response"`
+	Messages []ScheduledMessageItem `json:"messages"`
+}
+
+type CancelScheduledMessageResponse struct {
+	Response string `json:"response"`
+}
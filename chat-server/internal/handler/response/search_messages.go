@@ -0,0 +1,19 @@
+package response
+
+import "time"
+
+type SearchMessagesResponse struct {
+	Response   string             `json:"response"`
+	Results    []SearchResultItem `json:"results"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type SearchResultItem struct {
+	Chat            string    `json:"chat"`
+	Partner         string    `json:"partner,omitempty"`
+	ID              int       `json:"id"`
+	Sender          string    `json:"sender"`
+	SenderAvatarURL string    `json:"sender_avatar_url"`
+	Snippet         string    `json:"snippet"`
+	CreatedAt       time.Time `json:"created_at"`
+}
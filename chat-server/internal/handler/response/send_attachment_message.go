@@ -0,0 +1,21 @@
+package response
+
+type SendAttachmentMessageResponse struct {
+	Response    string           `json:"response"`
+	Attachments []AttachmentItem `json:"attachments"`
+	Ephemeral   []EphemeralItem  `json:"ephemeral,omitempty"`
+}
+
+type AttachmentItem struct {
+	ID          string `json:"id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// MessageAttachmentItem is an attachment of the message found at
+// MessageIndex in the messages list of the same response.
+type MessageAttachmentItem struct {
+	MessageIndex int `json:"message_index"`
+	AttachmentItem
+}
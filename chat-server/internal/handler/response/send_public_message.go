@@ -1,5 +1,6 @@
 package response
 
 type SendPublicMessageResponse struct {
-	Response string `json:"response"`
+	Response  string          `json:"response"`
+	Ephemeral []EphemeralItem `json:"ephemeral,omitempty"`
 }
@@ -0,0 +1,25 @@
+package response
+
+import "time"
+
+type ShowInboxResponse struct {
+	Response      string             `json:"response"`
+	Conversations []ConversationItem `json:"conversations"`
+}
+
+type ConversationItem struct {
+	Username        string          `json:"username"`
+	AvatarURL       string          `json:"avatar_url"`
+	LastMessage     LastMessageItem `json:"last_message"`
+	UnreadCount     int             `json:"unread_count"`
+	SeenByRecipient bool            `json:"seen_by_recipient"`
+}
+
+type LastMessageItem struct {
+	ID              int       `json:"id"`
+	Sender          string    `json:"sender"`
+	SenderAvatarURL string    `json:"sender_avatar_url"`
+	Content         string    `json:"content"`
+	CreatedAt       time.Time `json:"created_at"`
+	Expired         bool      `json:"expired,omitempty"`
+}
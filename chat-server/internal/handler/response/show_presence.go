@@ -0,0 +1,15 @@
+package response
+
+import "time"
+
+type ShowPresenceResponse struct {
+	Response string         `json:"response"`
+	Presence []PresenceItem `json:"presence"`
+}
+
+type PresenceItem struct {
+	Username  string     `json:"username"`
+	AvatarURL string     `json:"avatar_url"`
+	Status    string     `json:"status"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+}
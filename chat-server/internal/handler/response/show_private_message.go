@@ -1,6 +1,10 @@
 package response
 
 type ShowPrivateMessageResponse struct {
-	Response string   `json:"response"`
-	Messages []string `json:"messages"`
+	Response    string                  `json:"response"`
+	Messages    []string                `json:"messages"`
+	Attachments []MessageAttachmentItem `json:"attachments,omitempty"`
+	// Expired holds the indexes of the self-destructed messages, which are
+	// listed with empty content.
+	Expired []int `json:"expired,omitempty"`
 }
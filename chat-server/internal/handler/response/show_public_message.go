@@ -3,4 +3,11 @@ package response
 type ShowPublicMessageResponse struct {
 	Response string   `json:"response"`
 	Messages []string `json:"messages"`
+	// IDs holds the identifiers of the messages, in the same order, for
+	// reporting them.
+	IDs         []int                   `json:"ids,omitempty"`
+	Attachments []MessageAttachmentItem `json:"attachments,omitempty"`
+	// Deleted holds the indexes of the messages removed by a moderator,
+	// which are listed with empty content.
+	Deleted []int `json:"deleted,omitempty"`
 }
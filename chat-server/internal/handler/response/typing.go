@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+type SendTypingResponse struct {
+	Response string `json:"response"`
+}
+
+type ShowTypingResponse struct {
+	Response   string            `json:"response"`
+	Users      []string          `json:"users"`
+	AvatarURLs map[string]string `json:"avatar_urls"`
+}
+
+type TypingEventItem struct {
+	Sender          string    `json:"sender"`
+	SenderAvatarURL string    `json:"sender_avatar_url"`
+	Recipient       string    `json:"recipient,omitempty"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
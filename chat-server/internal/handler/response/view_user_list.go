@@ -1,6 +1,13 @@
 package response
 
 type ViewUserListResponse struct {
-	Response string   `json:"response"`
-	Messages []string `json:"users"`
+	Response string         `json:"response"`
+	Users    []UserListItem `json:"users"`
+}
+
+type UserListItem struct {
+	Username        string `json:"username"`
+	AvatarURL       string `json:"avatar_url"`
+	UnreadCount     int    `json:"unread_count"`
+	SeenByRecipient bool   `json:"seen_by_recipient"`
 }
@@ -0,0 +1,48 @@
+package response
+
+import "time"
+
+type WebhookItem struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Channel   string    `json:"channel,omitempty"`
+	Active    bool      `json:"active"`
+	Failures  int       `json:"failures"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateWebhookResponse struct {
+	Response string      `json:"response"`
+	Webhook  WebhookItem `json:"webhook"`
+	Secret   string      `json:"secret"`
+}
+
+type ListWebhooksResponse struct {
+	Response string        `json:"response"`
+	Webhooks []WebhookItem `json:"webhooks"`
+}
+
+type WebhookResponse struct {
+	Response string `json:"response"`
+}
+
+type ShowDeliveriesResponse struct {
+	Response   string         `json:"response"`
+	Deliveries []DeliveryItem `json:"deliveries"`
+}
+
+type DeliveryItem struct {
+	ID             int       `json:"id"`
+	Event          string    `json:"event"`
+	EventID        int       `json:"event_id"`
+	MessageID      int       `json:"message_id"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+	ResponseStatus int       `json:"response_status,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	legalHoldPlaced    = "legal hold placed"
+	legalHoldLifted    = "legal hold lifted"
+	legalHoldsReceived = "legal holds received"
+)
+
+//go:generate mockgen -source=retention_handler.go -destination=mocks/retention_service_mock.go
+
+type RetentionService interface {
+	PlaceLegalHold(username string) error
+	LiftLegalHold(username string) error
+	LegalHolds() ([]entities.LegalHold, error)
+}
+
+type RetentionHandler struct {
+	service RetentionService
+}
+
+func NewRetentionHandler(s RetentionService) *RetentionHandler {
+	return &RetentionHandler{service: s}
+}
+
+// RetentionRoutes registers the legal holds of the admin API. The
+// middlewares have to include the admin guard.
+func (h *RetentionHandler) RetentionRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/admin/legal-holds", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ShowLegalHolds)
+		r.Put("/{username}", h.PlaceLegalHold)
+		r.Delete("/{username}", h.LiftLegalHold)
+	})
+}
+
+// ShowLegalHolds @summary		Пользователи на удержании
+//
+//	@description	Возвращает пользователей, чьи переписки исключены из политик хранения сообщений, в порядке имён. Доступно только администраторам.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.ListLegalHoldsResponse	"Список получен"
+//	@failure		403	{object}	baseresponse.ResponseError		"Пользователь не администратор"
+//	@failure		500	{object}	baseresponse.ResponseError		"Ошибка при получении списка"
+//	@router			/v1/admin/legal-holds [get]
+func (h *RetentionHandler) ShowLegalHolds(w http.ResponseWriter, r *http.Request) {
+	holds, err := h.service.LegalHolds()
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.LegalHoldsToResponse(legalHoldsReceived, holds))
+}
+
+// PlaceLegalHold @summary		Удержание переписок пользователя
+//
+//	@description	Исключает публичные сообщения и личные переписки пользователя из политик хранения: они не удаляются, пока удержание не снято. Повторный запрос сохраняет время первого удержания. Доступно только администраторам.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string						true	"Имя пользователя"
+//	@success		200			{object}	response.RetentionResponse	"Удержание установлено"
+//	@failure		403			{object}	baseresponse.ResponseError	"Пользователь не администратор"
+//	@failure		404			{object}	baseresponse.ResponseError	"Пользователь не найден"
+//	@failure		500			{object}	baseresponse.ResponseError	"Ошибка при установке удержания"
+//	@router			/v1/admin/legal-holds/{username} [put]
+func (h *RetentionHandler) PlaceLegalHold(w http.ResponseWriter, r *http.Request) {
+	err := h.service.PlaceLegalHold(chi.URLParam(r, "username"))
+	if errors.Is(err, entities.ErrUserNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.RetentionResponse{Response: legalHoldPlaced})
+}
+
+// LiftLegalHold @summary		Снятие удержания
+//
+//	@description	Возвращает переписки пользователя под действие политик хранения: старые сообщения удалятся при следующей очистке. Доступно только администраторам.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			username	path		string						true	"Имя пользователя"
+//	@success		200			{object}	response.RetentionResponse	"Удержание снято"
+//	@failure		403			{object}	baseresponse.ResponseError	"Пользователь не администратор"
+//	@failure		404			{object}	baseresponse.ResponseError	"Пользователь не на удержании"
+//	@failure		500			{object}	baseresponse.ResponseError	"Ошибка при снятии удержания"
+//	@router			/v1/admin/legal-holds/{username} [delete]
+func (h *RetentionHandler) LiftLegalHold(w http.ResponseWriter, r *http.Request) {
+	err := h.service.LiftLegalHold(chi.URLParam(r, "username"))
+	if errors.Is(err, entities.ErrLegalHoldNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.RetentionResponse{Response: legalHoldLifted})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetentionHandler_LegalHolds(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockRetentionService)
+
+	placedAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		method              string
+		target              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "list",
+			method: "GET",
+			target: "/v1/admin/legal-holds",
+			mockBehavior: func(s *mock_handler.MockRetentionService) {
+				s.EXPECT().LegalHolds().Return([]entities.LegalHold{{Username: "valera", PlacedAt: placedAt}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"legal holds received","holds":[{"username":"valera","placed_at":"2026-10-19T12:00:00Z"}]}`,
+		},
+		{
+			name:   "list_empty",
+			method: "GET",
+			target: "/v1/admin/legal-holds",
+			mockBehavior: func(s *mock_handler.MockRetentionService) {
+				s.EXPECT().LegalHolds().Return([]entities.LegalHold{}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"legal holds received","holds":[]}`,
+		},
+		{
+			name:   "place",
+			method: "PUT",
+			target: "/v1/admin/legal-holds/valera",
+			mockBehavior: func(s *mock_handler.MockRetentionService) {
+				s.EXPECT().PlaceLegalHold("valera").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"legal hold placed"}`,
+		},
+		{
+			name:   "place_unknown_user",
+			method: "PUT",
+			target: "/v1/admin/legal-holds/igor",
+			mockBehavior: func(s *mock_handler.MockRetentionService) {
+				s.EXPECT().PlaceLegalHold("igor").Return(entities.ErrUserNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"user does not exist"}`,
+		},
+		{
+			name:   "lift",
+			method: "DELETE",
+			target: "/v1/admin/legal-holds/valera",
+			mockBehavior: func(s *mock_handler.MockRetentionService) {
+				s.EXPECT().LiftLegalHold("valera").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"legal hold lifted"}`,
+		},
+		{
+			name:   "lift_not_held",
+			method: "DELETE",
+			target: "/v1/admin/legal-holds/tester",
+			mockBehavior: func(s *mock_handler.MockRetentionService) {
+				s.EXPECT().LiftLegalHold("tester").Return(entities.ErrLegalHoldNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"the user has no legal hold"}`,
+		},
+		{
+			name:   "service_error",
+			method: "GET",
+			target: "/v1/admin/legal-holds",
+			mockBehavior: func(s *mock_handler.MockRetentionService) {
+				s.EXPECT().LegalHolds().Return(nil, errors.New("database is down"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"database is down"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			retention := mock_handler.NewMockRetentionService(ctrl)
+			retentionHandler := NewRetentionHandler(retention)
+
+			r := chi.NewRouter()
+			retentionHandler.RetentionRoutes(r)
+
+			// Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.target, nil)
+
+			testCase.mockBehavior(retention)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	messageScheduled          = "message scheduled"
+	scheduledReceived         = "scheduled messages received"
+	scheduledCanceled         = "scheduled message canceled"
+	defaultScheduledListLimit = 20
+)
+
+var errInvalidScheduledMessageID = errors.New("scheduled message id must be a number")
+
+//go:generate mockgen -source=scheduled_handler.go -destination=mocks/scheduled_service_mock.go
+
+type ScheduledService interface {
+	Schedule(m entities.ScheduledMessage) (entities.ScheduledMessage, error)
+	List(sender string, limit, offset int) ([]entities.ScheduledMessage, error)
+	Cancel(sender string, id int) error
+}
+
+type ScheduledHandler struct {
+	service  ScheduledService
+	validate *validator.Validate
+}
+
+func NewScheduledHandler(s ScheduledService, v *validator.Validate) *ScheduledHandler {
+	return &ScheduledHandler{service: s, validate: v}
+}
+
+func (h *ScheduledHandler) ScheduledRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/scheduled", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ShowScheduledMessages)
+		r.Delete("/{id}", h.CancelScheduledMessage)
+	})
+}
+
+// ShowScheduledMessages @summary		Получение запланированных сообщений
+//
+//	@description	Получает ещё не отправленные запланированные сообщения пользователя, начиная с ближайшего. Сообщения, которые не удалось отправить, возвращаются со статусом failed и текстом ошибки.
+//	@tags			scheduled
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			limit	query		int										false	"Количество сообщений (1-100)"
+//	@param			offset	query		int										false	"Смещение"
+//	@success		200		{object}	response.ListScheduledMessagesResponse	"Сообщения успешно получены"
+//	@failure		400		{object}	baseresponse.ResponseError				"Неверный запрос"
+//	@failure		500		{object}	baseresponse.ResponseError				"Ошибка при получении сообщений"
+//	@router			/v1/scheduled [get]
+func (h *ScheduledHandler) ShowScheduledMessages(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	params := r.URL.Query()
+	input := request.ShowScheduledMessagesRequest{Username: username, Limit: defaultScheduledListLimit}
+
+	var err error
+	if val := params.Get("limit"); val != "" {
+		input.Limit, err = strconv.Atoi(val)
+	}
+	if val := params.Get("offset"); val != "" && err == nil {
+		input.Offset, err = strconv.Atoi(val)
+	}
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidPaging)
+		return
+	}
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	messages, err := h.service.List(input.Username, input.Limit, input.Offset)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.ScheduledMessagesToResponse(scheduledReceived, messages))
+}
+
+// CancelScheduledMessage @summary		Отмена запланированного сообщения
+//
+//	@description	Отменяет ещё не отправленное сообщение или убирает из списка сообщение, которое не удалось отправить.
+//	@tags			scheduled
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		int										true	"Идентификатор сообщения"
+//	@success		200	{object}	response.CancelScheduledMessageResponse	"Сообщение отменено"
+//	@failure		400	{object}	baseresponse.ResponseError				"Неверный запрос"
+//	@failure		404	{object}	baseresponse.ResponseError				"Сообщение не найдено или уже отправляется"
+//	@router			/v1/scheduled/{id} [delete]
+func (h *ScheduledHandler) CancelScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidScheduledMessageID)
+		return
+	}
+
+	err = h.service.Cancel(username, id)
+	if errors.Is(err, entities.ErrScheduledMessageNotFound) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.CancelScheduledMessageResponse{Response: scheduledCanceled})
+}
+
+// scheduleMessage answers a send request which has send_at set.
+func scheduleMessage(w http.ResponseWriter, r *http.Request, s ScheduledService, m entities.ScheduledMessage) {
+	scheduled, err := s.Schedule(m)
+	switch {
+	case errors.Is(err, entities.ErrSendAtInPast), errors.Is(err, entities.ErrSendAtTooFar),
+		errors.Is(err, entities.ErrScheduledCommand), errors.Is(err, entities.ErrRecipientNotFound):
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	render.JSON(w, r, response.ScheduleMessageResponse{Response: messageScheduled, Scheduled: mapper.ScheduledMessageToResponse(scheduled)})
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublicHandler_SendPublicMessageScheduled(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockScheduledService)
+
+	sendAt := time.Date(2026, time.October, 20, 9, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"content": "stand-up!", "send_at": "2026-10-20T09:00:00Z"}`,
+			mockBehavior: func(s *mock_handler.MockScheduledService) {
+				s.EXPECT().Schedule(entities.ScheduledMessage{Channel: "public", Sender: "tester", Content: "stand-up!", SendAt: sendAt}).Return(entities.ScheduledMessage{
+					ID: 7, Channel: "public", Sender: "tester", Content: "stand-up!", SendAt: sendAt, Status: entities.ScheduledPending, CreatedAt: createdAt,
+				}, nil)
+			},
+			expectedStatusCode: 202,
+			expectedRequestBody: `{"response":"message scheduled","scheduled":{"id":7,"channel":"public","content":"stand-up!",` +
+				`"send_at":"2026-10-20T09:00:00Z","status":"pending","created_at":"2026-10-19T09:00:00Z"}}`,
+		},
+		{
+			name:      "in_past",
+			inputBody: `{"content": "stand-up!", "send_at": "2026-10-20T09:00:00Z"}`,
+			mockBehavior: func(s *mock_handler.MockScheduledService) {
+				s.EXPECT().Schedule(gomock.Any()).Return(entities.ScheduledMessage{}, entities.ErrSendAtInPast)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"send_at must be in the future"}`,
+		},
+		{
+			name:      "schedule_error",
+			inputBody: `{"content": "stand-up!", "send_at": "2026-10-20T09:00:00Z"}`,
+			mockBehavior: func(s *mock_handler.MockScheduledService) {
+				s.EXPECT().Schedule(gomock.Any()).Return(entities.ScheduledMessage{}, errors.New("insert error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"insert error"}`,
+		},
+		{
+			name:                "invalid_send_at",
+			inputBody:           `{"content": "stand-up!", "send_at": "tomorrow"}`,
+			mockBehavior:        func(s *mock_handler.MockScheduledService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"parsing time \"tomorrow\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"tomorrow\" as \"2006\""}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			scheduler := mock_handler.NewMockScheduledService(ctrl)
+			publicHandler := NewPublicHandler(mock_handler.NewMockPublicService(ctrl), scheduler, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/messages", publicHandler.SendPublicMessage)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("POST", "/messages", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(scheduler)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestPrivateHandler_SendPrivateMessageScheduled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sendAt := time.Date(2026, time.October, 20, 9, 0, 0, 0, time.UTC)
+
+	scheduler := mock_handler.NewMockScheduledService(ctrl)
+	scheduler.EXPECT().Schedule(entities.ScheduledMessage{Channel: "private", Sender: "tester", Recipient: "ghost", Content: "hi", SendAt: sendAt}).
+		Return(entities.ScheduledMessage{}, entities.ErrRecipientNotFound)
+
+	privateHandler := NewPrivateHAndler(mock_handler.NewMockPrivateService(ctrl), scheduler, validator.New())
+
+	r := chi.NewRouter()
+	r.Post("/messages", privateHandler.SendPrivateMessage)
+
+	w := httptest.NewRecorder()
+
+	req := httptest.NewRequest("POST", "/messages?username=ghost", bytes.NewBufferString(`{"content": "hi", "send_at": "2026-10-20T09:00:00Z"}`))
+	req = req.WithContext(context.WithValue(context.Background(), "Sender", "tester"))
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, `{"error":"recipient does not exist"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestScheduledHandler_CancelScheduledMessage(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockScheduledService)
+
+	testTable := []struct {
+		name                string
+		id                  string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "ok",
+			id:   "7",
+			mockBehavior: func(s *mock_handler.MockScheduledService) {
+				s.EXPECT().Cancel("tester", 7).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"scheduled message canceled"}`,
+		},
+		{
+			name: "not_found",
+			id:   "8",
+			mockBehavior: func(s *mock_handler.MockScheduledService) {
+				s.EXPECT().Cancel("tester", 8).Return(entities.ErrScheduledMessageNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"scheduled message not found"}`,
+		},
+		{
+			name:                "invalid_id",
+			id:                  "abc",
+			mockBehavior:        func(s *mock_handler.MockScheduledService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"scheduled message id must be a number"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			scheduled := mock_handler.NewMockScheduledService(ctrl)
+			scheduledHandler := NewScheduledHandler(scheduled, validator.New())
+
+			r := chi.NewRouter()
+			r.Delete("/scheduled/{id}", scheduledHandler.CancelScheduledMessage)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("DELETE", "/scheduled/"+testCase.id, nil)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(scheduled)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	searchCompleted    = "search completed"
+	defaultSearchLimit = 20
+)
+
+var errInvalidLimit = errors.New("limit must be a number")
+
+//go:generate mockgen -source=search_handler.go -destination=mocks/search_service_mock.go
+
+type SearchService interface {
+	Search(user string, q entities.SearchQuery) (entities.SearchPage, error)
+}
+
+type SearchHandler struct {
+	service  SearchService
+	validate *validator.Validate
+}
+
+func NewSearchHandler(s SearchService, v *validator.Validate) *SearchHandler {
+	return &SearchHandler{service: s, validate: v}
+}
+
+func (h *SearchHandler) SearchRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/search", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/messages", h.SearchMessages)
+	})
+}
+
+// SearchMessages @summary		Поиск сообщений
+//
+//	@description	Ищет сообщения в общем чате и в приватных переписках пользователя. Поддерживаются фразы в кавычках и префиксы со звездочкой, все слова запроса должны встречаться в сообщении. Результаты отсортированы от новых к старым.
+//	@tags			search
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			q		query		string							true	"Поисковый запрос"
+//	@param			sender	query		string							false	"Имя отправителя"
+//	@param			from	query		string							false	"Дата начала (YYYY-MM-DD)"
+//	@param			to		query		string							false	"Дата окончания включительно (YYYY-MM-DD)"
+//	@param			limit	query		int								false	"Количество результатов (1-100)"
+//	@param			cursor	query		string							false	"Курсор следующей страницы"
+//	@success		200		{object}	response.SearchMessagesResponse	"Поиск выполнен"
+//	@failure		400		{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		500		{object}	baseresponse.ResponseError		"Ошибка при поиске"
+//	@router			/v1/search/messages [get]
+func (h *SearchHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	username, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	params := r.URL.Query()
+	input := request.SearchMessagesRequest{
+		Username: username,
+		Query:    params.Get("q"),
+		Sender:   params.Get("sender"),
+		From:     params.Get("from"),
+		To:       params.Get("to"),
+		Limit:    defaultSearchLimit,
+		Cursor:   params.Get("cursor"),
+	}
+
+	if val := params.Get("limit"); val != "" {
+		limit, err := strconv.Atoi(val)
+		if err != nil {
+			baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidLimit)
+			return
+		}
+
+		input.Limit = limit
+	}
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	q, err := mapper.SearchMessagesRequestToQuery(input)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.service.Search(input.Username, q)
+	if errors.Is(err, entities.ErrEmptySearchQuery) {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.SearchPageToResponse(searchCompleted, input.Username, page))
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchHandler_SearchMessages(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockSearchService)
+
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+	cursor := entities.SearchCursor{CreatedAt: createdAt, Chat: "private", ID: 7}
+	token := mapper.EncodeSearchCursor(cursor)
+
+	testTable := []struct {
+		name                string
+		target              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "ok",
+			target: "/search/messages?q=plan&sender=valera&from=2026-10-01&to=2026-10-19&limit=2",
+			mockBehavior: func(s *mock_handler.MockSearchService) {
+				s.EXPECT().Search("tester", entities.SearchQuery{
+					Text:   "plan",
+					Sender: "valera",
+					From:   time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC),
+					Limit:  2,
+				}).Return(entities.SearchPage{
+					Results: []entities.SearchResult{
+						{Message: entities.Message{ID: 3, Sender: "valera", CreatedAt: createdAt}, Snippet: "the <mark>plan</mark>"},
+						{Message: entities.Message{ID: 7, Sender: "valera", Recipient: "tester", CreatedAt: createdAt}, Snippet: "<mark>plan</mark> approved"},
+					},
+					Next: &cursor,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"response":"search completed","results":[` +
+				`{"chat":"public","id":3,"sender":"valera","sender_avatar_url":"/v1/users/valera/avatar","snippet":"the \u003cmark\u003eplan\u003c/mark\u003e","created_at":"2026-10-19T09:00:00Z"},` +
+				`{"chat":"private","partner":"valera","id":7,"sender":"valera","sender_avatar_url":"/v1/users/valera/avatar","snippet":"\u003cmark\u003eplan\u003c/mark\u003e approved","created_at":"2026-10-19T09:00:00Z"}],` +
+				`"next_cursor":"` + token + `"}`,
+		},
+		{
+			name:   "next_page",
+			target: "/search/messages?q=plan&cursor=" + token,
+			mockBehavior: func(s *mock_handler.MockSearchService) {
+				s.EXPECT().Search("tester", entities.SearchQuery{Text: "plan", Limit: 20, After: &cursor}).Return(entities.SearchPage{}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"search completed","results":[]}`,
+		},
+		{
+			name:                "invalid_cursor",
+			target:              "/search/messages?q=plan&cursor=garbage",
+			mockBehavior:        func(s *mock_handler.MockSearchService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid search cursor"}`,
+		},
+		{
+			name:                "invalid_limit",
+			target:              "/search/messages?q=plan&limit=many",
+			mockBehavior:        func(s *mock_handler.MockSearchService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"limit must be a number"}`,
+		},
+		{
+			name:                "missing_query",
+			target:              "/search/messages",
+			mockBehavior:        func(s *mock_handler.MockSearchService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'SearchMessagesRequest.Query' Error:Field validation for 'Query' failed on the 'required' tag"}`,
+		},
+		{
+			name:   "no_words",
+			target: "/search/messages?q=%2A%2A",
+			mockBehavior: func(s *mock_handler.MockSearchService) {
+				s.EXPECT().Search("tester", entities.SearchQuery{Text: "**", Limit: 20}).Return(entities.SearchPage{}, entities.ErrEmptySearchQuery)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"search query has no words"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			search := mock_handler.NewMockSearchService(ctrl)
+			searchHandler := NewSearchHandler(search, validator.New())
+
+			r := chi.NewRouter()
+			r.Get("/search/messages", searchHandler.SearchMessages)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("GET", testCase.target, nil)
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(search)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
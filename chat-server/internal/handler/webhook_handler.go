@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/handler/mapper"
+	"github.com/vavelour/chat/internal/handler/request"
+	"github.com/vavelour/chat/internal/handler/response"
+	"github.com/vavelour/chat/pkg/http_utils/baseresponse"
+)
+
+const (
+	webhookCreated      = "webhook created"
+	webhooksReceived    = "webhooks received"
+	webhookDeleted      = "webhook deleted"
+	webhookEnabled      = "webhook enabled"
+	deliveriesReceived  = "deliveries received"
+	defaultDeliveryPage = 50
+)
+
+var errInvalidWebhookID = errors.New("invalid webhook id")
+
+//go:generate mockgen -source=webhook_handler.go -destination=mocks/webhook_service_mock.go
+
+type WebhookService interface {
+	Create(w entities.Webhook) (entities.Webhook, error)
+	List() ([]entities.Webhook, error)
+	Delete(id int) error
+	Enable(id int) error
+	Deliveries(webhookID, limit, offset int) ([]entities.WebhookDelivery, error)
+}
+
+type WebhookHandler struct {
+	service  WebhookService
+	validate *validator.Validate
+}
+
+func NewWebhookHandler(s WebhookService, v *validator.Validate) *WebhookHandler {
+	return &WebhookHandler{service: s, validate: v}
+}
+
+// WebhookRoutes registers the admin API. The middlewares have to include
+// the admin guard.
+func (h *WebhookHandler) WebhookRoutes(router *chi.Mux, middlewares ...func(next http.Handler) http.Handler) {
+	router.Route("/v1/admin/webhooks", func(r chi.Router) {
+		for _, mw := range middlewares {
+			r.Use(mw)
+		}
+		r.Get("/", h.ListWebhooks)
+		r.Post("/", h.CreateWebhook)
+		r.Delete("/{id}", h.DeleteWebhook)
+		r.Post("/{id}/enable", h.EnableWebhook)
+		r.Get("/{id}/deliveries", h.ShowDeliveries)
+	})
+}
+
+// CreateWebhook @summary		Создание вебхука
+//
+//	@description	Подписывает внешний URL на события сообщений. Каждый запрос подписывается HMAC-SHA256 в заголовке X-Chat-Signature; если секрет не указан, он генерируется и возвращается один раз.
+//	@tags			admin
+//	@accept			json
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			requestBody	body		request.CreateWebhookRequest	true	"Параметры вебхука"
+//	@success		201			{object}	response.CreateWebhookResponse	"Вебхук создан"
+//	@failure		400			{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		403			{object}	baseresponse.ResponseError		"Доступно только администраторам"
+//	@router			/v1/admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var input request.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	admin, ok := r.Context().Value("Sender").(string)
+	if !ok {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, errFailedGetSender)
+		return
+	}
+
+	input.CreatedBy = admin
+
+	if err := input.Validate(h.validate); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	webhook, err := h.service.Create(mapper.CreateWebhookRequestToEntity(input))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	render.JSON(w, r, response.CreateWebhookResponse{Response: webhookCreated, Webhook: mapper.WebhookToResponse(webhook), Secret: webhook.Secret})
+}
+
+// ListWebhooks @summary		Список вебхуков
+//
+//	@description	Возвращает все вебхуки вместе с их состоянием. Вебхук отключается автоматически после серии неудачных доставок.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@success		200	{object}	response.ListWebhooksResponse	"Вебхуки получены"
+//	@failure		403	{object}	baseresponse.ResponseError		"Доступно только администраторам"
+//	@router			/v1/admin/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.List()
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.WebhooksToResponse(webhooksReceived, webhooks))
+}
+
+// DeleteWebhook @summary		Удаление вебхука
+//
+//	@description	Удаляет вебхук вместе с журналом и очередью доставок.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		int							true	"Идентификатор вебхука"
+//	@success		200	{object}	response.WebhookResponse	"Вебхук удален"
+//	@failure		404	{object}	baseresponse.ResponseError	"Вебхук не найден"
+//	@router			/v1/admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidWebhookID)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, webhookErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.WebhookResponse{Response: webhookDeleted})
+}
+
+// EnableWebhook @summary		Включение вебхука
+//
+//	@description	Снова включает отключенный вебхук и сбрасывает счетчик ошибок. Ожидающие доставки будут отправлены.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id	path		int							true	"Идентификатор вебхука"
+//	@success		200	{object}	response.WebhookResponse	"Вебхук включен"
+//	@failure		404	{object}	baseresponse.ResponseError	"Вебхук не найден"
+//	@router			/v1/admin/webhooks/{id}/enable [post]
+func (h *WebhookHandler) EnableWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidWebhookID)
+		return
+	}
+
+	if err := h.service.Enable(id); err != nil {
+		baseresponse.ReturnErrorResponse(w, r, webhookErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.WebhookResponse{Response: webhookEnabled})
+}
+
+// ShowDeliveries @summary		Журнал доставок вебхука
+//
+//	@description	Возвращает доставки вебхука от новых к старым: статус, число попыток, код ответа и последнюю ошибку.
+//	@tags			admin
+//	@produce		json
+//
+//	@Security		BasicAuth
+//
+//	@param			id		path		int								true	"Идентификатор вебхука"
+//	@param			limit	query		int								false	"Количество доставок (1-100)"
+//	@param			offset	query		int								false	"Смещение"
+//	@success		200		{object}	response.ShowDeliveriesResponse	"Доставки получены"
+//	@failure		400		{object}	baseresponse.ResponseError		"Неверный запрос"
+//	@failure		404		{object}	baseresponse.ResponseError		"Вебхук не найден"
+//	@router			/v1/admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ShowDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidWebhookID)
+		return
+	}
+
+	limit, offset := defaultDeliveryPage, 0
+	if val := r.URL.Query().Get("limit"); val != "" {
+		limit, err = strconv.Atoi(val)
+	}
+	if val := r.URL.Query().Get("offset"); val != "" && err == nil {
+		offset, err = strconv.Atoi(val)
+	}
+	if err != nil || limit < 1 || limit > 100 || offset < 0 {
+		baseresponse.ReturnErrorResponse(w, r, http.StatusBadRequest, errInvalidPaging)
+		return
+	}
+
+	deliveries, err := h.service.Deliveries(id, limit, offset)
+	if err != nil {
+		baseresponse.ReturnErrorResponse(w, r, webhookErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, mapper.DeliveriesToResponse(deliveriesReceived, deliveries))
+}
+
+func webhookErrorStatus(err error) int {
+	if errors.Is(err, entities.ErrWebhookNotFound) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_handler "github.com/vavelour/chat/internal/handler/mocks"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockWebhookService)
+
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "ok",
+			inputBody: `{"url": "https://example.com/hook", "events": ["message.created"], "channel": "public"}`,
+			mockBehavior: func(s *mock_handler.MockWebhookService) {
+				s.EXPECT().Create(entities.Webhook{
+					URL: "https://example.com/hook", Events: []entities.WebhookEventType{entities.EventMessageCreated}, Channel: "public", CreatedBy: "tester",
+				}).Return(entities.Webhook{
+					ID: 1, URL: "https://example.com/hook", Events: []entities.WebhookEventType{entities.EventMessageCreated}, Channel: "public",
+					Secret: "generated", Active: true, CreatedBy: "tester", CreatedAt: createdAt,
+				}, nil)
+			},
+			expectedStatusCode: 201,
+			expectedRequestBody: `{"response":"webhook created","webhook":{"id":1,"url":"https://example.com/hook","events":["message.created"],` +
+				`"channel":"public","active":true,"failures":0,"created_by":"tester","created_at":"2026-10-19T09:00:00Z"},"secret":"generated"}`,
+		},
+		{
+			name:                "invalid_url",
+			inputBody:           `{"url": "ftp://example.com", "events": ["message.created"]}`,
+			mockBehavior:        func(s *mock_handler.MockWebhookService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'CreateWebhookRequest.URL' Error:Field validation for 'URL' failed on the 'http_url' tag"}`,
+		},
+		{
+			name:                "unknown_event",
+			inputBody:           `{"url": "https://example.com/hook", "events": ["user.created"]}`,
+			mockBehavior:        func(s *mock_handler.MockWebhookService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'CreateWebhookRequest.Events[0]' Error:Field validation for 'Events[0]' failed on the 'oneof' tag"}`,
+		},
+		{
+			name:                "private_channel",
+			inputBody:           `{"url": "https://example.com/hook", "events": ["message.created"], "channel": "private"}`,
+			mockBehavior:        func(s *mock_handler.MockWebhookService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"Key: 'CreateWebhookRequest.Channel' Error:Field validation for 'Channel' failed on the 'oneof' tag"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			webhooks := mock_handler.NewMockWebhookService(ctrl)
+			webhookHandler := NewWebhookHandler(webhooks, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/webhooks", webhookHandler.CreateWebhook)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(context.Background(), "Sender", "tester")
+
+			req := httptest.NewRequest("POST", "/webhooks", bytes.NewBufferString(testCase.inputBody))
+			req = req.WithContext(ctx)
+
+			testCase.mockBehavior(webhooks)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestWebhookHandler_EnableWebhook(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockWebhookService)
+
+	testTable := []struct {
+		name                string
+		target              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "ok",
+			target: "/webhooks/3/enable",
+			mockBehavior: func(s *mock_handler.MockWebhookService) {
+				s.EXPECT().Enable(3).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"response":"webhook enabled"}`,
+		},
+		{
+			name:   "not_found",
+			target: "/webhooks/3/enable",
+			mockBehavior: func(s *mock_handler.MockWebhookService) {
+				s.EXPECT().Enable(3).Return(entities.ErrWebhookNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"webhook not found"}`,
+		},
+		{
+			name:                "invalid_id",
+			target:              "/webhooks/abc/enable",
+			mockBehavior:        func(s *mock_handler.MockWebhookService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid webhook id"}`,
+		},
+		{
+			name:   "service_error",
+			target: "/webhooks/3/enable",
+			mockBehavior: func(s *mock_handler.MockWebhookService) {
+				s.EXPECT().Enable(3).Return(errors.New("storage error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"storage error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			webhooks := mock_handler.NewMockWebhookService(ctrl)
+			webhookHandler := NewWebhookHandler(webhooks, validator.New())
+
+			r := chi.NewRouter()
+			r.Post("/webhooks/{id}/enable", webhookHandler.EnableWebhook)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest("POST", testCase.target, nil)
+
+			testCase.mockBehavior(webhooks)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
+
+func TestWebhookHandler_ShowDeliveries(t *testing.T) {
+	type mockBehavior func(s *mock_handler.MockWebhookService)
+
+	at := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                string
+		target              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "ok",
+			target: "/webhooks/1/deliveries?limit=10",
+			mockBehavior: func(s *mock_handler.MockWebhookService) {
+				s.EXPECT().Deliveries(1, 10, 0).Return([]entities.WebhookDelivery{{
+					ID: 4, Event: entities.WebhookEvent{ID: 2, Type: entities.EventMessageCreated, Message: entities.Message{ID: 9}},
+					Status: entities.DeliveryPending, Attempts: 2, NextAttemptAt: at, ResponseStatus: 503, LastError: "unexpected status 503",
+					CreatedAt: at, UpdatedAt: at,
+				}}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"response":"deliveries received","deliveries":[{"id":4,"event":"message.created","event_id":2,"message_id":9,` +
+				`"status":"pending","attempts":2,"next_attempt_at":"2026-10-19T09:00:00Z","response_status":503,"last_error":"unexpected status 503",` +
+				`"created_at":"2026-10-19T09:00:00Z","updated_at":"2026-10-19T09:00:00Z"}]}`,
+		},
+		{
+			name:                "invalid_limit",
+			target:              "/webhooks/1/deliveries?limit=500",
+			mockBehavior:        func(s *mock_handler.MockWebhookService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"limit and offset must be numbers"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			webhooks := mock_handler.NewMockWebhookService(ctrl)
+			webhookHandler := NewWebhookHandler(webhooks, validator.New())
+
+			r := chi.NewRouter()
+			r.Get("/webhooks/{id}/deliveries", webhookHandler.ShowDeliveries)
+
+			// Request
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest("GET", testCase.target, nil)
+
+			testCase.mockBehavior(webhooks)
+
+			// Serve
+			r.ServeHTTP(w, req)
+
+			// Assert
+			actualResponse := strings.TrimSpace(w.Body.String())
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, actualResponse)
+		})
+	}
+}
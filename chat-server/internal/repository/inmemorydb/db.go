@@ -1,38 +1,186 @@
 package inmemorydb
 
 import (
+	"fmt"
 	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
 	"github.com/vavelour/chat/internal/repository/inmemorydb/model/constant"
 	"sync"
+	"time"
 
 	"github.com/vavelour/chat/internal/domain/entities"
 )
 
+// MemoryDB keeps every table behind a lock of its own. The tables are
+// listed in the order their locks are taken in; the shards of a table come
+// in the order they were added.
 type MemoryDB struct {
-	mu sync.RWMutex
-	db map[string]interface{}
+	Users              *Table[model.UsersTable]
+	Bots               *Table[model.BotTable]
+	PublicChat         *MessageLog
+	PrivateChats       *Sharded[model.MembersPrivateChatModel, model.PrivateChat]
+	PrivateReads       *Table[model.PrivateReadTable]
+	ConversationIndex  *Table[model.ConversationIndexTable]
+	Contacts           *Table[model.ContactTable]
+	MessageRequests    *Table[model.MessageRequestTable]
+	Privacy            *Table[model.PrivacyTable]
+	Blocks             *Table[model.BlockTable]
+	Reports            *Table[model.ReportTable]
+	Sanctions          *Table[model.SanctionTable]
+	ModerationLog      *Table[model.ModerationLogTable]
+	ExpiringMessages   *Table[model.ExpiryTable]
+	PublicAttachments  *Table[model.AttachmentTable]
+	PrivateAttachments *Table[model.AttachmentTable]
+	Avatars            *Table[model.AvatarTable]
+	PublicSearchIndex  *Table[model.SearchIndex]
+	PrivateSearchIndex *Table[model.SearchIndex]
+	Webhooks           *Table[model.WebhookStore]
+	IncomingWebhooks   *Table[model.IncomingWebhookStore]
+	BotCommands        *Table[model.BotCommandStore]
+	ScheduledMessages  *Table[model.ScheduledMessageStore]
+	Reminders          *Table[model.ReminderStore]
+	LastSeen           *Table[model.LastSeenTable]
+	LegalHolds         *Table[model.LegalHoldTable]
+	DataExports        *Table[model.DataExportTable]
+	Notifications      *Sharded[string, []entities.Notification]
+
+	tables  map[string]storedTable
+	journal *journal
+	opts    PersistenceOptions
+
+	// units is shared with the views of the units of work: a unit holds it
+	// for writing, any other operation for reading. active is the unit
+	// running, whose log records wait for it to commit; unit is the unit of
+	// a view and nil for the database itself.
+	units  *sync.RWMutex
+	active *unit
+	unit   *unit
+
+	stopOnce *sync.Once
+	stop     chan struct{}
+	done     chan struct{}
 }
 
+// NewDB returns a database which lives in memory only.
 func NewDB() *MemoryDB {
-	db := make(map[string]interface{})
+	db := &MemoryDB{
+		tables:   make(map[string]storedTable),
+		units:    new(sync.RWMutex),
+		stopOnce: new(sync.Once),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
 
-	db[constant.UsersKey] = model.UsersTable{Table: make(map[string]entities.User)}
-	db[constant.PublicChatKey] = model.PublicChat{Messages: make([]entities.Message, 0)}
-	db[constant.PrivateChatKey] = model.PrivateChatTable{Table: make(map[model.MembersPrivateChatModel]model.PrivateChat)}
+	db.Users = newTable(db, constant.UsersKey, func() model.UsersTable {
+		return model.UsersTable{Table: make(map[string]entities.User)}
+	}, model.SetUser{}).undoChanges()
+	db.Bots = newTable(db, constant.BotsKey, func() model.BotTable {
+		return model.BotTable{Table: make(map[string]bool)}
+	})
+	db.PublicChat = newMessageLog(db, constant.PublicChatKey)
+	db.PrivateChats = newSharded[model.MembersPrivateChatModel, model.PrivateChat](db, constant.PrivateChatKey,
+		model.AppendMessage{})
+	db.PrivateReads = newTable(db, constant.PrivateReadsKey, func() model.PrivateReadTable {
+		return model.PrivateReadTable{Table: make(map[model.ReadMarkerModel]int)}
+	}, model.SetReadMarker{})
+	db.ConversationIndex = newTable(db, constant.ConversationIndexKey, func() model.ConversationIndexTable {
+		return model.ConversationIndexTable{Table: make(map[string][]string)}
+	}, model.SetConversations{})
+	db.Contacts = newTable(db, constant.ContactsKey, func() model.ContactTable {
+		return model.ContactTable{Table: make(map[string]map[string]bool)}
+	}, model.AddContact{})
+	db.MessageRequests = newTable(db, constant.MessageRequestsKey, func() model.MessageRequestTable {
+		return model.MessageRequestTable{Table: make(map[string][]string)}
+	}, model.SetMessageRequests{})
+	db.Privacy = newTable(db, constant.PrivacyKey, func() model.PrivacyTable {
+		return model.PrivacyTable{Table: make(map[string]entities.PrivacySettings)}
+	}, model.SetPrivacySettings{}).undoChanges()
+	db.Blocks = newTable(db, constant.BlocksKey, func() model.BlockTable {
+		return model.BlockTable{Table: make(map[string]map[string]time.Time)}
+	})
+	db.Reports = newTable(db, constant.ReportsKey, func() model.ReportTable {
+		return model.ReportTable{}
+	})
+	db.Sanctions = newTable(db, constant.SanctionsKey, func() model.SanctionTable {
+		return model.SanctionTable{Table: make(map[string]map[entities.SanctionKind]entities.Sanction)}
+	})
+	db.ModerationLog = newTable(db, constant.ModerationLogKey, func() model.ModerationLogTable {
+		return model.ModerationLogTable{}
+	}, model.AddAuditEntry{})
+	db.ExpiringMessages = newTable(db, constant.ExpiringMessagesKey, func() model.ExpiryTable {
+		return model.ExpiryTable{Table: make(map[model.PrivateMessageRefModel]time.Time)}
+	}, model.ScheduleExpiry{})
+	db.PublicAttachments = newTable(db, constant.PublicAttachmentsKey, func() model.AttachmentTable {
+		return model.AttachmentTable{Table: make(map[string]model.AttachmentModel)}
+	}, model.AddAttachments{}, model.RemoveAttachments{})
+	db.PrivateAttachments = newTable(db, constant.PrivateAttachmentsKey, func() model.AttachmentTable {
+		return model.AttachmentTable{Table: make(map[string]model.AttachmentModel)}
+	}, model.AddAttachments{}, model.RemoveAttachments{})
+	db.Avatars = newTable(db, constant.AvatarsKey, func() model.AvatarTable {
+		return model.AvatarTable{Table: make(map[string]entities.Avatar)}
+	})
+	db.PublicSearchIndex = newTable(db, constant.PublicSearchIndexKey, func() model.SearchIndex {
+		return model.SearchIndex{Postings: make(map[string][]model.PostingModel)}
+	}, model.AddPosting{}, model.RemovePosting{})
+	db.PrivateSearchIndex = newTable(db, constant.PrivateSearchIndexKey, func() model.SearchIndex {
+		return model.SearchIndex{Postings: make(map[string][]model.PostingModel)}
+	}, model.AddPosting{}, model.RemovePosting{})
+	db.Webhooks = newTable(db, constant.WebhooksKey, func() model.WebhookStore {
+		return model.WebhookStore{Webhooks: make(map[int]entities.Webhook)}
+	}, model.AddDeliveries{}, model.UpdateDeliveries{})
+	db.IncomingWebhooks = newTable(db, constant.IncomingWebhooksKey, func() model.IncomingWebhookStore {
+		return model.IncomingWebhookStore{Webhooks: make(map[int]entities.IncomingWebhook), Tokens: make(map[string]int)}
+	})
+	db.BotCommands = newTable(db, constant.BotCommandsKey, func() model.BotCommandStore {
+		return model.BotCommandStore{Commands: make(map[string]entities.BotCommand)}
+	})
+	db.ScheduledMessages = newTable(db, constant.ScheduledMessagesKey, func() model.ScheduledMessageStore {
+		return model.ScheduledMessageStore{Messages: make(map[int]entities.ScheduledMessage)}
+	})
+	db.Reminders = newTable(db, constant.RemindersKey, func() model.ReminderStore {
+		return model.ReminderStore{Reminders: make(map[int]entities.Reminder)}
+	})
+	db.LastSeen = newTable(db, constant.LastSeenKey, func() model.LastSeenTable {
+		return model.LastSeenTable{Table: make(map[string]time.Time)}
+	})
+	db.LegalHolds = newTable(db, constant.LegalHoldsKey, func() model.LegalHoldTable {
+		return model.LegalHoldTable{Table: make(map[string]time.Time)}
+	})
+	db.DataExports = newTable(db, constant.DataExportsKey, func() model.DataExportTable {
+		return model.DataExportTable{Table: make(map[string]entities.DataExport)}
+	}, model.SetDataExport{})
+	db.Notifications = newSharded[string, []entities.Notification](db, constant.NotificationsKey,
+		model.AppendNotification{})
 
-	return &MemoryDB{db: db}
+	return db
 }
 
-func (db *MemoryDB) Insert(key string, data interface{}) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
+// register adds the table stored under name and returns its rank: the
+// tables are locked in the order they are registered in.
+func (db *MemoryDB) register(name string, l *lockable, t storedTable) int {
+	rank := len(db.tables) + 1
+	if l != nil {
+		l.rank = rank
+	}
 
-	db.db[key] = data
+	db.tables[name] = t
+
+	return rank
 }
 
-func (db *MemoryDB) Get(key string) interface{} {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+// write appends the record of a change of the table stored under name to
+// the write-ahead log. The callers check that persistence is on first, so
+// that the record is not even built without it, and make the change only
+// when the write succeeds. Within a unit of work the record is kept until
+// the unit commits.
+func (db *MemoryDB) write(name string, record interface{}) error {
+	if db.active != nil {
+		db.active.records = append(db.active.records, unitRecord{name: name, record: record})
+		return nil
+	}
+
+	if err := db.journal.append(name, record); err != nil {
+		return fmt.Errorf("inmemorydb: write %s to the log: %w", name, err)
+	}
 
-	return db.db[key]
+	return nil
 }
@@ -0,0 +1,204 @@
+package inmemorydb
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MessageLog holds the messages of a chat in the order of their IDs. The
+// writers take its write lock; the readers need no lock at all: Messages
+// returns a slice which is never changed afterwards. Appends go past its end
+// and a replaced or removed message is written to a copy of the slice.
+type MessageLog struct {
+	lockable
+	name     string
+	db       *MemoryDB
+	messages atomic.Pointer[[]entities.Message]
+	// last is the highest ID ever appended, which stays taken after the
+	// message is removed. It is changed under the write lock.
+	last int
+}
+
+// removal is the log record of removed messages.
+type removal struct {
+	Removed []int
+}
+
+// logSnapshot is the snapshot of a log.
+type logSnapshot struct {
+	Messages []entities.Message
+	Last     int
+}
+
+func newMessageLog(db *MemoryDB, name string) *MessageLog {
+	l := &MessageLog{name: name, db: db}
+	l.store(make([]entities.Message, 0))
+	l.backup = l.backupMessages
+	db.register(name, &l.lockable, l)
+
+	return l
+}
+
+// Messages returns the messages as they are now. The slice must not be
+// changed; its capacity is cut, so appending to it never writes over the
+// messages appended to the log later.
+func (l *MessageLog) Messages() []entities.Message {
+	messages := *l.messages.Load()
+
+	return messages[:len(messages):len(messages)]
+}
+
+// NextID returns the ID of the next message: IDs of removed messages are
+// never given again. The caller holds the write lock.
+func (l *MessageLog) NextID() int {
+	return l.last + 1
+}
+
+// Append adds the message at the end of the log, unless the write to the
+// log fails.
+func (l *MessageLog) Append(m entities.Message) error {
+	if l.db.journal != nil {
+		if err := l.db.write(l.name, m); err != nil {
+			return err
+		}
+	}
+	l.store(append(*l.messages.Load(), m))
+	l.last = max(l.last, m.ID)
+
+	return nil
+}
+
+// Replace swaps the message with the ID of m for m. It reports false when
+// there is no such message.
+func (l *MessageLog) Replace(m entities.Message) (bool, error) {
+	messages := l.Messages()
+
+	i := sort.Search(len(messages), func(i int) bool { return messages[i].ID >= m.ID })
+	if i == len(messages) || messages[i].ID != m.ID {
+		return false, nil
+	}
+
+	if l.db.journal != nil {
+		if err := l.db.write(l.name, m); err != nil {
+			return false, err
+		}
+	}
+
+	replaced := make([]entities.Message, len(messages), cap(messages))
+	copy(replaced, messages)
+	replaced[i] = m
+	l.store(replaced)
+
+	return true, nil
+}
+
+// Remove takes the messages with the IDs out of the log and returns the
+// ones it removed.
+func (l *MessageLog) Remove(ids []int) ([]entities.Message, error) {
+	remove := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	messages := l.Messages()
+	kept := make([]entities.Message, 0, len(messages))
+	removed := make([]entities.Message, 0, len(ids))
+	for _, m := range messages {
+		if remove[m.ID] {
+			removed = append(removed, m)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+
+	if len(removed) == 0 {
+		return removed, nil
+	}
+
+	if l.db.journal != nil {
+		if err := l.db.write(l.name, removal{Removed: ids}); err != nil {
+			return nil, err
+		}
+	}
+
+	l.store(kept)
+
+	return removed, nil
+}
+
+// backupMessages needs no copy: the slice it keeps is never changed. Its
+// capacity is cut when it is put back, so that the next append does not
+// write over a message a reader may still hold.
+func (l *MessageLog) backupMessages() (func() error, error) {
+	messages, last := l.Messages(), l.last
+
+	return func() error {
+		l.store(messages)
+		l.last = last
+		return nil
+	}, nil
+}
+
+func (l *MessageLog) store(messages []entities.Message) {
+	l.messages.Store(&messages)
+}
+
+func (l *MessageLog) replay(value []byte) error {
+	// A message has no field of a removal, so it never decodes as one.
+	var r removal
+	if err := decode(value, &r); err == nil {
+		_, err := l.Remove(r.Removed)
+		return err
+	}
+
+	var m entities.Message
+	if err := decode(value, &m); err != nil {
+		return err
+	}
+
+	// A record may be replayed over a snapshot which has it already.
+	if messages := *l.messages.Load(); len(messages) == 0 || messages[len(messages)-1].ID < m.ID {
+		l.store(append(messages, m))
+		l.last = max(l.last, m.ID)
+		return nil
+	}
+
+	_, err := l.Replace(m)
+
+	return err
+}
+
+func (l *MessageLog) dump() ([]byte, error) {
+	// The lock waits for an append written to the log to be stored.
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return encode(logSnapshot{Messages: l.Messages(), Last: l.last})
+}
+
+func (l *MessageLog) restore(value []byte) error {
+	var snapshot logSnapshot
+	if err := decode(value, &snapshot); err != nil {
+		// The snapshots written before messages could be removed hold the
+		// bare slice.
+		snapshot.Messages = make([]entities.Message, 0)
+		if err := decode(value, &snapshot.Messages); err != nil {
+			return err
+		}
+	}
+
+	if snapshot.Messages == nil {
+		snapshot.Messages = make([]entities.Message, 0)
+	}
+
+	if n := len(snapshot.Messages); n > 0 {
+		snapshot.Last = max(snapshot.Last, snapshot.Messages[n-1].ID)
+	}
+
+	l.store(snapshot.Messages)
+	l.last = snapshot.Last
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package model
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+// AttachmentModel links an attachment to the message it was sent with.
+type AttachmentModel struct {
+	Attachment entities.Attachment
+	MessageID  int
+	Sender     string
+	Recipient  string
+}
+
+type AttachmentTable struct {
+	Table map[string]AttachmentModel
+}
+
+// AddAttachments adds the attachments of a message.
+type AddAttachments struct {
+	Attachments []AttachmentModel
+}
+
+func (c AddAttachments) Apply(table *AttachmentTable) {
+	for _, val := range c.Attachments {
+		table.Table[val.Attachment.ID] = val
+	}
+}
+
+// RemoveAttachments removes the attachments with the IDs.
+type RemoveAttachments struct {
+	IDs []string
+}
+
+func (c RemoveAttachments) Apply(table *AttachmentTable) {
+	for _, id := range c.IDs {
+		delete(table.Table, id)
+	}
+}
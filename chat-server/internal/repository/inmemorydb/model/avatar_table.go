@@ -2,6 +2,6 @@ package model
 
 import "github.com/vavelour/chat/internal/domain/entities"
 
-type PublicChat struct {
-	Messages []entities.Message
+type AvatarTable struct {
+	Table map[string]entities.Avatar
 }
@@ -0,0 +1,14 @@
+package model
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+// BotTable lists the users created for bots. They stay when the webhooks
+// and commands of the bot are removed, so that the names can be used again.
+type BotTable struct {
+	Table map[string]bool
+}
+
+type BotCommandStore struct {
+	Commands map[string]entities.BotCommand
+	NextID   int
+}
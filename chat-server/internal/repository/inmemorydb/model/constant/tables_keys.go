@@ -1,7 +1,32 @@
 package constant
 
 const (
-	PrivateChatKey = "privateChats"
-	PublicChatKey  = "publicChat"
-	UsersKey       = "userInfo"
+	AvatarsKey            = "avatars"
+	BotCommandsKey        = "botCommands"
+	BlocksKey             = "blocks"
+	BotsKey               = "bots"
+	ContactsKey           = "contacts"
+	ConversationIndexKey  = "conversationIndex"
+	DataExportsKey        = "dataExports"
+	ExpiringMessagesKey   = "expiringMessages"
+	IncomingWebhooksKey   = "incomingWebhooks"
+	LastSeenKey           = "lastSeen"
+	LegalHoldsKey         = "legalHolds"
+	MessageRequestsKey    = "messageRequests"
+	ModerationLogKey      = "moderationLog"
+	NotificationsKey      = "notifications"
+	PrivateAttachmentsKey = "privateAttachments"
+	PrivacyKey            = "privacy"
+	PrivateChatKey        = "privateChats"
+	PrivateReadsKey       = "privateReads"
+	PrivateSearchIndexKey = "privateSearchIndex"
+	PublicAttachmentsKey  = "publicAttachments"
+	PublicChatKey         = "publicChat"
+	PublicSearchIndexKey  = "publicSearchIndex"
+	RemindersKey          = "reminders"
+	ReportsKey            = "reports"
+	SanctionsKey          = "sanctions"
+	ScheduledMessagesKey  = "scheduledMessages"
+	UsersKey              = "userInfo"
+	WebhooksKey           = "webhooks"
 )
@@ -0,0 +1,17 @@
+package model
+
+// ConversationIndexTable keeps, for every user, the partners they have
+// private chats with ordered by the most recent activity first.
+type ConversationIndexTable struct {
+	Table map[string][]string
+}
+
+// SetConversations replaces the partners of the user.
+type SetConversations struct {
+	User     string
+	Partners []string
+}
+
+func (c SetConversations) Apply(index *ConversationIndexTable) {
+	index.Table[c.User] = c.Partners
+}
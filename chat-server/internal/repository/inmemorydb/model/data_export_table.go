@@ -0,0 +1,17 @@
+package model
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+// DataExportTable holds the latest export of the data of every user.
+type DataExportTable struct {
+	Table map[string]entities.DataExport
+}
+
+// SetDataExport replaces the export of the user.
+type SetDataExport struct {
+	Export entities.DataExport
+}
+
+func (c SetDataExport) Apply(table *DataExportTable) {
+	table.Table[c.Export.Username] = c.Export
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// PrivateMessageRefModel points to a message of a private chat.
+type PrivateMessageRefModel struct {
+	Members   MembersPrivateChatModel
+	MessageID int
+}
+
+// ExpiryTable holds the self-destructing messages whose countdown has
+// started, with the time they expire at.
+type ExpiryTable struct {
+	Table map[PrivateMessageRefModel]time.Time
+}
+
+// ScheduleExpiry adds the message to the expiring ones.
+type ScheduleExpiry struct {
+	Ref       PrivateMessageRefModel
+	ExpiresAt time.Time
+}
+
+func (c ScheduleExpiry) Apply(table *ExpiryTable) {
+	table.Table[c.Ref] = c.ExpiresAt
+}
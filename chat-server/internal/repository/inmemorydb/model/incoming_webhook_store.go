@@ -0,0 +1,10 @@
+package model
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+// IncomingWebhookStore keeps the webhooks by ID and their IDs by token hash.
+type IncomingWebhookStore struct {
+	Webhooks map[int]entities.IncomingWebhook
+	Tokens   map[string]int
+	NextID   int
+}
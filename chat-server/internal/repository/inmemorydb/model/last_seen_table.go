@@ -0,0 +1,7 @@
+package model
+
+import "time"
+
+type LastSeenTable struct {
+	Table map[string]time.Time
+}
@@ -0,0 +1,8 @@
+package model
+
+import "time"
+
+// LegalHoldTable holds the time every held user was put on hold.
+type LegalHoldTable struct {
+	Table map[string]time.Time
+}
@@ -0,0 +1,27 @@
+package model
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+// ReportTable keeps the reports in the order of their IDs.
+type ReportTable struct {
+	Reports []entities.Report
+}
+
+// SanctionTable maps every user to their sanctions by kind.
+type SanctionTable struct {
+	Table map[string]map[entities.SanctionKind]entities.Sanction
+}
+
+// ModerationLogTable keeps the audit entries in the order of their IDs.
+type ModerationLogTable struct {
+	Entries []entities.AuditEntry
+}
+
+// AddAuditEntry adds the entry at the end of the audit log.
+type AddAuditEntry struct {
+	Entry entities.AuditEntry
+}
+
+func (c AddAuditEntry) Apply(log *ModerationLogTable) {
+	log.Entries = append(log.Entries, c.Entry)
+}
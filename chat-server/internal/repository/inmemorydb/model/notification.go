@@ -0,0 +1,13 @@
+package model
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+// AppendNotification adds the notification at the end of the
+// notifications of a user.
+type AppendNotification struct {
+	Notification entities.Notification
+}
+
+func (c AppendNotification) Apply(list *[]entities.Notification) {
+	*list = append(*list, c.Notification)
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+type PrivacyTable struct {
+	Table map[string]entities.PrivacySettings
+}
+
+// BlockTable maps every user to the users they blocked and when.
+type BlockTable struct {
+	Table map[string]map[string]time.Time
+}
+
+// ContactTable maps every user to the users they have talked to: sent a
+// message to or accepted a message request from.
+type ContactTable struct {
+	Table map[string]map[string]bool
+}
+
+// MessageRequestTable maps every user to the strangers whose messages wait
+// for them to accept, the latest first.
+type MessageRequestTable struct {
+	Table map[string][]string
+}
+
+// SetPrivacySettings replaces the privacy settings of the user.
+type SetPrivacySettings struct {
+	User     string
+	Settings entities.PrivacySettings
+}
+
+func (c SetPrivacySettings) Apply(privacy *PrivacyTable) {
+	privacy.Table[c.User] = c.Settings
+}
+
+func (c SetPrivacySettings) Undo(privacy *PrivacyTable) func(privacy *PrivacyTable) {
+	settings, ok := privacy.Table[c.User]
+
+	return func(privacy *PrivacyTable) {
+		if ok {
+			privacy.Table[c.User] = settings
+		} else {
+			delete(privacy.Table, c.User)
+		}
+	}
+}
+
+// AddContact adds the partner to the contacts of the user.
+type AddContact struct {
+	User    string
+	Partner string
+}
+
+func (c AddContact) Apply(contacts *ContactTable) {
+	if contacts.Table[c.User] == nil {
+		contacts.Table[c.User] = make(map[string]bool)
+	}
+
+	contacts.Table[c.User][c.Partner] = true
+}
+
+// SetMessageRequests replaces the message requests of the user.
+type SetMessageRequests struct {
+	User     string
+	Partners []string
+}
+
+func (c SetMessageRequests) Apply(requests *MessageRequestTable) {
+	requests.Table[c.User] = c.Partners
+}
@@ -4,4 +4,16 @@ import "github.com/vavelour/chat/internal/domain/entities"
 
 type PrivateChat struct {
 	Messages []entities.Message
+	// LastID is the highest ID the retention policy removed, which stays
+	// taken.
+	LastID int
+}
+
+// AppendMessage adds the message at the end of the conversation.
+type AppendMessage struct {
+	Message entities.Message
+}
+
+func (c AppendMessage) Apply(chat *PrivateChat) {
+	chat.Messages = append(chat.Messages, c.Message)
 }
@@ -1,5 +0,0 @@
-package model
-
-type PrivateChatTable struct {
-	Table map[MembersPrivateChatModel]PrivateChat
-}
@@ -0,0 +1,20 @@
+package model
+
+type ReadMarkerModel struct {
+	Reader  string
+	Partner string
+}
+
+type PrivateReadTable struct {
+	Table map[ReadMarkerModel]int
+}
+
+// SetReadMarker moves the read marker to the message.
+type SetReadMarker struct {
+	Marker    ReadMarkerModel
+	MessageID int
+}
+
+func (c SetReadMarker) Apply(table *PrivateReadTable) {
+	table.Table[c.Marker] = c.MessageID
+}
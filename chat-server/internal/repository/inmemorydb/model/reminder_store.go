@@ -0,0 +1,8 @@
+package model
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+type ReminderStore struct {
+	Reminders map[int]entities.Reminder
+	NextID    int
+}
@@ -0,0 +1,8 @@
+package model
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+type ScheduledMessageStore struct {
+	Messages map[int]entities.ScheduledMessage
+	NextID   int
+}
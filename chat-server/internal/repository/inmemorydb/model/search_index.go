@@ -0,0 +1,55 @@
+package model
+
+// PostingModel points to a message containing a word. Members are empty for
+// messages of the public chat.
+type PostingModel struct {
+	Members   MembersPrivateChatModel
+	MessageID int
+}
+
+// SearchIndex is an inverted index from every word to the messages which
+// contain it, in the order they were sent.
+type SearchIndex struct {
+	Postings map[string][]PostingModel
+}
+
+// AddPosting adds the posting of a message to the words it contains.
+type AddPosting struct {
+	Words   []string
+	Posting PostingModel
+}
+
+func (c AddPosting) Apply(index *SearchIndex) {
+	for _, word := range c.Words {
+		index.Postings[word] = append(index.Postings[word], c.Posting)
+	}
+}
+
+// RemovePosting removes the posting of a message from the words it
+// contains.
+type RemovePosting struct {
+	Words   []string
+	Posting PostingModel
+}
+
+func (c RemovePosting) Apply(index *SearchIndex) {
+	for _, word := range c.Words {
+		postings, ok := index.Postings[word]
+		if !ok {
+			continue
+		}
+
+		kept := postings[:0]
+		for _, val := range postings {
+			if val != c.Posting {
+				kept = append(kept, val)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(index.Postings, word)
+		} else {
+			index.Postings[word] = kept
+		}
+	}
+}
@@ -5,3 +5,24 @@ import "github.com/vavelour/chat/internal/domain/entities"
 type UsersTable struct {
 	Table map[string]entities.User
 }
+
+// SetUser adds the user or replaces it.
+type SetUser struct {
+	User entities.User
+}
+
+func (c SetUser) Apply(table *UsersTable) {
+	table.Table[c.User.Username] = c.User
+}
+
+func (c SetUser) Undo(table *UsersTable) func(table *UsersTable) {
+	user, ok := table.Table[c.User.Username]
+
+	return func(table *UsersTable) {
+		if ok {
+			table.Table[user.Username] = user
+		} else {
+			delete(table.Table, c.User.Username)
+		}
+	}
+}
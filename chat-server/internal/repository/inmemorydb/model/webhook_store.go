@@ -0,0 +1,52 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+// WebhookStore holds the subscriptions together with the delivery queue.
+// Deliveries keep only the ID of their webhook.
+type WebhookStore struct {
+	Webhooks       map[int]entities.Webhook
+	Deliveries     []entities.WebhookDelivery
+	NextWebhookID  int
+	NextEventID    int
+	NextDeliveryID int
+}
+
+// AddDeliveries queues the deliveries of an event.
+type AddDeliveries struct {
+	EventID    int
+	Deliveries []entities.WebhookDelivery
+}
+
+func (c AddDeliveries) Apply(store *WebhookStore) {
+	store.NextEventID = max(store.NextEventID, c.EventID)
+
+	for _, d := range c.Deliveries {
+		store.NextDeliveryID = max(store.NextDeliveryID, d.ID)
+		store.Deliveries = append(store.Deliveries, d)
+	}
+}
+
+// UpdateDeliveries replaces the deliveries and the webhooks with the same
+// IDs. The deliveries are kept in the order of their IDs.
+type UpdateDeliveries struct {
+	Deliveries []entities.WebhookDelivery
+	Webhooks   []entities.Webhook
+}
+
+func (c UpdateDeliveries) Apply(store *WebhookStore) {
+	for _, d := range c.Deliveries {
+		i := sort.Search(len(store.Deliveries), func(i int) bool { return store.Deliveries[i].ID >= d.ID })
+		if i < len(store.Deliveries) && store.Deliveries[i].ID == d.ID {
+			store.Deliveries[i] = d
+		}
+	}
+
+	for _, w := range c.Webhooks {
+		store.Webhooks[w.ID] = w
+	}
+}
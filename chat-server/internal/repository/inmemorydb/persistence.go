@@ -0,0 +1,162 @@
+package inmemorydb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// FsyncPolicy tells when the records of the write-ahead log are flushed to
+// the disk.
+type FsyncPolicy string
+
+const (
+	// FsyncAlways flushes every record before the write returns: nothing is
+	// lost on a crash, at the cost of a disk flush per write.
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval flushes the log every FsyncInterval: a crash loses at
+	// most the writes of the last interval.
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever leaves flushing to the operating system, except on
+	// snapshots and shutdown.
+	FsyncNever FsyncPolicy = "never"
+)
+
+func (p FsyncPolicy) Valid() bool {
+	return p == FsyncAlways || p == FsyncInterval || p == FsyncNever
+}
+
+type PersistenceOptions struct {
+	// Dir holds the log and the snapshots. An empty Dir keeps the database
+	// in memory only.
+	Dir           string
+	Fsync         FsyncPolicy
+	FsyncInterval time.Duration
+	// SnapshotInterval is how often the log is compacted into a snapshot;
+	// zero turns the periodic snapshots off.
+	SnapshotInterval time.Duration
+}
+
+// Open returns a database which survives restarts: every change is appended
+// to a write-ahead log in opts.Dir, and the log is compacted into snapshots
+// by Run. The state left by the previous run is read back first.
+//
+// A change of the public chat is logged as the message added or replaced,
+// and a change of any other table as the change itself, such as the
+// postings of a message or the deliveries of an event, or as the whole
+// table or shard when it was replaced. A write costs as much as the
+// encoding of what it logs. Once a write to the log fails, every later
+// write fails with ErrLogFailed, so that the log never misses a change
+// which a later one depends on.
+func Open(opts PersistenceOptions) (*MemoryDB, error) {
+	db := NewDB()
+	db.opts = opts
+
+	if opts.Dir == "" {
+		return db, nil
+	}
+
+	if !opts.Fsync.Valid() {
+		return nil, fmt.Errorf("inmemorydb: unknown fsync policy %q", opts.Fsync)
+	}
+
+	if opts.Fsync == FsyncInterval && opts.FsyncInterval <= 0 {
+		return nil, fmt.Errorf("inmemorydb: fsync interval must be positive")
+	}
+
+	j, err := openJournal(opts.Dir, opts.Fsync, db.tables)
+	if err != nil {
+		return nil, fmt.Errorf("inmemorydb: open %s: %w", opts.Dir, err)
+	}
+	db.journal = j
+
+	return db, nil
+}
+
+// Run flushes the log and takes the snapshots as the options say until ctx
+// is done or Shutdown is called. It returns at once for a database without
+// persistence.
+func (db *MemoryDB) Run(ctx context.Context) {
+	defer close(db.done)
+
+	if db.journal == nil {
+		return
+	}
+
+	var fsyncC, snapshotC <-chan time.Time
+	if db.opts.Fsync == FsyncInterval {
+		ticker := time.NewTicker(db.opts.FsyncInterval)
+		defer ticker.Stop()
+		fsyncC = ticker.C
+	}
+
+	if db.opts.SnapshotInterval > 0 {
+		ticker := time.NewTicker(db.opts.SnapshotInterval)
+		defer ticker.Stop()
+		snapshotC = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-db.stop:
+			return
+		case <-fsyncC:
+			if err := db.journal.sync(); err != nil {
+				log.Printf("inmemorydb: sync the log: %s", err)
+			}
+		case <-snapshotC:
+			if err := db.Snapshot(); err != nil {
+				log.Printf("inmemorydb: snapshot: %s", err)
+			}
+		}
+	}
+}
+
+// Snapshot compacts the log: it writes the current tables to a snapshot
+// and removes the log records the snapshot covers. It waits for the unit of
+// work running, whose changes may yet be rolled back.
+func (db *MemoryDB) Snapshot() error {
+	if db.journal == nil {
+		return nil
+	}
+
+	db.units.RLock()
+	defer db.units.RUnlock()
+
+	return db.journal.snapshot()
+}
+
+// FailWrites makes the log fail like a full disk once n more bytes are
+// written to it: the write going past them is cut off there. It is meant for
+// the tests of what a restart finds after a failed write.
+func (db *MemoryDB) FailWrites(n int64) {
+	if db.journal == nil {
+		return
+	}
+
+	db.journal.mu.Lock()
+	defer db.journal.mu.Unlock()
+
+	db.journal.space = n
+}
+
+// Shutdown stops Run, then flushes and closes the log. Changes made after it
+// fail.
+func (db *MemoryDB) Shutdown(ctx context.Context) error {
+	db.stopOnce.Do(func() { close(db.stop) })
+
+	select {
+	case <-db.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if db.journal == nil {
+		return nil
+	}
+
+	return db.journal.close()
+}
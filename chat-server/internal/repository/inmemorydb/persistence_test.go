@@ -0,0 +1,356 @@
+package inmemorydb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+func openTestDB(t *testing.T, dir string) *MemoryDB {
+	t.Helper()
+
+	db, err := Open(PersistenceOptions{Dir: dir, Fsync: FsyncAlways})
+	require.NoError(t, err)
+
+	go db.Run(context.Background())
+
+	return db
+}
+
+func closeTestDB(t *testing.T, db *MemoryDB) {
+	t.Helper()
+
+	require.NoError(t, db.Shutdown(context.Background()))
+}
+
+func insertUser(db *MemoryDB, username string) {
+	defer db.Lock(db.Users.ForWrite())()
+
+	db.Users.Update(model.SetUser{User: entities.User{Username: username, Password: "123"}})
+}
+
+func usernames(db *MemoryDB) []string {
+	defer db.Lock(db.Users.ForRead())()
+
+	var names []string
+	for name := range db.Users.Get().Table {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func TestOpen_ReplaysLog(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	insertUser(db, "tester")
+	insertUser(db, "valera")
+	db.Sanctions.Set(model.SanctionTable{Table: map[string]map[entities.SanctionKind]entities.Sanction{
+		"valera": {},
+	}})
+	db.PublicChat.Append(entities.Message{ID: 1, Sender: "tester", Content: "hello"})
+	db.PublicChat.Append(entities.Message{ID: 2, Sender: "valera", Content: "hi"})
+	db.PublicChat.Replace(entities.Message{ID: 1, Sender: "tester", Deleted: true})
+	members := model.MembersPrivateChatModel{User1: "tester", User2: "valera"}
+	db.PrivateChats.Shard(members).Set(model.PrivateChat{Messages: []entities.Message{{ID: 1, Sender: "tester", Recipient: "valera"}}})
+	db.Notifications.Shard("valera").Set([]entities.Notification{{ID: 1, Sender: "tester"}})
+	closeTestDB(t, db)
+
+	db = openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	assert.ElementsMatch(t, []string{"tester", "valera"}, usernames(db))
+	assert.Equal(t, []entities.Message{
+		{ID: 1, Sender: "tester", Deleted: true},
+		{ID: 2, Sender: "valera", Content: "hi"},
+	}, db.PublicChat.Messages())
+
+	chat, ok := db.PrivateChats.Shard(members).Get()
+	assert.True(t, ok)
+	assert.Len(t, chat.Messages, 1)
+
+	notifications, ok := db.Notifications.Shard("valera").Get()
+	assert.True(t, ok)
+	assert.Equal(t, []entities.Notification{{ID: 1, Sender: "tester"}}, notifications)
+
+	_, ok = db.Notifications.Lookup("tester")
+	assert.False(t, ok)
+
+	// The tables read back have to stay writable.
+	db.Sanctions.Get().Table["valera"][entities.SanctionMute] = entities.Sanction{Username: "valera", Kind: entities.SanctionMute}
+	db.Webhooks.Get().Webhooks[1] = entities.Webhook{ID: 1}
+	insertUser(db, "vika")
+}
+
+func TestOpen_TruncatesTornTail(t *testing.T) {
+	testTable := []struct {
+		name   string
+		damage func(t *testing.T, path string, good int64)
+	}{
+		{
+			name: "torn_record",
+			damage: func(t *testing.T, path string, good int64) {
+				require.NoError(t, os.Truncate(path, good+recordHeaderSize+3))
+			},
+		},
+		{
+			name: "bad_checksum",
+			damage: func(t *testing.T, path string, good int64) {
+				f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+				require.NoError(t, err)
+				defer f.Close()
+
+				_, err = f.WriteAt([]byte{0xff, 0xff}, good+recordHeaderSize+4)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "torn_header",
+			damage: func(t *testing.T, path string, good int64) {
+				require.NoError(t, os.Truncate(path, good+recordHeaderSize/2))
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, walName(0))
+
+			db := openTestDB(t, dir)
+			insertUser(db, "tester")
+			closeTestDB(t, db)
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			good := info.Size()
+
+			db = openTestDB(t, dir)
+			insertUser(db, "valera")
+			closeTestDB(t, db)
+
+			testCase.damage(t, path, good)
+
+			db = openTestDB(t, dir)
+			assert.Equal(t, []string{"tester"}, usernames(db))
+
+			info, err = os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, good, info.Size())
+
+			// The log goes on after the cut.
+			insertUser(db, "vika")
+			closeTestDB(t, db)
+
+			db = openTestDB(t, dir)
+			defer closeTestDB(t, db)
+			assert.ElementsMatch(t, []string{"tester", "vika"}, usernames(db))
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	insertUser(db, "tester")
+	insertUser(db, "valera")
+	db.PublicChat.Append(entities.Message{ID: 1, Sender: "tester"})
+	db.Notifications.Shard("valera").Set([]entities.Notification{{ID: 1, Sender: "tester"}})
+	require.NoError(t, db.Snapshot())
+	insertUser(db, "vika")
+	db.PublicChat.Append(entities.Message{ID: 2, Sender: "vika"})
+	closeTestDB(t, db)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{snapshotName(1), walName(1)}, names)
+
+	db = openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	assert.ElementsMatch(t, []string{"tester", "valera", "vika"}, usernames(db))
+	assert.Equal(t, []entities.Message{{ID: 1, Sender: "tester"}, {ID: 2, Sender: "vika"}}, db.PublicChat.Messages())
+	assert.Equal(t, 2, db.journal.records)
+
+	notifications, _ := db.Notifications.Shard("valera").Get()
+	assert.Equal(t, []entities.Notification{{ID: 1, Sender: "tester"}}, notifications)
+}
+
+func TestOpen_ReplaysLogsOfUnfinishedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	insertUser(db, "tester")
+
+	// A crash after the switch to the next log, before the snapshot of it
+	// was written.
+	db.journal.mu.Lock()
+	require.NoError(t, db.journal.rotate(1))
+	db.journal.mu.Unlock()
+
+	insertUser(db, "valera")
+	closeTestDB(t, db)
+
+	db = openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	assert.ElementsMatch(t, []string{"tester", "valera"}, usernames(db))
+}
+
+func TestOpen_RefusesCorruptSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	insertUser(db, "tester")
+	require.NoError(t, db.Snapshot())
+	closeTestDB(t, db)
+
+	path := filepath.Join(dir, snapshotName(1))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-1))
+
+	_, err = Open(PersistenceOptions{Dir: dir, Fsync: FsyncAlways})
+	assert.ErrorIs(t, err, errCorruptRecord)
+}
+
+func TestOpen_Options(t *testing.T) {
+	_, err := Open(PersistenceOptions{Dir: t.TempDir(), Fsync: "sometimes"})
+	assert.Error(t, err)
+
+	_, err = Open(PersistenceOptions{Dir: t.TempDir(), Fsync: FsyncInterval})
+	assert.Error(t, err)
+
+	db, err := Open(PersistenceOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, db.journal)
+
+	db, err = Open(PersistenceOptions{Dir: t.TempDir(), Fsync: FsyncInterval, FsyncInterval: time.Millisecond})
+	require.NoError(t, err)
+	go db.Run(context.Background())
+	insertUser(db, "tester")
+	closeTestDB(t, db)
+}
+
+func TestMessageLog_Remove(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	db.PublicChat.Append(entities.Message{ID: 1, Sender: "tester"})
+	db.PublicChat.Append(entities.Message{ID: 2, Sender: "valera"})
+	db.PublicChat.Append(entities.Message{ID: 3, Sender: "tester"})
+	removed, err := db.PublicChat.Remove([]int{1, 3, 4})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, []int{removed[0].ID, removed[1].ID})
+	closeTestDB(t, db)
+
+	// The removal is replayed from the log, and the ID of the last message
+	// stays taken.
+	db = openTestDB(t, dir)
+	assert.Equal(t, []entities.Message{{ID: 2, Sender: "valera"}}, db.PublicChat.Messages())
+	assert.Equal(t, 4, db.PublicChat.NextID())
+
+	require.NoError(t, db.Snapshot())
+	closeTestDB(t, db)
+
+	db = openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	assert.Equal(t, []entities.Message{{ID: 2, Sender: "valera"}}, db.PublicChat.Messages())
+	assert.Equal(t, 4, db.PublicChat.NextID())
+}
+
+func TestOpen_ReplaysChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, walName(0))
+
+	addPosting := func(id int) model.AddPosting {
+		return model.AddPosting{Words: []string{"hello"}, Posting: model.PostingModel{MessageID: id}}
+	}
+
+	logSize := func() int64 {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+
+		return info.Size()
+	}
+
+	db := openTestDB(t, dir)
+	require.NoError(t, db.PublicSearchIndex.Update(addPosting(1)))
+	first := logSize() - int64(len(walMagic))
+
+	// A change is logged on its own, however large the table grew.
+	for id := 2; id <= 101; id++ {
+		require.NoError(t, db.PublicSearchIndex.Update(addPosting(id)))
+	}
+	assert.Less(t, logSize()-first-int64(len(walMagic)), 100*2*first)
+
+	require.NoError(t, db.Snapshot())
+	require.NoError(t, db.PublicSearchIndex.Update(addPosting(102)))
+	require.NoError(t, db.Notifications.Shard("valera").Update(model.AppendNotification{Notification: entities.Notification{ID: 1}}))
+	closeTestDB(t, db)
+
+	db = openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	postings := db.PublicSearchIndex.Get().Postings["hello"]
+	require.Len(t, postings, 102)
+	for i, posting := range postings {
+		assert.Equal(t, i+1, posting.MessageID)
+	}
+
+	notifications, ok := db.Notifications.Shard("valera").Get()
+	assert.True(t, ok)
+	assert.Equal(t, []entities.Notification{{ID: 1}}, notifications)
+}
+
+func TestTable_ReplaySkipsRecordsOfSnapshot(t *testing.T) {
+	db := NewDB()
+
+	record, err := encode(tableRecord[model.SearchIndex]{
+		Seq:    1,
+		Change: model.AddPosting{Words: []string{"hello"}, Posting: model.PostingModel{MessageID: 1}},
+	})
+	require.NoError(t, err)
+
+	// The snapshot taken while the record was written may hold it already.
+	require.NoError(t, db.PublicSearchIndex.replay(record))
+	require.NoError(t, db.PublicSearchIndex.replay(record))
+
+	assert.Equal(t, []model.PostingModel{{MessageID: 1}}, db.PublicSearchIndex.Get().Postings["hello"])
+}
+
+func TestWrite_FailedLog(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	require.NoError(t, db.journal.file.Close())
+
+	users := db.Users.Get()
+	users.Table["tester"] = entities.User{Username: "tester"}
+	assert.ErrorIs(t, db.Users.Set(users), ErrLogFailed)
+
+	// The log misses a change, so it takes no more even once it could.
+	file, err := os.OpenFile(filepath.Join(dir, walName(0)), os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	db.journal.file = file
+
+	assert.ErrorIs(t, db.PublicChat.Append(entities.Message{ID: 1}), ErrLogFailed)
+	assert.Empty(t, db.PublicChat.Messages())
+}
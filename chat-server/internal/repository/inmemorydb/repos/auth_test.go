@@ -1,63 +1,47 @@
 package repos
 
 import (
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
 	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
-	"github.com/vavelour/chat/internal/repository/inmemorydb/model/constant"
-	mock_repos "github.com/vavelour/chat/internal/repository/inmemorydb/repos/mocks"
 	"testing"
 )
 
 func TestAuthRepos_GetUser(t *testing.T) {
-	type mockBehavior func(m *mock_repos.MockMemoryDB, username string)
+	type seed func(db *inmemorydb.MemoryDB, username string)
 
 	testTable := []struct {
 		name          string
 		username      string
-		mockBehavior  mockBehavior
+		seed          seed
 		expectedUser  entities.User
 		expectedError error
 	}{
 		{
 			name:     "ok",
 			username: "tester",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, username string) {
-				m.EXPECT().Get(constant.UsersKey).Return(model.UsersTable{Table: map[string]entities.User{username: {Username: username, Password: "123"}}})
+			seed: func(db *inmemorydb.MemoryDB, username string) {
+				db.Users.Set(model.UsersTable{Table: map[string]entities.User{username: {Username: username, Password: "123"}}})
 			},
 			expectedUser:  entities.User{Username: "tester", Password: "123"},
 			expectedError: nil,
 		},
 		{
-			name:     "user_not_found",
-			username: "tester",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, username string) {
-				m.EXPECT().Get(constant.UsersKey).Return(model.UsersTable{Table: map[string]entities.User{}})
-			},
+			name:          "user_not_found",
+			username:      "tester",
+			seed:          func(db *inmemorydb.MemoryDB, username string) {},
 			expectedUser:  entities.User{},
 			expectedError: errUnregisteredUser,
 		},
-		{
-			name:     "incorrect_type",
-			username: "tester",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, username string) {
-				m.EXPECT().Get(constant.UsersKey).Return("invalid type")
-			},
-			expectedUser:  entities.User{},
-			expectedError: errIncorrectType,
-		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			mockDB := mock_repos.NewMockMemoryDB(ctrl)
-			repo := NewAuthRepos(mockDB)
+			db := inmemorydb.NewDB()
+			repo := NewAuthRepos(db)
 
-			testCase.mockBehavior(mockDB, testCase.username)
+			testCase.seed(db, testCase.username)
 
 			user, err := repo.GetUser(testCase.username)
 			assert.Equal(t, testCase.expectedError, err)
@@ -67,60 +51,46 @@ func TestAuthRepos_GetUser(t *testing.T) {
 }
 
 func TestAuthRepos_InsertUser(t *testing.T) {
-	type mockBehavior func(m *mock_repos.MockMemoryDB, username, password string)
+	type seed func(db *inmemorydb.MemoryDB, username, password string)
 
 	testTable := []struct {
 		name          string
 		username      string
 		password      string
-		mockBehavior  mockBehavior
+		seed          seed
+		expectedUser  entities.User
 		expectedError error
 	}{
 		{
-			name:     "ok",
-			username: "tester",
-			password: "123",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, username, password string) {
-				m.EXPECT().Get(constant.UsersKey).Return(model.UsersTable{Table: map[string]entities.User{}})
-				m.EXPECT().Insert(constant.UsersKey, gomock.Any()).Do(func(key string, data interface{}) {
-					users, _ := data.(model.UsersTable)
-					assert.Equal(t, entities.User{Username: username, Password: password}, users.Table[username])
-				})
-			},
+			name:          "ok",
+			username:      "tester",
+			password:      "123",
+			seed:          func(db *inmemorydb.MemoryDB, username, password string) {},
+			expectedUser:  entities.User{Username: "tester", Password: "123"},
 			expectedError: nil,
 		},
 		{
 			name:     "user_already_exists",
 			username: "tester",
 			password: "123",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, username, password string) {
-				m.EXPECT().Get(constant.UsersKey).Return(model.UsersTable{Table: map[string]entities.User{username: {Username: username, Password: password}}})
+			seed: func(db *inmemorydb.MemoryDB, username, password string) {
+				db.Users.Set(model.UsersTable{Table: map[string]entities.User{username: {Username: username, Password: "old"}}})
 			},
+			expectedUser:  entities.User{Username: "tester", Password: "old"},
 			expectedError: errUserAlreadyExists,
 		},
-		{
-			name:     "incorrect_type",
-			username: "tester",
-			password: "123",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, username, password string) {
-				m.EXPECT().Get(constant.UsersKey).Return("invalid type")
-			},
-			expectedError: errIncorrectType,
-		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			mockDB := mock_repos.NewMockMemoryDB(ctrl)
-			repo := NewAuthRepos(mockDB)
+			db := inmemorydb.NewDB()
+			repo := NewAuthRepos(db)
 
-			testCase.mockBehavior(mockDB, testCase.username, testCase.password)
+			testCase.seed(db, testCase.username, testCase.password)
 
 			err := repo.InsertUser(testCase.username, testCase.password)
 			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedUser, db.Users.Get().Table[testCase.username])
 		})
 	}
 }
@@ -0,0 +1,46 @@
+package repos
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+)
+
+type AvatarRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewAvatarRepos(db *inmemorydb.MemoryDB) *AvatarRepos {
+	return &AvatarRepos{db: db}
+}
+
+// SetAvatar replaces the avatar of the user unless a newer one was set in
+// the meantime. An avatar with an empty ID removes the current one. It
+// returns the ID of the avatar which is no longer used, if any.
+func (a *AvatarRepos) SetAvatar(avatar entities.Avatar) (string, error) {
+	defer a.db.Lock(a.db.Avatars.ForWrite())()
+
+	avatars := a.db.Avatars.Get()
+
+	current := avatars.Table[avatar.Username]
+	if avatar.UpdatedAt.Before(current.UpdatedAt) {
+		return avatar.ID, nil
+	}
+
+	avatars.Table[avatar.Username] = avatar
+	if err := a.db.Avatars.Set(avatars); err != nil {
+		return "", err
+	}
+
+	return current.ID, nil
+}
+
+func (a *AvatarRepos) GetAvatar(username string) (entities.Avatar, error) {
+	defer a.db.Lock(a.db.Avatars.ForRead())()
+
+	avatar, ok := a.db.Avatars.Get().Table[username]
+	if !ok || avatar.ID == "" {
+		return entities.Avatar{}, entities.ErrAvatarNotFound
+	}
+
+	return avatar, nil
+}
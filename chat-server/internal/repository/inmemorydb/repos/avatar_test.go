@@ -0,0 +1,105 @@
+package repos
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"testing"
+	"time"
+)
+
+func TestAvatarRepos_SetAvatar(t *testing.T) {
+	earlier := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Minute)
+	current := entities.Avatar{Username: "tester", ID: "old", ContentType: "image/jpeg", UpdatedAt: earlier}
+
+	testTable := []struct {
+		name           string
+		stored         map[string]entities.Avatar
+		avatar         entities.Avatar
+		expectedStale  string
+		expectedAvatar entities.Avatar
+	}{
+		{
+			name:           "ok",
+			stored:         map[string]entities.Avatar{"tester": current},
+			avatar:         entities.Avatar{Username: "tester", ID: "new", ContentType: "image/png", UpdatedAt: later},
+			expectedStale:  "old",
+			expectedAvatar: entities.Avatar{Username: "tester", ID: "new", ContentType: "image/png", UpdatedAt: later},
+		},
+		{
+			name:           "first_avatar",
+			stored:         map[string]entities.Avatar{},
+			avatar:         entities.Avatar{Username: "tester", ID: "new", ContentType: "image/png", UpdatedAt: later},
+			expectedStale:  "",
+			expectedAvatar: entities.Avatar{Username: "tester", ID: "new", ContentType: "image/png", UpdatedAt: later},
+		},
+		{
+			name:           "older_avatar_ignored",
+			stored:         map[string]entities.Avatar{"tester": current},
+			avatar:         entities.Avatar{Username: "tester", ID: "outdated", ContentType: "image/png", UpdatedAt: earlier.Add(-time.Minute)},
+			expectedStale:  "outdated",
+			expectedAvatar: current,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewAvatarRepos(db)
+
+			db.Avatars.Set(model.AvatarTable{Table: testCase.stored})
+
+			stale, err := repo.SetAvatar(testCase.avatar)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStale, stale)
+			assert.Equal(t, testCase.expectedAvatar, db.Avatars.Get().Table["tester"])
+		})
+	}
+}
+
+func TestAvatarRepos_GetAvatar(t *testing.T) {
+	avatar := entities.Avatar{Username: "tester", ID: "a1", ContentType: "image/jpeg", UpdatedAt: time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)}
+
+	testTable := []struct {
+		name           string
+		username       string
+		data           model.AvatarTable
+		expectedAvatar entities.Avatar
+		expectedError  error
+	}{
+		{
+			name:           "ok",
+			username:       "tester",
+			data:           model.AvatarTable{Table: map[string]entities.Avatar{"tester": avatar}},
+			expectedAvatar: avatar,
+			expectedError:  nil,
+		},
+		{
+			name:          "deleted",
+			username:      "tester",
+			data:          model.AvatarTable{Table: map[string]entities.Avatar{"tester": {Username: "tester", UpdatedAt: avatar.UpdatedAt}}},
+			expectedError: entities.ErrAvatarNotFound,
+		},
+		{
+			name:          "not_found",
+			username:      "valera",
+			data:          model.AvatarTable{Table: map[string]entities.Avatar{"tester": avatar}},
+			expectedError: entities.ErrAvatarNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewAvatarRepos(db)
+
+			db.Avatars.Set(testCase.data)
+
+			result, err := repo.GetAvatar(testCase.username)
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedAvatar, result)
+		})
+	}
+}
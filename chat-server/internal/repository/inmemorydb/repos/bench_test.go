@@ -0,0 +1,182 @@
+package repos
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+)
+
+const (
+	benchUsers    = 64
+	benchMessages = 1000
+	benchPage     = 20
+)
+
+type benchRepos struct {
+	public  *PublicRepos
+	private *PrivateRepos
+	users   []string
+}
+
+// newBenchRepos returns the repositories over a database holding
+// benchMessages messages in the public chat and in every conversation of a
+// user with the next one.
+func newBenchRepos(b *testing.B) benchRepos {
+	b.Helper()
+
+	db := inmemorydb.NewDB()
+	auth := NewAuthRepos(db)
+
+	r := benchRepos{public: NewPublicRepos(db), private: NewPrivateRepos(db)}
+
+	for i := 0; i < benchUsers; i++ {
+		username := fmt.Sprintf("user%02d", i)
+		if err := auth.InsertUser(username, "123"); err != nil {
+			b.Fatal(err)
+		}
+		r.users = append(r.users, username)
+	}
+
+	for i := 0; i < benchMessages; i++ {
+		if err := r.public.InsertMessage(entities.Message{Sender: r.users[i%benchUsers], Content: "hello, world!"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for i := range r.users {
+		for j := 0; j < benchMessages/10; j++ {
+			if err := r.private.InsertMessage(r.privateMessage(i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	return r
+}
+
+// privateMessage returns a message of the i-th user to the next one, so the
+// users write to different conversations.
+func (r benchRepos) privateMessage(i int) entities.Message {
+	i %= len(r.users)
+
+	return entities.Message{Sender: r.users[i], Recipient: r.users[(i+1)%len(r.users)], Content: "hello, neighbour!"}
+}
+
+// op runs the n-th operation of a worker: one write for every ratio reads,
+// alternating the public chat and the conversation of the worker.
+func (r benchRepos) op(b *testing.B, worker, n, ratio int) {
+	var err error
+
+	write := n%(ratio+1) == 0
+	public := (n/(ratio+1))%2 == 0
+
+	switch {
+	case write && public:
+		err = r.public.InsertMessage(entities.Message{Sender: r.users[worker%len(r.users)], Content: "hello, world!"})
+	case write:
+		err = r.private.InsertMessage(r.privateMessage(worker))
+	case n%2 == 0:
+		_, err = r.public.GetMessages(benchPage, 0, entities.OrderOldestFirst)
+	default:
+		m := r.privateMessage(worker)
+		_, err = r.private.GetMessages(m.Sender, m.Recipient, benchPage, 0, entities.OrderOldestFirst)
+	}
+
+	if err != nil {
+		b.Error(err)
+	}
+}
+
+func runMixed(b *testing.B, ratio int) {
+	r := newBenchRepos(b)
+
+	var workers atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		worker := int(workers.Add(1))
+
+		for n := 0; pb.Next(); n++ {
+			r.op(b, worker, n, ratio)
+		}
+	})
+}
+
+// BenchmarkMixed runs concurrent reads and writes of the public chat and of
+// the private conversations, one write for every 9 reads.
+func BenchmarkMixed(b *testing.B) {
+	runMixed(b, 9)
+}
+
+// BenchmarkMixedWriteHeavy runs as many writes as reads.
+func BenchmarkMixedWriteHeavy(b *testing.B) {
+	runMixed(b, 1)
+}
+
+// BenchmarkPrivateWrite sends messages to different conversations
+// concurrently. The writers take turns on the indexes shared by the
+// conversations, so it measures the cost of a write rather than how the
+// writes scale with the cores.
+func BenchmarkPrivateWrite(b *testing.B) {
+	r := newBenchRepos(b)
+
+	var workers atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		worker := int(workers.Add(1))
+
+		for pb.Next() {
+			if err := r.private.InsertMessage(r.privateMessage(worker)); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+// BenchmarkPublicRead reads the public chat while one writer posts to it.
+func BenchmarkPublicRead(b *testing.B) {
+	r := newBenchRepos(b)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			if err := r.public.InsertMessage(entities.Message{Sender: r.users[0], Content: "hello, world!"}); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := r.public.GetMessages(benchPage, 0, entities.OrderOldestFirst); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+
+	b.StopTimer()
+	close(stop)
+	<-done
+}
@@ -0,0 +1,113 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+type BotCommandRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewBotCommandRepos(db *inmemorydb.MemoryDB) *BotCommandRepos {
+	return &BotCommandRepos{db: db}
+}
+
+// InsertBotCommand saves the command and registers its bot as a user with
+// the given password, unless the bot exists already.
+func (r *BotCommandRepos) InsertBotCommand(c entities.BotCommand, botPassword string) (entities.BotCommand, error) {
+	defer r.db.Lock(r.db.Users.ForWrite(), r.db.Bots.ForWrite(), r.db.BotCommands.ForWrite())()
+
+	store := r.db.BotCommands.Get()
+
+	if _, ok := store.Commands[c.Command]; ok {
+		return entities.BotCommand{}, entities.ErrCommandTaken
+	}
+
+	if err := registerBot(r.db, c.BotName, botPassword); err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	store.NextID++
+	c.ID = store.NextID
+	c.CreatedAt = time.Now()
+	store.Commands[c.Command] = c
+
+	if err := r.db.BotCommands.Set(store); err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	return c, nil
+}
+
+func (r *BotCommandRepos) GetBotCommands() ([]entities.BotCommand, error) {
+	defer r.db.Lock(r.db.BotCommands.ForRead())()
+
+	store := r.db.BotCommands.Get()
+
+	commands := make([]entities.BotCommand, 0, len(store.Commands))
+	for _, c := range store.Commands {
+		commands = append(commands, c)
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Command < commands[j].Command
+	})
+
+	return commands, nil
+}
+
+func (r *BotCommandRepos) GetBotCommand(command string) (entities.BotCommand, error) {
+	defer r.db.Lock(r.db.BotCommands.ForRead())()
+
+	c, ok := r.db.BotCommands.Get().Commands[command]
+	if !ok {
+		return entities.BotCommand{}, entities.ErrBotCommandNotFound
+	}
+
+	return c, nil
+}
+
+// DeleteBotCommand removes the command. Its bot and the messages it posted
+// are kept.
+func (r *BotCommandRepos) DeleteBotCommand(id int) error {
+	defer r.db.Lock(r.db.BotCommands.ForWrite())()
+
+	store := r.db.BotCommands.Get()
+
+	for name, c := range store.Commands {
+		if c.ID == id {
+			delete(store.Commands, name)
+
+			return r.db.BotCommands.Set(store)
+		}
+	}
+
+	return entities.ErrBotCommandNotFound
+}
+
+// registerBot creates the user the bot posts as, unless the bot exists. A
+// name taken by a person can not be used for a bot. The caller holds the
+// write locks of the users and of the bots.
+func registerBot(db *inmemorydb.MemoryDB, name, password string) error {
+	bots := db.Bots.Get()
+	if bots.Table[name] {
+		return nil
+	}
+
+	if _, ok := db.Users.Get().Table[name]; ok {
+		return entities.ErrBotNameTaken
+	}
+
+	if err := db.Users.Update(model.SetUser{User: entities.User{Username: name, Password: password}}); err != nil {
+		return err
+	}
+
+	bots.Table[name] = true
+
+	return db.Bots.Set(bots)
+}
@@ -0,0 +1,85 @@
+package repos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+func TestBotCommandRepos_InsertBotCommand(t *testing.T) {
+	testTable := []struct {
+		name          string
+		command       string
+		botName       string
+		expectedError error
+	}{
+		{
+			name:    "ok",
+			command: "deploy",
+			botName: "deployer",
+		},
+		{
+			name:          "command_taken",
+			command:       "weather",
+			botName:       "deployer",
+			expectedError: entities.ErrCommandTaken,
+		},
+		{
+			name:          "name_of_user",
+			command:       "deploy",
+			botName:       "valera",
+			expectedError: entities.ErrBotNameTaken,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewBotCommandRepos(db)
+
+			db.BotCommands.Set(model.BotCommandStore{
+				Commands: map[string]entities.BotCommand{"weather": {ID: 1, Command: "weather", BotName: "meteo"}},
+				NextID:   1,
+			})
+			db.Bots.Set(model.BotTable{Table: map[string]bool{"meteo": true}})
+			db.Users.Set(model.UsersTable{Table: map[string]entities.User{
+				"valera": {Username: "valera", Password: "123"},
+				"meteo":  {Username: "meteo", Password: "old"},
+			}})
+
+			c, err := repo.InsertBotCommand(entities.BotCommand{Command: testCase.command, BotName: testCase.botName, Secret: "s"}, "pass")
+			assert.Equal(t, testCase.expectedError, err)
+
+			if testCase.expectedError != nil {
+				assert.Len(t, db.BotCommands.Get().Commands, 1)
+				return
+			}
+
+			assert.Equal(t, 2, c.ID)
+			assert.False(t, c.CreatedAt.IsZero())
+			assert.Equal(t, c, db.BotCommands.Get().Commands["deploy"])
+			assert.True(t, db.Bots.Get().Table["deployer"])
+			assert.Equal(t, entities.User{Username: "deployer", Password: "pass"}, db.Users.Get().Table["deployer"])
+		})
+	}
+}
+
+func TestBotCommandRepos_GetBotCommands(t *testing.T) {
+	db := inmemorydb.NewDB()
+	repo := NewBotCommandRepos(db)
+
+	db.BotCommands.Set(model.BotCommandStore{Commands: map[string]entities.BotCommand{
+		"weather": {ID: 1, Command: "weather"},
+		"deploy":  {ID: 2, Command: "deploy"},
+	}})
+
+	commands, err := repo.GetBotCommands()
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.BotCommand{{ID: 2, Command: "deploy"}, {ID: 1, Command: "weather"}}, commands)
+
+	_, err = repo.GetBotCommand("translate")
+	assert.Equal(t, entities.ErrBotCommandNotFound, err)
+}
@@ -0,0 +1,59 @@
+package repos
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		return backend(inmemorydb.NewDB())
+	})
+}
+
+// TestConformance_Persistent runs the suite over a database which writes
+// its log to the disk.
+func TestConformance_Persistent(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		return openBackend(t, t.TempDir())
+	})
+}
+
+func openBackend(t *testing.T, dir string) repotest.Backend {
+	t.Helper()
+
+	db, err := inmemorydb.Open(inmemorydb.PersistenceOptions{Dir: dir, Fsync: inmemorydb.FsyncNever})
+	require.NoError(t, err)
+
+	go db.Run(context.Background())
+	t.Cleanup(func() { db.Shutdown(context.Background()) })
+
+	b := backend(db)
+	b.FailWrites = db.FailWrites
+	b.Reopen = func() repotest.Backend {
+		db.Shutdown(context.Background())
+		return openBackend(t, dir)
+	}
+
+	return b
+}
+
+func backend(db *inmemorydb.MemoryDB) repotest.Backend {
+	return repotest.Backend{
+		Auth:      NewAuthRepos(db),
+		Public:    NewPublicRepos(db),
+		Private:   NewPrivateRepos(db),
+		Retention: NewRetentionRepos(db),
+		Transfer:  NewTransferRepos(db),
+		Export:    NewExportRepos(db),
+		Unit: func(fn func(b repotest.Backend) error) error {
+			return db.Unit(func(tx *inmemorydb.MemoryDB) error {
+				return fn(backend(tx))
+			})
+		},
+	}
+}
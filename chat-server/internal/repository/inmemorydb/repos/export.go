@@ -0,0 +1,60 @@
+package repos
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+// ExportRepos keeps the latest export of the data of every user.
+type ExportRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewExportRepos(db *inmemorydb.MemoryDB) *ExportRepos {
+	return &ExportRepos{db: db}
+}
+
+func (r *ExportRepos) GetDataExport(username string) (entities.DataExport, error) {
+	defer r.db.Lock(r.db.DataExports.ForRead())()
+
+	export, ok := r.db.DataExports.Get().Table[username]
+	if !ok {
+		return entities.DataExport{}, entities.ErrDataExportNotFound
+	}
+
+	return export, nil
+}
+
+// SaveDataExport replaces the export of the user.
+func (r *ExportRepos) SaveDataExport(export entities.DataExport) error {
+	defer r.db.Lock(r.db.Users.ForRead(), r.db.DataExports.ForWrite())()
+
+	if _, ok := r.db.Users.Get().Table[export.Username]; !ok {
+		return entities.ErrUserNotFound
+	}
+
+	return r.db.DataExports.Update(model.SetDataExport{Export: export})
+}
+
+// FinishDataExport records the status, size and expiry of the export, and
+// reports whether it is still the export of its user: one replaced by a
+// newer export is not recorded.
+func (r *ExportRepos) FinishDataExport(export entities.DataExport) (bool, error) {
+	defer r.db.Lock(r.db.DataExports.ForWrite())()
+
+	current, ok := r.db.DataExports.Get().Table[export.Username]
+	if !ok || current.ID != export.ID {
+		return false, nil
+	}
+
+	current.Status = export.Status
+	current.ExpiresAt = export.ExpiresAt
+	current.Size = export.Size
+
+	if err := r.db.DataExports.Update(model.SetDataExport{Export: current}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+)
+
+type IncomingWebhookRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewIncomingWebhookRepos(db *inmemorydb.MemoryDB) *IncomingWebhookRepos {
+	return &IncomingWebhookRepos{db: db}
+}
+
+// InsertIncomingWebhook saves the webhook and registers its bot as a user
+// with the given password, unless the bot exists already.
+func (r *IncomingWebhookRepos) InsertIncomingWebhook(w entities.IncomingWebhook, tokenHash, botPassword string) (entities.IncomingWebhook, error) {
+	defer r.db.Lock(r.db.Users.ForWrite(), r.db.Bots.ForWrite(), r.db.IncomingWebhooks.ForWrite())()
+
+	store := r.db.IncomingWebhooks.Get()
+
+	if err := registerBot(r.db, w.BotName, botPassword); err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	store.NextID++
+	w.ID = store.NextID
+	w.Token = ""
+	w.CreatedAt = time.Now()
+	store.Webhooks[w.ID] = w
+	store.Tokens[tokenHash] = w.ID
+
+	if err := r.db.IncomingWebhooks.Set(store); err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	return w, nil
+}
+
+func (r *IncomingWebhookRepos) GetIncomingWebhooks() ([]entities.IncomingWebhook, error) {
+	defer r.db.Lock(r.db.IncomingWebhooks.ForRead())()
+
+	store := r.db.IncomingWebhooks.Get()
+
+	webhooks := make([]entities.IncomingWebhook, 0, len(store.Webhooks))
+	for _, w := range store.Webhooks {
+		webhooks = append(webhooks, w)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+func (r *IncomingWebhookRepos) GetIncomingWebhookByToken(tokenHash string) (entities.IncomingWebhook, error) {
+	defer r.db.Lock(r.db.IncomingWebhooks.ForRead())()
+
+	store := r.db.IncomingWebhooks.Get()
+
+	w, ok := store.Webhooks[store.Tokens[tokenHash]]
+	if !ok {
+		return entities.IncomingWebhook{}, entities.ErrIncomingWebhookNotFound
+	}
+
+	return w, nil
+}
+
+// DeleteIncomingWebhook revokes the webhook. Its bot and the messages it
+// posted are kept.
+func (r *IncomingWebhookRepos) DeleteIncomingWebhook(id int) error {
+	defer r.db.Lock(r.db.IncomingWebhooks.ForWrite())()
+
+	store := r.db.IncomingWebhooks.Get()
+
+	if _, ok := store.Webhooks[id]; !ok {
+		return entities.ErrIncomingWebhookNotFound
+	}
+
+	delete(store.Webhooks, id)
+	for hash, val := range store.Tokens {
+		if val == id {
+			delete(store.Tokens, hash)
+		}
+	}
+
+	return r.db.IncomingWebhooks.Set(store)
+}
+
+func (r *IncomingWebhookRepos) TouchIncomingWebhook(id int, usedAt time.Time) error {
+	defer r.db.Lock(r.db.IncomingWebhooks.ForWrite())()
+
+	store := r.db.IncomingWebhooks.Get()
+
+	w, ok := store.Webhooks[id]
+	if !ok {
+		return entities.ErrIncomingWebhookNotFound
+	}
+
+	w.LastUsedAt = usedAt
+	store.Webhooks[id] = w
+
+	return r.db.IncomingWebhooks.Set(store)
+}
@@ -0,0 +1,83 @@
+package repos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+func TestIncomingWebhookRepos_InsertIncomingWebhook(t *testing.T) {
+	testTable := []struct {
+		name          string
+		botName       string
+		expectedUser  entities.User
+		expectedError error
+	}{
+		{
+			name:         "new_bot",
+			botName:      "ci",
+			expectedUser: entities.User{Username: "ci", Password: "pass"},
+		},
+		{
+			name:         "existing_bot",
+			botName:      "deploy",
+			expectedUser: entities.User{Username: "deploy", Password: "old"},
+		},
+		{
+			name:          "name_of_user",
+			botName:       "valera",
+			expectedError: entities.ErrBotNameTaken,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewIncomingWebhookRepos(db)
+
+			db.IncomingWebhooks.Set(model.IncomingWebhookStore{
+				Webhooks: map[int]entities.IncomingWebhook{},
+				Tokens:   map[string]int{},
+				NextID:   4,
+			})
+			db.Bots.Set(model.BotTable{Table: map[string]bool{"deploy": true}})
+			db.Users.Set(model.UsersTable{Table: map[string]entities.User{
+				"valera": {Username: "valera", Password: "123"},
+				"deploy": {Username: "deploy", Password: "old"},
+			}})
+
+			w, err := repo.InsertIncomingWebhook(entities.IncomingWebhook{Name: "hook", BotName: testCase.botName, Token: "raw"}, "hash", "pass")
+			assert.Equal(t, testCase.expectedError, err)
+
+			if testCase.expectedError != nil {
+				assert.Empty(t, db.IncomingWebhooks.Get().Webhooks)
+				return
+			}
+
+			assert.Equal(t, 5, w.ID)
+			assert.Empty(t, w.Token)
+			assert.Equal(t, 5, db.IncomingWebhooks.Get().Tokens["hash"])
+			assert.True(t, db.Bots.Get().Table[testCase.botName])
+			assert.Equal(t, testCase.expectedUser, db.Users.Get().Table[testCase.botName])
+		})
+	}
+}
+
+func TestIncomingWebhookRepos_DeleteIncomingWebhook(t *testing.T) {
+	db := inmemorydb.NewDB()
+	repo := NewIncomingWebhookRepos(db)
+
+	db.IncomingWebhooks.Set(model.IncomingWebhookStore{
+		Webhooks: map[int]entities.IncomingWebhook{1: {ID: 1, BotName: "ci"}},
+		Tokens:   map[string]int{"hash": 1},
+	})
+
+	assert.NoError(t, repo.DeleteIncomingWebhook(1))
+
+	_, err := repo.GetIncomingWebhookByToken("hash")
+	assert.Equal(t, entities.ErrIncomingWebhookNotFound, err)
+	assert.Equal(t, entities.ErrIncomingWebhookNotFound, repo.DeleteIncomingWebhook(1))
+}
@@ -0,0 +1,360 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"github.com/vavelour/chat/pkg/pagination"
+)
+
+// ModerationRepos keeps the reports, the sanctions and the audit log. Every
+// moderation action is stored with its audit entry under the same locks.
+type ModerationRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewModerationRepos(db *inmemorydb.MemoryDB) *ModerationRepos {
+	return &ModerationRepos{db: db}
+}
+
+func (r *ModerationRepos) InsertReport(report entities.Report) (entities.Report, error) {
+	defer r.db.Lock(r.db.PublicChat.ForRead(), r.db.Reports.ForWrite())()
+
+	m, ok := findMessage(r.db.PublicChat.Messages(), report.MessageID)
+	if !ok || m.Deleted {
+		return entities.Report{}, entities.ErrMessageNotFound
+	}
+
+	if m.Sender == report.Reporter {
+		return entities.Report{}, entities.ErrReportOwnMessage
+	}
+
+	reports := r.db.Reports.Get()
+
+	for _, val := range reports.Reports {
+		if val.MessageID == report.MessageID && val.Reporter == report.Reporter && val.Status == entities.ReportOpen {
+			return entities.Report{}, entities.ErrAlreadyReported
+		}
+	}
+
+	report.ID = len(reports.Reports) + 1
+	report.Status = entities.ReportOpen
+	report.CreatedAt = time.Now()
+	report.Message = entities.Message{}
+
+	reports.Reports = append(reports.Reports, report)
+	if err := r.db.Reports.Set(reports); err != nil {
+		return entities.Report{}, err
+	}
+
+	report.Message = m
+
+	return report, nil
+}
+
+// GetReports returns the reports with the status: the open ones oldest
+// first, as a queue, and the closed ones latest first.
+func (r *ModerationRepos) GetReports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error) {
+	defer r.db.Lock(r.db.PublicChat.ForRead(), r.db.Reports.ForRead())()
+
+	reports := r.db.Reports.Get()
+	messages := r.db.PublicChat.Messages()
+
+	// The reports of the messages the retention policy removed go with them.
+	filtered := make([]entities.Report, 0)
+	for _, report := range reports.Reports {
+		if _, ok := findMessage(messages, report.MessageID); ok && report.Status == status {
+			filtered = append(filtered, report)
+		}
+	}
+
+	if status != entities.ReportOpen {
+		sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].ID > filtered[j].ID })
+	}
+
+	page, err := pagination.Pagination(filtered, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]entities.Report, 0, len(page))
+	for _, report := range page {
+		report.Message, _ = findMessage(messages, report.MessageID)
+		result = append(result, report)
+	}
+
+	return result, nil
+}
+
+// CloseReport resolves or dismisses the open report.
+func (r *ModerationRepos) CloseReport(id int, status entities.ReportStatus, entry entities.AuditEntry) (entities.Report, error) {
+	defer r.db.Lock(r.db.PublicChat.ForRead(), r.db.Reports.ForWrite(), r.db.ModerationLog.ForWrite())()
+
+	reports := r.db.Reports.Get()
+
+	if id < 1 || id > len(reports.Reports) || reports.Reports[id-1].Status != entities.ReportOpen {
+		return entities.Report{}, entities.ErrReportNotFound
+	}
+
+	m, ok := findMessage(r.db.PublicChat.Messages(), reports.Reports[id-1].MessageID)
+	if !ok {
+		return entities.Report{}, entities.ErrReportNotFound
+	}
+
+	now := time.Now()
+
+	report := reports.Reports[id-1]
+	report.Status = status
+	report.ResolvedBy = entry.Moderator
+	report.ResolvedAt = now
+	reports.Reports[id-1] = report
+
+	entry.Target = m.Sender
+	entry.MessageID = report.MessageID
+	entry.ReportID = report.ID
+
+	if err := r.audit(entry, now); err != nil {
+		return entities.Report{}, err
+	}
+
+	if err := r.db.Reports.Set(reports); err != nil {
+		return entities.Report{}, err
+	}
+
+	report.Message = m
+
+	return report, nil
+}
+
+// DeletePublicMessage removes the content and the attachments of the public
+// message, resolves its open reports and notifies the webhooks of the
+// deletion. The message stays listed as deleted, so the pages of the chat
+// do not shift. It returns the IDs of the attachments removed, whose files
+// are left to the caller.
+func (r *ModerationRepos) DeletePublicMessage(id int, entry entities.AuditEntry) ([]string, error) {
+	// The mentions of a message never change, so the notifications to lock
+	// are known before the lock.
+	m, ok := findMessage(r.db.PublicChat.Messages(), id)
+	if !ok {
+		return nil, entities.ErrMessageNotFound
+	}
+
+	locks := append(notificationLocks(r.db, m.Mentions...),
+		r.db.PublicChat.ForWrite(),
+		r.db.PublicAttachments.ForWrite(),
+		r.db.Reports.ForWrite(),
+		r.db.ModerationLog.ForWrite(),
+		r.db.Webhooks.ForWrite())
+	defer r.db.Lock(locks...)()
+
+	m, _ = findMessage(r.db.PublicChat.Messages(), id)
+	if m.Deleted {
+		return nil, entities.ErrMessageNotFound
+	}
+
+	reports := r.db.Reports.Get()
+	now := time.Now()
+
+	entry.Target = m.Sender
+	entry.MessageID = id
+
+	if err := r.audit(entry, now); err != nil {
+		return nil, err
+	}
+
+	if err := forgetMentions(r.db, m); err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0, len(m.Attachments))
+	for _, attachment := range m.Attachments {
+		removed = append(removed, attachment.ID)
+	}
+
+	for j, report := range reports.Reports {
+		if report.MessageID == id && report.Status == entities.ReportOpen {
+			report.Status = entities.ReportResolved
+			report.ResolvedBy = entry.Moderator
+			report.ResolvedAt = now
+			reports.Reports[j] = report
+		}
+	}
+
+	if _, err := r.db.PublicChat.Replace(entities.Message{ID: m.ID, Sender: m.Sender, CreatedAt: m.CreatedAt, Deleted: true}); err != nil {
+		return nil, err
+	}
+
+	if len(removed) > 0 {
+		if err := r.db.PublicAttachments.Update(model.RemoveAttachments{IDs: removed}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.db.Reports.Set(reports); err != nil {
+		return nil, err
+	}
+
+	err := enqueueWebhookEvent(r.db, entities.EventMessageDeleted, entities.PublicChannel,
+		entities.Message{ID: m.ID, Sender: m.Sender, CreatedAt: now, Deleted: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+// SetSanction puts the sanction on the user, replacing the one of the same
+// kind.
+func (r *ModerationRepos) SetSanction(s entities.Sanction, entry entities.AuditEntry) error {
+	defer r.db.Lock(r.db.Users.ForRead(), r.db.Sanctions.ForWrite(), r.db.ModerationLog.ForWrite())()
+
+	if _, ok := r.db.Users.Get().Table[s.Username]; !ok {
+		return entities.ErrUserNotFound
+	}
+
+	sanctions := r.db.Sanctions.Get()
+
+	now := time.Now()
+	s.CreatedAt = now
+
+	entry.Target = s.Username
+	entry.Until = s.Until
+
+	if err := r.audit(entry, now); err != nil {
+		return err
+	}
+
+	if sanctions.Table[s.Username] == nil {
+		sanctions.Table[s.Username] = make(map[entities.SanctionKind]entities.Sanction)
+	}
+
+	sanctions.Table[s.Username][s.Kind] = s
+
+	return r.db.Sanctions.Set(sanctions)
+}
+
+func (r *ModerationRepos) LiftSanction(username string, kind entities.SanctionKind, entry entities.AuditEntry) error {
+	defer r.db.Lock(r.db.Sanctions.ForWrite(), r.db.ModerationLog.ForWrite())()
+
+	sanctions := r.db.Sanctions.Get()
+
+	now := time.Now()
+
+	s, ok := sanctions.Table[username][kind]
+	if !ok || !s.ActiveAt(now) {
+		return entities.ErrSanctionNotFound
+	}
+
+	entry.Target = username
+
+	if err := r.audit(entry, now); err != nil {
+		return err
+	}
+
+	delete(sanctions.Table[username], kind)
+
+	return r.db.Sanctions.Set(sanctions)
+}
+
+// GetActiveSanctions returns the sanctions of the user in force at now.
+func (r *ModerationRepos) GetActiveSanctions(username string, now time.Time) ([]entities.Sanction, error) {
+	defer r.db.Lock(r.db.Sanctions.ForRead())()
+
+	sanctions := r.db.Sanctions.Get()
+
+	active := make([]entities.Sanction, 0, len(sanctions.Table[username]))
+	for _, s := range sanctions.Table[username] {
+		if s.ActiveAt(now) {
+			active = append(active, s)
+		}
+	}
+
+	sortSanctions(active)
+
+	return active, nil
+}
+
+// GetSanctions returns the sanctions of all the users in force at now, the
+// latest first.
+func (r *ModerationRepos) GetSanctions(now time.Time) ([]entities.Sanction, error) {
+	defer r.db.Lock(r.db.Sanctions.ForRead())()
+
+	sanctions := r.db.Sanctions.Get()
+
+	active := make([]entities.Sanction, 0)
+	for _, byKind := range sanctions.Table {
+		for _, s := range byKind {
+			if s.ActiveAt(now) {
+				active = append(active, s)
+			}
+		}
+	}
+
+	sortSanctions(active)
+
+	return active, nil
+}
+
+// GetAuditLog returns the moderation actions, the latest first.
+func (r *ModerationRepos) GetAuditLog(limit, offset int) ([]entities.AuditEntry, error) {
+	defer r.db.Lock(r.db.ModerationLog.ForRead())()
+
+	log := r.db.ModerationLog.Get()
+
+	entries := make([]entities.AuditEntry, len(log.Entries))
+	for i, entry := range log.Entries {
+		entries[len(entries)-1-i] = entry
+	}
+
+	return pagination.Pagination(entries, limit, offset)
+}
+
+// audit adds the entry to the audit log. The caller holds the write lock of
+// the log.
+func (r *ModerationRepos) audit(entry entities.AuditEntry, now time.Time) error {
+	entry.ID = len(r.db.ModerationLog.Get().Entries) + 1
+	entry.CreatedAt = now
+
+	return r.db.ModerationLog.Update(model.AddAuditEntry{Entry: entry})
+}
+
+// forgetMentions removes the content of the deleted message from the
+// notifications of the users it mentioned. The caller holds the locks of
+// notificationLocks for them.
+func forgetMentions(db *inmemorydb.MemoryDB, m entities.Message) error {
+	for _, user := range m.Mentions {
+		shard := db.Notifications.Shard(user)
+
+		list, ok := shard.Get()
+		if !ok {
+			continue
+		}
+
+		for i, n := range list {
+			if n.Kind == entities.NotificationMention && n.MessageID == m.ID {
+				list[i].Content = ""
+			}
+		}
+
+		if err := shard.Set(list); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortSanctions(sanctions []entities.Sanction) {
+	sort.Slice(sanctions, func(i, j int) bool {
+		if !sanctions[i].CreatedAt.Equal(sanctions[j].CreatedAt) {
+			return sanctions[i].CreatedAt.After(sanctions[j].CreatedAt)
+		}
+		if sanctions[i].Username != sanctions[j].Username {
+			return sanctions[i].Username < sanctions[j].Username
+		}
+		return sanctions[i].Kind < sanctions[j].Kind
+	})
+}
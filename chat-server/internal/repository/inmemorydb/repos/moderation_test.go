@@ -0,0 +1,154 @@
+package repos
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"testing"
+	"time"
+)
+
+func newModerationDB() *inmemorydb.MemoryDB {
+	db := inmemorydb.NewDB()
+
+	db.Users.Set(model.UsersTable{Table: map[string]entities.User{
+		"tester": {Username: "tester"},
+		"valera": {Username: "valera"},
+	}})
+	db.PublicChat.Append(entities.Message{ID: 1, Sender: "valera", Content: "spam @tester", Mentions: []string{"tester"},
+		Attachments: []entities.Attachment{{ID: "a1"}}})
+	db.PublicChat.Append(entities.Message{ID: 2, Sender: "tester", Content: "hi"})
+	db.PublicAttachments.Set(model.AttachmentTable{Table: map[string]model.AttachmentModel{"a1": {}}})
+	db.Notifications.Shard("tester").Set([]entities.Notification{
+		{Kind: entities.NotificationMention, MessageID: 1, Content: "spam @tester"},
+	})
+
+	return db
+}
+
+func TestModerationRepos_Reports(t *testing.T) {
+	repo := NewModerationRepos(newModerationDB())
+
+	_, err := repo.InsertReport(entities.Report{MessageID: 3, Reporter: "tester", Reason: "spam"})
+	assert.Equal(t, entities.ErrMessageNotFound, err)
+
+	_, err = repo.InsertReport(entities.Report{MessageID: 2, Reporter: "tester", Reason: "spam"})
+	assert.Equal(t, entities.ErrReportOwnMessage, err)
+
+	report, err := repo.InsertReport(entities.Report{MessageID: 1, Reporter: "tester", Reason: "spam"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.ID)
+	assert.Equal(t, entities.ReportOpen, report.Status)
+	assert.Equal(t, "valera", report.Message.Sender)
+
+	_, err = repo.InsertReport(entities.Report{MessageID: 1, Reporter: "tester", Reason: "spam"})
+	assert.Equal(t, entities.ErrAlreadyReported, err)
+
+	queue, err := repo.GetReports(entities.ReportOpen, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.Equal(t, "spam @tester", queue[0].Message.Content)
+
+	report, err = repo.CloseReport(1, entities.ReportDismissed, entities.AuditEntry{Moderator: "admin", Action: entities.ActionDismissReport})
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReportDismissed, report.Status)
+	assert.Equal(t, "admin", report.ResolvedBy)
+
+	_, err = repo.CloseReport(1, entities.ReportResolved, entities.AuditEntry{Moderator: "admin", Action: entities.ActionResolveReport})
+	assert.Equal(t, entities.ErrReportNotFound, err)
+
+	_, err = repo.InsertReport(entities.Report{MessageID: 1, Reporter: "tester", Reason: "still spam"})
+	require.NoError(t, err)
+
+	log, err := repo.GetAuditLog(10, 0)
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Equal(t, entities.AuditEntry{ID: 1, Moderator: "admin", Action: entities.ActionDismissReport,
+		Target: "valera", MessageID: 1, ReportID: 1, CreatedAt: log[0].CreatedAt}, log[0])
+}
+
+func TestModerationRepos_DeletePublicMessage(t *testing.T) {
+	db := newModerationDB()
+	repo := NewModerationRepos(db)
+
+	_, err := repo.InsertReport(entities.Report{MessageID: 1, Reporter: "tester", Reason: "spam"})
+	require.NoError(t, err)
+
+	db.Webhooks.Set(model.WebhookStore{Webhooks: map[int]entities.Webhook{
+		1: {ID: 1, Events: []entities.WebhookEventType{entities.EventMessageDeleted}, Active: true},
+		2: {ID: 2, Events: []entities.WebhookEventType{entities.EventMessageCreated}, Active: true},
+	}})
+
+	entry := entities.AuditEntry{Moderator: "admin", Action: entities.ActionDeleteMessage, Reason: "spam"}
+	removed, err := repo.DeletePublicMessage(1, entry)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1"}, removed)
+
+	_, err = repo.DeletePublicMessage(1, entry)
+	assert.Equal(t, entities.ErrMessageNotFound, err)
+
+	// The subscribers of the deletion learn of it, without the content.
+	deliveries := db.Webhooks.Get().Deliveries
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 1, deliveries[0].Webhook.ID)
+	assert.Equal(t, entities.EventMessageDeleted, deliveries[0].Event.Type)
+	assert.Equal(t, 1, deliveries[0].Event.Message.ID)
+	assert.Empty(t, deliveries[0].Event.Message.Content)
+
+	messages := db.PublicChat.Messages()
+	assert.Equal(t, entities.Message{ID: 1, Sender: "valera", CreatedAt: messages[0].CreatedAt, Deleted: true}, messages[0])
+	assert.Empty(t, db.PublicAttachments.Get().Table)
+
+	notifications, _ := db.Notifications.Shard("tester").Get()
+	assert.Empty(t, notifications[0].Content)
+
+	reports, err := repo.GetReports(entities.ReportResolved, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "admin", reports[0].ResolvedBy)
+	assert.True(t, reports[0].Message.Deleted)
+
+	_, err = repo.InsertReport(entities.Report{MessageID: 1, Reporter: "tester", Reason: "spam"})
+	assert.Equal(t, entities.ErrMessageNotFound, err)
+}
+
+func TestModerationRepos_Sanctions(t *testing.T) {
+	repo := NewModerationRepos(newModerationDB())
+	now := time.Now()
+
+	mute := entities.Sanction{Username: "valera", Kind: entities.SanctionMute, Until: now.Add(time.Hour), CreatedBy: "admin"}
+	assert.Equal(t, entities.ErrUserNotFound, repo.SetSanction(entities.Sanction{Username: "petya", Kind: entities.SanctionBan},
+		entities.AuditEntry{Moderator: "admin", Action: entities.ActionBan}))
+	require.NoError(t, repo.SetSanction(mute, entities.AuditEntry{Moderator: "admin", Action: entities.ActionMute}))
+
+	active, err := repo.GetActiveSanctions("valera", now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, entities.SanctionMute, active[0].Kind)
+
+	active, err = repo.GetActiveSanctions("valera", now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	all, err := repo.GetSanctions(now)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	assert.Equal(t, entities.ErrSanctionNotFound, repo.LiftSanction("valera", entities.SanctionBan,
+		entities.AuditEntry{Moderator: "admin", Action: entities.ActionUnban}))
+	require.NoError(t, repo.LiftSanction("valera", entities.SanctionMute,
+		entities.AuditEntry{Moderator: "admin", Action: entities.ActionUnmute}))
+
+	active, err = repo.GetActiveSanctions("valera", now)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	log, err := repo.GetAuditLog(10, 0)
+	require.NoError(t, err)
+	require.Len(t, log, 2)
+	assert.Equal(t, entities.ActionUnmute, log[0].Action)
+	assert.Equal(t, entities.ActionMute, log[1].Action)
+	assert.Equal(t, mute.Until, log[1].Until)
+}
@@ -0,0 +1,133 @@
+package repos
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+type NotificationRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewNotificationRepos(db *inmemorydb.MemoryDB) *NotificationRepos {
+	return &NotificationRepos{db: db}
+}
+
+// GetNotifications returns the notifications of the user, newest first.
+func (n *NotificationRepos) GetNotifications(user string, unreadOnly bool, limit, offset int) ([]entities.Notification, error) {
+	res := make([]entities.Notification, 0, limit)
+
+	shard, ok := n.db.Notifications.Lookup(user)
+	if !ok {
+		return res, nil
+	}
+
+	defer n.db.Lock(shard.ForRead())()
+
+	list, _ := shard.Get()
+
+	for i := len(list) - 1; i >= 0 && len(res) < limit; i-- {
+		if unreadOnly && list[i].Read {
+			continue
+		}
+
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		res = append(res, list[i])
+	}
+
+	return res, nil
+}
+
+func (n *NotificationRepos) CountUnread(user string) (int, error) {
+	shard, ok := n.db.Notifications.Lookup(user)
+	if !ok {
+		return 0, nil
+	}
+
+	defer n.db.Lock(shard.ForRead())()
+
+	list, _ := shard.Get()
+
+	var count int
+	for _, val := range list {
+		if !val.Read {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// MarkNotificationsRead marks the given notifications of the user as read,
+// or all of them when ids is empty, and returns how many were unread.
+func (n *NotificationRepos) MarkNotificationsRead(user string, ids []int) (int, error) {
+	shard, ok := n.db.Notifications.Lookup(user)
+	if !ok {
+		return 0, nil
+	}
+
+	defer n.db.Lock(shard.ForWrite())()
+
+	selected := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	var count int
+	list, _ := shard.Get()
+	for i := range list {
+		if list[i].Read || (len(ids) > 0 && !selected[list[i].ID]) {
+			continue
+		}
+
+		list[i].Read = true
+		count++
+	}
+
+	if count > 0 {
+		if err := shard.Set(list); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// notificationLocks returns the write locks of the notifications of the
+// users, which notify needs.
+func notificationLocks(db *inmemorydb.MemoryDB, users ...string) []inmemorydb.Access {
+	locks := make([]inmemorydb.Access, 0, len(users))
+	for _, user := range users {
+		locks = append(locks, db.Notifications.Shard(user).ForWrite())
+	}
+
+	return locks
+}
+
+// notify adds the notifications of a new message. Every user gets their own
+// sequence of IDs. The caller holds the locks of notificationLocks.
+func notify(db *inmemorydb.MemoryDB, m entities.Message, kind entities.NotificationKind, users ...string) error {
+	for _, user := range users {
+		shard := db.Notifications.Shard(user)
+
+		list, _ := shard.Get()
+		err := shard.Update(model.AppendNotification{Notification: entities.Notification{
+			ID:        len(list) + 1,
+			Kind:      kind,
+			Sender:    m.Sender,
+			MessageID: m.ID,
+			Content:   m.Content,
+			CreatedAt: m.CreatedAt,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package repos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+)
+
+func seedNotifications(db *inmemorydb.MemoryDB) {
+	db.Notifications.Shard("tester").Set([]entities.Notification{
+		{ID: 1, Kind: entities.NotificationMention, Sender: "valera", Read: true},
+		{ID: 2, Kind: entities.NotificationPrivateMessage, Sender: "valera"},
+		{ID: 3, Kind: entities.NotificationMention, Sender: "vika"},
+	})
+}
+
+func TestNotificationRepos_GetNotifications(t *testing.T) {
+	testTable := []struct {
+		name          string
+		user          string
+		unreadOnly    bool
+		limit         int
+		offset        int
+		expectedIDs   []int
+		expectedError error
+	}{
+		{
+			name:        "ok",
+			user:        "tester",
+			limit:       10,
+			expectedIDs: []int{3, 2, 1},
+		},
+		{
+			name:        "unread_only",
+			user:        "tester",
+			unreadOnly:  true,
+			limit:       10,
+			expectedIDs: []int{3, 2},
+		},
+		{
+			name:        "limit_offset",
+			user:        "tester",
+			limit:       1,
+			offset:      1,
+			expectedIDs: []int{2},
+		},
+		{
+			name:        "no_notifications",
+			user:        "valera",
+			limit:       10,
+			expectedIDs: []int{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewNotificationRepos(db)
+
+			seedNotifications(db)
+
+			notifications, err := repo.GetNotifications(testCase.user, testCase.unreadOnly, testCase.limit, testCase.offset)
+			assert.Equal(t, testCase.expectedError, err)
+
+			ids := make([]int, 0)
+			for _, val := range notifications {
+				ids = append(ids, val.ID)
+			}
+			assert.Equal(t, testCase.expectedIDs, ids)
+		})
+	}
+}
+
+func TestNotificationRepos_CountUnread(t *testing.T) {
+	db := inmemorydb.NewDB()
+	repo := NewNotificationRepos(db)
+
+	seedNotifications(db)
+
+	count, err := repo.CountUnread("tester")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = repo.CountUnread("valera")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestNotificationRepos_MarkNotificationsRead(t *testing.T) {
+	testTable := []struct {
+		name            string
+		ids             []int
+		expectedUpdated int
+		expectedUnread  []int
+	}{
+		{
+			name:            "selected",
+			ids:             []int{1, 3, 42},
+			expectedUpdated: 1,
+			expectedUnread:  []int{2},
+		},
+		{
+			name:            "all",
+			expectedUpdated: 2,
+			expectedUnread:  []int{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewNotificationRepos(db)
+
+			seedNotifications(db)
+
+			updated, err := repo.MarkNotificationsRead("tester", testCase.ids)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedUpdated, updated)
+
+			notifications, _ := db.Notifications.Shard("tester").Get()
+
+			unread := make([]int, 0)
+			for _, val := range notifications {
+				if !val.Read {
+					unread = append(unread, val.ID)
+				}
+			}
+			assert.Equal(t, testCase.expectedUnread, unread)
+		})
+	}
+}
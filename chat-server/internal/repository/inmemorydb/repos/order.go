@@ -0,0 +1,29 @@
+package repos
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/pagination"
+)
+
+// pageMessages returns the page of the messages, which are kept in the order
+// of their IDs, in the given order. The offsets of the newest first count
+// from the latest message.
+func pageMessages(messages []entities.Message, limit, offset int, order entities.Order) ([]entities.Message, error) {
+	if order != entities.OrderNewestFirst {
+		return pagination.Pagination(messages, limit, offset)
+	}
+
+	if offset >= len(messages) {
+		return nil, pagination.ErrOffsetRange
+	}
+
+	end := len(messages) - offset
+	start := max(end-limit, 0)
+
+	page := make([]entities.Message, 0, end-start)
+	for i := end - 1; i >= start; i-- {
+		page = append(page, messages[i])
+	}
+
+	return page, nil
+}
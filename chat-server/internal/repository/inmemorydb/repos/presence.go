@@ -0,0 +1,43 @@
+package repos
+
+import (
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"time"
+)
+
+type PresenceRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewPresenceRepos(db *inmemorydb.MemoryDB) *PresenceRepos {
+	return &PresenceRepos{db: db}
+}
+
+func (p *PresenceRepos) UpdateLastSeen(username string, lastSeen time.Time) error {
+	defer p.db.Lock(p.db.LastSeen.ForWrite())()
+
+	lastSeenTable := p.db.LastSeen.Get()
+
+	if lastSeen.Before(lastSeenTable.Table[username]) {
+		return nil
+	}
+
+	lastSeenTable.Table[username] = lastSeen
+
+	return p.db.LastSeen.Set(lastSeenTable)
+}
+
+func (p *PresenceRepos) GetLastSeen(usernames []string) (map[string]time.Time, error) {
+	defer p.db.Lock(p.db.LastSeen.ForRead())()
+
+	lastSeenTable := p.db.LastSeen.Get()
+
+	lastSeen := make(map[string]time.Time, len(usernames))
+	for _, username := range usernames {
+		if t, ok := lastSeenTable.Table[username]; ok {
+			lastSeen[username] = t
+		}
+	}
+
+	return lastSeen, nil
+}
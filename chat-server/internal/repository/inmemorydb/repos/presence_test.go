@@ -0,0 +1,63 @@
+package repos
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"testing"
+	"time"
+)
+
+func TestPresenceRepos_UpdateLastSeen(t *testing.T) {
+	earlier := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	testTable := []struct {
+		name             string
+		username         string
+		stored           time.Time
+		lastSeen         time.Time
+		expectedLastSeen time.Time
+	}{
+		{
+			name:             "ok",
+			username:         "tester",
+			stored:           earlier,
+			lastSeen:         later,
+			expectedLastSeen: later,
+		},
+		{
+			name:             "older_value_ignored",
+			username:         "tester",
+			stored:           later,
+			lastSeen:         earlier,
+			expectedLastSeen: later,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewPresenceRepos(db)
+
+			db.LastSeen.Set(model.LastSeenTable{Table: map[string]time.Time{testCase.username: testCase.stored}})
+
+			err := repo.UpdateLastSeen(testCase.username, testCase.lastSeen)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedLastSeen, db.LastSeen.Get().Table[testCase.username])
+		})
+	}
+}
+
+func TestPresenceRepos_GetLastSeen(t *testing.T) {
+	lastSeen := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	db := inmemorydb.NewDB()
+	repo := NewPresenceRepos(db)
+
+	db.LastSeen.Set(model.LastSeenTable{Table: map[string]time.Time{"tester": lastSeen, "other": lastSeen}})
+
+	got, err := repo.GetLastSeen([]string{"tester", "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"tester": lastSeen}, got)
+}
@@ -0,0 +1,107 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+type PrivacyRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewPrivacyRepos(db *inmemorydb.MemoryDB) *PrivacyRepos {
+	return &PrivacyRepos{db: db}
+}
+
+func (p *PrivacyRepos) GetPrivacySettings(user string) (entities.PrivacySettings, error) {
+	defer p.db.Lock(p.db.Privacy.ForRead())()
+
+	settings, ok := p.db.Privacy.Get().Table[user]
+	if !ok {
+		return entities.DefaultPrivacySettings(), nil
+	}
+
+	return settings, nil
+}
+
+func (p *PrivacyRepos) UpdatePrivacySettings(user string, settings entities.PrivacySettings) error {
+	defer p.db.Lock(p.db.Privacy.ForWrite())()
+
+	return p.db.Privacy.Update(model.SetPrivacySettings{User: user, Settings: settings})
+}
+
+func (p *PrivacyRepos) BlockUser(user, blocked string) error {
+	defer p.db.Lock(p.db.Users.ForRead(), p.db.Blocks.ForWrite())()
+
+	if _, ok := p.db.Users.Get().Table[blocked]; !ok {
+		return entities.ErrUserNotFound
+	}
+
+	blocks := p.db.Blocks.Get()
+
+	if _, ok := blocks.Table[user][blocked]; ok {
+		return nil
+	}
+
+	if blocks.Table[user] == nil {
+		blocks.Table[user] = make(map[string]time.Time)
+	}
+
+	blocks.Table[user][blocked] = time.Now()
+
+	return p.db.Blocks.Set(blocks)
+}
+
+func (p *PrivacyRepos) UnblockUser(user, blocked string) error {
+	defer p.db.Lock(p.db.Blocks.ForWrite())()
+
+	blocks := p.db.Blocks.Get()
+	if _, ok := blocks.Table[user][blocked]; !ok {
+		return nil
+	}
+
+	delete(blocks.Table[user], blocked)
+
+	return p.db.Blocks.Set(blocks)
+}
+
+// GetBlockedUsers returns the users blocked by user, the latest first.
+func (p *PrivacyRepos) GetBlockedUsers(user string) ([]entities.BlockedUser, error) {
+	defer p.db.Lock(p.db.Blocks.ForRead())()
+
+	blocks := p.db.Blocks.Get()
+
+	blocked := make([]entities.BlockedUser, 0, len(blocks.Table[user]))
+	for username, createdAt := range blocks.Table[user] {
+		blocked = append(blocked, entities.BlockedUser{Username: username, CreatedAt: createdAt})
+	}
+
+	sort.Slice(blocked, func(i, j int) bool {
+		if !blocked[i].CreatedAt.Equal(blocked[j].CreatedAt) {
+			return blocked[i].CreatedAt.After(blocked[j].CreatedAt)
+		}
+		return blocked[i].Username < blocked[j].Username
+	})
+
+	return blocked, nil
+}
+
+// IsBlocked tells whether user has blocked other.
+func (p *PrivacyRepos) IsBlocked(user, other string) (bool, error) {
+	defer p.db.Lock(p.db.Blocks.ForRead())()
+
+	_, ok := p.db.Blocks.Get().Table[user][other]
+
+	return ok, nil
+}
+
+// IsContact tells whether user has talked to other.
+func (p *PrivacyRepos) IsContact(user, other string) (bool, error) {
+	defer p.db.Lock(p.db.Contacts.ForRead())()
+
+	return p.db.Contacts.Get().Table[user][other], nil
+}
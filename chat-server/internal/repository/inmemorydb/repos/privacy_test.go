@@ -0,0 +1,57 @@
+package repos
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"testing"
+)
+
+func TestPrivacyRepos_Settings(t *testing.T) {
+	repo := NewPrivacyRepos(inmemorydb.NewDB())
+
+	settings, err := repo.GetPrivacySettings("tester")
+	require.NoError(t, err)
+	assert.Equal(t, entities.DefaultPrivacySettings(), settings)
+
+	require.NoError(t, repo.UpdatePrivacySettings("tester", entities.PrivacySettings{DMPolicy: entities.DMPolicyNobody}))
+
+	settings, err = repo.GetPrivacySettings("tester")
+	require.NoError(t, err)
+	assert.Equal(t, entities.PrivacySettings{DMPolicy: entities.DMPolicyNobody}, settings)
+}
+
+func TestPrivacyRepos_Blocks(t *testing.T) {
+	db := inmemorydb.NewDB()
+	db.Users.Set(model.UsersTable{Table: map[string]entities.User{
+		"tester": {Username: "tester"},
+		"valera": {Username: "valera"},
+	}})
+
+	repo := NewPrivacyRepos(db)
+
+	assert.Equal(t, entities.ErrUserNotFound, repo.BlockUser("tester", "petya"))
+	require.NoError(t, repo.BlockUser("tester", "valera"))
+	require.NoError(t, repo.BlockUser("tester", "valera"))
+
+	blocked, err := repo.IsBlocked("tester", "valera")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+
+	blocked, err = repo.IsBlocked("valera", "tester")
+	require.NoError(t, err)
+	assert.False(t, blocked)
+
+	users, err := repo.GetBlockedUsers("tester")
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "valera", users[0].Username)
+
+	require.NoError(t, repo.UnblockUser("tester", "valera"))
+
+	blocked, err = repo.IsBlocked("tester", "valera")
+	require.NoError(t, err)
+	assert.False(t, blocked)
+}
@@ -2,11 +2,11 @@ package repos
 
 import (
 	"errors"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
 	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
-	"github.com/vavelour/chat/internal/repository/inmemorydb/model/constant"
 	"github.com/vavelour/chat/internal/service/mapper"
 	"sort"
-	"sync"
+	"time"
 
 	"github.com/vavelour/chat/pkg/pagination"
 
@@ -19,108 +19,611 @@ var (
 	ErrNonUsers        = errors.New("no users who have written to you")
 )
 
-type PrivateDatabase interface {
-	Insert(key string, data interface{})
-	Get(key string) interface{}
-}
-
+// PrivateRepos keeps every conversation in a shard of its own, so the
+// readers of a conversation wait only for the writers of the same one. The
+// writers of all the conversations still take turns: a message updates the
+// inboxes, the contacts and the indexes, which are shared by every
+// conversation.
 type PrivateRepos struct {
-	mu sync.RWMutex
-	db PrivateDatabase
+	db *inmemorydb.MemoryDB
 }
 
-func NewPrivateRepos(db PrivateDatabase) *PrivateRepos {
+func NewPrivateRepos(db *inmemorydb.MemoryDB) *PrivateRepos {
 	return &PrivateRepos{db: db}
 }
 
 func (p *PrivateRepos) InsertMessage(m entities.Message) error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
 	members := mapper.MessageToMembersPrivateChat(m)
 
-	data := p.db.Get(constant.UsersKey)
+	// The shards of the conversation and of the notifications are added
+	// once the recipient is known to exist: the shards are never removed, so
+	// messages to made-up users would pile them up.
+	if !p.userExists(m.Recipient) {
+		return ErrUserIsNotExists
+	}
 
-	users, ok := data.(model.UsersTable)
-	if !ok {
-		return errIncorrectType
+	shard := p.db.PrivateChats.Shard(members)
+
+	locks := []inmemorydb.Access{
+		p.db.Users.ForRead(),
+		shard.ForWrite(),
+		p.db.ConversationIndex.ForWrite(),
+		p.db.Contacts.ForWrite(),
+		p.db.MessageRequests.ForWrite(),
+		p.db.ExpiringMessages.ForWrite(),
+		p.db.PrivateAttachments.ForWrite(),
+		p.db.PrivateSearchIndex.ForWrite(),
+	}
+	if m.Recipient != m.Sender {
+		locks = append(locks, notificationLocks(p.db, m.Recipient)...)
 	}
+	defer p.db.Lock(locks...)()
 
-	_, ok = users.Table[m.Recipient]
-	if !ok {
+	if _, ok := p.db.Users.Get().Table[m.Recipient]; !ok {
 		return ErrUserIsNotExists
 	}
 
-	data = p.db.Get(constant.PrivateChatKey)
+	chat, _ := shard.Get()
+	m.ID = nextMessageID(chat)
+	m.CreatedAt = time.Now()
 
-	privateChats, ok := data.(model.PrivateChatTable)
-	if !ok {
-		return errIncorrectType
+	if m.TTL > 0 && !m.ExpireAfterRead {
+		m.ExpiresAt = m.CreatedAt.Add(m.TTL)
+	}
+
+	if len(m.Attachments) > 0 {
+		if err := p.db.PrivateAttachments.Update(indexAttachments(&m)); err != nil {
+			return err
+		}
+	}
+
+	if m.Recipient != m.Sender {
+		// The content of a self-destructing message is not copied to the
+		// notification, where it would outlive the message.
+		notified := m
+		if m.TTL > 0 {
+			notified.Content = ""
+		}
+
+		if err := notify(p.db, notified, entities.NotificationPrivateMessage, m.Recipient); err != nil {
+			return err
+		}
 	}
 
-	chat := privateChats.Table[members]
-	chat.Messages = append(chat.Messages, m)
-	privateChats.Table[members] = chat
+	return storePrivateMessage(p.db, shard, members, m)
+}
 
-	p.db.Insert(constant.PrivateChatKey, privateChats)
+func (p *PrivateRepos) userExists(username string) bool {
+	defer p.db.Lock(p.db.Users.ForRead())()
 
-	return nil
+	_, ok := p.db.Users.Get().Table[username]
+
+	return ok
 }
 
-func (p *PrivateRepos) GetMessages(sender, recipient string, limit, offset int) ([]entities.Message, error) {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+// storePrivateMessage appends the message to the conversation and brings
+// the expiring messages, the search index and the inboxes of both members
+// up to date. The caller holds the write locks of all of them.
+func storePrivateMessage(db *inmemorydb.MemoryDB, shard *inmemorydb.Shard[model.MembersPrivateChatModel, model.PrivateChat],
+	members model.MembersPrivateChatModel, m entities.Message) error {
+	if !m.ExpiresAt.IsZero() && !m.Expired {
+		if err := scheduleExpiry(db, members, m); err != nil {
+			return err
+		}
+	}
+
+	if err := db.PrivateSearchIndex.Update(indexMessage(members, m)); err != nil {
+		return err
+	}
 
-	members := mapper.StringToMembersPrivateChat(sender, recipient)
+	if err := shard.Update(model.AppendMessage{Message: m}); err != nil {
+		return err
+	}
 
-	data := p.db.Get(constant.PrivateChatKey)
+	index := db.ConversationIndex.Get()
+	requests := db.MessageRequests.Get()
 
-	privateChats, ok := data.(model.PrivateChatTable)
+	err := db.ConversationIndex.Update(model.SetConversations{User: m.Sender, Partners: moveToFront(index.Table[m.Sender], m.Recipient)})
+	if err != nil {
+		return err
+	}
+
+	if err := db.Contacts.Update(model.AddContact{User: m.Sender, Partner: m.Recipient}); err != nil {
+		return err
+	}
+
+	// Replying to a message request accepts it.
+	if containsPartner(requests.Table[m.Sender], m.Recipient) {
+		err := db.MessageRequests.Update(model.SetMessageRequests{User: m.Sender, Partners: removePartner(requests.Table[m.Sender], m.Recipient)})
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.Recipient == m.Sender {
+		return nil
+	}
+
+	// The messages of a stranger wait in the message requests until the
+	// recipient accepts them or replies.
+	if db.Contacts.Get().Table[m.Recipient][m.Sender] {
+		return db.ConversationIndex.Update(model.SetConversations{User: m.Recipient, Partners: moveToFront(index.Table[m.Recipient], m.Sender)})
+	}
+
+	return db.MessageRequests.Update(model.SetMessageRequests{User: m.Recipient, Partners: moveToFront(requests.Table[m.Recipient], m.Sender)})
+}
+
+func (p *PrivateRepos) GetMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error) {
+	shard, ok := p.db.PrivateChats.Lookup(mapper.StringToMembersPrivateChat(sender, recipient))
 	if !ok {
-		return nil, errIncorrectType
+		return nil, ErrChatIsNotExists
 	}
 
-	messages, ok := privateChats.Table[members]
+	defer p.db.Lock(shard.ForRead())()
+
+	chat, ok := shard.Get()
 	if !ok {
 		return nil, ErrChatIsNotExists
 	}
 
-	paginationMessages, err := pagination.Pagination(messages.Messages, limit, offset)
+	paginationMessages, err := pageMessages(chat.Messages, limit, offset, order)
 	if err != nil {
 		return nil, err
 	}
 
-	return paginationMessages, nil
+	now := time.Now()
+	visible := make([]entities.Message, 0, len(paginationMessages))
+	for _, m := range paginationMessages {
+		visible = append(visible, visibleMessage(m, now))
+	}
+
+	return visible, nil
 }
 
 func (p *PrivateRepos) GetUsers(user string) ([]string, error) {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+	defer p.db.Lock(p.db.ConversationIndex.ForRead())()
 
-	data := p.db.Get(constant.PrivateChatKey)
+	var userList model.UserListModel
+	userList.Usernames = append(userList.Usernames, p.db.ConversationIndex.Get().Table[user]...)
+
+	if len(userList.Usernames) < 1 {
+		return nil, ErrNonUsers
+	}
+
+	sort.Strings(userList.Usernames)
 
-	privateChats, ok := data.(model.PrivateChatTable)
+	return userList.Usernames, nil
+}
+
+func (p *PrivateRepos) MarkAsRead(reader, partner string, messageID int) error {
+	members := mapper.StringToMembersPrivateChat(reader, partner)
+
+	shard, ok := p.db.PrivateChats.Lookup(members)
 	if !ok {
-		return nil, errIncorrectType
+		return ErrChatIsNotExists
 	}
 
-	var userList model.UserListModel
+	defer p.db.Lock(shard.ForWrite(), p.db.PrivateReads.ForWrite(), p.db.ExpiringMessages.ForWrite())()
+
+	chat, _ := shard.Get()
+	if len(chat.Messages) == 0 {
+		return ErrChatIsNotExists
+	}
+
+	lastID := chat.Messages[len(chat.Messages)-1].ID
+	if messageID == 0 || messageID > lastID {
+		messageID = lastID
+	}
+
+	reads := p.db.PrivateReads.Get()
+
+	marker := model.ReadMarkerModel{Reader: reader, Partner: partner}
+	if reads.Table[marker] >= messageID {
+		return nil
+	}
+
+	started, err := startExpiry(p.db, members, chat.Messages, reader, reads.Table[marker], messageID)
+	if err != nil {
+		return err
+	}
 
-	for members := range privateChats.Table {
-		if members.User1 == user {
-			userList.Usernames = append(userList.Usernames, members.User2)
+	if started {
+		if err := shard.Set(chat); err != nil {
+			return err
 		}
+	}
+
+	return p.db.PrivateReads.Update(model.SetReadMarker{Marker: marker, MessageID: messageID})
+}
+
+func (p *PrivateRepos) GetConversations(user string, partners []string) ([]entities.Conversation, error) {
+	return p.conversations(user, partners, false), nil
+}
+
+func (p *PrivateRepos) GetInbox(user string, limit, offset int) ([]entities.Conversation, error) {
+	unlock := p.db.Lock(p.db.ConversationIndex.ForRead())
+	partners, err := pagePartners(p.db.ConversationIndex.Get().Table[user], limit, offset)
+	unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p.conversations(user, partners, true), nil
+}
+
+// GetMessageRequests returns the conversations started by the strangers
+// that user has not accepted yet, the latest first.
+func (p *PrivateRepos) GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error) {
+	unlock := p.db.Lock(p.db.MessageRequests.ForRead())
+	partners, err := pagePartners(p.db.MessageRequests.Get().Table[user], limit, offset)
+	unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p.conversations(user, partners, true), nil
+}
 
-		if members.User2 == user {
-			userList.Usernames = append(userList.Usernames, members.User1)
+// AcceptMessageRequest moves the conversation with partner to the inbox of
+// user and lets partner write to them as a contact.
+func (p *PrivateRepos) AcceptMessageRequest(user, partner string) error {
+	defer p.db.Lock(p.db.MessageRequests.ForWrite(), p.db.ConversationIndex.ForWrite(), p.db.Contacts.ForWrite())()
+
+	requests := p.db.MessageRequests.Get()
+	if !containsPartner(requests.Table[user], partner) {
+		return entities.ErrMessageRequestNotFound
+	}
+
+	index := p.db.ConversationIndex.Get()
+
+	err := p.db.MessageRequests.Update(model.SetMessageRequests{User: user, Partners: removePartner(requests.Table[user], partner)})
+	if err != nil {
+		return err
+	}
+
+	err = p.db.ConversationIndex.Update(model.SetConversations{User: user, Partners: moveToFront(index.Table[user], partner)})
+	if err != nil {
+		return err
+	}
+
+	return p.db.Contacts.Update(model.AddContact{User: user, Partner: partner})
+}
+
+// DeclineMessageRequest removes the request of partner. The messages stay,
+// and a new message of partner makes a new request.
+func (p *PrivateRepos) DeclineMessageRequest(user, partner string) error {
+	defer p.db.Lock(p.db.MessageRequests.ForWrite())()
+
+	requests := p.db.MessageRequests.Get()
+	if !containsPartner(requests.Table[user], partner) {
+		return entities.ErrMessageRequestNotFound
+	}
+
+	return p.db.MessageRequests.Update(model.SetMessageRequests{User: user, Partners: removePartner(requests.Table[user], partner)})
+}
+
+// pagePartners returns a copy of the page of the partners. The partners are
+// read under the lock of their table and released before the conversations
+// are locked, which come first in the order of the locks.
+func pagePartners(partners []string, limit, offset int) ([]string, error) {
+	page, err := pagination.Pagination(partners, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string(nil), page...), nil
+}
+
+// conversations returns the status of the conversations of user with the
+// partners, with their last messages when last is set.
+func (p *PrivateRepos) conversations(user string, partners []string, last bool) []entities.Conversation {
+	locks := []inmemorydb.Access{p.db.PrivateReads.ForRead()}
+	shards := make([]*inmemorydb.Shard[model.MembersPrivateChatModel, model.PrivateChat], len(partners))
+	for i, partner := range partners {
+		if shard, ok := p.db.PrivateChats.Lookup(mapper.StringToMembersPrivateChat(user, partner)); ok {
+			shards[i] = shard
+			locks = append(locks, shard.ForRead())
 		}
 	}
+	defer p.db.Lock(locks...)()
 
-	if len(userList.Usernames) < 1 {
-		return nil, ErrNonUsers
+	reads := p.db.PrivateReads.Get()
+
+	now := time.Now()
+	conversations := make([]entities.Conversation, 0, len(partners))
+
+	for i, partner := range partners {
+		var chat model.PrivateChat
+		if shards[i] != nil {
+			chat, _ = shards[i].Get()
+		}
+
+		conversation := conversationStatus(user, partner, chat.Messages, reads)
+		if last && len(chat.Messages) > 0 {
+			conversation.LastMessage = visibleMessage(chat.Messages[len(chat.Messages)-1], now)
+		}
+
+		conversations = append(conversations, conversation)
 	}
 
-	sort.Strings(userList.Usernames)
+	return conversations
+}
 
-	return userList.Usernames, nil
+func (p *PrivateRepos) GetAttachment(id string) (entities.Attachment, entities.Message, error) {
+	unlock := p.db.Lock(p.db.PrivateAttachments.ForRead())
+	attachment, m, err := lookupAttachment(p.db.PrivateAttachments.Get(), id)
+	unlock()
+
+	if err != nil {
+		return entities.Attachment{}, entities.Message{}, err
+	}
+
+	if message, ok := p.findMessage(mapper.StringToMembersPrivateChat(m.Sender, m.Recipient), m.ID); ok && message.ExpiredAt(time.Now()) {
+		return entities.Attachment{}, entities.Message{}, entities.ErrAttachmentNotFound
+	}
+
+	return attachment, m, nil
+}
+
+// SearchMessages looks for messages of the private conversations the user
+// takes part in.
+func (p *PrivateRepos) SearchMessages(user string, q entities.SearchQuery) ([]entities.SearchResult, error) {
+	unlock := p.db.Lock(p.db.PrivateSearchIndex.ForRead())
+	postings := append([]model.PostingModel(nil), candidates(p.db.PrivateSearchIndex.Get(), q.Terms)...)
+	unlock()
+
+	now := time.Now()
+	results := make([]entities.SearchResult, 0)
+	for _, posting := range postings {
+		if posting.Members.User1 != user && posting.Members.User2 != user {
+			continue
+		}
+
+		m, ok := p.findMessage(posting.Members, posting.MessageID)
+		if !ok || m.ExpiredAt(now) {
+			continue
+		}
+
+		if result, ok := matchMessage(q, privateChatKey(posting.Members), m); ok {
+			results = append(results, result)
+		}
+	}
+
+	return firstResults(results, q.Limit), nil
+}
+
+// PurgeExpiredMessages replaces up to limit messages expired at now with
+// tombstones, the longest expired first, and returns how many it purged
+// together with the IDs of the attachments it removed, whose files are left
+// to the caller.
+func (p *PrivateRepos) PurgeExpiredMessages(now time.Time, limit int) (int, []string, error) {
+	due := p.dueMessages(now, limit)
+	if len(due) == 0 {
+		return 0, nil, nil
+	}
+
+	locks := []inmemorydb.Access{p.db.ExpiringMessages.ForWrite(), p.db.PrivateAttachments.ForWrite()}
+	shards := make(map[model.MembersPrivateChatModel]*inmemorydb.Shard[model.MembersPrivateChatModel, model.PrivateChat])
+	for _, ref := range due {
+		if shard, ok := p.db.PrivateChats.Lookup(ref.Members); ok && shards[ref.Members] == nil {
+			shards[ref.Members] = shard
+			locks = append(locks, shard.ForWrite())
+		}
+	}
+	defer p.db.Lock(locks...)()
+
+	expiring := p.db.ExpiringMessages.Get()
+	changed := make(map[model.MembersPrivateChatModel]bool)
+
+	var (
+		purged  int
+		removed []string
+	)
+	for _, ref := range due {
+		// Another purge may have come first.
+		if _, ok := expiring.Table[ref]; !ok {
+			continue
+		}
+
+		delete(expiring.Table, ref)
+		purged++
+
+		shard, ok := shards[ref.Members]
+		if !ok {
+			continue
+		}
+
+		chat, _ := shard.Get()
+		messages := chat.Messages
+		i := sort.Search(len(messages), func(i int) bool { return messages[i].ID >= ref.MessageID })
+		if i == len(messages) || messages[i].ID != ref.MessageID {
+			continue
+		}
+
+		for _, attachment := range messages[i].Attachments {
+			removed = append(removed, attachment.ID)
+		}
+
+		messages[i] = messages[i].Tombstone()
+		changed[ref.Members] = true
+	}
+
+	for members := range changed {
+		chat, _ := shards[members].Get()
+		if err := shards[members].Set(chat); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := p.db.PrivateAttachments.Update(model.RemoveAttachments{IDs: removed}); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if err := p.db.ExpiringMessages.Set(expiring); err != nil {
+		return 0, nil, err
+	}
+
+	return purged, removed, nil
+}
+
+// dueMessages returns up to limit messages expired at now, the longest
+// expired first.
+func (p *PrivateRepos) dueMessages(now time.Time, limit int) []model.PrivateMessageRefModel {
+	defer p.db.Lock(p.db.ExpiringMessages.ForRead())()
+
+	expiring := p.db.ExpiringMessages.Get()
+
+	due := make([]model.PrivateMessageRefModel, 0)
+	for ref, expiresAt := range expiring.Table {
+		if !expiresAt.After(now) {
+			due = append(due, ref)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		ti, tj := expiring.Table[due[i]], expiring.Table[due[j]]
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+
+		return due[i].MessageID < due[j].MessageID
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due
+}
+
+// findMessage returns the message of the conversation under the lock of its
+// shard.
+func (p *PrivateRepos) findMessage(members model.MembersPrivateChatModel, id int) (entities.Message, bool) {
+	shard, ok := p.db.PrivateChats.Lookup(members)
+	if !ok {
+		return entities.Message{}, false
+	}
+
+	defer p.db.Lock(shard.ForRead())()
+
+	chat, _ := shard.Get()
+
+	return findMessage(chat.Messages, id)
+}
+
+// nextMessageID returns the ID of the next message of the conversation:
+// the IDs of the messages the retention policy removed are never given
+// again.
+func nextMessageID(chat model.PrivateChat) int {
+	if len(chat.Messages) == 0 {
+		return chat.LastID + 1
+	}
+
+	return max(chat.LastID, chat.Messages[len(chat.Messages)-1].ID) + 1
+}
+
+// visibleMessage returns the message as it is shown at now: a tombstone
+// once it has expired.
+func visibleMessage(m entities.Message, now time.Time) entities.Message {
+	if m.ExpiredAt(now) {
+		return m.Tombstone()
+	}
+
+	return m
+}
+
+// scheduleExpiry adds the message to the expiring ones. The caller holds the
+// write lock of the expiring messages.
+func scheduleExpiry(db *inmemorydb.MemoryDB, members model.MembersPrivateChatModel, m entities.Message) error {
+	return db.ExpiringMessages.Update(model.ScheduleExpiry{
+		Ref:       model.PrivateMessageRefModel{Members: members, MessageID: m.ID},
+		ExpiresAt: m.ExpiresAt,
+	})
+}
+
+// startExpiry starts the countdown of the messages expiring after read the
+// reader has just read: the ones sent to them after lastRead up to readTo.
+// It reports whether it started any.
+func startExpiry(db *inmemorydb.MemoryDB, members model.MembersPrivateChatModel, messages []entities.Message, reader string, lastRead, readTo int) (bool, error) {
+	now := time.Now()
+
+	var started bool
+	for i := len(messages) - 1; i >= 0 && messages[i].ID > lastRead; i-- {
+		m := &messages[i]
+		if m.ID > readTo || m.Recipient != reader || !m.ExpireAfterRead || !m.ExpiresAt.IsZero() || m.Expired {
+			continue
+		}
+
+		m.ExpiresAt = now.Add(m.TTL)
+		if err := scheduleExpiry(db, members, *m); err != nil {
+			return false, err
+		}
+		started = true
+	}
+
+	return started, nil
+}
+
+func conversationStatus(user, partner string, messages []entities.Message, reads model.PrivateReadTable) entities.Conversation {
+	conversation := entities.Conversation{Partner: partner}
+
+	lastRead := reads.Table[model.ReadMarkerModel{Reader: user, Partner: partner}]
+	for i := len(messages) - 1; i >= 0 && messages[i].ID > lastRead; i-- {
+		if messages[i].Sender == partner {
+			conversation.UnreadCount++
+		}
+	}
+
+	partnerLastRead := reads.Table[model.ReadMarkerModel{Reader: partner, Partner: user}]
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Sender == user {
+			conversation.SeenByPartner = messages[i].ID <= partnerLastRead
+			break
+		}
+	}
+
+	return conversation
+}
+
+// moveToFront returns the partners with partner first. The list is copied
+// rather than changed: it is stored by a change of its table.
+func moveToFront(partners []string, partner string) []string {
+	moved := make([]string, 0, len(partners)+1)
+	moved = append(moved, partner)
+
+	for _, val := range partners {
+		if val != partner {
+			moved = append(moved, val)
+		}
+	}
+
+	return moved
+}
+
+// removePartner returns the partners without partner, as a copy like
+// moveToFront.
+func removePartner(partners []string, partner string) []string {
+	kept := make([]string, 0, len(partners))
+
+	for _, val := range partners {
+		if val != partner {
+			kept = append(kept, val)
+		}
+	}
+
+	return kept
+}
+
+func containsPartner(partners []string, partner string) bool {
+	for _, val := range partners {
+		if val == partner {
+			return true
+		}
+	}
+
+	return false
 }
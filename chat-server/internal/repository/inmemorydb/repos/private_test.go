@@ -1,25 +1,30 @@
 package repos
 
 import (
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
 	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
-	"github.com/vavelour/chat/internal/repository/inmemorydb/model/constant"
-	mock_repos "github.com/vavelour/chat/internal/repository/inmemorydb/repos/mocks"
+	"github.com/vavelour/chat/pkg/pagination"
 	"testing"
+	"time"
 )
 
-func TestPrivateRepos_GetMessages(t *testing.T) {
-	type mockBehavior func(m *mock_repos.MockMemoryDB, sender, recipient string, limit, offset int)
+func seedPrivateChats(db *inmemorydb.MemoryDB, chats map[model.MembersPrivateChatModel]model.PrivateChat) {
+	for members, chat := range chats {
+		db.PrivateChats.Shard(members).Set(chat)
+	}
+}
 
+func TestPrivateRepos_GetMessages(t *testing.T) {
 	testTable := []struct {
 		name             string
 		sender           string
 		recipient        string
 		limit            int
 		offset           int
-		mockBehavior     mockBehavior
+		seed             func(db *inmemorydb.MemoryDB, sender, recipient string)
 		expectedMessages []entities.Message
 		expectedError    error
 	}{
@@ -29,18 +34,19 @@ func TestPrivateRepos_GetMessages(t *testing.T) {
 			recipient: "recipient_tester",
 			limit:     2,
 			offset:    0,
-			mockBehavior: func(m *mock_repos.MockMemoryDB, sender, recipient string, limit, offset int) {
-				m.EXPECT().Get(constant.PrivateChatKey).Return(model.PrivateChatTable{Table: map[model.MembersPrivateChatModel]model.PrivateChat{model.MembersPrivateChatModel{User1: recipient, User2: sender}: {Messages: []entities.Message{{
-					Sender:    sender,
-					Recipient: recipient,
-					Content:   "hello, bro!",
-				},
+			seed: func(db *inmemorydb.MemoryDB, sender, recipient string) {
+				db.PrivateChats.Shard(model.MembersPrivateChatModel{User1: recipient, User2: sender}).Set(model.PrivateChat{Messages: []entities.Message{
+					{
+						Sender:    sender,
+						Recipient: recipient,
+						Content:   "hello, bro!",
+					},
 					{
 						Sender:    sender,
 						Recipient: recipient,
 						Content:   "how are you?",
-					}}}},
-				})
+					},
+				}})
 			},
 			expectedMessages: []entities.Message{
 				{
@@ -56,26 +62,14 @@ func TestPrivateRepos_GetMessages(t *testing.T) {
 			},
 			expectedError: nil,
 		},
-		{
-			name:      "incorrect_type",
-			sender:    "sender_tester",
-			recipient: "recipient_tester",
-			limit:     2,
-			offset:    0,
-			mockBehavior: func(m *mock_repos.MockMemoryDB, sender, recipient string, limit, offset int) {
-				m.EXPECT().Get(constant.PrivateChatKey).Return(errIncorrectType)
-			},
-			expectedMessages: nil,
-			expectedError:    errIncorrectType,
-		},
 		{
 			name:      "chat_is_not_exists",
 			sender:    "sender_tester",
 			recipient: "tester",
 			limit:     2,
 			offset:    0,
-			mockBehavior: func(m *mock_repos.MockMemoryDB, sender, recipient string, limit, offset int) {
-				m.EXPECT().Get(constant.PrivateChatKey).Return(model.PrivateChatTable{Table: map[model.MembersPrivateChatModel]model.PrivateChat{}})
+			seed: func(db *inmemorydb.MemoryDB, sender, recipient string) {
+				db.PrivateChats.Shard(model.MembersPrivateChatModel{User1: "recipient_tester", User2: sender}).Set(model.PrivateChat{})
 			},
 			expectedMessages: nil,
 			expectedError:    ErrChatIsNotExists,
@@ -83,15 +77,12 @@ func TestPrivateRepos_GetMessages(t *testing.T) {
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			mockDB := mock_repos.NewMockMemoryDB(ctrl)
-			repo := NewPrivateRepos(mockDB)
+			db := inmemorydb.NewDB()
+			repo := NewPrivateRepos(db)
 
-			testCase.mockBehavior(mockDB, testCase.sender, testCase.recipient, testCase.limit, testCase.offset)
+			testCase.seed(db, testCase.sender, testCase.recipient)
 
-			messages, err := repo.GetMessages(testCase.sender, testCase.recipient, testCase.limit, testCase.offset)
+			messages, err := repo.GetMessages(testCase.sender, testCase.recipient, testCase.limit, testCase.offset, entities.OrderOldestFirst)
 			assert.Equal(t, testCase.expectedError, err)
 			assert.Equal(t, testCase.expectedMessages, messages)
 		})
@@ -100,137 +91,502 @@ func TestPrivateRepos_GetMessages(t *testing.T) {
 }
 
 func TestPrivateRepos_InsertMessage(t *testing.T) {
-	type mockBehavior func(m *mock_repos.MockMemoryDB, mess entities.Message)
+	members := model.MembersPrivateChatModel{User1: "sender_sender", User2: "tester"}
 
 	testTable := []struct {
-		name            string
-		expectedMessage entities.Message
-		mockBehavior    mockBehavior
-		expectedError   error
+		name          string
+		message       entities.Message
+		seed          func(db *inmemorydb.MemoryDB, mess entities.Message)
+		check         func(t *testing.T, db *inmemorydb.MemoryDB, mess entities.Message)
+		expectedError error
 	}{
 		{
 			name: "ok",
-			expectedMessage: entities.Message{
+			message: entities.Message{
 				Sender:    "sender_sender",
 				Recipient: "tester",
 				Content:   "hello, tester!",
 			},
-			mockBehavior: func(m *mock_repos.MockMemoryDB, mess entities.Message) {
-				m.EXPECT().Get(constant.UsersKey).Return(model.UsersTable{Table: map[string]entities.User{"tester": {Username: "tester", Password: "123"}}})
-				m.EXPECT().Get(constant.PrivateChatKey).Return(model.PrivateChatTable{Table: map[model.MembersPrivateChatModel]model.PrivateChat{
-					model.MembersPrivateChatModel{User1: "sender_sender", User2: "tester"}: {
-						Messages: []entities.Message{{
-							Sender:    mess.Sender,
-							Recipient: mess.Recipient,
-							Content:   mess.Content,
-						}}}}})
-				m.EXPECT().Insert(constant.PrivateChatKey, gomock.Any()).Do(func(key string, data interface{}) {
-					messages, _ := data.(model.PrivateChatTable)
-					assert.Equal(t, mess, messages.Table[model.MembersPrivateChatModel{User1: "sender_sender", User2: "tester"}].Messages[0])
-				})
+			seed: func(db *inmemorydb.MemoryDB, mess entities.Message) {
+				db.Users.Set(model.UsersTable{Table: map[string]entities.User{"tester": {Username: "tester", Password: "123"}}})
+				db.PrivateChats.Shard(members).Set(model.PrivateChat{Messages: []entities.Message{{
+					Sender:    mess.Sender,
+					Recipient: mess.Recipient,
+					Content:   mess.Content,
+				}}})
+				db.ConversationIndex.Set(model.ConversationIndexTable{Table: map[string][]string{
+					"sender_sender": {"other", "tester"},
+				}})
+			},
+			check: func(t *testing.T, db *inmemorydb.MemoryDB, mess entities.Message) {
+				index := db.PrivateSearchIndex.Get()
+				assert.Equal(t, []model.PostingModel{{Members: members, MessageID: 1}}, index.Postings["hello"])
+				assert.Equal(t, []model.PostingModel{{Members: members, MessageID: 1}}, index.Postings["tester"])
+
+				notifications, _ := db.Notifications.Shard("tester").Get()
+				assert.Len(t, notifications, 1)
+				assert.Equal(t, entities.NotificationPrivateMessage, notifications[0].Kind)
+				assert.Equal(t, "sender_sender", notifications[0].Sender)
+				assert.Equal(t, 1, notifications[0].MessageID)
+
+				chat, _ := db.PrivateChats.Shard(members).Get()
+				assert.Equal(t, mess, chat.Messages[0])
+				assert.Equal(t, 1, chat.Messages[1].ID)
+
+				conversations := db.ConversationIndex.Get()
+				assert.Equal(t, []string{"tester", "other"}, conversations.Table["sender_sender"])
+				assert.Empty(t, conversations.Table["tester"])
+
+				contacts := db.Contacts.Get()
+				assert.True(t, contacts.Table["sender_sender"]["tester"])
+				assert.False(t, contacts.Table["tester"]["sender_sender"])
+
+				assert.Equal(t, []string{"sender_sender"}, db.MessageRequests.Get().Table["tester"])
 			},
 			expectedError: nil,
 		},
 		{
-			name:            "user_is_not_exists",
-			expectedMessage: entities.Message{},
-			mockBehavior: func(m *mock_repos.MockMemoryDB, mess entities.Message) {
-				m.EXPECT().Get(constant.UsersKey).Return(model.UsersTable{Table: map[string]entities.User{}})
+			name:    "user_is_not_exists",
+			message: entities.Message{Sender: "sender_sender", Recipient: "tester"},
+			seed:    func(db *inmemorydb.MemoryDB, mess entities.Message) {},
+			check: func(t *testing.T, db *inmemorydb.MemoryDB, mess entities.Message) {
+				// No shard is left behind for the made-up recipient.
+				assert.Empty(t, db.PrivateChats.Keys())
+				assert.Empty(t, db.Notifications.Keys())
+				assert.Empty(t, db.ConversationIndex.Get().Table)
 			},
 			expectedError: ErrUserIsNotExists,
 		},
-		{
-			name:            "incorrect_type_user",
-			expectedMessage: entities.Message{},
-			mockBehavior: func(m *mock_repos.MockMemoryDB, mess entities.Message) {
-				m.EXPECT().Get(constant.UsersKey).Return(errIncorrectType)
-			},
-			expectedError: errIncorrectType,
-		},
-		{
-			name: "incorrect_type_message",
-			expectedMessage: entities.Message{
-				Sender:    "sender_sender",
-				Recipient: "tester",
-				Content:   "hello, tester!",
-			},
-			mockBehavior: func(m *mock_repos.MockMemoryDB, mess entities.Message) {
-				m.EXPECT().Get(constant.UsersKey).Return(model.UsersTable{Table: map[string]entities.User{"tester": {Username: "tester", Password: "123"}}})
-				m.EXPECT().Get(constant.PrivateChatKey).Return(errIncorrectType)
-			},
-			expectedError: errIncorrectType,
-		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			db := inmemorydb.NewDB()
+			repo := NewPrivateRepos(db)
 
-			mockDB := mock_repos.NewMockMemoryDB(ctrl)
-			repo := NewPrivateRepos(mockDB)
+			testCase.seed(db, testCase.message)
 
-			testCase.mockBehavior(mockDB, testCase.expectedMessage)
-
-			err := repo.InsertMessage(testCase.expectedMessage)
+			err := repo.InsertMessage(testCase.message)
 			assert.Equal(t, testCase.expectedError, err)
+
+			testCase.check(t, db, testCase.message)
 		})
 	}
 }
 
 func TestPrivateRepos_GetUsers(t *testing.T) {
-	type mockBehavior func(m *mock_repos.MockMemoryDB, user string)
-
 	testTable := []struct {
 		name          string
 		user          string
-		mockBehavior  mockBehavior
+		index         map[string][]string
 		expectedUsers []string
 		expectedError error
 	}{
 		{
 			name: "ok",
 			user: "tester",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, user string) {
-				m.EXPECT().Get(constant.PrivateChatKey).Return(model.PrivateChatTable{Table: map[model.MembersPrivateChatModel]model.PrivateChat{
-					model.MembersPrivateChatModel{User1: "tester_1", User2: "tester"}: {Messages: nil},
-					model.MembersPrivateChatModel{User1: "tester_2", User2: "tester"}: {Messages: nil},
-				}})
+			index: map[string][]string{
+				"tester":   {"tester_2", "tester_1"},
+				"tester_1": {"tester"},
 			},
 			expectedUsers: []string{"tester_1", "tester_2"},
 			expectedError: nil,
 		},
 		{
-			name: "incorrect_type",
-			user: "tester",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, user string) {
-				m.EXPECT().Get(constant.PrivateChatKey).Return(errIncorrectType)
-			},
+			name:          "non_users",
+			user:          "tester",
+			index:         map[string][]string{},
 			expectedUsers: nil,
-			expectedError: errIncorrectType,
+			expectedError: ErrNonUsers,
 		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewPrivateRepos(db)
+
+			db.ConversationIndex.Set(model.ConversationIndexTable{Table: testCase.index})
+
+			users, err := repo.GetUsers(testCase.user)
+			assert.Equal(t, testCase.expectedUsers, users)
+			assert.Equal(t, testCase.expectedError, err)
+		})
+	}
+}
+
+func TestPrivateRepos_MarkAsRead(t *testing.T) {
+	chats := func() map[model.MembersPrivateChatModel]model.PrivateChat {
+		return map[model.MembersPrivateChatModel]model.PrivateChat{
+			model.MembersPrivateChatModel{User1: "tester", User2: "tester_1"}: {Messages: []entities.Message{
+				{ID: 1, Sender: "tester_1", Recipient: "tester", Content: "hello"},
+				{ID: 2, Sender: "tester_1", Recipient: "tester", Content: "how are you?"},
+			}},
+		}
+	}
+
+	testTable := []struct {
+		name           string
+		reader         string
+		partner        string
+		messageID      int
+		reads          map[model.ReadMarkerModel]int
+		expectedMarker int
+		expectedError  error
+	}{
 		{
-			name: "non_users",
-			user: "tester",
-			mockBehavior: func(m *mock_repos.MockMemoryDB, user string) {
-				m.EXPECT().Get(constant.PrivateChatKey).Return(model.PrivateChatTable{Table: map[model.MembersPrivateChatModel]model.PrivateChat{}})
+			name:           "ok_latest",
+			reader:         "tester",
+			partner:        "tester_1",
+			messageID:      0,
+			reads:          map[model.ReadMarkerModel]int{},
+			expectedMarker: 2,
+			expectedError:  nil,
+		},
+		{
+			name:           "ok_message_id",
+			reader:         "tester",
+			partner:        "tester_1",
+			messageID:      1,
+			reads:          map[model.ReadMarkerModel]int{},
+			expectedMarker: 1,
+			expectedError:  nil,
+		},
+		{
+			name:      "marker_not_moved_back",
+			reader:    "tester",
+			partner:   "tester_1",
+			messageID: 1,
+			reads: map[model.ReadMarkerModel]int{
+				{Reader: "tester", Partner: "tester_1"}: 2,
 			},
-			expectedUsers: nil,
-			expectedError: ErrNonUsers,
+			expectedMarker: 2,
+			expectedError:  nil,
+		},
+		{
+			name:           "chat_is_not_exists",
+			reader:         "tester",
+			partner:        "tester_2",
+			messageID:      0,
+			reads:          map[model.ReadMarkerModel]int{},
+			expectedMarker: 0,
+			expectedError:  ErrChatIsNotExists,
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			db := inmemorydb.NewDB()
+			repo := NewPrivateRepos(db)
 
-			mockDB := mock_repos.NewMockMemoryDB(ctrl)
-			repo := NewPrivateRepos(mockDB)
+			seedPrivateChats(db, chats())
+			db.PrivateReads.Set(model.PrivateReadTable{Table: testCase.reads})
 
-			testCase.mockBehavior(mockDB, testCase.user)
+			err := repo.MarkAsRead(testCase.reader, testCase.partner, testCase.messageID)
+			assert.Equal(t, testCase.expectedError, err)
 
-			users, err := repo.GetUsers(testCase.user)
-			assert.Equal(t, testCase.expectedUsers, users)
+			marker := model.ReadMarkerModel{Reader: testCase.reader, Partner: testCase.partner}
+			assert.Equal(t, testCase.expectedMarker, db.PrivateReads.Get().Table[marker])
+		})
+	}
+}
+
+func TestPrivateRepos_GetConversations(t *testing.T) {
+	testTable := []struct {
+		name                  string
+		user                  string
+		partners              []string
+		chats                 map[model.MembersPrivateChatModel]model.PrivateChat
+		reads                 map[model.ReadMarkerModel]int
+		expectedConversations []entities.Conversation
+		expectedError         error
+	}{
+		{
+			name:     "ok",
+			user:     "tester",
+			partners: []string{"tester_1", "tester_2"},
+			chats: map[model.MembersPrivateChatModel]model.PrivateChat{
+				model.MembersPrivateChatModel{User1: "tester", User2: "tester_1"}: {Messages: []entities.Message{
+					{ID: 1, Sender: "tester", Recipient: "tester_1", Content: "hello"},
+					{ID: 2, Sender: "tester_1", Recipient: "tester", Content: "hi"},
+					{ID: 3, Sender: "tester_1", Recipient: "tester", Content: "how are you?"},
+				}},
+				model.MembersPrivateChatModel{User1: "tester", User2: "tester_2"}: {Messages: []entities.Message{
+					{ID: 1, Sender: "tester", Recipient: "tester_2", Content: "hello"},
+				}},
+			},
+			reads: map[model.ReadMarkerModel]int{
+				{Reader: "tester", Partner: "tester_1"}: 2,
+				{Reader: "tester_1", Partner: "tester"}: 1,
+			},
+			expectedConversations: []entities.Conversation{
+				{Partner: "tester_1", UnreadCount: 1, SeenByPartner: true},
+				{Partner: "tester_2", UnreadCount: 0, SeenByPartner: false},
+			},
+			expectedError: nil,
+		},
+		{
+			name:     "no_chat",
+			user:     "tester",
+			partners: []string{"tester_1"},
+			chats:    map[model.MembersPrivateChatModel]model.PrivateChat{},
+			reads:    map[model.ReadMarkerModel]int{},
+			expectedConversations: []entities.Conversation{
+				{Partner: "tester_1"},
+			},
+			expectedError: nil,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewPrivateRepos(db)
+
+			seedPrivateChats(db, testCase.chats)
+			db.PrivateReads.Set(model.PrivateReadTable{Table: testCase.reads})
+
+			conversations, err := repo.GetConversations(testCase.user, testCase.partners)
+			assert.Equal(t, testCase.expectedConversations, conversations)
 			assert.Equal(t, testCase.expectedError, err)
 		})
 	}
 }
+
+func TestPrivateRepos_GetInbox(t *testing.T) {
+	firstMessage := entities.Message{ID: 1, Sender: "tester", Recipient: "tester_1", Content: "hello"}
+	lastMessage := entities.Message{ID: 2, Sender: "tester_1", Recipient: "tester", Content: "hi"}
+	otherMessage := entities.Message{ID: 1, Sender: "tester_2", Recipient: "tester", Content: "ping"}
+
+	testTable := []struct {
+		name                  string
+		user                  string
+		limit                 int
+		offset                int
+		expectedConversations []entities.Conversation
+		expectedError         error
+	}{
+		{
+			name:   "ok",
+			user:   "tester",
+			limit:  1,
+			offset: 1,
+			expectedConversations: []entities.Conversation{
+				{Partner: "tester_1", LastMessage: lastMessage, UnreadCount: 1, SeenByPartner: true},
+			},
+			expectedError: nil,
+		},
+		{
+			name:                  "offset_out_of_range",
+			user:                  "tester",
+			limit:                 1,
+			offset:                5,
+			expectedConversations: nil,
+			expectedError:         pagination.ErrOffsetRange,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewPrivateRepos(db)
+
+			db.ConversationIndex.Set(model.ConversationIndexTable{Table: map[string][]string{
+				"tester": {"tester_2", "tester_1"},
+			}})
+			seedPrivateChats(db, map[model.MembersPrivateChatModel]model.PrivateChat{
+				model.MembersPrivateChatModel{User1: "tester", User2: "tester_1"}: {Messages: []entities.Message{firstMessage, lastMessage}},
+				model.MembersPrivateChatModel{User1: "tester", User2: "tester_2"}: {Messages: []entities.Message{otherMessage}},
+			})
+			db.PrivateReads.Set(model.PrivateReadTable{Table: map[model.ReadMarkerModel]int{
+				{Reader: "tester_1", Partner: "tester"}: 1,
+			}})
+
+			conversations, err := repo.GetInbox(testCase.user, testCase.limit, testCase.offset)
+			assert.Equal(t, testCase.expectedConversations, conversations)
+			assert.Equal(t, testCase.expectedError, err)
+		})
+	}
+}
+
+func TestPrivateRepos_GetAttachment(t *testing.T) {
+	attachment := entities.Attachment{ID: "a1", FileName: "report.pdf", ContentType: "application/pdf", Size: 1024}
+	members := model.MembersPrivateChatModel{User1: "tester", User2: "valera"}
+
+	testTable := []struct {
+		name               string
+		id                 string
+		data               model.AttachmentTable
+		chats              map[model.MembersPrivateChatModel]model.PrivateChat
+		expectedAttachment entities.Attachment
+		expectedMessage    entities.Message
+		expectedError      error
+	}{
+		{
+			name: "ok",
+			id:   "a1",
+			data: model.AttachmentTable{Table: map[string]model.AttachmentModel{
+				"a1": {Attachment: attachment, MessageID: 7, Sender: "tester", Recipient: "valera"},
+			}},
+			chats: map[model.MembersPrivateChatModel]model.PrivateChat{
+				members: {Messages: []entities.Message{{ID: 7, Sender: "tester", Recipient: "valera", Attachments: []entities.Attachment{attachment}}}},
+			},
+			expectedAttachment: attachment,
+			expectedMessage:    entities.Message{ID: 7, Sender: "tester", Recipient: "valera"},
+			expectedError:      nil,
+		},
+		{
+			name: "expired",
+			id:   "a1",
+			data: model.AttachmentTable{Table: map[string]model.AttachmentModel{
+				"a1": {Attachment: attachment, MessageID: 7, Sender: "tester", Recipient: "valera"},
+			}},
+			chats: map[model.MembersPrivateChatModel]model.PrivateChat{
+				members: {Messages: []entities.Message{{ID: 7, Sender: "tester", Recipient: "valera", TTL: time.Minute, ExpiresAt: time.Now().Add(-time.Second)}}},
+			},
+			expectedError: entities.ErrAttachmentNotFound,
+		},
+		{
+			name:          "not_found",
+			id:            "a2",
+			data:          model.AttachmentTable{Table: map[string]model.AttachmentModel{}},
+			expectedError: entities.ErrAttachmentNotFound,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewPrivateRepos(db)
+
+			db.PrivateAttachments.Set(testCase.data)
+			seedPrivateChats(db, testCase.chats)
+
+			attachment, message, err := repo.GetAttachment(testCase.id)
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedAttachment, attachment)
+			assert.Equal(t, testCase.expectedMessage, message)
+		})
+	}
+}
+
+func TestPrivateRepos_PurgeExpiredMessages(t *testing.T) {
+	db := inmemorydb.NewDB()
+	db.Users.Set(model.UsersTable{Table: map[string]entities.User{
+		"tester": {Username: "tester"},
+		"valera": {Username: "valera"},
+	}})
+
+	repo := NewPrivateRepos(db)
+
+	assert.NoError(t, repo.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "burn after reading", TTL: time.Minute}))
+	assert.NoError(t, repo.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "read me first", TTL: time.Minute, ExpireAfterRead: true}))
+	assert.NoError(t, repo.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "stays"}))
+
+	notifications, _ := db.Notifications.Shard("valera").Get()
+	assert.Equal(t, "", notifications[0].Content)
+	assert.Equal(t, "stays", notifications[2].Content)
+
+	// Nothing has expired yet; the second message waits to be read.
+	purged, _, err := repo.PurgeExpiredMessages(time.Now(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	assert.NoError(t, repo.MarkAsRead("valera", "tester", 0))
+
+	later := time.Now().Add(2 * time.Minute)
+
+	purged, _, err = repo.PurgeExpiredMessages(later, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	purged, _, err = repo.PurgeExpiredMessages(later, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	messages, err := repo.GetMessages("tester", "valera", 10, 0, entities.OrderOldestFirst)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "", "stays"}, []string{messages[0].Content, messages[1].Content, messages[2].Content})
+	assert.True(t, messages[0].Expired)
+	assert.True(t, messages[1].Expired)
+	assert.False(t, messages[2].Expired)
+
+	results, err := repo.SearchMessages("valera", searchQuery(t, "reading", 10))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestPrivateRepos_MessageRequests(t *testing.T) {
+	db := inmemorydb.NewDB()
+	db.Users.Set(model.UsersTable{Table: map[string]entities.User{
+		"tester": {Username: "tester"},
+		"valera": {Username: "valera"},
+		"petya":  {Username: "petya"},
+	}})
+
+	repo := NewPrivateRepos(db)
+
+	partners := func(conversations []entities.Conversation) []string {
+		result := make([]string, 0, len(conversations))
+		for _, c := range conversations {
+			result = append(result, c.Partner)
+		}
+		return result
+	}
+
+	require.NoError(t, repo.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "hi"}))
+	require.NoError(t, repo.InsertMessage(entities.Message{Sender: "petya", Recipient: "tester", Content: "hey"}))
+
+	_, err := repo.GetInbox("tester", 10, 0)
+	assert.Equal(t, pagination.ErrOffsetRange, err)
+
+	requests, err := repo.GetMessageRequests("tester", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"petya", "valera"}, partners(requests))
+	assert.Equal(t, 1, requests[0].UnreadCount)
+	assert.Equal(t, "hey", requests[0].LastMessage.Content)
+
+	inbox, err := repo.GetInbox("valera", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tester"}, partners(inbox))
+
+	require.NoError(t, repo.AcceptMessageRequest("tester", "valera"))
+	assert.Equal(t, entities.ErrMessageRequestNotFound, repo.AcceptMessageRequest("tester", "valera"))
+
+	inbox, err = repo.GetInbox("tester", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"valera"}, partners(inbox))
+
+	require.NoError(t, repo.DeclineMessageRequest("tester", "petya"))
+	assert.Equal(t, entities.ErrMessageRequestNotFound, repo.DeclineMessageRequest("tester", "petya"))
+
+	_, err = repo.GetMessageRequests("tester", 10, 0)
+	assert.Equal(t, pagination.ErrOffsetRange, err)
+
+	// An accepted contact writes straight to the inbox.
+	require.NoError(t, repo.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "again"}))
+
+	_, err = repo.GetMessageRequests("tester", 10, 0)
+	assert.Equal(t, pagination.ErrOffsetRange, err)
+
+	// A new message of the declined stranger makes a new request, and
+	// replying to it accepts it.
+	require.NoError(t, repo.InsertMessage(entities.Message{Sender: "petya", Recipient: "tester", Content: "please"}))
+
+	requests, err = repo.GetMessageRequests("tester", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"petya"}, partners(requests))
+
+	require.NoError(t, repo.InsertMessage(entities.Message{Sender: "tester", Recipient: "petya", Content: "ok"}))
+
+	_, err = repo.GetMessageRequests("tester", 10, 0)
+	assert.Equal(t, pagination.ErrOffsetRange, err)
+
+	inbox, err = repo.GetInbox("tester", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"petya", "valera"}, partners(inbox))
+
+	privacy := NewPrivacyRepos(db)
+
+	contact, err := privacy.IsContact("tester", "petya")
+	require.NoError(t, err)
+	assert.True(t, contact)
+
+	contact, err = privacy.IsContact("petya", "valera")
+	require.NoError(t, err)
+	assert.False(t, contact)
+}
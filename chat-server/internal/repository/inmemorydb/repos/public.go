@@ -1,61 +1,117 @@
 package repos
 
 import (
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
 	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
-	"github.com/vavelour/chat/internal/repository/inmemorydb/model/constant"
-	"sync"
-
-	"github.com/vavelour/chat/pkg/pagination"
+	"time"
 
 	"github.com/vavelour/chat/internal/domain/entities"
 )
 
-type PublicDatabase interface {
-	Insert(key string, data interface{})
-	Get(key string) interface{}
-}
-
 type PublicRepos struct {
-	mu sync.RWMutex
-	db PublicDatabase
+	db *inmemorydb.MemoryDB
 }
 
-func NewPublicRepos(db PublicDatabase) *PublicRepos {
+func NewPublicRepos(db *inmemorydb.MemoryDB) *PublicRepos {
 	return &PublicRepos{db: db}
 }
 
 func (pub *PublicRepos) InsertMessage(m entities.Message) error {
-	pub.mu.Lock()
-	defer pub.mu.Unlock()
+	locks := append(notificationLocks(pub.db, m.Mentions...),
+		pub.db.PublicChat.ForWrite(),
+		pub.db.PublicAttachments.ForWrite(),
+		pub.db.PublicSearchIndex.ForWrite(),
+		pub.db.Webhooks.ForWrite())
+	defer pub.db.Lock(locks...)()
+
+	m.ID = pub.db.PublicChat.NextID()
+	m.CreatedAt = time.Now()
+
+	if len(m.Attachments) > 0 {
+		if err := pub.db.PublicAttachments.Update(indexAttachments(&m)); err != nil {
+			return err
+		}
+	}
 
-	data := pub.db.Get(constant.PublicChatKey)
+	if err := pub.db.PublicSearchIndex.Update(indexMessage(model.MembersPrivateChatModel{}, m)); err != nil {
+		return err
+	}
 
-	publicMessages, ok := data.(model.PublicChat)
-	if !ok {
-		return errIncorrectType
+	if err := notify(pub.db, m, entities.NotificationMention, m.Mentions...); err != nil {
+		return err
 	}
 
-	publicMessages.Messages = append(publicMessages.Messages, m)
-	pub.db.Insert(constant.PublicChatKey, publicMessages)
+	if err := enqueueWebhookEvent(pub.db, entities.EventMessageCreated, entities.PublicChannel, m); err != nil {
+		return err
+	}
 
-	return nil
+	return pub.db.PublicChat.Append(m)
 }
 
-func (pub *PublicRepos) GetMessages(limit, offset int) ([]entities.Message, error) {
-	pub.mu.RLock()
-	defer pub.mu.RUnlock()
+// GetMessages reads the public chat without taking any lock.
+func (pub *PublicRepos) GetMessages(limit, offset int, order entities.Order) ([]entities.Message, error) {
+	paginationMessages, err := pageMessages(pub.db.PublicChat.Messages(), limit, offset, order)
+	if err != nil {
+		return nil, err
+	}
 
-	data := pub.db.Get(constant.PublicChatKey)
+	return paginationMessages, nil
+}
 
-	publicMessages, ok := data.(model.PublicChat)
-	if !ok {
-		return nil, errIncorrectType
+func (pub *PublicRepos) GetAttachment(id string) (entities.Attachment, entities.Message, error) {
+	defer pub.db.Lock(pub.db.PublicAttachments.ForRead())()
+
+	return lookupAttachment(pub.db.PublicAttachments.Get(), id)
+}
+
+func (pub *PublicRepos) SearchMessages(q entities.SearchQuery) ([]entities.SearchResult, error) {
+	defer pub.db.Lock(pub.db.PublicSearchIndex.ForRead())()
+
+	index := pub.db.PublicSearchIndex.Get()
+	messages := pub.db.PublicChat.Messages()
+
+	results := make([]entities.SearchResult, 0)
+	for _, posting := range candidates(index, q.Terms) {
+		m, ok := findMessage(messages, posting.MessageID)
+		if !ok {
+			continue
+		}
+
+		if result, ok := matchMessage(q, publicChatKey, m); ok {
+			results = append(results, result)
+		}
 	}
 
-	paginationMessages, err := pagination.Pagination(publicMessages.Messages, limit, offset)
-	if err != nil {
-		return nil, err
+	return firstResults(results, q.Limit), nil
+}
+
+// indexAttachments dates the attachments of the message and returns the
+// change adding them to their table.
+func indexAttachments(m *entities.Message) model.AddAttachments {
+	attachments := make([]entities.Attachment, len(m.Attachments))
+	change := model.AddAttachments{Attachments: make([]model.AttachmentModel, len(m.Attachments))}
+
+	for i, attachment := range m.Attachments {
+		attachment.CreatedAt = m.CreatedAt
+		attachments[i] = attachment
+		change.Attachments[i] = model.AttachmentModel{
+			Attachment: attachment,
+			MessageID:  m.ID,
+			Sender:     m.Sender,
+			Recipient:  m.Recipient,
+		}
 	}
 
-	return paginationMessages, nil
+	m.Attachments = attachments
+
+	return change
+}
+
+func lookupAttachment(table model.AttachmentTable, id string) (entities.Attachment, entities.Message, error) {
+	val, ok := table.Table[id]
+	if !ok {
+		return entities.Attachment{}, entities.Message{}, entities.ErrAttachmentNotFound
+	}
+
+	return val.Attachment, entities.Message{ID: val.MessageID, Sender: val.Sender, Recipient: val.Recipient}, nil
 }
@@ -1,23 +1,21 @@
 package repos
 
 import (
-	"github.com/golang/mock/gomock"
+	"testing"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
 	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
-	"github.com/vavelour/chat/internal/repository/inmemorydb/model/constant"
-	mock_repos "github.com/vavelour/chat/internal/repository/inmemorydb/repos/mocks"
-	"testing"
+	"github.com/vavelour/chat/pkg/pagination"
 )
 
 func TestPublicRepos_GetMessages(t *testing.T) {
-	type mockBehavior func(m *mock_repos.MockMemoryDB, limit, offset int)
-
 	testTable := []struct {
 		name             string
 		limit            int
 		offset           int
-		mockBehavior     mockBehavior
+		stored           []entities.Message
 		expectedMessages []entities.Message
 		expectedError    error
 	}{
@@ -25,52 +23,36 @@ func TestPublicRepos_GetMessages(t *testing.T) {
 			name:   "ok",
 			limit:  2,
 			offset: 0,
-			mockBehavior: func(m *mock_repos.MockMemoryDB, limit, offset int) {
-				m.EXPECT().Get(constant.PublicChatKey).Return(model.PublicChat{Messages: []entities.Message{
-					{
-						Sender:  "tester",
-						Content: "hello, world!",
-					},
-					{
-						Sender:  "user",
-						Content: "hello, tester!",
-					},
-				}})
+			stored: []entities.Message{
+				{ID: 1, Sender: "tester", Content: "hello, world!"},
+				{ID: 2, Sender: "user", Content: "hello, tester!"},
+				{ID: 3, Sender: "valera", Content: "hi"},
 			},
 			expectedMessages: []entities.Message{
-				{
-					Sender:  "tester",
-					Content: "hello, world!",
-				},
-				{
-					Sender:  "user",
-					Content: "hello, tester!",
-				},
+				{ID: 1, Sender: "tester", Content: "hello, world!"},
+				{ID: 2, Sender: "user", Content: "hello, tester!"},
 			},
 			expectedError: nil,
 		},
 		{
-			name:   "incorrect_type",
-			limit:  1,
-			offset: 0,
-			mockBehavior: func(m *mock_repos.MockMemoryDB, limit, offset int) {
-				m.EXPECT().Get(constant.PublicChatKey).Return(errIncorrectType)
-			},
+			name:             "empty",
+			limit:            1,
+			offset:           0,
+			stored:           nil,
 			expectedMessages: nil,
-			expectedError:    errIncorrectType,
+			expectedError:    pagination.ErrOffsetRange,
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			db := inmemorydb.NewDB()
+			repo := NewPublicRepos(db)
 
-			mockDB := mock_repos.NewMockMemoryDB(ctrl)
-			repo := NewPublicRepos(mockDB)
+			for _, m := range testCase.stored {
+				db.PublicChat.Append(m)
+			}
 
-			testCase.mockBehavior(mockDB, testCase.limit, testCase.offset)
-
-			messages, err := repo.GetMessages(testCase.limit, testCase.offset)
+			messages, err := repo.GetMessages(testCase.limit, testCase.offset, entities.OrderOldestFirst)
 			assert.Equal(t, testCase.expectedError, err)
 			assert.Equal(t, testCase.expectedMessages, messages)
 		})
@@ -78,47 +60,124 @@ func TestPublicRepos_GetMessages(t *testing.T) {
 }
 
 func TestPublicRepos_InsertMessage(t *testing.T) {
-	type mockBehavior func(m *mock_repos.MockMemoryDB, mess entities.Message)
-
 	testTable := []struct {
-		name            string
-		expectedMessage entities.Message
-		mockBehavior    mockBehavior
-		expectedError   error
+		name    string
+		message entities.Message
+		seed    func(db *inmemorydb.MemoryDB)
+		check   func(t *testing.T, db *inmemorydb.MemoryDB)
 	}{
 		{
-			name:            "ok",
-			expectedMessage: entities.Message{Sender: "tester", Content: "hello, world!"},
-			mockBehavior: func(m *mock_repos.MockMemoryDB, mess entities.Message) {
-				m.EXPECT().Get(constant.PublicChatKey).Return(model.PublicChat{Messages: []entities.Message{{Sender: "tester", Content: "hello, world!"}}})
-				m.EXPECT().Insert(constant.PublicChatKey, gomock.Any()).Do(func(key string, data interface{}) {
-					messages, _ := data.(model.PublicChat)
-					assert.Equal(t, mess, messages.Messages[0])
-				})
+			name:    "ok",
+			message: entities.Message{Sender: "tester", Content: "hello, world!"},
+			seed: func(db *inmemorydb.MemoryDB) {
+				db.Webhooks.Set(model.WebhookStore{Webhooks: map[int]entities.Webhook{
+					1: {ID: 1, Events: []entities.WebhookEventType{entities.EventMessageCreated}, Active: true},
+					2: {ID: 2, Events: []entities.WebhookEventType{entities.EventMessageCreated}, Active: false},
+				}})
+			},
+			check: func(t *testing.T, db *inmemorydb.MemoryDB) {
+				messages := db.PublicChat.Messages()
+				assert.Len(t, messages, 1)
+				assert.Equal(t, 1, messages[0].ID)
+				assert.Equal(t, "hello, world!", messages[0].Content)
+				assert.False(t, messages[0].CreatedAt.IsZero())
+
+				index := db.PublicSearchIndex.Get()
+				assert.Equal(t, []model.PostingModel{{MessageID: 1}}, index.Postings["hello"])
+				assert.Equal(t, []model.PostingModel{{MessageID: 1}}, index.Postings["world"])
+
+				store := db.Webhooks.Get()
+				assert.Len(t, store.Deliveries, 1)
+				assert.Equal(t, 1, store.Deliveries[0].Webhook.ID)
+				assert.Equal(t, entities.EventMessageCreated, store.Deliveries[0].Event.Type)
+				assert.Equal(t, "hello, world!", store.Deliveries[0].Event.Message.Content)
+				assert.Equal(t, entities.DeliveryPending, store.Deliveries[0].Status)
 			},
-			expectedError: nil,
 		},
 		{
-			name:            "incorrect_type",
-			expectedMessage: entities.Message{Sender: "tester", Content: "hello, world!"},
-			mockBehavior: func(m *mock_repos.MockMemoryDB, mess entities.Message) {
-				m.EXPECT().Get(constant.PublicChatKey).Return("invalid type")
+			name:    "ok_with_attachments",
+			message: entities.Message{Sender: "tester", Attachments: []entities.Attachment{{ID: "a1", FileName: "cat.png", ContentType: "image/png", Size: 42}}},
+			seed:    func(db *inmemorydb.MemoryDB) {},
+			check: func(t *testing.T, db *inmemorydb.MemoryDB) {
+				attachments := db.PublicAttachments.Get()
+				assert.Equal(t, 1, attachments.Table["a1"].MessageID)
+				assert.Equal(t, "tester", attachments.Table["a1"].Sender)
+				assert.Equal(t, "cat.png", attachments.Table["a1"].Attachment.FileName)
+
+				messages := db.PublicChat.Messages()
+				assert.Equal(t, "a1", messages[0].Attachments[0].ID)
+				assert.Equal(t, messages[0].CreatedAt, messages[0].Attachments[0].CreatedAt)
+			},
+		},
+		{
+			name:    "ok_with_mentions",
+			message: entities.Message{Sender: "tester", Content: "hi @valera", Mentions: []string{"valera"}},
+			seed: func(db *inmemorydb.MemoryDB) {
+				db.Notifications.Shard("valera").Set([]entities.Notification{{ID: 1, Kind: entities.NotificationPrivateMessage, Read: true}})
+			},
+			check: func(t *testing.T, db *inmemorydb.MemoryDB) {
+				notifications, _ := db.Notifications.Shard("valera").Get()
+				assert.Len(t, notifications, 2)
+				assert.Equal(t, 2, notifications[1].ID)
+				assert.Equal(t, entities.NotificationMention, notifications[1].Kind)
+				assert.Equal(t, "hi @valera", notifications[1].Content)
 			},
-			expectedError: errIncorrectType,
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			db := inmemorydb.NewDB()
+			repo := NewPublicRepos(db)
+
+			testCase.seed(db)
+
+			err := repo.InsertMessage(testCase.message)
+			assert.NoError(t, err)
 
-			mockDB := mock_repos.NewMockMemoryDB(ctrl)
-			repo := NewPublicRepos(mockDB)
+			testCase.check(t, db)
+		})
+	}
+}
+
+func TestPublicRepos_GetAttachment(t *testing.T) {
+	attachment := entities.Attachment{ID: "a1", FileName: "cat.png", ContentType: "image/png", Size: 42}
+
+	testTable := []struct {
+		name               string
+		id                 string
+		data               model.AttachmentTable
+		expectedAttachment entities.Attachment
+		expectedMessage    entities.Message
+		expectedError      error
+	}{
+		{
+			name: "ok",
+			id:   "a1",
+			data: model.AttachmentTable{Table: map[string]model.AttachmentModel{
+				"a1": {Attachment: attachment, MessageID: 3, Sender: "tester"},
+			}},
+			expectedAttachment: attachment,
+			expectedMessage:    entities.Message{ID: 3, Sender: "tester"},
+			expectedError:      nil,
+		},
+		{
+			name:          "not_found",
+			id:            "a2",
+			data:          model.AttachmentTable{Table: map[string]model.AttachmentModel{}},
+			expectedError: entities.ErrAttachmentNotFound,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewPublicRepos(db)
 
-			testCase.mockBehavior(mockDB, testCase.expectedMessage)
+			db.PublicAttachments.Set(testCase.data)
 
-			err := repo.InsertMessage(testCase.expectedMessage)
+			attachment, message, err := repo.GetAttachment(testCase.id)
 			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedAttachment, attachment)
+			assert.Equal(t, testCase.expectedMessage, message)
 		})
 	}
 }
@@ -0,0 +1,174 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+)
+
+type ReminderRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewReminderRepos(db *inmemorydb.MemoryDB) *ReminderRepos {
+	return &ReminderRepos{db: db}
+}
+
+// InsertReminder saves the reminder and registers its sender as a bot with
+// the given password, unless the bot exists already.
+func (r *ReminderRepos) InsertReminder(rem entities.Reminder, senderPassword string) (entities.Reminder, error) {
+	defer r.db.Lock(r.db.Users.ForWrite(), r.db.Bots.ForWrite(), r.db.Reminders.ForWrite())()
+
+	store := r.db.Reminders.Get()
+
+	if err := registerBot(r.db, rem.Sender, senderPassword); err != nil {
+		return entities.Reminder{}, err
+	}
+
+	store.NextID++
+	rem.ID = store.NextID
+	rem.CreatedAt = time.Now()
+	store.Reminders[rem.ID] = rem
+
+	if err := r.db.Reminders.Set(store); err != nil {
+		return entities.Reminder{}, err
+	}
+
+	return rem, nil
+}
+
+func (r *ReminderRepos) GetReminders() ([]entities.Reminder, error) {
+	defer r.db.Lock(r.db.Reminders.ForRead())()
+
+	store := r.db.Reminders.Get()
+
+	reminders := make([]entities.Reminder, 0, len(store.Reminders))
+	for _, rem := range store.Reminders {
+		reminders = append(reminders, rem)
+	}
+
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i].ID < reminders[j].ID
+	})
+
+	return reminders, nil
+}
+
+func (r *ReminderRepos) GetReminder(id int) (entities.Reminder, error) {
+	defer r.db.Lock(r.db.Reminders.ForRead())()
+
+	store := r.db.Reminders.Get()
+
+	rem, ok := store.Reminders[id]
+	if !ok {
+		return entities.Reminder{}, entities.ErrReminderNotFound
+	}
+
+	return rem, nil
+}
+
+func (r *ReminderRepos) PauseReminder(id int) (entities.Reminder, error) {
+	return r.update(id, func(rem *entities.Reminder) {
+		rem.Paused = true
+	})
+}
+
+// ResumeReminder turns the reminder back on from nextRunAt, so the runs
+// missed while it was paused are not posted.
+func (r *ReminderRepos) ResumeReminder(id int, nextRunAt time.Time) (entities.Reminder, error) {
+	return r.update(id, func(rem *entities.Reminder) {
+		rem.Paused = false
+		rem.NextRunAt = nextRunAt
+	})
+}
+
+func (r *ReminderRepos) DeleteReminder(id int) error {
+	defer r.db.Lock(r.db.Reminders.ForWrite())()
+
+	store := r.db.Reminders.Get()
+
+	if _, ok := store.Reminders[id]; !ok {
+		return entities.ErrReminderNotFound
+	}
+
+	delete(store.Reminders, id)
+
+	return r.db.Reminders.Set(store)
+}
+
+// GetDueReminders returns up to limit active reminders due at now, the
+// longest overdue first.
+func (r *ReminderRepos) GetDueReminders(now time.Time, limit int) ([]entities.Reminder, error) {
+	defer r.db.Lock(r.db.Reminders.ForRead())()
+
+	store := r.db.Reminders.Get()
+
+	due := make([]entities.Reminder, 0)
+	for _, rem := range store.Reminders {
+		if !rem.Paused && !rem.NextRunAt.After(now) {
+			due = append(due, rem)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextRunAt.Equal(due[j].NextRunAt) {
+			return due[i].NextRunAt.Before(due[j].NextRunAt)
+		}
+
+		return due[i].ID < due[j].ID
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+// AdvanceReminder moves the reminder from the run due at to the next one,
+// if it is still due at that time and active. It reports whether it did,
+// so of several runners only the one that advanced the reminder posts it.
+func (r *ReminderRepos) AdvanceReminder(id int, due, next time.Time, posted bool) (bool, error) {
+	defer r.db.Lock(r.db.Reminders.ForWrite())()
+
+	store := r.db.Reminders.Get()
+
+	rem, ok := store.Reminders[id]
+	if !ok || rem.Paused || !rem.NextRunAt.Equal(due) {
+		return false, nil
+	}
+
+	rem.NextRunAt = next
+	if posted {
+		rem.LastRunAt = due
+	}
+	store.Reminders[id] = rem
+
+	if err := r.db.Reminders.Set(store); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *ReminderRepos) update(id int, f func(rem *entities.Reminder)) (entities.Reminder, error) {
+	defer r.db.Lock(r.db.Reminders.ForWrite())()
+
+	store := r.db.Reminders.Get()
+
+	rem, ok := store.Reminders[id]
+	if !ok {
+		return entities.Reminder{}, entities.ErrReminderNotFound
+	}
+
+	f(&rem)
+	store.Reminders[id] = rem
+
+	if err := r.db.Reminders.Set(store); err != nil {
+		return entities.Reminder{}, err
+	}
+
+	return rem, nil
+}
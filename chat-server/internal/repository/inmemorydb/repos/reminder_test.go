@@ -0,0 +1,64 @@
+package repos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+func TestReminderRepos_AdvanceReminder(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	next := now.Add(time.Hour)
+
+	db := inmemorydb.NewDB()
+	db.Reminders.Set(model.ReminderStore{Reminders: map[int]entities.Reminder{
+		1: {ID: 1, NextRunAt: now.Add(-time.Minute)},
+		2: {ID: 2, NextRunAt: now.Add(-time.Hour)},
+		3: {ID: 3, NextRunAt: now.Add(time.Minute)},
+		4: {ID: 4, NextRunAt: now.Add(-2 * time.Hour), Paused: true},
+	}, NextID: 4})
+
+	repo := NewReminderRepos(db)
+
+	due, err := repo.GetDueReminders(now, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, reminderIDs(due))
+
+	advanced, err := repo.AdvanceReminder(2, now.Add(-time.Hour), next, true)
+	assert.NoError(t, err)
+	assert.True(t, advanced)
+
+	// The run was advanced already, e.g. by another server.
+	advanced, err = repo.AdvanceReminder(2, now.Add(-time.Hour), next, true)
+	assert.NoError(t, err)
+	assert.False(t, advanced)
+
+	advanced, err = repo.AdvanceReminder(4, now.Add(-2*time.Hour), next, true)
+	assert.NoError(t, err)
+	assert.False(t, advanced)
+
+	rem, err := repo.GetReminder(2)
+	assert.NoError(t, err)
+	assert.Equal(t, next, rem.NextRunAt)
+	assert.Equal(t, now.Add(-time.Hour), rem.LastRunAt)
+
+	due, err = repo.GetDueReminders(now, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, reminderIDs(due))
+
+	_, err = repo.PauseReminder(5)
+	assert.Equal(t, entities.ErrReminderNotFound, err)
+}
+
+func reminderIDs(reminders []entities.Reminder) []int {
+	ids := make([]int, 0, len(reminders))
+	for _, rem := range reminders {
+		ids = append(ids, rem.ID)
+	}
+
+	return ids
+}
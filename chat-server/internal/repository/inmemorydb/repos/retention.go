@@ -0,0 +1,353 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"github.com/vavelour/chat/internal/service/mapper"
+)
+
+// RetentionRepos removes the messages older than the retention policies
+// allow. Every batch is removed under the locks of the chats it touches
+// only, so the writers of the other chats never wait for it; the readers of
+// the public chat do not wait at all.
+type RetentionRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewRetentionRepos(db *inmemorydb.MemoryDB) *RetentionRepos {
+	return &RetentionRepos{db: db}
+}
+
+// GetPublicMessagesBefore returns up to limit public messages sent before
+// the time by users not on hold, the oldest first.
+func (r *RetentionRepos) GetPublicMessagesBefore(before time.Time, limit int) ([]entities.Message, error) {
+	held := r.heldUsers()
+
+	result := make([]entities.Message, 0)
+	for _, m := range r.db.PublicChat.Messages() {
+		if len(result) == limit || !m.CreatedAt.Before(before) {
+			break
+		}
+
+		if !held[m.Sender] {
+			result = append(result, m)
+		}
+	}
+
+	return result, nil
+}
+
+// DeletePublicMessages removes the public messages with the IDs which are
+// still sent before the time by users not on hold, and returns how many it
+// removed and the IDs of their attachments.
+func (r *RetentionRepos) DeletePublicMessages(ids []int, before time.Time) (int, []string, error) {
+	// The mentions of a message never change, so the notifications to lock
+	// are known before the lock.
+	var mentions []string
+	for _, id := range ids {
+		if m, ok := findMessage(r.db.PublicChat.Messages(), id); ok {
+			mentions = append(mentions, m.Mentions...)
+		}
+	}
+
+	locks := append(notificationLocks(r.db, mentions...),
+		r.db.PublicChat.ForWrite(),
+		r.db.PublicAttachments.ForWrite(),
+		r.db.PublicSearchIndex.ForWrite(),
+		r.db.LegalHolds.ForRead())
+	defer r.db.Lock(locks...)()
+
+	held := r.db.LegalHolds.Get().Table
+	messages := r.db.PublicChat.Messages()
+
+	due := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if m, ok := findMessage(messages, id); ok && m.CreatedAt.Before(before) && held[m.Sender].IsZero() {
+			due = append(due, id)
+		}
+	}
+
+	if len(due) == 0 {
+		return 0, nil, nil
+	}
+
+	removed, err := r.db.PublicChat.Remove(due)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var attachments []string
+	for _, m := range removed {
+		for _, attachment := range m.Attachments {
+			attachments = append(attachments, attachment.ID)
+		}
+
+		if err := r.db.PublicSearchIndex.Update(unindexMessage(model.MembersPrivateChatModel{}, m)); err != nil {
+			return 0, nil, err
+		}
+
+		if err := forgetMentions(r.db, m); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if len(attachments) > 0 {
+		if err := r.db.PublicAttachments.Update(model.RemoveAttachments{IDs: attachments}); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return len(removed), attachments, nil
+}
+
+// GetPrivateMessagesBefore returns up to limit private messages sent
+// before the time in the conversations of users not on hold, the oldest
+// first.
+func (r *RetentionRepos) GetPrivateMessagesBefore(before time.Time, limit int) ([]entities.Message, error) {
+	held := r.heldUsers()
+
+	result := make([]entities.Message, 0)
+	for _, members := range r.db.PrivateChats.Keys() {
+		if held[members.User1] || held[members.User2] {
+			continue
+		}
+
+		result = append(result, r.privateMessagesBefore(members, before, limit)...)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+
+		return result[i].ID < result[j].ID
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// privateMessagesBefore returns up to limit messages of the conversation
+// sent before the time, under the lock of its shard.
+func (r *RetentionRepos) privateMessagesBefore(members model.MembersPrivateChatModel, before time.Time, limit int) []entities.Message {
+	shard, ok := r.db.PrivateChats.Lookup(members)
+	if !ok {
+		return nil
+	}
+
+	defer r.db.Lock(shard.ForRead())()
+
+	chat, _ := shard.Get()
+	now := time.Now()
+
+	var result []entities.Message
+	for _, m := range chat.Messages {
+		if len(result) == limit || !m.CreatedAt.Before(before) {
+			break
+		}
+
+		result = append(result, visibleMessage(m, now))
+	}
+
+	return result
+}
+
+// DeletePrivateMessages removes the private messages which are still sent
+// before the time in the conversations of users not on hold, and returns
+// how many it removed and the IDs of their attachments. A conversation left
+// without messages leaves the inboxes and the message requests of both users.
+func (r *RetentionRepos) DeletePrivateMessages(messages []entities.Message, before time.Time) (int, []string, error) {
+	due := make(map[model.MembersPrivateChatModel][]int)
+	locks := []inmemorydb.Access{
+		r.db.ConversationIndex.ForWrite(),
+		r.db.MessageRequests.ForWrite(),
+		r.db.ExpiringMessages.ForWrite(),
+		r.db.PrivateAttachments.ForWrite(),
+		r.db.PrivateSearchIndex.ForWrite(),
+		r.db.LegalHolds.ForRead(),
+	}
+
+	shards := make(map[model.MembersPrivateChatModel]*inmemorydb.Shard[model.MembersPrivateChatModel, model.PrivateChat])
+	for _, m := range messages {
+		members := mapper.MessageToMembersPrivateChat(m)
+		if shard, ok := r.db.PrivateChats.Lookup(members); ok && shards[members] == nil {
+			shards[members] = shard
+			locks = append(locks, shard.ForWrite())
+			locks = append(locks, notificationLocks(r.db, members.User1, members.User2)...)
+		}
+
+		due[members] = append(due[members], m.ID)
+	}
+	defer r.db.Lock(locks...)()
+
+	held := r.db.LegalHolds.Get().Table
+	index := r.db.ConversationIndex.Get()
+	requests := r.db.MessageRequests.Get()
+	expiring := r.db.ExpiringMessages.Get()
+
+	var (
+		removed     int
+		attachments []string
+	)
+	for members, shard := range shards {
+		if !held[members.User1].IsZero() || !held[members.User2].IsZero() {
+			continue
+		}
+
+		remove := make(map[int]bool, len(due[members]))
+		for _, id := range due[members] {
+			remove[id] = true
+		}
+
+		chat, _ := shard.Get()
+		kept := make([]entities.Message, 0, len(chat.Messages))
+		for _, m := range chat.Messages {
+			if !remove[m.ID] || !m.CreatedAt.Before(before) {
+				kept = append(kept, m)
+				continue
+			}
+
+			for _, attachment := range m.Attachments {
+				attachments = append(attachments, attachment.ID)
+			}
+
+			delete(expiring.Table, model.PrivateMessageRefModel{Members: members, MessageID: m.ID})
+
+			if err := r.db.PrivateSearchIndex.Update(unindexMessage(members, m)); err != nil {
+				return 0, nil, err
+			}
+
+			if err := forgetPrivateMessage(r.db, m); err != nil {
+				return 0, nil, err
+			}
+
+			chat.LastID = max(chat.LastID, m.ID)
+			removed++
+		}
+
+		if len(kept) == len(chat.Messages) {
+			continue
+		}
+
+		chat.Messages = kept
+		if err := shard.Set(chat); err != nil {
+			return 0, nil, err
+		}
+
+		if len(kept) == 0 {
+			index.Table[members.User1] = removePartner(index.Table[members.User1], members.User2)
+			index.Table[members.User2] = removePartner(index.Table[members.User2], members.User1)
+			requests.Table[members.User1] = removePartner(requests.Table[members.User1], members.User2)
+			requests.Table[members.User2] = removePartner(requests.Table[members.User2], members.User1)
+		}
+	}
+
+	if err := r.db.ConversationIndex.Set(index); err != nil {
+		return 0, nil, err
+	}
+
+	if err := r.db.MessageRequests.Set(requests); err != nil {
+		return 0, nil, err
+	}
+
+	if err := r.db.ExpiringMessages.Set(expiring); err != nil {
+		return 0, nil, err
+	}
+
+	if len(attachments) > 0 {
+		if err := r.db.PrivateAttachments.Update(model.RemoveAttachments{IDs: attachments}); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return removed, attachments, nil
+}
+
+// PlaceLegalHold puts the user on hold. A user already on hold keeps the
+// time the hold was first placed.
+func (r *RetentionRepos) PlaceLegalHold(username string) error {
+	defer r.db.Lock(r.db.Users.ForRead(), r.db.LegalHolds.ForWrite())()
+
+	if _, ok := r.db.Users.Get().Table[username]; !ok {
+		return entities.ErrUserNotFound
+	}
+
+	holds := r.db.LegalHolds.Get()
+	if _, ok := holds.Table[username]; ok {
+		return nil
+	}
+
+	holds.Table[username] = time.Now()
+
+	return r.db.LegalHolds.Set(holds)
+}
+
+func (r *RetentionRepos) LiftLegalHold(username string) error {
+	defer r.db.Lock(r.db.LegalHolds.ForWrite())()
+
+	holds := r.db.LegalHolds.Get()
+	if _, ok := holds.Table[username]; !ok {
+		return entities.ErrLegalHoldNotFound
+	}
+
+	delete(holds.Table, username)
+
+	return r.db.LegalHolds.Set(holds)
+}
+
+// GetLegalHolds returns the users on hold in the order of their names.
+func (r *RetentionRepos) GetLegalHolds() ([]entities.LegalHold, error) {
+	defer r.db.Lock(r.db.LegalHolds.ForRead())()
+
+	holds := make([]entities.LegalHold, 0, len(r.db.LegalHolds.Get().Table))
+	for username, placedAt := range r.db.LegalHolds.Get().Table {
+		holds = append(holds, entities.LegalHold{Username: username, PlacedAt: placedAt})
+	}
+
+	sort.Slice(holds, func(i, j int) bool { return holds[i].Username < holds[j].Username })
+
+	return holds, nil
+}
+
+// heldUsers returns the set of the users on hold.
+func (r *RetentionRepos) heldUsers() map[string]bool {
+	defer r.db.Lock(r.db.LegalHolds.ForRead())()
+
+	held := make(map[string]bool, len(r.db.LegalHolds.Get().Table))
+	for username := range r.db.LegalHolds.Get().Table {
+		held[username] = true
+	}
+
+	return held
+}
+
+// forgetPrivateMessage removes the content of the removed message from the
+// notification of its recipient. The caller holds the locks of
+// notificationLocks for them.
+func forgetPrivateMessage(db *inmemorydb.MemoryDB, m entities.Message) error {
+	if m.Recipient == m.Sender {
+		return nil
+	}
+
+	shard := db.Notifications.Shard(m.Recipient)
+
+	list, ok := shard.Get()
+	if !ok {
+		return nil
+	}
+
+	for i, n := range list {
+		if n.Kind == entities.NotificationPrivateMessage && n.Sender == m.Sender && n.MessageID == m.ID {
+			list[i].Content = ""
+		}
+	}
+
+	return shard.Set(list)
+}
@@ -0,0 +1,139 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+)
+
+type ScheduledMessageRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewScheduledMessageRepos(db *inmemorydb.MemoryDB) *ScheduledMessageRepos {
+	return &ScheduledMessageRepos{db: db}
+}
+
+func (r *ScheduledMessageRepos) InsertScheduledMessage(m entities.ScheduledMessage) (entities.ScheduledMessage, error) {
+	defer r.db.Lock(r.db.ScheduledMessages.ForWrite())()
+
+	store := r.db.ScheduledMessages.Get()
+
+	store.NextID++
+	m.ID = store.NextID
+	m.Status = entities.ScheduledPending
+	m.CreatedAt = time.Now()
+	store.Messages[m.ID] = m
+
+	if err := r.db.ScheduledMessages.Set(store); err != nil {
+		return entities.ScheduledMessage{}, err
+	}
+
+	return m, nil
+}
+
+// GetScheduledMessages returns the messages of the sender which are not
+// sent yet, the next to go first.
+func (r *ScheduledMessageRepos) GetScheduledMessages(sender string, limit, offset int) ([]entities.ScheduledMessage, error) {
+	defer r.db.Lock(r.db.ScheduledMessages.ForRead())()
+
+	store := r.db.ScheduledMessages.Get()
+
+	messages := make([]entities.ScheduledMessage, 0)
+	for _, m := range store.Messages {
+		if m.Sender == sender && m.Status != entities.ScheduledSent {
+			messages = append(messages, m)
+		}
+	}
+
+	sortScheduled(messages)
+
+	if offset >= len(messages) {
+		return messages[:0], nil
+	}
+
+	messages = messages[offset:]
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return messages, nil
+}
+
+// DeleteScheduledMessage cancels a pending message or dismisses a failed one.
+// Messages which are being sent or were sent are not found.
+func (r *ScheduledMessageRepos) DeleteScheduledMessage(sender string, id int) error {
+	defer r.db.Lock(r.db.ScheduledMessages.ForWrite())()
+
+	store := r.db.ScheduledMessages.Get()
+
+	m, ok := store.Messages[id]
+	if !ok || m.Sender != sender || (m.Status != entities.ScheduledPending && m.Status != entities.ScheduledFailed) {
+		return entities.ErrScheduledMessageNotFound
+	}
+
+	delete(store.Messages, id)
+
+	return r.db.ScheduledMessages.Set(store)
+}
+
+// ClaimScheduledMessages marks up to limit due messages as being sent and
+// returns them. A claimed message is never returned again.
+func (r *ScheduledMessageRepos) ClaimScheduledMessages(now time.Time, limit int) ([]entities.ScheduledMessage, error) {
+	defer r.db.Lock(r.db.ScheduledMessages.ForWrite())()
+
+	store := r.db.ScheduledMessages.Get()
+
+	due := make([]entities.ScheduledMessage, 0)
+	for _, m := range store.Messages {
+		if m.Status == entities.ScheduledPending && !m.SendAt.After(now) {
+			due = append(due, m)
+		}
+	}
+
+	sortScheduled(due)
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].Status = entities.ScheduledSending
+		store.Messages[due[i].ID] = due[i]
+	}
+
+	if err := r.db.ScheduledMessages.Set(store); err != nil {
+		return nil, err
+	}
+
+	return due, nil
+}
+
+func (r *ScheduledMessageRepos) FinishScheduledMessage(id int, status entities.ScheduledStatus, lastError string) error {
+	defer r.db.Lock(r.db.ScheduledMessages.ForWrite())()
+
+	store := r.db.ScheduledMessages.Get()
+
+	m, ok := store.Messages[id]
+	if !ok {
+		return entities.ErrScheduledMessageNotFound
+	}
+
+	m.Status = status
+	m.LastError = lastError
+	store.Messages[id] = m
+
+	return r.db.ScheduledMessages.Set(store)
+}
+
+func sortScheduled(messages []entities.ScheduledMessage) {
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].SendAt.Equal(messages[j].SendAt) {
+			return messages[i].SendAt.Before(messages[j].SendAt)
+		}
+
+		return messages[i].ID < messages[j].ID
+	})
+}
@@ -0,0 +1,59 @@
+package repos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+func TestScheduledMessageRepos_ClaimScheduledMessages(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	db := inmemorydb.NewDB()
+	db.ScheduledMessages.Set(model.ScheduledMessageStore{Messages: map[int]entities.ScheduledMessage{
+		1: {ID: 1, Sender: "valera", SendAt: now.Add(-time.Minute), Status: entities.ScheduledPending},
+		2: {ID: 2, Sender: "valera", SendAt: now.Add(-time.Hour), Status: entities.ScheduledPending},
+		3: {ID: 3, Sender: "valera", SendAt: now.Add(time.Minute), Status: entities.ScheduledPending},
+		4: {ID: 4, Sender: "valera", SendAt: now.Add(-2 * time.Hour), Status: entities.ScheduledSending},
+		5: {ID: 5, Sender: "dima", SendAt: now, Status: entities.ScheduledPending},
+	}, NextID: 5})
+
+	repo := NewScheduledMessageRepos(db)
+
+	claimed, err := repo.ClaimScheduledMessages(now, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, scheduledIDs(claimed))
+
+	claimed, err = repo.ClaimScheduledMessages(now, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5}, scheduledIDs(claimed))
+
+	claimed, err = repo.ClaimScheduledMessages(now, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	assert.Equal(t, entities.ErrScheduledMessageNotFound, repo.DeleteScheduledMessage("valera", 1))
+	assert.Equal(t, entities.ErrScheduledMessageNotFound, repo.DeleteScheduledMessage("dima", 3))
+	assert.NoError(t, repo.DeleteScheduledMessage("valera", 3))
+
+	assert.NoError(t, repo.FinishScheduledMessage(1, entities.ScheduledSent, ""))
+	assert.NoError(t, repo.FinishScheduledMessage(2, entities.ScheduledFailed, "this user is not exist"))
+
+	list, err := repo.GetScheduledMessages("valera", 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 2}, scheduledIDs(list))
+	assert.Equal(t, "this user is not exist", list[1].LastError)
+}
+
+func scheduledIDs(messages []entities.ScheduledMessage) []int {
+	ids := make([]int, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+
+	return ids
+}
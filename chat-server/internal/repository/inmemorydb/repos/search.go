@@ -0,0 +1,113 @@
+package repos
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"github.com/vavelour/chat/pkg/textsearch"
+)
+
+const publicChatKey = "public"
+
+// indexMessage returns the change adding the postings of the message to the
+// index.
+func indexMessage(members model.MembersPrivateChatModel, m entities.Message) model.AddPosting {
+	return model.AddPosting{Words: messageWords(m), Posting: model.PostingModel{Members: members, MessageID: m.ID}}
+}
+
+// unindexMessage returns the change removing the postings of the message
+// from the index.
+func unindexMessage(members model.MembersPrivateChatModel, m entities.Message) model.RemovePosting {
+	return model.RemovePosting{Words: messageWords(m), Posting: model.PostingModel{Members: members, MessageID: m.ID}}
+}
+
+// messageWords returns every word of the message once.
+func messageWords(m entities.Message) []string {
+	seen := make(map[string]bool)
+
+	var words []string
+	for _, token := range textsearch.Tokenize(m.Content) {
+		if seen[token.Word] {
+			continue
+		}
+		seen[token.Word] = true
+
+		words = append(words, token.Word)
+	}
+
+	return words
+}
+
+// candidates returns the postings of the most selective term. Every match
+// of the query is among them, but they still have to be checked against the
+// other terms.
+func candidates(index model.SearchIndex, terms []textsearch.Term) []model.PostingModel {
+	var best []model.PostingModel
+
+	for i, term := range terms {
+		var postings []model.PostingModel
+		if term.Prefix && len(term.Words) == 1 {
+			for word, val := range index.Postings {
+				if term.MatchesWord(0, word) {
+					postings = append(postings, val...)
+				}
+			}
+		} else {
+			postings = index.Postings[term.Words[0]]
+		}
+
+		if i == 0 || len(postings) < len(best) {
+			best = postings
+		}
+	}
+
+	return best
+}
+
+// matchMessage checks the message against the whole query and returns the
+// search result for it.
+func matchMessage(q entities.SearchQuery, chat string, m entities.Message) (entities.SearchResult, bool) {
+	result := entities.SearchResult{Message: m, Cursor: entities.SearchCursor{CreatedAt: m.CreatedAt, Chat: chat, ID: m.ID}}
+
+	switch {
+	case q.Sender != "" && q.Sender != m.Sender:
+		return result, false
+	case !q.From.IsZero() && m.CreatedAt.Before(q.From):
+		return result, false
+	case !q.To.IsZero() && !m.CreatedAt.Before(q.To):
+		return result, false
+	case q.After != nil && !q.After.Before(result.Cursor):
+		return result, false
+	}
+
+	return result, textsearch.Match(textsearch.Tokenize(m.Content), q.Terms)
+}
+
+// firstResults sorts the results in the search order and keeps the first
+// limit of them.
+func firstResults(results []entities.SearchResult, limit int) []entities.SearchResult {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Cursor.Before(results[j].Cursor)
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+func findMessage(messages []entities.Message, id int) (entities.Message, bool) {
+	i := sort.Search(len(messages), func(i int) bool { return messages[i].ID >= id })
+	if i == len(messages) || messages[i].ID != id {
+		return entities.Message{}, false
+	}
+
+	return messages[i], true
+}
+
+func privateChatKey(members model.MembersPrivateChatModel) string {
+	return strings.Join([]string{"private", members.User1, members.User2}, ":")
+}
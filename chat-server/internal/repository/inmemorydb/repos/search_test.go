@@ -0,0 +1,163 @@
+package repos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"github.com/vavelour/chat/pkg/textsearch"
+)
+
+func searchQuery(t *testing.T, text string, limit int) entities.SearchQuery {
+	terms, err := textsearch.Parse(text)
+	assert.NoError(t, err)
+
+	return entities.SearchQuery{Text: text, Terms: terms, Limit: limit}
+}
+
+func TestPublicRepos_SearchMessages(t *testing.T) {
+	base := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	messages := []entities.Message{
+		{ID: 1, Sender: "tester", Content: "Deploy the release today", CreatedAt: base},
+		{ID: 2, Sender: "valera", Content: "release notes are ready", CreatedAt: base.Add(time.Hour)},
+		{ID: 3, Sender: "tester", Content: "the release is deployed", CreatedAt: base.Add(24 * time.Hour)},
+	}
+
+	index := model.SearchIndex{Postings: make(map[string][]model.PostingModel)}
+	for _, m := range messages {
+		indexMessage(model.MembersPrivateChatModel{}, m).Apply(&index)
+	}
+
+	after := entities.SearchCursor{CreatedAt: base.Add(24 * time.Hour), Chat: publicChatKey, ID: 3}
+
+	testTable := []struct {
+		name          string
+		query         func(q entities.SearchQuery) entities.SearchQuery
+		text          string
+		expectedIDs   []int
+		expectedError error
+	}{
+		{
+			name:        "word",
+			text:        "release",
+			expectedIDs: []int{3, 2, 1},
+		},
+		{
+			name:        "prefix",
+			text:        "deploy*",
+			expectedIDs: []int{3, 1},
+		},
+		{
+			name:        "phrase",
+			text:        `"release notes"`,
+			expectedIDs: []int{2},
+		},
+		{
+			name:        "all_terms",
+			text:        "release today",
+			expectedIDs: []int{1},
+		},
+		{
+			name: "sender",
+			text: "release",
+			query: func(q entities.SearchQuery) entities.SearchQuery {
+				q.Sender = "tester"
+				return q
+			},
+			expectedIDs: []int{3, 1},
+		},
+		{
+			name: "dates",
+			text: "release",
+			query: func(q entities.SearchQuery) entities.SearchQuery {
+				q.From = base.Add(time.Minute)
+				q.To = base.Add(2 * time.Hour)
+				return q
+			},
+			expectedIDs: []int{2},
+		},
+		{
+			name: "cursor",
+			text: "release",
+			query: func(q entities.SearchQuery) entities.SearchQuery {
+				q.After = &after
+				return q
+			},
+			expectedIDs: []int{2, 1},
+		},
+		{
+			name:        "no_matches",
+			text:        "rollback",
+			expectedIDs: []int{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewPublicRepos(db)
+
+			db.PublicSearchIndex.Set(index)
+			for _, m := range messages {
+				db.PublicChat.Append(m)
+			}
+
+			q := searchQuery(t, testCase.text, 10)
+			if testCase.query != nil {
+				q = testCase.query(q)
+			}
+
+			results, err := repo.SearchMessages(q)
+			assert.Equal(t, testCase.expectedError, err)
+
+			ids := make([]int, 0)
+			for _, result := range results {
+				ids = append(ids, result.Message.ID)
+			}
+			assert.Equal(t, testCase.expectedIDs, ids)
+		})
+	}
+}
+
+func TestPrivateRepos_SearchMessages(t *testing.T) {
+	base := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+
+	ours := model.MembersPrivateChatModel{User1: "tester", User2: "valera"}
+	theirs := model.MembersPrivateChatModel{User1: "user", User2: "valera"}
+
+	chats := map[model.MembersPrivateChatModel]model.PrivateChat{
+		ours: {Messages: []entities.Message{
+			{ID: 1, Sender: "tester", Recipient: "valera", Content: "secret plan", CreatedAt: base},
+			{ID: 2, Sender: "valera", Recipient: "tester", Content: "plan approved", CreatedAt: base.Add(time.Hour)},
+		}},
+		theirs: {Messages: []entities.Message{
+			{ID: 1, Sender: "user", Recipient: "valera", Content: "another plan", CreatedAt: base.Add(2 * time.Hour)},
+		}},
+	}
+
+	db := inmemorydb.NewDB()
+	repo := NewPrivateRepos(db)
+
+	index := model.SearchIndex{Postings: make(map[string][]model.PostingModel)}
+	for members, chat := range chats {
+		for _, m := range chat.Messages {
+			indexMessage(members, m).Apply(&index)
+		}
+		db.PrivateChats.Shard(members).Set(chat)
+	}
+	db.PrivateSearchIndex.Set(index)
+
+	results, err := repo.SearchMessages("tester", searchQuery(t, "plan", 1))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Message.ID)
+	assert.Equal(t, "private:tester:valera", results[0].Cursor.Chat)
+
+	results, err = repo.SearchMessages("user", searchQuery(t, "secret", 10))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
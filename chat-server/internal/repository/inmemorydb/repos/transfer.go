@@ -0,0 +1,177 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+	"github.com/vavelour/chat/internal/service/mapper"
+)
+
+// TransferRepos reads the data of the database page by page for an export,
+// and writes an export back. The imported messages keep the time they were
+// sent at, and nobody is notified of them.
+type TransferRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewTransferRepos(db *inmemorydb.MemoryDB) *TransferRepos {
+	return &TransferRepos{db: db}
+}
+
+// GetUsers returns up to limit users whose names go after the given one, in
+// the order of their names, with the hashes of their passwords.
+func (r *TransferRepos) GetUsers(after string, limit int) ([]entities.User, error) {
+	defer r.db.Lock(r.db.Users.ForRead())()
+
+	users := make([]entities.User, 0)
+	for username, user := range r.db.Users.Get().Table {
+		if username > after {
+			users = append(users, user)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+
+	if len(users) > limit {
+		users = users[:limit]
+	}
+
+	return users, nil
+}
+
+// GetPublicMessages returns up to limit public messages with IDs above the
+// given one, the oldest first.
+func (r *TransferRepos) GetPublicMessages(afterID, limit int) ([]entities.Message, error) {
+	result := make([]entities.Message, 0)
+	for _, m := range r.db.PublicChat.Messages() {
+		if len(result) == limit {
+			break
+		}
+
+		if m.ID > afterID {
+			result = append(result, m)
+		}
+	}
+
+	return result, nil
+}
+
+// GetPartners returns the partners of the user's private chats, the message
+// requests included, in the order of their names.
+func (r *TransferRepos) GetPartners(username string) ([]string, error) {
+	defer r.db.Lock(r.db.ConversationIndex.ForRead(), r.db.MessageRequests.ForRead())()
+
+	partners := append([]string{}, r.db.ConversationIndex.Get().Table[username]...)
+	partners = append(partners, r.db.MessageRequests.Get().Table[username]...)
+	sort.Strings(partners)
+
+	return partners, nil
+}
+
+// GetPrivateMessages returns up to limit messages of the private chat of
+// the two users with IDs above the given one, the oldest first. An expired
+// message comes without its content.
+func (r *TransferRepos) GetPrivateMessages(user1, user2 string, afterID, limit int) ([]entities.Message, error) {
+	result := make([]entities.Message, 0)
+
+	shard, ok := r.db.PrivateChats.Lookup(mapper.StringToMembersPrivateChat(user1, user2))
+	if !ok {
+		return result, nil
+	}
+
+	defer r.db.Lock(shard.ForRead())()
+
+	chat, _ := shard.Get()
+	now := time.Now()
+
+	for _, m := range chat.Messages {
+		if len(result) == limit {
+			break
+		}
+
+		if m.ID > afterID {
+			result = append(result, visibleMessage(m, now))
+		}
+	}
+
+	return result, nil
+}
+
+// ImportUser adds the user with the hash of their password as it is.
+func (r *TransferRepos) ImportUser(user entities.User) error {
+	return NewAuthRepos(r.db).InsertUser(user.Username, user.Password)
+}
+
+// ImportPublicMessages appends the messages to the public chat in the given
+// order. They get IDs of this database.
+func (r *TransferRepos) ImportPublicMessages(messages []entities.Message) error {
+	defer r.db.Lock(r.db.Users.ForRead(), r.db.PublicChat.ForWrite(), r.db.PublicSearchIndex.ForWrite())()
+
+	users := r.db.Users.Get().Table
+	for _, m := range messages {
+		if _, ok := users[m.Sender]; !ok {
+			return entities.ErrUserNotFound
+		}
+	}
+
+	for _, m := range messages {
+		m.ID = r.db.PublicChat.NextID()
+
+		if err := r.db.PublicSearchIndex.Update(indexMessage(model.MembersPrivateChatModel{}, m)); err != nil {
+			return err
+		}
+
+		if err := r.db.PublicChat.Append(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportPrivateMessages appends the messages to their private chats in the
+// given order. They get IDs of this database, and fill the inboxes and the
+// message requests the way sending them did.
+func (r *TransferRepos) ImportPrivateMessages(messages []entities.Message) error {
+	for _, m := range messages {
+		if err := r.importPrivateMessage(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *TransferRepos) importPrivateMessage(m entities.Message) error {
+	members := mapper.MessageToMembersPrivateChat(m)
+	shard := r.db.PrivateChats.Shard(members)
+
+	defer r.db.Lock(
+		r.db.Users.ForRead(),
+		shard.ForWrite(),
+		r.db.ConversationIndex.ForWrite(),
+		r.db.Contacts.ForWrite(),
+		r.db.MessageRequests.ForWrite(),
+		r.db.ExpiringMessages.ForWrite(),
+		r.db.PrivateSearchIndex.ForWrite(),
+	)()
+
+	users := r.db.Users.Get().Table
+	if _, ok := users[m.Sender]; !ok {
+		return entities.ErrUserNotFound
+	}
+
+	if _, ok := users[m.Recipient]; !ok {
+		return entities.ErrUserNotFound
+	}
+
+	chat, _ := shard.Get()
+	m.ID = nextMessageID(chat)
+
+	return storePrivateMessage(r.db, shard, members, m)
+}
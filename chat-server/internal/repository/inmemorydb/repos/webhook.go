@@ -0,0 +1,248 @@
+package repos
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+type WebhookRepos struct {
+	db *inmemorydb.MemoryDB
+}
+
+func NewWebhookRepos(db *inmemorydb.MemoryDB) *WebhookRepos {
+	return &WebhookRepos{db: db}
+}
+
+func (r *WebhookRepos) InsertWebhook(w entities.Webhook) (entities.Webhook, error) {
+	defer r.db.Lock(r.db.Webhooks.ForWrite())()
+
+	store := r.db.Webhooks.Get()
+
+	store.NextWebhookID++
+	w.ID = store.NextWebhookID
+	w.CreatedAt = time.Now()
+	store.Webhooks[w.ID] = w
+
+	if err := r.db.Webhooks.Set(store); err != nil {
+		return entities.Webhook{}, err
+	}
+
+	return w, nil
+}
+
+func (r *WebhookRepos) GetWebhooks() ([]entities.Webhook, error) {
+	defer r.db.Lock(r.db.Webhooks.ForRead())()
+
+	store := r.db.Webhooks.Get()
+
+	webhooks := make([]entities.Webhook, 0, len(store.Webhooks))
+	for _, w := range store.Webhooks {
+		webhooks = append(webhooks, w)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepos) GetWebhook(id int) (entities.Webhook, error) {
+	defer r.db.Lock(r.db.Webhooks.ForRead())()
+
+	store := r.db.Webhooks.Get()
+
+	w, ok := store.Webhooks[id]
+	if !ok {
+		return entities.Webhook{}, entities.ErrWebhookNotFound
+	}
+
+	return w, nil
+}
+
+// DeleteWebhook removes the webhook together with its deliveries.
+func (r *WebhookRepos) DeleteWebhook(id int) error {
+	defer r.db.Lock(r.db.Webhooks.ForWrite())()
+
+	store := r.db.Webhooks.Get()
+
+	if _, ok := store.Webhooks[id]; !ok {
+		return entities.ErrWebhookNotFound
+	}
+
+	delete(store.Webhooks, id)
+
+	deliveries := store.Deliveries[:0]
+	for _, d := range store.Deliveries {
+		if d.Webhook.ID != id {
+			deliveries = append(deliveries, d)
+		}
+	}
+	store.Deliveries = deliveries
+
+	return r.db.Webhooks.Set(store)
+}
+
+// SetWebhookActive enables or disables the webhook. Enabling it starts the
+// failure count over.
+func (r *WebhookRepos) SetWebhookActive(id int, active bool) error {
+	defer r.db.Lock(r.db.Webhooks.ForWrite())()
+
+	store := r.db.Webhooks.Get()
+
+	w, ok := store.Webhooks[id]
+	if !ok {
+		return entities.ErrWebhookNotFound
+	}
+
+	w.Active = active
+	if active {
+		w.Failures = 0
+	}
+	store.Webhooks[id] = w
+
+	return r.db.Webhooks.Set(store)
+}
+
+// GetDeliveries returns the delivery log of the webhook, newest first.
+func (r *WebhookRepos) GetDeliveries(webhookID, limit, offset int) ([]entities.WebhookDelivery, error) {
+	defer r.db.Lock(r.db.Webhooks.ForRead())()
+
+	store := r.db.Webhooks.Get()
+
+	if _, ok := store.Webhooks[webhookID]; !ok {
+		return nil, entities.ErrWebhookNotFound
+	}
+
+	deliveries := make([]entities.WebhookDelivery, 0, limit)
+	for i := len(store.Deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if store.Deliveries[i].Webhook.ID != webhookID {
+			continue
+		}
+
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		deliveries = append(deliveries, store.Deliveries[i])
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDeliveries returns up to limit pending deliveries which are due and
+// belong to active webhooks. They are leased until now+lease, so a worker
+// that dies before recording the attempt only delays them.
+func (r *WebhookRepos) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]entities.WebhookDelivery, error) {
+	defer r.db.Lock(r.db.Webhooks.ForWrite())()
+
+	store := r.db.Webhooks.Get()
+
+	var leased []entities.WebhookDelivery
+	claimed := make([]entities.WebhookDelivery, 0, limit)
+	for _, d := range store.Deliveries {
+		if len(claimed) == limit {
+			break
+		}
+
+		w, ok := store.Webhooks[d.Webhook.ID]
+		if d.Status != entities.DeliveryPending || d.NextAttemptAt.After(now) || !ok || !w.Active {
+			continue
+		}
+
+		d.NextAttemptAt = now.Add(lease)
+		leased = append(leased, d)
+
+		d.Webhook = w
+		claimed = append(claimed, d)
+	}
+
+	if len(leased) == 0 {
+		return claimed, nil
+	}
+
+	if err := r.db.Webhooks.Update(model.UpdateDeliveries{Deliveries: leased}); err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// RecordAttempt saves the outcome of a delivery attempt. A failed attempt
+// counts against the webhook, which is disabled after disableAfter failures
+// in a row; a successful one resets the count.
+func (r *WebhookRepos) RecordAttempt(d entities.WebhookDelivery, disableAfter int) error {
+	defer r.db.Lock(r.db.Webhooks.ForWrite())()
+
+	store := r.db.Webhooks.Get()
+
+	var change model.UpdateDeliveries
+
+	i := sort.Search(len(store.Deliveries), func(i int) bool { return store.Deliveries[i].ID >= d.ID })
+	if i < len(store.Deliveries) && store.Deliveries[i].ID == d.ID {
+		val := store.Deliveries[i]
+		val.Status = d.Status
+		val.Attempts = d.Attempts
+		val.NextAttemptAt = d.NextAttemptAt
+		val.ResponseStatus = d.ResponseStatus
+		val.LastError = d.LastError
+		val.UpdatedAt = time.Now()
+
+		change.Deliveries = append(change.Deliveries, val)
+	}
+
+	if w, ok := store.Webhooks[d.Webhook.ID]; ok {
+		if d.Status == entities.DeliverySucceeded {
+			w.Failures = 0
+		} else {
+			w.Failures++
+			if w.Failures >= disableAfter {
+				w.Active = false
+			}
+		}
+
+		change.Webhooks = append(change.Webhooks, w)
+	}
+
+	return r.db.Webhooks.Update(change)
+}
+
+// enqueueWebhookEvent queues a delivery of the event to every webhook
+// subscribed to it. Nothing is stored when there are no subscribers. The
+// caller holds the write lock of the webhooks.
+func enqueueWebhookEvent(db *inmemorydb.MemoryDB, event entities.WebhookEventType, channel string, m entities.Message) error {
+	store := db.Webhooks.Get()
+
+	var subscribers []int
+	for id, w := range store.Webhooks {
+		if w.Subscribed(event, channel) {
+			subscribers = append(subscribers, id)
+		}
+	}
+
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	sort.Ints(subscribers)
+
+	change := model.AddDeliveries{EventID: store.NextEventID + 1}
+	ev := entities.WebhookEvent{ID: change.EventID, Type: event, Channel: channel, Message: m, CreatedAt: m.CreatedAt}
+
+	for i, id := range subscribers {
+		change.Deliveries = append(change.Deliveries, entities.WebhookDelivery{
+			ID:            store.NextDeliveryID + i + 1,
+			Webhook:       entities.Webhook{ID: id},
+			Event:         ev,
+			Status:        entities.DeliveryPending,
+			NextAttemptAt: m.CreatedAt,
+			CreatedAt:     m.CreatedAt,
+			UpdatedAt:     m.CreatedAt,
+		})
+	}
+
+	return db.Webhooks.Update(change)
+}
@@ -0,0 +1,120 @@
+package repos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+var webhookNow = time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+func webhookStore() model.WebhookStore {
+	return model.WebhookStore{
+		Webhooks: map[int]entities.Webhook{
+			1: {ID: 1, URL: "https://example.com/a", Active: true, Failures: 1},
+			2: {ID: 2, URL: "https://example.com/b"},
+		},
+		Deliveries: []entities.WebhookDelivery{
+			{ID: 1, Webhook: entities.Webhook{ID: 1}, Status: entities.DeliverySucceeded},
+			{ID: 2, Webhook: entities.Webhook{ID: 1}, Status: entities.DeliveryPending, NextAttemptAt: webhookNow.Add(-time.Second)},
+			{ID: 3, Webhook: entities.Webhook{ID: 1}, Status: entities.DeliveryPending, NextAttemptAt: webhookNow.Add(time.Second)},
+			{ID: 4, Webhook: entities.Webhook{ID: 2}, Status: entities.DeliveryPending, NextAttemptAt: webhookNow.Add(-time.Second)},
+			{ID: 5, Webhook: entities.Webhook{ID: 1}, Status: entities.DeliveryPending, NextAttemptAt: webhookNow},
+		},
+		NextWebhookID:  2,
+		NextDeliveryID: 5,
+	}
+}
+
+func TestWebhookRepos_ClaimDeliveries(t *testing.T) {
+	testTable := []struct {
+		name        string
+		limit       int
+		expectedIDs []int
+	}{
+		{
+			name:        "due_and_active",
+			limit:       10,
+			expectedIDs: []int{2, 5},
+		},
+		{
+			name:        "limit",
+			limit:       1,
+			expectedIDs: []int{2},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewWebhookRepos(db)
+
+			db.Webhooks.Set(webhookStore())
+
+			deliveries, err := repo.ClaimDeliveries(webhookNow, time.Minute, testCase.limit)
+			assert.NoError(t, err)
+			assert.Equal(t, webhookNow.Add(time.Minute), db.Webhooks.Get().Deliveries[1].NextAttemptAt)
+
+			ids := make([]int, 0)
+			for _, val := range deliveries {
+				ids = append(ids, val.ID)
+				assert.Equal(t, "https://example.com/a", val.Webhook.URL)
+			}
+			assert.Equal(t, testCase.expectedIDs, ids)
+		})
+	}
+}
+
+func TestWebhookRepos_RecordAttempt(t *testing.T) {
+	testTable := []struct {
+		name             string
+		status           entities.DeliveryStatus
+		disableAfter     int
+		expectedFailures int
+		expectedActive   bool
+	}{
+		{
+			name:             "succeeded",
+			status:           entities.DeliverySucceeded,
+			disableAfter:     2,
+			expectedFailures: 0,
+			expectedActive:   true,
+		},
+		{
+			name:             "failed",
+			status:           entities.DeliveryPending,
+			disableAfter:     3,
+			expectedFailures: 2,
+			expectedActive:   true,
+		},
+		{
+			name:             "disabled",
+			status:           entities.DeliveryFailed,
+			disableAfter:     2,
+			expectedFailures: 2,
+			expectedActive:   false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			db := inmemorydb.NewDB()
+			repo := NewWebhookRepos(db)
+
+			db.Webhooks.Set(webhookStore())
+
+			err := repo.RecordAttempt(entities.WebhookDelivery{ID: 2, Webhook: entities.Webhook{ID: 1}, Status: testCase.status, Attempts: 1}, testCase.disableAfter)
+			assert.NoError(t, err)
+
+			store := db.Webhooks.Get()
+			assert.Equal(t, testCase.status, store.Deliveries[1].Status)
+			assert.Equal(t, 1, store.Deliveries[1].Attempts)
+			assert.Equal(t, testCase.expectedFailures, store.Webhooks[1].Failures)
+			assert.Equal(t, testCase.expectedActive, store.Webhooks[1].Active)
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package inmemorydb
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sync"
+)
+
+// Sharded is a table split by key into shards with locks of their own, so
+// the writers of different keys do not wait for each other. Every shard
+// holds a single value of type V.
+type Sharded[K comparable, V any] struct {
+	name string
+	rank int
+	db   *MemoryDB
+
+	mu     sync.RWMutex
+	shards map[K]*Shard[K, V]
+	seq    int
+}
+
+// Shard is the part of a Sharded table stored under one key.
+type Shard[K comparable, V any] struct {
+	lockable
+	owner *Sharded[K, V]
+	key   K
+	value V
+	ok    bool
+	// seq is the number of the last record of the shard.
+	seq uint64
+}
+
+// shardRecord is the log record of a shard: its whole value or a change of
+// it, numbered like the records of a table.
+type shardRecord[K comparable, V any] struct {
+	Key    K
+	Value  V
+	Seq    uint64
+	Change Change[V]
+}
+
+// newSharded adds the table with the types of the changes of its shards.
+func newSharded[K comparable, V any](db *MemoryDB, name string, changes ...Change[V]) *Sharded[K, V] {
+	for _, c := range changes {
+		gob.Register(c)
+	}
+
+	s := &Sharded[K, V]{name: name, db: db, shards: make(map[K]*Shard[K, V])}
+	s.rank = db.register(name, nil, s)
+
+	return s
+}
+
+// Shard returns the shard of the key, adding an empty one if there is none.
+func (s *Sharded[K, V]) Shard(key K) *Shard[K, V] {
+	if shard, ok := s.Lookup(key); ok {
+		return shard
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.shard(key)
+}
+
+// Lookup returns the shard of the key, if there is one.
+func (s *Sharded[K, V]) Lookup(key K) (*Shard[K, V], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	shard, ok := s.shards[key]
+
+	return shard, ok
+}
+
+// Keys returns the keys of the shards, in no particular order.
+func (s *Sharded[K, V]) Keys() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]K, 0, len(s.shards))
+	for key := range s.shards {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (s *Sharded[K, V]) shard(key K) *Shard[K, V] {
+	shard, ok := s.shards[key]
+	if !ok {
+		s.seq++
+		shard = &Shard[K, V]{lockable: lockable{rank: s.rank, seq: s.seq}, owner: s, key: key}
+		shard.backup = shard.backupValue
+		s.shards[key] = shard
+	}
+
+	return shard
+}
+
+// Get returns the value of the shard, and false if it was never set. A value
+// with maps or slices may be changed under the write lock only, and the
+// change is stored with Set or Update.
+func (sh *Shard[K, V]) Get() (V, bool) {
+	return sh.value, sh.ok
+}
+
+// Set replaces the value of the shard and writes it to the log. The value
+// is kept as it was when the write fails.
+func (sh *Shard[K, V]) Set(value V) error {
+	if err := sh.log(shardRecord[K, V]{Key: sh.key, Value: value}); err != nil {
+		return err
+	}
+
+	sh.value, sh.ok = value, true
+
+	return nil
+}
+
+// Update applies the change to the value of the shard, which counts as set
+// afterwards, and writes the change to the log. The change is not applied
+// when the write fails.
+func (sh *Shard[K, V]) Update(c Change[V]) error {
+	if err := sh.log(shardRecord[K, V]{Key: sh.key, Change: c}); err != nil {
+		return err
+	}
+
+	c.Apply(&sh.value)
+	sh.ok = true
+
+	return nil
+}
+
+func (sh *Shard[K, V]) log(record shardRecord[K, V]) error {
+	db := sh.owner.db
+	if db.journal == nil {
+		return nil
+	}
+
+	record.Seq = sh.seq + 1
+	if err := db.write(sh.owner.name, record); err != nil {
+		return err
+	}
+	sh.seq = record.Seq
+
+	return nil
+}
+
+func (sh *Shard[K, V]) backupValue() (func() error, error) {
+	seq := sh.seq
+
+	if !sh.ok {
+		return func() error {
+			var empty V
+			sh.value, sh.ok, sh.seq = empty, false, seq
+
+			return nil
+		}, nil
+	}
+
+	value, err := encode(sh.value)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		var restored V
+		if err := decode(value, &restored); err != nil {
+			return err
+		}
+
+		sh.value, sh.seq = restored, seq
+
+		return nil
+	}, nil
+}
+
+func (s *Sharded[K, V]) replay(value []byte) error {
+	var record shardRecord[K, V]
+	if err := decode(value, &record); err != nil {
+		return err
+	}
+
+	shard := s.shard(record.Key)
+
+	// The records written before they were numbered are applied anyway.
+	if record.Seq != 0 {
+		if record.Seq <= shard.seq {
+			return nil
+		}
+		shard.seq = record.Seq
+	}
+
+	if record.Change != nil {
+		record.Change.Apply(&shard.value)
+	} else {
+		shard.value = record.Value
+	}
+	shard.ok = true
+
+	return nil
+}
+
+// dump encodes the records of the shards set, each under the lock of its
+// shard and after a true, then a false.
+func (s *Sharded[K, V]) dump() ([]byte, error) {
+	s.mu.RLock()
+	shards := make([]*Shard[K, V], 0, len(s.shards))
+	for _, shard := range s.shards {
+		shards = append(shards, shard)
+	}
+	s.mu.RUnlock()
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+
+	for _, shard := range shards {
+		if err := shard.dump(enc); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.Encode(false); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (sh *Shard[K, V]) dump(enc *gob.Encoder) error {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	if !sh.ok {
+		return nil
+	}
+
+	if err := enc.Encode(true); err != nil {
+		return err
+	}
+
+	return enc.Encode(shardRecord[K, V]{Key: sh.key, Value: sh.value, Seq: sh.seq})
+}
+
+func (s *Sharded[K, V]) restore(value []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(value))
+
+	for {
+		var more bool
+		if err := dec.Decode(&more); err != nil {
+			return err
+		}
+
+		if !more {
+			return nil
+		}
+
+		var record shardRecord[K, V]
+		if err := dec.Decode(&record); err != nil {
+			return err
+		}
+
+		shard := s.shard(record.Key)
+		shard.value, shard.ok, shard.seq = record.Value, true, record.Seq
+	}
+}
@@ -0,0 +1,347 @@
+package inmemorydb
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sync"
+)
+
+// lockable is the lock of a table or of a shard. The locks are always taken
+// in the order of their rank, then of their seq, so two writers can never
+// wait for each other.
+type lockable struct {
+	mu   sync.RWMutex
+	rank int
+	seq  int
+	// backup copies the data under the lock for a unit of work to put back.
+	// It is nil for a table whose changes the unit undoes one by one.
+	backup func() (restore func() error, err error)
+}
+
+// Access asks Lock for the lock of a table, for reading or for writing.
+type Access struct {
+	l     *lockable
+	write bool
+}
+
+func (l *lockable) ForRead() Access {
+	return Access{l: l}
+}
+
+func (l *lockable) ForWrite() Access {
+	return Access{l: l, write: true}
+}
+
+func (l *lockable) before(other *lockable) bool {
+	if l.rank != other.rank {
+		return l.rank < other.rank
+	}
+
+	return l.seq < other.seq
+}
+
+// Lock takes the locks of the tables in their order, whatever the order they
+// are given in, and returns the function releasing them. A table asked for
+// both reading and writing is locked for writing.
+//
+// The data of a table may only be read under its lock and changed under
+// its write lock. An operation takes every lock it needs with a single
+// Lock, never one after another.
+func Lock(access ...Access) (unlock func()) {
+	// The lists are short: an insertion sort is the cheapest here and, unlike
+	// sort.Slice, allocates nothing.
+	locked := make([]Access, 0, len(access))
+	for _, a := range access {
+		i := len(locked)
+		for i > 0 && a.l.before(locked[i-1].l) {
+			i--
+		}
+
+		if i > 0 && locked[i-1].l == a.l {
+			locked[i-1].write = locked[i-1].write || a.write
+			continue
+		}
+
+		locked = append(locked, Access{})
+		copy(locked[i+1:], locked[i:])
+		locked[i] = a
+	}
+
+	for _, a := range locked {
+		if a.write {
+			a.l.mu.Lock()
+		} else {
+			a.l.mu.RLock()
+		}
+	}
+
+	return func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			if locked[i].write {
+				locked[i].l.mu.Unlock()
+			} else {
+				locked[i].l.mu.RUnlock()
+			}
+		}
+	}
+}
+
+// Lock takes the locks like the function Lock. Outside of a unit of work it
+// first waits for the unit running, if there is one; within a unit it backs
+// up the data it locks for writing, so that the unit can be rolled back.
+func (db *MemoryDB) Lock(access ...Access) (unlock func()) {
+	if db.unit != nil {
+		unlock := Lock(access...)
+		db.unit.backup(access)
+
+		return unlock
+	}
+
+	db.units.RLock()
+	unlockTables := Lock(access...)
+
+	return func() {
+		unlockTables()
+		db.units.RUnlock()
+	}
+}
+
+// storedTable is a table as the journal sees it.
+type storedTable interface {
+	// replay applies a record written to the log.
+	replay(value []byte) error
+	// dump encodes the whole table for a snapshot, and restore loads it.
+	dump() ([]byte, error)
+	restore(value []byte) error
+}
+
+// Change is a change of a table of type T which is logged on its own, so
+// that a write costs as much as the change rather than the whole table. The
+// types of the changes of a table are given to newTable, which registers
+// them with gob.
+type Change[T any] interface {
+	Apply(data *T)
+}
+
+// Reversible is a change which a unit of work undoes on its own: Undo,
+// called before the change is applied, returns the function putting back
+// what the change replaces.
+type Reversible[T any] interface {
+	Change[T]
+	Undo(data *T) func(data *T)
+}
+
+// tableRecord is the log record of a table: the whole table or a change of
+// it. Seq numbers the records of the table, so that a record the snapshot
+// holds already is not applied twice.
+type tableRecord[T any] struct {
+	Seq    uint64
+	Data   *T
+	Change Change[T]
+}
+
+// tableSnapshot is the snapshot of a table.
+type tableSnapshot[T any] struct {
+	Data T
+	Seq  uint64
+}
+
+// Table is a table of the database held as a single value of type T.
+type Table[T any] struct {
+	lockable
+	name  string
+	db    *MemoryDB
+	empty func() T
+	data  T
+	// seq is the number of the last record of the table.
+	seq uint64
+}
+
+func newTable[T any](db *MemoryDB, name string, empty func() T, changes ...Change[T]) *Table[T] {
+	for _, c := range changes {
+		gob.Register(c)
+	}
+
+	t := &Table[T]{name: name, db: db, empty: empty, data: empty()}
+	t.backup = t.backupData
+	db.register(name, &t.lockable, t)
+
+	return t
+}
+
+// undoChanges makes the units of work undo the changes of the table one by
+// one, rather than copy the whole table when they lock it for writing. The
+// table is then changed through Set and Update only, never in place, and
+// its changes are best Reversible: any other is undone with a copy of the
+// table.
+func (t *Table[T]) undoChanges() *Table[T] {
+	t.backup = nil
+
+	return t
+}
+
+// Get returns the table. Its maps are shared: they may be changed under the
+// write lock only, and the change is stored with Set or Update.
+func (t *Table[T]) Get() T {
+	return t.data
+}
+
+// Set replaces the table and writes it to the log. The table is kept as it
+// was when the write fails.
+func (t *Table[T]) Set(data T) error {
+	old, seq := t.data, t.seq
+
+	if err := t.log(tableRecord[T]{Data: &data}); err != nil {
+		return err
+	}
+
+	t.data = data
+	t.saveUndo(func() error {
+		t.data, t.seq = old, seq
+		return nil
+	})
+
+	return nil
+}
+
+// Update applies the change to the table and writes the change to the log.
+// The change is not applied when the write fails.
+func (t *Table[T]) Update(c Change[T]) error {
+	undo, err := t.undo(c)
+	if err != nil {
+		return err
+	}
+
+	if err := t.log(tableRecord[T]{Change: c}); err != nil {
+		return err
+	}
+
+	c.Apply(&t.data)
+	t.saveUndo(undo)
+
+	return nil
+}
+
+// undo returns the function undoing the change, when a unit of work running
+// undoes the changes of the table one by one.
+func (t *Table[T]) undo(c Change[T]) (func() error, error) {
+	if t.db.active == nil || t.backup != nil {
+		return nil, nil
+	}
+
+	seq := t.seq
+
+	if r, ok := c.(Reversible[T]); ok {
+		undo := r.Undo(&t.data)
+
+		return func() error {
+			undo(&t.data)
+			t.seq = seq
+
+			return nil
+		}, nil
+	}
+
+	return t.backupData()
+}
+
+// saveUndo hands the function undoing the change just made to the unit of
+// work running, when it undoes the changes of the table one by one.
+func (t *Table[T]) saveUndo(undo func() error) {
+	if u := t.db.active; u != nil && t.backup == nil && undo != nil {
+		u.restore = append(u.restore, undo)
+	}
+}
+
+func (t *Table[T]) log(record tableRecord[T]) error {
+	if t.db.journal == nil {
+		return nil
+	}
+
+	record.Seq = t.seq + 1
+	if err := t.db.write(t.name, record); err != nil {
+		return err
+	}
+	t.seq = record.Seq
+
+	return nil
+}
+
+func (t *Table[T]) backupData() (func() error, error) {
+	value, err := encode(tableSnapshot[T]{Data: t.data, Seq: t.seq})
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error { return t.restore(value) }, nil
+}
+
+func (t *Table[T]) replay(value []byte) error {
+	data := t.empty()
+	record := tableRecord[T]{Data: &data}
+	if err := decode(value, &record); err != nil {
+		// The logs written before the changes were numbered hold the bare
+		// table.
+		return t.restoreTable(value)
+	}
+
+	if record.Seq <= t.seq {
+		return nil
+	}
+	t.seq = record.Seq
+
+	if record.Change != nil {
+		record.Change.Apply(&t.data)
+		return nil
+	}
+
+	t.data = data
+
+	return nil
+}
+
+func (t *Table[T]) dump() ([]byte, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return encode(tableSnapshot[T]{Data: t.data, Seq: t.seq})
+}
+
+func (t *Table[T]) restore(value []byte) error {
+	// Gob leaves out empty maps, so they are decoded into the ones of an
+	// empty table rather than left nil.
+	snapshot := tableSnapshot[T]{Data: t.empty()}
+	if err := decode(value, &snapshot); err != nil {
+		// The snapshots written before the changes were numbered hold the
+		// bare table.
+		return t.restoreTable(value)
+	}
+
+	t.data, t.seq = snapshot.Data, snapshot.Seq
+
+	return nil
+}
+
+func (t *Table[T]) restoreTable(value []byte) error {
+	data := t.empty()
+	if err := decode(value, &data); err != nil {
+		return err
+	}
+
+	t.data = data
+
+	return nil
+}
+
+func encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decode(value []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(value)).Decode(v)
+}
@@ -0,0 +1,118 @@
+package inmemorydb
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+func TestLock_Order(t *testing.T) {
+	db := NewDB()
+
+	tester := db.Notifications.Shard("tester")
+	valera := db.Notifications.Shard("valera")
+
+	// Without the ordering, the two sides would wait for each other.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for n := 0; n < 1000; n++ {
+				Lock(db.Users.ForWrite(), db.Contacts.ForWrite(), tester.ForWrite(), valera.ForWrite())()
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for n := 0; n < 1000; n++ {
+				Lock(valera.ForWrite(), tester.ForWrite(), db.Contacts.ForWrite(), db.Users.ForRead())()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestLock_SameTable(t *testing.T) {
+	db := NewDB()
+
+	unlock := Lock(db.Users.ForRead(), db.Users.ForWrite())
+	assert.False(t, db.Users.mu.TryRLock())
+	unlock()
+
+	assert.True(t, db.Users.mu.TryLock())
+	db.Users.mu.Unlock()
+}
+
+func TestMessageLog_Readers(t *testing.T) {
+	db := NewDB()
+
+	db.PublicChat.Append(entities.Message{ID: 1, Content: "hello"})
+	before := db.PublicChat.Messages()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		unlock := Lock(db.PublicChat.ForWrite())
+		defer unlock()
+
+		for id := 2; id <= 100; id++ {
+			db.PublicChat.Append(entities.Message{ID: id})
+		}
+		db.PublicChat.Replace(entities.Message{ID: 1, Deleted: true})
+	}()
+
+	for n := 0; n < 100; n++ {
+		messages := db.PublicChat.Messages()
+		assert.Equal(t, 1, messages[0].ID)
+		_ = append(messages, entities.Message{ID: -1})
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, []entities.Message{{ID: 1, Content: "hello"}}, before)
+
+	messages := db.PublicChat.Messages()
+	assert.Len(t, messages, 100)
+	assert.True(t, messages[0].Deleted)
+	for i, m := range messages {
+		assert.Equal(t, i+1, m.ID)
+	}
+
+	replaced, err := db.PublicChat.Replace(entities.Message{ID: 101})
+	assert.NoError(t, err)
+	assert.False(t, replaced)
+}
+
+func TestSharded_Lookup(t *testing.T) {
+	db := NewDB()
+
+	members := model.MembersPrivateChatModel{User1: "tester", User2: "valera"}
+
+	_, ok := db.PrivateChats.Lookup(members)
+	assert.False(t, ok)
+
+	shard := db.PrivateChats.Shard(members)
+	_, ok = shard.Get()
+	assert.False(t, ok)
+
+	shard.Set(model.PrivateChat{Messages: []entities.Message{{ID: 1}}})
+
+	found, ok := db.PrivateChats.Lookup(members)
+	assert.True(t, ok)
+	assert.Same(t, shard, found)
+
+	chat, ok := found.Get()
+	assert.True(t, ok)
+	assert.Len(t, chat.Messages, 1)
+}
@@ -0,0 +1,106 @@
+package inmemorydb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// unit is a unit of work in progress.
+type unit struct {
+	restore []func() error
+	backups map[*lockable]bool
+	records []unitRecord
+	err     error
+}
+
+// unitRecord is a log record held back until its unit commits.
+type unitRecord struct {
+	name   string
+	record interface{}
+}
+
+// Unit runs fn as a unit of work: its changes are kept all together, or
+// rolled back if fn returns an error or panics. The unit runs alone, every
+// other operation waits for it; the readers of the public chat, who take no
+// lock, see its changes at once.
+//
+// fn gets a view of the database to run the operations of the unit with.
+// The database itself must not be used within fn, or the unit waits for
+// itself. A unit started with a view is part of the unit of the view.
+func (db *MemoryDB) Unit(fn func(tx *MemoryDB) error) (err error) {
+	if db.unit != nil {
+		return fn(db)
+	}
+
+	db.units.Lock()
+	defer db.units.Unlock()
+
+	u := &unit{backups: make(map[*lockable]bool)}
+	db.active = u
+	defer func() { db.active = nil }()
+
+	view := *db
+	view.unit = u
+
+	defer func() {
+		if p := recover(); p != nil {
+			if err := u.rollback(); err != nil {
+				log.Printf("inmemorydb: roll back a unit of work: %s", err)
+			}
+			panic(p)
+		}
+	}()
+
+	if err := fn(&view); err != nil {
+		return errors.Join(err, u.rollback())
+	}
+
+	if u.err != nil {
+		return errors.Join(u.err, u.rollback())
+	}
+
+	// The records are written as one, so a unit whose write fails is rolled
+	// back here and left out of the log after a restart alike.
+	db.active = nil
+	if db.journal != nil && len(u.records) > 0 {
+		if err := db.journal.appendUnit(u.records); err != nil {
+			return errors.Join(fmt.Errorf("inmemorydb: write a unit of work to the log: %w", err), u.rollback())
+		}
+	}
+
+	return nil
+}
+
+// backup backs up the data locked for writing, unless it was backed up by
+// the unit already or the unit undoes its changes one by one. A failed backup
+// fails the unit.
+func (u *unit) backup(access []Access) {
+	for _, a := range access {
+		if !a.write || u.backups[a.l] || a.l.backup == nil {
+			continue
+		}
+
+		restore, err := a.l.backup()
+		if err != nil {
+			u.err = errors.Join(u.err, err)
+			continue
+		}
+
+		u.backups[a.l] = true
+		u.restore = append(u.restore, restore)
+	}
+}
+
+// rollback puts the data back as it was before the unit and drops the log
+// records of the unit.
+func (u *unit) rollback() error {
+	var err error
+	for i := len(u.restore) - 1; i >= 0; i-- {
+		err = errors.Join(err, u.restore[i]())
+	}
+
+	u.records = nil
+
+	return err
+}
@@ -0,0 +1,186 @@
+package inmemorydb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/inmemorydb/model"
+)
+
+var errStop = errors.New("stop")
+
+var members = model.MembersPrivateChatModel{User1: "tester", User2: "valera"}
+
+// change changes a table, a shard and the public chat.
+func change(tx *MemoryDB) {
+	insertUser(tx, "valera")
+
+	shard := tx.PrivateChats.Shard(members)
+	unlock := tx.Lock(shard.ForWrite(), tx.PublicChat.ForWrite())
+	defer unlock()
+
+	shard.Set(model.PrivateChat{Messages: []entities.Message{{ID: 1, Sender: "tester", Recipient: "valera"}}})
+	tx.PublicChat.Append(entities.Message{ID: 1, Sender: "valera", Content: "hi"})
+}
+
+func TestUnit_Commit(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	insertUser(db, "tester")
+	require.NoError(t, db.Unit(func(tx *MemoryDB) error {
+		change(tx)
+		return nil
+	}))
+	closeTestDB(t, db)
+
+	db = openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	assert.ElementsMatch(t, []string{"tester", "valera"}, usernames(db))
+	_, ok := db.PrivateChats.Shard(members).Get()
+	assert.True(t, ok)
+	assert.Len(t, db.PublicChat.Messages(), 1)
+}
+
+func TestUnit_Rollback(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	insertUser(db, "tester")
+
+	err := db.Unit(func(tx *MemoryDB) error {
+		change(tx)
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+
+	check := func(db *MemoryDB) {
+		assert.Equal(t, []string{"tester"}, usernames(db))
+		_, ok := db.PrivateChats.Shard(members).Get()
+		assert.False(t, ok)
+		assert.Empty(t, db.PublicChat.Messages())
+	}
+
+	check(db)
+	closeTestDB(t, db)
+
+	// Nothing of the unit made it to the log either.
+	db = openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	check(db)
+}
+
+func TestUnit_UndoesChanges(t *testing.T) {
+	dir := t.TempDir()
+
+	db := openTestDB(t, dir)
+	insertUser(db, "tester")
+
+	err := db.Unit(func(tx *MemoryDB) error {
+		unlock := tx.Lock(tx.Users.ForWrite(), tx.Privacy.ForWrite())
+		defer unlock()
+
+		require.NoError(t, tx.Users.Update(model.SetUser{User: entities.User{Username: "tester", Password: "456"}}))
+		require.NoError(t, tx.Users.Update(model.SetUser{User: entities.User{Username: "valera", Password: "123"}}))
+		require.NoError(t, tx.Privacy.Update(model.SetPrivacySettings{User: "valera", Settings: entities.DefaultPrivacySettings()}))
+
+		// The tables are not copied, only the values the changes replace.
+		assert.False(t, tx.unit.backups[&db.Users.lockable])
+		assert.False(t, tx.unit.backups[&db.Privacy.lockable])
+
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+
+	check := func(db *MemoryDB) {
+		defer db.Lock(db.Users.ForRead(), db.Privacy.ForRead())()
+
+		assert.Equal(t, map[string]entities.User{
+			"tester": {Username: "tester", Password: "123"},
+			"oleg":   {Username: "oleg", Password: "123"},
+		}, db.Users.Get().Table)
+		assert.Empty(t, db.Privacy.Get().Table)
+	}
+
+	// The changes after the unit go on from the tables it put back.
+	insertUser(db, "oleg")
+	check(db)
+	closeTestDB(t, db)
+
+	db = openTestDB(t, dir)
+	defer closeTestDB(t, db)
+
+	check(db)
+}
+
+func TestUnit_Panic(t *testing.T) {
+	db := NewDB()
+	insertUser(db, "tester")
+
+	assert.PanicsWithValue(t, "stop", func() {
+		db.Unit(func(tx *MemoryDB) error {
+			change(tx)
+			panic("stop")
+		})
+	})
+
+	assert.Equal(t, []string{"tester"}, usernames(db))
+	assert.Empty(t, db.PublicChat.Messages())
+
+	// The locks were let go.
+	insertUser(db, "valera")
+	assert.ElementsMatch(t, []string{"tester", "valera"}, usernames(db))
+}
+
+func TestUnit_Nested(t *testing.T) {
+	db := NewDB()
+
+	err := db.Unit(func(tx *MemoryDB) error {
+		insertUser(tx, "tester")
+
+		return tx.Unit(func(tx *MemoryDB) error {
+			insertUser(tx, "valera")
+			return errStop
+		})
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Empty(t, usernames(db))
+}
+
+func TestUnit_RunsAlone(t *testing.T) {
+	db := NewDB()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go db.Unit(func(tx *MemoryDB) error {
+		insertUser(tx, "tester")
+		close(started)
+		<-release
+
+		return errStop
+	})
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		insertUser(db, "valera")
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("a write went through while the unit was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+
+	assert.Equal(t, []string{"valera"}, usernames(db))
+}
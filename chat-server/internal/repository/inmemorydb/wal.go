@@ -0,0 +1,639 @@
+package inmemorydb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A log or snapshot file is its magic followed by records. A record is the
+// length and the CRC-32C of its payload, then the payload: the key of the
+// table and the gob encoding of the change. A log record holds one message,
+// or a change or the whole value of a table or of a shard, numbered so that
+// the records a snapshot holds already are skipped; a snapshot record holds
+// a whole table. Replaying a record twice does no harm. The records of a unit
+// of work are written as the payload of one record under unitKey, so that
+// they are replayed all together or, when the write was torn, not at all.
+const (
+	walMagic         = "chatwal2"
+	snapshotMagic    = "chatsnp2"
+	walPrefix        = "wal-"
+	walSuffix        = ".log"
+	snapshotPrefix   = "snapshot-"
+	snapshotSuffix   = ".db"
+	tmpSuffix        = ".tmp"
+	recordHeaderSize = 8
+	maxRecordSize    = 1 << 30
+	// unitKey is no key of a table.
+	unitKey = ""
+)
+
+var (
+	errCorruptRecord = errors.New("corrupt record")
+	errUnknownTable  = errors.New("unknown table")
+	errBadMagic      = errors.New("not a file of the chat database")
+	errDiskFull      = errors.New("no space left on the disk")
+
+	// ErrLogFailed is returned by every write after a write to the log
+	// failed: the log misses a change, so it takes no more.
+	ErrLogFailed = errors.New("the write-ahead log failed")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// journal is the write-ahead log of a MemoryDB. The log is split into
+// generations: snapshot N holds the state written to the logs before
+// generation N, and log N holds the records written after it.
+type journal struct {
+	mu     sync.Mutex
+	dir    string
+	policy FsyncPolicy
+	gen    int
+	file   *os.File
+	// records counts the records in the current log.
+	records int
+	dirty   bool
+	// err is the failure of a write, after which the log takes no more.
+	err    error
+	tables map[string]storedTable
+	// space is how many more bytes the log takes before it fails like a
+	// full disk, or negative when there is no such limit.
+	space int64
+}
+
+// openJournal replays the snapshot and the logs in dir into tables and
+// opens the latest log for appending. A torn or corrupt record at the end
+// of the latest log is what a crash in the middle of a write leaves, so it
+// is cut off; a corrupt record anywhere else fails the open.
+func openJournal(dir string, policy FsyncPolicy, tables map[string]storedTable) (*journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	snapshots, logs, err := listGenerations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &journal{dir: dir, policy: policy, tables: tables, space: -1}
+
+	if len(snapshots) > 0 {
+		j.gen = snapshots[len(snapshots)-1]
+		if err := replayFile(filepath.Join(dir, snapshotName(j.gen)), snapshotMagic, j.apply(storedTable.restore)); err != nil {
+			return nil, err
+		}
+	}
+
+	apply := j.apply(storedTable.replay)
+
+	var replay []int
+	for _, gen := range logs {
+		if gen >= j.gen {
+			replay = append(replay, gen)
+		}
+	}
+
+	for i, gen := range replay {
+		path := filepath.Join(dir, walName(gen))
+		if i < len(replay)-1 {
+			if err := replayFile(path, walMagic, apply); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		j.gen = gen
+		if j.records, err = recoverLog(path, apply); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(replay) == 0 {
+		if err := createLog(dir, j.gen); err != nil {
+			return nil, err
+		}
+	}
+
+	j.file, err = os.OpenFile(filepath.Join(dir, walName(j.gen)), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// apply returns the function loading a record into the table of its key,
+// or the records of a unit of work into their tables.
+func (j *journal) apply(load func(t storedTable, value []byte) error) func(key string, value []byte) error {
+	var apply func(key string, value []byte) error
+	apply = func(key string, value []byte) error {
+		if key == unitKey {
+			if _, _, err := readRecords(bytes.NewReader(value), apply); err != nil {
+				return fmt.Errorf("unit of work: %w", err)
+			}
+
+			return nil
+		}
+
+		t, ok := j.tables[key]
+		if !ok {
+			return fmt.Errorf("%w %q", errUnknownTable, key)
+		}
+
+		if err := load(t, value); err != nil {
+			return fmt.Errorf("decode %s: %w", key, err)
+		}
+
+		return nil
+	}
+
+	return apply
+}
+
+// append writes the record of a change of the table stored under key to
+// the log. A record which cannot be encoded leaves the log as it was; once
+// the log itself fails, every append fails with ErrLogFailed.
+func (j *journal) append(key string, record interface{}) error {
+	value, err := encode(record)
+	if err != nil {
+		return err
+	}
+
+	return j.appendRecord(encodeRecord(key, value))
+}
+
+// appendUnit writes the records of a unit of work to the log as a single
+// record: a write which fails or is torn half-way leaves none of them to
+// replay, and fails the log, so nothing is written after the torn record.
+func (j *journal) appendUnit(records []unitRecord) error {
+	var payload []byte
+	for _, r := range records {
+		value, err := encode(r.record)
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.name, err)
+		}
+
+		payload = append(payload, encodeRecord(r.name, value)...)
+	}
+
+	return j.appendRecord(encodeRecord(unitKey, payload))
+}
+
+func (j *journal) appendRecord(record []byte) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err != nil {
+		return j.err
+	}
+
+	if err := j.write(record); err != nil {
+		return j.fail(err)
+	}
+	j.records++
+
+	if j.policy == FsyncAlways {
+		if err := j.file.Sync(); err != nil {
+			return j.fail(err)
+		}
+
+		return nil
+	}
+
+	j.dirty = true
+
+	return nil
+}
+
+// write writes the record to the file, or as much of it as fits in the
+// space left. The caller holds the lock.
+func (j *journal) write(record []byte) error {
+	if j.space >= 0 && int64(len(record)) > j.space {
+		n, _ := j.file.Write(record[:j.space])
+		j.space -= int64(n)
+
+		return errDiskFull
+	}
+
+	n, err := j.file.Write(record)
+	if j.space >= 0 {
+		j.space -= int64(n)
+	}
+
+	return err
+}
+
+// fail keeps the failure of a write, which the later writes return too.
+// The caller holds the lock.
+func (j *journal) fail(err error) error {
+	j.err = fmt.Errorf("%w: %w", ErrLogFailed, err)
+
+	return j.err
+}
+
+// sync flushes the records written since the last sync to the disk.
+func (j *journal) sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if !j.dirty {
+		return nil
+	}
+
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	j.dirty = false
+
+	return nil
+}
+
+// snapshot starts the next generation of the log and writes the tables to
+// the snapshot of that generation. The appends wait only for the switch, not
+// for the snapshot to be written. A change is written to the log under the
+// lock of its table, and every table is dumped under its lock after the
+// switch, so the snapshot holds all the changes of the older logs; the ones
+// of the new log it holds as well are replayed over it harmlessly. Once the
+// snapshot is in place the files of the older generations are removed.
+func (j *journal) snapshot() error {
+	j.mu.Lock()
+
+	if j.records == 0 {
+		j.mu.Unlock()
+		return nil
+	}
+
+	gen := j.gen + 1
+	if err := j.rotate(gen); err != nil {
+		j.mu.Unlock()
+		return err
+	}
+
+	j.mu.Unlock()
+
+	tables := make(map[string][]byte, len(j.tables))
+	for key, t := range j.tables {
+		value, err := t.dump()
+		if err != nil {
+			return fmt.Errorf("dump %s: %w", key, err)
+		}
+
+		tables[key] = value
+	}
+
+	if err := writeSnapshot(j.dir, gen, tables); err != nil {
+		return err
+	}
+
+	return removeGenerationsBefore(j.dir, gen)
+}
+
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Sync(); err != nil {
+		j.file.Close()
+		return err
+	}
+
+	return j.file.Close()
+}
+
+// rotate syncs and closes the current log and opens an empty one of gen.
+func (j *journal) rotate(gen int) error {
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+
+	if err := createLog(j.dir, gen); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Join(j.dir, walName(gen)), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	j.file.Close()
+
+	j.file, j.gen, j.records, j.dirty = file, gen, 0, false
+
+	return nil
+}
+
+func encodeRecord(key string, value []byte) []byte {
+	payload := make([]byte, 0, binary.MaxVarintLen64+len(key)+len(value))
+	payload = binary.AppendUvarint(payload, uint64(len(key)))
+	payload = append(payload, key...)
+	payload = append(payload, value...)
+
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.Checksum(payload, crcTable))
+
+	return append(record, payload...)
+}
+
+// readRecords calls apply for every record read from r, which is past the
+// magic. It returns the number of bytes of good records read and the number
+// of records; errCorruptRecord means a torn or damaged record follows them.
+func readRecords(r io.Reader, apply func(key string, value []byte) error) (int64, int, error) {
+	br := bufio.NewReader(r)
+
+	var offset int64
+	var count int
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, err := io.ReadFull(br, header); errors.Is(err, io.EOF) {
+			return offset, count, nil
+		} else if errors.Is(err, io.ErrUnexpectedEOF) {
+			return offset, count, errCorruptRecord
+		} else if err != nil {
+			return offset, count, err
+		}
+
+		size := binary.LittleEndian.Uint32(header[0:4])
+		if size > maxRecordSize {
+			return offset, count, errCorruptRecord
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return offset, count, errCorruptRecord
+		} else if err != nil {
+			return offset, count, err
+		}
+
+		if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:8]) {
+			return offset, count, errCorruptRecord
+		}
+
+		keyLen, n := binary.Uvarint(payload)
+		if n <= 0 || keyLen > uint64(len(payload)-n) {
+			return offset, count, errCorruptRecord
+		}
+
+		key := string(payload[n : n+int(keyLen)])
+		if err := apply(key, payload[n+int(keyLen):]); err != nil {
+			return offset, count, err
+		}
+
+		offset += int64(recordHeaderSize) + int64(size)
+		count++
+	}
+}
+
+// replayFile applies every record of a file which has to be whole.
+func replayFile(path, magic string, apply func(key string, value []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := readMagic(f, magic); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if _, _, err := readRecords(f, apply); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// recoverLog applies the records of the latest log and cuts off the torn
+// record at its end, if there is one. It returns the number of records
+// kept.
+func recoverLog(path string, apply func(key string, value []byte) error) (int, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if err := readMagic(f, walMagic); errors.Is(err, errCorruptRecord) {
+		// The crash came while the log was created.
+		return 0, rewriteLog(f, path)
+	} else if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	offset, count, err := readRecords(f, apply)
+	if errors.Is(err, errCorruptRecord) {
+		end := int64(len(walMagic)) + offset
+
+		info, statErr := f.Stat()
+		if statErr != nil {
+			return 0, statErr
+		}
+
+		log.Printf("inmemorydb: %s: dropped %d bytes of a torn record after %d records", path, info.Size()-end, count)
+
+		if err := f.Truncate(end); err != nil {
+			return 0, err
+		}
+
+		return count, f.Sync()
+	} else if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func readMagic(r io.Reader, magic string) error {
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, head); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errCorruptRecord
+	} else if err != nil {
+		return err
+	}
+
+	if string(head) != magic {
+		return errBadMagic
+	}
+
+	return nil
+}
+
+func rewriteLog(f *os.File, path string) error {
+	log.Printf("inmemorydb: %s: rewriting a log torn at its creation", path)
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := f.WriteAt([]byte(walMagic), 0); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+func createLog(dir string, gen int) error {
+	path := filepath.Join(dir, walName(gen))
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(walMagic); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// writeSnapshot writes the snapshot to a temporary file and renames it into
+// place, so a snapshot on disk is always whole.
+func writeSnapshot(dir string, gen int, tables map[string][]byte) error {
+	path := filepath.Join(dir, snapshotName(gen))
+
+	f, err := os.Create(path + tmpSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path + tmpSuffix)
+
+	keys := make([]string, 0, len(tables))
+	for key := range tables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := bufio.NewWriter(f)
+	w.WriteString(snapshotMagic)
+	for _, key := range keys {
+		w.Write(encodeRecord(key, tables[key]))
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(path+tmpSuffix, path); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+func removeGenerationsBefore(dir string, gen int) error {
+	snapshots, logs, err := listGenerations(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range snapshots {
+		if g < gen {
+			if err := os.Remove(filepath.Join(dir, snapshotName(g))); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, g := range logs {
+		if g < gen {
+			if err := os.Remove(filepath.Join(dir, walName(g))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// listGenerations returns the generations of the snapshots and of the logs
+// in dir in ascending order. Temporary files left by a crash are removed.
+func listGenerations(dir string) ([]int, []int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var snapshots, logs []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, tmpSuffix) {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		if gen, ok := parseGeneration(name, snapshotPrefix, snapshotSuffix); ok {
+			snapshots = append(snapshots, gen)
+		} else if gen, ok := parseGeneration(name, walPrefix, walSuffix); ok {
+			logs = append(logs, gen)
+		}
+	}
+
+	sort.Ints(snapshots)
+	sort.Ints(logs)
+
+	return snapshots, logs, nil
+}
+
+func parseGeneration(name, prefix, suffix string) (int, bool) {
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return 0, false
+	}
+
+	gen, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix))
+	if err != nil || gen < 0 {
+		return 0, false
+	}
+
+	return gen, true
+}
+
+func walName(gen int) string {
+	return fmt.Sprintf("%s%08d%s", walPrefix, gen, walSuffix)
+}
+
+func snapshotName(gen int) string {
+	return fmt.Sprintf("%s%08d%s", snapshotPrefix, gen, snapshotSuffix)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -1,35 +1,151 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 	_ "github.com/jackc/pgx/v4/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
 	"github.com/vavelour/chat/pkg/database_utils/postgres"
-	"sync"
+	"log"
+	"time"
 )
 
+// maxConnectBackoff caps the pause between two attempts to connect.
+const maxConnectBackoff = 30 * time.Second
+
+// SqlPostgresDB is a pool of connections. The database orders the
+// concurrent statements itself, so nothing here serializes them.
 type SqlPostgresDB struct {
-	mu sync.RWMutex
 	db *sqlx.DB
 }
 
+// Tx is a transaction of SqlPostgresDB, with the same methods to run
+// statements. A statement's rows must be closed before the next one runs.
+type Tx struct {
+	tx *sqlx.Tx
+}
+
+// NewSqlPostgresDB opens the pool and waits for the database, which may
+// start later than the server: it tries cfg.ConnectAttempts times, doubling
+// the pause between the attempts from cfg.ConnectBackoff.
 func NewSqlPostgresDB(cfg postgres.SqlPostgresConfig) (*SqlPostgresDB, error) {
-	db, err := sqlx.Open("pgx", fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.DBName, cfg.Password, cfg.SSLMode))
+	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.DBName, cfg.Password, cfg.SSLMode)
+	if cfg.StatementTimeout > 0 {
+		// The unknown parameters go to the server as settings of the session.
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+	}
+
+	db, err := sqlx.Open("pgx", dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Ping()
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.MaxIdleConns > 0 {
+		// Zero would keep no idle connections at all.
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	attempts := cfg.ConnectAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		err = db.Ping()
+		if err == nil {
+			break
+		}
+
+		if attempt == attempts {
+			db.Close()
+			return nil, err
+		}
+
+		log.Printf("postgres: attempt %d of %d to connect failed: %s, retrying in %s", attempt, attempts, err, backoff)
+		time.Sleep(backoff)
+
+		backoff = min(2*backoff, maxConnectBackoff)
+	}
+
+	return &SqlPostgresDB{db: db}, nil
+}
+
+func (db *SqlPostgresDB) Get(query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := db.db.Queryx(query, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SqlPostgresDB{db: db}, nil
+	return rows, nil
+}
+
+func (db *SqlPostgresDB) Insert(query string, args ...interface{}) error {
+	_, err := db.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Tx runs fn in a transaction, which is committed if fn returns nil and
+// rolled back if it returns an error or panics.
+func (db *SqlPostgresDB) Tx(fn func(tx *Tx) error) error {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&Tx{tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Ping checks that the database answers.
+func (db *SqlPostgresDB) Ping(ctx context.Context) error {
+	return db.db.PingContext(ctx)
+}
+
+// Stats returns the state of the pool.
+func (db *SqlPostgresDB) Stats() entities.PoolStats {
+	stats := db.db.Stats()
+
+	return entities.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// Shutdown closes the pool once the statements in flight are done.
+func (db *SqlPostgresDB) Shutdown(_ context.Context) error {
+	return db.db.Close()
 }
 
-func (db *SqlPostgresDB) Get(query string) (*sqlx.Rows, error) {
-	rows, err := db.db.Queryx(query)
+func (tx *Tx) Get(query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := tx.tx.Queryx(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -37,8 +153,8 @@ func (db *SqlPostgresDB) Get(query string) (*sqlx.Rows, error) {
 	return rows, nil
 }
 
-func (db *SqlPostgresDB) Insert(query string) error {
-	_, err := db.db.Exec(query)
+func (tx *Tx) Insert(query string, args ...interface{}) error {
+	_, err := tx.tx.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,9 @@
 package mapper
 
 import (
+	"strings"
+	"time"
+
 	"github.com/vavelour/chat/internal/domain/entities"
 	"github.com/vavelour/chat/internal/repository/postgres/models"
 )
@@ -9,8 +12,308 @@ func MessageModelToEntities(model models.ChatModel) []entities.Message {
 	message := make([]entities.Message, 0)
 
 	for _, val := range model.Messages {
-		message = append(message, entities.Message{Sender: val.Sender, Recipient: val.Recipient, Content: val.Content})
+		message = append(message, MessageModelToEntity(val))
 	}
 
 	return message
 }
+
+func MessageModelToEntity(model models.MessageModel) entities.Message {
+	message := entities.Message{ID: model.ID, Sender: model.Sender, Recipient: model.Recipient, Content: model.Content, CreatedAt: model.CreatedAt}
+
+	if model.TTLSeconds != nil {
+		message.TTL = time.Duration(*model.TTLSeconds) * time.Second
+		message.ExpireAfterRead = model.ExpireAfterRead
+	}
+
+	if model.ExpiresAt != nil {
+		message.ExpiresAt = *model.ExpiresAt
+	}
+
+	if model.Expired {
+		return message.Tombstone()
+	}
+
+	if model.Deleted {
+		message.Deleted = true
+		return message
+	}
+
+	for _, val := range model.Attachments {
+		message.Attachments = append(message.Attachments, AttachmentModelToEntity(val))
+	}
+
+	return message
+}
+
+func AttachmentModelToEntity(model models.AttachmentModel) entities.Attachment {
+	return entities.Attachment{ID: model.ID, FileName: model.FileName, ContentType: model.ContentType, Size: model.Size, CreatedAt: model.CreatedAt}
+}
+
+func MessageAttachmentModelToEntities(model models.MessageAttachmentModel) (entities.Attachment, entities.Message) {
+	return AttachmentModelToEntity(model.AttachmentModel), entities.Message{ID: model.MessageID, Sender: model.Sender, Recipient: model.Recipient}
+}
+
+func ConversationModelsToEntities(partners []string, byPartner map[string]models.ConversationModel) []entities.Conversation {
+	conversations := make([]entities.Conversation, 0, len(partners))
+
+	for _, partner := range partners {
+		val := byPartner[partner]
+		conversations = append(conversations, entities.Conversation{Partner: partner, UnreadCount: val.UnreadCount, SeenByPartner: val.SeenByPartner})
+	}
+
+	return conversations
+}
+
+func InboxModelsToEntities(inbox []models.InboxModel) []entities.Conversation {
+	conversations := make([]entities.Conversation, 0, len(inbox))
+
+	for _, val := range inbox {
+		conversations = append(conversations, entities.Conversation{
+			Partner:       val.Partner,
+			LastMessage:   MessageModelToEntity(val.MessageModel),
+			UnreadCount:   val.UnreadCount,
+			SeenByPartner: val.SeenByPartner,
+		})
+	}
+
+	return conversations
+}
+
+func AvatarModelToEntity(model models.AvatarModel) entities.Avatar {
+	return entities.Avatar{Username: model.Username, ID: model.ID, ContentType: model.ContentType, UpdatedAt: model.UpdatedAt}
+}
+
+func NotificationModelsToEntities(models []models.NotificationModel) []entities.Notification {
+	notifications := make([]entities.Notification, 0, len(models))
+
+	for _, val := range models {
+		notifications = append(notifications, entities.Notification{
+			ID:        val.ID,
+			Kind:      entities.NotificationKind(val.Kind),
+			Sender:    val.Sender,
+			MessageID: val.MessageID,
+			Content:   val.Content,
+			Read:      val.Read,
+			CreatedAt: val.CreatedAt,
+		})
+	}
+
+	return notifications
+}
+
+func WebhookModelToEntity(model models.WebhookModel) entities.Webhook {
+	webhook := entities.Webhook{
+		ID:        model.ID,
+		URL:       model.URL,
+		Secret:    model.Secret,
+		Active:    model.Active,
+		Failures:  model.Failures,
+		CreatedBy: model.CreatedBy,
+		CreatedAt: model.CreatedAt,
+	}
+
+	if model.Channel != nil {
+		webhook.Channel = *model.Channel
+	}
+
+	for _, val := range strings.Split(model.Events, ",") {
+		if val != "" {
+			webhook.Events = append(webhook.Events, entities.WebhookEventType(val))
+		}
+	}
+
+	return webhook
+}
+
+func WebhookDeliveryModelToEntity(model models.WebhookDeliveryModel) entities.WebhookDelivery {
+	return entities.WebhookDelivery{
+		ID:      model.ID,
+		Webhook: entities.Webhook{ID: model.WebhookID, URL: model.URL, Secret: model.Secret},
+		Event: entities.WebhookEvent{
+			ID:        model.EventID,
+			Type:      entities.WebhookEventType(model.EventType),
+			Channel:   model.Channel,
+			Message:   entities.Message{ID: model.MessageID, Sender: model.Sender, Content: model.Content, CreatedAt: model.EventCreatedAt},
+			CreatedAt: model.EventCreatedAt,
+		},
+		Status:         entities.DeliveryStatus(model.Status),
+		Attempts:       model.Attempts,
+		NextAttemptAt:  model.NextAttemptAt,
+		ResponseStatus: model.ResponseStatus,
+		LastError:      model.LastError,
+		CreatedAt:      model.CreatedAt,
+		UpdatedAt:      model.UpdatedAt,
+	}
+}
+
+func IncomingWebhookModelToEntity(model models.IncomingWebhookModel) entities.IncomingWebhook {
+	w := entities.IncomingWebhook{
+		ID:        model.ID,
+		Name:      model.Name,
+		Channel:   model.Channel,
+		BotName:   model.BotName,
+		CreatedBy: model.CreatedBy,
+		CreatedAt: model.CreatedAt,
+	}
+
+	if model.LastUsedAt != nil {
+		w.LastUsedAt = *model.LastUsedAt
+	}
+
+	return w
+}
+
+func BotCommandModelToEntity(model models.BotCommandModel) entities.BotCommand {
+	return entities.BotCommand{
+		ID:          model.ID,
+		Command:     model.Command,
+		URL:         model.URL,
+		Description: model.Description,
+		BotName:     model.BotName,
+		Secret:      model.Secret,
+		CreatedBy:   model.CreatedBy,
+		CreatedAt:   model.CreatedAt,
+	}
+}
+
+func ScheduledMessageModelToEntity(model models.ScheduledMessageModel) entities.ScheduledMessage {
+	return entities.ScheduledMessage{
+		ID:        model.ID,
+		Channel:   model.Channel,
+		Sender:    model.Sender,
+		Recipient: model.Recipient,
+		Content:   model.Content,
+		SendAt:    model.SendAt,
+		Status:    entities.ScheduledStatus(model.Status),
+		LastError: model.LastError,
+		CreatedAt: model.CreatedAt,
+	}
+}
+
+func ReminderModelToEntity(model models.ReminderModel) entities.Reminder {
+	reminder := entities.Reminder{
+		ID:        model.ID,
+		Name:      model.Name,
+		Content:   model.Content,
+		Schedule:  model.Schedule,
+		TimeZone:  model.TimeZone,
+		Sender:    model.Sender,
+		Paused:    model.Paused,
+		NextRunAt: model.NextRunAt,
+		CreatedBy: model.CreatedBy,
+		CreatedAt: model.CreatedAt,
+	}
+
+	if model.LastRunAt != nil {
+		reminder.LastRunAt = *model.LastRunAt
+	}
+
+	return reminder
+}
+
+func PrivacySettingsModelToEntity(model models.PrivacySettingsModel) entities.PrivacySettings {
+	return entities.PrivacySettings{
+		DMPolicy:          entities.DMPolicy(model.DMPolicy),
+		HideBlockedInFeed: model.HideBlockedInFeed,
+	}
+}
+
+func BlockedUserModelToEntity(model models.BlockedUserModel) entities.BlockedUser {
+	return entities.BlockedUser{Username: model.Username, CreatedAt: model.CreatedAt}
+}
+
+func ReportModelToEntity(model models.ReportModel) entities.Report {
+	report := entities.Report{
+		ID:        model.ID,
+		MessageID: model.MessageID,
+		Reporter:  model.Reporter,
+		Reason:    model.Reason,
+		Status:    entities.ReportStatus(model.Status),
+		CreatedAt: model.CreatedAt,
+		Message: entities.Message{
+			ID:        model.MessageID,
+			Sender:    model.Sender,
+			Content:   model.Content,
+			CreatedAt: model.MessageCreatedAt,
+			Deleted:   model.Deleted,
+		},
+	}
+
+	if model.ResolvedBy != nil {
+		report.ResolvedBy = *model.ResolvedBy
+	}
+
+	if model.ResolvedAt != nil {
+		report.ResolvedAt = *model.ResolvedAt
+	}
+
+	return report
+}
+
+func SanctionModelToEntity(model models.SanctionModel) entities.Sanction {
+	sanction := entities.Sanction{
+		Username:  model.Username,
+		Kind:      entities.SanctionKind(model.Kind),
+		Reason:    model.Reason,
+		CreatedAt: model.CreatedAt,
+	}
+
+	if model.Until != nil {
+		sanction.Until = *model.Until
+	}
+
+	if model.CreatedBy != nil {
+		sanction.CreatedBy = *model.CreatedBy
+	}
+
+	return sanction
+}
+
+func AuditEntryModelToEntity(model models.AuditEntryModel) entities.AuditEntry {
+	entry := entities.AuditEntry{
+		ID:        model.ID,
+		Moderator: model.Moderator,
+		Action:    entities.ModerationAction(model.Action),
+		Reason:    model.Reason,
+		CreatedAt: model.CreatedAt,
+	}
+
+	if model.Target != nil {
+		entry.Target = *model.Target
+	}
+
+	if model.MessageID != nil {
+		entry.MessageID = *model.MessageID
+	}
+
+	if model.ReportID != nil {
+		entry.ReportID = *model.ReportID
+	}
+
+	if model.Until != nil {
+		entry.Until = *model.Until
+	}
+
+	return entry
+}
+
+func LegalHoldModelToEntity(model models.LegalHoldModel) entities.LegalHold {
+	return entities.LegalHold{Username: model.Username, PlacedAt: model.PlacedAt}
+}
+
+func DataExportModelToEntity(model models.DataExportModel) entities.DataExport {
+	export := entities.DataExport{
+		ID:          model.ID,
+		Username:    model.Username,
+		Status:      entities.DataExportStatus(model.Status),
+		RequestedAt: model.RequestedAt,
+		Size:        model.Size,
+	}
+
+	if model.ExpiresAt != nil {
+		export.ExpiresAt = *model.ExpiresAt
+	}
+
+	return export
+}
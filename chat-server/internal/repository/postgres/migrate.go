@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/migrations"
+)
+
+const (
+	// migrationLockKey is the key of the advisory lock the replicas take to
+	// migrate one at a time.
+	migrationLockKey = 20240305
+
+	// baselineVersion is the version of the databases migrated by hand
+	// before the server kept schema_migrations: the users table exists, but
+	// nothing says which migrations made it.
+	baselineVersion = 20240305140226
+)
+
+var ErrDirtyMigration = errors.New("the last migration failed half-way, fix the schema by hand")
+
+// Migration is a step of the schema, read from a file named like
+// 20240305140226_dir.up.sql.
+type Migration struct {
+	Version int64
+	Name    string
+	Script  string
+}
+
+// LoadMigrations reads the up migrations of fsys in the order of their
+// versions.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Migration, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(file, ".up.sql")
+
+		version, err := strconv.ParseInt(strings.SplitN(name, "_", 2)[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: migration %s: %w", file, err)
+		}
+
+		script, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, Migration{Version: version, Name: name, Script: string(script)})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// PendingMigrations returns the names of the migrations Migrate would apply,
+// without applying them.
+func (db *SqlPostgresDB) PendingMigrations() ([]string, error) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	current, _, dirty, err := schemaVersion(context.Background(), db.db)
+	if err != nil {
+		return nil, err
+	}
+
+	if dirty {
+		return nil, ErrDirtyMigration
+	}
+
+	return names(pendingMigrations(all, current)), nil
+}
+
+// Migrate applies the pending migrations, each in a transaction of its own,
+// and returns their names. The replicas starting together take turns on an
+// advisory lock, so only the first of them migrates.
+//
+// The version is kept in schema_migrations the way the migrate CLI keeps it,
+// so the CLI can still roll the migrations back.
+func (db *SqlPostgresDB) Migrate() ([]string, error) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	conn, err := db.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// The statement timeout of the pool is for the requests: waiting for
+	// the lock while another replica migrates, and the migrations
+	// themselves, may take longer. The connection goes back to the pool with
+	// the timeout it was opened with.
+	if _, err := conn.ExecContext(ctx, "SET statement_timeout = 0"); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(ctx, "RESET statement_timeout")
+
+	// The lock belongs to the session, so it is taken and released on the
+	// same connection.
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	current, tracked, dirty, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if dirty {
+		return nil, ErrDirtyMigration
+	}
+
+	if !tracked {
+		if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations "+
+			"(version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"); err != nil {
+			return nil, err
+		}
+
+		if current > 0 {
+			if err := setSchemaVersion(ctx, conn, current); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	pending := pendingMigrations(all, current)
+	for _, m := range pending {
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return nil, fmt.Errorf("postgres: migration %s: %w", m.Name, err)
+		}
+	}
+
+	return names(pending), nil
+}
+
+// schemaVersion returns the version of the schema and whether
+// schema_migrations keeps it. A database without schema_migrations is at the
+// baseline if it has the users table, and empty otherwise.
+func schemaVersion(ctx context.Context, db sqlx.QueryerContext) (version int64, tracked, dirty bool, err error) {
+	var tables struct {
+		Migrations bool `db:"migrations"`
+		Users      bool `db:"users"`
+	}
+
+	err = sqlx.GetContext(ctx, db, &tables, "SELECT to_regclass('schema_migrations') IS NOT NULL AS migrations, "+
+		"to_regclass('users') IS NOT NULL AS users")
+	if err != nil {
+		return 0, false, false, err
+	}
+
+	if !tables.Migrations {
+		if tables.Users {
+			return baselineVersion, false, false, nil
+		}
+
+		return 0, false, false, nil
+	}
+
+	var row struct {
+		Version int64 `db:"version"`
+		Dirty   bool  `db:"dirty"`
+	}
+
+	err = sqlx.GetContext(ctx, db, &row, "SELECT version, dirty FROM schema_migrations LIMIT 1")
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, true, false, nil
+	}
+	if err != nil {
+		return 0, false, false, err
+	}
+
+	return row.Version, true, row.Dirty, nil
+}
+
+func setSchemaVersion(ctx context.Context, db sqlx.ExecerContext, version int64) error {
+	if _, err := db.ExecContext(ctx, "TRUNCATE schema_migrations"); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", version)
+
+	return err
+}
+
+func applyMigration(ctx context.Context, conn *sqlx.Conn, m Migration) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, m.Script); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := setSchemaVersion(ctx, tx, m.Version); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// pendingMigrations returns the migrations newer than version.
+func pendingMigrations(all []Migration, version int64) []Migration {
+	i := sort.Search(len(all), func(i int) bool { return all[i].Version > version })
+
+	return all[i:]
+}
+
+func names(migrations []Migration) []string {
+	result := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		result = append(result, m.Name)
+	}
+
+	return result
+}
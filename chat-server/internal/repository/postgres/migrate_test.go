@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20261019100000_b.up.sql":   {Data: []byte("CREATE TABLE b ()")},
+		"20261019100000_b.down.sql": {Data: []byte("DROP TABLE b")},
+		"20240305140226_a.up.sql":   {Data: []byte("CREATE TABLE a ()")},
+	}
+
+	all, err := LoadMigrations(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 20240305140226, Name: "20240305140226_a", Script: "CREATE TABLE a ()"},
+		{Version: 20261019100000, Name: "20261019100000_b", Script: "CREATE TABLE b ()"},
+	}, all)
+
+	_, err = LoadMigrations(fstest.MapFS{"first.up.sql": {}})
+	assert.Error(t, err)
+}
+
+func TestPendingMigrations(t *testing.T) {
+	all, err := LoadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, all)
+
+	// The first migration is the baseline of the databases migrated by hand.
+	assert.Equal(t, int64(baselineVersion), all[0].Version)
+
+	assert.Equal(t, names(all), names(pendingMigrations(all, 0)))
+	assert.Equal(t, names(all[1:]), names(pendingMigrations(all, baselineVersion)))
+	assert.Empty(t, pendingMigrations(all, all[len(all)-1].Version))
+}
+
+func TestMigrationVersions(t *testing.T) {
+	all, err := LoadMigrations(migrations.FS)
+	require.NoError(t, err)
+
+	// The versions are the times the migrations were written, the way the
+	// migrate CLI names them.
+	for _, m := range all {
+		_, err := time.Parse("20060102150405", strconv.FormatInt(m.Version, 10))
+		assert.NoError(t, err, m.Name)
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type AttachmentModel struct {
+	ID          string    `db:"id"`
+	MessageID   int       `db:"message_id"`
+	FileName    string    `db:"file_name"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type MessageAttachmentModel struct {
+	AttachmentModel
+	Sender    string `db:"sender"`
+	Recipient string `db:"recipient"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type AvatarModel struct {
+	Username    string    `db:"username"`
+	ID          string    `db:"avatar_id"`
+	ContentType string    `db:"avatar_content_type"`
+	UpdatedAt   time.Time `db:"avatar_updated_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type BotNameModel struct {
+	UserExists bool `db:"user_exists"`
+	IsBot      bool `db:"is_bot"`
+}
+
+type BotCommandModel struct {
+	ID          int       `db:"id"`
+	Command     string    `db:"command"`
+	URL         string    `db:"url"`
+	Description string    `db:"description"`
+	BotName     string    `db:"bot_name"`
+	Secret      string    `db:"secret"`
+	CreatedBy   string    `db:"created_by"`
+	CreatedAt   time.Time `db:"created_at"`
+}
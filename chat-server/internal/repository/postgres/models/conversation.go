@@ -0,0 +1,12 @@
+package models
+
+type ConversationModel struct {
+	Partner       string `db:"username"`
+	UnreadCount   int    `db:"unread_count"`
+	SeenByPartner bool   `db:"seen_by_partner"`
+}
+
+type InboxModel struct {
+	ConversationModel
+	MessageModel
+}
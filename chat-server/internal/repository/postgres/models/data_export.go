@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type DataExportModel struct {
+	ID          string     `db:"id"`
+	Username    string     `db:"username"`
+	Status      string     `db:"status"`
+	RequestedAt time.Time  `db:"requested_at"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+	Size        int64      `db:"size"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type IncomingWebhookModel struct {
+	ID         int        `db:"id"`
+	Name       string     `db:"name"`
+	Channel    string     `db:"channel"`
+	BotName    string     `db:"bot_name"`
+	CreatedBy  string     `db:"created_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+}
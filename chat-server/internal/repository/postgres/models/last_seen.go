@@ -0,0 +1,8 @@
+package models
+
+import "time"
+
+type LastSeenModel struct {
+	Username string    `db:"username"`
+	LastSeen time.Time `db:"last_seen"`
+}
@@ -1,7 +1,17 @@
 package models
 
+import "time"
+
 type MessageModel struct {
-	Sender    string `db:"sender_id"`
-	Recipient string `db:"recipient_id"`
-	Content   string `db:"message"`
+	ID              int               `db:"id"`
+	Sender          string            `db:"sender"`
+	Recipient       string            `db:"recipient"`
+	Content         string            `db:"message"`
+	CreatedAt       time.Time         `db:"created_at"`
+	TTLSeconds      *int              `db:"ttl_seconds"`
+	ExpireAfterRead bool              `db:"expire_after_read"`
+	ExpiresAt       *time.Time        `db:"expires_at"`
+	Expired         bool              `db:"expired"`
+	Deleted         bool              `db:"deleted"`
+	Attachments     []AttachmentModel `db:"-"`
 }
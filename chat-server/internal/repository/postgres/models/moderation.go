@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+type ReportModel struct {
+	ID               int        `db:"id"`
+	MessageID        int        `db:"message_id"`
+	Reporter         string     `db:"reporter"`
+	Reason           string     `db:"reason"`
+	Status           string     `db:"status"`
+	CreatedAt        time.Time  `db:"created_at"`
+	ResolvedBy       *string    `db:"resolved_by"`
+	ResolvedAt       *time.Time `db:"resolved_at"`
+	Sender           string     `db:"sender"`
+	Content          string     `db:"message"`
+	Deleted          bool       `db:"deleted"`
+	MessageCreatedAt time.Time  `db:"message_created_at"`
+}
+
+type SanctionModel struct {
+	Username  string     `db:"username"`
+	Kind      string     `db:"kind"`
+	Reason    string     `db:"reason"`
+	Until     *time.Time `db:"until"`
+	CreatedBy *string    `db:"created_by"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type AuditEntryModel struct {
+	ID        int        `db:"id"`
+	Moderator string     `db:"moderator"`
+	Action    string     `db:"action"`
+	Target    *string    `db:"target"`
+	MessageID *int       `db:"message_id"`
+	ReportID  *int       `db:"report_id"`
+	Reason    string     `db:"reason"`
+	Until     *time.Time `db:"until"`
+	CreatedAt time.Time  `db:"created_at"`
+}
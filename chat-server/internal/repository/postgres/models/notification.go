@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type NotificationModel struct {
+	ID        int       `db:"id"`
+	Kind      string    `db:"kind"`
+	Sender    string    `db:"sender"`
+	MessageID int       `db:"message_id"`
+	Content   string    `db:"message"`
+	Read      bool      `db:"read"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type CountModel struct {
+	Count int `db:"count"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type PrivacySettingsModel struct {
+	DMPolicy          string `db:"dm_policy"`
+	HideBlockedInFeed bool   `db:"hide_blocked_in_feed"`
+}
+
+type BlockedUserModel struct {
+	Username  string    `db:"username"`
+	CreatedAt time.Time `db:"created_at"`
+}
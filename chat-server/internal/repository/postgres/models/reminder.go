@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type ReminderModel struct {
+	ID        int        `db:"id"`
+	Name      string     `db:"name"`
+	Content   string     `db:"content"`
+	Schedule  string     `db:"schedule"`
+	TimeZone  string     `db:"time_zone"`
+	Sender    string     `db:"sender"`
+	Paused    bool       `db:"paused"`
+	NextRunAt time.Time  `db:"next_run_at"`
+	LastRunAt *time.Time `db:"last_run_at"`
+	CreatedBy string     `db:"created_by"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -0,0 +1,8 @@
+package models
+
+import "time"
+
+type LegalHoldModel struct {
+	Username string    `db:"username"`
+	PlacedAt time.Time `db:"placed_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type ScheduledMessageModel struct {
+	ID        int       `db:"id"`
+	Channel   string    `db:"channel"`
+	Sender    string    `db:"sender"`
+	Recipient string    `db:"recipient"`
+	Content   string    `db:"message"`
+	SendAt    time.Time `db:"send_at"`
+	Status    string    `db:"status"`
+	LastError string    `db:"last_error"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// WebhookModel has the events joined with commas, since the driver does not
+// scan arrays into slices.
+type WebhookModel struct {
+	ID        int       `db:"id"`
+	URL       string    `db:"url"`
+	Events    string    `db:"events"`
+	Channel   *string   `db:"channel"`
+	Secret    string    `db:"secret"`
+	Active    bool      `db:"active"`
+	Failures  int       `db:"failures"`
+	CreatedBy string    `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type WebhookDeliveryModel struct {
+	ID             int       `db:"id"`
+	WebhookID      int       `db:"webhook_id"`
+	URL            string    `db:"url"`
+	Secret         string    `db:"secret"`
+	EventID        int       `db:"event_id"`
+	EventType      string    `db:"type"`
+	Channel        string    `db:"channel"`
+	MessageID      int       `db:"message_id"`
+	Sender         string    `db:"sender"`
+	Content        string    `db:"message"`
+	EventCreatedAt time.Time `db:"event_created_at"`
+	Status         string    `db:"status"`
+	Attempts       int       `db:"attempts"`
+	NextAttemptAt  time.Time `db:"next_attempt_at"`
+	ResponseStatus int       `db:"response_status"`
+	LastError      string    `db:"last_error"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
@@ -0,0 +1,131 @@
+package repos
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+const (
+	globalMessageColumn  = "global_message_id"
+	privateMessageColumn = "private_message_id"
+)
+
+type attachmentSource interface {
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// attachmentValues selects the attachments passed as $first..$first+3 arrays
+// in their original order, ready to be linked to the inserted message.
+func attachmentValues(first int) string {
+	return fmt.Sprintf("unnest($%d::VARCHAR[], $%d::VARCHAR[], $%d::VARCHAR[], $%d::BIGINT[]) "+
+		"WITH ORDINALITY AS a(id, file_name, content_type, size, position)", first, first+1, first+2, first+3)
+}
+
+func attachmentArgs(attachments []entities.Attachment) []interface{} {
+	ids := make([]string, 0, len(attachments))
+	names := make([]string, 0, len(attachments))
+	types := make([]string, 0, len(attachments))
+	sizes := make([]int64, 0, len(attachments))
+
+	for _, val := range attachments {
+		ids = append(ids, val.ID)
+		names = append(names, val.FileName)
+		types = append(types, val.ContentType)
+		sizes = append(sizes, val.Size)
+	}
+
+	return []interface{}{ids, names, types, sizes}
+}
+
+// loadAttachments fills in the attachments of the messages, which are linked
+// to them through the given column of the attachments table.
+func loadAttachments(db attachmentSource, column string, messages []models.MessageModel) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(messages))
+	byID := make(map[int]int, len(messages))
+	for i, val := range messages {
+		ids = append(ids, int64(val.ID))
+		byID[val.ID] = i
+	}
+
+	query := fmt.Sprintf("SELECT id, %[1]s AS message_id, file_name, content_type, size, created_at "+
+		"FROM attachments "+
+		"WHERE %[1]s = ANY($1) "+
+		"ORDER BY %[1]s, position", column)
+
+	rows, err := db.Get(query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attachment models.AttachmentModel
+		err := rows.StructScan(&attachment)
+		if err != nil {
+			return err
+		}
+
+		i := byID[attachment.MessageID]
+		messages[i].Attachments = append(messages[i].Attachments, attachment)
+	}
+
+	return rows.Err()
+}
+
+func getAttachment(db attachmentSource, query, id string) (entities.Attachment, entities.Message, error) {
+	rows, err := db.Get(query, id)
+	if err != nil {
+		return entities.Attachment{}, entities.Message{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return entities.Attachment{}, entities.Message{}, err
+		}
+
+		return entities.Attachment{}, entities.Message{}, entities.ErrAttachmentNotFound
+	}
+
+	var attachment models.MessageAttachmentModel
+	if err := rows.StructScan(&attachment); err != nil {
+		return entities.Attachment{}, entities.Message{}, err
+	}
+
+	a, m := mapper.MessageAttachmentModelToEntities(attachment)
+
+	return a, m, nil
+}
+
+// purgedAttachments reads the rows of a purge or a deletion, a message with
+// one of its removed attachments on every row, and returns how many
+// messages there were and the IDs of the attachments.
+func purgedAttachments(rows *sqlx.Rows) (int, []string, error) {
+	messages := make(map[int]bool)
+
+	var attachments []string
+	for rows.Next() {
+		var (
+			messageID    int
+			attachmentID sql.NullString
+		)
+		if err := rows.Scan(&messageID, &attachmentID); err != nil {
+			return 0, nil, err
+		}
+
+		messages[messageID] = true
+		if attachmentID.Valid {
+			attachments = append(attachments, attachmentID.String)
+		}
+	}
+
+	return len(messages), attachments, rows.Err()
+}
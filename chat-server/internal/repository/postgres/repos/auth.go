@@ -1,22 +1,19 @@
 package repos
 
 import (
-	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/vavelour/chat/internal/domain/entities"
 	"github.com/vavelour/chat/internal/repository/postgres/models"
-	"sync"
 )
 
 //go:generate mockgen -source=auth.go -destination=mocks/postgres_db_mock.go -mock_names=AuthPostgresDB=MockPostgresDB
 
 type AuthPostgresDB interface {
-	Insert(query string) error
-	Get(query string) (*sqlx.Rows, error)
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
 }
 
 type AuthSqlRepos struct {
-	mu sync.RWMutex
 	db AuthPostgresDB
 }
 
@@ -25,12 +22,9 @@ func NewAuthSqlRepos(db AuthPostgresDB) *AuthSqlRepos {
 }
 
 func (r *AuthSqlRepos) InsertUser(username, password string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	query := "INSERT INTO users(username, password_hash) VALUES($1, $2)"
 
-	query := fmt.Sprintf("INSERT INTO users(username, password_hash) VALUES('%s', '%s')", username, password)
-
-	if err := r.db.Insert(query); err != nil {
+	if err := r.db.Insert(query, username, password); err != nil {
 		return err
 	}
 
@@ -38,16 +32,14 @@ func (r *AuthSqlRepos) InsertUser(username, password string) error {
 }
 
 func (r *AuthSqlRepos) GetUser(username string) (entities.User, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	query := fmt.Sprintf("SELECT username, password_hash FROM users WHERE username = '%s'", username)
+	query := "SELECT username, password_hash FROM users WHERE username = $1"
 	var user models.UserModel
 
-	rows, err := r.db.Get(query)
+	rows, err := r.db.Get(query, username)
 	if err != nil {
 		return entities.User{}, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		rows.StructScan(&user)
@@ -29,7 +29,7 @@ func TestAuthSqlRepos_GetUser(t *testing.T) {
 	}
 
 	for _, testCase := range testTable {
-
+		_ = testCase
 	}
 }
 
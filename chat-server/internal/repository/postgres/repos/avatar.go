@@ -0,0 +1,77 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+type AvatarPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type AvatarSqlRepos struct {
+	db AvatarPostgresDB
+}
+
+func NewAvatarSqlRepos(db AvatarPostgresDB) *AvatarSqlRepos {
+	return &AvatarSqlRepos{db: db}
+}
+
+// SetAvatar replaces the avatar of the user unless a newer one was set in
+// the meantime. An avatar with an empty ID removes the current one. It
+// returns the ID of the avatar which is no longer used, if any.
+func (a *AvatarSqlRepos) SetAvatar(avatar entities.Avatar) (string, error) {
+	query := "WITH old AS (SELECT id, avatar_id FROM users WHERE username = $1 FOR UPDATE) " +
+		"UPDATE users u " +
+		"SET avatar_id = NULLIF($2, ''), avatar_content_type = NULLIF($3, ''), avatar_updated_at = $4 " +
+		"FROM old " +
+		"WHERE u.id = old.id AND (u.avatar_updated_at IS NULL OR u.avatar_updated_at <= $4) " +
+		"RETURNING COALESCE(old.avatar_id, '')"
+
+	rows, err := a.db.Get(query, avatar.Username, avatar.ID, avatar.ContentType, avatar.UpdatedAt)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return avatar.ID, rows.Err()
+	}
+
+	var stale string
+	if err := rows.Scan(&stale); err != nil {
+		return "", err
+	}
+
+	return stale, nil
+}
+
+func (a *AvatarSqlRepos) GetAvatar(username string) (entities.Avatar, error) {
+	query := "SELECT username, avatar_id, avatar_content_type, avatar_updated_at " +
+		"FROM users " +
+		"WHERE username = $1 AND avatar_id IS NOT NULL"
+
+	rows, err := a.db.Get(query, username)
+	if err != nil {
+		return entities.Avatar{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return entities.Avatar{}, err
+		}
+
+		return entities.Avatar{}, entities.ErrAvatarNotFound
+	}
+
+	var avatar models.AvatarModel
+	if err := rows.StructScan(&avatar); err != nil {
+		return entities.Avatar{}, err
+	}
+
+	return mapper.AvatarModelToEntity(avatar), nil
+}
@@ -0,0 +1,157 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+const (
+	// botCTE creates the user $1 with the password $2 for a bot unless the
+	// bot exists; the bot CTE has its id either way. The new user is not
+	// visible to the other parts of the statement, so the id comes either
+	// from u or from the existing bots, never both.
+	botCTE = "WITH u AS ( " +
+		"INSERT INTO users(username, password_hash) SELECT $1, $2 " +
+		"WHERE NOT EXISTS (SELECT 1 FROM users WHERE username = $1) " +
+		"RETURNING id), " +
+		"b AS ( " +
+		"INSERT INTO bots(user_id) SELECT id FROM u RETURNING user_id), " +
+		"bot AS ( " +
+		"SELECT user_id FROM b " +
+		"UNION ALL " +
+		"SELECT eb.user_id FROM bots eb JOIN users eu ON eu.id = eb.user_id WHERE eu.username = $1) "
+
+	botCommandColumns = "c.id, c.command, c.url, c.description, u.username AS bot_name, c.secret, c.created_by, c.created_at"
+)
+
+type BotPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type BotCommandSqlRepos struct {
+	db BotPostgresDB
+}
+
+func NewBotCommandSqlRepos(db BotPostgresDB) *BotCommandSqlRepos {
+	return &BotCommandSqlRepos{db: db}
+}
+
+// InsertBotCommand saves the command and registers its bot as a user with
+// the given password, unless the bot exists already.
+func (r *BotCommandSqlRepos) InsertBotCommand(c entities.BotCommand, botPassword string) (entities.BotCommand, error) {
+	if err := checkBotName(r.db, c.BotName); err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	commands, err := r.commands("SELECT 1 AS id FROM bot_commands WHERE command = $1", c.Command)
+	if err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	if len(commands) > 0 {
+		return entities.BotCommand{}, entities.ErrCommandTaken
+	}
+
+	query := botCTE + ", " +
+		"c AS ( " +
+		"INSERT INTO bot_commands(command, url, description, bot_id, secret, created_by) " +
+		"SELECT $3, $4, $5, user_id, $6, $7 FROM bot " +
+		"ON CONFLICT (command) DO NOTHING " +
+		"RETURNING *) " +
+		"SELECT c.id, c.command, c.url, c.description, $1 AS bot_name, c.secret, c.created_by, c.created_at FROM c"
+
+	commands, err = r.commands(query, c.BotName, botPassword, c.Command, c.URL, c.Description, c.Secret, c.CreatedBy)
+	if err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	if len(commands) == 0 {
+		// Another request may have taken the command since it was checked.
+		if _, err := r.GetBotCommand(c.Command); err == nil {
+			return entities.BotCommand{}, entities.ErrCommandTaken
+		}
+
+		return entities.BotCommand{}, entities.ErrBotCommandNotFound
+	}
+
+	return commands[0], nil
+}
+
+func (r *BotCommandSqlRepos) GetBotCommands() ([]entities.BotCommand, error) {
+	return r.commands("SELECT " + botCommandColumns + " FROM bot_commands c JOIN users u ON u.id = c.bot_id ORDER BY c.command")
+}
+
+func (r *BotCommandSqlRepos) GetBotCommand(command string) (entities.BotCommand, error) {
+	commands, err := r.commands("SELECT "+botCommandColumns+" FROM bot_commands c JOIN users u ON u.id = c.bot_id WHERE c.command = $1", command)
+	if err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	if len(commands) == 0 {
+		return entities.BotCommand{}, entities.ErrBotCommandNotFound
+	}
+
+	return commands[0], nil
+}
+
+// DeleteBotCommand removes the command. Its bot and the messages it posted
+// are kept.
+func (r *BotCommandSqlRepos) DeleteBotCommand(id int) error {
+	commands, err := r.commands("DELETE FROM bot_commands WHERE id = $1 RETURNING id", id)
+	if err != nil {
+		return err
+	}
+
+	if len(commands) == 0 {
+		return entities.ErrBotCommandNotFound
+	}
+
+	return nil
+}
+
+func (r *BotCommandSqlRepos) commands(query string, args ...interface{}) ([]entities.BotCommand, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commands := make([]entities.BotCommand, 0)
+	for rows.Next() {
+		var model models.BotCommandModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		commands = append(commands, mapper.BotCommandModelToEntity(model))
+	}
+
+	return commands, nil
+}
+
+// checkBotName fails when the name belongs to a person rather than a bot.
+func checkBotName(db BotPostgresDB, name string) error {
+	rows, err := db.Get("SELECT EXISTS (SELECT 1 FROM users WHERE username = $1) AS user_exists, "+
+		"EXISTS (SELECT 1 FROM bots b JOIN users u ON u.id = b.user_id WHERE u.username = $1) AS is_bot", name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var model models.BotNameModel
+	for rows.Next() {
+		if err := rows.StructScan(&model); err != nil {
+			return err
+		}
+	}
+
+	if model.UserExists && !model.IsBot {
+		return entities.ErrBotNameTaken
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package repos
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	postgresdb "github.com/vavelour/chat/internal/repository/postgres"
+	"github.com/vavelour/chat/internal/repository/repotest"
+	"github.com/vavelour/chat/pkg/database_utils/postgres"
+)
+
+// The tables the tests empty before they start. The rest of the tables
+// reference them and are emptied by the cascade.
+const truncateTables = "TRUNCATE users, global_chat, private_chats, webhooks, webhook_events RESTART IDENTITY CASCADE"
+
+// TestConformance runs against the database given by the
+// CHAT_TEST_POSTGRES_* variables, and is skipped without them. The database
+// is emptied before every test.
+func TestConformance(t *testing.T) {
+	db := openTestDB(t, 0)
+
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		require.NoError(t, db.Insert(truncateTables))
+
+		return repotest.Backend{
+			Auth:      NewAuthSqlRepos(db),
+			Public:    NewPublicSqlRepos(db),
+			Private:   NewPrivateSqlRepos(db),
+			Retention: NewRetentionSqlRepos(db),
+			Transfer:  NewTransferSqlRepos(db),
+			Export:    NewExportSqlRepos(db),
+			Unit: func(fn func(b repotest.Backend) error) error {
+				return db.Tx(func(tx *postgresdb.Tx) error {
+					return fn(repotest.Backend{
+						Auth:    NewAuthSqlRepos(tx),
+						Public:  NewPublicSqlRepos(tx),
+						Private: NewPrivateSqlRepos(tx),
+					})
+				})
+			},
+		}
+	})
+}
+
+// openTestDB connects to the database given by the CHAT_TEST_POSTGRES_*
+// variables and migrates it, or skips the test without them.
+func openTestDB(t *testing.T, maxOpenConns int) *postgresdb.SqlPostgresDB {
+	t.Helper()
+
+	host := os.Getenv("CHAT_TEST_POSTGRES_HOST")
+	if host == "" {
+		t.Skip("CHAT_TEST_POSTGRES_HOST is not set")
+	}
+
+	db, err := postgresdb.NewSqlPostgresDB(postgres.SqlPostgresConfig{
+		Host:         host,
+		Port:         getenv("CHAT_TEST_POSTGRES_PORT", "5432"),
+		User:         getenv("CHAT_TEST_POSTGRES_USER", "postgres"),
+		DBName:       getenv("CHAT_TEST_POSTGRES_DB", "postgres"),
+		Password:     os.Getenv("CHAT_TEST_POSTGRES_PASSWORD"),
+		SSLMode:      getenv("CHAT_TEST_POSTGRES_SSLMODE", "disable"),
+		MaxOpenConns: maxOpenConns,
+	})
+	require.NoError(t, err)
+
+	_, err = db.Migrate()
+	require.NoError(t, err)
+
+	return db
+}
+
+func getenv(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+
+	return fallback
+}
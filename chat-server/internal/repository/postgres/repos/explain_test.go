@@ -0,0 +1,96 @@
+package repos
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	postgresdb "github.com/vavelour/chat/internal/repository/postgres"
+)
+
+// explainDB records the plans of the queries it runs.
+type explainDB struct {
+	*postgresdb.SqlPostgresDB
+	t     *testing.T
+	plans []string
+}
+
+func (db *explainDB) Get(query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := db.SqlPostgresDB.Get("EXPLAIN "+query, args...)
+	require.NoError(db.t, err)
+
+	var plan []string
+	for rows.Next() {
+		var line string
+		require.NoError(db.t, rows.Scan(&line))
+		plan = append(plan, line)
+	}
+	rows.Close()
+
+	db.plans = append(db.plans, strings.Join(plan, "\n"))
+
+	return db.SqlPostgresDB.Get(query, args...)
+}
+
+// TestIndexUsage checks the plans of the queries of the chat history and
+// the inbox. The tables of a test are too small for the planner to prefer an
+// index, so the sequential scans are turned off on the only connection.
+func TestIndexUsage(t *testing.T) {
+	db := openTestDB(t, 1)
+	require.NoError(t, db.Insert(truncateTables))
+	require.NoError(t, db.Insert("SET enable_seqscan = off"))
+	t.Cleanup(func() { db.Insert("RESET enable_seqscan") })
+
+	auth := NewAuthSqlRepos(db)
+	for _, username := range []string{"tester", "valera"} {
+		require.NoError(t, auth.InsertUser(username, "password"))
+	}
+
+	private := NewPrivateSqlRepos(db)
+	require.NoError(t, private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "hi"}))
+	require.NoError(t, private.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "hello"}))
+
+	testTable := []struct {
+		name  string
+		query func(p *PrivateSqlRepos) error
+		index string
+	}{
+		{
+			name: "history",
+			query: func(p *PrivateSqlRepos) error {
+				_, err := p.GetMessages("valera", "tester", 10, 0, entities.OrderOldestFirst)
+				return err
+			},
+			index: "private_chats_pair_idx",
+		},
+		{
+			name: "partners",
+			query: func(p *PrivateSqlRepos) error {
+				_, err := p.GetUsers("tester")
+				return err
+			},
+			index: "conversations_user_request_activity_idx",
+		},
+		{
+			name: "inbox",
+			query: func(p *PrivateSqlRepos) error {
+				_, err := p.GetInbox("tester", 10, 0)
+				return err
+			},
+			index: "conversations_user_request_activity_idx",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			explained := &explainDB{SqlPostgresDB: db, t: t}
+
+			require.NoError(t, testCase.query(NewPrivateSqlRepos(explained)))
+			require.NotEmpty(t, explained.plans)
+			assert.Contains(t, explained.plans[0], testCase.index)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"time"
+)
+
+type ExportPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// ExportSqlRepos keeps the latest export of the data of every user, so that
+// the exports outlive a restart and every replica sees them.
+type ExportSqlRepos struct {
+	db ExportPostgresDB
+}
+
+func NewExportSqlRepos(db ExportPostgresDB) *ExportSqlRepos {
+	return &ExportSqlRepos{db: db}
+}
+
+func (r *ExportSqlRepos) GetDataExport(username string) (entities.DataExport, error) {
+	query := "SELECT e.id, u.username, e.status, e.requested_at, e.expires_at, e.size " +
+		"FROM data_exports e " +
+		"JOIN users u ON u.id = e.user_id " +
+		"WHERE u.username = $1"
+
+	rows, err := r.db.Get(query, username)
+	if err != nil {
+		return entities.DataExport{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return entities.DataExport{}, err
+		}
+
+		return entities.DataExport{}, entities.ErrDataExportNotFound
+	}
+
+	var model models.DataExportModel
+	if err := rows.StructScan(&model); err != nil {
+		return entities.DataExport{}, err
+	}
+
+	return mapper.DataExportModelToEntity(model), nil
+}
+
+// SaveDataExport replaces the export of the user.
+func (r *ExportSqlRepos) SaveDataExport(export entities.DataExport) error {
+	query := "WITH u AS (SELECT id FROM users WHERE username = $1), " +
+		"ins AS ( " +
+		"INSERT INTO data_exports(user_id, id, status, requested_at, expires_at, size) " +
+		"SELECT id, $2, $3, $4, $5, $6 FROM u " +
+		"ON CONFLICT (user_id) DO UPDATE " +
+		"SET id = EXCLUDED.id, status = EXCLUDED.status, requested_at = EXCLUDED.requested_at, " +
+		"expires_at = EXCLUDED.expires_at, size = EXCLUDED.size) " +
+		"SELECT id FROM u"
+
+	found, err := r.found(query, export.Username, export.ID, string(export.Status), export.RequestedAt,
+		expiresAt(export), export.Size)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return entities.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// FinishDataExport records the status, size and expiry of the export, and
+// reports whether it is still the export of its user: one replaced by a
+// newer export is not recorded.
+func (r *ExportSqlRepos) FinishDataExport(export entities.DataExport) (bool, error) {
+	query := "UPDATE data_exports SET status = $2, expires_at = $3, size = $4 " +
+		"WHERE id = $1 " +
+		"RETURNING id"
+
+	return r.found(query, export.ID, string(export.Status), expiresAt(export), export.Size)
+}
+
+// found runs the query and reports whether it returned a row.
+func (r *ExportSqlRepos) found(query string, args ...interface{}) (bool, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	found := rows.Next()
+
+	return found, rows.Err()
+}
+
+// expiresAt is the expiry of the export, which a pending one has none of.
+func expiresAt(export entities.DataExport) *time.Time {
+	if export.ExpiresAt.IsZero() {
+		return nil
+	}
+
+	return &export.ExpiresAt
+}
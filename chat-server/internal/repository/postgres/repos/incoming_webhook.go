@@ -0,0 +1,111 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+const incomingWebhookColumns = "iw.id, iw.name, iw.channel, u.username AS bot_name, iw.created_by, iw.created_at, iw.last_used_at"
+
+type IncomingWebhookPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type IncomingWebhookSqlRepos struct {
+	db IncomingWebhookPostgresDB
+}
+
+func NewIncomingWebhookSqlRepos(db IncomingWebhookPostgresDB) *IncomingWebhookSqlRepos {
+	return &IncomingWebhookSqlRepos{db: db}
+}
+
+// InsertIncomingWebhook saves the webhook and registers its bot as a user
+// with the given password, unless the bot exists already.
+func (r *IncomingWebhookSqlRepos) InsertIncomingWebhook(w entities.IncomingWebhook, tokenHash, botPassword string) (entities.IncomingWebhook, error) {
+	if err := checkBotName(r.db, w.BotName); err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	query := botCTE + ", " +
+		"iw AS ( " +
+		"INSERT INTO incoming_webhooks(token_hash, name, channel, bot_id, created_by) " +
+		"SELECT $3, $4, $5, user_id, $6 FROM bot " +
+		"RETURNING *) " +
+		"SELECT iw.id, iw.name, iw.channel, $1 AS bot_name, iw.created_by, iw.created_at, iw.last_used_at FROM iw"
+
+	webhooks, err := r.webhooks(query, w.BotName, botPassword, tokenHash, w.Name, w.Channel, w.CreatedBy)
+	if err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.IncomingWebhook{}, entities.ErrIncomingWebhookNotFound
+	}
+
+	return webhooks[0], nil
+}
+
+func (r *IncomingWebhookSqlRepos) GetIncomingWebhooks() ([]entities.IncomingWebhook, error) {
+	return r.webhooks("SELECT " + incomingWebhookColumns + " FROM incoming_webhooks iw " +
+		"JOIN users u ON u.id = iw.bot_id ORDER BY iw.id")
+}
+
+func (r *IncomingWebhookSqlRepos) GetIncomingWebhookByToken(tokenHash string) (entities.IncomingWebhook, error) {
+	webhooks, err := r.webhooks("SELECT "+incomingWebhookColumns+" FROM incoming_webhooks iw "+
+		"JOIN users u ON u.id = iw.bot_id WHERE iw.token_hash = $1", tokenHash)
+	if err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.IncomingWebhook{}, entities.ErrIncomingWebhookNotFound
+	}
+
+	return webhooks[0], nil
+}
+
+// DeleteIncomingWebhook revokes the webhook. Its bot and the messages it
+// posted are kept.
+func (r *IncomingWebhookSqlRepos) DeleteIncomingWebhook(id int) error {
+	webhooks, err := r.webhooks("WITH iw AS (DELETE FROM incoming_webhooks WHERE id = $1 RETURNING *) "+
+		"SELECT "+incomingWebhookColumns+" FROM iw JOIN users u ON u.id = iw.bot_id", id)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.ErrIncomingWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *IncomingWebhookSqlRepos) TouchIncomingWebhook(id int, usedAt time.Time) error {
+	return r.db.Insert("UPDATE incoming_webhooks SET last_used_at = $2 WHERE id = $1", id, usedAt)
+}
+
+func (r *IncomingWebhookSqlRepos) webhooks(query string, args ...interface{}) ([]entities.IncomingWebhook, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]entities.IncomingWebhook, 0)
+	for rows.Next() {
+		var model models.IncomingWebhookModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, mapper.IncomingWebhookModelToEntity(model))
+	}
+
+	return webhooks, nil
+}
@@ -35,30 +35,40 @@ func (m *MockPostgresDB) EXPECT() *MockPostgresDBMockRecorder {
 }
 
 // Get mocks base method.
-func (m *MockPostgresDB) Get(query string) (*sqlx.Rows, error) {
+func (m *MockPostgresDB) Get(query string, args ...interface{}) (*sqlx.Rows, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", query)
+	varargs := []interface{}{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Get", varargs...)
 	ret0, _ := ret[0].(*sqlx.Rows)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockPostgresDBMockRecorder) Get(query interface{}) *gomock.Call {
+func (mr *MockPostgresDBMockRecorder) Get(query interface{}, args ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPostgresDB)(nil).Get), query)
+	varargs := append([]interface{}{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPostgresDB)(nil).Get), varargs...)
 }
 
 // Insert mocks base method.
-func (m *MockPostgresDB) Insert(query string) error {
+func (m *MockPostgresDB) Insert(query string, args ...interface{}) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Insert", query)
+	varargs := []interface{}{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Insert", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Insert indicates an expected call of Insert.
-func (mr *MockPostgresDBMockRecorder) Insert(query interface{}) *gomock.Call {
+func (mr *MockPostgresDBMockRecorder) Insert(query interface{}, args ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockPostgresDB)(nil).Insert), query)
+	varargs := append([]interface{}{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockPostgresDB)(nil).Insert), varargs...)
 }
@@ -0,0 +1,323 @@
+package repos
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"time"
+)
+
+const reportSelect = "SELECT r.id, r.message_id, rp.username AS reporter, r.reason, r.status, r.created_at, " +
+	"rb.username AS resolved_by, r.resolved_at, " +
+	"su.username AS sender, gc.message, gc.deleted, gc.created_at AS message_created_at " +
+	"FROM reports r " +
+	"JOIN users rp ON rp.id = r.reporter_id " +
+	"LEFT JOIN users rb ON rb.id = r.resolved_by " +
+	"JOIN global_chat gc ON gc.id = r.message_id " +
+	"JOIN users su ON su.id = gc.sender_id "
+
+const sanctionSelect = "SELECT u.username, s.kind, s.reason, s.until, cb.username AS created_by, s.created_at " +
+	"FROM sanctions s " +
+	"JOIN users u ON u.id = s.user_id " +
+	"LEFT JOIN users cb ON cb.id = s.created_by "
+
+type ModerationPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// ModerationSqlRepos keeps the reports, the sanctions and the audit log.
+// Every moderation action is written with its audit entry in one statement.
+type ModerationSqlRepos struct {
+	db ModerationPostgresDB
+}
+
+func NewModerationSqlRepos(db ModerationPostgresDB) *ModerationSqlRepos {
+	return &ModerationSqlRepos{db: db}
+}
+
+func (p *ModerationSqlRepos) InsertReport(report entities.Report) (entities.Report, error) {
+	query := "WITH m AS (SELECT gc.id, gc.sender_id, u.username AS sender FROM global_chat gc " +
+		"JOIN users u ON u.id = gc.sender_id WHERE gc.id = $1 AND NOT gc.deleted), " +
+		"rp AS (SELECT id FROM users WHERE username = $2), " +
+		"ins AS ( " +
+		"INSERT INTO reports(message_id, reporter_id, reason) " +
+		"SELECT m.id, rp.id, $3 FROM m, rp WHERE m.sender_id <> rp.id " +
+		"ON CONFLICT DO NOTHING " +
+		"RETURNING id) " +
+		"SELECT m.sender, ins.id FROM m LEFT JOIN ins ON TRUE"
+
+	rows, err := p.db.Get(query, report.MessageID, report.Reporter, report.Reason)
+	if err != nil {
+		return entities.Report{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return entities.Report{}, err
+		}
+
+		return entities.Report{}, entities.ErrMessageNotFound
+	}
+
+	var sender string
+	var id sql.NullInt64
+	if err := rows.Scan(&sender, &id); err != nil {
+		return entities.Report{}, err
+	}
+
+	if !id.Valid {
+		if sender == report.Reporter {
+			return entities.Report{}, entities.ErrReportOwnMessage
+		}
+
+		return entities.Report{}, entities.ErrAlreadyReported
+	}
+
+	rows.Close()
+
+	return p.getReport(int(id.Int64))
+}
+
+// GetReports returns the reports with the status: the open ones oldest
+// first, as a queue, and the closed ones latest first.
+func (p *ModerationSqlRepos) GetReports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error) {
+	query := reportSelect +
+		"WHERE r.status = $1 " +
+		"ORDER BY CASE WHEN r.status = 'open' THEN r.id END ASC, r.id DESC " +
+		"LIMIT $2 OFFSET $3"
+
+	return p.listReports(query, string(status), limit, offset)
+}
+
+// CloseReport resolves or dismisses the open report.
+func (p *ModerationSqlRepos) CloseReport(id int, status entities.ReportStatus, entry entities.AuditEntry) (entities.Report, error) {
+	query := "WITH md AS (SELECT id FROM users WHERE username = $3), " +
+		"c AS ( " +
+		"UPDATE reports r SET status = $2, resolved_by = (SELECT id FROM md), resolved_at = now() " +
+		"WHERE r.id = $1 AND r.status = 'open' " +
+		"RETURNING r.id, r.message_id), " +
+		"lg AS ( " +
+		"INSERT INTO moderation_log(moderator_id, action, target_id, message_id, report_id, reason) " +
+		"SELECT (SELECT id FROM md), $4, gc.sender_id, c.message_id, c.id, $5 " +
+		"FROM c JOIN global_chat gc ON gc.id = c.message_id) " +
+		"SELECT id FROM c"
+
+	if err := p.change(query, entities.ErrReportNotFound,
+		id, string(status), entry.Moderator, string(entry.Action), entry.Reason); err != nil {
+		return entities.Report{}, err
+	}
+
+	return p.getReport(id)
+}
+
+// DeletePublicMessage removes the content and the attachments of the public
+// message, resolves its open reports and notifies the webhooks of the
+// deletion. The message stays listed as deleted, so the pages of the chat
+// do not shift. It returns the IDs of the attachments removed, whose files
+// are left to the caller.
+func (p *ModerationSqlRepos) DeletePublicMessage(id int, entry entities.AuditEntry) ([]string, error) {
+	query := "WITH md AS (SELECT id FROM users WHERE username = $2), " +
+		"d AS ( " +
+		"UPDATE global_chat SET message = '', deleted = TRUE " +
+		"WHERE id = $1 AND NOT deleted " +
+		"RETURNING id, sender_id), " +
+		"att AS (DELETE FROM attachments a USING d WHERE a.global_message_id = d.id RETURNING a.id, a.global_message_id), " +
+		"rs AS ( " +
+		"UPDATE reports r SET status = 'resolved', resolved_by = (SELECT id FROM md), resolved_at = now() " +
+		"FROM d WHERE r.message_id = d.id AND r.status = 'open'), " +
+		"lg AS ( " +
+		"INSERT INTO moderation_log(moderator_id, action, target_id, message_id, reason) " +
+		"SELECT (SELECT id FROM md), $3, d.sender_id, d.id, $4 FROM d), " +
+		"ev AS ( " +
+		"INSERT INTO webhook_events(type, channel, message_id, sender, message, created_at) " +
+		"SELECT $5, $6, d.id, u.username, '', now() FROM d JOIN users u ON u.id = d.sender_id " +
+		"WHERE EXISTS (SELECT 1 FROM webhooks w " + subscribedWebhookAt(5, 6) + ") " +
+		"RETURNING id), " +
+		"wd AS ( " +
+		"INSERT INTO webhook_deliveries(webhook_id, event_id) " +
+		"SELECT w.id, ev.id FROM ev, webhooks w " + subscribedWebhookAt(5, 6) + ") " +
+		"SELECT d.id, att.id FROM d LEFT JOIN att ON att.global_message_id = d.id"
+
+	rows, err := p.db.Get(query, id, entry.Moderator, string(entry.Action), entry.Reason,
+		string(entities.EventMessageDeleted), entities.PublicChannel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deleted, attachments, err := purgedAttachments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if deleted == 0 {
+		return nil, entities.ErrMessageNotFound
+	}
+
+	return attachments, nil
+}
+
+// SetSanction puts the sanction on the user, replacing the one of the same
+// kind.
+func (p *ModerationSqlRepos) SetSanction(s entities.Sanction, entry entities.AuditEntry) error {
+	query := "WITH u AS (SELECT id FROM users WHERE username = $1), " +
+		"md AS (SELECT id FROM users WHERE username = $5), " +
+		"s AS ( " +
+		"INSERT INTO sanctions(user_id, kind, reason, until, created_by) " +
+		"SELECT u.id, $2, $3, $4, (SELECT id FROM md) FROM u " +
+		"ON CONFLICT (user_id, kind) DO UPDATE " +
+		"SET reason = EXCLUDED.reason, until = EXCLUDED.until, created_by = EXCLUDED.created_by, created_at = now()), " +
+		"lg AS ( " +
+		"INSERT INTO moderation_log(moderator_id, action, target_id, reason, until) " +
+		"SELECT (SELECT id FROM md), $6, u.id, $3, $4 FROM u) " +
+		"SELECT id FROM u"
+
+	var until *time.Time
+	if !s.Until.IsZero() {
+		until = &s.Until
+	}
+
+	return p.change(query, entities.ErrUserNotFound,
+		s.Username, string(s.Kind), s.Reason, until, entry.Moderator, string(entry.Action))
+}
+
+func (p *ModerationSqlRepos) LiftSanction(username string, kind entities.SanctionKind, entry entities.AuditEntry) error {
+	query := "WITH d AS ( " +
+		"DELETE FROM sanctions s USING users u " +
+		"WHERE s.user_id = u.id AND u.username = $1 AND s.kind = $2 AND (s.until IS NULL OR s.until > now()) " +
+		"RETURNING s.user_id), " +
+		"lg AS ( " +
+		"INSERT INTO moderation_log(moderator_id, action, target_id, reason) " +
+		"SELECT (SELECT id FROM users WHERE username = $3), $4, d.user_id, $5 FROM d) " +
+		"SELECT user_id FROM d"
+
+	return p.change(query, entities.ErrSanctionNotFound,
+		username, string(kind), entry.Moderator, string(entry.Action), entry.Reason)
+}
+
+// GetActiveSanctions returns the sanctions of the user in force at now.
+func (p *ModerationSqlRepos) GetActiveSanctions(username string, now time.Time) ([]entities.Sanction, error) {
+	query := sanctionSelect +
+		"WHERE u.username = $1 AND (s.until IS NULL OR s.until > $2) " +
+		"ORDER BY s.created_at DESC, s.kind"
+
+	return p.listSanctions(query, username, now)
+}
+
+// GetSanctions returns the sanctions of all the users in force at now, the
+// latest first.
+func (p *ModerationSqlRepos) GetSanctions(now time.Time) ([]entities.Sanction, error) {
+	query := sanctionSelect +
+		"WHERE s.until IS NULL OR s.until > $1 " +
+		"ORDER BY s.created_at DESC, u.username, s.kind"
+
+	return p.listSanctions(query, now)
+}
+
+// GetAuditLog returns the moderation actions, the latest first.
+func (p *ModerationSqlRepos) GetAuditLog(limit, offset int) ([]entities.AuditEntry, error) {
+	query := "SELECT l.id, mu.username AS moderator, l.action, tu.username AS target, " +
+		"l.message_id, l.report_id, l.reason, l.until, l.created_at " +
+		"FROM moderation_log l " +
+		"JOIN users mu ON mu.id = l.moderator_id " +
+		"LEFT JOIN users tu ON tu.id = l.target_id " +
+		"ORDER BY l.id DESC " +
+		"LIMIT $1 OFFSET $2"
+
+	rows, err := p.db.Get(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]entities.AuditEntry, 0)
+	for rows.Next() {
+		var model models.AuditEntryModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, mapper.AuditEntryModelToEntity(model))
+	}
+
+	return entries, nil
+}
+
+func (p *ModerationSqlRepos) getReport(id int) (entities.Report, error) {
+	reports, err := p.listReports(reportSelect+"WHERE r.id = $1", id)
+	if err != nil {
+		return entities.Report{}, err
+	}
+
+	if len(reports) == 0 {
+		return entities.Report{}, entities.ErrReportNotFound
+	}
+
+	return reports[0], nil
+}
+
+func (p *ModerationSqlRepos) listReports(query string, args ...interface{}) ([]entities.Report, error) {
+	rows, err := p.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]entities.Report, 0)
+	for rows.Next() {
+		var model models.ReportModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, mapper.ReportModelToEntity(model))
+	}
+
+	return reports, nil
+}
+
+func (p *ModerationSqlRepos) listSanctions(query string, args ...interface{}) ([]entities.Sanction, error) {
+	rows, err := p.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sanctions := make([]entities.Sanction, 0)
+	for rows.Next() {
+		var model models.SanctionModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		sanctions = append(sanctions, mapper.SanctionModelToEntity(model))
+	}
+
+	return sanctions, nil
+}
+
+// change runs the statement and returns notFound when it affects no row.
+func (p *ModerationSqlRepos) change(query string, notFound error, args ...interface{}) error {
+	rows, err := p.db.Get(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"time"
+)
+
+type PresencePostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type PresenceSqlRepos struct {
+	db PresencePostgresDB
+}
+
+func NewPresenceSqlRepos(db PresencePostgresDB) *PresenceSqlRepos {
+	return &PresenceSqlRepos{db: db}
+}
+
+func (p *PresenceSqlRepos) UpdateLastSeen(username string, lastSeen time.Time) error {
+	query := "UPDATE users SET last_seen = GREATEST(COALESCE(last_seen, $2), $2) WHERE username = $1"
+
+	if err := p.db.Insert(query, username, lastSeen); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *PresenceSqlRepos) GetLastSeen(usernames []string) (map[string]time.Time, error) {
+	query := "SELECT username, last_seen FROM users WHERE username = ANY($1) AND last_seen IS NOT NULL"
+
+	rows, err := p.db.Get(query, usernames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastSeen := make(map[string]time.Time, len(usernames))
+	for rows.Next() {
+		var model models.LastSeenModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		lastSeen[model.Username] = model.LastSeen
+	}
+
+	return lastSeen, nil
+}
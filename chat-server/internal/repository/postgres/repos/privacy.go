@@ -0,0 +1,161 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+type PrivacyPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type PrivacySqlRepos struct {
+	db PrivacyPostgresDB
+}
+
+func NewPrivacySqlRepos(db PrivacyPostgresDB) *PrivacySqlRepos {
+	return &PrivacySqlRepos{db: db}
+}
+
+func (p *PrivacySqlRepos) GetPrivacySettings(user string) (entities.PrivacySettings, error) {
+	query := "SELECT ps.dm_policy, ps.hide_blocked_in_feed " +
+		"FROM privacy_settings ps " +
+		"JOIN users u ON u.id = ps.user_id " +
+		"WHERE u.username = $1"
+
+	rows, err := p.db.Get(query, user)
+	if err != nil {
+		return entities.PrivacySettings{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return entities.PrivacySettings{}, err
+		}
+
+		return entities.DefaultPrivacySettings(), nil
+	}
+
+	var model models.PrivacySettingsModel
+	if err := rows.StructScan(&model); err != nil {
+		return entities.PrivacySettings{}, err
+	}
+
+	return mapper.PrivacySettingsModelToEntity(model), nil
+}
+
+func (p *PrivacySqlRepos) UpdatePrivacySettings(user string, settings entities.PrivacySettings) error {
+	query := "INSERT INTO privacy_settings(user_id, dm_policy, hide_blocked_in_feed) " +
+		"SELECT id, $2, $3 FROM users WHERE username = $1 " +
+		"ON CONFLICT (user_id) DO UPDATE " +
+		"SET dm_policy = EXCLUDED.dm_policy, hide_blocked_in_feed = EXCLUDED.hide_blocked_in_feed"
+
+	if err := p.db.Insert(query, user, string(settings.DMPolicy), settings.HideBlockedInFeed); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *PrivacySqlRepos) BlockUser(user, blocked string) error {
+	query := "WITH b AS (SELECT id FROM users WHERE username = $2), " +
+		"ins AS ( " +
+		"INSERT INTO blocks(user_id, blocked_id) " +
+		"SELECT u.id, b.id FROM users u, b WHERE u.username = $1 " +
+		"ON CONFLICT DO NOTHING) " +
+		"SELECT id FROM b"
+
+	rows, err := p.db.Get(query, user, blocked)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return entities.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (p *PrivacySqlRepos) UnblockUser(user, blocked string) error {
+	query := "DELETE FROM blocks " +
+		"WHERE user_id = (SELECT id FROM users WHERE username = $1) " +
+		"AND blocked_id = (SELECT id FROM users WHERE username = $2)"
+
+	if err := p.db.Insert(query, user, blocked); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *PrivacySqlRepos) GetBlockedUsers(user string) ([]entities.BlockedUser, error) {
+	query := "SELECT bu.username, b.created_at " +
+		"FROM blocks b " +
+		"JOIN users bu ON bu.id = b.blocked_id " +
+		"WHERE b.user_id = (SELECT id FROM users WHERE username = $1) " +
+		"ORDER BY b.created_at DESC, bu.username"
+
+	rows, err := p.db.Get(query, user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocked := make([]entities.BlockedUser, 0)
+	for rows.Next() {
+		var model models.BlockedUserModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		blocked = append(blocked, mapper.BlockedUserModelToEntity(model))
+	}
+
+	return blocked, nil
+}
+
+func (p *PrivacySqlRepos) IsBlocked(user, other string) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM blocks b " +
+		"JOIN users u ON u.id = b.user_id " +
+		"JOIN users o ON o.id = b.blocked_id " +
+		"WHERE u.username = $1 AND o.username = $2)"
+
+	return p.exists(query, user, other)
+}
+
+func (p *PrivacySqlRepos) IsContact(user, other string) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM contacts c " +
+		"JOIN users u ON u.id = c.user_id " +
+		"JOIN users o ON o.id = c.contact_id " +
+		"WHERE u.username = $1 AND o.username = $2)"
+
+	return p.exists(query, user, other)
+}
+
+func (p *PrivacySqlRepos) exists(query string, args ...interface{}) (bool, error) {
+	rows, err := p.db.Get(query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var exists bool
+	if rows.Next() {
+		if err := rows.Scan(&exists); err != nil {
+			return false, err
+		}
+	}
+
+	return exists, rows.Err()
+}
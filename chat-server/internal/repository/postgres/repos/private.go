@@ -1,21 +1,36 @@
 package repos
 
 import (
+	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/vavelour/chat/internal/domain/entities"
 	"github.com/vavelour/chat/internal/repository/postgres/mapper"
 	"github.com/vavelour/chat/internal/repository/postgres/models"
-	"sync"
+	"time"
 )
 
+var ErrChatIsNotExists = errors.New("no chat with this user")
+
+// privateExpired tells whether the content of the message is gone: purged
+// by the reaper or past its expiry and waiting for it.
+const privateExpired = "(%[1]s.expired OR COALESCE(%[1]s.expires_at <= now(), FALSE))"
+
+// privateContent selects the content of the message, empty once it has
+// expired, with its expiry.
+func privateContent(alias string) string {
+	expired := fmt.Sprintf(privateExpired, alias)
+
+	return "CASE WHEN " + expired + " THEN '' ELSE " + alias + ".message END AS message, " +
+		expired + " AS expired, " + alias + ".expires_at"
+}
+
 type PrivatePostgresDB interface {
-	Insert(query string) error
-	Get(query string) (*sqlx.Rows, error)
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
 }
 
 type PrivateSqlRepos struct {
-	mu sync.RWMutex
 	db PrivatePostgresDB
 }
 
@@ -24,36 +39,62 @@ func NewPrivateSqlRepos(db PrivatePostgresDB) *PrivateSqlRepos {
 }
 
 func (p *PrivateSqlRepos) InsertMessage(m entities.Message) error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	// The messages of a stranger wait in the message requests of the
+	// recipient until they accept them or reply, which clears is_request.
+	query := "WITH m AS ( " +
+		"INSERT INTO private_chats(sender_id, recipient_id, message, ttl_seconds, expire_after_read, expires_at) " +
+		"VALUES ((SELECT id FROM users WHERE username = $1), (SELECT id FROM users WHERE username = $2), $3, " +
+		"NULLIF($9::INTEGER, 0), $10::BOOLEAN, CASE WHEN $9::INTEGER > 0 AND NOT $10::BOOLEAN THEN now() + make_interval(secs => $9::INTEGER) END) " +
+		"RETURNING id, sender_id, recipient_id, created_at), " +
+		"att AS ( " +
+		"INSERT INTO attachments(id, private_message_id, file_name, content_type, size, position) " +
+		"SELECT a.id, m.id, a.file_name, a.content_type, a.size, a.position " +
+		"FROM m, " + attachmentValues(4) + "), " +
+		"nt AS ( " +
+		"INSERT INTO notifications(user_id, kind, sender_id, private_message_id) " +
+		"SELECT recipient_id, $8, sender_id, id FROM m WHERE recipient_id <> sender_id), " +
+		"ct AS ( " +
+		"INSERT INTO contacts(user_id, contact_id) " +
+		"SELECT sender_id, recipient_id FROM m " +
+		"ON CONFLICT DO NOTHING) " +
+		"INSERT INTO conversations(user_id, partner_id, last_message_id, last_message_at, is_request) " +
+		"SELECT sender_id, recipient_id, id, created_at, FALSE FROM m " +
+		"UNION ALL " +
+		"SELECT recipient_id, sender_id, id, created_at, " +
+		"NOT EXISTS (SELECT 1 FROM contacts c WHERE c.user_id = m.recipient_id AND c.contact_id = m.sender_id) " +
+		"FROM m WHERE recipient_id <> sender_id " +
+		"ON CONFLICT (user_id, partner_id) DO UPDATE " +
+		"SET last_message_id = EXCLUDED.last_message_id, last_message_at = EXCLUDED.last_message_at, " +
+		"is_request = conversations.is_request AND EXCLUDED.is_request"
 
-	query := fmt.Sprintf("INSERT INTO private_chats(sender_id, recipient_id, message) "+
-		"VALUES ( "+
-		"(SELECT id FROM users WHERE username = '%s'), "+
-		"(SELECT id FROM users WHERE username = '%s'), "+
-		"'%s')", m.Sender, m.Recipient, m.Content)
+	args := append([]interface{}{m.Sender, m.Recipient, m.Content}, attachmentArgs(m.Attachments)...)
+	args = append(args, string(entities.NotificationPrivateMessage), int(m.TTL/time.Second), m.ExpireAfterRead)
 
-	if err := p.db.Insert(query); err != nil {
+	if err := p.db.Insert(query, args...); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (p *PrivateSqlRepos) GetMessages(sender, recipient string, limit, offset int) ([]entities.Message, error) {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
-	query := fmt.Sprintf("SELECT sender_id, recipient_id, message "+
-		"FROM private_chats "+
-		"WHERE (sender_id = (SELECT id FROM users WHERE username = '%s') AND recipient_id = (SELECT id FROM users WHERE username = '%s')) "+
-		"OR (sender_id = (SELECT id FROM users WHERE username = '%s') AND recipient_id = (SELECT id FROM users WHERE username = '%s')) "+
-		"LIMIT %d OFFSET %d", sender, recipient, recipient, sender, limit, offset)
+func (p *PrivateSqlRepos) GetMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error) {
+	// The conversation is looked up by the pair of its members in either
+	// order, the way private_chats_pair_idx keeps it.
+	query := "SELECT pc.id, su.username AS sender, ru.username AS recipient, " + privateContent("pc") + ", pc.created_at " +
+		"FROM (SELECT LEAST(a.id, b.id) AS low, GREATEST(a.id, b.id) AS high " +
+		"FROM users a, users b WHERE a.username = $1 AND b.username = $2) pair " +
+		"JOIN private_chats pc ON LEAST(pc.sender_id, pc.recipient_id) = pair.low " +
+		"AND GREATEST(pc.sender_id, pc.recipient_id) = pair.high " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		orderMessages("pc", order) +
+		"LIMIT $3 OFFSET $4"
 
-	rows, err := p.db.Get(query)
+	rows, err := p.db.Get(query, sender, recipient, limit, offset)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	chat := models.ChatModel{Messages: make([]models.MessageModel, 0)}
 	for rows.Next() {
@@ -66,29 +107,39 @@ func (p *PrivateSqlRepos) GetMessages(sender, recipient string, limit, offset in
 		chat.Messages = append(chat.Messages, message)
 	}
 
+	if err := loadAttachments(p.db, privateMessageColumn, chat.Messages); err != nil {
+		return nil, err
+	}
+
 	return mapper.MessageModelToEntities(chat), nil
+}
+
+func (p *PrivateSqlRepos) GetAttachment(id string) (entities.Attachment, entities.Message, error) {
+	query := "SELECT a.id, a.private_message_id AS message_id, a.file_name, a.content_type, a.size, a.created_at, " +
+		"su.username AS sender, ru.username AS recipient " +
+		"FROM attachments a " +
+		"JOIN private_chats pc ON pc.id = a.private_message_id " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		"WHERE a.id = $1 AND NOT " + fmt.Sprintf(privateExpired, "pc")
 
+	return getAttachment(p.db, query, id)
 }
 
 func (p *PrivateSqlRepos) GetUsers(user string) ([]string, error) {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
 	var userList models.UserListModel
-	query := fmt.Sprintf("SELECT DISTINCT u.username "+
-		"FROM users u "+
-		"JOIN private_chats pc ON u.id = pc.sender_id "+
-		"WHERE pc.recipient_id = (SELECT id FROM users WHERE username = '%s') "+
-		"UNION "+
-		"SELECT DISTINCT u.username "+
-		"FROM users u "+
-		"JOIN private_chats pc ON u.id = pc.recipient_id "+
-		"WHERE pc.sender_id = (SELECT id FROM users WHERE username = '%s')", user, user)
-
-	rows, err := p.db.Get(query)
+	query := "SELECT pt.username " +
+		"FROM users u " +
+		"JOIN conversations c ON c.user_id = u.id AND c.is_request = FALSE " +
+		"JOIN users pt ON pt.id = c.partner_id " +
+		"WHERE u.username = $1 " +
+		"ORDER BY pt.username"
+
+	rows, err := p.db.Get(query, user)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var user string
@@ -102,3 +153,204 @@ func (p *PrivateSqlRepos) GetUsers(user string) ([]string, error) {
 
 	return userList.Usernames, nil
 }
+
+func (p *PrivateSqlRepos) MarkAsRead(reader, partner string, messageID int) error {
+	// The messages expiring after read start their countdown when the
+	// reader first reads them.
+	query := "WITH rd AS ( " +
+		"INSERT INTO private_chat_reads(reader_id, partner_id, last_read_message_id) " +
+		"SELECT r.id, pt.id, MAX(pc.id) " +
+		"FROM users r " +
+		"JOIN users pt ON pt.username = $2 " +
+		"JOIN private_chats pc ON (pc.sender_id = r.id AND pc.recipient_id = pt.id) " +
+		"OR (pc.sender_id = pt.id AND pc.recipient_id = r.id) " +
+		"WHERE r.username = $1 AND ($3::INTEGER = 0 OR pc.id <= $3::INTEGER) " +
+		"GROUP BY r.id, pt.id " +
+		"ON CONFLICT (reader_id, partner_id) DO UPDATE " +
+		"SET last_read_message_id = GREATEST(private_chat_reads.last_read_message_id, EXCLUDED.last_read_message_id) " +
+		"RETURNING reader_id, partner_id, last_read_message_id), " +
+		"ex AS ( " +
+		"UPDATE private_chats pc SET expires_at = now() + make_interval(secs => pc.ttl_seconds) " +
+		"FROM rd " +
+		"WHERE pc.sender_id = rd.partner_id AND pc.recipient_id = rd.reader_id AND pc.id <= rd.last_read_message_id " +
+		"AND pc.expire_after_read AND pc.expires_at IS NULL) " +
+		"SELECT last_read_message_id FROM rd"
+
+	rows, err := p.db.Get(query, reader, partner, messageID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return ErrChatIsNotExists
+	}
+
+	return rows.Err()
+}
+
+func (p *PrivateSqlRepos) GetConversations(user string, partners []string) ([]entities.Conversation, error) {
+	query := "SELECT pt.username, " +
+		"(SELECT COUNT(*) FROM private_chats pc " +
+		"WHERE pc.sender_id = pt.id AND pc.recipient_id = u.id AND pc.id > COALESCE(r.last_read_message_id, 0)) AS unread_count, " +
+		"COALESCE((SELECT MAX(pc.id) FROM private_chats pc " +
+		"WHERE pc.sender_id = u.id AND pc.recipient_id = pt.id) <= pr.last_read_message_id, FALSE) AS seen_by_partner " +
+		"FROM users u " +
+		"JOIN users pt ON pt.username = ANY($2) " +
+		"LEFT JOIN private_chat_reads r ON r.reader_id = u.id AND r.partner_id = pt.id " +
+		"LEFT JOIN private_chat_reads pr ON pr.reader_id = pt.id AND pr.partner_id = u.id " +
+		"WHERE u.username = $1"
+
+	rows, err := p.db.Get(query, user, partners)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byPartner := make(map[string]models.ConversationModel, len(partners))
+	for rows.Next() {
+		var conversation models.ConversationModel
+		err := rows.StructScan(&conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		byPartner[conversation.Partner] = conversation
+	}
+
+	return mapper.ConversationModelsToEntities(partners, byPartner), nil
+}
+
+func (p *PrivateSqlRepos) GetInbox(user string, limit, offset int) ([]entities.Conversation, error) {
+	return p.listConversations(user, false, limit, offset)
+}
+
+// GetMessageRequests returns the conversations started by the strangers
+// that user has not accepted yet, the latest first.
+func (p *PrivateSqlRepos) GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error) {
+	return p.listConversations(user, true, limit, offset)
+}
+
+// AcceptMessageRequest moves the conversation with partner to the inbox of
+// user and lets partner write to them as a contact.
+func (p *PrivateSqlRepos) AcceptMessageRequest(user, partner string) error {
+	query := "WITH acc AS ( " +
+		"UPDATE conversations SET is_request = FALSE " +
+		"WHERE user_id = (SELECT id FROM users WHERE username = $1) " +
+		"AND partner_id = (SELECT id FROM users WHERE username = $2) AND is_request " +
+		"RETURNING user_id, partner_id), " +
+		"ct AS ( " +
+		"INSERT INTO contacts(user_id, contact_id) " +
+		"SELECT user_id, partner_id FROM acc " +
+		"ON CONFLICT DO NOTHING) " +
+		"SELECT partner_id FROM acc"
+
+	return p.changeMessageRequest(query, user, partner)
+}
+
+// DeclineMessageRequest removes the request of partner. The messages stay,
+// and a new message of partner makes a new request.
+func (p *PrivateSqlRepos) DeclineMessageRequest(user, partner string) error {
+	query := "DELETE FROM conversations " +
+		"WHERE user_id = (SELECT id FROM users WHERE username = $1) " +
+		"AND partner_id = (SELECT id FROM users WHERE username = $2) AND is_request " +
+		"RETURNING partner_id"
+
+	return p.changeMessageRequest(query, user, partner)
+}
+
+func (p *PrivateSqlRepos) changeMessageRequest(query, user, partner string) error {
+	rows, err := p.db.Get(query, user, partner)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return entities.ErrMessageRequestNotFound
+	}
+
+	return nil
+}
+
+func (p *PrivateSqlRepos) listConversations(user string, requests bool, limit, offset int) ([]entities.Conversation, error) {
+	query := "SELECT pt.username, " +
+		"(SELECT COUNT(*) FROM private_chats pc " +
+		"WHERE pc.sender_id = c.partner_id AND pc.recipient_id = c.user_id AND pc.id > COALESCE(r.last_read_message_id, 0)) AS unread_count, " +
+		"COALESCE((SELECT MAX(pc.id) FROM private_chats pc " +
+		"WHERE pc.sender_id = c.user_id AND pc.recipient_id = c.partner_id) <= pr.last_read_message_id, FALSE) AS seen_by_partner, " +
+		"m.id, su.username AS sender, ru.username AS recipient, " + privateContent("m") + ", m.created_at " +
+		"FROM conversations c " +
+		"JOIN users pt ON pt.id = c.partner_id " +
+		"JOIN private_chats m ON m.id = c.last_message_id " +
+		"JOIN users su ON su.id = m.sender_id " +
+		"JOIN users ru ON ru.id = m.recipient_id " +
+		"LEFT JOIN private_chat_reads r ON r.reader_id = c.user_id AND r.partner_id = c.partner_id " +
+		"LEFT JOIN private_chat_reads pr ON pr.reader_id = c.partner_id AND pr.partner_id = c.user_id " +
+		"WHERE c.user_id = (SELECT id FROM users WHERE username = $1) AND c.is_request = $4 " +
+		"ORDER BY c.last_message_at DESC, c.partner_id " +
+		"LIMIT $2 OFFSET $3"
+
+	rows, err := p.db.Get(query, user, limit, offset, requests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inbox := make([]models.InboxModel, 0)
+	for rows.Next() {
+		var conversation models.InboxModel
+		err := rows.StructScan(&conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		inbox = append(inbox, conversation)
+	}
+
+	return mapper.InboxModelsToEntities(inbox), nil
+}
+
+// SearchMessages looks for messages of the private conversations the user
+// takes part in.
+func (p *PrivateSqlRepos) SearchMessages(user string, q entities.SearchQuery) ([]entities.SearchResult, error) {
+	filters, args := searchFilters(q, "pc", privateSearchChat, []interface{}{user})
+	args = append(args, q.Limit)
+
+	query := "SELECT pc.id, su.username AS sender, ru.username AS recipient, pc.message, pc.created_at " +
+		"FROM private_chats pc " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		"WHERE (su.username = $1 OR ru.username = $1) AND NOT " + fmt.Sprintf(privateExpired, "pc") + " AND " + filters + " " +
+		fmt.Sprintf("ORDER BY pc.created_at DESC, pc.id DESC LIMIT $%d", len(args))
+
+	return searchMessages(p.db, query, privateSearchChat, args...)
+}
+
+// PurgeExpiredMessages replaces up to limit messages expired at now with
+// tombstones, the longest expired first, and returns how many it purged
+// together with the IDs of the attachments it removed, whose files are left
+// to the caller. The rows are locked with SKIP LOCKED, so several servers
+// purge different messages.
+func (p *PrivateSqlRepos) PurgeExpiredMessages(now time.Time, limit int) (int, []string, error) {
+	query := "WITH due AS ( " +
+		"SELECT id FROM private_chats WHERE expires_at <= $1 AND NOT expired " +
+		"ORDER BY expires_at, id LIMIT $2 FOR UPDATE SKIP LOCKED), " +
+		"att AS ( " +
+		"DELETE FROM attachments WHERE private_message_id IN (SELECT id FROM due) RETURNING id, private_message_id), " +
+		"purged AS ( " +
+		"UPDATE private_chats pc SET message = '', expired = TRUE FROM due WHERE pc.id = due.id RETURNING pc.id) " +
+		"SELECT purged.id, att.id FROM purged LEFT JOIN att ON att.private_message_id = purged.id"
+
+	rows, err := p.db.Get(query, now, limit)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	return purgedAttachments(rows)
+}
@@ -6,16 +6,14 @@ import (
 	"github.com/vavelour/chat/internal/domain/entities"
 	"github.com/vavelour/chat/internal/repository/postgres/mapper"
 	"github.com/vavelour/chat/internal/repository/postgres/models"
-	"sync"
 )
 
 type PublicPostgresDB interface {
-	Insert(query string) error
-	Get(query string) (*sqlx.Rows, error)
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
 }
 
 type PublicSqlRepos struct {
-	mu sync.RWMutex
 	db PublicPostgresDB
 }
 
@@ -24,25 +22,44 @@ func NewPublicSqlRepos(db PublicPostgresDB) *PublicSqlRepos {
 }
 
 func (pub *PublicSqlRepos) InsertMessage(m entities.Message) error {
-	pub.mu.Lock()
-	defer pub.mu.Unlock()
+	query := "WITH m AS ( " +
+		"INSERT INTO global_chat(sender_id, message) " +
+		"VALUES ((SELECT id FROM users WHERE username = $1), $2) " +
+		"RETURNING id, sender_id, created_at), " +
+		"att AS ( " +
+		"INSERT INTO attachments(id, global_message_id, file_name, content_type, size, position) " +
+		"SELECT a.id, m.id, a.file_name, a.content_type, a.size, a.position " +
+		"FROM m, " + attachmentValues(3) + "), " +
+		"ev AS ( " +
+		"INSERT INTO webhook_events(type, channel, message_id, sender, message, created_at) " +
+		"SELECT $9, $10, m.id, $1, $2, m.created_at FROM m " +
+		"WHERE EXISTS (SELECT 1 FROM webhooks w " + subscribedWebhook + ") " +
+		"RETURNING id), " +
+		"wd AS ( " +
+		"INSERT INTO webhook_deliveries(webhook_id, event_id) " +
+		"SELECT w.id, ev.id FROM ev, webhooks w " + subscribedWebhook + ") " +
+		"INSERT INTO notifications(user_id, kind, sender_id, global_message_id) " +
+		"SELECT u.id, $7, m.sender_id, m.id " +
+		"FROM m JOIN users u ON u.username = ANY($8::VARCHAR[])"
 
-	query := fmt.Sprintf("INSERT INTO global_chat(sender_id, message) VALUES ((SELECT id FROM users WHERE username = '%s'), '%s')", m.Sender, m.Content)
+	args := append([]interface{}{m.Sender, m.Content}, attachmentArgs(m.Attachments)...)
+	args = append(args, string(entities.NotificationMention), m.Mentions, string(entities.EventMessageCreated), entities.PublicChannel)
 
-	if err := pub.db.Insert(query); err != nil {
+	if err := pub.db.Insert(query, args...); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (pub *PublicSqlRepos) GetMessages(limit, offset int) ([]entities.Message, error) {
-	pub.mu.RLock()
-	defer pub.mu.RUnlock()
+func (pub *PublicSqlRepos) GetMessages(limit, offset int, order entities.Order) ([]entities.Message, error) {
+	query := "SELECT gc.id, u.username AS sender, '' AS recipient, gc.message, gc.created_at, gc.deleted " +
+		"FROM global_chat gc " +
+		"JOIN users u ON u.id = gc.sender_id " +
+		orderMessages("gc", order) +
+		"LIMIT $1 OFFSET $2"
 
-	query := fmt.Sprintf("SELECT sender_id, message FROM global_chat LIMIT %d OFFSET %d", limit, offset)
-
-	rows, err := pub.db.Get(query)
+	rows, err := pub.db.Get(query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -60,5 +77,33 @@ func (pub *PublicSqlRepos) GetMessages(limit, offset int) ([]entities.Message, e
 		chat.Messages = append(chat.Messages, message)
 	}
 
+	if err := loadAttachments(pub.db, globalMessageColumn, chat.Messages); err != nil {
+		return nil, err
+	}
+
 	return mapper.MessageModelToEntities(chat), nil
 }
+
+func (pub *PublicSqlRepos) GetAttachment(id string) (entities.Attachment, entities.Message, error) {
+	query := "SELECT a.id, a.global_message_id AS message_id, a.file_name, a.content_type, a.size, a.created_at, " +
+		"u.username AS sender, '' AS recipient " +
+		"FROM attachments a " +
+		"JOIN global_chat gc ON gc.id = a.global_message_id " +
+		"JOIN users u ON u.id = gc.sender_id " +
+		"WHERE a.id = $1"
+
+	return getAttachment(pub.db, query, id)
+}
+
+func (pub *PublicSqlRepos) SearchMessages(q entities.SearchQuery) ([]entities.SearchResult, error) {
+	filters, args := searchFilters(q, "gc", publicSearchChat, nil)
+	args = append(args, q.Limit)
+
+	query := "SELECT gc.id, su.username AS sender, '' AS recipient, gc.message, gc.created_at " +
+		"FROM global_chat gc " +
+		"JOIN users su ON su.id = gc.sender_id " +
+		"WHERE " + filters + " " +
+		fmt.Sprintf("ORDER BY gc.created_at DESC, gc.id DESC LIMIT $%d", len(args))
+
+	return searchMessages(pub.db, query, publicSearchChat, args...)
+}
@@ -0,0 +1,152 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+// reminderSelect reads the rows rem with the names of their senders.
+const reminderSelect = "SELECT rem.id, rem.name, rem.content, rem.schedule, rem.time_zone, su.username AS sender, " +
+	"rem.paused, rem.next_run_at, rem.last_run_at, rem.created_by, rem.created_at " +
+	"FROM rem JOIN users su ON su.id = rem.sender_id "
+
+type ReminderPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type ReminderSqlRepos struct {
+	db ReminderPostgresDB
+}
+
+func NewReminderSqlRepos(db ReminderPostgresDB) *ReminderSqlRepos {
+	return &ReminderSqlRepos{db: db}
+}
+
+// InsertReminder saves the reminder and registers its sender as a bot with
+// the given password, unless the bot exists already.
+func (r *ReminderSqlRepos) InsertReminder(rem entities.Reminder, senderPassword string) (entities.Reminder, error) {
+	if err := checkBotName(r.db, rem.Sender); err != nil {
+		return entities.Reminder{}, err
+	}
+
+	query := botCTE + ", " +
+		"rem AS ( " +
+		"INSERT INTO reminders(name, content, schedule, time_zone, sender_id, next_run_at, created_by) " +
+		"SELECT $3, $4, $5, $6, user_id, $7, $8 FROM bot " +
+		"RETURNING *) " +
+		"SELECT rem.id, rem.name, rem.content, rem.schedule, rem.time_zone, $1 AS sender, " +
+		"rem.paused, rem.next_run_at, rem.last_run_at, rem.created_by, rem.created_at FROM rem"
+
+	reminders, err := r.reminders(query, rem.Sender, senderPassword, rem.Name, rem.Content, rem.Schedule, rem.TimeZone, rem.NextRunAt, rem.CreatedBy)
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	if len(reminders) == 0 {
+		return entities.Reminder{}, entities.ErrReminderNotFound
+	}
+
+	return reminders[0], nil
+}
+
+func (r *ReminderSqlRepos) GetReminders() ([]entities.Reminder, error) {
+	return r.reminders("WITH rem AS (SELECT * FROM reminders) " + reminderSelect + "ORDER BY rem.id")
+}
+
+func (r *ReminderSqlRepos) GetReminder(id int) (entities.Reminder, error) {
+	return r.reminder("WITH rem AS (SELECT * FROM reminders WHERE id = $1) "+reminderSelect, id)
+}
+
+func (r *ReminderSqlRepos) PauseReminder(id int) (entities.Reminder, error) {
+	query := "WITH rem AS ( " +
+		"UPDATE reminders SET paused = TRUE, updated_at = now() WHERE id = $1 RETURNING *) " +
+		reminderSelect
+
+	return r.reminder(query, id)
+}
+
+// ResumeReminder turns the reminder back on from nextRunAt, so the runs
+// missed while it was paused are not posted.
+func (r *ReminderSqlRepos) ResumeReminder(id int, nextRunAt time.Time) (entities.Reminder, error) {
+	query := "WITH rem AS ( " +
+		"UPDATE reminders SET paused = FALSE, next_run_at = $2, updated_at = now() WHERE id = $1 RETURNING *) " +
+		reminderSelect
+
+	return r.reminder(query, id, nextRunAt)
+}
+
+func (r *ReminderSqlRepos) DeleteReminder(id int) error {
+	_, err := r.reminder("WITH rem AS (DELETE FROM reminders WHERE id = $1 RETURNING *) "+reminderSelect, id)
+
+	return err
+}
+
+// GetDueReminders returns up to limit active reminders due at now, the
+// longest overdue first.
+func (r *ReminderSqlRepos) GetDueReminders(now time.Time, limit int) ([]entities.Reminder, error) {
+	query := "WITH rem AS ( " +
+		"SELECT * FROM reminders WHERE NOT paused AND next_run_at <= $1 " +
+		"ORDER BY next_run_at, id LIMIT $2) " +
+		reminderSelect +
+		"ORDER BY rem.next_run_at, rem.id"
+
+	return r.reminders(query, now, limit)
+}
+
+// AdvanceReminder moves the reminder from the run due at to the next one,
+// if it is still due at that time and active. The update is conditional,
+// so of several servers running the same reminder only one advances it and
+// posts the run.
+func (r *ReminderSqlRepos) AdvanceReminder(id int, due, next time.Time, posted bool) (bool, error) {
+	query := "WITH rem AS ( " +
+		"UPDATE reminders SET next_run_at = $3, " +
+		"last_run_at = CASE WHEN $4 THEN $2 ELSE last_run_at END, updated_at = now() " +
+		"WHERE id = $1 AND next_run_at = $2 AND NOT paused RETURNING *) " +
+		reminderSelect
+
+	reminders, err := r.reminders(query, id, due, next, posted)
+	if err != nil {
+		return false, err
+	}
+
+	return len(reminders) > 0, nil
+}
+
+func (r *ReminderSqlRepos) reminder(query string, args ...interface{}) (entities.Reminder, error) {
+	reminders, err := r.reminders(query, args...)
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	if len(reminders) == 0 {
+		return entities.Reminder{}, entities.ErrReminderNotFound
+	}
+
+	return reminders[0], nil
+}
+
+func (r *ReminderSqlRepos) reminders(query string, args ...interface{}) ([]entities.Reminder, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := make([]entities.Reminder, 0)
+	for rows.Next() {
+		var model models.ReminderModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		reminders = append(reminders, mapper.ReminderModelToEntity(model))
+	}
+
+	return reminders, nil
+}
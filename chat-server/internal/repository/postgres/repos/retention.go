@@ -0,0 +1,221 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"strings"
+	"time"
+)
+
+// notHeld tells that none of the users is on legal hold.
+func notHeld(users ...string) string {
+	return "NOT EXISTS (SELECT 1 FROM legal_holds h WHERE h.user_id IN (" + strings.Join(users, ", ") + "))"
+}
+
+type RetentionPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// RetentionSqlRepos removes the messages older than the retention policies
+// allow. Every batch is a statement of its own, so the purge never holds
+// the locks of more rows than a batch has; the attachments, notifications
+// and reports of the removed messages go with them by the cascade.
+type RetentionSqlRepos struct {
+	db RetentionPostgresDB
+}
+
+func NewRetentionSqlRepos(db RetentionPostgresDB) *RetentionSqlRepos {
+	return &RetentionSqlRepos{db: db}
+}
+
+// GetPublicMessagesBefore returns up to limit public messages sent before
+// the time by users not on hold, the oldest first.
+func (r *RetentionSqlRepos) GetPublicMessagesBefore(before time.Time, limit int) ([]entities.Message, error) {
+	query := "SELECT gc.id, u.username AS sender, '' AS recipient, gc.message, gc.created_at, gc.deleted " +
+		"FROM global_chat gc " +
+		"JOIN users u ON u.id = gc.sender_id " +
+		"WHERE gc.created_at < $1 AND " + notHeld("gc.sender_id") + " " +
+		"ORDER BY gc.created_at, gc.id " +
+		"LIMIT $2"
+
+	return r.messages(globalMessageColumn, query, before, limit)
+}
+
+// DeletePublicMessages removes the public messages with the IDs which are
+// still sent before the time by users not on hold, and returns how many it
+// removed and the IDs of their attachments. The attachments go by the
+// cascade, which the statement does not see yet, so it still reads them.
+func (r *RetentionSqlRepos) DeletePublicMessages(ids []int, before time.Time) (int, []string, error) {
+	query := "WITH deleted AS ( " +
+		"DELETE FROM global_chat gc " +
+		"WHERE gc.id = ANY($1::INTEGER[]) AND gc.created_at < $2 AND " + notHeld("gc.sender_id") + " " +
+		"RETURNING gc.id) " +
+		"SELECT deleted.id, a.id FROM deleted " +
+		"LEFT JOIN attachments a ON a.global_message_id = deleted.id"
+
+	return r.purged(query, ids, before)
+}
+
+// GetPrivateMessagesBefore returns up to limit private messages sent
+// before the time in the conversations of users not on hold, the oldest
+// first.
+func (r *RetentionSqlRepos) GetPrivateMessagesBefore(before time.Time, limit int) ([]entities.Message, error) {
+	query := "SELECT pc.id, su.username AS sender, ru.username AS recipient, " + privateContent("pc") + ", pc.created_at " +
+		"FROM private_chats pc " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		"WHERE pc.created_at < $1 AND " + notHeld("pc.sender_id", "pc.recipient_id") + " " +
+		"ORDER BY pc.created_at, pc.id " +
+		"LIMIT $2"
+
+	return r.messages(privateMessageColumn, query, before, limit)
+}
+
+// DeletePrivateMessages removes the private messages which are still sent
+// before the time in the conversations of users not on hold, and returns
+// how many it removed and the IDs of their attachments. A conversation whose
+// last message is removed moves to the latest message kept, and leaves the
+// inboxes when none is.
+func (r *RetentionSqlRepos) DeletePrivateMessages(messages []entities.Message, before time.Time) (int, []string, error) {
+	ids := make([]int, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+
+	query := "WITH deleted AS ( " +
+		"DELETE FROM private_chats pc " +
+		"WHERE pc.id = ANY($1::INTEGER[]) AND pc.created_at < $2 AND " + notHeld("pc.sender_id", "pc.recipient_id") + " " +
+		"RETURNING pc.id), " +
+		"affected AS ( " +
+		"SELECT c.user_id, c.partner_id FROM conversations c " +
+		"WHERE c.last_message_id IN (SELECT id FROM deleted)), " +
+		"latest AS ( " +
+		"SELECT a.user_id, a.partner_id, m.id, m.created_at FROM affected a " +
+		"CROSS JOIN LATERAL ( " +
+		"SELECT pc.id, pc.created_at FROM private_chats pc " +
+		"WHERE LEAST(pc.sender_id, pc.recipient_id) = LEAST(a.user_id, a.partner_id) " +
+		"AND GREATEST(pc.sender_id, pc.recipient_id) = GREATEST(a.user_id, a.partner_id) " +
+		"AND pc.id NOT IN (SELECT id FROM deleted) " +
+		"ORDER BY pc.id DESC LIMIT 1) m), " +
+		"moved AS ( " +
+		"UPDATE conversations c SET last_message_id = l.id, last_message_at = l.created_at " +
+		"FROM latest l WHERE c.user_id = l.user_id AND c.partner_id = l.partner_id), " +
+		"emptied AS ( " +
+		"DELETE FROM conversations c USING affected a " +
+		"WHERE c.user_id = a.user_id AND c.partner_id = a.partner_id " +
+		"AND NOT EXISTS (SELECT 1 FROM latest l WHERE l.user_id = a.user_id AND l.partner_id = a.partner_id)) " +
+		"SELECT deleted.id, a.id FROM deleted " +
+		"LEFT JOIN attachments a ON a.private_message_id = deleted.id"
+
+	return r.purged(query, ids, before)
+}
+
+// PlaceLegalHold puts the user on hold. A user already on hold keeps the
+// time the hold was first placed.
+func (r *RetentionSqlRepos) PlaceLegalHold(username string) error {
+	query := "WITH u AS (SELECT id FROM users WHERE username = $1), " +
+		"ins AS ( " +
+		"INSERT INTO legal_holds(user_id) SELECT id FROM u " +
+		"ON CONFLICT DO NOTHING) " +
+		"SELECT id FROM u"
+
+	return r.affectUser(query, username, entities.ErrUserNotFound)
+}
+
+func (r *RetentionSqlRepos) LiftLegalHold(username string) error {
+	query := "DELETE FROM legal_holds " +
+		"WHERE user_id = (SELECT id FROM users WHERE username = $1) " +
+		"RETURNING user_id"
+
+	return r.affectUser(query, username, entities.ErrLegalHoldNotFound)
+}
+
+// GetLegalHolds returns the users on hold in the order of their names.
+func (r *RetentionSqlRepos) GetLegalHolds() ([]entities.LegalHold, error) {
+	query := "SELECT u.username, h.placed_at " +
+		"FROM legal_holds h " +
+		"JOIN users u ON u.id = h.user_id " +
+		"ORDER BY u.username"
+
+	rows, err := r.db.Get(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := make([]entities.LegalHold, 0)
+	for rows.Next() {
+		var hold models.LegalHoldModel
+		if err := rows.StructScan(&hold); err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, mapper.LegalHoldModelToEntity(hold))
+	}
+
+	return holds, rows.Err()
+}
+
+func (r *RetentionSqlRepos) messages(column, query string, args ...interface{}) ([]entities.Message, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chat := models.ChatModel{Messages: make([]models.MessageModel, 0)}
+	for rows.Next() {
+		var message models.MessageModel
+		if err := rows.StructScan(&message); err != nil {
+			return nil, err
+		}
+
+		chat.Messages = append(chat.Messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadAttachments(r.db, column, chat.Messages); err != nil {
+		return nil, err
+	}
+
+	return mapper.MessageModelToEntities(chat), nil
+}
+
+// purged runs the query, which returns a row for every removed message and
+// attachment of it, and returns how many messages it removed and the IDs of
+// their attachments.
+func (r *RetentionSqlRepos) purged(query string, args ...interface{}) (int, []string, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	return purgedAttachments(rows)
+}
+
+// affectUser runs the query, which returns a row when it found what it
+// looked for, and returns notFound when it did not.
+func (r *RetentionSqlRepos) affectUser(query, username string, notFound error) error {
+	rows, err := r.db.Get(query, username)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+const (
+	// scheduledSelect reads the rows s with the names of their users.
+	scheduledSelect = "SELECT s.id, s.channel, su.username AS sender, COALESCE(ru.username, '') AS recipient, " +
+		"s.message, s.send_at, s.status, s.last_error, s.created_at " +
+		"FROM s JOIN users su ON su.id = s.sender_id LEFT JOIN users ru ON ru.id = s.recipient_id "
+)
+
+type ScheduledMessagePostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type ScheduledMessageSqlRepos struct {
+	db ScheduledMessagePostgresDB
+}
+
+func NewScheduledMessageSqlRepos(db ScheduledMessagePostgresDB) *ScheduledMessageSqlRepos {
+	return &ScheduledMessageSqlRepos{db: db}
+}
+
+func (r *ScheduledMessageSqlRepos) InsertScheduledMessage(m entities.ScheduledMessage) (entities.ScheduledMessage, error) {
+	query := "WITH s AS ( " +
+		"INSERT INTO scheduled_messages(channel, sender_id, recipient_id, message, send_at) " +
+		"VALUES ($1, (SELECT id FROM users WHERE username = $2), (SELECT id FROM users WHERE username = NULLIF($3, '')), $4, $5) " +
+		"RETURNING *) " + scheduledSelect
+
+	messages, err := r.scheduled(query, m.Channel, m.Sender, m.Recipient, m.Content, m.SendAt)
+	if err != nil {
+		return entities.ScheduledMessage{}, err
+	}
+
+	if len(messages) == 0 {
+		return entities.ScheduledMessage{}, entities.ErrScheduledMessageNotFound
+	}
+
+	return messages[0], nil
+}
+
+// GetScheduledMessages returns the messages of the sender which are not
+// sent yet, the next to go first.
+func (r *ScheduledMessageSqlRepos) GetScheduledMessages(sender string, limit, offset int) ([]entities.ScheduledMessage, error) {
+	query := "WITH s AS ( " +
+		"SELECT * FROM scheduled_messages " +
+		"WHERE sender_id = (SELECT id FROM users WHERE username = $1) AND status <> 'sent') " +
+		scheduledSelect +
+		"ORDER BY s.send_at, s.id LIMIT $2 OFFSET $3"
+
+	return r.scheduled(query, sender, limit, offset)
+}
+
+// DeleteScheduledMessage cancels a pending message or dismisses a failed one.
+// Messages which are being sent or were sent are not found.
+func (r *ScheduledMessageSqlRepos) DeleteScheduledMessage(sender string, id int) error {
+	query := "WITH s AS ( " +
+		"DELETE FROM scheduled_messages " +
+		"WHERE id = $2 AND sender_id = (SELECT id FROM users WHERE username = $1) AND status IN ('pending', 'failed') " +
+		"RETURNING *) " + scheduledSelect
+
+	messages, err := r.scheduled(query, sender, id)
+	if err != nil {
+		return err
+	}
+
+	if len(messages) == 0 {
+		return entities.ErrScheduledMessageNotFound
+	}
+
+	return nil
+}
+
+// ClaimScheduledMessages marks up to limit due messages as being sent and
+// returns them. A claimed message is never returned again, and SKIP LOCKED
+// keeps several servers from claiming the same rows, so every message is
+// sent at most once.
+func (r *ScheduledMessageSqlRepos) ClaimScheduledMessages(now time.Time, limit int) ([]entities.ScheduledMessage, error) {
+	query := "WITH c AS ( " +
+		"SELECT id FROM scheduled_messages " +
+		"WHERE status = 'pending' AND send_at <= $1 " +
+		"ORDER BY send_at, id LIMIT $2 " +
+		"FOR UPDATE SKIP LOCKED), " +
+		"s AS ( " +
+		"UPDATE scheduled_messages m SET status = 'sending', updated_at = now() " +
+		"FROM c WHERE m.id = c.id RETURNING m.*) " +
+		scheduledSelect +
+		"ORDER BY s.send_at, s.id"
+
+	return r.scheduled(query, now, limit)
+}
+
+func (r *ScheduledMessageSqlRepos) FinishScheduledMessage(id int, status entities.ScheduledStatus, lastError string) error {
+	query := "UPDATE scheduled_messages SET status = $2, last_error = $3, updated_at = now() WHERE id = $1"
+
+	return r.db.Insert(query, id, string(status), lastError)
+}
+
+func (r *ScheduledMessageSqlRepos) scheduled(query string, args ...interface{}) ([]entities.ScheduledMessage, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]entities.ScheduledMessage, 0)
+	for rows.Next() {
+		var model models.ScheduledMessageModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, mapper.ScheduledMessageModelToEntity(model))
+	}
+
+	return messages, nil
+}
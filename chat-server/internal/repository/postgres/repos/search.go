@@ -0,0 +1,98 @@
+package repos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/pkg/textsearch"
+)
+
+const (
+	publicSearchChat  = "public"
+	privateSearchChat = "private"
+)
+
+type searchSource interface {
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// tsQuery turns the parsed terms into a to_tsquery expression. Tokenize
+// leaves only letters and digits, so the words need no quoting.
+func tsQuery(terms []textsearch.Term) string {
+	parts := make([]string, 0, len(terms))
+
+	for _, term := range terms {
+		words := make([]string, len(term.Words))
+		copy(words, term.Words)
+
+		if term.Prefix {
+			words[len(words)-1] += ":*"
+		}
+
+		if len(words) == 1 {
+			parts = append(parts, words[0])
+		} else {
+			parts = append(parts, "("+strings.Join(words, " <-> ")+")")
+		}
+	}
+
+	return strings.Join(parts, " & ")
+}
+
+// searchFilters builds the conditions shared by the public and the private
+// search, numbering the placeholders after the arguments already in args.
+func searchFilters(q entities.SearchQuery, table, chat string, args []interface{}) (string, []interface{}) {
+	conds := []string{fmt.Sprintf("%s.search_vector @@ to_tsquery('simple', $%d)", table, len(args)+1)}
+	args = append(args, tsQuery(q.Terms))
+
+	if q.Sender != "" {
+		args = append(args, q.Sender)
+		conds = append(conds, fmt.Sprintf("su.username = $%d", len(args)))
+	}
+
+	if !q.From.IsZero() {
+		args = append(args, q.From)
+		conds = append(conds, fmt.Sprintf("%s.created_at >= $%d", table, len(args)))
+	}
+
+	if !q.To.IsZero() {
+		args = append(args, q.To)
+		conds = append(conds, fmt.Sprintf("%s.created_at < $%d", table, len(args)))
+	}
+
+	if q.After != nil {
+		args = append(args, q.After.CreatedAt, q.After.Chat, q.After.ID)
+		conds = append(conds, fmt.Sprintf("(%s.created_at, '%s'::text, %s.id) < ($%d, $%d, $%d)",
+			table, chat, table, len(args)-2, len(args)-1, len(args)))
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+func searchMessages(db searchSource, query, chat string, args ...interface{}) ([]entities.SearchResult, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]entities.SearchResult, 0)
+	for rows.Next() {
+		var message models.MessageModel
+		err := rows.StructScan(&message)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, entities.SearchResult{
+			Message: mapper.MessageModelToEntity(message),
+			Cursor:  entities.SearchCursor{CreatedAt: message.CreatedAt, Chat: chat, ID: message.ID},
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,245 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+type TransferPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// TransferSqlRepos reads the data of the database page by page for an
+// export, and writes an export back. The imported messages keep the time
+// they were sent at, and nobody is notified of them. The pages go by key,
+// so the rows added during an export never shift them.
+type TransferSqlRepos struct {
+	db TransferPostgresDB
+}
+
+func NewTransferSqlRepos(db TransferPostgresDB) *TransferSqlRepos {
+	return &TransferSqlRepos{db: db}
+}
+
+// GetUsers returns up to limit users whose names go after the given one, in
+// the order of their names, with the hashes of their passwords.
+func (r *TransferSqlRepos) GetUsers(after string, limit int) ([]entities.User, error) {
+	query := "SELECT username, password_hash FROM users WHERE username > $1 ORDER BY username LIMIT $2"
+
+	rows, err := r.db.Get(query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]entities.User, 0)
+	for rows.Next() {
+		var user models.UserModel
+		if err := rows.StructScan(&user); err != nil {
+			return nil, err
+		}
+
+		users = append(users, entities.User{Username: user.Username, Password: user.Password})
+	}
+
+	return users, rows.Err()
+}
+
+// GetPublicMessages returns up to limit public messages with IDs above the
+// given one, the oldest first.
+func (r *TransferSqlRepos) GetPublicMessages(afterID, limit int) ([]entities.Message, error) {
+	query := "SELECT gc.id, u.username AS sender, '' AS recipient, gc.message, gc.created_at, gc.deleted " +
+		"FROM global_chat gc " +
+		"JOIN users u ON u.id = gc.sender_id " +
+		"WHERE gc.id > $1 " +
+		"ORDER BY gc.id " +
+		"LIMIT $2"
+
+	return r.messages(query, afterID, limit)
+}
+
+// GetPartners returns the partners of the user's private chats, the message
+// requests included, in the order of their names.
+func (r *TransferSqlRepos) GetPartners(username string) ([]string, error) {
+	query := "SELECT p.username " +
+		"FROM conversations c " +
+		"JOIN users u ON u.id = c.user_id " +
+		"JOIN users p ON p.id = c.partner_id " +
+		"WHERE u.username = $1 " +
+		"ORDER BY p.username"
+
+	rows, err := r.db.Get(query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partners := make([]string, 0)
+	for rows.Next() {
+		var partner string
+		if err := rows.Scan(&partner); err != nil {
+			return nil, err
+		}
+
+		partners = append(partners, partner)
+	}
+
+	return partners, rows.Err()
+}
+
+// GetPrivateMessages returns up to limit messages of the private chat of
+// the two users with IDs above the given one, the oldest first. An expired
+// message comes without its content.
+func (r *TransferSqlRepos) GetPrivateMessages(user1, user2 string, afterID, limit int) ([]entities.Message, error) {
+	query := "SELECT pc.id, su.username AS sender, ru.username AS recipient, " + privateContent("pc") + ", " +
+		"pc.created_at, pc.ttl_seconds, pc.expire_after_read " +
+		"FROM (SELECT LEAST(a.id, b.id) AS low, GREATEST(a.id, b.id) AS high " +
+		"FROM users a, users b WHERE a.username = $1 AND b.username = $2) pair " +
+		"JOIN private_chats pc ON LEAST(pc.sender_id, pc.recipient_id) = pair.low " +
+		"AND GREATEST(pc.sender_id, pc.recipient_id) = pair.high " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		"WHERE pc.id > $3 " +
+		"ORDER BY pc.id " +
+		"LIMIT $4"
+
+	return r.messages(query, user1, user2, afterID, limit)
+}
+
+// ImportUser adds the user with the hash of their password as it is.
+func (r *TransferSqlRepos) ImportUser(user entities.User) error {
+	query := "INSERT INTO users(username, password_hash) VALUES ($1, $2)"
+
+	return r.db.Insert(query, user.Username, user.Password)
+}
+
+// ImportPublicMessages appends the messages to the public chat in the given
+// order. They get IDs of this database. Nothing is imported when a sender
+// is unknown.
+func (r *TransferSqlRepos) ImportPublicMessages(messages []entities.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	senders := make([]string, 0, len(messages))
+	contents := make([]string, 0, len(messages))
+	sentAt := make([]time.Time, 0, len(messages))
+	deleted := make([]bool, 0, len(messages))
+	for _, m := range messages {
+		senders = append(senders, m.Sender)
+		contents = append(contents, m.Content)
+		sentAt = append(sentAt, m.CreatedAt)
+		deleted = append(deleted, m.Deleted)
+	}
+
+	query := "INSERT INTO global_chat(sender_id, message, created_at, deleted) " +
+		"SELECT u.id, m.message, m.created_at, m.deleted " +
+		"FROM unnest($1::VARCHAR[], $2::TEXT[], $3::TIMESTAMPTZ[], $4::BOOLEAN[]) " +
+		"WITH ORDINALITY AS m(sender, message, created_at, deleted, n) " +
+		"JOIN users u ON u.username = m.sender " +
+		"WHERE NOT EXISTS (SELECT 1 FROM unnest($1::VARCHAR[]) s(username) " +
+		"WHERE NOT EXISTS (SELECT 1 FROM users WHERE username = s.username)) " +
+		"ORDER BY m.n " +
+		"RETURNING id"
+
+	imported, err := r.count(query, senders, contents, sentAt, deleted)
+	if err != nil {
+		return err
+	}
+
+	if imported == 0 {
+		return entities.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ImportPrivateMessages appends the messages to their private chats in the
+// given order, a message a statement. They get IDs of this database, and
+// fill the inboxes and the message requests the way sending them did.
+func (r *TransferSqlRepos) ImportPrivateMessages(messages []entities.Message) error {
+	query := "WITH m AS ( " +
+		"INSERT INTO private_chats(sender_id, recipient_id, message, created_at, ttl_seconds, expire_after_read, expires_at, expired) " +
+		"SELECT s.id, r.id, $3, $4, NULLIF($5::INTEGER, 0), $6::BOOLEAN, $7::TIMESTAMPTZ, $8::BOOLEAN " +
+		"FROM users s, users r WHERE s.username = $1 AND r.username = $2 " +
+		"RETURNING id, sender_id, recipient_id, created_at), " +
+		"ct AS ( " +
+		"INSERT INTO contacts(user_id, contact_id) " +
+		"SELECT sender_id, recipient_id FROM m " +
+		"ON CONFLICT DO NOTHING), " +
+		"cv AS ( " +
+		"INSERT INTO conversations(user_id, partner_id, last_message_id, last_message_at, is_request) " +
+		"SELECT sender_id, recipient_id, id, created_at, FALSE FROM m " +
+		"UNION ALL " +
+		"SELECT recipient_id, sender_id, id, created_at, " +
+		"NOT EXISTS (SELECT 1 FROM contacts c WHERE c.user_id = m.recipient_id AND c.contact_id = m.sender_id) " +
+		"FROM m WHERE recipient_id <> sender_id " +
+		"ON CONFLICT (user_id, partner_id) DO UPDATE " +
+		"SET last_message_id = EXCLUDED.last_message_id, last_message_at = EXCLUDED.last_message_at, " +
+		"is_request = conversations.is_request AND EXCLUDED.is_request) " +
+		"SELECT id FROM m"
+
+	for _, m := range messages {
+		var expiresAt *time.Time
+		if !m.ExpiresAt.IsZero() {
+			expiresAt = &m.ExpiresAt
+		}
+
+		imported, err := r.count(query, m.Sender, m.Recipient, m.Content, m.CreatedAt,
+			int(m.TTL/time.Second), m.ExpireAfterRead, expiresAt, m.Expired)
+		if err != nil {
+			return err
+		}
+
+		if imported == 0 {
+			return entities.ErrUserNotFound
+		}
+	}
+
+	return nil
+}
+
+func (r *TransferSqlRepos) messages(query string, args ...interface{}) ([]entities.Message, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chat := models.ChatModel{Messages: make([]models.MessageModel, 0)}
+	for rows.Next() {
+		var message models.MessageModel
+		if err := rows.StructScan(&message); err != nil {
+			return nil, err
+		}
+
+		chat.Messages = append(chat.Messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mapper.MessageModelToEntities(chat), nil
+}
+
+// count runs the query and returns how many rows it returned.
+func (r *TransferSqlRepos) count(query string, args ...interface{}) (int, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		count++
+	}
+
+	return count, rows.Err()
+}
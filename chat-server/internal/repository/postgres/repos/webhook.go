@@ -0,0 +1,211 @@
+package repos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+const (
+	webhookColumns = "id, url, array_to_string(events, ',') AS events, channel, secret, active, failures, created_by, created_at"
+
+	deliveryColumns = "d.id, d.webhook_id, w.url, w.secret, d.event_id, e.type, e.channel, e.message_id, e.sender, e.message, " +
+		"e.created_at AS event_created_at, d.status, d.attempts, d.next_attempt_at, d.response_status, d.last_error, " +
+		"d.created_at, d.updated_at"
+)
+
+// subscribedWebhook filters the webhooks w subscribed to the event $9 of the
+// channel $10 in a message insert.
+var subscribedWebhook = subscribedWebhookAt(9, 10)
+
+// subscribedWebhookAt filters the webhooks w subscribed to the event and the
+// channel passed as the parameters at the given positions.
+func subscribedWebhookAt(event, channel int) string {
+	return fmt.Sprintf("WHERE w.active AND $%d::VARCHAR = ANY(w.events) AND (w.channel IS NULL OR w.channel = $%d)", event, channel)
+}
+
+type WebhookPostgresDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type WebhookSqlRepos struct {
+	db WebhookPostgresDB
+}
+
+func NewWebhookSqlRepos(db WebhookPostgresDB) *WebhookSqlRepos {
+	return &WebhookSqlRepos{db: db}
+}
+
+func (r *WebhookSqlRepos) InsertWebhook(w entities.Webhook) (entities.Webhook, error) {
+	query := "INSERT INTO webhooks(url, events, channel, secret, active, created_by) " +
+		"VALUES ($1, string_to_array($2, ','), NULLIF($3, ''), $4, $5, $6) " +
+		"RETURNING " + webhookColumns
+
+	webhooks, err := r.webhooks(query, w.URL, joinEvents(w.Events), w.Channel, w.Secret, w.Active, w.CreatedBy)
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.Webhook{}, entities.ErrWebhookNotFound
+	}
+
+	return webhooks[0], nil
+}
+
+func (r *WebhookSqlRepos) GetWebhooks() ([]entities.Webhook, error) {
+	return r.webhooks("SELECT " + webhookColumns + " FROM webhooks ORDER BY id")
+}
+
+func (r *WebhookSqlRepos) GetWebhook(id int) (entities.Webhook, error) {
+	webhooks, err := r.webhooks("SELECT "+webhookColumns+" FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.Webhook{}, entities.ErrWebhookNotFound
+	}
+
+	return webhooks[0], nil
+}
+
+// DeleteWebhook removes the webhook, its deliveries go with it.
+func (r *WebhookSqlRepos) DeleteWebhook(id int) error {
+	webhooks, err := r.webhooks("DELETE FROM webhooks WHERE id = $1 RETURNING "+webhookColumns, id)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// SetWebhookActive enables or disables the webhook. Enabling it starts the
+// failure count over.
+func (r *WebhookSqlRepos) SetWebhookActive(id int, active bool) error {
+	query := "UPDATE webhooks SET active = $2, failures = CASE WHEN $2 THEN 0 ELSE failures END " +
+		"WHERE id = $1 RETURNING " + webhookColumns
+
+	webhooks, err := r.webhooks(query, id, active)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// GetDeliveries returns the delivery log of the webhook, newest first.
+func (r *WebhookSqlRepos) GetDeliveries(webhookID, limit, offset int) ([]entities.WebhookDelivery, error) {
+	if _, err := r.GetWebhook(webhookID); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + deliveryColumns + " " +
+		"FROM webhook_deliveries d " +
+		"JOIN webhooks w ON w.id = d.webhook_id " +
+		"JOIN webhook_events e ON e.id = d.event_id " +
+		"WHERE d.webhook_id = $1 " +
+		"ORDER BY d.id DESC LIMIT $2 OFFSET $3"
+
+	return r.deliveries(query, webhookID, limit, offset)
+}
+
+// ClaimDeliveries returns up to limit pending deliveries which are due and
+// belong to active webhooks. They are leased until now+lease, so a worker
+// that dies before recording the attempt only delays them. SKIP LOCKED lets
+// several servers share the queue.
+func (r *WebhookSqlRepos) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]entities.WebhookDelivery, error) {
+	query := "WITH c AS ( " +
+		"SELECT d.id FROM webhook_deliveries d " +
+		"JOIN webhooks w ON w.id = d.webhook_id " +
+		"WHERE d.status = 'pending' AND d.next_attempt_at <= $1 AND w.active " +
+		"ORDER BY d.next_attempt_at, d.id LIMIT $3 " +
+		"FOR UPDATE OF d SKIP LOCKED) " +
+		"UPDATE webhook_deliveries d SET next_attempt_at = $2 " +
+		"FROM c, webhooks w, webhook_events e " +
+		"WHERE d.id = c.id AND w.id = d.webhook_id AND e.id = d.event_id " +
+		"RETURNING " + deliveryColumns
+
+	return r.deliveries(query, now, now.Add(lease), limit)
+}
+
+// RecordAttempt saves the outcome of a delivery attempt. A failed attempt
+// counts against the webhook, which is disabled after disableAfter failures
+// in a row; a successful one resets the count.
+func (r *WebhookSqlRepos) RecordAttempt(d entities.WebhookDelivery, disableAfter int) error {
+	query := "WITH d AS ( " +
+		"UPDATE webhook_deliveries SET status = $2, attempts = $3, next_attempt_at = $4, " +
+		"response_status = $5, last_error = $6, updated_at = now() " +
+		"WHERE id = $1 RETURNING webhook_id) " +
+		"UPDATE webhooks w SET " +
+		"failures = CASE WHEN $2 = 'succeeded' THEN 0 ELSE w.failures + 1 END, " +
+		"active = w.active AND ($2 = 'succeeded' OR w.failures + 1 < $7) " +
+		"FROM d WHERE w.id = d.webhook_id"
+
+	return r.db.Insert(query, d.ID, string(d.Status), d.Attempts, d.NextAttemptAt, d.ResponseStatus, d.LastError, disableAfter)
+}
+
+func (r *WebhookSqlRepos) webhooks(query string, args ...interface{}) ([]entities.Webhook, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]entities.Webhook, 0)
+	for rows.Next() {
+		var model models.WebhookModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, mapper.WebhookModelToEntity(model))
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookSqlRepos) deliveries(query string, args ...interface{}) ([]entities.WebhookDelivery, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]entities.WebhookDelivery, 0)
+	for rows.Next() {
+		var model models.WebhookDeliveryModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, mapper.WebhookDeliveryModelToEntity(model))
+	}
+
+	return deliveries, nil
+}
+
+func joinEvents(events []entities.WebhookEventType) string {
+	res := make([]string, 0, len(events))
+	for _, val := range events {
+		res = append(res, string(val))
+	}
+
+	return strings.Join(res, ",")
+}
@@ -0,0 +1,1058 @@
+// Package repotest is the conformance suite of the repositories: the same
+// behavioral tests run against every backend, so the in-memory, SQLite and
+// Postgres repositories keep one contract.
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/pagination"
+	"github.com/vavelour/chat/pkg/textsearch"
+)
+
+type AuthRepository interface {
+	InsertUser(username string, password string) error
+	GetUser(username string) (entities.User, error)
+}
+
+type PublicRepository interface {
+	InsertMessage(m entities.Message) error
+	GetMessages(limit, offset int, order entities.Order) ([]entities.Message, error)
+	GetAttachment(id string) (entities.Attachment, entities.Message, error)
+	SearchMessages(q entities.SearchQuery) ([]entities.SearchResult, error)
+}
+
+type PrivateRepository interface {
+	InsertMessage(m entities.Message) error
+	GetMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error)
+	GetUsers(user string) ([]string, error)
+	MarkAsRead(reader, partner string, messageID int) error
+	GetConversations(user string, partners []string) ([]entities.Conversation, error)
+	GetInbox(user string, limit, offset int) ([]entities.Conversation, error)
+	GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error)
+	AcceptMessageRequest(user, partner string) error
+	DeclineMessageRequest(user, partner string) error
+	GetAttachment(id string) (entities.Attachment, entities.Message, error)
+	SearchMessages(user string, q entities.SearchQuery) ([]entities.SearchResult, error)
+	PurgeExpiredMessages(now time.Time, limit int) (int, []string, error)
+}
+
+type RetentionRepository interface {
+	GetPublicMessagesBefore(before time.Time, limit int) ([]entities.Message, error)
+	DeletePublicMessages(ids []int, before time.Time) (int, []string, error)
+	GetPrivateMessagesBefore(before time.Time, limit int) ([]entities.Message, error)
+	DeletePrivateMessages(messages []entities.Message, before time.Time) (int, []string, error)
+	PlaceLegalHold(username string) error
+	LiftLegalHold(username string) error
+	GetLegalHolds() ([]entities.LegalHold, error)
+}
+
+type TransferRepository interface {
+	GetUsers(after string, limit int) ([]entities.User, error)
+	GetPublicMessages(afterID, limit int) ([]entities.Message, error)
+	GetPartners(username string) ([]string, error)
+	GetPrivateMessages(user1, user2 string, afterID, limit int) ([]entities.Message, error)
+	ImportUser(user entities.User) error
+	ImportPublicMessages(messages []entities.Message) error
+	ImportPrivateMessages(messages []entities.Message) error
+}
+
+type ExportRepository interface {
+	GetDataExport(username string) (entities.DataExport, error)
+	SaveDataExport(export entities.DataExport) error
+	FinishDataExport(export entities.DataExport) (bool, error)
+}
+
+// Backend is the set of repositories of one database.
+type Backend struct {
+	Auth      AuthRepository
+	Public    PublicRepository
+	Private   PrivateRepository
+	Retention RetentionRepository
+	Transfer  TransferRepository
+	Export    ExportRepository
+	// Unit runs fn with the repositories of a unit of work.
+	Unit func(fn func(b Backend) error) error
+	// FailWrites makes the storage fail like a full disk once n more bytes
+	// are written to it, and Reopen closes the database and opens it again.
+	// They are nil for the backends which can not fail their writes so.
+	FailWrites func(n int64)
+	Reopen     func() Backend
+}
+
+// Run runs the suite. open returns the repositories of an empty database,
+// and is called once for every test.
+func Run(t *testing.T, open func(t *testing.T) Backend) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, b Backend)
+	}{
+		{"Auth", testAuth},
+		{"PublicMessages", testPublicMessages},
+		{"PublicAttachments", testPublicAttachments},
+		{"PublicSearch", testPublicSearch},
+		{"PublicOrder", testPublicOrder},
+		{"PublicPagingUnderInserts", testPublicPagingUnderInserts},
+		{"PrivateMessages", testPrivateMessages},
+		{"PrivateUnknownRecipient", testPrivateUnknownRecipient},
+		{"PrivateOrder", testPrivateOrder},
+		{"PrivatePagingUnderInserts", testPrivatePagingUnderInserts},
+		{"PrivateReads", testPrivateReads},
+		{"MessageRequests", testMessageRequests},
+		{"PrivateAttachments", testPrivateAttachments},
+		{"PrivateSearch", testPrivateSearch},
+		{"PurgeExpiredMessages", testPurgeExpiredMessages},
+		{"RetentionPublic", testRetentionPublic},
+		{"RetentionPrivate", testRetentionPrivate},
+		{"LegalHolds", testLegalHolds},
+		{"TransferExport", testTransferExport},
+		{"TransferImport", testTransferImport},
+		{"DataExports", testDataExports},
+		{"UnitCommit", testUnitCommit},
+		{"UnitRollback", testUnitRollback},
+		{"UnitPanic", testUnitPanic},
+		{"UnitWriteFailure", testUnitWriteFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, open(t))
+		})
+	}
+}
+
+func register(t *testing.T, b Backend, usernames ...string) {
+	t.Helper()
+
+	for _, username := range usernames {
+		require.NoError(t, b.Auth.InsertUser(username, username+"-password"))
+	}
+}
+
+func terms(t *testing.T, query string) []textsearch.Term {
+	t.Helper()
+
+	parsed, err := textsearch.Parse(query)
+	require.NoError(t, err)
+
+	return parsed
+}
+
+func contents(messages []entities.Message) []string {
+	result := make([]string, 0, len(messages))
+	for _, m := range messages {
+		result = append(result, m.Content)
+	}
+
+	return result
+}
+
+func ids(messages []entities.Message) []int {
+	result := make([]int, 0, len(messages))
+	for _, m := range messages {
+		result = append(result, m.ID)
+	}
+
+	return result
+}
+
+// pageAll reads the pages of limit messages, the oldest first, until a page
+// is empty.
+func pageAll(t *testing.T, limit int, get func(limit, offset int) ([]entities.Message, error)) []entities.Message {
+	t.Helper()
+
+	var all []entities.Message
+	for offset := 0; ; offset += limit {
+		page, err := get(limit, offset)
+		if errors.Is(err, pagination.ErrOffsetRange) {
+			return all
+		}
+		require.NoError(t, err)
+
+		if len(page) == 0 {
+			return all
+		}
+
+		for i := 1; i < len(page); i++ {
+			require.Less(t, page[i-1].ID, page[i].ID, "page at offset %d is out of order", offset)
+		}
+
+		all = append(all, page...)
+	}
+}
+
+// checkOrder checks that the newest first are the oldest first reversed,
+// with the offsets counted from the latest message.
+func checkOrder(t *testing.T, get func(limit, offset int, order entities.Order) ([]entities.Message, error)) {
+	t.Helper()
+
+	oldest, err := get(10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Equal(t, []string{"one", "two", "three", "four"}, contents(oldest))
+
+	newest, err := get(10, 0, entities.OrderNewestFirst)
+	require.NoError(t, err)
+	require.Equal(t, []string{"four", "three", "two", "one"}, contents(newest))
+
+	for i := 1; i < len(newest); i++ {
+		assert.Greater(t, newest[i-1].ID, newest[i].ID)
+	}
+
+	page, err := get(2, 1, entities.OrderNewestFirst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"three", "two"}, contents(page))
+
+	page, err = get(2, 3, entities.OrderNewestFirst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one"}, contents(page))
+
+	page, _ = get(2, 4, entities.OrderNewestFirst)
+	assert.Empty(t, page)
+}
+
+// checkPagingUnderInserts pages through the messages, the oldest first,
+// while insert adds more of them. The messages there before the first page
+// must come first, in order, with none skipped or repeated. The ones added
+// meanwhile only have to keep the order within a page: Postgres may commit
+// a later ID before an earlier one, so a page can miss a message that shows
+// up at a lower offset later.
+func checkPagingUnderInserts(t *testing.T, insert func(content string) error, get func(limit, offset int) ([]entities.Message, error)) {
+	t.Helper()
+
+	const (
+		initial = 20
+		writers = 4
+		late    = 10
+	)
+
+	for i := 0; i < initial; i++ {
+		require.NoError(t, insert(fmt.Sprintf("initial-%d", i)))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < late; i++ {
+				assert.NoError(t, insert(fmt.Sprintf("late-%d-%d", w, i)))
+			}
+		}(w)
+	}
+
+	paged := pageAll(t, 3, get)
+	wg.Wait()
+
+	require.GreaterOrEqual(t, len(paged), initial)
+	for i := 0; i < initial; i++ {
+		assert.Equal(t, fmt.Sprintf("initial-%d", i), paged[i].Content)
+	}
+
+	all := pageAll(t, 7, get)
+	require.Len(t, all, initial+writers*late)
+	assert.Equal(t, ids(all[:initial]), ids(paged[:initial]))
+}
+
+func partners(conversations []entities.Conversation) []string {
+	result := make([]string, 0, len(conversations))
+	for _, c := range conversations {
+		result = append(result, c.Partner)
+	}
+
+	return result
+}
+
+func testAuth(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	user, err := b.Auth.GetUser("tester")
+	require.NoError(t, err)
+	assert.Equal(t, "tester", user.Username)
+	assert.Equal(t, "tester-password", user.Password)
+
+	assert.Error(t, b.Auth.InsertUser("tester", "other"))
+
+	// The backends differ in how they report an unknown user, but none of
+	// them returns one.
+	user, err = b.Auth.GetUser("nobody")
+	assert.True(t, err != nil || user.Username == "")
+
+	// The names are data, not SQL.
+	require.NoError(t, b.Auth.InsertUser("o'neil", "o'neil-password"))
+
+	user, err = b.Auth.GetUser("o'neil")
+	require.NoError(t, err)
+	assert.Equal(t, "o'neil", user.Username)
+	assert.Equal(t, "o'neil-password", user.Password)
+}
+
+func testPublicMessages(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	for _, m := range []entities.Message{
+		{Sender: "tester", Content: "first"},
+		{Sender: "valera", Content: "second"},
+		{Sender: "tester", Content: "third"},
+	} {
+		require.NoError(t, b.Public.InsertMessage(m))
+	}
+
+	messages, err := b.Public.GetMessages(10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "second", "third"}, contents(messages))
+	assert.Equal(t, "valera", messages[1].Sender)
+	assert.Less(t, messages[0].ID, messages[1].ID)
+	assert.False(t, messages[0].CreatedAt.IsZero())
+
+	messages, err = b.Public.GetMessages(1, 1, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"second"}, contents(messages))
+
+	messages, _ = b.Public.GetMessages(10, 3, entities.OrderOldestFirst)
+	assert.Empty(t, messages)
+}
+
+func testPublicAttachments(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	attachment := entities.Attachment{ID: "a1", FileName: "cat.png", ContentType: "image/png", Size: 42}
+	require.NoError(t, b.Public.InsertMessage(entities.Message{
+		Sender: "tester", Content: "look", Attachments: []entities.Attachment{attachment},
+	}))
+
+	messages, err := b.Public.GetMessages(10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Len(t, messages[0].Attachments, 1)
+	assert.Equal(t, "cat.png", messages[0].Attachments[0].FileName)
+
+	got, m, err := b.Public.GetAttachment("a1")
+	require.NoError(t, err)
+	assert.Equal(t, attachment.FileName, got.FileName)
+	assert.Equal(t, attachment.ContentType, got.ContentType)
+	assert.Equal(t, attachment.Size, got.Size)
+	assert.Equal(t, "tester", m.Sender)
+
+	_, _, err = b.Public.GetAttachment("missing")
+	assert.ErrorIs(t, err, entities.ErrAttachmentNotFound)
+}
+
+func testPublicSearch(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	for _, m := range []entities.Message{
+		{Sender: "tester", Content: "hello world"},
+		{Sender: "valera", Content: "hello there"},
+		{Sender: "tester", Content: "goodbye"},
+	} {
+		require.NoError(t, b.Public.InsertMessage(m))
+	}
+
+	results, err := b.Public.SearchMessages(entities.SearchQuery{Terms: terms(t, "hello"), Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = b.Public.SearchMessages(entities.SearchQuery{Terms: terms(t, "hello"), Sender: "valera", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "hello there", results[0].Message.Content)
+
+	results, err = b.Public.SearchMessages(entities.SearchQuery{Terms: terms(t, "good*"), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "goodbye", results[0].Message.Content)
+
+	results, err = b.Public.SearchMessages(entities.SearchQuery{Terms: terms(t, `"world hello"`), Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func testPrivateMessages(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "hi"}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "hello"}))
+
+	for _, pair := range [][2]string{{"tester", "valera"}, {"valera", "tester"}} {
+		messages, err := b.Private.GetMessages(pair[0], pair[1], 10, 0, entities.OrderOldestFirst)
+		require.NoError(t, err)
+		require.Equal(t, []string{"hi", "hello"}, contents(messages))
+		assert.Equal(t, "tester", messages[0].Sender)
+		assert.Equal(t, "valera", messages[0].Recipient)
+	}
+
+	messages, err := b.Private.GetMessages("tester", "valera", 1, 1, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello"}, contents(messages))
+
+	messages, _ = b.Private.GetMessages("tester", "nobody", 10, 0, entities.OrderOldestFirst)
+	assert.Empty(t, messages)
+
+	users, err := b.Private.GetUsers("tester")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"valera"}, users)
+}
+
+func testPublicOrder(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	for _, content := range []string{"one", "two", "three", "four"} {
+		require.NoError(t, b.Public.InsertMessage(entities.Message{Sender: "tester", Content: content}))
+	}
+
+	checkOrder(t, b.Public.GetMessages)
+}
+
+func testPublicPagingUnderInserts(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	checkPagingUnderInserts(t,
+		func(content string) error {
+			return b.Public.InsertMessage(entities.Message{Sender: "tester", Content: content})
+		},
+		func(limit, offset int) ([]entities.Message, error) {
+			return b.Public.GetMessages(limit, offset, entities.OrderOldestFirst)
+		},
+	)
+}
+
+func testPrivateOrder(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	for i, content := range []string{"one", "two", "three", "four"} {
+		m := entities.Message{Sender: "tester", Recipient: "valera", Content: content}
+		if i%2 == 1 {
+			m.Sender, m.Recipient = m.Recipient, m.Sender
+		}
+
+		require.NoError(t, b.Private.InsertMessage(m))
+	}
+
+	checkOrder(t, func(limit, offset int, order entities.Order) ([]entities.Message, error) {
+		return b.Private.GetMessages("valera", "tester", limit, offset, order)
+	})
+}
+
+func testPrivatePagingUnderInserts(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	checkPagingUnderInserts(t,
+		func(content string) error {
+			return b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: content})
+		},
+		func(limit, offset int) ([]entities.Message, error) {
+			return b.Private.GetMessages("valera", "tester", limit, offset, entities.OrderOldestFirst)
+		},
+	)
+}
+
+func testPrivateUnknownRecipient(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	assert.Error(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "nobody", Content: "hi"}))
+
+	messages, _ := b.Private.GetMessages("tester", "nobody", 10, 0, entities.OrderOldestFirst)
+	assert.Empty(t, messages)
+}
+
+func testPrivateReads(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	for _, content := range []string{"one", "two", "three"} {
+		require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: content}))
+	}
+
+	messages, err := b.Private.GetMessages("valera", "tester", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	conversations, err := b.Private.GetConversations("valera", []string{"tester"})
+	require.NoError(t, err)
+	require.Len(t, conversations, 1)
+	assert.Equal(t, 3, conversations[0].UnreadCount)
+
+	require.NoError(t, b.Private.MarkAsRead("valera", "tester", messages[1].ID))
+
+	conversations, err = b.Private.GetConversations("valera", []string{"tester"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, conversations[0].UnreadCount)
+
+	conversations, err = b.Private.GetConversations("tester", []string{"valera"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, conversations[0].UnreadCount)
+	assert.False(t, conversations[0].SeenByPartner)
+
+	// A read marker never moves back, and 0 reads the whole conversation.
+	require.NoError(t, b.Private.MarkAsRead("valera", "tester", messages[0].ID))
+	require.NoError(t, b.Private.MarkAsRead("valera", "tester", 0))
+
+	conversations, err = b.Private.GetConversations("tester", []string{"valera"})
+	require.NoError(t, err)
+	assert.True(t, conversations[0].SeenByPartner)
+
+	assert.Error(t, b.Private.MarkAsRead("tester", "nobody", 0))
+}
+
+func testMessageRequests(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera", "igor")
+
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "hi"}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "igor", Recipient: "valera", Content: "hey"}))
+
+	inbox, _ := b.Private.GetInbox("tester", 10, 0)
+	assert.Equal(t, []string{"valera"}, partners(inbox))
+
+	inbox, _ = b.Private.GetInbox("valera", 10, 0)
+	assert.Empty(t, inbox)
+
+	requests, err := b.Private.GetMessageRequests("valera", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"igor", "tester"}, partners(requests))
+	assert.Equal(t, "hey", requests[0].LastMessage.Content)
+
+	require.NoError(t, b.Private.AcceptMessageRequest("valera", "tester"))
+	require.NoError(t, b.Private.DeclineMessageRequest("valera", "igor"))
+
+	assert.ErrorIs(t, b.Private.AcceptMessageRequest("valera", "tester"), entities.ErrMessageRequestNotFound)
+	assert.ErrorIs(t, b.Private.DeclineMessageRequest("valera", "igor"), entities.ErrMessageRequestNotFound)
+
+	requests, _ = b.Private.GetMessageRequests("valera", 10, 0)
+	assert.Empty(t, requests)
+
+	inbox, err = b.Private.GetInbox("valera", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tester"}, partners(inbox))
+
+	// An accepted stranger writes straight to the inbox.
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "again"}))
+
+	requests, _ = b.Private.GetMessageRequests("valera", 10, 0)
+	assert.Empty(t, requests)
+
+	// A reply accepts the request.
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "igor", Recipient: "valera", Content: "please"}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "valera", Recipient: "igor", Content: "fine"}))
+
+	requests, _ = b.Private.GetMessageRequests("valera", 10, 0)
+	assert.Empty(t, requests)
+
+	inbox, err = b.Private.GetInbox("valera", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"igor", "tester"}, partners(inbox))
+	assert.Equal(t, "fine", inbox[0].LastMessage.Content)
+}
+
+func testPrivateAttachments(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	attachment := entities.Attachment{ID: "p1", FileName: "doc.pdf", ContentType: "application/pdf", Size: 7}
+	require.NoError(t, b.Private.InsertMessage(entities.Message{
+		Sender: "tester", Recipient: "valera", Content: "file", Attachments: []entities.Attachment{attachment},
+	}))
+
+	got, m, err := b.Private.GetAttachment("p1")
+	require.NoError(t, err)
+	assert.Equal(t, "doc.pdf", got.FileName)
+	assert.Equal(t, "tester", m.Sender)
+	assert.Equal(t, "valera", m.Recipient)
+
+	_, _, err = b.Private.GetAttachment("missing")
+	assert.ErrorIs(t, err, entities.ErrAttachmentNotFound)
+}
+
+func testPrivateSearch(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera", "igor")
+
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "secret plan"}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "igor", Recipient: "valera", Content: "another plan"}))
+
+	results, err := b.Private.SearchMessages("tester", entities.SearchQuery{Terms: terms(t, "plan"), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "secret plan", results[0].Message.Content)
+
+	results, err = b.Private.SearchMessages("valera", entities.SearchQuery{Terms: terms(t, "plan"), Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = b.Private.SearchMessages("valera", entities.SearchQuery{Terms: terms(t, "plan"), Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func testPurgeExpiredMessages(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	attachment := entities.Attachment{ID: "e1", FileName: "note.txt", ContentType: "text/plain", Size: 1}
+	require.NoError(t, b.Private.InsertMessage(entities.Message{
+		Sender: "tester", Recipient: "valera", Content: "burn", TTL: time.Minute,
+		Attachments: []entities.Attachment{attachment},
+	}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "keep"}))
+
+	purged, removed, err := b.Private.PurgeExpiredMessages(time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+	assert.Empty(t, removed)
+
+	purged, removed, err = b.Private.PurgeExpiredMessages(time.Now().Add(2*time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.Equal(t, []string{"e1"}, removed)
+
+	messages, err := b.Private.GetMessages("tester", "valera", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.True(t, messages[0].Expired)
+	assert.Empty(t, messages[0].Content)
+	assert.Equal(t, "keep", messages[1].Content)
+
+	_, _, err = b.Private.GetAttachment("e1")
+	assert.ErrorIs(t, err, entities.ErrAttachmentNotFound)
+}
+
+func testRetentionPublic(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+	require.NoError(t, b.Retention.PlaceLegalHold("valera"))
+
+	attachment := entities.Attachment{ID: "r1", FileName: "old.txt", ContentType: "text/plain", Size: 3}
+	require.NoError(t, b.Public.InsertMessage(entities.Message{
+		Sender: "tester", Content: "first words", Attachments: []entities.Attachment{attachment},
+	}))
+	require.NoError(t, b.Public.InsertMessage(entities.Message{Sender: "valera", Content: "held words"}))
+	require.NoError(t, b.Public.InsertMessage(entities.Message{Sender: "tester", Content: "second words"}))
+
+	due, err := b.Retention.GetPublicMessagesBefore(time.Now().Add(-time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	before := time.Now().Add(time.Minute)
+
+	due, err = b.Retention.GetPublicMessagesBefore(before, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first words"}, contents(due))
+	require.Len(t, due[0].Attachments, 1)
+	assert.Equal(t, "r1", due[0].Attachments[0].ID)
+
+	due, err = b.Retention.GetPublicMessagesBefore(before, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first words", "second words"}, contents(due))
+
+	all, err := b.Public.GetMessages(10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+
+	// The messages are checked again: the held one and the ones sent since
+	// the time are kept.
+	removed, attachments, err := b.Retention.DeletePublicMessages(ids(all), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+	assert.Empty(t, attachments)
+
+	removed, attachments, err = b.Retention.DeletePublicMessages(ids(all), before)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, []string{"r1"}, attachments)
+
+	messages, err := b.Public.GetMessages(10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"held words"}, contents(messages))
+
+	_, _, err = b.Public.GetAttachment("r1")
+	assert.ErrorIs(t, err, entities.ErrAttachmentNotFound)
+
+	results, err := b.Public.SearchMessages(entities.SearchQuery{Terms: terms(t, "words"), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "held words", results[0].Message.Content)
+
+	// The IDs of the removed messages are not given again.
+	require.NoError(t, b.Public.InsertMessage(entities.Message{Sender: "tester", Content: "new"}))
+
+	messages, err = b.Public.GetMessages(10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Greater(t, messages[1].ID, all[len(all)-1].ID)
+
+	require.NoError(t, b.Retention.LiftLegalHold("valera"))
+
+	due, err = b.Retention.GetPublicMessagesBefore(before, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"held words", "new"}, contents(due))
+}
+
+func testRetentionPrivate(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera", "igor")
+	require.NoError(t, b.Retention.PlaceLegalHold("igor"))
+
+	attachment := entities.Attachment{ID: "r2", FileName: "old.txt", ContentType: "text/plain", Size: 3}
+	require.NoError(t, b.Private.InsertMessage(entities.Message{
+		Sender: "tester", Recipient: "valera", Content: "old plan", Attachments: []entities.Attachment{attachment},
+	}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "old reply"}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "igor", Content: "held plan"}))
+
+	before := time.Now().Add(time.Minute)
+
+	due, err := b.Retention.GetPrivateMessagesBefore(before, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old plan", "old reply"}, contents(due))
+	assert.Equal(t, "valera", due[0].Recipient)
+	require.Len(t, due[0].Attachments, 1)
+
+	due, err = b.Retention.GetPrivateMessagesBefore(before, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old plan"}, contents(due))
+
+	held, err := b.Private.GetMessages("tester", "igor", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+
+	conversation, err := b.Private.GetMessages("tester", "valera", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+
+	removed, attachments, err := b.Retention.DeletePrivateMessages(append(conversation, held...), before)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, []string{"r2"}, attachments)
+
+	messages, err := b.Private.GetMessages("tester", "igor", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"held plan"}, contents(messages))
+
+	_, _, err = b.Private.GetAttachment("r2")
+	assert.ErrorIs(t, err, entities.ErrAttachmentNotFound)
+
+	results, err := b.Private.SearchMessages("tester", entities.SearchQuery{Terms: terms(t, "plan"), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "held plan", results[0].Message.Content)
+
+	// The conversation left without messages leaves the inboxes.
+	inbox, err := b.Private.GetInbox("tester", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"igor"}, partners(inbox))
+
+	// The in-memory backend reports a page past the end as an error.
+	inbox, _ = b.Private.GetInbox("valera", 10, 0)
+	assert.Empty(t, inbox)
+
+	// The IDs of the removed messages are not given again.
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "new"}))
+
+	messages, err = b.Private.GetMessages("tester", "valera", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Greater(t, messages[0].ID, conversation[len(conversation)-1].ID)
+
+	// The conversation whose last message is removed shows the one before.
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "newer"}))
+
+	messages, err = b.Private.GetMessages("tester", "valera", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	removed, _, err = b.Retention.DeletePrivateMessages(messages[1:], time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	inbox, err = b.Private.GetInbox("tester", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"valera", "igor"}, partners(inbox))
+	assert.Equal(t, "new", inbox[0].LastMessage.Content)
+
+	inbox, err = b.Private.GetInbox("valera", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tester"}, partners(inbox))
+	assert.Equal(t, "new", inbox[0].LastMessage.Content)
+}
+
+func testLegalHolds(t *testing.T, b Backend) {
+	register(t, b, "tester", "valera")
+
+	assert.ErrorIs(t, b.Retention.PlaceLegalHold("igor"), entities.ErrUserNotFound)
+	assert.ErrorIs(t, b.Retention.LiftLegalHold("tester"), entities.ErrLegalHoldNotFound)
+
+	require.NoError(t, b.Retention.PlaceLegalHold("valera"))
+	require.NoError(t, b.Retention.PlaceLegalHold("tester"))
+
+	holds, err := b.Retention.GetLegalHolds()
+	require.NoError(t, err)
+	require.Len(t, holds, 2)
+	assert.Equal(t, "tester", holds[0].Username)
+	assert.Equal(t, "valera", holds[1].Username)
+	assert.False(t, holds[1].PlacedAt.IsZero())
+
+	// Placing a hold again keeps the first one.
+	require.NoError(t, b.Retention.PlaceLegalHold("valera"))
+
+	again, err := b.Retention.GetLegalHolds()
+	require.NoError(t, err)
+	assert.True(t, holds[1].PlacedAt.Equal(again[1].PlacedAt))
+
+	require.NoError(t, b.Retention.LiftLegalHold("tester"))
+
+	holds, err = b.Retention.GetLegalHolds()
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	assert.Equal(t, "valera", holds[0].Username)
+}
+
+func testTransferExport(t *testing.T, b Backend) {
+	register(t, b, "valera", "tester", "igor")
+
+	users, err := b.Transfer.GetUsers("", 2)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "igor", users[0].Username)
+	assert.Equal(t, "igor-password", users[0].Password)
+	assert.Equal(t, "tester", users[1].Username)
+
+	users, err = b.Transfer.GetUsers("tester", 2)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "valera", users[0].Username)
+
+	require.NoError(t, b.Public.InsertMessage(entities.Message{Sender: "tester", Content: "first"}))
+	require.NoError(t, b.Public.InsertMessage(entities.Message{Sender: "valera", Content: "second"}))
+	require.NoError(t, b.Public.InsertMessage(entities.Message{Sender: "igor", Content: "third"}))
+
+	messages, err := b.Transfer.GetPublicMessages(0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, contents(messages))
+
+	messages, err = b.Transfer.GetPublicMessages(messages[1].ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"third"}, contents(messages))
+
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "hi"}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{
+		Sender: "valera", Recipient: "tester", Content: "secret", TTL: time.Hour, ExpireAfterRead: true,
+	}))
+	require.NoError(t, b.Private.InsertMessage(entities.Message{Sender: "igor", Recipient: "valera", Content: "hey"}))
+
+	// The message requests are among the partners.
+	others, err := b.Transfer.GetPartners("valera")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"igor", "tester"}, others)
+
+	others, err = b.Transfer.GetPartners("nobody")
+	require.NoError(t, err)
+	assert.Empty(t, others)
+
+	conversation, err := b.Transfer.GetPrivateMessages("valera", "tester", 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hi"}, contents(conversation))
+
+	conversation, err = b.Transfer.GetPrivateMessages("tester", "valera", conversation[0].ID, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"secret"}, contents(conversation))
+	assert.Equal(t, time.Hour, conversation[0].TTL)
+	assert.True(t, conversation[0].ExpireAfterRead)
+
+	conversation, err = b.Transfer.GetPrivateMessages("tester", "igor", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, conversation)
+}
+
+func testTransferImport(t *testing.T, b Backend) {
+	require.NoError(t, b.Transfer.ImportUser(entities.User{Username: "tester", Password: "hash"}))
+	require.NoError(t, b.Transfer.ImportUser(entities.User{Username: "valera", Password: "hash"}))
+	require.NoError(t, b.Transfer.ImportUser(entities.User{Username: "igor", Password: "hash"}))
+
+	user, err := b.Auth.GetUser("tester")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", user.Password)
+
+	sentAt := time.Date(2024, 3, 5, 14, 2, 26, 0, time.UTC)
+
+	require.NoError(t, b.Transfer.ImportPublicMessages([]entities.Message{
+		{Sender: "tester", Content: "old news", CreatedAt: sentAt},
+		{Sender: "valera", CreatedAt: sentAt.Add(time.Minute), Deleted: true},
+	}))
+
+	err = b.Transfer.ImportPublicMessages([]entities.Message{
+		{Sender: "tester", Content: "lost", CreatedAt: sentAt},
+		{Sender: "nobody", Content: "lost", CreatedAt: sentAt},
+	})
+	assert.ErrorIs(t, err, entities.ErrUserNotFound)
+
+	messages, err := b.Public.GetMessages(10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "old news", messages[0].Content)
+	assert.True(t, sentAt.Equal(messages[0].CreatedAt))
+	assert.True(t, messages[1].Deleted)
+
+	results, err := b.Public.SearchMessages(entities.SearchQuery{Terms: terms(t, "news"), Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	require.NoError(t, b.Transfer.ImportPrivateMessages([]entities.Message{
+		{Sender: "tester", Recipient: "valera", Content: "hi", CreatedAt: sentAt},
+		{Sender: "valera", Recipient: "tester", Content: "hello", CreatedAt: sentAt.Add(time.Minute)},
+		{Sender: "igor", Recipient: "valera", Content: "hey", CreatedAt: sentAt.Add(2 * time.Minute)},
+	}))
+
+	err = b.Transfer.ImportPrivateMessages([]entities.Message{{Sender: "tester", Recipient: "nobody", Content: "lost"}})
+	assert.ErrorIs(t, err, entities.ErrUserNotFound)
+
+	conversation, err := b.Private.GetMessages("tester", "valera", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	require.Equal(t, []string{"hi", "hello"}, contents(conversation))
+	assert.True(t, sentAt.Equal(conversation[0].CreatedAt))
+
+	// The imported messages fill the inboxes and the message requests the
+	// way sending them did.
+	inbox, err := b.Private.GetInbox("valera", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tester"}, partners(inbox))
+
+	requests, err := b.Private.GetMessageRequests("valera", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"igor"}, partners(requests))
+	assert.True(t, sentAt.Add(2*time.Minute).Equal(requests[0].LastMessage.CreatedAt))
+}
+
+func testDataExports(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	_, err := b.Export.GetDataExport("tester")
+	assert.ErrorIs(t, err, entities.ErrDataExportNotFound)
+
+	requestedAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	err = b.Export.SaveDataExport(entities.DataExport{ID: "e0", Username: "igor", Status: entities.DataExportPending, RequestedAt: requestedAt})
+	assert.ErrorIs(t, err, entities.ErrUserNotFound)
+
+	first := entities.DataExport{ID: "e1", Username: "tester", Status: entities.DataExportPending, RequestedAt: requestedAt}
+	require.NoError(t, b.Export.SaveDataExport(first))
+
+	export, err := b.Export.GetDataExport("tester")
+	require.NoError(t, err)
+	assert.Equal(t, "e1", export.ID)
+	assert.Equal(t, "tester", export.Username)
+	assert.Equal(t, entities.DataExportPending, export.Status)
+	assert.True(t, requestedAt.Equal(export.RequestedAt))
+	assert.True(t, export.ExpiresAt.IsZero())
+
+	// A new export replaces the one before, which is not recorded any more
+	// when it is done.
+	second := entities.DataExport{ID: "e2", Username: "tester", Status: entities.DataExportPending, RequestedAt: requestedAt.Add(time.Minute)}
+	require.NoError(t, b.Export.SaveDataExport(second))
+
+	first.Status = entities.DataExportReady
+	first.ExpiresAt = requestedAt.Add(time.Hour)
+	first.Size = 10
+
+	finished, err := b.Export.FinishDataExport(first)
+	require.NoError(t, err)
+	assert.False(t, finished)
+
+	second.Status = entities.DataExportReady
+	second.ExpiresAt = requestedAt.Add(time.Hour)
+	second.Size = 42
+
+	finished, err = b.Export.FinishDataExport(second)
+	require.NoError(t, err)
+	assert.True(t, finished)
+
+	export, err = b.Export.GetDataExport("tester")
+	require.NoError(t, err)
+	assert.Equal(t, "e2", export.ID)
+	assert.Equal(t, entities.DataExportReady, export.Status)
+	assert.Equal(t, int64(42), export.Size)
+	assert.True(t, second.RequestedAt.Equal(export.RequestedAt))
+	assert.True(t, second.ExpiresAt.Equal(export.ExpiresAt))
+}
+
+func testUnitCommit(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	require.NoError(t, b.Unit(func(tx Backend) error {
+		register(t, tx, "valera")
+		return tx.Private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "hi"})
+	}))
+
+	messages, err := b.Private.GetMessages("valera", "tester", 10, 0, entities.OrderOldestFirst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hi"}, contents(messages))
+
+	users, err := b.Private.GetUsers("tester")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"valera"}, users)
+}
+
+// checkRolledBack checks that nothing of the unit which registered valera
+// and wrote to tester is left, and that the database is not held by it.
+func checkRolledBack(t *testing.T, b Backend) {
+	t.Helper()
+
+	user, err := b.Auth.GetUser("valera")
+	assert.True(t, err != nil || user.Username == "")
+
+	// The in-memory backend reports a user without partners as an error.
+	users, _ := b.Private.GetUsers("tester")
+	assert.Empty(t, users)
+
+	register(t, b, "valera")
+	messages, _ := b.Private.GetMessages("valera", "tester", 10, 0, entities.OrderOldestFirst)
+	assert.Empty(t, messages)
+}
+
+func testUnitRollback(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	errStop := errors.New("stop")
+	err := b.Unit(func(tx Backend) error {
+		register(t, tx, "valera")
+		require.NoError(t, tx.Private.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "hi"}))
+
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+
+	checkRolledBack(t, b)
+}
+
+func testUnitPanic(t *testing.T, b Backend) {
+	register(t, b, "tester")
+
+	assert.PanicsWithValue(t, "stop", func() {
+		b.Unit(func(tx Backend) error {
+			register(t, tx, "valera")
+			require.NoError(t, tx.Private.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "hi"}))
+
+			panic("stop")
+		})
+	})
+
+	checkRolledBack(t, b)
+}
+
+// testUnitWriteFailure fails the write of a unit part-way: the unit is
+// rolled back, and left out of the database after a restart too.
+func testUnitWriteFailure(t *testing.T, b Backend) {
+	if b.FailWrites == nil {
+		t.Skip("the backend can not fail its writes")
+	}
+
+	register(t, b, "tester")
+
+	b.FailWrites(1024)
+	err := b.Unit(func(tx Backend) error {
+		register(t, tx, "valera")
+		return tx.Private.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "hi"})
+	})
+	assert.Error(t, err)
+
+	b = b.Reopen()
+
+	checkRolledBack(t, b)
+}
@@ -0,0 +1,306 @@
+package sqlite
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/database_utils/sqlite"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// The database runs in WAL mode, so readers do not wait for the writer, and
+// the transactions take the write lock when they begin, so two of them never
+// deadlock upgrading their read locks. The times are written in the format of
+// SQLite, which compares in order as long as they are all in UTC.
+const dsnOptions = "?_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)" +
+	"&_pragma=synchronous(NORMAL)&_txlock=immediate&_time_format=sqlite"
+
+// SqliteDB is a database in a single file, for installs without Postgres.
+type SqliteDB struct {
+	db *sqlx.DB
+}
+
+// Tx is a transaction of SqliteDB, with the same methods to run statements.
+type Tx struct {
+	tx *sqlx.Tx
+}
+
+// NewSqliteDB opens the database at cfg.Path, creating it if there is none.
+// Its schema is brought up to date by Migrate.
+func NewSqliteDB(cfg sqlite.SqliteConfig) (*SqliteDB, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, err
+	}
+
+	db, err := sqlx.Open("sqlite", "file:"+cfg.Path+dsnOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SqliteDB{db: db}, nil
+}
+
+func (db *SqliteDB) Get(query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := db.db.Queryx(query, utc(args)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (db *SqliteDB) Insert(query string, args ...interface{}) error {
+	_, err := db.db.Exec(query, utc(args)...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Tx runs fn in a transaction, which is committed if fn returns nil and
+// rolled back if it returns an error or panics.
+func (db *SqliteDB) Tx(fn func(tx *Tx) error) error {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&Tx{tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Ping checks that the database answers.
+func (db *SqliteDB) Ping(ctx context.Context) error {
+	return db.db.PingContext(ctx)
+}
+
+// Stats returns the state of the pool of connections to the file.
+func (db *SqliteDB) Stats() entities.PoolStats {
+	stats := db.db.Stats()
+
+	return entities.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// Shutdown closes the database.
+func (db *SqliteDB) Shutdown(_ context.Context) error {
+	return db.db.Close()
+}
+
+func (tx *Tx) Get(query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := tx.tx.Queryx(query, utc(args)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (tx *Tx) Insert(query string, args ...interface{}) error {
+	_, err := tx.tx.Exec(query, utc(args)...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Tx runs fn within the transaction, behind a savepoint: the changes of fn
+// are rolled back on their own if it fails, and kept with the transaction
+// otherwise. The repositories run with a transaction this way.
+func (tx *Tx) Tx(fn func(tx *Tx) error) error {
+	if err := tx.Insert("SAVEPOINT nested"); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.rollbackNested()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.rollbackNested(); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return tx.Insert("RELEASE nested")
+}
+
+func (tx *Tx) rollbackNested() error {
+	if err := tx.Insert("ROLLBACK TO nested"); err != nil {
+		return err
+	}
+
+	return tx.Insert("RELEASE nested")
+}
+
+// utc moves the times among the arguments to UTC.
+func utc(args []interface{}) []interface{} {
+	for i, arg := range args {
+		switch val := arg.(type) {
+		case time.Time:
+			args[i] = val.UTC()
+		case *time.Time:
+			if val != nil {
+				t := val.UTC()
+				args[i] = &t
+			}
+		}
+	}
+
+	return args
+}
+
+type migration struct {
+	version int
+	name    string
+}
+
+// PendingMigrations returns the names of the migrations Migrate would apply,
+// without applying them.
+func (db *SqliteDB) PendingMigrations() ([]string, error) {
+	pending, err := db.pendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return names(pending), nil
+}
+
+// Migrate applies the migrations newer than the user_version of the
+// database, each in a transaction of its own, and returns their names. The
+// transactions take the write lock, so two processes opening the database
+// together do not apply a migration twice.
+func (db *SqliteDB) Migrate() ([]string, error) {
+	pending, err := db.pendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range pending {
+		if err := db.applyMigration(m); err != nil {
+			return nil, fmt.Errorf("sqlite: migration %s: %w", m.name, err)
+		}
+	}
+
+	return names(pending), nil
+}
+
+// pendingMigrations returns the migrations newer than the user_version of
+// the database in the order of their versions. A migration is named after
+// its version, like 0001_init.sql.
+func (db *SqliteDB) pendingMigrations() ([]migration, error) {
+	var current int
+	if err := db.db.Get(&current, "PRAGMA user_version"); err != nil {
+		return nil, err
+	}
+
+	entries, err := migrations.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []migration
+	for _, entry := range entries {
+		version, err := strconv.Atoi(strings.SplitN(entry.Name(), "_", 2)[0])
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: migration %s: %w", entry.Name(), err)
+		}
+
+		if version > current {
+			pending = append(pending, migration{version: version, name: strings.TrimSuffix(entry.Name(), ".sql")})
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].version < pending[j].version
+	})
+
+	return pending, nil
+}
+
+func (db *SqliteDB) applyMigration(m migration) error {
+	script, err := migrations.ReadFile("migrations/" + m.name + ".sql")
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	// Another process may have applied it while this one waited for the
+	// lock.
+	var current int
+	if err := tx.Get(&current, "PRAGMA user_version"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if current >= m.version {
+		return tx.Rollback()
+	}
+
+	// PRAGMA takes no placeholders.
+	if _, err := tx.Exec(string(script) + fmt.Sprintf(";\nPRAGMA user_version = %d", m.version)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func names(migrations []migration) []string {
+	result := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		result = append(result, m.name)
+	}
+
+	return result
+}
@@ -0,0 +1,312 @@
+-- The schema of the Postgres migrations up to 20261019233000_moderation, in
+-- the types SQLite has. Times are stored as text in UTC, in the format the
+-- driver writes them in, so they compare in order; booleans are 0 and 1.
+
+CREATE TABLE users
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    last_seen TIMESTAMP,
+    avatar_id TEXT,
+    avatar_content_type TEXT,
+    avatar_updated_at TIMESTAMP
+);
+
+CREATE TABLE global_chat
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sender_id INTEGER REFERENCES users(id),
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    deleted BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE private_chats
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sender_id INTEGER REFERENCES users(id),
+    recipient_id INTEGER REFERENCES users(id),
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    ttl_seconds INTEGER CHECK (ttl_seconds > 0),
+    expire_after_read BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP,
+    expired BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX private_chats_sender_recipient_id_idx ON private_chats (sender_id, recipient_id, id);
+
+CREATE INDEX private_chats_expires_at_idx ON private_chats (expires_at, id) WHERE expires_at IS NOT NULL AND NOT expired;
+
+-- The search indexes keep the words of the messages; the triggers keep them
+-- in step with the chats.
+CREATE VIRTUAL TABLE global_chat_search USING fts5
+(
+    message,
+    content = 'global_chat',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 0'
+);
+
+CREATE TRIGGER global_chat_search_insert AFTER INSERT ON global_chat BEGIN
+    INSERT INTO global_chat_search(rowid, message) VALUES (new.id, new.message);
+END;
+
+CREATE TRIGGER global_chat_search_update AFTER UPDATE OF message ON global_chat BEGIN
+    INSERT INTO global_chat_search(global_chat_search, rowid, message) VALUES ('delete', old.id, old.message);
+    INSERT INTO global_chat_search(rowid, message) VALUES (new.id, new.message);
+END;
+
+CREATE TRIGGER global_chat_search_delete AFTER DELETE ON global_chat BEGIN
+    INSERT INTO global_chat_search(global_chat_search, rowid, message) VALUES ('delete', old.id, old.message);
+END;
+
+CREATE VIRTUAL TABLE private_chats_search USING fts5
+(
+    message,
+    content = 'private_chats',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 0'
+);
+
+CREATE TRIGGER private_chats_search_insert AFTER INSERT ON private_chats BEGIN
+    INSERT INTO private_chats_search(rowid, message) VALUES (new.id, new.message);
+END;
+
+CREATE TRIGGER private_chats_search_update AFTER UPDATE OF message ON private_chats BEGIN
+    INSERT INTO private_chats_search(private_chats_search, rowid, message) VALUES ('delete', old.id, old.message);
+    INSERT INTO private_chats_search(rowid, message) VALUES (new.id, new.message);
+END;
+
+CREATE TRIGGER private_chats_search_delete AFTER DELETE ON private_chats BEGIN
+    INSERT INTO private_chats_search(private_chats_search, rowid, message) VALUES ('delete', old.id, old.message);
+END;
+
+CREATE TABLE private_chat_reads
+(
+    reader_id INTEGER REFERENCES users(id),
+    partner_id INTEGER REFERENCES users(id),
+    last_read_message_id INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (reader_id, partner_id)
+);
+
+CREATE TABLE conversations
+(
+    user_id INTEGER REFERENCES users(id),
+    partner_id INTEGER REFERENCES users(id),
+    last_message_id INTEGER NOT NULL REFERENCES private_chats(id),
+    last_message_at TIMESTAMP NOT NULL,
+    is_request BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (user_id, partner_id)
+);
+
+CREATE INDEX conversations_user_activity_idx ON conversations (user_id, last_message_at DESC, partner_id);
+
+CREATE TABLE attachments
+(
+    id TEXT PRIMARY KEY,
+    global_message_id INTEGER REFERENCES global_chat(id) ON DELETE CASCADE,
+    private_message_id INTEGER REFERENCES private_chats(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    CHECK ((global_message_id IS NULL) <> (private_message_id IS NULL))
+);
+
+CREATE INDEX attachments_global_message_id_idx ON attachments (global_message_id, position) WHERE global_message_id IS NOT NULL;
+
+CREATE INDEX attachments_private_message_id_idx ON attachments (private_message_id, position) WHERE private_message_id IS NOT NULL;
+
+CREATE TABLE notifications
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    global_message_id INTEGER REFERENCES global_chat(id) ON DELETE CASCADE,
+    private_message_id INTEGER REFERENCES private_chats(id) ON DELETE CASCADE,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    CHECK ((global_message_id IS NULL) <> (private_message_id IS NULL))
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, id DESC);
+
+CREATE INDEX notifications_unread_idx ON notifications (user_id, id DESC) WHERE read_at IS NULL;
+
+-- The events of a webhook are joined with commas.
+CREATE TABLE webhooks
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    events TEXT NOT NULL,
+    channel TEXT,
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    failures INTEGER NOT NULL DEFAULT 0,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE webhook_events
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    message_id INTEGER NOT NULL,
+    sender TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id INTEGER NOT NULL REFERENCES webhook_events(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    response_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id DESC);
+
+CREATE TABLE bots
+(
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE incoming_webhooks
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token_hash TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    bot_id INTEGER NOT NULL REFERENCES bots(user_id),
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    last_used_at TIMESTAMP
+);
+
+CREATE TABLE bot_commands
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    command TEXT NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    bot_id INTEGER NOT NULL REFERENCES bots(user_id),
+    secret TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE scheduled_messages
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel TEXT NOT NULL,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    send_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX scheduled_messages_due_idx ON scheduled_messages (send_at, id) WHERE status = 'pending';
+
+CREATE INDEX scheduled_messages_sender_id_idx ON scheduled_messages (sender_id, send_at, id) WHERE status <> 'sent';
+
+CREATE TABLE reminders
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    content TEXT NOT NULL,
+    schedule TEXT NOT NULL,
+    time_zone TEXT NOT NULL,
+    sender_id INTEGER NOT NULL REFERENCES bots(user_id),
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMP NOT NULL,
+    last_run_at TIMESTAMP,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX reminders_due_idx ON reminders (next_run_at, id) WHERE NOT paused;
+
+CREATE TABLE privacy_settings
+(
+    user_id INTEGER PRIMARY KEY REFERENCES users(id),
+    dm_policy TEXT NOT NULL DEFAULT 'anyone' CHECK (dm_policy IN ('anyone', 'contacts', 'nobody')),
+    hide_blocked_in_feed BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE blocks
+(
+    user_id INTEGER REFERENCES users(id),
+    blocked_id INTEGER REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (user_id, blocked_id),
+    CHECK (user_id <> blocked_id)
+);
+
+CREATE TABLE contacts
+(
+    user_id INTEGER REFERENCES users(id),
+    contact_id INTEGER REFERENCES users(id),
+    PRIMARY KEY (user_id, contact_id)
+);
+
+CREATE TABLE reports
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id INTEGER NOT NULL REFERENCES global_chat(id) ON DELETE CASCADE,
+    reporter_id INTEGER NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    resolved_by INTEGER REFERENCES users(id),
+    resolved_at TIMESTAMP
+);
+
+-- A user reports a message once until the report is closed.
+CREATE UNIQUE INDEX reports_open_idx ON reports (message_id, reporter_id) WHERE status = 'open';
+
+CREATE INDEX reports_status_idx ON reports (status, id);
+
+CREATE TABLE sanctions
+(
+    user_id INTEGER REFERENCES users(id),
+    kind TEXT CHECK (kind IN ('mute', 'ban', 'suspend')),
+    reason TEXT NOT NULL DEFAULT '',
+    until TIMESTAMP,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (user_id, kind)
+);
+
+-- The log keeps the IDs of the messages and reports without foreign keys,
+-- so it outlives them.
+CREATE TABLE moderation_log
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    moderator_id INTEGER NOT NULL REFERENCES users(id),
+    action TEXT NOT NULL,
+    target_id INTEGER REFERENCES users(id),
+    message_id INTEGER,
+    report_id INTEGER,
+    reason TEXT NOT NULL DEFAULT '',
+    until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
@@ -0,0 +1,25 @@
+-- The history of a conversation, in both directions, in the order of the
+-- messages.
+CREATE INDEX private_chats_pair_idx ON private_chats (min(sender_id, recipient_id), max(sender_id, recipient_id), id);
+
+-- The inbox and the message requests of a user, the latest first.
+DROP INDEX conversations_user_activity_idx;
+
+CREATE INDEX conversations_user_request_activity_idx ON conversations (user_id, is_request, last_message_at DESC, partner_id);
+
+-- The other sides of the foreign keys.
+CREATE INDEX global_chat_sender_id_idx ON global_chat (sender_id);
+
+CREATE INDEX private_chats_recipient_id_idx ON private_chats (recipient_id);
+
+CREATE INDEX conversations_partner_id_idx ON conversations (partner_id);
+
+CREATE INDEX private_chat_reads_partner_id_idx ON private_chat_reads (partner_id);
+
+CREATE INDEX blocks_blocked_id_idx ON blocks (blocked_id);
+
+CREATE INDEX contacts_contact_id_idx ON contacts (contact_id);
+
+CREATE INDEX notifications_sender_id_idx ON notifications (sender_id);
+
+CREATE INDEX reports_reporter_id_idx ON reports (reporter_id);
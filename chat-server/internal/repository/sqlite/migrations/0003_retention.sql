@@ -0,0 +1,11 @@
+-- The users on legal hold, whose conversations the retention policies keep.
+CREATE TABLE legal_holds
+(
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    placed_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+-- The purge walks the chats from the oldest message.
+CREATE INDEX global_chat_created_at_idx ON global_chat (created_at, id);
+
+CREATE INDEX private_chats_created_at_idx ON private_chats (created_at, id);
@@ -0,0 +1,93 @@
+package repos
+
+import (
+	"fmt"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+)
+
+const (
+	globalMessageColumn  = "global_message_id"
+	privateMessageColumn = "private_message_id"
+)
+
+// insertAttachments links the attachments to the message through the given
+// column of the attachments table, in their original order.
+func insertAttachments(tx *sqlite.Tx, column string, messageID int, attachments []entities.Attachment) error {
+	query := fmt.Sprintf("INSERT INTO attachments(id, %s, file_name, content_type, size, position) "+
+		"VALUES ($1, $2, $3, $4, $5, $6)", column)
+
+	for i, val := range attachments {
+		if err := tx.Insert(query, val.ID, messageID, val.FileName, val.ContentType, val.Size, i+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadAttachments fills in the attachments of the messages, which are linked
+// to them through the given column of the attachments table.
+func loadAttachments(db querier, column string, messages []models.MessageModel) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(messages))
+	byID := make(map[int]int, len(messages))
+	for i, val := range messages {
+		ids = append(ids, val.ID)
+		byID[val.ID] = i
+	}
+
+	query := fmt.Sprintf("SELECT id, %[1]s AS message_id, file_name, content_type, size, created_at "+
+		"FROM attachments "+
+		"WHERE %[1]s IN (SELECT value FROM json_each($1)) "+
+		"ORDER BY %[1]s, position", column)
+
+	rows, err := db.Get(query, jsonArray(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attachment models.AttachmentModel
+		err := rows.StructScan(&attachment)
+		if err != nil {
+			return err
+		}
+
+		i := byID[attachment.MessageID]
+		messages[i].Attachments = append(messages[i].Attachments, attachment)
+	}
+
+	return rows.Err()
+}
+
+func getAttachment(db querier, query, id string) (entities.Attachment, entities.Message, error) {
+	rows, err := db.Get(query, id)
+	if err != nil {
+		return entities.Attachment{}, entities.Message{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return entities.Attachment{}, entities.Message{}, err
+		}
+
+		return entities.Attachment{}, entities.Message{}, entities.ErrAttachmentNotFound
+	}
+
+	var attachment models.MessageAttachmentModel
+	if err := rows.StructScan(&attachment); err != nil {
+		return entities.Attachment{}, entities.Message{}, err
+	}
+
+	a, m := mapper.MessageAttachmentModelToEntities(attachment)
+
+	return a, m, nil
+}
@@ -0,0 +1,49 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+type AuthSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type AuthSqliteRepos struct {
+	db AuthSqliteDB
+}
+
+func NewAuthSqliteRepos(db AuthSqliteDB) *AuthSqliteRepos {
+	return &AuthSqliteRepos{db: db}
+}
+
+func (r *AuthSqliteRepos) InsertUser(username, password string) error {
+	query := "INSERT INTO users(username, password_hash) VALUES ($1, $2)"
+
+	if err := r.db.Insert(query, username, password); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *AuthSqliteRepos) GetUser(username string) (entities.User, error) {
+	query := "SELECT username, password_hash FROM users WHERE username = $1"
+
+	rows, err := r.db.Get(query, username)
+	if err != nil {
+		return entities.User{}, err
+	}
+	defer rows.Close()
+
+	var user models.UserModel
+	for rows.Next() {
+		if err := rows.StructScan(&user); err != nil {
+			return entities.User{}, err
+		}
+	}
+
+	return entities.User{Username: user.Username, Password: user.Password}, rows.Err()
+}
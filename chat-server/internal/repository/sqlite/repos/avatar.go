@@ -0,0 +1,97 @@
+package repos
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+)
+
+type AvatarSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+type AvatarSqliteRepos struct {
+	db AvatarSqliteDB
+}
+
+func NewAvatarSqliteRepos(db AvatarSqliteDB) *AvatarSqliteRepos {
+	return &AvatarSqliteRepos{db: db}
+}
+
+// SetAvatar replaces the avatar of the user unless a newer one was set in
+// the meantime. An avatar with an empty ID removes the current one. It
+// returns the ID of the avatar which is no longer used, if any.
+func (a *AvatarSqliteRepos) SetAvatar(avatar entities.Avatar) (string, error) {
+	stale := avatar.ID
+
+	err := a.db.Tx(func(tx *sqlite.Tx) error {
+		rows, err := tx.Get("SELECT avatar_id FROM users WHERE username = $1", avatar.Username)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		if !rows.Next() {
+			return rows.Err()
+		}
+
+		var old sql.NullString
+		if err := rows.Scan(&old); err != nil {
+			return err
+		}
+		rows.Close()
+
+		query := "UPDATE users " +
+			"SET avatar_id = NULLIF($2, ''), avatar_content_type = NULLIF($3, ''), avatar_updated_at = $4 " +
+			"WHERE username = $1 AND (avatar_updated_at IS NULL OR avatar_updated_at <= $4) " +
+			"RETURNING id"
+
+		ids, err := returnedIDs(tx, query, avatar.Username, avatar.ID, avatar.ContentType, avatar.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) > 0 {
+			stale = old.String
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return stale, nil
+}
+
+func (a *AvatarSqliteRepos) GetAvatar(username string) (entities.Avatar, error) {
+	query := "SELECT username, avatar_id, avatar_content_type, avatar_updated_at " +
+		"FROM users " +
+		"WHERE username = $1 AND avatar_id IS NOT NULL"
+
+	rows, err := a.db.Get(query, username)
+	if err != nil {
+		return entities.Avatar{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return entities.Avatar{}, err
+		}
+
+		return entities.Avatar{}, entities.ErrAvatarNotFound
+	}
+
+	var avatar models.AvatarModel
+	if err := rows.StructScan(&avatar); err != nil {
+		return entities.Avatar{}, err
+	}
+
+	return mapper.AvatarModelToEntity(avatar), nil
+}
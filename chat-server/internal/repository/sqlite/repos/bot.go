@@ -0,0 +1,164 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+)
+
+const botCommandColumns = "c.id, c.command, c.url, c.description, u.username AS bot_name, c.secret, c.created_by, c.created_at"
+
+type BotSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+type BotCommandSqliteRepos struct {
+	db BotSqliteDB
+}
+
+func NewBotCommandSqliteRepos(db BotSqliteDB) *BotCommandSqliteRepos {
+	return &BotCommandSqliteRepos{db: db}
+}
+
+// InsertBotCommand saves the command and registers its bot as a user with
+// the given password, unless the bot exists already.
+func (r *BotCommandSqliteRepos) InsertBotCommand(c entities.BotCommand, botPassword string) (entities.BotCommand, error) {
+	var commands []entities.BotCommand
+
+	err := r.db.Tx(func(tx *sqlite.Tx) error {
+		taken, err := exists(tx, "SELECT EXISTS (SELECT 1 FROM bot_commands WHERE command = $1)", c.Command)
+		if err != nil {
+			return err
+		}
+
+		if taken {
+			return entities.ErrCommandTaken
+		}
+
+		botID, err := ensureBot(tx, c.BotName, botPassword)
+		if err != nil {
+			return err
+		}
+
+		query := "INSERT INTO bot_commands(command, url, description, bot_id, secret, created_by) " +
+			"VALUES ($1, $2, $3, $4, $5, $6) " +
+			"RETURNING id"
+
+		ids, err := returnedIDs(tx, query, c.Command, c.URL, c.Description, botID, c.Secret, c.CreatedBy)
+		if err != nil {
+			return err
+		}
+
+		commands, err = listBotCommands(tx, "SELECT "+botCommandColumns+" FROM bot_commands c "+
+			"JOIN users u ON u.id = c.bot_id WHERE c.id IN (SELECT value FROM json_each($1))", jsonArray(ids))
+
+		return err
+	})
+	if err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	if len(commands) == 0 {
+		return entities.BotCommand{}, entities.ErrBotCommandNotFound
+	}
+
+	return commands[0], nil
+}
+
+func (r *BotCommandSqliteRepos) GetBotCommands() ([]entities.BotCommand, error) {
+	return listBotCommands(r.db, "SELECT "+botCommandColumns+" FROM bot_commands c JOIN users u ON u.id = c.bot_id ORDER BY c.command")
+}
+
+func (r *BotCommandSqliteRepos) GetBotCommand(command string) (entities.BotCommand, error) {
+	commands, err := listBotCommands(r.db, "SELECT "+botCommandColumns+" FROM bot_commands c JOIN users u ON u.id = c.bot_id WHERE c.command = $1", command)
+	if err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	if len(commands) == 0 {
+		return entities.BotCommand{}, entities.ErrBotCommandNotFound
+	}
+
+	return commands[0], nil
+}
+
+// DeleteBotCommand removes the command. Its bot and the messages it posted
+// are kept.
+func (r *BotCommandSqliteRepos) DeleteBotCommand(id int) error {
+	ids, err := returnedIDs(r.db, "DELETE FROM bot_commands WHERE id = $1 RETURNING id", id)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return entities.ErrBotCommandNotFound
+	}
+
+	return nil
+}
+
+func listBotCommands(db querier, query string, args ...interface{}) ([]entities.BotCommand, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commands := make([]entities.BotCommand, 0)
+	for rows.Next() {
+		var model models.BotCommandModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		commands = append(commands, mapper.BotCommandModelToEntity(model))
+	}
+
+	return commands, rows.Err()
+}
+
+// ensureBot returns the user ID of the bot, creating the bot as a user with
+// the password unless it exists. It fails when the name belongs to a person
+// rather than a bot.
+func ensureBot(tx *sqlite.Tx, name, password string) (int, error) {
+	rows, err := tx.Get("SELECT EXISTS (SELECT 1 FROM users WHERE username = $1) AS user_exists, "+
+		"EXISTS (SELECT 1 FROM bots b JOIN users u ON u.id = b.user_id WHERE u.username = $1) AS is_bot", name)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var model models.BotNameModel
+	for rows.Next() {
+		if err := rows.StructScan(&model); err != nil {
+			return 0, err
+		}
+	}
+	rows.Close()
+
+	if model.UserExists && !model.IsBot {
+		return 0, entities.ErrBotNameTaken
+	}
+
+	if !model.UserExists {
+		if err := tx.Insert("INSERT INTO users(username, password_hash) VALUES ($1, $2)", name, password); err != nil {
+			return 0, err
+		}
+
+		if err := tx.Insert("INSERT INTO bots(user_id) SELECT id FROM users WHERE username = $1", name); err != nil {
+			return 0, err
+		}
+	}
+
+	ids, err := returnedIDs(tx, "SELECT id FROM users WHERE username = $1", name)
+	if err != nil {
+		return 0, err
+	}
+
+	return ids[0], nil
+}
@@ -0,0 +1,41 @@
+package repos
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/repository/repotest"
+	sqlitedb "github.com/vavelour/chat/internal/repository/sqlite"
+	"github.com/vavelour/chat/pkg/database_utils/sqlite"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		db, err := sqlitedb.NewSqliteDB(sqlite.SqliteConfig{Path: filepath.Join(t.TempDir(), "chat.db")})
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Shutdown(context.Background()) })
+
+		_, err = db.Migrate()
+		require.NoError(t, err)
+
+		return repotest.Backend{
+			Auth:      NewAuthSqliteRepos(db),
+			Public:    NewPublicSqliteRepos(db),
+			Private:   NewPrivateSqliteRepos(db),
+			Retention: NewRetentionSqliteRepos(db),
+			Transfer:  NewTransferSqliteRepos(db),
+			Export:    NewExportSqliteRepos(db),
+			Unit: func(fn func(b repotest.Backend) error) error {
+				return db.Tx(func(tx *sqlitedb.Tx) error {
+					return fn(repotest.Backend{
+						Auth:    NewAuthSqliteRepos(tx),
+						Public:  NewPublicSqliteRepos(tx),
+						Private: NewPrivateSqliteRepos(tx),
+					})
+				})
+			},
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package repos
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	sqlitedb "github.com/vavelour/chat/internal/repository/sqlite"
+	"github.com/vavelour/chat/pkg/database_utils/sqlite"
+)
+
+// explainDB records the plans of the queries it runs.
+type explainDB struct {
+	*sqlitedb.SqliteDB
+	t     *testing.T
+	plans []string
+}
+
+func (db *explainDB) Get(query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := db.SqliteDB.Get("EXPLAIN QUERY PLAN "+query, args...)
+	require.NoError(db.t, err)
+
+	var plan []string
+	for rows.Next() {
+		var step struct {
+			ID      int    `db:"id"`
+			Parent  int    `db:"parent"`
+			NotUsed int    `db:"notused"`
+			Detail  string `db:"detail"`
+		}
+		require.NoError(db.t, rows.StructScan(&step))
+		plan = append(plan, step.Detail)
+	}
+	rows.Close()
+
+	db.plans = append(db.plans, strings.Join(plan, "\n"))
+
+	return db.SqliteDB.Get(query, args...)
+}
+
+func TestIndexUsage(t *testing.T) {
+	db, err := sqlitedb.NewSqliteDB(sqlite.SqliteConfig{Path: filepath.Join(t.TempDir(), "chat.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Shutdown(context.Background()) })
+
+	_, err = db.Migrate()
+	require.NoError(t, err)
+
+	auth := NewAuthSqliteRepos(db)
+	for _, username := range []string{"tester", "valera"} {
+		require.NoError(t, auth.InsertUser(username, "password"))
+	}
+
+	private := NewPrivateSqliteRepos(db)
+	require.NoError(t, private.InsertMessage(entities.Message{Sender: "tester", Recipient: "valera", Content: "hi"}))
+	require.NoError(t, private.InsertMessage(entities.Message{Sender: "valera", Recipient: "tester", Content: "hello"}))
+
+	testTable := []struct {
+		name  string
+		query func(p *PrivateSqliteRepos) error
+		index string
+	}{
+		{
+			name: "history",
+			query: func(p *PrivateSqliteRepos) error {
+				_, err := p.GetMessages("valera", "tester", 10, 0, entities.OrderOldestFirst)
+				return err
+			},
+			index: "private_chats_pair_idx",
+		},
+		{
+			name: "partners",
+			query: func(p *PrivateSqliteRepos) error {
+				_, err := p.GetUsers("tester")
+				return err
+			},
+			index: "conversations_user_request_activity_idx",
+		},
+		{
+			name: "inbox",
+			query: func(p *PrivateSqliteRepos) error {
+				_, err := p.GetInbox("tester", 10, 0)
+				return err
+			},
+			index: "conversations_user_request_activity_idx",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			explained := &explainDB{SqliteDB: db, t: t}
+
+			require.NoError(t, testCase.query(NewPrivateSqliteRepos(explained)))
+			require.NotEmpty(t, explained.plans)
+			assert.Contains(t, explained.plans[0], testCase.index)
+		})
+	}
+}
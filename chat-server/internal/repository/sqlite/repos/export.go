@@ -0,0 +1,102 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+	"time"
+)
+
+type ExportSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+// ExportSqliteRepos keeps the latest export of the data of every user, so
+// that the exports outlive a restart.
+type ExportSqliteRepos struct {
+	db ExportSqliteDB
+}
+
+func NewExportSqliteRepos(db ExportSqliteDB) *ExportSqliteRepos {
+	return &ExportSqliteRepos{db: db}
+}
+
+func (r *ExportSqliteRepos) GetDataExport(username string) (entities.DataExport, error) {
+	query := "SELECT e.id, u.username, e.status, e.requested_at, e.expires_at, e.size " +
+		"FROM data_exports e " +
+		"JOIN users u ON u.id = e.user_id " +
+		"WHERE u.username = $1"
+
+	rows, err := r.db.Get(query, username)
+	if err != nil {
+		return entities.DataExport{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return entities.DataExport{}, err
+		}
+
+		return entities.DataExport{}, entities.ErrDataExportNotFound
+	}
+
+	var model models.DataExportModel
+	if err := rows.StructScan(&model); err != nil {
+		return entities.DataExport{}, err
+	}
+
+	return mapper.DataExportModelToEntity(model), nil
+}
+
+// SaveDataExport replaces the export of the user.
+func (r *ExportSqliteRepos) SaveDataExport(export entities.DataExport) error {
+	return r.db.Tx(func(tx *sqlite.Tx) error {
+		found, err := exists(tx, "SELECT EXISTS (SELECT 1 FROM users WHERE username = $1)", export.Username)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return entities.ErrUserNotFound
+		}
+
+		query := "INSERT INTO data_exports(user_id, id, status, requested_at, expires_at, size) " +
+			"SELECT id, $2, $3, $4, $5, $6 FROM users WHERE username = $1 " +
+			"ON CONFLICT (user_id) DO UPDATE " +
+			"SET id = excluded.id, status = excluded.status, requested_at = excluded.requested_at, " +
+			"expires_at = excluded.expires_at, size = excluded.size"
+
+		return tx.Insert(query, export.Username, export.ID, string(export.Status), export.RequestedAt,
+			expiresAt(export), export.Size)
+	})
+}
+
+// FinishDataExport records the status, size and expiry of the export, and
+// reports whether it is still the export of its user: one replaced by a
+// newer export is not recorded.
+func (r *ExportSqliteRepos) FinishDataExport(export entities.DataExport) (bool, error) {
+	query := "UPDATE data_exports SET status = $2, expires_at = $3, size = $4 " +
+		"WHERE id = $1 " +
+		"RETURNING user_id"
+
+	finished, err := returnedIDs(r.db, query, export.ID, string(export.Status), expiresAt(export), export.Size)
+	if err != nil {
+		return false, err
+	}
+
+	return len(finished) > 0, nil
+}
+
+// expiresAt is the expiry of the export, which a pending one has none of.
+func expiresAt(export entities.DataExport) *time.Time {
+	if export.ExpiresAt.IsZero() {
+		return nil
+	}
+
+	return &export.ExpiresAt
+}
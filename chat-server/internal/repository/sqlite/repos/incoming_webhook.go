@@ -0,0 +1,122 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+)
+
+const incomingWebhookColumns = "iw.id, iw.name, iw.channel, u.username AS bot_name, iw.created_by, iw.created_at, iw.last_used_at"
+
+type IncomingWebhookSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+type IncomingWebhookSqliteRepos struct {
+	db IncomingWebhookSqliteDB
+}
+
+func NewIncomingWebhookSqliteRepos(db IncomingWebhookSqliteDB) *IncomingWebhookSqliteRepos {
+	return &IncomingWebhookSqliteRepos{db: db}
+}
+
+// InsertIncomingWebhook saves the webhook and registers its bot as a user
+// with the given password, unless the bot exists already.
+func (r *IncomingWebhookSqliteRepos) InsertIncomingWebhook(w entities.IncomingWebhook, tokenHash, botPassword string) (entities.IncomingWebhook, error) {
+	var webhooks []entities.IncomingWebhook
+
+	err := r.db.Tx(func(tx *sqlite.Tx) error {
+		botID, err := ensureBot(tx, w.BotName, botPassword)
+		if err != nil {
+			return err
+		}
+
+		query := "INSERT INTO incoming_webhooks(token_hash, name, channel, bot_id, created_by) " +
+			"VALUES ($1, $2, $3, $4, $5) " +
+			"RETURNING id"
+
+		ids, err := returnedIDs(tx, query, tokenHash, w.Name, w.Channel, botID, w.CreatedBy)
+		if err != nil {
+			return err
+		}
+
+		webhooks, err = listIncomingWebhooks(tx, "SELECT "+incomingWebhookColumns+" FROM incoming_webhooks iw "+
+			"JOIN users u ON u.id = iw.bot_id WHERE iw.id IN (SELECT value FROM json_each($1))", jsonArray(ids))
+
+		return err
+	})
+	if err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.IncomingWebhook{}, entities.ErrIncomingWebhookNotFound
+	}
+
+	return webhooks[0], nil
+}
+
+func (r *IncomingWebhookSqliteRepos) GetIncomingWebhooks() ([]entities.IncomingWebhook, error) {
+	return listIncomingWebhooks(r.db, "SELECT "+incomingWebhookColumns+" FROM incoming_webhooks iw "+
+		"JOIN users u ON u.id = iw.bot_id ORDER BY iw.id")
+}
+
+func (r *IncomingWebhookSqliteRepos) GetIncomingWebhookByToken(tokenHash string) (entities.IncomingWebhook, error) {
+	webhooks, err := listIncomingWebhooks(r.db, "SELECT "+incomingWebhookColumns+" FROM incoming_webhooks iw "+
+		"JOIN users u ON u.id = iw.bot_id WHERE iw.token_hash = $1", tokenHash)
+	if err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.IncomingWebhook{}, entities.ErrIncomingWebhookNotFound
+	}
+
+	return webhooks[0], nil
+}
+
+// DeleteIncomingWebhook revokes the webhook. Its bot and the messages it
+// posted are kept.
+func (r *IncomingWebhookSqliteRepos) DeleteIncomingWebhook(id int) error {
+	ids, err := returnedIDs(r.db, "DELETE FROM incoming_webhooks WHERE id = $1 RETURNING id", id)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return entities.ErrIncomingWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *IncomingWebhookSqliteRepos) TouchIncomingWebhook(id int, usedAt time.Time) error {
+	return r.db.Insert("UPDATE incoming_webhooks SET last_used_at = $2 WHERE id = $1", id, usedAt)
+}
+
+func listIncomingWebhooks(db querier, query string, args ...interface{}) ([]entities.IncomingWebhook, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]entities.IncomingWebhook, 0)
+	for rows.Next() {
+		var model models.IncomingWebhookModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, mapper.IncomingWebhookModelToEntity(model))
+	}
+
+	return webhooks, rows.Err()
+}
@@ -0,0 +1,362 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+	"time"
+)
+
+const reportSelect = "SELECT r.id, r.message_id, rp.username AS reporter, r.reason, r.status, r.created_at, " +
+	"rb.username AS resolved_by, r.resolved_at, " +
+	"su.username AS sender, gc.message, gc.deleted, gc.created_at AS message_created_at " +
+	"FROM reports r " +
+	"JOIN users rp ON rp.id = r.reporter_id " +
+	"LEFT JOIN users rb ON rb.id = r.resolved_by " +
+	"JOIN global_chat gc ON gc.id = r.message_id " +
+	"JOIN users su ON su.id = gc.sender_id "
+
+const sanctionSelect = "SELECT u.username, s.kind, s.reason, s.until, cb.username AS created_by, s.created_at " +
+	"FROM sanctions s " +
+	"JOIN users u ON u.id = s.user_id " +
+	"LEFT JOIN users cb ON cb.id = s.created_by "
+
+// insertAuditEntry writes the entry of the moderator $1 with the action $2
+// and the reason $3 against the sender of the public message $4.
+const insertAuditEntry = "INSERT INTO moderation_log(moderator_id, action, target_id, message_id, report_id, reason) " +
+	"SELECT (SELECT id FROM users WHERE username = $1), $2, gc.sender_id, gc.id, $5, $3 " +
+	"FROM global_chat gc WHERE gc.id = $4"
+
+type ModerationSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+// ModerationSqliteRepos keeps the reports, the sanctions and the audit log.
+// Every moderation action is written with its audit entry in one
+// transaction.
+type ModerationSqliteRepos struct {
+	db ModerationSqliteDB
+}
+
+func NewModerationSqliteRepos(db ModerationSqliteDB) *ModerationSqliteRepos {
+	return &ModerationSqliteRepos{db: db}
+}
+
+func (p *ModerationSqliteRepos) InsertReport(report entities.Report) (entities.Report, error) {
+	var inserted entities.Report
+
+	err := p.db.Tx(func(tx *sqlite.Tx) error {
+		senders, err := returnedNames(tx, "SELECT u.username FROM global_chat gc "+
+			"JOIN users u ON u.id = gc.sender_id WHERE gc.id = $1 AND NOT gc.deleted", report.MessageID)
+		if err != nil {
+			return err
+		}
+
+		if len(senders) == 0 {
+			return entities.ErrMessageNotFound
+		}
+
+		if senders[0] == report.Reporter {
+			return entities.ErrReportOwnMessage
+		}
+
+		query := "INSERT INTO reports(message_id, reporter_id, reason) " +
+			"SELECT $1, id, $3 FROM users WHERE username = $2 " +
+			"ON CONFLICT DO NOTHING " +
+			"RETURNING id"
+
+		ids, err := returnedIDs(tx, query, report.MessageID, report.Reporter, report.Reason)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return entities.ErrAlreadyReported
+		}
+
+		inserted, err = getReport(tx, ids[0])
+
+		return err
+	})
+	if err != nil {
+		return entities.Report{}, err
+	}
+
+	return inserted, nil
+}
+
+// GetReports returns the reports with the status: the open ones oldest
+// first, as a queue, and the closed ones latest first.
+func (p *ModerationSqliteRepos) GetReports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error) {
+	query := reportSelect +
+		"WHERE r.status = $1 " +
+		"ORDER BY CASE WHEN r.status = 'open' THEN r.id END ASC, r.id DESC " +
+		"LIMIT $2 OFFSET $3"
+
+	return listReports(p.db, query, string(status), limit, offset)
+}
+
+// CloseReport resolves or dismisses the open report.
+func (p *ModerationSqliteRepos) CloseReport(id int, status entities.ReportStatus, entry entities.AuditEntry) (entities.Report, error) {
+	var closed entities.Report
+
+	err := p.db.Tx(func(tx *sqlite.Tx) error {
+		query := "UPDATE reports SET status = $2, resolved_by = (SELECT id FROM users WHERE username = $3), resolved_at = " + sqlNow + " " +
+			"WHERE id = $1 AND status = 'open' " +
+			"RETURNING message_id"
+
+		messages, err := returnedIDs(tx, query, id, string(status), entry.Moderator)
+		if err != nil {
+			return err
+		}
+
+		if len(messages) == 0 {
+			return entities.ErrReportNotFound
+		}
+
+		if err := tx.Insert(insertAuditEntry, entry.Moderator, string(entry.Action), entry.Reason, messages[0], id); err != nil {
+			return err
+		}
+
+		closed, err = getReport(tx, id)
+
+		return err
+	})
+	if err != nil {
+		return entities.Report{}, err
+	}
+
+	return closed, nil
+}
+
+// DeletePublicMessage removes the content and the attachments of the public
+// message, resolves its open reports and notifies the webhooks of the
+// deletion. The message stays listed as deleted, so the pages of the chat
+// do not shift. It returns the IDs of the attachments removed, whose files
+// are left to the caller.
+func (p *ModerationSqliteRepos) DeletePublicMessage(id int, entry entities.AuditEntry) ([]string, error) {
+	var attachments []string
+
+	err := p.db.Tx(func(tx *sqlite.Tx) error {
+		senders, err := returnedNames(tx, "SELECT u.username FROM global_chat gc JOIN users u ON u.id = gc.sender_id "+
+			"WHERE gc.id = $1 AND NOT gc.deleted", id)
+		if err != nil {
+			return err
+		}
+
+		if len(senders) == 0 {
+			return entities.ErrMessageNotFound
+		}
+
+		if err := tx.Insert("UPDATE global_chat SET message = '', deleted = TRUE WHERE id = $1", id); err != nil {
+			return err
+		}
+
+		attachments, err = returnedNames(tx, "DELETE FROM attachments WHERE global_message_id = $1 RETURNING id", id)
+		if err != nil {
+			return err
+		}
+
+		query := "UPDATE reports SET status = 'resolved', resolved_by = (SELECT id FROM users WHERE username = $2), " +
+			"resolved_at = " + sqlNow + " " +
+			"WHERE message_id = $1 AND status = 'open'"
+
+		if err := tx.Insert(query, id, entry.Moderator); err != nil {
+			return err
+		}
+
+		if err := tx.Insert(insertAuditEntry, entry.Moderator, string(entry.Action), entry.Reason, id, nil); err != nil {
+			return err
+		}
+
+		return enqueueWebhookEvent(tx, entities.EventMessageDeleted, entities.PublicChannel, id, senders[0], "", time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// SetSanction puts the sanction on the user, replacing the one of the same
+// kind.
+func (p *ModerationSqliteRepos) SetSanction(s entities.Sanction, entry entities.AuditEntry) error {
+	var until *time.Time
+	if !s.Until.IsZero() {
+		until = &s.Until
+	}
+
+	return p.db.Tx(func(tx *sqlite.Tx) error {
+		query := "INSERT INTO sanctions(user_id, kind, reason, until, created_by) " +
+			"SELECT id, $2, $3, $4, (SELECT id FROM users WHERE username = $5) FROM users WHERE username = $1 " +
+			"ON CONFLICT (user_id, kind) DO UPDATE " +
+			"SET reason = excluded.reason, until = excluded.until, created_by = excluded.created_by, created_at = " + sqlNow + " " +
+			"RETURNING user_id"
+
+		users, err := returnedIDs(tx, query, s.Username, string(s.Kind), s.Reason, until, entry.Moderator)
+		if err != nil {
+			return err
+		}
+
+		if len(users) == 0 {
+			return entities.ErrUserNotFound
+		}
+
+		query = "INSERT INTO moderation_log(moderator_id, action, target_id, reason, until) " +
+			"VALUES ((SELECT id FROM users WHERE username = $1), $2, $3, $4, $5)"
+
+		return tx.Insert(query, entry.Moderator, string(entry.Action), users[0], s.Reason, until)
+	})
+}
+
+func (p *ModerationSqliteRepos) LiftSanction(username string, kind entities.SanctionKind, entry entities.AuditEntry) error {
+	return p.db.Tx(func(tx *sqlite.Tx) error {
+		query := "DELETE FROM sanctions " +
+			"WHERE user_id = (SELECT id FROM users WHERE username = $1) AND kind = $2 " +
+			"AND (until IS NULL OR until > " + sqlNow + ") " +
+			"RETURNING user_id"
+
+		users, err := returnedIDs(tx, query, username, string(kind))
+		if err != nil {
+			return err
+		}
+
+		if len(users) == 0 {
+			return entities.ErrSanctionNotFound
+		}
+
+		query = "INSERT INTO moderation_log(moderator_id, action, target_id, reason) " +
+			"VALUES ((SELECT id FROM users WHERE username = $1), $2, $3, $4)"
+
+		return tx.Insert(query, entry.Moderator, string(entry.Action), users[0], entry.Reason)
+	})
+}
+
+// GetActiveSanctions returns the sanctions of the user in force at now.
+func (p *ModerationSqliteRepos) GetActiveSanctions(username string, now time.Time) ([]entities.Sanction, error) {
+	query := sanctionSelect +
+		"WHERE u.username = $1 AND (s.until IS NULL OR s.until > $2) " +
+		"ORDER BY s.created_at DESC, s.kind"
+
+	return listSanctions(p.db, query, username, now)
+}
+
+// GetSanctions returns the sanctions of all the users in force at now, the
+// latest first.
+func (p *ModerationSqliteRepos) GetSanctions(now time.Time) ([]entities.Sanction, error) {
+	query := sanctionSelect +
+		"WHERE s.until IS NULL OR s.until > $1 " +
+		"ORDER BY s.created_at DESC, u.username, s.kind"
+
+	return listSanctions(p.db, query, now)
+}
+
+// GetAuditLog returns the moderation actions, the latest first.
+func (p *ModerationSqliteRepos) GetAuditLog(limit, offset int) ([]entities.AuditEntry, error) {
+	query := "SELECT l.id, mu.username AS moderator, l.action, tu.username AS target, " +
+		"l.message_id, l.report_id, l.reason, l.until, l.created_at " +
+		"FROM moderation_log l " +
+		"JOIN users mu ON mu.id = l.moderator_id " +
+		"LEFT JOIN users tu ON tu.id = l.target_id " +
+		"ORDER BY l.id DESC " +
+		"LIMIT $1 OFFSET $2"
+
+	rows, err := p.db.Get(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]entities.AuditEntry, 0)
+	for rows.Next() {
+		var model models.AuditEntryModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, mapper.AuditEntryModelToEntity(model))
+	}
+
+	return entries, rows.Err()
+}
+
+func getReport(db querier, id int) (entities.Report, error) {
+	reports, err := listReports(db, reportSelect+"WHERE r.id = $1", id)
+	if err != nil {
+		return entities.Report{}, err
+	}
+
+	if len(reports) == 0 {
+		return entities.Report{}, entities.ErrReportNotFound
+	}
+
+	return reports[0], nil
+}
+
+func listReports(db querier, query string, args ...interface{}) ([]entities.Report, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]entities.Report, 0)
+	for rows.Next() {
+		var model models.ReportModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, mapper.ReportModelToEntity(model))
+	}
+
+	return reports, rows.Err()
+}
+
+func listSanctions(db querier, query string, args ...interface{}) ([]entities.Sanction, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sanctions := make([]entities.Sanction, 0)
+	for rows.Next() {
+		var model models.SanctionModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		sanctions = append(sanctions, mapper.SanctionModelToEntity(model))
+	}
+
+	return sanctions, rows.Err()
+}
+
+// returnedNames runs the query and reads the names it returns.
+func returnedNames(db querier, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
@@ -0,0 +1,97 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+)
+
+type NotificationSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+type NotificationSqliteRepos struct {
+	db NotificationSqliteDB
+}
+
+func NewNotificationSqliteRepos(db NotificationSqliteDB) *NotificationSqliteRepos {
+	return &NotificationSqliteRepos{db: db}
+}
+
+// GetNotifications returns the notifications of the user, newest first.
+func (n *NotificationSqliteRepos) GetNotifications(user string, unreadOnly bool, limit, offset int) ([]entities.Notification, error) {
+	query := "SELECT n.id, n.kind, su.username AS sender, " +
+		"COALESCE(n.global_message_id, n.private_message_id) AS message_id, " +
+		"COALESCE(gc.message, CASE WHEN pc.ttl_seconds IS NULL THEN pc.message ELSE '' END) AS message, " +
+		"n.read_at IS NOT NULL AS read, n.created_at " +
+		"FROM notifications n " +
+		"JOIN users u ON u.id = n.user_id " +
+		"JOIN users su ON su.id = n.sender_id " +
+		"LEFT JOIN global_chat gc ON gc.id = n.global_message_id " +
+		"LEFT JOIN private_chats pc ON pc.id = n.private_message_id " +
+		"WHERE u.username = $1 AND (NOT $2 OR n.read_at IS NULL) " +
+		"ORDER BY n.id DESC LIMIT $3 OFFSET $4"
+
+	rows, err := n.db.Get(query, user, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]models.NotificationModel, 0)
+	for rows.Next() {
+		var model models.NotificationModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, model)
+	}
+
+	return mapper.NotificationModelsToEntities(notifications), rows.Err()
+}
+
+func (n *NotificationSqliteRepos) CountUnread(user string) (int, error) {
+	query := "SELECT COUNT(*) AS count FROM notifications n " +
+		"JOIN users u ON u.id = n.user_id " +
+		"WHERE u.username = $1 AND n.read_at IS NULL"
+
+	return n.count(query, user)
+}
+
+// MarkNotificationsRead marks the given notifications of the user as read,
+// or all of them when ids is empty, and returns how many were unread.
+func (n *NotificationSqliteRepos) MarkNotificationsRead(user string, ids []int) (int, error) {
+	query := "UPDATE notifications SET read_at = " + sqlNow + " " +
+		"WHERE user_id = (SELECT id FROM users WHERE username = $1) AND read_at IS NULL " +
+		"AND (json_array_length($2) = 0 OR id IN (SELECT value FROM json_each($2))) " +
+		"RETURNING id"
+
+	read, err := returnedIDs(n.db, query, user, jsonArray(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(read), nil
+}
+
+func (n *NotificationSqliteRepos) count(query string, args ...interface{}) (int, error) {
+	rows, err := n.db.Get(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var model models.CountModel
+	for rows.Next() {
+		err := rows.StructScan(&model)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return model.Count, rows.Err()
+}
@@ -0,0 +1,13 @@
+package repos
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+// orderMessages returns the ORDER BY clause of the messages of alias in the
+// given order, the oldest first unless told otherwise.
+func orderMessages(alias string, order entities.Order) string {
+	if order == entities.OrderNewestFirst {
+		return "ORDER BY " + alias + ".id DESC "
+	}
+
+	return "ORDER BY " + alias + ".id "
+}
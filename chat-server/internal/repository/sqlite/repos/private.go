@@ -0,0 +1,407 @@
+package repos
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+	"time"
+)
+
+var (
+	ErrUserIsNotExists = errors.New("this user is not exist")
+	ErrChatIsNotExists = errors.New("no chat with this user")
+)
+
+// upsertConversation moves the conversation of the user $1 with $2 up to the
+// message $3 sent at $4. $5 tells whether the message is a request; the
+// conversation stays one only while all its messages are.
+const upsertConversation = "INSERT INTO conversations(user_id, partner_id, last_message_id, last_message_at, is_request) " +
+	"VALUES ($1, $2, $3, $4, $5) " +
+	"ON CONFLICT (user_id, partner_id) DO UPDATE " +
+	"SET last_message_id = excluded.last_message_id, last_message_at = excluded.last_message_at, " +
+	"is_request = conversations.is_request AND excluded.is_request"
+
+// privateExpired tells whether the content of the message is gone: purged
+// by the reaper or past its expiry and waiting for it.
+func privateExpired(alias string) string {
+	return "(" + alias + ".expired OR COALESCE(" + alias + ".expires_at <= " + sqlNow + ", FALSE))"
+}
+
+// privateContent selects the content of the message, empty once it has
+// expired, with its expiry.
+func privateContent(alias string) string {
+	expired := privateExpired(alias)
+
+	return "CASE WHEN " + expired + " THEN '' ELSE " + alias + ".message END AS message, " +
+		expired + " AS expired, " + alias + ".expires_at"
+}
+
+type PrivateSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+type PrivateSqliteRepos struct {
+	db PrivateSqliteDB
+}
+
+func NewPrivateSqliteRepos(db PrivateSqliteDB) *PrivateSqliteRepos {
+	return &PrivateSqliteRepos{db: db}
+}
+
+// InsertMessage saves the message with its attachments and the notification
+// of the recipient, and moves the conversation up the inboxes of both, in
+// one transaction. The messages of a stranger wait in the message requests
+// of the recipient until they accept them or reply, which clears is_request.
+func (p *PrivateSqliteRepos) InsertMessage(m entities.Message) error {
+	return p.db.Tx(func(tx *sqlite.Tx) error {
+		query := "INSERT INTO private_chats(sender_id, recipient_id, message, ttl_seconds, expire_after_read, expires_at) " +
+			"VALUES ((SELECT id FROM users WHERE username = $1), (SELECT id FROM users WHERE username = $2), $3, " +
+			"NULLIF($4, 0), $5, CASE WHEN $4 > 0 AND NOT $5 THEN " + later(sqlNow, "$4") + " END) " +
+			"RETURNING id, sender_id, recipient_id, created_at"
+
+		var inserted insertedMessage
+		err := insertMessage(tx, &inserted, query, m.Sender, m.Recipient, m.Content, int(m.TTL/time.Second), m.ExpireAfterRead)
+		if err != nil {
+			return err
+		}
+
+		if inserted.SenderID == nil || inserted.RecipientID == nil {
+			return ErrUserIsNotExists
+		}
+
+		sender, recipient := *inserted.SenderID, *inserted.RecipientID
+
+		if err := insertAttachments(tx, privateMessageColumn, inserted.ID, m.Attachments); err != nil {
+			return err
+		}
+
+		query = "INSERT INTO contacts(user_id, contact_id) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+		if err := tx.Insert(query, sender, recipient); err != nil {
+			return err
+		}
+
+		if err := tx.Insert(upsertConversation, sender, recipient, inserted.ID, inserted.CreatedAt, false); err != nil {
+			return err
+		}
+
+		if sender == recipient {
+			return nil
+		}
+
+		query = "INSERT INTO notifications(user_id, kind, sender_id, private_message_id) VALUES ($1, $2, $3, $4)"
+		if err := tx.Insert(query, recipient, string(entities.NotificationPrivateMessage), sender, inserted.ID); err != nil {
+			return err
+		}
+
+		isContact, err := exists(tx, "SELECT EXISTS (SELECT 1 FROM contacts WHERE user_id = $1 AND contact_id = $2)", recipient, sender)
+		if err != nil {
+			return err
+		}
+
+		return tx.Insert(upsertConversation, recipient, sender, inserted.ID, inserted.CreatedAt, !isContact)
+	})
+}
+
+func (p *PrivateSqliteRepos) GetMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error) {
+	// The conversation is looked up by the pair of its members in either
+	// order, the way private_chats_pair_idx keeps it.
+	query := "SELECT pc.id, su.username AS sender, ru.username AS recipient, " + privateContent("pc") + ", pc.created_at " +
+		"FROM (SELECT min(a.id, b.id) AS low, max(a.id, b.id) AS high " +
+		"FROM users a, users b WHERE a.username = $1 AND b.username = $2) pair " +
+		"JOIN private_chats pc ON min(pc.sender_id, pc.recipient_id) = pair.low " +
+		"AND max(pc.sender_id, pc.recipient_id) = pair.high " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		orderMessages("pc", order) +
+		"LIMIT $3 OFFSET $4"
+
+	rows, err := p.db.Get(query, sender, recipient, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chat := models.ChatModel{Messages: make([]models.MessageModel, 0)}
+	for rows.Next() {
+		var message models.MessageModel
+		err := rows.StructScan(&message)
+		if err != nil {
+			return nil, err
+		}
+
+		chat.Messages = append(chat.Messages, message)
+	}
+
+	if err := loadAttachments(p.db, privateMessageColumn, chat.Messages); err != nil {
+		return nil, err
+	}
+
+	return mapper.MessageModelToEntities(chat), nil
+}
+
+func (p *PrivateSqliteRepos) GetAttachment(id string) (entities.Attachment, entities.Message, error) {
+	query := "SELECT a.id, a.private_message_id AS message_id, a.file_name, a.content_type, a.size, a.created_at, " +
+		"su.username AS sender, ru.username AS recipient " +
+		"FROM attachments a " +
+		"JOIN private_chats pc ON pc.id = a.private_message_id " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		"WHERE a.id = $1 AND NOT " + privateExpired("pc")
+
+	return getAttachment(p.db, query, id)
+}
+
+func (p *PrivateSqliteRepos) GetUsers(user string) ([]string, error) {
+	var userList models.UserListModel
+	query := "SELECT pt.username " +
+		"FROM users u " +
+		"JOIN conversations c ON c.user_id = u.id AND c.is_request = FALSE " +
+		"JOIN users pt ON pt.id = c.partner_id " +
+		"WHERE u.username = $1 " +
+		"ORDER BY pt.username"
+
+	rows, err := p.db.Get(query, user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user string
+		err := rows.Scan(&user)
+		if err != nil {
+			return nil, err
+		}
+
+		userList.Usernames = append(userList.Usernames, user)
+	}
+
+	return userList.Usernames, rows.Err()
+}
+
+// MarkAsRead moves the read marker of reader in the conversation with
+// partner up to messageID, or to the latest message when it is 0. The
+// messages expiring after read start their countdown when the reader first
+// reads them.
+func (p *PrivateSqliteRepos) MarkAsRead(reader, partner string, messageID int) error {
+	return p.db.Tx(func(tx *sqlite.Tx) error {
+		query := "INSERT INTO private_chat_reads(reader_id, partner_id, last_read_message_id) " +
+			"SELECT r.id, pt.id, MAX(pc.id) " +
+			"FROM users r " +
+			"JOIN users pt ON pt.username = $2 " +
+			"JOIN private_chats pc ON (pc.sender_id = r.id AND pc.recipient_id = pt.id) " +
+			"OR (pc.sender_id = pt.id AND pc.recipient_id = r.id) " +
+			"WHERE r.username = $1 AND ($3 = 0 OR pc.id <= $3) " +
+			"GROUP BY r.id, pt.id " +
+			"ON CONFLICT (reader_id, partner_id) DO UPDATE " +
+			"SET last_read_message_id = MAX(private_chat_reads.last_read_message_id, excluded.last_read_message_id) " +
+			"RETURNING reader_id, partner_id, last_read_message_id"
+
+		rows, err := tx.Get(query, reader, partner, messageID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			return ErrChatIsNotExists
+		}
+
+		var readerID, partnerID, lastRead int
+		if err := rows.Scan(&readerID, &partnerID, &lastRead); err != nil {
+			return err
+		}
+		rows.Close()
+
+		query = "UPDATE private_chats SET expires_at = " + later(sqlNow, "ttl_seconds") + " " +
+			"WHERE sender_id = $1 AND recipient_id = $2 AND id <= $3 AND expire_after_read AND expires_at IS NULL"
+
+		return tx.Insert(query, partnerID, readerID, lastRead)
+	})
+}
+
+func (p *PrivateSqliteRepos) GetConversations(user string, partners []string) ([]entities.Conversation, error) {
+	query := "SELECT pt.username, " +
+		"(SELECT COUNT(*) FROM private_chats pc " +
+		"WHERE pc.sender_id = pt.id AND pc.recipient_id = u.id AND pc.id > COALESCE(r.last_read_message_id, 0)) AS unread_count, " +
+		"COALESCE((SELECT MAX(pc.id) FROM private_chats pc " +
+		"WHERE pc.sender_id = u.id AND pc.recipient_id = pt.id) <= pr.last_read_message_id, FALSE) AS seen_by_partner " +
+		"FROM users u " +
+		"JOIN users pt ON pt.username IN (SELECT value FROM json_each($2)) " +
+		"LEFT JOIN private_chat_reads r ON r.reader_id = u.id AND r.partner_id = pt.id " +
+		"LEFT JOIN private_chat_reads pr ON pr.reader_id = pt.id AND pr.partner_id = u.id " +
+		"WHERE u.username = $1"
+
+	rows, err := p.db.Get(query, user, jsonArray(partners))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byPartner := make(map[string]models.ConversationModel, len(partners))
+	for rows.Next() {
+		var conversation models.ConversationModel
+		err := rows.StructScan(&conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		byPartner[conversation.Partner] = conversation
+	}
+
+	return mapper.ConversationModelsToEntities(partners, byPartner), rows.Err()
+}
+
+func (p *PrivateSqliteRepos) GetInbox(user string, limit, offset int) ([]entities.Conversation, error) {
+	return p.listConversations(user, false, limit, offset)
+}
+
+// GetMessageRequests returns the conversations started by the strangers
+// that user has not accepted yet, the latest first.
+func (p *PrivateSqliteRepos) GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error) {
+	return p.listConversations(user, true, limit, offset)
+}
+
+// AcceptMessageRequest moves the conversation with partner to the inbox of
+// user and lets partner write to them as a contact.
+func (p *PrivateSqliteRepos) AcceptMessageRequest(user, partner string) error {
+	return p.db.Tx(func(tx *sqlite.Tx) error {
+		query := "UPDATE conversations SET is_request = FALSE " +
+			"WHERE user_id = (SELECT id FROM users WHERE username = $1) " +
+			"AND partner_id = (SELECT id FROM users WHERE username = $2) AND is_request " +
+			"RETURNING partner_id"
+
+		ids, err := returnedIDs(tx, query, user, partner)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return entities.ErrMessageRequestNotFound
+		}
+
+		query = "INSERT INTO contacts(user_id, contact_id) " +
+			"SELECT id, $2 FROM users WHERE username = $1 " +
+			"ON CONFLICT DO NOTHING"
+
+		return tx.Insert(query, user, ids[0])
+	})
+}
+
+// DeclineMessageRequest removes the request of partner. The messages stay,
+// and a new message of partner makes a new request.
+func (p *PrivateSqliteRepos) DeclineMessageRequest(user, partner string) error {
+	query := "DELETE FROM conversations " +
+		"WHERE user_id = (SELECT id FROM users WHERE username = $1) " +
+		"AND partner_id = (SELECT id FROM users WHERE username = $2) AND is_request " +
+		"RETURNING partner_id"
+
+	ids, err := returnedIDs(p.db, query, user, partner)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return entities.ErrMessageRequestNotFound
+	}
+
+	return nil
+}
+
+func (p *PrivateSqliteRepos) listConversations(user string, requests bool, limit, offset int) ([]entities.Conversation, error) {
+	query := "SELECT pt.username, " +
+		"(SELECT COUNT(*) FROM private_chats pc " +
+		"WHERE pc.sender_id = c.partner_id AND pc.recipient_id = c.user_id AND pc.id > COALESCE(r.last_read_message_id, 0)) AS unread_count, " +
+		"COALESCE((SELECT MAX(pc.id) FROM private_chats pc " +
+		"WHERE pc.sender_id = c.user_id AND pc.recipient_id = c.partner_id) <= pr.last_read_message_id, FALSE) AS seen_by_partner, " +
+		"m.id, su.username AS sender, ru.username AS recipient, " + privateContent("m") + ", m.created_at " +
+		"FROM conversations c " +
+		"JOIN users pt ON pt.id = c.partner_id " +
+		"JOIN private_chats m ON m.id = c.last_message_id " +
+		"JOIN users su ON su.id = m.sender_id " +
+		"JOIN users ru ON ru.id = m.recipient_id " +
+		"LEFT JOIN private_chat_reads r ON r.reader_id = c.user_id AND r.partner_id = c.partner_id " +
+		"LEFT JOIN private_chat_reads pr ON pr.reader_id = c.partner_id AND pr.partner_id = c.user_id " +
+		"WHERE c.user_id = (SELECT id FROM users WHERE username = $1) AND c.is_request = $4 " +
+		"ORDER BY c.last_message_at DESC, c.last_message_id DESC " +
+		"LIMIT $2 OFFSET $3"
+
+	rows, err := p.db.Get(query, user, limit, offset, requests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inbox := make([]models.InboxModel, 0)
+	for rows.Next() {
+		var conversation models.InboxModel
+		err := rows.StructScan(&conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		inbox = append(inbox, conversation)
+	}
+
+	return mapper.InboxModelsToEntities(inbox), rows.Err()
+}
+
+// SearchMessages looks for messages of the private conversations the user
+// takes part in.
+func (p *PrivateSqliteRepos) SearchMessages(user string, q entities.SearchQuery) ([]entities.SearchResult, error) {
+	filters, args := searchFilters(q, "private_chats", "pc", privateSearchChat, []interface{}{user})
+	args = append(args, q.Limit)
+
+	query := "SELECT pc.id, su.username AS sender, ru.username AS recipient, pc.message, pc.created_at " +
+		"FROM private_chats pc " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		"WHERE (su.username = $1 OR ru.username = $1) AND NOT " + privateExpired("pc") + " AND " + filters + " " +
+		fmt.Sprintf("ORDER BY pc.created_at DESC, pc.id DESC LIMIT $%d", len(args))
+
+	return searchMessages(p.db, query, privateSearchChat, args...)
+}
+
+// PurgeExpiredMessages replaces up to limit messages expired at now with
+// tombstones, the longest expired first, and returns how many it purged
+// together with the IDs of the attachments it removed, whose files are left
+// to the caller.
+func (p *PrivateSqliteRepos) PurgeExpiredMessages(now time.Time, limit int) (int, []string, error) {
+	var (
+		purged      int
+		attachments []string
+	)
+
+	err := p.db.Tx(func(tx *sqlite.Tx) error {
+		query := "UPDATE private_chats SET message = '', expired = TRUE " +
+			"WHERE id IN (SELECT id FROM private_chats WHERE expires_at <= $1 AND NOT expired " +
+			"ORDER BY expires_at, id LIMIT $2) " +
+			"RETURNING id"
+
+		ids, err := returnedIDs(tx, query, now, limit)
+		if err != nil {
+			return err
+		}
+
+		purged = len(ids)
+
+		attachments, err = returnedNames(tx, "DELETE FROM attachments WHERE private_message_id IN (SELECT value FROM json_each($1)) RETURNING id", jsonArray(ids))
+
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return purged, attachments, nil
+}
@@ -0,0 +1,133 @@
+package repos
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+	"time"
+)
+
+type PublicSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+type PublicSqliteRepos struct {
+	db PublicSqliteDB
+}
+
+func NewPublicSqliteRepos(db PublicSqliteDB) *PublicSqliteRepos {
+	return &PublicSqliteRepos{db: db}
+}
+
+// InsertMessage saves the message with its attachments, the notifications
+// of the mentioned users and the webhook event in one transaction.
+func (pub *PublicSqliteRepos) InsertMessage(m entities.Message) error {
+	return pub.db.Tx(func(tx *sqlite.Tx) error {
+		query := "INSERT INTO global_chat(sender_id, message) " +
+			"VALUES ((SELECT id FROM users WHERE username = $1), $2) " +
+			"RETURNING id, sender_id, created_at"
+
+		var inserted insertedMessage
+		if err := insertMessage(tx, &inserted, query, m.Sender, m.Content); err != nil {
+			return err
+		}
+
+		if err := insertAttachments(tx, globalMessageColumn, inserted.ID, m.Attachments); err != nil {
+			return err
+		}
+
+		if err := enqueueWebhookEvent(tx, entities.EventMessageCreated, entities.PublicChannel, inserted.ID, m.Sender, m.Content, inserted.CreatedAt); err != nil {
+			return err
+		}
+
+		query = "INSERT INTO notifications(user_id, kind, sender_id, global_message_id) " +
+			"SELECT id, $1, $2, $3 FROM users WHERE username IN (SELECT value FROM json_each($4))"
+
+		return tx.Insert(query, string(entities.NotificationMention), inserted.SenderID, inserted.ID, jsonArray(m.Mentions))
+	})
+}
+
+func (pub *PublicSqliteRepos) GetMessages(limit, offset int, order entities.Order) ([]entities.Message, error) {
+	query := "SELECT gc.id, u.username AS sender, '' AS recipient, gc.message, gc.created_at, gc.deleted " +
+		"FROM global_chat gc " +
+		"JOIN users u ON u.id = gc.sender_id " +
+		orderMessages("gc", order) +
+		"LIMIT $1 OFFSET $2"
+
+	rows, err := pub.db.Get(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chat := models.ChatModel{Messages: make([]models.MessageModel, 0)}
+
+	for rows.Next() {
+		var message models.MessageModel
+		err := rows.StructScan(&message)
+		if err != nil {
+			return nil, err
+		}
+
+		chat.Messages = append(chat.Messages, message)
+	}
+
+	if err := loadAttachments(pub.db, globalMessageColumn, chat.Messages); err != nil {
+		return nil, err
+	}
+
+	return mapper.MessageModelToEntities(chat), nil
+}
+
+func (pub *PublicSqliteRepos) GetAttachment(id string) (entities.Attachment, entities.Message, error) {
+	query := "SELECT a.id, a.global_message_id AS message_id, a.file_name, a.content_type, a.size, a.created_at, " +
+		"u.username AS sender, '' AS recipient " +
+		"FROM attachments a " +
+		"JOIN global_chat gc ON gc.id = a.global_message_id " +
+		"JOIN users u ON u.id = gc.sender_id " +
+		"WHERE a.id = $1"
+
+	return getAttachment(pub.db, query, id)
+}
+
+func (pub *PublicSqliteRepos) SearchMessages(q entities.SearchQuery) ([]entities.SearchResult, error) {
+	filters, args := searchFilters(q, "global_chat", "gc", publicSearchChat, nil)
+	args = append(args, q.Limit)
+
+	query := "SELECT gc.id, su.username AS sender, '' AS recipient, gc.message, gc.created_at " +
+		"FROM global_chat gc " +
+		"JOIN users su ON su.id = gc.sender_id " +
+		"WHERE " + filters + " " +
+		fmt.Sprintf("ORDER BY gc.created_at DESC, gc.id DESC LIMIT $%d", len(args))
+
+	return searchMessages(pub.db, query, publicSearchChat, args...)
+}
+
+// insertedMessage is the row of a message just inserted.
+type insertedMessage struct {
+	ID          int       `db:"id"`
+	SenderID    *int      `db:"sender_id"`
+	RecipientID *int      `db:"recipient_id"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// insertMessage runs the insert, which returns the columns of
+// insertedMessage.
+func insertMessage(tx *sqlite.Tx, m *insertedMessage, query string, args ...interface{}) error {
+	rows, err := tx.Get(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return rows.Err()
+	}
+
+	return rows.StructScan(m)
+}
@@ -0,0 +1,179 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+)
+
+// reminderSelect reads the reminders rem with the names of their senders.
+const reminderSelect = "SELECT rem.id, rem.name, rem.content, rem.schedule, rem.time_zone, su.username AS sender, " +
+	"rem.paused, rem.next_run_at, rem.last_run_at, rem.created_by, rem.created_at " +
+	"FROM reminders rem JOIN users su ON su.id = rem.sender_id "
+
+type ReminderSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+type ReminderSqliteRepos struct {
+	db ReminderSqliteDB
+}
+
+func NewReminderSqliteRepos(db ReminderSqliteDB) *ReminderSqliteRepos {
+	return &ReminderSqliteRepos{db: db}
+}
+
+// InsertReminder saves the reminder and registers its sender as a bot with
+// the given password, unless the bot exists already.
+func (r *ReminderSqliteRepos) InsertReminder(rem entities.Reminder, senderPassword string) (entities.Reminder, error) {
+	var reminder entities.Reminder
+
+	err := r.db.Tx(func(tx *sqlite.Tx) error {
+		botID, err := ensureBot(tx, rem.Sender, senderPassword)
+		if err != nil {
+			return err
+		}
+
+		query := "INSERT INTO reminders(name, content, schedule, time_zone, sender_id, next_run_at, created_by) " +
+			"VALUES ($1, $2, $3, $4, $5, $6, $7) " +
+			"RETURNING id"
+
+		ids, err := returnedIDs(tx, query, rem.Name, rem.Content, rem.Schedule, rem.TimeZone, botID, rem.NextRunAt, rem.CreatedBy)
+		if err != nil {
+			return err
+		}
+
+		reminder, err = getReminder(tx, ids)
+
+		return err
+	})
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	return reminder, nil
+}
+
+func (r *ReminderSqliteRepos) GetReminders() ([]entities.Reminder, error) {
+	return listReminders(r.db, reminderSelect+"ORDER BY rem.id")
+}
+
+func (r *ReminderSqliteRepos) GetReminder(id int) (entities.Reminder, error) {
+	return getReminder(r.db, []int{id})
+}
+
+func (r *ReminderSqliteRepos) PauseReminder(id int) (entities.Reminder, error) {
+	query := "UPDATE reminders SET paused = TRUE, updated_at = " + sqlNow + " WHERE id = $1 RETURNING id"
+
+	return r.change(query, id)
+}
+
+// ResumeReminder turns the reminder back on from nextRunAt, so the runs
+// missed while it was paused are not posted.
+func (r *ReminderSqliteRepos) ResumeReminder(id int, nextRunAt time.Time) (entities.Reminder, error) {
+	query := "UPDATE reminders SET paused = FALSE, next_run_at = $2, updated_at = " + sqlNow + " WHERE id = $1 RETURNING id"
+
+	return r.change(query, id, nextRunAt)
+}
+
+func (r *ReminderSqliteRepos) DeleteReminder(id int) error {
+	ids, err := returnedIDs(r.db, "DELETE FROM reminders WHERE id = $1 RETURNING id", id)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return entities.ErrReminderNotFound
+	}
+
+	return nil
+}
+
+// GetDueReminders returns up to limit active reminders due at now, the
+// longest overdue first.
+func (r *ReminderSqliteRepos) GetDueReminders(now time.Time, limit int) ([]entities.Reminder, error) {
+	query := reminderSelect +
+		"WHERE NOT rem.paused AND rem.next_run_at <= $1 " +
+		"ORDER BY rem.next_run_at, rem.id LIMIT $2"
+
+	return listReminders(r.db, query, now, limit)
+}
+
+// AdvanceReminder moves the reminder from the run due at to the next one,
+// if it is still due at that time and active. The update is conditional,
+// so of several servers running the same reminder only one advances it and
+// posts the run.
+func (r *ReminderSqliteRepos) AdvanceReminder(id int, due, next time.Time, posted bool) (bool, error) {
+	query := "UPDATE reminders SET next_run_at = $3, " +
+		"last_run_at = CASE WHEN $4 THEN $2 ELSE last_run_at END, updated_at = " + sqlNow + " " +
+		"WHERE id = $1 AND next_run_at = $2 AND NOT paused RETURNING id"
+
+	ids, err := returnedIDs(r.db, query, id, due, next, posted)
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
+}
+
+// change runs the statement, which returns the ID of the reminder it
+// changes, and reads the reminder in the same transaction.
+func (r *ReminderSqliteRepos) change(query string, args ...interface{}) (entities.Reminder, error) {
+	var reminder entities.Reminder
+
+	err := r.db.Tx(func(tx *sqlite.Tx) error {
+		ids, err := returnedIDs(tx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		reminder, err = getReminder(tx, ids)
+
+		return err
+	})
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	return reminder, nil
+}
+
+func getReminder(db querier, ids []int) (entities.Reminder, error) {
+	reminders, err := listReminders(db, reminderSelect+"WHERE rem.id IN (SELECT value FROM json_each($1))", jsonArray(ids))
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	if len(reminders) == 0 {
+		return entities.Reminder{}, entities.ErrReminderNotFound
+	}
+
+	return reminders[0], nil
+}
+
+func listReminders(db querier, query string, args ...interface{}) ([]entities.Reminder, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := make([]entities.Reminder, 0)
+	for rows.Next() {
+		var model models.ReminderModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		reminders = append(reminders, mapper.ReminderModelToEntity(model))
+	}
+
+	return reminders, rows.Err()
+}
@@ -0,0 +1,257 @@
+package repos
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+	"strings"
+	"time"
+)
+
+// notHeld tells that none of the users is on legal hold.
+func notHeld(users ...string) string {
+	return "NOT EXISTS (SELECT 1 FROM legal_holds h WHERE h.user_id IN (" + strings.Join(users, ", ") + "))"
+}
+
+type RetentionSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+// RetentionSqliteRepos removes the messages older than the retention
+// policies allow, a batch in a transaction. The attachments, notifications
+// and reports of the removed messages go with them by the cascade, their
+// words by the triggers of the search indexes.
+type RetentionSqliteRepos struct {
+	db RetentionSqliteDB
+}
+
+func NewRetentionSqliteRepos(db RetentionSqliteDB) *RetentionSqliteRepos {
+	return &RetentionSqliteRepos{db: db}
+}
+
+// GetPublicMessagesBefore returns up to limit public messages sent before
+// the time by users not on hold, the oldest first.
+func (r *RetentionSqliteRepos) GetPublicMessagesBefore(before time.Time, limit int) ([]entities.Message, error) {
+	query := "SELECT gc.id, u.username AS sender, '' AS recipient, gc.message, gc.created_at, gc.deleted " +
+		"FROM global_chat gc " +
+		"JOIN users u ON u.id = gc.sender_id " +
+		"WHERE gc.created_at < $1 AND " + notHeld("gc.sender_id") + " " +
+		"ORDER BY gc.created_at, gc.id " +
+		"LIMIT $2"
+
+	return r.messages(globalMessageColumn, query, before, limit)
+}
+
+// DeletePublicMessages removes the public messages with the IDs which are
+// still sent before the time by users not on hold, and returns how many it
+// removed and the IDs of their attachments.
+func (r *RetentionSqliteRepos) DeletePublicMessages(ids []int, before time.Time) (int, []string, error) {
+	var (
+		removed     int
+		attachments []string
+	)
+
+	err := r.db.Tx(func(tx *sqlite.Tx) error {
+		query := "SELECT gc.id FROM global_chat gc " +
+			"WHERE gc.id IN (SELECT value FROM json_each($1)) AND gc.created_at < $2 AND " + notHeld("gc.sender_id")
+
+		due, err := returnedIDs(tx, query, jsonArray(ids), before)
+		if err != nil {
+			return err
+		}
+
+		removed = len(due)
+		if removed == 0 {
+			return nil
+		}
+
+		attachments, err = returnedNames(tx, "DELETE FROM attachments WHERE global_message_id IN (SELECT value FROM json_each($1)) RETURNING id", jsonArray(due))
+		if err != nil {
+			return err
+		}
+
+		return tx.Insert("DELETE FROM global_chat WHERE id IN (SELECT value FROM json_each($1))", jsonArray(due))
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return removed, attachments, nil
+}
+
+// GetPrivateMessagesBefore returns up to limit private messages sent
+// before the time in the conversations of users not on hold, the oldest
+// first.
+func (r *RetentionSqliteRepos) GetPrivateMessagesBefore(before time.Time, limit int) ([]entities.Message, error) {
+	query := "SELECT pc.id, su.username AS sender, ru.username AS recipient, " + privateContent("pc") + ", pc.created_at " +
+		"FROM private_chats pc " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		"WHERE pc.created_at < $1 AND " + notHeld("pc.sender_id", "pc.recipient_id") + " " +
+		"ORDER BY pc.created_at, pc.id " +
+		"LIMIT $2"
+
+	return r.messages(privateMessageColumn, query, before, limit)
+}
+
+// DeletePrivateMessages removes the private messages which are still sent
+// before the time in the conversations of users not on hold, and returns
+// how many it removed and the IDs of their attachments. A conversation whose
+// last message is removed moves to the latest message kept, and leaves the
+// inboxes when none is; its rows change before the messages go, as they
+// still refer to them.
+func (r *RetentionSqliteRepos) DeletePrivateMessages(messages []entities.Message, before time.Time) (int, []string, error) {
+	ids := make([]int, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+
+	var (
+		removed     int
+		attachments []string
+	)
+
+	err := r.db.Tx(func(tx *sqlite.Tx) error {
+		query := "SELECT pc.id FROM private_chats pc " +
+			"WHERE pc.id IN (SELECT value FROM json_each($1)) AND pc.created_at < $2 AND " +
+			notHeld("pc.sender_id", "pc.recipient_id")
+
+		due, err := returnedIDs(tx, query, jsonArray(ids), before)
+		if err != nil {
+			return err
+		}
+
+		removed = len(due)
+		if removed == 0 {
+			return nil
+		}
+
+		attachments, err = returnedNames(tx, "DELETE FROM attachments WHERE private_message_id IN (SELECT value FROM json_each($1)) RETURNING id", jsonArray(due))
+		if err != nil {
+			return err
+		}
+
+		// The latest message of the pair the conversation is between which
+		// is kept.
+		latest := "FROM private_chats pc " +
+			"WHERE MIN(pc.sender_id, pc.recipient_id) = MIN(conversations.user_id, conversations.partner_id) " +
+			"AND MAX(pc.sender_id, pc.recipient_id) = MAX(conversations.user_id, conversations.partner_id) " +
+			"AND pc.id NOT IN (SELECT value FROM json_each($1))"
+
+		err = tx.Insert("DELETE FROM conversations "+
+			"WHERE last_message_id IN (SELECT value FROM json_each($1)) AND NOT EXISTS (SELECT 1 "+latest+")", jsonArray(due))
+		if err != nil {
+			return err
+		}
+
+		err = tx.Insert("UPDATE conversations "+
+			"SET (last_message_id, last_message_at) = (SELECT pc.id, pc.created_at "+latest+" ORDER BY pc.id DESC LIMIT 1) "+
+			"WHERE last_message_id IN (SELECT value FROM json_each($1))", jsonArray(due))
+		if err != nil {
+			return err
+		}
+
+		return tx.Insert("DELETE FROM private_chats WHERE id IN (SELECT value FROM json_each($1))", jsonArray(due))
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return removed, attachments, nil
+}
+
+// PlaceLegalHold puts the user on hold. A user already on hold keeps the
+// time the hold was first placed.
+func (r *RetentionSqliteRepos) PlaceLegalHold(username string) error {
+	return r.db.Tx(func(tx *sqlite.Tx) error {
+		found, err := exists(tx, "SELECT EXISTS (SELECT 1 FROM users WHERE username = $1)", username)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return entities.ErrUserNotFound
+		}
+
+		query := "INSERT INTO legal_holds(user_id) " +
+			"SELECT id FROM users WHERE username = $1 " +
+			"ON CONFLICT DO NOTHING"
+
+		return tx.Insert(query, username)
+	})
+}
+
+func (r *RetentionSqliteRepos) LiftLegalHold(username string) error {
+	query := "DELETE FROM legal_holds " +
+		"WHERE user_id = (SELECT id FROM users WHERE username = $1) " +
+		"RETURNING user_id"
+
+	lifted, err := returnedIDs(r.db, query, username)
+	if err != nil {
+		return err
+	}
+
+	if len(lifted) == 0 {
+		return entities.ErrLegalHoldNotFound
+	}
+
+	return nil
+}
+
+// GetLegalHolds returns the users on hold in the order of their names.
+func (r *RetentionSqliteRepos) GetLegalHolds() ([]entities.LegalHold, error) {
+	query := "SELECT u.username, h.placed_at " +
+		"FROM legal_holds h " +
+		"JOIN users u ON u.id = h.user_id " +
+		"ORDER BY u.username"
+
+	rows, err := r.db.Get(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := make([]entities.LegalHold, 0)
+	for rows.Next() {
+		var hold models.LegalHoldModel
+		if err := rows.StructScan(&hold); err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, mapper.LegalHoldModelToEntity(hold))
+	}
+
+	return holds, rows.Err()
+}
+
+func (r *RetentionSqliteRepos) messages(column, query string, args ...interface{}) ([]entities.Message, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chat := models.ChatModel{Messages: make([]models.MessageModel, 0)}
+	for rows.Next() {
+		var message models.MessageModel
+		if err := rows.StructScan(&message); err != nil {
+			return nil, err
+		}
+
+		chat.Messages = append(chat.Messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadAttachments(r.db, column, chat.Messages); err != nil {
+		return nil, err
+	}
+
+	return mapper.MessageModelToEntities(chat), nil
+}
@@ -0,0 +1,141 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+)
+
+// scheduledSelect reads the scheduled messages s with the names of their
+// users.
+const scheduledSelect = "SELECT s.id, s.channel, su.username AS sender, COALESCE(ru.username, '') AS recipient, " +
+	"s.message, s.send_at, s.status, s.last_error, s.created_at " +
+	"FROM scheduled_messages s JOIN users su ON su.id = s.sender_id LEFT JOIN users ru ON ru.id = s.recipient_id "
+
+type ScheduledMessageSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+type ScheduledMessageSqliteRepos struct {
+	db ScheduledMessageSqliteDB
+}
+
+func NewScheduledMessageSqliteRepos(db ScheduledMessageSqliteDB) *ScheduledMessageSqliteRepos {
+	return &ScheduledMessageSqliteRepos{db: db}
+}
+
+func (r *ScheduledMessageSqliteRepos) InsertScheduledMessage(m entities.ScheduledMessage) (entities.ScheduledMessage, error) {
+	query := "INSERT INTO scheduled_messages(channel, sender_id, recipient_id, message, send_at) " +
+		"VALUES ($1, (SELECT id FROM users WHERE username = $2), (SELECT id FROM users WHERE username = NULLIF($3, '')), $4, $5) " +
+		"RETURNING id"
+
+	messages, err := r.change(query, m.Channel, m.Sender, m.Recipient, m.Content, m.SendAt)
+	if err != nil {
+		return entities.ScheduledMessage{}, err
+	}
+
+	if len(messages) == 0 {
+		return entities.ScheduledMessage{}, entities.ErrScheduledMessageNotFound
+	}
+
+	return messages[0], nil
+}
+
+// GetScheduledMessages returns the messages of the sender which are not
+// sent yet, the next to go first.
+func (r *ScheduledMessageSqliteRepos) GetScheduledMessages(sender string, limit, offset int) ([]entities.ScheduledMessage, error) {
+	query := scheduledSelect +
+		"WHERE su.username = $1 AND s.status <> 'sent' " +
+		"ORDER BY s.send_at, s.id LIMIT $2 OFFSET $3"
+
+	return listScheduled(r.db, query, sender, limit, offset)
+}
+
+// DeleteScheduledMessage cancels a pending message or dismisses a failed one.
+// Messages which are being sent or were sent are not found.
+func (r *ScheduledMessageSqliteRepos) DeleteScheduledMessage(sender string, id int) error {
+	query := "DELETE FROM scheduled_messages " +
+		"WHERE id = $2 AND sender_id = (SELECT id FROM users WHERE username = $1) AND status IN ('pending', 'failed') " +
+		"RETURNING id"
+
+	ids, err := returnedIDs(r.db, query, sender, id)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return entities.ErrScheduledMessageNotFound
+	}
+
+	return nil
+}
+
+// ClaimScheduledMessages marks up to limit due messages as being sent and
+// returns them. A claimed message is never returned again, and the claim
+// takes the write lock of the database, so every message is sent at most
+// once.
+func (r *ScheduledMessageSqliteRepos) ClaimScheduledMessages(now time.Time, limit int) ([]entities.ScheduledMessage, error) {
+	query := "UPDATE scheduled_messages SET status = 'sending', updated_at = " + sqlNow + " " +
+		"WHERE id IN (SELECT id FROM scheduled_messages " +
+		"WHERE status = 'pending' AND send_at <= $1 " +
+		"ORDER BY send_at, id LIMIT $2) " +
+		"RETURNING id"
+
+	return r.change(query, now, limit)
+}
+
+func (r *ScheduledMessageSqliteRepos) FinishScheduledMessage(id int, status entities.ScheduledStatus, lastError string) error {
+	query := "UPDATE scheduled_messages SET status = $2, last_error = $3, updated_at = " + sqlNow + " WHERE id = $1"
+
+	return r.db.Insert(query, id, string(status), lastError)
+}
+
+// change runs the statement, which returns the IDs of the messages it
+// changes, and reads the messages in the same transaction.
+func (r *ScheduledMessageSqliteRepos) change(query string, args ...interface{}) ([]entities.ScheduledMessage, error) {
+	var messages []entities.ScheduledMessage
+
+	err := r.db.Tx(func(tx *sqlite.Tx) error {
+		ids, err := returnedIDs(tx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		messages, err = listScheduled(tx, scheduledSelect+"WHERE s.id IN (SELECT value FROM json_each($1)) "+
+			"ORDER BY s.send_at, s.id", jsonArray(ids))
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func listScheduled(db querier, query string, args ...interface{}) ([]entities.ScheduledMessage, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]entities.ScheduledMessage, 0)
+	for rows.Next() {
+		var model models.ScheduledMessageModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, mapper.ScheduledMessageModelToEntity(model))
+	}
+
+	return messages, rows.Err()
+}
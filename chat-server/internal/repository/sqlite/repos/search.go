@@ -0,0 +1,90 @@
+package repos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/pkg/textsearch"
+)
+
+const (
+	publicSearchChat  = "public"
+	privateSearchChat = "private"
+)
+
+// ftsQuery turns the parsed terms into an FTS5 query of phrases, all of
+// which have to match. Tokenize leaves only letters and digits, so the words
+// need no escaping.
+func ftsQuery(terms []textsearch.Term) string {
+	parts := make([]string, 0, len(terms))
+
+	for _, term := range terms {
+		phrase := `"` + strings.Join(term.Words, " ") + `"`
+		if term.Prefix {
+			phrase += "*"
+		}
+
+		parts = append(parts, phrase)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// searchFilters builds the conditions shared by the public and the private
+// search, numbering the placeholders after the arguments already in args.
+// The table is searched through its index, named after it.
+func searchFilters(q entities.SearchQuery, table, alias, chat string, args []interface{}) (string, []interface{}) {
+	conds := []string{fmt.Sprintf("%[2]s.id IN (SELECT rowid FROM %[1]s_search WHERE %[1]s_search MATCH $%[3]d)",
+		table, alias, len(args)+1)}
+	args = append(args, ftsQuery(q.Terms))
+
+	if q.Sender != "" {
+		args = append(args, q.Sender)
+		conds = append(conds, fmt.Sprintf("su.username = $%d", len(args)))
+	}
+
+	if !q.From.IsZero() {
+		args = append(args, q.From)
+		conds = append(conds, fmt.Sprintf("%s.created_at >= $%d", alias, len(args)))
+	}
+
+	if !q.To.IsZero() {
+		args = append(args, q.To)
+		conds = append(conds, fmt.Sprintf("%s.created_at < $%d", alias, len(args)))
+	}
+
+	if q.After != nil {
+		args = append(args, q.After.CreatedAt, q.After.Chat, q.After.ID)
+		conds = append(conds, fmt.Sprintf("(%s.created_at, '%s', %s.id) < ($%d, $%d, $%d)",
+			alias, chat, alias, len(args)-2, len(args)-1, len(args)))
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+func searchMessages(db querier, query, chat string, args ...interface{}) ([]entities.SearchResult, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]entities.SearchResult, 0)
+	for rows.Next() {
+		var message models.MessageModel
+		err := rows.StructScan(&message)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, entities.SearchResult{
+			Message: mapper.MessageModelToEntity(message),
+			Cursor:  entities.SearchCursor{CreatedAt: message.CreatedAt, Chat: chat, ID: message.ID},
+		})
+	}
+
+	return results, nil
+}
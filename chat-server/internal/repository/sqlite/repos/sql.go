@@ -0,0 +1,34 @@
+package repos
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+)
+
+// sqlNow is the current time in the format the times are stored in, the
+// now() of Postgres.
+const sqlNow = "strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')"
+
+// querier runs the queries of the helpers shared by the repositories, on
+// the database or in a transaction.
+type querier interface {
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// later is the time the given seconds after at, in the same format.
+func later(at, seconds string) string {
+	return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:%%M:%%f+00:00', %s, '+' || %s || ' seconds')", at, seconds)
+}
+
+// jsonArray encodes the values as a JSON array to be read with json_each,
+// which stands in for the arrays of Postgres.
+func jsonArray[T any](values []T) string {
+	if len(values) == 0 {
+		return "[]"
+	}
+
+	b, _ := json.Marshal(values)
+
+	return string(b)
+}
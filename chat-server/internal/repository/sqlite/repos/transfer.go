@@ -0,0 +1,229 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+)
+
+type TransferSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+// TransferSqliteRepos reads the data of the database page by page for an
+// export, and writes an export back, a batch in a transaction. The imported
+// messages keep the time they were sent at, and nobody is notified of them.
+type TransferSqliteRepos struct {
+	db TransferSqliteDB
+}
+
+func NewTransferSqliteRepos(db TransferSqliteDB) *TransferSqliteRepos {
+	return &TransferSqliteRepos{db: db}
+}
+
+// GetUsers returns up to limit users whose names go after the given one, in
+// the order of their names, with the hashes of their passwords.
+func (r *TransferSqliteRepos) GetUsers(after string, limit int) ([]entities.User, error) {
+	query := "SELECT username, password_hash FROM users WHERE username > $1 ORDER BY username LIMIT $2"
+
+	rows, err := r.db.Get(query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]entities.User, 0)
+	for rows.Next() {
+		var user models.UserModel
+		if err := rows.StructScan(&user); err != nil {
+			return nil, err
+		}
+
+		users = append(users, entities.User{Username: user.Username, Password: user.Password})
+	}
+
+	return users, rows.Err()
+}
+
+// GetPublicMessages returns up to limit public messages with IDs above the
+// given one, the oldest first.
+func (r *TransferSqliteRepos) GetPublicMessages(afterID, limit int) ([]entities.Message, error) {
+	query := "SELECT gc.id, u.username AS sender, '' AS recipient, gc.message, gc.created_at, gc.deleted " +
+		"FROM global_chat gc " +
+		"JOIN users u ON u.id = gc.sender_id " +
+		"WHERE gc.id > $1 " +
+		"ORDER BY gc.id " +
+		"LIMIT $2"
+
+	return r.messages(query, afterID, limit)
+}
+
+// GetPartners returns the partners of the user's private chats, the message
+// requests included, in the order of their names.
+func (r *TransferSqliteRepos) GetPartners(username string) ([]string, error) {
+	query := "SELECT p.username " +
+		"FROM conversations c " +
+		"JOIN users u ON u.id = c.user_id " +
+		"JOIN users p ON p.id = c.partner_id " +
+		"WHERE u.username = $1 " +
+		"ORDER BY p.username"
+
+	rows, err := r.db.Get(query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partners := make([]string, 0)
+	for rows.Next() {
+		var partner string
+		if err := rows.Scan(&partner); err != nil {
+			return nil, err
+		}
+
+		partners = append(partners, partner)
+	}
+
+	return partners, rows.Err()
+}
+
+// GetPrivateMessages returns up to limit messages of the private chat of
+// the two users with IDs above the given one, the oldest first. An expired
+// message comes without its content.
+func (r *TransferSqliteRepos) GetPrivateMessages(user1, user2 string, afterID, limit int) ([]entities.Message, error) {
+	query := "SELECT pc.id, su.username AS sender, ru.username AS recipient, " + privateContent("pc") + ", " +
+		"pc.created_at, pc.ttl_seconds, pc.expire_after_read " +
+		"FROM (SELECT min(a.id, b.id) AS low, max(a.id, b.id) AS high " +
+		"FROM users a, users b WHERE a.username = $1 AND b.username = $2) pair " +
+		"JOIN private_chats pc ON min(pc.sender_id, pc.recipient_id) = pair.low " +
+		"AND max(pc.sender_id, pc.recipient_id) = pair.high " +
+		"JOIN users su ON su.id = pc.sender_id " +
+		"JOIN users ru ON ru.id = pc.recipient_id " +
+		"WHERE pc.id > $3 " +
+		"ORDER BY pc.id " +
+		"LIMIT $4"
+
+	return r.messages(query, user1, user2, afterID, limit)
+}
+
+// ImportUser adds the user with the hash of their password as it is.
+func (r *TransferSqliteRepos) ImportUser(user entities.User) error {
+	query := "INSERT INTO users(username, password_hash) VALUES ($1, $2)"
+
+	return r.db.Insert(query, user.Username, user.Password)
+}
+
+// ImportPublicMessages appends the messages to the public chat in the given
+// order. They get IDs of this database. Nothing is imported when a sender
+// is unknown.
+func (r *TransferSqliteRepos) ImportPublicMessages(messages []entities.Message) error {
+	query := "INSERT INTO global_chat(sender_id, message, created_at, deleted) " +
+		"SELECT id, $2, $3, $4 FROM users WHERE username = $1 " +
+		"RETURNING id"
+
+	return r.db.Tx(func(tx *sqlite.Tx) error {
+		for _, m := range messages {
+			imported, err := returnedIDs(tx, query, m.Sender, m.Content, m.CreatedAt, m.Deleted)
+			if err != nil {
+				return err
+			}
+
+			if len(imported) == 0 {
+				return entities.ErrUserNotFound
+			}
+		}
+
+		return nil
+	})
+}
+
+// ImportPrivateMessages appends the messages to their private chats in the
+// given order. They get IDs of this database, and fill the inboxes and the
+// message requests the way sending them did. Nothing is imported when a
+// member is unknown.
+func (r *TransferSqliteRepos) ImportPrivateMessages(messages []entities.Message) error {
+	query := "INSERT INTO private_chats(sender_id, recipient_id, message, created_at, ttl_seconds, expire_after_read, expires_at, expired) " +
+		"SELECT s.id, r.id, $3, $4, NULLIF($5, 0), $6, $7, $8 " +
+		"FROM users s, users r WHERE s.username = $1 AND r.username = $2 " +
+		"RETURNING id, sender_id, recipient_id, created_at"
+
+	return r.db.Tx(func(tx *sqlite.Tx) error {
+		for _, m := range messages {
+			var expiresAt *time.Time
+			if !m.ExpiresAt.IsZero() {
+				expiresAt = &m.ExpiresAt
+			}
+
+			var inserted insertedMessage
+			err := insertMessage(tx, &inserted, query, m.Sender, m.Recipient, m.Content, m.CreatedAt,
+				int(m.TTL/time.Second), m.ExpireAfterRead, expiresAt, m.Expired)
+			if err != nil {
+				return err
+			}
+
+			if inserted.SenderID == nil || inserted.RecipientID == nil {
+				return entities.ErrUserNotFound
+			}
+
+			if err := r.addConversation(tx, *inserted.SenderID, *inserted.RecipientID, inserted); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// addConversation adds the message to the inboxes of its members the way
+// InsertMessage of PrivateSqliteRepos does.
+func (r *TransferSqliteRepos) addConversation(tx *sqlite.Tx, sender, recipient int, inserted insertedMessage) error {
+	query := "INSERT INTO contacts(user_id, contact_id) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+	if err := tx.Insert(query, sender, recipient); err != nil {
+		return err
+	}
+
+	if err := tx.Insert(upsertConversation, sender, recipient, inserted.ID, inserted.CreatedAt, false); err != nil {
+		return err
+	}
+
+	if sender == recipient {
+		return nil
+	}
+
+	isContact, err := exists(tx, "SELECT EXISTS (SELECT 1 FROM contacts WHERE user_id = $1 AND contact_id = $2)", recipient, sender)
+	if err != nil {
+		return err
+	}
+
+	return tx.Insert(upsertConversation, recipient, sender, inserted.ID, inserted.CreatedAt, !isContact)
+}
+
+func (r *TransferSqliteRepos) messages(query string, args ...interface{}) ([]entities.Message, error) {
+	rows, err := r.db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chat := models.ChatModel{Messages: make([]models.MessageModel, 0)}
+	for rows.Next() {
+		var message models.MessageModel
+		if err := rows.StructScan(&message); err != nil {
+			return nil, err
+		}
+
+		chat.Messages = append(chat.Messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mapper.MessageModelToEntities(chat), nil
+}
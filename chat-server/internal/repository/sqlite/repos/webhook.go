@@ -0,0 +1,272 @@
+package repos
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/internal/repository/postgres/mapper"
+	"github.com/vavelour/chat/internal/repository/postgres/models"
+	"github.com/vavelour/chat/internal/repository/sqlite"
+)
+
+const (
+	webhookColumns = "id, url, events, channel, secret, active, failures, created_by, created_at"
+
+	deliveryColumns = "d.id, d.webhook_id, w.url, w.secret, d.event_id, e.type, e.channel, e.message_id, e.sender, e.message, " +
+		"e.created_at AS event_created_at, d.status, d.attempts, d.next_attempt_at, d.response_status, d.last_error, " +
+		"d.created_at, d.updated_at"
+
+	// subscribedWebhook filters the webhooks w subscribed to the event $1 of
+	// the channel $2.
+	subscribedWebhook = "WHERE w.active AND instr(',' || w.events || ',', ',' || $1 || ',') > 0 " +
+		"AND (w.channel IS NULL OR w.channel = $2)"
+)
+
+type WebhookSqliteDB interface {
+	Insert(query string, args ...interface{}) error
+	Get(query string, args ...interface{}) (*sqlx.Rows, error)
+	Tx(fn func(tx *sqlite.Tx) error) error
+}
+
+type WebhookSqliteRepos struct {
+	db WebhookSqliteDB
+}
+
+func NewWebhookSqliteRepos(db WebhookSqliteDB) *WebhookSqliteRepos {
+	return &WebhookSqliteRepos{db: db}
+}
+
+func (r *WebhookSqliteRepos) InsertWebhook(w entities.Webhook) (entities.Webhook, error) {
+	query := "INSERT INTO webhooks(url, events, channel, secret, active, created_by) " +
+		"VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6) " +
+		"RETURNING " + webhookColumns
+
+	webhooks, err := listWebhooks(r.db, query, w.URL, joinEvents(w.Events), w.Channel, w.Secret, w.Active, w.CreatedBy)
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.Webhook{}, entities.ErrWebhookNotFound
+	}
+
+	return webhooks[0], nil
+}
+
+func (r *WebhookSqliteRepos) GetWebhooks() ([]entities.Webhook, error) {
+	return listWebhooks(r.db, "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+}
+
+func (r *WebhookSqliteRepos) GetWebhook(id int) (entities.Webhook, error) {
+	webhooks, err := listWebhooks(r.db, "SELECT "+webhookColumns+" FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.Webhook{}, entities.ErrWebhookNotFound
+	}
+
+	return webhooks[0], nil
+}
+
+// DeleteWebhook removes the webhook, its deliveries go with it.
+func (r *WebhookSqliteRepos) DeleteWebhook(id int) error {
+	webhooks, err := listWebhooks(r.db, "DELETE FROM webhooks WHERE id = $1 RETURNING "+webhookColumns, id)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// SetWebhookActive enables or disables the webhook. Enabling it starts the
+// failure count over.
+func (r *WebhookSqliteRepos) SetWebhookActive(id int, active bool) error {
+	query := "UPDATE webhooks SET active = $2, failures = CASE WHEN $2 THEN 0 ELSE failures END " +
+		"WHERE id = $1 RETURNING " + webhookColumns
+
+	webhooks, err := listWebhooks(r.db, query, id, active)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return entities.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// GetDeliveries returns the delivery log of the webhook, newest first.
+func (r *WebhookSqliteRepos) GetDeliveries(webhookID, limit, offset int) ([]entities.WebhookDelivery, error) {
+	if _, err := r.GetWebhook(webhookID); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + deliveryColumns + " " +
+		"FROM webhook_deliveries d " +
+		"JOIN webhooks w ON w.id = d.webhook_id " +
+		"JOIN webhook_events e ON e.id = d.event_id " +
+		"WHERE d.webhook_id = $1 " +
+		"ORDER BY d.id DESC LIMIT $2 OFFSET $3"
+
+	return listDeliveries(r.db, query, webhookID, limit, offset)
+}
+
+// ClaimDeliveries returns up to limit pending deliveries which are due and
+// belong to active webhooks. They are leased until now+lease, so a worker
+// that dies before recording the attempt only delays them. The claim takes
+// the write lock of the database, so no two workers claim the same ones.
+func (r *WebhookSqliteRepos) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]entities.WebhookDelivery, error) {
+	var claimed []entities.WebhookDelivery
+
+	err := r.db.Tx(func(tx *sqlite.Tx) error {
+		query := "UPDATE webhook_deliveries SET next_attempt_at = $2 " +
+			"WHERE id IN (SELECT d.id FROM webhook_deliveries d " +
+			"JOIN webhooks w ON w.id = d.webhook_id " +
+			"WHERE d.status = 'pending' AND d.next_attempt_at <= $1 AND w.active " +
+			"ORDER BY d.next_attempt_at, d.id LIMIT $3) " +
+			"RETURNING id"
+
+		ids, err := returnedIDs(tx, query, now, now.Add(lease), limit)
+		if err != nil {
+			return err
+		}
+
+		query = "SELECT " + deliveryColumns + " " +
+			"FROM webhook_deliveries d " +
+			"JOIN webhooks w ON w.id = d.webhook_id " +
+			"JOIN webhook_events e ON e.id = d.event_id " +
+			"WHERE d.id IN (SELECT value FROM json_each($1)) " +
+			"ORDER BY d.id"
+
+		claimed, err = listDeliveries(tx, query, jsonArray(ids))
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// RecordAttempt saves the outcome of a delivery attempt. A failed attempt
+// counts against the webhook, which is disabled after disableAfter failures
+// in a row; a successful one resets the count.
+func (r *WebhookSqliteRepos) RecordAttempt(d entities.WebhookDelivery, disableAfter int) error {
+	return r.db.Tx(func(tx *sqlite.Tx) error {
+		query := "UPDATE webhook_deliveries SET status = $2, attempts = $3, next_attempt_at = $4, " +
+			"response_status = $5, last_error = $6, updated_at = " + sqlNow + " " +
+			"WHERE id = $1"
+
+		if err := tx.Insert(query, d.ID, string(d.Status), d.Attempts, d.NextAttemptAt, d.ResponseStatus, d.LastError); err != nil {
+			return err
+		}
+
+		query = "UPDATE webhooks SET " +
+			"active = active AND ($2 = 'succeeded' OR failures + 1 < $3), " +
+			"failures = CASE WHEN $2 = 'succeeded' THEN 0 ELSE failures + 1 END " +
+			"WHERE id = (SELECT webhook_id FROM webhook_deliveries WHERE id = $1)"
+
+		return tx.Insert(query, d.ID, string(d.Status), disableAfter)
+	})
+}
+
+// enqueueWebhookEvent saves the event of the message and a delivery of it
+// to every webhook subscribed to it, if there is any.
+func enqueueWebhookEvent(tx *sqlite.Tx, event entities.WebhookEventType, channel string, messageID int, sender, content string, createdAt time.Time) error {
+	query := "INSERT INTO webhook_events(type, channel, message_id, sender, message, created_at) " +
+		"SELECT $1, $2, $3, $4, $5, $6 " +
+		"WHERE EXISTS (SELECT 1 FROM webhooks w " + subscribedWebhook + ") " +
+		"RETURNING id"
+
+	ids, err := returnedIDs(tx, query, string(event), channel, messageID, sender, content, createdAt)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	query = "INSERT INTO webhook_deliveries(webhook_id, event_id) " +
+		"SELECT w.id, $3 FROM webhooks w " + subscribedWebhook
+
+	return tx.Insert(query, string(event), channel, ids[0])
+}
+
+func listWebhooks(db querier, query string, args ...interface{}) ([]entities.Webhook, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]entities.Webhook, 0)
+	for rows.Next() {
+		var model models.WebhookModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, mapper.WebhookModelToEntity(model))
+	}
+
+	return webhooks, rows.Err()
+}
+
+func listDeliveries(db querier, query string, args ...interface{}) ([]entities.WebhookDelivery, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]entities.WebhookDelivery, 0)
+	for rows.Next() {
+		var model models.WebhookDeliveryModel
+		err := rows.StructScan(&model)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, mapper.WebhookDeliveryModelToEntity(model))
+	}
+
+	return deliveries, rows.Err()
+}
+
+// returnedIDs runs the statement and reads the IDs it returns.
+func returnedIDs(db querier, query string, args ...interface{}) ([]int, error) {
+	rows, err := db.Get(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func joinEvents(events []entities.WebhookEventType) string {
+	res := make([]string, 0, len(events))
+	for _, val := range events {
+		res = append(res, string(val))
+	}
+
+	return strings.Join(res, ",")
+}
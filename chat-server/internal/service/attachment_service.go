@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+const (
+	sniffLen       = 512
+	maxFileNameLen = 255
+	blobIDSize     = 16
+)
+
+//go:generate mockgen -source=attachment_service.go -destination=mocks/attachment_repository_mock.go
+
+type AttachmentRepository interface {
+	GetAttachment(id string) (entities.Attachment, entities.Message, error)
+}
+
+type PublicChatSender interface {
+	SendPublicMessage(m entities.Message) (entities.SendResult, error)
+}
+
+type PrivateChatSender interface {
+	SendPrivateMessage(m entities.Message) (entities.SendResult, error)
+}
+
+// AttachmentService stores uploaded files in the blob store and links their
+// metadata to public or private messages.
+type AttachmentService struct {
+	public       AttachmentRepository
+	private      AttachmentRepository
+	publicChat   PublicChatSender
+	privateChat  PrivateChatSender
+	store        blobstore.BlobStore
+	maxSize      int64
+	allowedTypes []string
+}
+
+func NewAttachmentService(public, private AttachmentRepository, publicChat PublicChatSender, privateChat PrivateChatSender, store blobstore.BlobStore, maxSize int64, allowedTypes []string) *AttachmentService {
+	return &AttachmentService{public: public, private: private, publicChat: publicChat, privateChat: privateChat, store: store, maxSize: maxSize, allowedTypes: allowedTypes}
+}
+
+// Upload stores the file read from r. The content type is sniffed from the
+// content itself, the name and headers sent by the client are not trusted.
+func (s *AttachmentService) Upload(ctx context.Context, fileName string, r io.Reader) (entities.Attachment, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return entities.Attachment{}, err
+	}
+
+	if n == 0 {
+		return entities.Attachment{}, entities.ErrEmptyAttachment
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if !s.allowed(contentType) {
+		return entities.Attachment{}, entities.ErrUnsupportedType
+	}
+
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return entities.Attachment{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, io.MultiReader(bytes.NewReader(head[:n]), io.LimitReader(r, s.maxSize-int64(n)+1)))
+	if err != nil {
+		return entities.Attachment{}, err
+	}
+
+	if size > s.maxSize {
+		return entities.Attachment{}, entities.ErrAttachmentTooLarge
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return entities.Attachment{}, err
+	}
+
+	id, err := newBlobID()
+	if err != nil {
+		return entities.Attachment{}, err
+	}
+
+	if err := s.store.Put(ctx, id, tmp, size, contentType); err != nil {
+		return entities.Attachment{}, err
+	}
+
+	return entities.Attachment{ID: id, FileName: cleanFileName(fileName), ContentType: contentType, Size: size}, nil
+}
+
+// Discard removes stored files which did not end up attached to a message.
+func (s *AttachmentService) Discard(ctx context.Context, attachments []entities.Attachment) {
+	ids := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		ids = append(ids, attachment.ID)
+	}
+
+	deleteBlobs(ctx, s.store, "attachments", ids)
+}
+
+// SendMessage sends the message to the public chat when it has no recipient
+// and to the private chat otherwise, the same way as a message without
+// files: a command is run, the mentioned users are notified and the sender
+// is checked against the sanctions and the privacy of the recipient.
+// Uploaded files are discarded if the message was not saved with them,
+// which includes the messages run as commands.
+func (s *AttachmentService) SendMessage(ctx context.Context, m entities.Message) (entities.SendResult, error) {
+	send := s.publicChat.SendPublicMessage
+	if m.Recipient != "" {
+		send = s.privateChat.SendPrivateMessage
+	}
+
+	res, err := send(m)
+	if err != nil || res.Command {
+		s.Discard(ctx, m.Attachments)
+	}
+
+	return res, err
+}
+
+// Open returns the attachment and its content if the user can see the
+// message it belongs to. Attachments of other users' private chats are
+// reported as not found.
+func (s *AttachmentService) Open(ctx context.Context, user, id string) (entities.Attachment, io.ReadCloser, error) {
+	attachment, _, err := s.public.GetAttachment(id)
+	if errors.Is(err, entities.ErrAttachmentNotFound) {
+		var m entities.Message
+		attachment, m, err = s.private.GetAttachment(id)
+		if err == nil && user != m.Sender && user != m.Recipient {
+			err = entities.ErrAttachmentNotFound
+		}
+	}
+
+	if err != nil {
+		return entities.Attachment{}, nil, err
+	}
+
+	content, err := s.store.Get(ctx, attachment.ID)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return entities.Attachment{}, nil, entities.ErrAttachmentNotFound
+	} else if err != nil {
+		return entities.Attachment{}, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+func (s *AttachmentService) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range s.allowedTypes {
+		if allowed == mediaType || strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteBlobs removes the files of the attachments with the given IDs from
+// the store. The messages they belonged to are gone already, so a failure
+// is only logged: the file stays behind, but nothing links to it.
+func deleteBlobs(ctx context.Context, store blobstore.BlobStore, service string, ids []string) {
+	for _, id := range ids {
+		if err := store.Delete(ctx, id); err != nil {
+			log.Printf("%s: delete attachment %s: %s", service, id, err)
+		}
+	}
+}
+
+func newBlobID() (string, error) {
+	b := make([]byte, blobIDSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return time.Now().UTC().Format("20060102") + "-" + hex.EncodeToString(b), nil
+}
+
+func cleanFileName(fileName string) string {
+	fileName = path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if fileName == "." || fileName == "/" {
+		return "file"
+	}
+
+	if len(fileName) > maxFileNameLen {
+		fileName = strings.ToValidUTF8(fileName[len(fileName)-maxFileNameLen:], "")
+	}
+
+	return fileName
+}
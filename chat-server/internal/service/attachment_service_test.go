@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+func TestAttachmentService_SendMessage(t *testing.T) {
+	type mockBehavior func(public *mock_service.MockPublicChatSender, private *mock_service.MockPrivateChatSender, m entities.Message)
+
+	tests := []struct {
+		name          string
+		message       entities.Message
+		mockBehavior  mockBehavior
+		expectedKept  bool
+		expectedError error
+	}{
+		{
+			name:    "Public",
+			message: entities.Message{Sender: "tester", Content: "look @valera"},
+			mockBehavior: func(public *mock_service.MockPublicChatSender, private *mock_service.MockPrivateChatSender, m entities.Message) {
+				public.EXPECT().SendPublicMessage(m).Return(entities.SendResult{}, nil)
+			},
+			expectedKept: true,
+		},
+		{
+			name:    "Private",
+			message: entities.Message{Sender: "tester", Recipient: "valera"},
+			mockBehavior: func(public *mock_service.MockPublicChatSender, private *mock_service.MockPrivateChatSender, m entities.Message) {
+				private.EXPECT().SendPrivateMessage(m).Return(entities.SendResult{}, nil)
+			},
+			expectedKept: true,
+		},
+		{
+			name:    "Command",
+			message: entities.Message{Sender: "tester", Content: "/help"},
+			mockBehavior: func(public *mock_service.MockPublicChatSender, private *mock_service.MockPrivateChatSender, m entities.Message) {
+				public.EXPECT().SendPublicMessage(m).Return(entities.SendResult{Command: true}, nil)
+			},
+		},
+		{
+			name:    "Not allowed",
+			message: entities.Message{Sender: "tester", Recipient: "valera"},
+			mockBehavior: func(public *mock_service.MockPublicChatSender, private *mock_service.MockPrivateChatSender, m entities.Message) {
+				private.EXPECT().SendPrivateMessage(m).Return(entities.SendResult{}, entities.ErrMessageNotAllowed)
+			},
+			expectedError: entities.ErrMessageNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+
+			store, err := blobstore.NewLocalStore(t.TempDir())
+			require.NoError(t, err)
+			require.NoError(t, store.Put(ctx, "a1", strings.NewReader("png"), 3, "image/png"))
+
+			public := mock_service.NewMockPublicChatSender(ctrl)
+			private := mock_service.NewMockPrivateChatSender(ctrl)
+
+			m := tt.message
+			m.Attachments = []entities.Attachment{{ID: "a1", FileName: "cat.png", ContentType: "image/png", Size: 3}}
+			tt.mockBehavior(public, private, m)
+
+			s := NewAttachmentService(nil, nil, public, private, store, 1<<20, []string{"image/*"})
+
+			_, err = s.SendMessage(ctx, m)
+			assert.ErrorIs(t, err, tt.expectedError)
+
+			_, err = store.Get(ctx, "a1")
+			if tt.expectedKept {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, blobstore.ErrNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/blobstore"
+	"github.com/vavelour/chat/pkg/imaging"
+)
+
+//go:generate mockgen -source=avatar_service.go -destination=mocks/avatar_repository_mock.go
+
+type AvatarRepository interface {
+	SetAvatar(avatar entities.Avatar) (string, error)
+	GetAvatar(username string) (entities.Avatar, error)
+}
+
+type AvatarOptions struct {
+	MaxSize     int64
+	MaxSide     int
+	Sizes       []int
+	DefaultSize int
+	Workers     int
+	QueueSize   int
+}
+
+type avatarJob struct {
+	avatar entities.Avatar
+	img    image.Image
+}
+
+// AvatarService validates uploaded avatars and hands them over to a pool of
+// workers which render the thumbnails. A new avatar becomes visible once all
+// of its thumbnails are stored.
+type AvatarService struct {
+	repos AvatarRepository
+	store blobstore.BlobStore
+	opts  AvatarOptions
+	jobs  chan avatarJob
+}
+
+func NewAvatarService(r AvatarRepository, store blobstore.BlobStore, opts AvatarOptions) *AvatarService {
+	return &AvatarService{repos: r, store: store, opts: opts, jobs: make(chan avatarJob, opts.QueueSize)}
+}
+
+// Upload validates the image and queues it for processing. Metadata of the
+// source file, EXIF and GPS included, never reaches the storage since the
+// thumbnails are rendered from the decoded pixels.
+func (s *AvatarService) Upload(user string, r io.Reader) (entities.Avatar, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.opts.MaxSize+1))
+	if err != nil {
+		return entities.Avatar{}, err
+	}
+
+	if int64(len(data)) > s.opts.MaxSize {
+		return entities.Avatar{}, entities.ErrAvatarTooLarge
+	}
+
+	img, err := imaging.Decode(data, s.opts.MaxSide)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return entities.Avatar{}, entities.ErrAvatarTooLarge
+	} else if err != nil {
+		return entities.Avatar{}, entities.ErrInvalidAvatar
+	}
+
+	id, err := newBlobID()
+	if err != nil {
+		return entities.Avatar{}, err
+	}
+
+	avatar := entities.Avatar{Username: user, ID: id, ContentType: imaging.ContentType(img), UpdatedAt: time.Now()}
+
+	select {
+	case s.jobs <- avatarJob{avatar: avatar, img: img}:
+	default:
+		return entities.Avatar{}, entities.ErrAvatarBusy
+	}
+
+	return avatar, nil
+}
+
+func (s *AvatarService) Delete(ctx context.Context, user string) error {
+	stale, err := s.repos.SetAvatar(entities.Avatar{Username: user, UpdatedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	s.discard(ctx, stale)
+
+	return nil
+}
+
+// Open returns the avatar of the user together with its thumbnail of the
+// given size, or of the default size when size is 0.
+func (s *AvatarService) Open(ctx context.Context, username string, size int) (entities.Avatar, io.ReadCloser, error) {
+	if size == 0 {
+		size = s.opts.DefaultSize
+	}
+
+	if !s.supportedSize(size) {
+		return entities.Avatar{}, nil, entities.ErrInvalidAvatarSize
+	}
+
+	avatar, err := s.repos.GetAvatar(username)
+	if err != nil {
+		return entities.Avatar{}, nil, err
+	}
+
+	content, err := s.store.Get(ctx, avatarKey(avatar.ID, size))
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return entities.Avatar{}, nil, entities.ErrAvatarNotFound
+	} else if err != nil {
+		return entities.Avatar{}, nil, err
+	}
+
+	return avatar, content, nil
+}
+
+// Run starts the thumbnail workers and blocks until ctx is done. Avatars
+// still waiting in the queue at that point are dropped.
+func (s *AvatarService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.jobs:
+					s.process(ctx, job)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (s *AvatarService) process(ctx context.Context, job avatarJob) {
+	for _, size := range s.opts.Sizes {
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, imaging.Thumbnail(job.img, size), job.avatar.ContentType); err != nil {
+			log.Printf("avatars: render %s of %s: %s", job.avatar.ID, job.avatar.Username, err)
+			s.discard(ctx, job.avatar.ID)
+			return
+		}
+
+		if err := s.store.Put(ctx, avatarKey(job.avatar.ID, size), &buf, int64(buf.Len()), job.avatar.ContentType); err != nil {
+			log.Printf("avatars: store %s of %s: %s", job.avatar.ID, job.avatar.Username, err)
+			s.discard(ctx, job.avatar.ID)
+			return
+		}
+	}
+
+	stale, err := s.repos.SetAvatar(job.avatar)
+	if err != nil {
+		log.Printf("avatars: set %s of %s: %s", job.avatar.ID, job.avatar.Username, err)
+		stale = job.avatar.ID
+	}
+
+	s.discard(ctx, stale)
+}
+
+func (s *AvatarService) discard(ctx context.Context, id string) {
+	if id == "" {
+		return
+	}
+
+	for _, size := range s.opts.Sizes {
+		if err := s.store.Delete(ctx, avatarKey(id, size)); err != nil {
+			log.Printf("avatars: delete %s: %s", id, err)
+		}
+	}
+}
+
+func (s *AvatarService) supportedSize(size int) bool {
+	for _, val := range s.opts.Sizes {
+		if val == size {
+			return true
+		}
+	}
+
+	return false
+}
+
+func avatarKey(id string, size int) string {
+	return id + "/" + strconv.Itoa(size)
+}
@@ -18,15 +18,17 @@ type AuthRepository interface {
 }
 
 type AuthService struct {
-	repos AuthRepository
+	repos    AuthRepository
+	units    UnitOfWork
+	accounts AccountChecker
 }
 
-func NewAuthService(r AuthRepository) *AuthService {
-	return &AuthService{repos: r}
+func NewAuthService(r AuthRepository, units UnitOfWork, accounts AccountChecker) *AuthService {
+	return &AuthService{repos: r, units: units, accounts: accounts}
 }
 
 func (s *AuthService) CreateUser(username, password string) (string, error) {
-	if err := s.repos.InsertUser(username, password); err != nil {
+	if err := insertUser(s.units, username, password); err != nil {
 		return "", err
 	}
 
@@ -48,5 +50,22 @@ func (s *AuthService) UserIdentity(usr interface{}) (string, error) {
 		return "", ErrIncorrectPassword
 	}
 
+	if err := s.accounts.CheckAccount(u.Username); err != nil {
+		return "", err
+	}
+
 	return u.Username, nil
 }
+
+// insertUser adds the user with the default privacy settings. They are
+// stored rather than implied, so that a later change of the defaults does
+// not change the settings of the users who signed up before it.
+func insertUser(units UnitOfWork, username, password string) error {
+	return units.Do(func(r Repositories) error {
+		if err := r.Auth.InsertUser(username, password); err != nil {
+			return err
+		}
+
+		return r.Privacy.UpdatePrivacySettings(username, entities.DefaultPrivacySettings())
+	})
+}
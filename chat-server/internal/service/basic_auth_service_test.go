@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+)
+
+func TestAuthService_CreateUser(t *testing.T) {
+	type mockBehavior func(auth *mock_service.MockAuthRepository, privacy *mock_service.MockPrivacyRepository)
+
+	testTable := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name: "ok",
+			mockBehavior: func(auth *mock_service.MockAuthRepository, privacy *mock_service.MockPrivacyRepository) {
+				gomock.InOrder(
+					auth.EXPECT().InsertUser("tester", "123").Return(nil),
+					privacy.EXPECT().UpdatePrivacySettings("tester", entities.DefaultPrivacySettings()).Return(nil),
+				)
+			},
+		},
+		{
+			name: "user_taken",
+			mockBehavior: func(auth *mock_service.MockAuthRepository, privacy *mock_service.MockPrivacyRepository) {
+				auth.EXPECT().InsertUser("tester", "123").Return(errors.New("user taken"))
+			},
+			expectedError: errors.New("user taken"),
+		},
+		{
+			name: "settings_error",
+			mockBehavior: func(auth *mock_service.MockAuthRepository, privacy *mock_service.MockPrivacyRepository) {
+				auth.EXPECT().InsertUser("tester", "123").Return(nil)
+				privacy.EXPECT().UpdatePrivacySettings("tester", entities.DefaultPrivacySettings()).Return(errors.New("settings error"))
+			},
+			expectedError: errors.New("settings error"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auth := mock_service.NewMockAuthRepository(ctrl)
+			privacy := mock_service.NewMockPrivacyRepository(ctrl)
+			testCase.mockBehavior(auth, privacy)
+
+			// The unit reports the error of fn, as a real one does after
+			// rolling back.
+			var units UnitOfWorkFunc = func(fn func(r Repositories) error) error {
+				return fn(Repositories{Auth: auth, Privacy: privacy})
+			}
+
+			s := NewAuthService(mock_service.NewMockAuthRepository(ctrl), units, nil)
+
+			username, err := s.CreateUser("tester", "123")
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "tester", username)
+		})
+	}
+}
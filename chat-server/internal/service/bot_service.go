@@ -0,0 +1,50 @@
+package service
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+type BuiltinChecker interface {
+	IsBuiltin(name string) bool
+}
+
+// BotService manages the commands served by external bots.
+type BotService struct {
+	repos    BotCommandRepository
+	builtins BuiltinChecker
+}
+
+func NewBotService(r BotCommandRepository, builtins BuiltinChecker) *BotService {
+	return &BotService{repos: r, builtins: builtins}
+}
+
+// Create registers the command with a new secret the bot checks requests
+// with; it is returned only here. The bot gets a random password nobody
+// knows, so it can not log in.
+func (s *BotService) Create(c entities.BotCommand) (entities.BotCommand, error) {
+	if s.builtins.IsBuiltin(c.Command) {
+		return entities.BotCommand{}, entities.ErrCommandTaken
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	password, err := randomToken()
+	if err != nil {
+		return entities.BotCommand{}, err
+	}
+
+	c.Secret = secret
+
+	return s.repos.InsertBotCommand(c, password)
+}
+
+func (s *BotService) List() ([]entities.BotCommand, error) {
+	return s.repos.GetBotCommands()
+}
+
+func (s *BotService) Delete(id int) error {
+	return s.repos.DeleteBotCommand(id)
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/webhook"
+)
+
+const (
+	commandEvent      = "command"
+	maxBotReplyLength = 64 << 10
+)
+
+// commandPattern matches a slash, the command name and its arguments. Other
+// text starting with a slash, like a path, is not a command.
+var commandPattern = regexp.MustCompile(`^/([A-Za-z][A-Za-z0-9_-]{0,31})(?:\s+([\s\S]*))?$`)
+
+//go:generate mockgen -source=command_router.go -destination=mocks/command_router_mock.go
+
+type BotCommandRepository interface {
+	InsertBotCommand(c entities.BotCommand, botPassword string) (entities.BotCommand, error)
+	GetBotCommands() ([]entities.BotCommand, error)
+	GetBotCommand(command string) (entities.BotCommand, error)
+	DeleteBotCommand(id int) error
+}
+
+type PresenceLister interface {
+	Online() []entities.Presence
+	GetPresence(usernames []string) ([]entities.Presence, error)
+}
+
+type CommandFunc func(c entities.Command) (entities.CommandReply, error)
+
+type builtinCommand struct {
+	usage       string
+	description string
+	run         CommandFunc
+}
+
+type botRequest struct {
+	Command   string `json:"command"`
+	Text      string `json:"text"`
+	UserName  string `json:"user_name"`
+	Channel   string `json:"channel"`
+	Recipient string `json:"recipient,omitempty"`
+}
+
+type botReply struct {
+	ResponseType entities.CommandVisibility `json:"response_type"`
+	Text         string                     `json:"text"`
+}
+
+// CommandRouter runs the slash commands of the chat: the built-in ones and
+// those served by external bots over HTTP.
+type CommandRouter struct {
+	bots     BotCommandRepository
+	presence PresenceLister
+	client   *http.Client
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	builtins map[string]builtinCommand
+}
+
+func NewCommandRouter(bots BotCommandRepository, presence PresenceLister, client *http.Client) *CommandRouter {
+	r := &CommandRouter{
+		bots:     bots,
+		presence: presence,
+		client:   client,
+		now:      time.Now,
+		builtins: make(map[string]builtinCommand),
+	}
+
+	r.Register("me", "/me <action>", "show an action, like /me waves", r.me)
+	r.Register("shrug", "/shrug [text]", `add ¯\_(ツ)_/¯ to the message`, r.shrug)
+	r.Register("who", "/who", "show who is online, or the status of the partner in a private chat", r.who)
+	r.Register("help", "/help", "list the commands", r.help)
+
+	return r
+}
+
+// Register adds a built-in command, replacing one with the same name.
+func (r *CommandRouter) Register(name, usage, description string, run CommandFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.builtins[name] = builtinCommand{usage: usage, description: description, run: run}
+}
+
+func (r *CommandRouter) IsBuiltin(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.builtins[name]
+
+	return ok
+}
+
+// Dispatch runs the command in the message and returns the reply to it. The
+// reply is nil when the message is not a command; a message starting with
+// "//" is not one either and loses the first slash.
+func (r *CommandRouter) Dispatch(channel string, m entities.Message) (entities.Message, *entities.CommandReply) {
+	if strings.HasPrefix(m.Content, "//") {
+		m.Content = m.Content[1:]
+		return m, nil
+	}
+
+	match := commandPattern.FindStringSubmatch(m.Content)
+	if match == nil {
+		return m, nil
+	}
+
+	c := entities.Command{
+		Name:      strings.ToLower(match[1]),
+		Args:      strings.TrimSpace(match[2]),
+		Sender:    m.Sender,
+		Channel:   channel,
+		Recipient: m.Recipient,
+	}
+
+	reply := r.run(c)
+	if reply.Visibility == "" {
+		reply.Visibility = entities.VisibilityEphemeral
+	}
+
+	// A private chat has two members, nobody else can post in it.
+	if channel == entities.PrivateChannel && reply.Sender != c.Sender {
+		reply.Visibility = entities.VisibilityEphemeral
+	}
+
+	return m, &reply
+}
+
+func (r *CommandRouter) run(c entities.Command) entities.CommandReply {
+	r.mu.RLock()
+	builtin, ok := r.builtins[c.Name]
+	r.mu.RUnlock()
+
+	if ok {
+		reply, err := builtin.run(c)
+		if err != nil {
+			log.Printf("commands: run /%s: %s", c.Name, err)
+			return entities.CommandReply{Text: fmt.Sprintf("Command /%s failed, try again later.", c.Name)}
+		}
+
+		return reply
+	}
+
+	bot, err := r.bots.GetBotCommand(c.Name)
+	if err == entities.ErrBotCommandNotFound {
+		return entities.CommandReply{Text: fmt.Sprintf("Unknown command /%s. Type /help to see the commands.", c.Name)}
+	} else if err != nil {
+		log.Printf("commands: get /%s: %s", c.Name, err)
+		return entities.CommandReply{Text: fmt.Sprintf("Command /%s failed, try again later.", c.Name)}
+	}
+
+	reply, err := r.callBot(bot, c)
+	if err != nil {
+		log.Printf("commands: call /%s: %s", c.Name, err)
+		return entities.CommandReply{Sender: bot.BotName, Text: fmt.Sprintf("%s did not answer to /%s.", bot.BotName, c.Name)}
+	}
+
+	return reply
+}
+
+// callBot sends the command to the bot signed the same way as webhooks and
+// reads its reply in Slack's slash command format.
+func (r *CommandRouter) callBot(bot entities.BotCommand, c entities.Command) (entities.CommandReply, error) {
+	body, err := json.Marshal(botRequest{Command: "/" + c.Name, Text: c.Args, UserName: c.Sender, Channel: c.Channel, Recipient: c.Recipient})
+	if err != nil {
+		return entities.CommandReply{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, bot.URL, bytes.NewReader(body))
+	if err != nil {
+		return entities.CommandReply{}, err
+	}
+
+	now := r.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(webhook.EventHeader, commandEvent)
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(bot.Secret, now, body))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return entities.CommandReply{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return entities.CommandReply{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBotReplyLength))
+	if err != nil {
+		return entities.CommandReply{}, err
+	}
+
+	reply := entities.CommandReply{Sender: bot.BotName}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return reply, nil
+	}
+
+	var res botReply
+	if err := json.Unmarshal(data, &res); err != nil {
+		// Like Slack, a reply that is not JSON is taken as ephemeral text.
+		reply.Text = strings.TrimSpace(string(data))
+		return reply, nil
+	}
+
+	reply.Text = strings.TrimSpace(res.Text)
+	if res.ResponseType == entities.VisibilityInChannel {
+		reply.Visibility = entities.VisibilityInChannel
+	}
+
+	return reply, nil
+}
+
+func (r *CommandRouter) me(c entities.Command) (entities.CommandReply, error) {
+	if c.Args == "" {
+		return entities.CommandReply{Text: "Usage: /me <action>"}, nil
+	}
+
+	return entities.CommandReply{Sender: c.Sender, Text: "* " + c.Sender + " " + c.Args, Visibility: entities.VisibilityInChannel}, nil
+}
+
+func (r *CommandRouter) shrug(c entities.Command) (entities.CommandReply, error) {
+	return entities.CommandReply{Sender: c.Sender, Text: strings.TrimSpace(c.Args + ` ¯\_(ツ)_/¯`), Visibility: entities.VisibilityInChannel}, nil
+}
+
+func (r *CommandRouter) who(c entities.Command) (entities.CommandReply, error) {
+	if c.Channel == entities.PrivateChannel {
+		presence, err := r.presence.GetPresence([]string{c.Recipient})
+		if err != nil {
+			return entities.CommandReply{}, err
+		}
+
+		p := presence[0]
+		if p.Status == entities.StatusOffline && !p.LastSeen.IsZero() {
+			return entities.CommandReply{Text: fmt.Sprintf("%s is offline, last seen %s.", p.Username, p.LastSeen.UTC().Format("2006-01-02 15:04 MST"))}, nil
+		}
+
+		return entities.CommandReply{Text: fmt.Sprintf("%s is %s.", p.Username, p.Status)}, nil
+	}
+
+	byStatus := make(map[string][]string)
+	for _, p := range r.presence.Online() {
+		byStatus[p.Status] = append(byStatus[p.Status], p.Username)
+	}
+
+	var lines []string
+	if users := byStatus[entities.StatusOnline]; len(users) > 0 {
+		lines = append(lines, "Online: "+strings.Join(users, ", "))
+	}
+	if users := byStatus[entities.StatusAway]; len(users) > 0 {
+		lines = append(lines, "Away: "+strings.Join(users, ", "))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "Nobody is online.")
+	}
+
+	return entities.CommandReply{Text: strings.Join(lines, "\n")}, nil
+}
+
+func (r *CommandRouter) help(c entities.Command) (entities.CommandReply, error) {
+	var lines []string
+
+	r.mu.RLock()
+	for _, val := range r.builtins {
+		lines = append(lines, val.usage+" - "+val.description)
+	}
+	r.mu.RUnlock()
+
+	bots, err := r.bots.GetBotCommands()
+	if err != nil {
+		log.Printf("commands: get bot commands: %s", err)
+	}
+
+	for _, val := range bots {
+		line := "/" + val.Command + " - "
+		if val.Description != "" {
+			line += val.Description + ", "
+		}
+		lines = append(lines, line+"by "+val.BotName)
+	}
+
+	sort.Strings(lines)
+
+	return entities.CommandReply{Text: "Commands:\n" + strings.Join(lines, "\n") + "\nStart a message with // to send it as is."}, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/webhook"
+)
+
+func TestCommandRouter_Dispatch(t *testing.T) {
+	type mockBehavior func(b *mock_service.MockBotCommandRepository, p *mock_service.MockPresenceLister)
+
+	testTable := []struct {
+		name            string
+		channel         string
+		message         entities.Message
+		mockBehavior    mockBehavior
+		expectedMessage entities.Message
+		expectedReply   *entities.CommandReply
+	}{
+		{
+			name:            "not_command",
+			channel:         entities.PublicChannel,
+			message:         entities.Message{Sender: "valera", Content: "/usr/bin is a path"},
+			mockBehavior:    func(b *mock_service.MockBotCommandRepository, p *mock_service.MockPresenceLister) {},
+			expectedMessage: entities.Message{Sender: "valera", Content: "/usr/bin is a path"},
+		},
+		{
+			name:            "escaped",
+			channel:         entities.PublicChannel,
+			message:         entities.Message{Sender: "valera", Content: "//me is a command"},
+			mockBehavior:    func(b *mock_service.MockBotCommandRepository, p *mock_service.MockPresenceLister) {},
+			expectedMessage: entities.Message{Sender: "valera", Content: "/me is a command"},
+		},
+		{
+			name:            "me",
+			channel:         entities.PublicChannel,
+			message:         entities.Message{Sender: "valera", Content: "/me waves"},
+			mockBehavior:    func(b *mock_service.MockBotCommandRepository, p *mock_service.MockPresenceLister) {},
+			expectedMessage: entities.Message{Sender: "valera", Content: "/me waves"},
+			expectedReply:   &entities.CommandReply{Sender: "valera", Text: "* valera waves", Visibility: entities.VisibilityInChannel},
+		},
+		{
+			name:            "shrug_private",
+			channel:         entities.PrivateChannel,
+			message:         entities.Message{Sender: "valera", Recipient: "dima", Content: "/SHRUG ok"},
+			mockBehavior:    func(b *mock_service.MockBotCommandRepository, p *mock_service.MockPresenceLister) {},
+			expectedMessage: entities.Message{Sender: "valera", Recipient: "dima", Content: "/SHRUG ok"},
+			expectedReply:   &entities.CommandReply{Sender: "valera", Text: `ok ¯\_(ツ)_/¯`, Visibility: entities.VisibilityInChannel},
+		},
+		{
+			name:    "who_public",
+			channel: entities.PublicChannel,
+			message: entities.Message{Sender: "valera", Content: "/who"},
+			mockBehavior: func(b *mock_service.MockBotCommandRepository, p *mock_service.MockPresenceLister) {
+				p.EXPECT().Online().Return([]entities.Presence{
+					{Username: "dima", Status: entities.StatusAway},
+					{Username: "valera", Status: entities.StatusOnline},
+				})
+			},
+			expectedMessage: entities.Message{Sender: "valera", Content: "/who"},
+			expectedReply:   &entities.CommandReply{Text: "Online: valera\nAway: dima", Visibility: entities.VisibilityEphemeral},
+		},
+		{
+			name:    "who_private",
+			channel: entities.PrivateChannel,
+			message: entities.Message{Sender: "valera", Recipient: "dima", Content: "/who"},
+			mockBehavior: func(b *mock_service.MockBotCommandRepository, p *mock_service.MockPresenceLister) {
+				p.EXPECT().GetPresence([]string{"dima"}).Return([]entities.Presence{
+					{Username: "dima", Status: entities.StatusOffline, LastSeen: time.Date(2026, time.October, 19, 9, 30, 0, 0, time.UTC)},
+				}, nil)
+			},
+			expectedMessage: entities.Message{Sender: "valera", Recipient: "dima", Content: "/who"},
+			expectedReply:   &entities.CommandReply{Text: "dima is offline, last seen 2026-10-19 09:30 UTC.", Visibility: entities.VisibilityEphemeral},
+		},
+		{
+			name:    "unknown",
+			channel: entities.PublicChannel,
+			message: entities.Message{Sender: "valera", Content: "/deploy prod"},
+			mockBehavior: func(b *mock_service.MockBotCommandRepository, p *mock_service.MockPresenceLister) {
+				b.EXPECT().GetBotCommand("deploy").Return(entities.BotCommand{}, entities.ErrBotCommandNotFound)
+			},
+			expectedMessage: entities.Message{Sender: "valera", Content: "/deploy prod"},
+			expectedReply:   &entities.CommandReply{Text: "Unknown command /deploy. Type /help to see the commands.", Visibility: entities.VisibilityEphemeral},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bots := mock_service.NewMockBotCommandRepository(ctrl)
+			presence := mock_service.NewMockPresenceLister(ctrl)
+			testCase.mockBehavior(bots, presence)
+
+			r := NewCommandRouter(bots, presence, http.DefaultClient)
+
+			m, reply := r.Dispatch(testCase.channel, testCase.message)
+			assert.Equal(t, testCase.expectedMessage, m)
+			assert.Equal(t, testCase.expectedReply, reply)
+		})
+	}
+}
+
+func TestCommandRouter_DispatchBot(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		channel       string
+		status        int
+		reply         string
+		expectedReply *entities.CommandReply
+	}{
+		{
+			name:          "in_channel",
+			channel:       entities.PublicChannel,
+			status:        http.StatusOK,
+			reply:         `{"response_type":"in_channel","text":"deployed"}`,
+			expectedReply: &entities.CommandReply{Sender: "deployer", Text: "deployed", Visibility: entities.VisibilityInChannel},
+		},
+		{
+			name:          "in_channel_private",
+			channel:       entities.PrivateChannel,
+			status:        http.StatusOK,
+			reply:         `{"response_type":"in_channel","text":"deployed"}`,
+			expectedReply: &entities.CommandReply{Sender: "deployer", Text: "deployed", Visibility: entities.VisibilityEphemeral},
+		},
+		{
+			name:          "plain_text",
+			channel:       entities.PublicChannel,
+			status:        http.StatusOK,
+			reply:         "queued\n",
+			expectedReply: &entities.CommandReply{Sender: "deployer", Text: "queued", Visibility: entities.VisibilityEphemeral},
+		},
+		{
+			name:          "bot_failed",
+			channel:       entities.PublicChannel,
+			status:        http.StatusInternalServerError,
+			expectedReply: &entities.CommandReply{Sender: "deployer", Text: "deployer did not answer to /deploy.", Visibility: entities.VisibilityEphemeral},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				err := webhook.Verify("secret", r.Header.Get(webhook.SignatureHeader), r.Header.Get(webhook.TimestampHeader), body, now, time.Minute)
+				assert.NoError(t, err)
+				assert.Equal(t, commandEvent, r.Header.Get(webhook.EventHeader))
+
+				var req botRequest
+				assert.NoError(t, json.Unmarshal(body, &req))
+				assert.Equal(t, botRequest{Command: "/deploy", Text: "prod", UserName: "valera", Channel: testCase.channel, Recipient: req.Recipient}, req)
+
+				w.WriteHeader(testCase.status)
+				_, _ = io.WriteString(w, testCase.reply)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bots := mock_service.NewMockBotCommandRepository(ctrl)
+			bots.EXPECT().GetBotCommand("deploy").Return(entities.BotCommand{Command: "deploy", URL: server.URL, BotName: "deployer", Secret: "secret"}, nil)
+
+			r := NewCommandRouter(bots, mock_service.NewMockPresenceLister(ctrl), server.Client())
+			r.now = func() time.Time { return now }
+
+			_, reply := r.Dispatch(testCase.channel, entities.Message{Sender: "valera", Recipient: "dima", Content: "/deploy prod"})
+			assert.Equal(t, testCase.expectedReply, reply)
+		})
+	}
+}
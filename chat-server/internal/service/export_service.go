@@ -0,0 +1,297 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+//go:generate mockgen -source=export_service.go -destination=mocks/user_exporter_mock.go
+
+type UserExporter interface {
+	ExportUser(w io.Writer, username string) error
+	ExportMbox(w io.Writer, username string) error
+}
+
+type ExportRepository interface {
+	GetDataExport(username string) (entities.DataExport, error)
+	SaveDataExport(export entities.DataExport) error
+	FinishDataExport(export entities.DataExport) (bool, error)
+}
+
+const (
+	exportDataFile    = "messages.jsonl"
+	exportMailboxFile = "private.mbox"
+	exportKeyPrefix   = "exports/"
+)
+
+type ExportOptions struct {
+	Workers   int
+	QueueSize int
+	// TTL is how long a ready export can be downloaded.
+	TTL time.Duration
+}
+
+// ExportService prepares the archives of the users' own data in the
+// background: a zip with their messages as JSON lines and their private
+// conversations as a mailbox. The exports are kept in the repository, and
+// the archives are streamed to the blob store as they are written. The
+// queue is in memory only: an export left pending by a server which stopped
+// is given up after the TTL, and the user requests a new one.
+type ExportService struct {
+	repos    ExportRepository
+	exporter UserExporter
+	store    blobstore.BlobStore
+	opts     ExportOptions
+	now      func() time.Time
+	jobs     chan entities.DataExport
+
+	// mu makes the requests of a server take turns, so that a user asking
+	// twice at once gets one export.
+	mu sync.Mutex
+}
+
+func NewExportService(r ExportRepository, e UserExporter, store blobstore.BlobStore, opts ExportOptions) *ExportService {
+	return &ExportService{
+		repos:    r,
+		exporter: e,
+		store:    store,
+		opts:     opts,
+		now:      time.Now,
+		jobs:     make(chan entities.DataExport, opts.QueueSize),
+	}
+}
+
+// Request returns the export of the user's data, and queues a new one when
+// the user has none: never requested, failed, given up or expired.
+func (s *ExportService) Request(ctx context.Context, username string) (entities.DataExport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.repos.GetDataExport(username)
+	if err != nil && !errors.Is(err, entities.ErrDataExportNotFound) {
+		return entities.DataExport{}, err
+	}
+
+	found := err == nil
+	if found && s.live(current) {
+		return current, nil
+	}
+
+	id, err := newBlobID()
+	if err != nil {
+		return entities.DataExport{}, err
+	}
+
+	export := entities.DataExport{ID: id, Username: username, Status: entities.DataExportPending, RequestedAt: s.now()}
+	if err := s.repos.SaveDataExport(export); err != nil {
+		return entities.DataExport{}, err
+	}
+
+	if found && current.Status == entities.DataExportReady {
+		s.discard(ctx, current)
+	}
+
+	select {
+	case s.jobs <- export:
+		return export, nil
+	default:
+		// The export is failed at once, so that the next request queues
+		// a new one.
+		s.finish(ctx, export, entities.DataExportFailed)
+		return entities.DataExport{}, entities.ErrDataExportBusy
+	}
+}
+
+// Open returns the ready export of the user's data together with its
+// archive.
+func (s *ExportService) Open(ctx context.Context, username string) (entities.DataExport, io.ReadCloser, error) {
+	export, err := s.repos.GetDataExport(username)
+	if err != nil {
+		return entities.DataExport{}, nil, err
+	}
+
+	if !s.live(export) {
+		return entities.DataExport{}, nil, entities.ErrDataExportNotFound
+	}
+
+	if export.Status != entities.DataExportReady {
+		return entities.DataExport{}, nil, entities.ErrDataExportNotReady
+	}
+
+	content, err := s.store.Get(ctx, exportKey(export.ID))
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return entities.DataExport{}, nil, entities.ErrDataExportNotFound
+	} else if err != nil {
+		return entities.DataExport{}, nil, err
+	}
+
+	return export, content, nil
+}
+
+// Run starts the export workers and blocks until ctx is done, sweeping the
+// expired archives meanwhile. Exports still waiting in the queue at that
+// point are dropped.
+func (s *ExportService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case export := <-s.jobs:
+					s.process(ctx, export)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(s.opts.TTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// live reports whether the export is still worth waiting for or
+// downloading.
+func (s *ExportService) live(export entities.DataExport) bool {
+	switch export.Status {
+	case entities.DataExportPending:
+		return s.now().Before(export.RequestedAt.Add(s.opts.TTL))
+	case entities.DataExportReady:
+		return s.now().Before(export.ExpiresAt)
+	default:
+		return false
+	}
+}
+
+// process writes the archive through a pipe into the store, so that it is
+// never held in memory whole.
+func (s *ExportService) process(ctx context.Context, export entities.DataExport) {
+	pr, pw := io.Pipe()
+	w := &countingWriter{w: pw}
+
+	archived := make(chan struct{})
+	go func() {
+		defer close(archived)
+		pw.CloseWithError(s.archive(w, export.Username))
+	}()
+
+	err := s.store.Put(ctx, exportKey(export.ID), pr, -1, "application/zip")
+	// A store which failed before the end stops the archive too.
+	pr.CloseWithError(err)
+	<-archived
+
+	if err != nil {
+		log.Printf("exports: prepare %s of %s: %s", export.ID, export.Username, err)
+		s.finish(ctx, export, entities.DataExportFailed)
+		return
+	}
+
+	export.Size = w.n
+	export.ExpiresAt = s.now().Add(s.opts.TTL)
+	s.finish(ctx, export, entities.DataExportReady)
+}
+
+func (s *ExportService) archive(w io.Writer, username string) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name  string
+		write func(w io.Writer, username string) error
+	}{
+		{exportDataFile, s.exporter.ExportUser},
+		{exportMailboxFile, s.exporter.ExportMbox},
+	}
+
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: s.now()})
+		if err != nil {
+			return err
+		}
+
+		if err := file.write(fw, username); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// finish records the outcome of the export, unless the user has requested
+// another one since, whose archive then replaces this one.
+func (s *ExportService) finish(ctx context.Context, export entities.DataExport, status entities.DataExportStatus) {
+	export.Status = status
+
+	current, err := s.repos.FinishDataExport(export)
+	if err != nil {
+		log.Printf("exports: record %s of %s: %s", export.ID, export.Username, err)
+	}
+
+	if !current && status == entities.DataExportReady {
+		s.discard(ctx, export)
+	}
+}
+
+// sweep deletes the archives stored longer than the TTL ago, which have all
+// expired: the ones replaced or expired are deleted when that is seen, and
+// the sweep catches the ones whose deletion failed or never came.
+func (s *ExportService) sweep(ctx context.Context) {
+	blobs, err := s.store.List(ctx, exportKeyPrefix)
+	if err != nil {
+		log.Printf("exports: list the archives: %s", err)
+		return
+	}
+
+	expired := s.now().Add(-s.opts.TTL)
+	for _, blob := range blobs {
+		if !blob.Modified.After(expired) {
+			if err := s.store.Delete(ctx, blob.Key); err != nil {
+				log.Printf("exports: delete %s: %s", blob.Key, err)
+			}
+		}
+	}
+}
+
+func (s *ExportService) discard(ctx context.Context, export entities.DataExport) {
+	if err := s.store.Delete(ctx, exportKey(export.ID)); err != nil {
+		log.Printf("exports: delete %s of %s: %s", export.ID, export.Username, err)
+	}
+}
+
+func exportKey(id string) string {
+	return exportKeyPrefix + id + ".zip"
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+// clock is the time of a test, moved on by hand.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// exportRepo keeps the exports of a test in memory.
+type exportRepo struct {
+	mu      sync.Mutex
+	exports map[string]entities.DataExport
+}
+
+func newExportRepo() *exportRepo {
+	return &exportRepo{exports: make(map[string]entities.DataExport)}
+}
+
+func (r *exportRepo) GetDataExport(username string) (entities.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	export, ok := r.exports[username]
+	if !ok {
+		return entities.DataExport{}, entities.ErrDataExportNotFound
+	}
+
+	return export, nil
+}
+
+func (r *exportRepo) SaveDataExport(export entities.DataExport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.exports[export.Username] = export
+
+	return nil
+}
+
+func (r *exportRepo) FinishDataExport(export entities.DataExport) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exports[export.Username].ID != export.ID {
+		return false, nil
+	}
+
+	r.exports[export.Username] = export
+
+	return true, nil
+}
+
+func waitExport(t *testing.T, r *exportRepo, username string, status entities.DataExportStatus) entities.DataExport {
+	t.Helper()
+
+	var export entities.DataExport
+	require.Eventually(t, func() bool {
+		export, _ = r.GetDataExport(username)
+
+		return export.Status == status
+	}, time.Second, time.Millisecond)
+
+	return export
+}
+
+func TestExportService_Request(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	exporter := mock_service.NewMockUserExporter(ctrl)
+	exporter.EXPECT().ExportUser(gomock.Any(), "tester").DoAndReturn(func(w io.Writer, _ string) error {
+		_, err := io.WriteString(w, `{"type":"header","version":1}`+"\n")
+		return err
+	}).Times(2)
+	exporter.EXPECT().ExportMbox(gomock.Any(), "tester").DoAndReturn(func(w io.Writer, _ string) error {
+		_, err := io.WriteString(w, "From tester@chat Tue Mar  5 14:02:26 2024\n")
+		return err
+	}).Times(2)
+
+	c := &clock{now: time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)}
+
+	repo := newExportRepo()
+	s := NewExportService(repo, exporter, store, ExportOptions{Workers: 1, QueueSize: 1, TTL: time.Hour})
+	s.now = c.Now
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, _, err = s.Open(ctx, "tester")
+	assert.ErrorIs(t, err, entities.ErrDataExportNotFound)
+
+	export, err := s.Request(ctx, "tester")
+	require.NoError(t, err)
+	assert.Equal(t, entities.DataExportPending, export.Status)
+
+	_, _, err = s.Open(ctx, "tester")
+	assert.ErrorIs(t, err, entities.ErrDataExportNotReady)
+
+	// A pending export is not queued again.
+	again, err := s.Request(ctx, "tester")
+	require.NoError(t, err)
+	assert.Equal(t, export.ID, again.ID)
+
+	go s.Run(ctx)
+
+	ready := waitExport(t, repo, "tester", entities.DataExportReady)
+	assert.Equal(t, export.ID, ready.ID)
+	assert.Equal(t, c.Now().Add(time.Hour), ready.ExpiresAt)
+
+	opened, content, err := s.Open(ctx, "tester")
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, ready, opened)
+	assert.Equal(t, ready.Size, int64(len(data)))
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+	assert.Equal(t, "messages.jsonl", archive.File[0].Name)
+	assert.Equal(t, "private.mbox", archive.File[1].Name)
+
+	// An expired export is replaced by a new one, and its archive goes.
+	c.Add(time.Hour)
+
+	_, _, err = s.Open(ctx, "tester")
+	assert.ErrorIs(t, err, entities.ErrDataExportNotFound)
+
+	renewed, err := s.Request(ctx, "tester")
+	require.NoError(t, err)
+	assert.NotEqual(t, export.ID, renewed.ID)
+
+	_, err = store.Get(ctx, exportKey(export.ID))
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+
+	waitExport(t, repo, "tester", entities.DataExportReady)
+}
+
+func TestExportService_RequestFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	exporter := mock_service.NewMockUserExporter(ctrl)
+	exporter.EXPECT().ExportUser(gomock.Any(), "tester").Return(errors.New("database is down"))
+
+	repo := newExportRepo()
+	s := NewExportService(repo, exporter, store, ExportOptions{Workers: 1, QueueSize: 1, TTL: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = s.Request(ctx, "tester")
+	require.NoError(t, err)
+
+	// The queue is full until a worker takes the export.
+	_, err = s.Request(ctx, "valera")
+	assert.ErrorIs(t, err, entities.ErrDataExportBusy)
+
+	go s.Run(ctx)
+
+	failed := waitExport(t, repo, "tester", entities.DataExportFailed)
+
+	_, _, err = s.Open(ctx, "tester")
+	assert.ErrorIs(t, err, entities.ErrDataExportNotFound)
+
+	// A failed export is requested anew.
+	cancel()
+
+	export, err := s.Request(context.Background(), "tester")
+	require.NoError(t, err)
+	assert.NotEqual(t, failed.ID, export.ID)
+	assert.Equal(t, entities.DataExportPending, export.Status)
+}
+
+func TestExportService_Sweep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for _, key := range []string{exportKey("20261019-old"), "attachments/20261019-kept"} {
+		require.NoError(t, store.Put(ctx, key, strings.NewReader("data"), 4, "application/zip"))
+	}
+
+	c := &clock{now: time.Now()}
+
+	s := NewExportService(newExportRepo(), mock_service.NewMockUserExporter(ctrl), store,
+		ExportOptions{Workers: 1, QueueSize: 1, TTL: time.Hour})
+	s.now = c.Now
+
+	// The archive is younger than the TTL yet.
+	s.sweep(ctx)
+
+	blobs, err := store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, blobs, 2)
+
+	c.Add(time.Hour)
+	s.sweep(ctx)
+
+	_, err = store.Get(ctx, exportKey("20261019-old"))
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+
+	// Only the exports are swept.
+	content, err := store.Get(ctx, "attachments/20261019-kept")
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+}
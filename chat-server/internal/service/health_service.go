@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+// healthCheckTimeout bounds the ping of a health check, so a stuck database
+// fails the check rather than hanging it.
+const healthCheckTimeout = 2 * time.Second
+
+//go:generate mockgen -source=health_service.go -destination=mocks/health_repository_mock.go
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	Stats() entities.PoolStats
+}
+
+// HealthService checks the database. Without a repository, as with the
+// in-memory database, there is nothing to ping and no pool.
+type HealthService struct {
+	repos HealthRepository
+}
+
+func NewHealthService(r HealthRepository) *HealthService {
+	return &HealthService{repos: r}
+}
+
+func (s *HealthService) Check(ctx context.Context) error {
+	if s.repos == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	return s.repos.Ping(ctx)
+}
+
+func (s *HealthService) PoolStats() (entities.PoolStats, error) {
+	if s.repos == nil {
+		return entities.PoolStats{}, entities.ErrNoConnectionPool
+	}
+
+	return s.repos.Stats(), nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+)
+
+func TestHealthService_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_service.NewMockHealthRepository(ctrl)
+	errDown := errors.New("connection refused")
+
+	repo.EXPECT().Ping(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+
+		return errDown
+	})
+
+	assert.ErrorIs(t, NewHealthService(repo).Check(context.Background()), errDown)
+	assert.NoError(t, NewHealthService(nil).Check(context.Background()))
+}
+
+func TestHealthService_PoolStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_service.NewMockHealthRepository(ctrl)
+	repo.EXPECT().Stats().Return(entities.PoolStats{OpenConnections: 3, InUse: 1, Idle: 2})
+
+	stats, err := NewHealthService(repo).PoolStats()
+	assert.NoError(t, err)
+	assert.Equal(t, entities.PoolStats{OpenConnections: 3, InUse: 1, Idle: 2}, stats)
+
+	_, err = NewHealthService(nil).PoolStats()
+	assert.ErrorIs(t, err, entities.ErrNoConnectionPool)
+}
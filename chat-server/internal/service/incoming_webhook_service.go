@@ -0,0 +1,117 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/slackfmt"
+)
+
+const incomingTokenBytes = 32
+
+//go:generate mockgen -source=incoming_webhook_service.go -destination=mocks/incoming_webhook_repository_mock.go
+
+type IncomingWebhookRepository interface {
+	InsertIncomingWebhook(w entities.IncomingWebhook, tokenHash, botPassword string) (entities.IncomingWebhook, error)
+	GetIncomingWebhooks() ([]entities.IncomingWebhook, error)
+	GetIncomingWebhookByToken(tokenHash string) (entities.IncomingWebhook, error)
+	DeleteIncomingWebhook(id int) error
+	TouchIncomingWebhook(id int, usedAt time.Time) error
+}
+
+type PublicMessageSender interface {
+	PostPublicMessage(m entities.Message) error
+}
+
+// IncomingWebhookService lets external tools post to the public chat with
+// the payloads they send to Slack.
+type IncomingWebhookService struct {
+	repos  IncomingWebhookRepository
+	public PublicMessageSender
+	now    func() time.Time
+}
+
+func NewIncomingWebhookService(r IncomingWebhookRepository, public PublicMessageSender) *IncomingWebhookService {
+	return &IncomingWebhookService{repos: r, public: public, now: time.Now}
+}
+
+// Create registers the webhook with a new token, which is returned only
+// here. The bot gets a random password nobody knows, so it can not log in.
+func (s *IncomingWebhookService) Create(w entities.IncomingWebhook) (entities.IncomingWebhook, error) {
+	token, err := randomToken()
+	if err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	password, err := randomToken()
+	if err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	if w.Channel == "" {
+		w.Channel = entities.PublicChannel
+	}
+
+	w, err = s.repos.InsertIncomingWebhook(w, hashToken(token), password)
+	if err != nil {
+		return entities.IncomingWebhook{}, err
+	}
+
+	w.Token = token
+
+	return w, nil
+}
+
+func (s *IncomingWebhookService) List() ([]entities.IncomingWebhook, error) {
+	return s.repos.GetIncomingWebhooks()
+}
+
+func (s *IncomingWebhookService) Revoke(id int) error {
+	return s.repos.DeleteIncomingWebhook(id)
+}
+
+// Post sends the payload to the webhook's channel as its bot. The text is
+// posted as is, even when it looks like a command. The username of the
+// payload is ignored, like Slack does for app webhooks, so a tool can not
+// speak for a person.
+func (s *IncomingWebhookService) Post(token string, p slackfmt.Payload) error {
+	w, err := s.repos.GetIncomingWebhookByToken(hashToken(token))
+	if err != nil {
+		return err
+	}
+
+	content := p.Content()
+	if content == "" {
+		return entities.ErrNoText
+	}
+
+	if err := s.public.PostPublicMessage(entities.Message{Sender: w.BotName, Content: content}); err != nil {
+		return err
+	}
+
+	if err := s.repos.TouchIncomingWebhook(w.ID, s.now()); err != nil {
+		log.Printf("incoming webhooks: touch %d: %s", w.ID, err)
+	}
+
+	return nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, incomingTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
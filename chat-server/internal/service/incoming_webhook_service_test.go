@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/slackfmt"
+)
+
+func TestIncomingWebhookService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_service.NewMockIncomingWebhookRepository(ctrl)
+	s := NewIncomingWebhookService(repo, mock_service.NewMockPublicMessageSender(ctrl))
+
+	var tokenHash string
+	repo.EXPECT().InsertIncomingWebhook(entities.IncomingWebhook{Name: "CI", Channel: entities.PublicChannel, BotName: "ci"}, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(w entities.IncomingWebhook, hash, password string) (entities.IncomingWebhook, error) {
+			tokenHash = hash
+			assert.Len(t, password, 43)
+			w.ID = 1
+			return w, nil
+		})
+
+	w, err := s.Create(entities.IncomingWebhook{Name: "CI", BotName: "ci"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, w.ID)
+	assert.Len(t, w.Token, 43)
+	assert.Equal(t, hashToken(w.Token), tokenHash)
+}
+
+func TestIncomingWebhookService_Post(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockIncomingWebhookRepository, p *mock_service.MockPublicMessageSender)
+
+	usedAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	webhook := entities.IncomingWebhook{ID: 3, BotName: "ci", Channel: entities.PublicChannel}
+
+	testTable := []struct {
+		name          string
+		payload       slackfmt.Payload
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:    "ok",
+			payload: slackfmt.Payload{Text: "build <https://ci/7|#7> passed, <@valera>", Username: "valera"},
+			mockBehavior: func(r *mock_service.MockIncomingWebhookRepository, p *mock_service.MockPublicMessageSender) {
+				r.EXPECT().GetIncomingWebhookByToken(hashToken("token")).Return(webhook, nil)
+				p.EXPECT().PostPublicMessage(entities.Message{Sender: "ci", Content: "build #7 (https://ci/7) passed, @valera"}).Return(nil)
+				r.EXPECT().TouchIncomingWebhook(3, usedAt).Return(nil)
+			},
+		},
+		{
+			name:    "unknown_token",
+			payload: slackfmt.Payload{Text: "hi"},
+			mockBehavior: func(r *mock_service.MockIncomingWebhookRepository, p *mock_service.MockPublicMessageSender) {
+				r.EXPECT().GetIncomingWebhookByToken(hashToken("token")).Return(entities.IncomingWebhook{}, entities.ErrIncomingWebhookNotFound)
+			},
+			expectedError: entities.ErrIncomingWebhookNotFound,
+		},
+		{
+			name:    "no_text",
+			payload: slackfmt.Payload{Text: " "},
+			mockBehavior: func(r *mock_service.MockIncomingWebhookRepository, p *mock_service.MockPublicMessageSender) {
+				r.EXPECT().GetIncomingWebhookByToken(hashToken("token")).Return(webhook, nil)
+			},
+			expectedError: entities.ErrNoText,
+		},
+		{
+			name:    "send_error",
+			payload: slackfmt.Payload{Text: "hi"},
+			mockBehavior: func(r *mock_service.MockIncomingWebhookRepository, p *mock_service.MockPublicMessageSender) {
+				r.EXPECT().GetIncomingWebhookByToken(hashToken("token")).Return(webhook, nil)
+				p.EXPECT().PostPublicMessage(entities.Message{Sender: "ci", Content: "hi"}).Return(errors.New("storage error"))
+			},
+			expectedError: errors.New("storage error"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_service.NewMockIncomingWebhookRepository(ctrl)
+			public := mock_service.NewMockPublicMessageSender(ctrl)
+			testCase.mockBehavior(repo, public)
+
+			s := NewIncomingWebhookService(repo, public)
+			s.now = func() time.Time { return usedAt }
+
+			assert.Equal(t, testCase.expectedError, s.Post("token", testCase.payload))
+		})
+	}
+}
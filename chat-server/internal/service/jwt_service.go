@@ -18,15 +18,17 @@ type AuthJWTRepository interface {
 }
 
 type JwtService struct {
-	repos AuthJWTRepository
+	repos    AuthJWTRepository
+	units    UnitOfWork
+	accounts AccountChecker
 }
 
-func NewJWTService(r AuthJWTRepository) *JwtService {
-	return &JwtService{repos: r}
+func NewJWTService(r AuthJWTRepository, units UnitOfWork, accounts AccountChecker) *JwtService {
+	return &JwtService{repos: r, units: units, accounts: accounts}
 }
 
 func (s *JwtService) CreateUser(username, password string) (string, error) {
-	if err := s.repos.InsertUser(username, password); err != nil {
+	if err := insertUser(s.units, username, password); err != nil {
 		return "", err
 	}
 
@@ -47,6 +49,10 @@ func (s *JwtService) UserIdentity(token interface{}) (string, error) {
 	}
 
 	if claims, ok := parsedToken.Claims.(*jwt.StandardClaims); ok && parsedToken.Valid {
+		if err := s.accounts.CheckAccount(claims.Subject); err != nil {
+			return "", err
+		}
+
 		return claims.Subject, nil
 	}
 
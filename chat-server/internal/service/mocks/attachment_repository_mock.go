@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: attachment_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockAttachmentRepository is a mock of AttachmentRepository interface.
+type MockAttachmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentRepositoryMockRecorder
+}
+
+// MockAttachmentRepositoryMockRecorder is the mock recorder for MockAttachmentRepository.
+type MockAttachmentRepositoryMockRecorder struct {
+	mock *MockAttachmentRepository
+}
+
+// NewMockAttachmentRepository creates a new mock instance.
+func NewMockAttachmentRepository(ctrl *gomock.Controller) *MockAttachmentRepository {
+	mock := &MockAttachmentRepository{ctrl: ctrl}
+	mock.recorder = &MockAttachmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentRepository) EXPECT() *MockAttachmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAttachment mocks base method.
+func (m *MockAttachmentRepository) GetAttachment(id string) (entities.Attachment, entities.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachment", id)
+	ret0, _ := ret[0].(entities.Attachment)
+	ret1, _ := ret[1].(entities.Message)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAttachment indicates an expected call of GetAttachment.
+func (mr *MockAttachmentRepositoryMockRecorder) GetAttachment(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachment", reflect.TypeOf((*MockAttachmentRepository)(nil).GetAttachment), id)
+}
+
+// MockPublicChatSender is a mock of PublicChatSender interface.
+type MockPublicChatSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublicChatSenderMockRecorder
+}
+
+// MockPublicChatSenderMockRecorder is the mock recorder for MockPublicChatSender.
+type MockPublicChatSenderMockRecorder struct {
+	mock *MockPublicChatSender
+}
+
+// NewMockPublicChatSender creates a new mock instance.
+func NewMockPublicChatSender(ctrl *gomock.Controller) *MockPublicChatSender {
+	mock := &MockPublicChatSender{ctrl: ctrl}
+	mock.recorder = &MockPublicChatSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublicChatSender) EXPECT() *MockPublicChatSenderMockRecorder {
+	return m.recorder
+}
+
+// SendPublicMessage mocks base method.
+func (m_2 *MockPublicChatSender) SendPublicMessage(m entities.Message) (entities.SendResult, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendPublicMessage", m)
+	ret0, _ := ret[0].(entities.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPublicMessage indicates an expected call of SendPublicMessage.
+func (mr *MockPublicChatSenderMockRecorder) SendPublicMessage(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPublicMessage", reflect.TypeOf((*MockPublicChatSender)(nil).SendPublicMessage), m)
+}
+
+// MockPrivateChatSender is a mock of PrivateChatSender interface.
+type MockPrivateChatSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrivateChatSenderMockRecorder
+}
+
+// MockPrivateChatSenderMockRecorder is the mock recorder for MockPrivateChatSender.
+type MockPrivateChatSenderMockRecorder struct {
+	mock *MockPrivateChatSender
+}
+
+// NewMockPrivateChatSender creates a new mock instance.
+func NewMockPrivateChatSender(ctrl *gomock.Controller) *MockPrivateChatSender {
+	mock := &MockPrivateChatSender{ctrl: ctrl}
+	mock.recorder = &MockPrivateChatSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrivateChatSender) EXPECT() *MockPrivateChatSenderMockRecorder {
+	return m.recorder
+}
+
+// SendPrivateMessage mocks base method.
+func (m_2 *MockPrivateChatSender) SendPrivateMessage(m entities.Message) (entities.SendResult, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendPrivateMessage", m)
+	ret0, _ := ret[0].(entities.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPrivateMessage indicates an expected call of SendPrivateMessage.
+func (mr *MockPrivateChatSenderMockRecorder) SendPrivateMessage(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPrivateMessage", reflect.TypeOf((*MockPrivateChatSender)(nil).SendPrivateMessage), m)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: avatar_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockAvatarRepository is a mock of AvatarRepository interface.
+type MockAvatarRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAvatarRepositoryMockRecorder
+}
+
+// MockAvatarRepositoryMockRecorder is the mock recorder for MockAvatarRepository.
+type MockAvatarRepositoryMockRecorder struct {
+	mock *MockAvatarRepository
+}
+
+// NewMockAvatarRepository creates a new mock instance.
+func NewMockAvatarRepository(ctrl *gomock.Controller) *MockAvatarRepository {
+	mock := &MockAvatarRepository{ctrl: ctrl}
+	mock.recorder = &MockAvatarRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAvatarRepository) EXPECT() *MockAvatarRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAvatar mocks base method.
+func (m *MockAvatarRepository) GetAvatar(username string) (entities.Avatar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAvatar", username)
+	ret0, _ := ret[0].(entities.Avatar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAvatar indicates an expected call of GetAvatar.
+func (mr *MockAvatarRepositoryMockRecorder) GetAvatar(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvatar", reflect.TypeOf((*MockAvatarRepository)(nil).GetAvatar), username)
+}
+
+// SetAvatar mocks base method.
+func (m *MockAvatarRepository) SetAvatar(avatar entities.Avatar) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAvatar", avatar)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAvatar indicates an expected call of SetAvatar.
+func (mr *MockAvatarRepositoryMockRecorder) SetAvatar(avatar interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAvatar", reflect.TypeOf((*MockAvatarRepository)(nil).SetAvatar), avatar)
+}
@@ -0,0 +1,146 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: command_router.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockBotCommandRepository is a mock of BotCommandRepository interface.
+type MockBotCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotCommandRepositoryMockRecorder
+}
+
+// MockBotCommandRepositoryMockRecorder is the mock recorder for MockBotCommandRepository.
+type MockBotCommandRepositoryMockRecorder struct {
+	mock *MockBotCommandRepository
+}
+
+// NewMockBotCommandRepository creates a new mock instance.
+func NewMockBotCommandRepository(ctrl *gomock.Controller) *MockBotCommandRepository {
+	mock := &MockBotCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockBotCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotCommandRepository) EXPECT() *MockBotCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteBotCommand mocks base method.
+func (m *MockBotCommandRepository) DeleteBotCommand(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBotCommand", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBotCommand indicates an expected call of DeleteBotCommand.
+func (mr *MockBotCommandRepositoryMockRecorder) DeleteBotCommand(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBotCommand", reflect.TypeOf((*MockBotCommandRepository)(nil).DeleteBotCommand), id)
+}
+
+// GetBotCommand mocks base method.
+func (m *MockBotCommandRepository) GetBotCommand(command string) (entities.BotCommand, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBotCommand", command)
+	ret0, _ := ret[0].(entities.BotCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBotCommand indicates an expected call of GetBotCommand.
+func (mr *MockBotCommandRepositoryMockRecorder) GetBotCommand(command interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBotCommand", reflect.TypeOf((*MockBotCommandRepository)(nil).GetBotCommand), command)
+}
+
+// GetBotCommands mocks base method.
+func (m *MockBotCommandRepository) GetBotCommands() ([]entities.BotCommand, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBotCommands")
+	ret0, _ := ret[0].([]entities.BotCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBotCommands indicates an expected call of GetBotCommands.
+func (mr *MockBotCommandRepositoryMockRecorder) GetBotCommands() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBotCommands", reflect.TypeOf((*MockBotCommandRepository)(nil).GetBotCommands))
+}
+
+// InsertBotCommand mocks base method.
+func (m *MockBotCommandRepository) InsertBotCommand(c entities.BotCommand, botPassword string) (entities.BotCommand, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBotCommand", c, botPassword)
+	ret0, _ := ret[0].(entities.BotCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertBotCommand indicates an expected call of InsertBotCommand.
+func (mr *MockBotCommandRepositoryMockRecorder) InsertBotCommand(c, botPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBotCommand", reflect.TypeOf((*MockBotCommandRepository)(nil).InsertBotCommand), c, botPassword)
+}
+
+// MockPresenceLister is a mock of PresenceLister interface.
+type MockPresenceLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceListerMockRecorder
+}
+
+// MockPresenceListerMockRecorder is the mock recorder for MockPresenceLister.
+type MockPresenceListerMockRecorder struct {
+	mock *MockPresenceLister
+}
+
+// NewMockPresenceLister creates a new mock instance.
+func NewMockPresenceLister(ctrl *gomock.Controller) *MockPresenceLister {
+	mock := &MockPresenceLister{ctrl: ctrl}
+	mock.recorder = &MockPresenceListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceLister) EXPECT() *MockPresenceListerMockRecorder {
+	return m.recorder
+}
+
+// GetPresence mocks base method.
+func (m *MockPresenceLister) GetPresence(usernames []string) ([]entities.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPresence", usernames)
+	ret0, _ := ret[0].([]entities.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPresence indicates an expected call of GetPresence.
+func (mr *MockPresenceListerMockRecorder) GetPresence(usernames interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPresence", reflect.TypeOf((*MockPresenceLister)(nil).GetPresence), usernames)
+}
+
+// Online mocks base method.
+func (m *MockPresenceLister) Online() []entities.Presence {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Online")
+	ret0, _ := ret[0].([]entities.Presence)
+	return ret0
+}
+
+// Online indicates an expected call of Online.
+func (mr *MockPresenceListerMockRecorder) Online() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Online", reflect.TypeOf((*MockPresenceLister)(nil).Online))
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reaper_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockExpiryRepository is a mock of ExpiryRepository interface.
+type MockExpiryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpiryRepositoryMockRecorder
+}
+
+// MockExpiryRepositoryMockRecorder is the mock recorder for MockExpiryRepository.
+type MockExpiryRepositoryMockRecorder struct {
+	mock *MockExpiryRepository
+}
+
+// NewMockExpiryRepository creates a new mock instance.
+func NewMockExpiryRepository(ctrl *gomock.Controller) *MockExpiryRepository {
+	mock := &MockExpiryRepository{ctrl: ctrl}
+	mock.recorder = &MockExpiryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpiryRepository) EXPECT() *MockExpiryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// PurgeExpiredMessages mocks base method.
+func (m *MockExpiryRepository) PurgeExpiredMessages(now time.Time, limit int) (int, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredMessages", now, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PurgeExpiredMessages indicates an expected call of PurgeExpiredMessages.
+func (mr *MockExpiryRepositoryMockRecorder) PurgeExpiredMessages(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredMessages", reflect.TypeOf((*MockExpiryRepository)(nil).PurgeExpiredMessages), now, limit)
+}
@@ -0,0 +1,146 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: incoming_webhook_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockIncomingWebhookRepository is a mock of IncomingWebhookRepository interface.
+type MockIncomingWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIncomingWebhookRepositoryMockRecorder
+}
+
+// MockIncomingWebhookRepositoryMockRecorder is the mock recorder for MockIncomingWebhookRepository.
+type MockIncomingWebhookRepositoryMockRecorder struct {
+	mock *MockIncomingWebhookRepository
+}
+
+// NewMockIncomingWebhookRepository creates a new mock instance.
+func NewMockIncomingWebhookRepository(ctrl *gomock.Controller) *MockIncomingWebhookRepository {
+	mock := &MockIncomingWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockIncomingWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIncomingWebhookRepository) EXPECT() *MockIncomingWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteIncomingWebhook mocks base method.
+func (m *MockIncomingWebhookRepository) DeleteIncomingWebhook(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIncomingWebhook", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIncomingWebhook indicates an expected call of DeleteIncomingWebhook.
+func (mr *MockIncomingWebhookRepositoryMockRecorder) DeleteIncomingWebhook(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIncomingWebhook", reflect.TypeOf((*MockIncomingWebhookRepository)(nil).DeleteIncomingWebhook), id)
+}
+
+// GetIncomingWebhookByToken mocks base method.
+func (m *MockIncomingWebhookRepository) GetIncomingWebhookByToken(tokenHash string) (entities.IncomingWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncomingWebhookByToken", tokenHash)
+	ret0, _ := ret[0].(entities.IncomingWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncomingWebhookByToken indicates an expected call of GetIncomingWebhookByToken.
+func (mr *MockIncomingWebhookRepositoryMockRecorder) GetIncomingWebhookByToken(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncomingWebhookByToken", reflect.TypeOf((*MockIncomingWebhookRepository)(nil).GetIncomingWebhookByToken), tokenHash)
+}
+
+// GetIncomingWebhooks mocks base method.
+func (m *MockIncomingWebhookRepository) GetIncomingWebhooks() ([]entities.IncomingWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncomingWebhooks")
+	ret0, _ := ret[0].([]entities.IncomingWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncomingWebhooks indicates an expected call of GetIncomingWebhooks.
+func (mr *MockIncomingWebhookRepositoryMockRecorder) GetIncomingWebhooks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncomingWebhooks", reflect.TypeOf((*MockIncomingWebhookRepository)(nil).GetIncomingWebhooks))
+}
+
+// InsertIncomingWebhook mocks base method.
+func (m *MockIncomingWebhookRepository) InsertIncomingWebhook(w entities.IncomingWebhook, tokenHash, botPassword string) (entities.IncomingWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertIncomingWebhook", w, tokenHash, botPassword)
+	ret0, _ := ret[0].(entities.IncomingWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertIncomingWebhook indicates an expected call of InsertIncomingWebhook.
+func (mr *MockIncomingWebhookRepositoryMockRecorder) InsertIncomingWebhook(w, tokenHash, botPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertIncomingWebhook", reflect.TypeOf((*MockIncomingWebhookRepository)(nil).InsertIncomingWebhook), w, tokenHash, botPassword)
+}
+
+// TouchIncomingWebhook mocks base method.
+func (m *MockIncomingWebhookRepository) TouchIncomingWebhook(id int, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchIncomingWebhook", id, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchIncomingWebhook indicates an expected call of TouchIncomingWebhook.
+func (mr *MockIncomingWebhookRepositoryMockRecorder) TouchIncomingWebhook(id, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchIncomingWebhook", reflect.TypeOf((*MockIncomingWebhookRepository)(nil).TouchIncomingWebhook), id, usedAt)
+}
+
+// MockPublicMessageSender is a mock of PublicMessageSender interface.
+type MockPublicMessageSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublicMessageSenderMockRecorder
+}
+
+// MockPublicMessageSenderMockRecorder is the mock recorder for MockPublicMessageSender.
+type MockPublicMessageSenderMockRecorder struct {
+	mock *MockPublicMessageSender
+}
+
+// NewMockPublicMessageSender creates a new mock instance.
+func NewMockPublicMessageSender(ctrl *gomock.Controller) *MockPublicMessageSender {
+	mock := &MockPublicMessageSender{ctrl: ctrl}
+	mock.recorder = &MockPublicMessageSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublicMessageSender) EXPECT() *MockPublicMessageSenderMockRecorder {
+	return m.recorder
+}
+
+// PostPublicMessage mocks base method.
+func (m_2 *MockPublicMessageSender) PostPublicMessage(m entities.Message) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "PostPublicMessage", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostPublicMessage indicates an expected call of PostPublicMessage.
+func (mr *MockPublicMessageSenderMockRecorder) PostPublicMessage(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostPublicMessage", reflect.TypeOf((*MockPublicMessageSender)(nil).PostPublicMessage), m)
+}
@@ -0,0 +1,243 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: moderation_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockModerationRepository is a mock of ModerationRepository interface.
+type MockModerationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationRepositoryMockRecorder
+}
+
+// MockModerationRepositoryMockRecorder is the mock recorder for MockModerationRepository.
+type MockModerationRepositoryMockRecorder struct {
+	mock *MockModerationRepository
+}
+
+// NewMockModerationRepository creates a new mock instance.
+func NewMockModerationRepository(ctrl *gomock.Controller) *MockModerationRepository {
+	mock := &MockModerationRepository{ctrl: ctrl}
+	mock.recorder = &MockModerationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationRepository) EXPECT() *MockModerationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CloseReport mocks base method.
+func (m *MockModerationRepository) CloseReport(id int, status entities.ReportStatus, entry entities.AuditEntry) (entities.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseReport", id, status, entry)
+	ret0, _ := ret[0].(entities.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseReport indicates an expected call of CloseReport.
+func (mr *MockModerationRepositoryMockRecorder) CloseReport(id, status, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReport", reflect.TypeOf((*MockModerationRepository)(nil).CloseReport), id, status, entry)
+}
+
+// DeletePublicMessage mocks base method.
+func (m *MockModerationRepository) DeletePublicMessage(id int, entry entities.AuditEntry) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePublicMessage", id, entry)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePublicMessage indicates an expected call of DeletePublicMessage.
+func (mr *MockModerationRepositoryMockRecorder) DeletePublicMessage(id, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublicMessage", reflect.TypeOf((*MockModerationRepository)(nil).DeletePublicMessage), id, entry)
+}
+
+// GetActiveSanctions mocks base method.
+func (m *MockModerationRepository) GetActiveSanctions(username string, now time.Time) ([]entities.Sanction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveSanctions", username, now)
+	ret0, _ := ret[0].([]entities.Sanction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveSanctions indicates an expected call of GetActiveSanctions.
+func (mr *MockModerationRepositoryMockRecorder) GetActiveSanctions(username, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveSanctions", reflect.TypeOf((*MockModerationRepository)(nil).GetActiveSanctions), username, now)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockModerationRepository) GetAuditLog(limit, offset int) ([]entities.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", limit, offset)
+	ret0, _ := ret[0].([]entities.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockModerationRepositoryMockRecorder) GetAuditLog(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockModerationRepository)(nil).GetAuditLog), limit, offset)
+}
+
+// GetReports mocks base method.
+func (m *MockModerationRepository) GetReports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReports", status, limit, offset)
+	ret0, _ := ret[0].([]entities.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReports indicates an expected call of GetReports.
+func (mr *MockModerationRepositoryMockRecorder) GetReports(status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReports", reflect.TypeOf((*MockModerationRepository)(nil).GetReports), status, limit, offset)
+}
+
+// GetSanctions mocks base method.
+func (m *MockModerationRepository) GetSanctions(now time.Time) ([]entities.Sanction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSanctions", now)
+	ret0, _ := ret[0].([]entities.Sanction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSanctions indicates an expected call of GetSanctions.
+func (mr *MockModerationRepositoryMockRecorder) GetSanctions(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSanctions", reflect.TypeOf((*MockModerationRepository)(nil).GetSanctions), now)
+}
+
+// InsertReport mocks base method.
+func (m *MockModerationRepository) InsertReport(report entities.Report) (entities.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReport", report)
+	ret0, _ := ret[0].(entities.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReport indicates an expected call of InsertReport.
+func (mr *MockModerationRepositoryMockRecorder) InsertReport(report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReport", reflect.TypeOf((*MockModerationRepository)(nil).InsertReport), report)
+}
+
+// LiftSanction mocks base method.
+func (m *MockModerationRepository) LiftSanction(username string, kind entities.SanctionKind, entry entities.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LiftSanction", username, kind, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LiftSanction indicates an expected call of LiftSanction.
+func (mr *MockModerationRepositoryMockRecorder) LiftSanction(username, kind, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LiftSanction", reflect.TypeOf((*MockModerationRepository)(nil).LiftSanction), username, kind, entry)
+}
+
+// SetSanction mocks base method.
+func (m *MockModerationRepository) SetSanction(s entities.Sanction, entry entities.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSanction", s, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSanction indicates an expected call of SetSanction.
+func (mr *MockModerationRepositoryMockRecorder) SetSanction(s, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSanction", reflect.TypeOf((*MockModerationRepository)(nil).SetSanction), s, entry)
+}
+
+// MockModerationGuard is a mock of ModerationGuard interface.
+type MockModerationGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationGuardMockRecorder
+}
+
+// MockModerationGuardMockRecorder is the mock recorder for MockModerationGuard.
+type MockModerationGuardMockRecorder struct {
+	mock *MockModerationGuard
+}
+
+// NewMockModerationGuard creates a new mock instance.
+func NewMockModerationGuard(ctrl *gomock.Controller) *MockModerationGuard {
+	mock := &MockModerationGuard{ctrl: ctrl}
+	mock.recorder = &MockModerationGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationGuard) EXPECT() *MockModerationGuardMockRecorder {
+	return m.recorder
+}
+
+// CanPost mocks base method.
+func (m *MockModerationGuard) CanPost(user string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanPost", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CanPost indicates an expected call of CanPost.
+func (mr *MockModerationGuardMockRecorder) CanPost(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanPost", reflect.TypeOf((*MockModerationGuard)(nil).CanPost), user)
+}
+
+// MockAccountChecker is a mock of AccountChecker interface.
+type MockAccountChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountCheckerMockRecorder
+}
+
+// MockAccountCheckerMockRecorder is the mock recorder for MockAccountChecker.
+type MockAccountCheckerMockRecorder struct {
+	mock *MockAccountChecker
+}
+
+// NewMockAccountChecker creates a new mock instance.
+func NewMockAccountChecker(ctrl *gomock.Controller) *MockAccountChecker {
+	mock := &MockAccountChecker{ctrl: ctrl}
+	mock.recorder = &MockAccountCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountChecker) EXPECT() *MockAccountCheckerMockRecorder {
+	return m.recorder
+}
+
+// CheckAccount mocks base method.
+func (m *MockAccountChecker) CheckAccount(user string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckAccount", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckAccount indicates an expected call of CheckAccount.
+func (mr *MockAccountCheckerMockRecorder) CheckAccount(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAccount", reflect.TypeOf((*MockAccountChecker)(nil).CheckAccount), user)
+}
@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presence_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPresenceRepository is a mock of PresenceRepository interface.
+type MockPresenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceRepositoryMockRecorder
+}
+
+// MockPresenceRepositoryMockRecorder is the mock recorder for MockPresenceRepository.
+type MockPresenceRepositoryMockRecorder struct {
+	mock *MockPresenceRepository
+}
+
+// NewMockPresenceRepository creates a new mock instance.
+func NewMockPresenceRepository(ctrl *gomock.Controller) *MockPresenceRepository {
+	mock := &MockPresenceRepository{ctrl: ctrl}
+	mock.recorder = &MockPresenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceRepository) EXPECT() *MockPresenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetLastSeen mocks base method.
+func (m *MockPresenceRepository) GetLastSeen(usernames []string) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastSeen", usernames)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastSeen indicates an expected call of GetLastSeen.
+func (mr *MockPresenceRepositoryMockRecorder) GetLastSeen(usernames interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastSeen", reflect.TypeOf((*MockPresenceRepository)(nil).GetLastSeen), usernames)
+}
+
+// UpdateLastSeen mocks base method.
+func (m *MockPresenceRepository) UpdateLastSeen(username string, lastSeen time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastSeen", username, lastSeen)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastSeen indicates an expected call of UpdateLastSeen.
+func (mr *MockPresenceRepositoryMockRecorder) UpdateLastSeen(username, lastSeen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastSeen", reflect.TypeOf((*MockPresenceRepository)(nil).UpdateLastSeen), username, lastSeen)
+}
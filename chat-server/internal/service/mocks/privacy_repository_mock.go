@@ -0,0 +1,189 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: privacy_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockPrivacyRepository is a mock of PrivacyRepository interface.
+type MockPrivacyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrivacyRepositoryMockRecorder
+}
+
+// MockPrivacyRepositoryMockRecorder is the mock recorder for MockPrivacyRepository.
+type MockPrivacyRepositoryMockRecorder struct {
+	mock *MockPrivacyRepository
+}
+
+// NewMockPrivacyRepository creates a new mock instance.
+func NewMockPrivacyRepository(ctrl *gomock.Controller) *MockPrivacyRepository {
+	mock := &MockPrivacyRepository{ctrl: ctrl}
+	mock.recorder = &MockPrivacyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrivacyRepository) EXPECT() *MockPrivacyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// BlockUser mocks base method.
+func (m *MockPrivacyRepository) BlockUser(user, blocked string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUser", user, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUser indicates an expected call of BlockUser.
+func (mr *MockPrivacyRepositoryMockRecorder) BlockUser(user, blocked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUser", reflect.TypeOf((*MockPrivacyRepository)(nil).BlockUser), user, blocked)
+}
+
+// GetBlockedUsers mocks base method.
+func (m *MockPrivacyRepository) GetBlockedUsers(user string) ([]entities.BlockedUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockedUsers", user)
+	ret0, _ := ret[0].([]entities.BlockedUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockedUsers indicates an expected call of GetBlockedUsers.
+func (mr *MockPrivacyRepositoryMockRecorder) GetBlockedUsers(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockedUsers", reflect.TypeOf((*MockPrivacyRepository)(nil).GetBlockedUsers), user)
+}
+
+// GetPrivacySettings mocks base method.
+func (m *MockPrivacyRepository) GetPrivacySettings(user string) (entities.PrivacySettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrivacySettings", user)
+	ret0, _ := ret[0].(entities.PrivacySettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrivacySettings indicates an expected call of GetPrivacySettings.
+func (mr *MockPrivacyRepositoryMockRecorder) GetPrivacySettings(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivacySettings", reflect.TypeOf((*MockPrivacyRepository)(nil).GetPrivacySettings), user)
+}
+
+// IsBlocked mocks base method.
+func (m *MockPrivacyRepository) IsBlocked(user, other string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlocked", user, other)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlocked indicates an expected call of IsBlocked.
+func (mr *MockPrivacyRepositoryMockRecorder) IsBlocked(user, other interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlocked", reflect.TypeOf((*MockPrivacyRepository)(nil).IsBlocked), user, other)
+}
+
+// IsContact mocks base method.
+func (m *MockPrivacyRepository) IsContact(user, other string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsContact", user, other)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsContact indicates an expected call of IsContact.
+func (mr *MockPrivacyRepositoryMockRecorder) IsContact(user, other interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsContact", reflect.TypeOf((*MockPrivacyRepository)(nil).IsContact), user, other)
+}
+
+// UnblockUser mocks base method.
+func (m *MockPrivacyRepository) UnblockUser(user, blocked string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnblockUser", user, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnblockUser indicates an expected call of UnblockUser.
+func (mr *MockPrivacyRepositoryMockRecorder) UnblockUser(user, blocked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnblockUser", reflect.TypeOf((*MockPrivacyRepository)(nil).UnblockUser), user, blocked)
+}
+
+// UpdatePrivacySettings mocks base method.
+func (m *MockPrivacyRepository) UpdatePrivacySettings(user string, settings entities.PrivacySettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrivacySettings", user, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePrivacySettings indicates an expected call of UpdatePrivacySettings.
+func (mr *MockPrivacyRepositoryMockRecorder) UpdatePrivacySettings(user, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrivacySettings", reflect.TypeOf((*MockPrivacyRepository)(nil).UpdatePrivacySettings), user, settings)
+}
+
+// MockPrivacyGuard is a mock of PrivacyGuard interface.
+type MockPrivacyGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrivacyGuardMockRecorder
+}
+
+// MockPrivacyGuardMockRecorder is the mock recorder for MockPrivacyGuard.
+type MockPrivacyGuardMockRecorder struct {
+	mock *MockPrivacyGuard
+}
+
+// NewMockPrivacyGuard creates a new mock instance.
+func NewMockPrivacyGuard(ctrl *gomock.Controller) *MockPrivacyGuard {
+	mock := &MockPrivacyGuard{ctrl: ctrl}
+	mock.recorder = &MockPrivacyGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrivacyGuard) EXPECT() *MockPrivacyGuardMockRecorder {
+	return m.recorder
+}
+
+// CanMessage mocks base method.
+func (m *MockPrivacyGuard) CanMessage(sender, recipient string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanMessage", sender, recipient)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CanMessage indicates an expected call of CanMessage.
+func (mr *MockPrivacyGuardMockRecorder) CanMessage(sender, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanMessage", reflect.TypeOf((*MockPrivacyGuard)(nil).CanMessage), sender, recipient)
+}
+
+// HiddenSenders mocks base method.
+func (m *MockPrivacyGuard) HiddenSenders(viewer string) (map[string]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HiddenSenders", viewer)
+	ret0, _ := ret[0].(map[string]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HiddenSenders indicates an expected call of HiddenSenders.
+func (mr *MockPrivacyGuardMockRecorder) HiddenSenders(viewer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HiddenSenders", reflect.TypeOf((*MockPrivacyGuard)(nil).HiddenSenders), viewer)
+}
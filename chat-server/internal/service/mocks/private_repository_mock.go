@@ -34,19 +34,92 @@ func (m *MockPrivateRepository) EXPECT() *MockPrivateRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AcceptMessageRequest mocks base method.
+func (m *MockPrivateRepository) AcceptMessageRequest(user, partner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptMessageRequest", user, partner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcceptMessageRequest indicates an expected call of AcceptMessageRequest.
+func (mr *MockPrivateRepositoryMockRecorder) AcceptMessageRequest(user, partner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptMessageRequest", reflect.TypeOf((*MockPrivateRepository)(nil).AcceptMessageRequest), user, partner)
+}
+
+// DeclineMessageRequest mocks base method.
+func (m *MockPrivateRepository) DeclineMessageRequest(user, partner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineMessageRequest", user, partner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeclineMessageRequest indicates an expected call of DeclineMessageRequest.
+func (mr *MockPrivateRepositoryMockRecorder) DeclineMessageRequest(user, partner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineMessageRequest", reflect.TypeOf((*MockPrivateRepository)(nil).DeclineMessageRequest), user, partner)
+}
+
+// GetConversations mocks base method.
+func (m *MockPrivateRepository) GetConversations(user string, partners []string) ([]entities.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversations", user, partners)
+	ret0, _ := ret[0].([]entities.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversations indicates an expected call of GetConversations.
+func (mr *MockPrivateRepositoryMockRecorder) GetConversations(user, partners interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversations", reflect.TypeOf((*MockPrivateRepository)(nil).GetConversations), user, partners)
+}
+
+// GetInbox mocks base method.
+func (m *MockPrivateRepository) GetInbox(user string, limit, offset int) ([]entities.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInbox", user, limit, offset)
+	ret0, _ := ret[0].([]entities.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInbox indicates an expected call of GetInbox.
+func (mr *MockPrivateRepositoryMockRecorder) GetInbox(user, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInbox", reflect.TypeOf((*MockPrivateRepository)(nil).GetInbox), user, limit, offset)
+}
+
+// GetMessageRequests mocks base method.
+func (m *MockPrivateRepository) GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessageRequests", user, limit, offset)
+	ret0, _ := ret[0].([]entities.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessageRequests indicates an expected call of GetMessageRequests.
+func (mr *MockPrivateRepositoryMockRecorder) GetMessageRequests(user, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessageRequests", reflect.TypeOf((*MockPrivateRepository)(nil).GetMessageRequests), user, limit, offset)
+}
+
 // GetMessages mocks base method.
-func (m *MockPrivateRepository) GetMessages(sender, recipient string, limit, offset int) ([]entities.Message, error) {
+func (m *MockPrivateRepository) GetMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMessages", sender, recipient, limit, offset)
+	ret := m.ctrl.Call(m, "GetMessages", sender, recipient, limit, offset, order)
 	ret0, _ := ret[0].([]entities.Message)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMessages indicates an expected call of GetMessages.
-func (mr *MockPrivateRepositoryMockRecorder) GetMessages(sender, recipient, limit, offset interface{}) *gomock.Call {
+func (mr *MockPrivateRepositoryMockRecorder) GetMessages(sender, recipient, limit, offset, order interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockPrivateRepository)(nil).GetMessages), sender, recipient, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockPrivateRepository)(nil).GetMessages), sender, recipient, limit, offset, order)
 }
 
 // GetUsers mocks base method.
@@ -77,3 +150,17 @@ func (mr *MockPrivateRepositoryMockRecorder) InsertMessage(m interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertMessage", reflect.TypeOf((*MockPrivateRepository)(nil).InsertMessage), m)
 }
+
+// MarkAsRead mocks base method.
+func (m *MockPrivateRepository) MarkAsRead(reader, partner string, messageID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsRead", reader, partner, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsRead indicates an expected call of MarkAsRead.
+func (mr *MockPrivateRepositoryMockRecorder) MarkAsRead(reader, partner, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsRead", reflect.TypeOf((*MockPrivateRepository)(nil).MarkAsRead), reader, partner, messageID)
+}
@@ -35,18 +35,18 @@ func (m *MockPublicRepository) EXPECT() *MockPublicRepositoryMockRecorder {
 }
 
 // GetMessages mocks base method.
-func (m *MockPublicRepository) GetMessages(limit, offset int) ([]entities.Message, error) {
+func (m *MockPublicRepository) GetMessages(limit, offset int, order entities.Order) ([]entities.Message, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMessages", limit, offset)
+	ret := m.ctrl.Call(m, "GetMessages", limit, offset, order)
 	ret0, _ := ret[0].([]entities.Message)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMessages indicates an expected call of GetMessages.
-func (mr *MockPublicRepositoryMockRecorder) GetMessages(limit, offset interface{}) *gomock.Call {
+func (mr *MockPublicRepositoryMockRecorder) GetMessages(limit, offset, order interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockPublicRepository)(nil).GetMessages), limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockPublicRepository)(nil).GetMessages), limit, offset, order)
 }
 
 // InsertMessage mocks base method.
@@ -62,3 +62,41 @@ func (mr *MockPublicRepositoryMockRecorder) InsertMessage(m interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertMessage", reflect.TypeOf((*MockPublicRepository)(nil).InsertMessage), m)
 }
+
+// MockCommandDispatcher is a mock of CommandDispatcher interface.
+type MockCommandDispatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommandDispatcherMockRecorder
+}
+
+// MockCommandDispatcherMockRecorder is the mock recorder for MockCommandDispatcher.
+type MockCommandDispatcherMockRecorder struct {
+	mock *MockCommandDispatcher
+}
+
+// NewMockCommandDispatcher creates a new mock instance.
+func NewMockCommandDispatcher(ctrl *gomock.Controller) *MockCommandDispatcher {
+	mock := &MockCommandDispatcher{ctrl: ctrl}
+	mock.recorder = &MockCommandDispatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommandDispatcher) EXPECT() *MockCommandDispatcherMockRecorder {
+	return m.recorder
+}
+
+// Dispatch mocks base method.
+func (m_2 *MockCommandDispatcher) Dispatch(channel string, m entities.Message) (entities.Message, *entities.CommandReply) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Dispatch", channel, m)
+	ret0, _ := ret[0].(entities.Message)
+	ret1, _ := ret[1].(*entities.CommandReply)
+	return ret0, ret1
+}
+
+// Dispatch indicates an expected call of Dispatch.
+func (mr *MockCommandDispatcherMockRecorder) Dispatch(channel, m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dispatch", reflect.TypeOf((*MockCommandDispatcher)(nil).Dispatch), channel, m)
+}
@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reminder_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockReminderRepository is a mock of ReminderRepository interface.
+type MockReminderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReminderRepositoryMockRecorder
+}
+
+// MockReminderRepositoryMockRecorder is the mock recorder for MockReminderRepository.
+type MockReminderRepositoryMockRecorder struct {
+	mock *MockReminderRepository
+}
+
+// NewMockReminderRepository creates a new mock instance.
+func NewMockReminderRepository(ctrl *gomock.Controller) *MockReminderRepository {
+	mock := &MockReminderRepository{ctrl: ctrl}
+	mock.recorder = &MockReminderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReminderRepository) EXPECT() *MockReminderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AdvanceReminder mocks base method.
+func (m *MockReminderRepository) AdvanceReminder(id int, due, next time.Time, posted bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceReminder", id, due, next, posted)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvanceReminder indicates an expected call of AdvanceReminder.
+func (mr *MockReminderRepositoryMockRecorder) AdvanceReminder(id, due, next, posted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceReminder", reflect.TypeOf((*MockReminderRepository)(nil).AdvanceReminder), id, due, next, posted)
+}
+
+// DeleteReminder mocks base method.
+func (m *MockReminderRepository) DeleteReminder(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReminder", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReminder indicates an expected call of DeleteReminder.
+func (mr *MockReminderRepositoryMockRecorder) DeleteReminder(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReminder", reflect.TypeOf((*MockReminderRepository)(nil).DeleteReminder), id)
+}
+
+// GetDueReminders mocks base method.
+func (m *MockReminderRepository) GetDueReminders(now time.Time, limit int) ([]entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueReminders", now, limit)
+	ret0, _ := ret[0].([]entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueReminders indicates an expected call of GetDueReminders.
+func (mr *MockReminderRepositoryMockRecorder) GetDueReminders(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueReminders", reflect.TypeOf((*MockReminderRepository)(nil).GetDueReminders), now, limit)
+}
+
+// GetReminder mocks base method.
+func (m *MockReminderRepository) GetReminder(id int) (entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminder", id)
+	ret0, _ := ret[0].(entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminder indicates an expected call of GetReminder.
+func (mr *MockReminderRepositoryMockRecorder) GetReminder(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminder", reflect.TypeOf((*MockReminderRepository)(nil).GetReminder), id)
+}
+
+// GetReminders mocks base method.
+func (m *MockReminderRepository) GetReminders() ([]entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminders")
+	ret0, _ := ret[0].([]entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminders indicates an expected call of GetReminders.
+func (mr *MockReminderRepositoryMockRecorder) GetReminders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminders", reflect.TypeOf((*MockReminderRepository)(nil).GetReminders))
+}
+
+// InsertReminder mocks base method.
+func (m *MockReminderRepository) InsertReminder(rem entities.Reminder, senderPassword string) (entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReminder", rem, senderPassword)
+	ret0, _ := ret[0].(entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReminder indicates an expected call of InsertReminder.
+func (mr *MockReminderRepositoryMockRecorder) InsertReminder(rem, senderPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReminder", reflect.TypeOf((*MockReminderRepository)(nil).InsertReminder), rem, senderPassword)
+}
+
+// PauseReminder mocks base method.
+func (m *MockReminderRepository) PauseReminder(id int) (entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseReminder", id)
+	ret0, _ := ret[0].(entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseReminder indicates an expected call of PauseReminder.
+func (mr *MockReminderRepositoryMockRecorder) PauseReminder(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseReminder", reflect.TypeOf((*MockReminderRepository)(nil).PauseReminder), id)
+}
+
+// ResumeReminder mocks base method.
+func (m *MockReminderRepository) ResumeReminder(id int, nextRunAt time.Time) (entities.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeReminder", id, nextRunAt)
+	ret0, _ := ret[0].(entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeReminder indicates an expected call of ResumeReminder.
+func (mr *MockReminderRepositoryMockRecorder) ResumeReminder(id, nextRunAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeReminder", reflect.TypeOf((*MockReminderRepository)(nil).ResumeReminder), id, nextRunAt)
+}
@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: retention_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockRetentionRepository is a mock of RetentionRepository interface.
+type MockRetentionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRetentionRepositoryMockRecorder
+}
+
+// MockRetentionRepositoryMockRecorder is the mock recorder for MockRetentionRepository.
+type MockRetentionRepositoryMockRecorder struct {
+	mock *MockRetentionRepository
+}
+
+// NewMockRetentionRepository creates a new mock instance.
+func NewMockRetentionRepository(ctrl *gomock.Controller) *MockRetentionRepository {
+	mock := &MockRetentionRepository{ctrl: ctrl}
+	mock.recorder = &MockRetentionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRetentionRepository) EXPECT() *MockRetentionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeletePrivateMessages mocks base method.
+func (m *MockRetentionRepository) DeletePrivateMessages(messages []entities.Message, before time.Time) (int, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePrivateMessages", messages, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DeletePrivateMessages indicates an expected call of DeletePrivateMessages.
+func (mr *MockRetentionRepositoryMockRecorder) DeletePrivateMessages(messages, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePrivateMessages", reflect.TypeOf((*MockRetentionRepository)(nil).DeletePrivateMessages), messages, before)
+}
+
+// DeletePublicMessages mocks base method.
+func (m *MockRetentionRepository) DeletePublicMessages(ids []int, before time.Time) (int, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePublicMessages", ids, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DeletePublicMessages indicates an expected call of DeletePublicMessages.
+func (mr *MockRetentionRepositoryMockRecorder) DeletePublicMessages(ids, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublicMessages", reflect.TypeOf((*MockRetentionRepository)(nil).DeletePublicMessages), ids, before)
+}
+
+// GetLegalHolds mocks base method.
+func (m *MockRetentionRepository) GetLegalHolds() ([]entities.LegalHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLegalHolds")
+	ret0, _ := ret[0].([]entities.LegalHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLegalHolds indicates an expected call of GetLegalHolds.
+func (mr *MockRetentionRepositoryMockRecorder) GetLegalHolds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLegalHolds", reflect.TypeOf((*MockRetentionRepository)(nil).GetLegalHolds))
+}
+
+// GetPrivateMessagesBefore mocks base method.
+func (m *MockRetentionRepository) GetPrivateMessagesBefore(before time.Time, limit int) ([]entities.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrivateMessagesBefore", before, limit)
+	ret0, _ := ret[0].([]entities.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrivateMessagesBefore indicates an expected call of GetPrivateMessagesBefore.
+func (mr *MockRetentionRepositoryMockRecorder) GetPrivateMessagesBefore(before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivateMessagesBefore", reflect.TypeOf((*MockRetentionRepository)(nil).GetPrivateMessagesBefore), before, limit)
+}
+
+// GetPublicMessagesBefore mocks base method.
+func (m *MockRetentionRepository) GetPublicMessagesBefore(before time.Time, limit int) ([]entities.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicMessagesBefore", before, limit)
+	ret0, _ := ret[0].([]entities.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicMessagesBefore indicates an expected call of GetPublicMessagesBefore.
+func (mr *MockRetentionRepositoryMockRecorder) GetPublicMessagesBefore(before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicMessagesBefore", reflect.TypeOf((*MockRetentionRepository)(nil).GetPublicMessagesBefore), before, limit)
+}
+
+// LiftLegalHold mocks base method.
+func (m *MockRetentionRepository) LiftLegalHold(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LiftLegalHold", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LiftLegalHold indicates an expected call of LiftLegalHold.
+func (mr *MockRetentionRepositoryMockRecorder) LiftLegalHold(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LiftLegalHold", reflect.TypeOf((*MockRetentionRepository)(nil).LiftLegalHold), username)
+}
+
+// PlaceLegalHold mocks base method.
+func (m *MockRetentionRepository) PlaceLegalHold(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceLegalHold", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PlaceLegalHold indicates an expected call of PlaceLegalHold.
+func (mr *MockRetentionRepositoryMockRecorder) PlaceLegalHold(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceLegalHold", reflect.TypeOf((*MockRetentionRepository)(nil).PlaceLegalHold), username)
+}
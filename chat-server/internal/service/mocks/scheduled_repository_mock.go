@@ -0,0 +1,146 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scheduled_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockScheduledMessageRepository is a mock of ScheduledMessageRepository interface.
+type MockScheduledMessageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduledMessageRepositoryMockRecorder
+}
+
+// MockScheduledMessageRepositoryMockRecorder is the mock recorder for MockScheduledMessageRepository.
+type MockScheduledMessageRepositoryMockRecorder struct {
+	mock *MockScheduledMessageRepository
+}
+
+// NewMockScheduledMessageRepository creates a new mock instance.
+func NewMockScheduledMessageRepository(ctrl *gomock.Controller) *MockScheduledMessageRepository {
+	mock := &MockScheduledMessageRepository{ctrl: ctrl}
+	mock.recorder = &MockScheduledMessageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduledMessageRepository) EXPECT() *MockScheduledMessageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimScheduledMessages mocks base method.
+func (m *MockScheduledMessageRepository) ClaimScheduledMessages(now time.Time, limit int) ([]entities.ScheduledMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimScheduledMessages", now, limit)
+	ret0, _ := ret[0].([]entities.ScheduledMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimScheduledMessages indicates an expected call of ClaimScheduledMessages.
+func (mr *MockScheduledMessageRepositoryMockRecorder) ClaimScheduledMessages(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimScheduledMessages", reflect.TypeOf((*MockScheduledMessageRepository)(nil).ClaimScheduledMessages), now, limit)
+}
+
+// DeleteScheduledMessage mocks base method.
+func (m *MockScheduledMessageRepository) DeleteScheduledMessage(sender string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteScheduledMessage", sender, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteScheduledMessage indicates an expected call of DeleteScheduledMessage.
+func (mr *MockScheduledMessageRepositoryMockRecorder) DeleteScheduledMessage(sender, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScheduledMessage", reflect.TypeOf((*MockScheduledMessageRepository)(nil).DeleteScheduledMessage), sender, id)
+}
+
+// FinishScheduledMessage mocks base method.
+func (m *MockScheduledMessageRepository) FinishScheduledMessage(id int, status entities.ScheduledStatus, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishScheduledMessage", id, status, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishScheduledMessage indicates an expected call of FinishScheduledMessage.
+func (mr *MockScheduledMessageRepositoryMockRecorder) FinishScheduledMessage(id, status, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishScheduledMessage", reflect.TypeOf((*MockScheduledMessageRepository)(nil).FinishScheduledMessage), id, status, lastError)
+}
+
+// GetScheduledMessages mocks base method.
+func (m *MockScheduledMessageRepository) GetScheduledMessages(sender string, limit, offset int) ([]entities.ScheduledMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledMessages", sender, limit, offset)
+	ret0, _ := ret[0].([]entities.ScheduledMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledMessages indicates an expected call of GetScheduledMessages.
+func (mr *MockScheduledMessageRepositoryMockRecorder) GetScheduledMessages(sender, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledMessages", reflect.TypeOf((*MockScheduledMessageRepository)(nil).GetScheduledMessages), sender, limit, offset)
+}
+
+// InsertScheduledMessage mocks base method.
+func (m_2 *MockScheduledMessageRepository) InsertScheduledMessage(m entities.ScheduledMessage) (entities.ScheduledMessage, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "InsertScheduledMessage", m)
+	ret0, _ := ret[0].(entities.ScheduledMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertScheduledMessage indicates an expected call of InsertScheduledMessage.
+func (mr *MockScheduledMessageRepositoryMockRecorder) InsertScheduledMessage(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertScheduledMessage", reflect.TypeOf((*MockScheduledMessageRepository)(nil).InsertScheduledMessage), m)
+}
+
+// MockPrivateMessageSender is a mock of PrivateMessageSender interface.
+type MockPrivateMessageSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrivateMessageSenderMockRecorder
+}
+
+// MockPrivateMessageSenderMockRecorder is the mock recorder for MockPrivateMessageSender.
+type MockPrivateMessageSenderMockRecorder struct {
+	mock *MockPrivateMessageSender
+}
+
+// NewMockPrivateMessageSender creates a new mock instance.
+func NewMockPrivateMessageSender(ctrl *gomock.Controller) *MockPrivateMessageSender {
+	mock := &MockPrivateMessageSender{ctrl: ctrl}
+	mock.recorder = &MockPrivateMessageSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrivateMessageSender) EXPECT() *MockPrivateMessageSenderMockRecorder {
+	return m.recorder
+}
+
+// PostPrivateMessage mocks base method.
+func (m_2 *MockPrivateMessageSender) PostPrivateMessage(m entities.Message) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "PostPrivateMessage", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostPrivateMessage indicates an expected call of PostPrivateMessage.
+func (mr *MockPrivateMessageSenderMockRecorder) PostPrivateMessage(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostPrivateMessage", reflect.TypeOf((*MockPrivateMessageSender)(nil).PostPrivateMessage), m)
+}
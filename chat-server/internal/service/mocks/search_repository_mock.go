@@ -0,0 +1,88 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: search_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockPublicSearchRepository is a mock of PublicSearchRepository interface.
+type MockPublicSearchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublicSearchRepositoryMockRecorder
+}
+
+// MockPublicSearchRepositoryMockRecorder is the mock recorder for MockPublicSearchRepository.
+type MockPublicSearchRepositoryMockRecorder struct {
+	mock *MockPublicSearchRepository
+}
+
+// NewMockPublicSearchRepository creates a new mock instance.
+func NewMockPublicSearchRepository(ctrl *gomock.Controller) *MockPublicSearchRepository {
+	mock := &MockPublicSearchRepository{ctrl: ctrl}
+	mock.recorder = &MockPublicSearchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublicSearchRepository) EXPECT() *MockPublicSearchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SearchMessages mocks base method.
+func (m *MockPublicSearchRepository) SearchMessages(q entities.SearchQuery) ([]entities.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchMessages", q)
+	ret0, _ := ret[0].([]entities.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchMessages indicates an expected call of SearchMessages.
+func (mr *MockPublicSearchRepositoryMockRecorder) SearchMessages(q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMessages", reflect.TypeOf((*MockPublicSearchRepository)(nil).SearchMessages), q)
+}
+
+// MockPrivateSearchRepository is a mock of PrivateSearchRepository interface.
+type MockPrivateSearchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrivateSearchRepositoryMockRecorder
+}
+
+// MockPrivateSearchRepositoryMockRecorder is the mock recorder for MockPrivateSearchRepository.
+type MockPrivateSearchRepositoryMockRecorder struct {
+	mock *MockPrivateSearchRepository
+}
+
+// NewMockPrivateSearchRepository creates a new mock instance.
+func NewMockPrivateSearchRepository(ctrl *gomock.Controller) *MockPrivateSearchRepository {
+	mock := &MockPrivateSearchRepository{ctrl: ctrl}
+	mock.recorder = &MockPrivateSearchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrivateSearchRepository) EXPECT() *MockPrivateSearchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SearchMessages mocks base method.
+func (m *MockPrivateSearchRepository) SearchMessages(user string, q entities.SearchQuery) ([]entities.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchMessages", user, q)
+	ret0, _ := ret[0].([]entities.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchMessages indicates an expected call of SearchMessages.
+func (mr *MockPrivateSearchRepositoryMockRecorder) SearchMessages(user, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMessages", reflect.TypeOf((*MockPrivateSearchRepository)(nil).SearchMessages), user, q)
+}
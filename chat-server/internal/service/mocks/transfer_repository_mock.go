@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transfer_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockTransferRepository is a mock of TransferRepository interface.
+type MockTransferRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferRepositoryMockRecorder
+}
+
+// MockTransferRepositoryMockRecorder is the mock recorder for MockTransferRepository.
+type MockTransferRepositoryMockRecorder struct {
+	mock *MockTransferRepository
+}
+
+// NewMockTransferRepository creates a new mock instance.
+func NewMockTransferRepository(ctrl *gomock.Controller) *MockTransferRepository {
+	mock := &MockTransferRepository{ctrl: ctrl}
+	mock.recorder = &MockTransferRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferRepository) EXPECT() *MockTransferRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetPartners mocks base method.
+func (m *MockTransferRepository) GetPartners(username string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPartners", username)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPartners indicates an expected call of GetPartners.
+func (mr *MockTransferRepositoryMockRecorder) GetPartners(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPartners", reflect.TypeOf((*MockTransferRepository)(nil).GetPartners), username)
+}
+
+// GetPrivateMessages mocks base method.
+func (m *MockTransferRepository) GetPrivateMessages(user1, user2 string, afterID, limit int) ([]entities.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrivateMessages", user1, user2, afterID, limit)
+	ret0, _ := ret[0].([]entities.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrivateMessages indicates an expected call of GetPrivateMessages.
+func (mr *MockTransferRepositoryMockRecorder) GetPrivateMessages(user1, user2, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivateMessages", reflect.TypeOf((*MockTransferRepository)(nil).GetPrivateMessages), user1, user2, afterID, limit)
+}
+
+// GetPublicMessages mocks base method.
+func (m *MockTransferRepository) GetPublicMessages(afterID, limit int) ([]entities.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicMessages", afterID, limit)
+	ret0, _ := ret[0].([]entities.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicMessages indicates an expected call of GetPublicMessages.
+func (mr *MockTransferRepositoryMockRecorder) GetPublicMessages(afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicMessages", reflect.TypeOf((*MockTransferRepository)(nil).GetPublicMessages), afterID, limit)
+}
+
+// GetUsers mocks base method.
+func (m *MockTransferRepository) GetUsers(after string, limit int) ([]entities.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", after, limit)
+	ret0, _ := ret[0].([]entities.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockTransferRepositoryMockRecorder) GetUsers(after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockTransferRepository)(nil).GetUsers), after, limit)
+}
+
+// ImportPrivateMessages mocks base method.
+func (m *MockTransferRepository) ImportPrivateMessages(messages []entities.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportPrivateMessages", messages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportPrivateMessages indicates an expected call of ImportPrivateMessages.
+func (mr *MockTransferRepositoryMockRecorder) ImportPrivateMessages(messages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportPrivateMessages", reflect.TypeOf((*MockTransferRepository)(nil).ImportPrivateMessages), messages)
+}
+
+// ImportPublicMessages mocks base method.
+func (m *MockTransferRepository) ImportPublicMessages(messages []entities.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportPublicMessages", messages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportPublicMessages indicates an expected call of ImportPublicMessages.
+func (mr *MockTransferRepositoryMockRecorder) ImportPublicMessages(messages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportPublicMessages", reflect.TypeOf((*MockTransferRepository)(nil).ImportPublicMessages), messages)
+}
+
+// ImportUser mocks base method.
+func (m *MockTransferRepository) ImportUser(user entities.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportUser", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportUser indicates an expected call of ImportUser.
+func (mr *MockTransferRepositoryMockRecorder) ImportUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportUser", reflect.TypeOf((*MockTransferRepository)(nil).ImportUser), user)
+}
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: export_service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/vavelour/chat/internal/domain/entities"
+)
+
+// MockUserExporter is a mock of UserExporter interface.
+type MockUserExporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserExporterMockRecorder
+}
+
+// MockUserExporterMockRecorder is the mock recorder for MockUserExporter.
+type MockUserExporterMockRecorder struct {
+	mock *MockUserExporter
+}
+
+// NewMockUserExporter creates a new mock instance.
+func NewMockUserExporter(ctrl *gomock.Controller) *MockUserExporter {
+	mock := &MockUserExporter{ctrl: ctrl}
+	mock.recorder = &MockUserExporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserExporter) EXPECT() *MockUserExporterMockRecorder {
+	return m.recorder
+}
+
+// ExportMbox mocks base method.
+func (m *MockUserExporter) ExportMbox(w io.Writer, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportMbox", w, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportMbox indicates an expected call of ExportMbox.
+func (mr *MockUserExporterMockRecorder) ExportMbox(w, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportMbox", reflect.TypeOf((*MockUserExporter)(nil).ExportMbox), w, username)
+}
+
+// ExportUser mocks base method.
+func (m *MockUserExporter) ExportUser(w io.Writer, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUser", w, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportUser indicates an expected call of ExportUser.
+func (mr *MockUserExporterMockRecorder) ExportUser(w, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUser", reflect.TypeOf((*MockUserExporter)(nil).ExportUser), w, username)
+}
+
+// MockExportRepository is a mock of ExportRepository interface.
+type MockExportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportRepositoryMockRecorder
+}
+
+// MockExportRepositoryMockRecorder is the mock recorder for MockExportRepository.
+type MockExportRepositoryMockRecorder struct {
+	mock *MockExportRepository
+}
+
+// NewMockExportRepository creates a new mock instance.
+func NewMockExportRepository(ctrl *gomock.Controller) *MockExportRepository {
+	mock := &MockExportRepository{ctrl: ctrl}
+	mock.recorder = &MockExportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportRepository) EXPECT() *MockExportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FinishDataExport mocks base method.
+func (m *MockExportRepository) FinishDataExport(export entities.DataExport) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishDataExport", export)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishDataExport indicates an expected call of FinishDataExport.
+func (mr *MockExportRepositoryMockRecorder) FinishDataExport(export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishDataExport", reflect.TypeOf((*MockExportRepository)(nil).FinishDataExport), export)
+}
+
+// GetDataExport mocks base method.
+func (m *MockExportRepository) GetDataExport(username string) (entities.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExport", username)
+	ret0, _ := ret[0].(entities.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExport indicates an expected call of GetDataExport.
+func (mr *MockExportRepositoryMockRecorder) GetDataExport(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExport", reflect.TypeOf((*MockExportRepository)(nil).GetDataExport), username)
+}
+
+// SaveDataExport mocks base method.
+func (m *MockExportRepository) SaveDataExport(export entities.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDataExport", export)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDataExport indicates an expected call of SaveDataExport.
+func (mr *MockExportRepositoryMockRecorder) SaveDataExport(export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDataExport", reflect.TypeOf((*MockExportRepository)(nil).SaveDataExport), export)
+}
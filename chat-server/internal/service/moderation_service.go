@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+//go:generate mockgen -source=moderation_service.go -destination=mocks/moderation_repository_mock.go
+
+type ModerationRepository interface {
+	InsertReport(report entities.Report) (entities.Report, error)
+	GetReports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error)
+	CloseReport(id int, status entities.ReportStatus, entry entities.AuditEntry) (entities.Report, error)
+	DeletePublicMessage(id int, entry entities.AuditEntry) ([]string, error)
+	SetSanction(s entities.Sanction, entry entities.AuditEntry) error
+	LiftSanction(username string, kind entities.SanctionKind, entry entities.AuditEntry) error
+	GetActiveSanctions(username string, now time.Time) ([]entities.Sanction, error)
+	GetSanctions(now time.Time) ([]entities.Sanction, error)
+	GetAuditLog(limit, offset int) ([]entities.AuditEntry, error)
+}
+
+// ModerationGuard tells whether the user may post to the public chat. The
+// public service asks it before posting a message.
+type ModerationGuard interface {
+	CanPost(user string) error
+}
+
+// AccountChecker tells whether the user may use their account. The auth
+// services ask it on every identification.
+type AccountChecker interface {
+	CheckAccount(user string) error
+}
+
+var (
+	sanctionActions = map[entities.SanctionKind]entities.ModerationAction{
+		entities.SanctionMute:    entities.ActionMute,
+		entities.SanctionBan:     entities.ActionBan,
+		entities.SanctionSuspend: entities.ActionSuspend,
+	}
+	liftActions = map[entities.SanctionKind]entities.ModerationAction{
+		entities.SanctionMute:    entities.ActionUnmute,
+		entities.SanctionBan:     entities.ActionUnban,
+		entities.SanctionSuspend: entities.ActionUnsuspend,
+	}
+	sanctionErrors = map[entities.SanctionKind]error{
+		entities.SanctionMute:    entities.ErrMuted,
+		entities.SanctionBan:     entities.ErrBanned,
+		entities.SanctionSuspend: entities.ErrAccountSuspended,
+	}
+)
+
+type ModerationService struct {
+	repos ModerationRepository
+	store blobstore.BlobStore
+	now   func() time.Time
+}
+
+func NewModerationService(r ModerationRepository, store blobstore.BlobStore) *ModerationService {
+	return &ModerationService{repos: r, store: store, now: time.Now}
+}
+
+func (s *ModerationService) Report(reporter string, messageID int, reason string) (entities.Report, error) {
+	return s.repos.InsertReport(entities.Report{MessageID: messageID, Reporter: reporter, Reason: reason})
+}
+
+func (s *ModerationService) Reports(status entities.ReportStatus, limit, offset int) ([]entities.Report, error) {
+	if !status.Valid() {
+		return nil, entities.ErrInvalidReportStatus
+	}
+
+	return s.repos.GetReports(status, limit, offset)
+}
+
+// ResolveReport closes the report as handled. Acting on the message or its
+// sender is a separate action.
+func (s *ModerationService) ResolveReport(moderator string, id int, reason string) (entities.Report, error) {
+	entry := entities.AuditEntry{Moderator: moderator, Action: entities.ActionResolveReport, Reason: reason}
+
+	return s.repos.CloseReport(id, entities.ReportResolved, entry)
+}
+
+func (s *ModerationService) DismissReport(moderator string, id int, reason string) (entities.Report, error) {
+	entry := entities.AuditEntry{Moderator: moderator, Action: entities.ActionDismissReport, Reason: reason}
+
+	return s.repos.CloseReport(id, entities.ReportDismissed, entry)
+}
+
+// DeleteMessage removes the public message together with the files of its
+// attachments.
+func (s *ModerationService) DeleteMessage(ctx context.Context, moderator string, id int, reason string) error {
+	entry := entities.AuditEntry{Moderator: moderator, Action: entities.ActionDeleteMessage, Reason: reason}
+
+	attachments, err := s.repos.DeletePublicMessage(id, entry)
+	if err != nil {
+		return err
+	}
+
+	deleteBlobs(ctx, s.store, "moderation", attachments)
+
+	return nil
+}
+
+// Sanction puts the sanction of the kind on the user for the duration. A
+// zero duration makes a ban or a suspension permanent; a mute always needs
+// one.
+func (s *ModerationService) Sanction(moderator, username string, kind entities.SanctionKind, duration time.Duration, reason string) error {
+	if moderator == username {
+		return entities.ErrSanctionSelf
+	}
+
+	if kind == entities.SanctionMute && duration <= 0 {
+		return entities.ErrDurationRequired
+	}
+
+	sanction := entities.Sanction{Username: username, Kind: kind, Reason: reason, CreatedBy: moderator}
+	if duration > 0 {
+		sanction.Until = s.now().Add(duration)
+	}
+
+	entry := entities.AuditEntry{Moderator: moderator, Action: sanctionActions[kind], Reason: reason}
+
+	return s.repos.SetSanction(sanction, entry)
+}
+
+func (s *ModerationService) Lift(moderator, username string, kind entities.SanctionKind, reason string) error {
+	entry := entities.AuditEntry{Moderator: moderator, Action: liftActions[kind], Reason: reason}
+
+	return s.repos.LiftSanction(username, kind, entry)
+}
+
+func (s *ModerationService) Sanctions() ([]entities.Sanction, error) {
+	return s.repos.GetSanctions(s.now())
+}
+
+func (s *ModerationService) AuditLog(limit, offset int) ([]entities.AuditEntry, error) {
+	return s.repos.GetAuditLog(limit, offset)
+}
+
+// CanPost returns the error of the sanction that keeps the user from
+// posting to the public chat, the suspension first.
+func (s *ModerationService) CanPost(user string) error {
+	active, err := s.repos.GetActiveSanctions(user, s.now())
+	if err != nil {
+		return err
+	}
+
+	found := make(map[entities.SanctionKind]bool, len(active))
+	for _, sanction := range active {
+		found[sanction.Kind] = true
+	}
+
+	for _, kind := range []entities.SanctionKind{entities.SanctionSuspend, entities.SanctionBan, entities.SanctionMute} {
+		if found[kind] {
+			return sanctionErrors[kind]
+		}
+	}
+
+	return nil
+}
+
+func (s *ModerationService) CheckAccount(user string) error {
+	active, err := s.repos.GetActiveSanctions(user, s.now())
+	if err != nil {
+		return err
+	}
+
+	for _, sanction := range active {
+		if sanction.Kind == entities.SanctionSuspend {
+			return entities.ErrAccountSuspended
+		}
+	}
+
+	return nil
+}
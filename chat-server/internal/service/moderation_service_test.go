@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+func TestModerationService_CanPost(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		sanctions     []entities.Sanction
+		expectedError error
+		accountError  error
+	}{
+		{
+			name: "free",
+		},
+		{
+			name:          "muted",
+			sanctions:     []entities.Sanction{{Kind: entities.SanctionMute}},
+			expectedError: entities.ErrMuted,
+		},
+		{
+			name:          "banned_and_muted",
+			sanctions:     []entities.Sanction{{Kind: entities.SanctionMute}, {Kind: entities.SanctionBan}},
+			expectedError: entities.ErrBanned,
+		},
+		{
+			name:          "suspended",
+			sanctions:     []entities.Sanction{{Kind: entities.SanctionBan}, {Kind: entities.SanctionSuspend}},
+			expectedError: entities.ErrAccountSuspended,
+			accountError:  entities.ErrAccountSuspended,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_service.NewMockModerationRepository(ctrl)
+			repo.EXPECT().GetActiveSanctions("valera", now).Return(testCase.sanctions, nil).Times(2)
+
+			s := NewModerationService(repo, nil)
+			s.now = func() time.Time { return now }
+
+			assert.Equal(t, testCase.expectedError, s.CanPost("valera"))
+			assert.Equal(t, testCase.accountError, s.CheckAccount("valera"))
+		})
+	}
+}
+
+func TestModerationService_Sanction(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	type mockBehavior func(r *mock_service.MockModerationRepository)
+
+	testTable := []struct {
+		name          string
+		username      string
+		kind          entities.SanctionKind
+		duration      time.Duration
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:     "mute",
+			username: "valera",
+			kind:     entities.SanctionMute,
+			duration: time.Hour,
+			mockBehavior: func(r *mock_service.MockModerationRepository) {
+				r.EXPECT().SetSanction(
+					entities.Sanction{Username: "valera", Kind: entities.SanctionMute, Reason: "flood", Until: now.Add(time.Hour), CreatedBy: "admin"},
+					entities.AuditEntry{Moderator: "admin", Action: entities.ActionMute, Reason: "flood"}).Return(nil)
+			},
+		},
+		{
+			name:     "permanent_ban",
+			username: "valera",
+			kind:     entities.SanctionBan,
+			mockBehavior: func(r *mock_service.MockModerationRepository) {
+				r.EXPECT().SetSanction(
+					entities.Sanction{Username: "valera", Kind: entities.SanctionBan, Reason: "flood", CreatedBy: "admin"},
+					entities.AuditEntry{Moderator: "admin", Action: entities.ActionBan, Reason: "flood"}).Return(nil)
+			},
+		},
+		{
+			name:          "mute_without_duration",
+			username:      "valera",
+			kind:          entities.SanctionMute,
+			mockBehavior:  func(r *mock_service.MockModerationRepository) {},
+			expectedError: entities.ErrDurationRequired,
+		},
+		{
+			name:          "self",
+			username:      "admin",
+			kind:          entities.SanctionSuspend,
+			mockBehavior:  func(r *mock_service.MockModerationRepository) {},
+			expectedError: entities.ErrSanctionSelf,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_service.NewMockModerationRepository(ctrl)
+			testCase.mockBehavior(repo)
+
+			s := NewModerationService(repo, nil)
+			s.now = func() time.Time { return now }
+
+			err := s.Sanction("admin", testCase.username, testCase.kind, testCase.duration, "flood")
+			assert.Equal(t, testCase.expectedError, err)
+		})
+	}
+}
+
+func TestModerationService_DeleteMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, "a1", strings.NewReader("png"), 3, "image/png"))
+
+	entry := entities.AuditEntry{Moderator: "admin", Action: entities.ActionDeleteMessage, Reason: "spam"}
+
+	repo := mock_service.NewMockModerationRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().DeletePublicMessage(7, entry).Return([]string{"a1"}, nil),
+		repo.EXPECT().DeletePublicMessage(7, entry).Return(nil, entities.ErrMessageNotFound),
+	)
+
+	s := NewModerationService(repo, store)
+
+	require.NoError(t, s.DeleteMessage(ctx, "admin", 7, "spam"))
+	assert.ErrorIs(t, s.DeleteMessage(ctx, "admin", 7, "spam"), entities.ErrMessageNotFound)
+
+	_, err = store.Get(ctx, "a1")
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+}
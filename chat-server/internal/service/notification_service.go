@@ -0,0 +1,39 @@
+package service
+
+import "github.com/vavelour/chat/internal/domain/entities"
+
+//go:generate mockgen -source=notification_service.go -destination=mocks/notification_repository_mock.go
+
+type NotificationRepository interface {
+	GetNotifications(user string, unreadOnly bool, limit, offset int) ([]entities.Notification, error)
+	CountUnread(user string) (int, error)
+	MarkNotificationsRead(user string, ids []int) (int, error)
+}
+
+type NotificationService struct {
+	repos NotificationRepository
+}
+
+func NewNotificationService(r NotificationRepository) *NotificationService {
+	return &NotificationService{repos: r}
+}
+
+func (s *NotificationService) GetNotifications(user string, unreadOnly bool, limit, offset int) (entities.NotificationInbox, error) {
+	notifications, err := s.repos.GetNotifications(user, unreadOnly, limit, offset)
+	if err != nil {
+		return entities.NotificationInbox{}, err
+	}
+
+	unread, err := s.repos.CountUnread(user)
+	if err != nil {
+		return entities.NotificationInbox{}, err
+	}
+
+	return entities.NotificationInbox{Notifications: notifications, UnreadCount: unread}, nil
+}
+
+// MarkAsRead marks the given notifications as read, or all of them when ids
+// is empty.
+func (s *NotificationService) MarkAsRead(user string, ids []int) (int, error) {
+	return s.repos.MarkNotificationsRead(user, ids)
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+const typingEventsBuffer = 16
+
+//go:generate mockgen -source=presence_service.go -destination=mocks/presence_repository_mock.go
+
+type PresenceRepository interface {
+	UpdateLastSeen(username string, lastSeen time.Time) error
+	GetLastSeen(usernames []string) (map[string]time.Time, error)
+}
+
+type presenceState struct {
+	connections  int
+	lastActivity time.Time
+}
+
+type typingKey struct {
+	sender    string
+	recipient string
+}
+
+// PresenceService keeps presence and typing state in memory. Only the
+// last_seen timestamp is persisted, once a user disconnects or goes stale.
+type PresenceService struct {
+	repos       PresenceRepository
+	awayTimeout time.Duration
+	typingTTL   time.Duration
+
+	mu          sync.Mutex
+	users       map[string]*presenceState
+	typing      map[typingKey]time.Time
+	subscribers map[string]map[chan entities.TypingEvent]struct{}
+}
+
+func NewPresenceService(r PresenceRepository, awayTimeout, typingTTL time.Duration) *PresenceService {
+	return &PresenceService{
+		repos:       r,
+		awayTimeout: awayTimeout,
+		typingTTL:   typingTTL,
+		users:       make(map[string]*presenceState),
+		typing:      make(map[typingKey]time.Time),
+		subscribers: make(map[string]map[chan entities.TypingEvent]struct{}),
+	}
+}
+
+func (s *PresenceService) Touch(user string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state(user).lastActivity = time.Now()
+}
+
+// Connect registers a real-time connection for the user and returns the
+// stream of typing events addressed to them together with a func that
+// must be called once the connection is closed.
+func (s *PresenceService) Connect(user string) (<-chan entities.TypingEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state(user)
+	state.connections++
+	state.lastActivity = time.Now()
+
+	events := make(chan entities.TypingEvent, typingEventsBuffer)
+	if s.subscribers[user] == nil {
+		s.subscribers[user] = make(map[chan entities.TypingEvent]struct{})
+	}
+	s.subscribers[user][events] = struct{}{}
+
+	var once sync.Once
+
+	return events, func() {
+		once.Do(func() { s.disconnect(user, events) })
+	}
+}
+
+func (s *PresenceService) disconnect(user string, events chan entities.TypingEvent) {
+	s.mu.Lock()
+
+	delete(s.subscribers[user], events)
+	if len(s.subscribers[user]) == 0 {
+		delete(s.subscribers, user)
+	}
+	close(events)
+
+	state := s.state(user)
+	state.connections--
+	state.lastActivity = time.Now()
+	lastSeen := state.lastActivity
+	offline := state.connections == 0
+
+	s.mu.Unlock()
+
+	if offline {
+		if err := s.repos.UpdateLastSeen(user, lastSeen); err != nil {
+			log.Printf("presence: update last seen of %s: %s", user, err)
+		}
+	}
+}
+
+func (s *PresenceService) GetPresence(usernames []string) ([]entities.Presence, error) {
+	now := time.Now()
+	presence := make([]entities.Presence, len(usernames))
+	offline := make([]string, 0, len(usernames))
+
+	s.mu.Lock()
+	for i, username := range usernames {
+		presence[i] = entities.Presence{Username: username, Status: entities.StatusOffline}
+
+		state, ok := s.users[username]
+		if !ok {
+			offline = append(offline, username)
+			continue
+		}
+
+		presence[i].LastSeen = state.lastActivity
+		switch {
+		case now.Sub(state.lastActivity) <= s.awayTimeout:
+			presence[i].Status = entities.StatusOnline
+		case state.connections > 0:
+			presence[i].Status = entities.StatusAway
+		}
+	}
+	s.mu.Unlock()
+
+	if len(offline) == 0 {
+		return presence, nil
+	}
+
+	lastSeen, err := s.repos.GetLastSeen(offline)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range presence {
+		if t, ok := lastSeen[presence[i].Username]; ok && presence[i].LastSeen.IsZero() {
+			presence[i].LastSeen = t
+		}
+	}
+
+	return presence, nil
+}
+
+// Online returns the users who are online or away, sorted by name.
+func (s *PresenceService) Online() []entities.Presence {
+	now := time.Now()
+
+	s.mu.Lock()
+	presence := make([]entities.Presence, 0, len(s.users))
+	for username, state := range s.users {
+		p := entities.Presence{Username: username, LastSeen: state.lastActivity}
+		switch {
+		case now.Sub(state.lastActivity) <= s.awayTimeout:
+			p.Status = entities.StatusOnline
+		case state.connections > 0:
+			p.Status = entities.StatusAway
+		default:
+			continue
+		}
+
+		presence = append(presence, p)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(presence, func(i, j int) bool {
+		return presence[i].Username < presence[j].Username
+	})
+
+	return presence
+}
+
+// SetTyping marks the user as typing to the recipient, or in the public chat
+// when the recipient is empty, and notifies connected users.
+func (s *PresenceService) SetTyping(user, recipient string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state(user).lastActivity = time.Now()
+
+	event := entities.TypingEvent{Sender: user, Recipient: recipient, ExpiresAt: time.Now().Add(s.typingTTL)}
+	s.typing[typingKey{sender: user, recipient: recipient}] = event.ExpiresAt
+
+	if recipient != "" {
+		s.publish(recipient, event)
+		return
+	}
+
+	for subscriber := range s.subscribers {
+		if subscriber != user {
+			s.publish(subscriber, event)
+		}
+	}
+}
+
+// GetTyping returns who is typing to the user in the private chat with the
+// partner, or in the public chat when the partner is empty.
+func (s *PresenceService) GetTyping(user, partner string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	typing := make([]string, 0)
+
+	if partner != "" {
+		if expiresAt, ok := s.typing[typingKey{sender: partner, recipient: user}]; ok && now.Before(expiresAt) {
+			typing = append(typing, partner)
+		}
+
+		return typing
+	}
+
+	for key, expiresAt := range s.typing {
+		if key.recipient == "" && key.sender != user && now.Before(expiresAt) {
+			typing = append(typing, key.sender)
+		}
+	}
+
+	sort.Strings(typing)
+
+	return typing
+}
+
+// Run periodically drops expired typing events and persists last_seen of
+// users that went offline without holding a real-time connection.
+func (s *PresenceService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.awayTimeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep()
+		}
+	}
+}
+
+func (s *PresenceService) sweep() {
+	now := time.Now()
+	stale := make(map[string]time.Time)
+
+	s.mu.Lock()
+	for key, expiresAt := range s.typing {
+		if !now.Before(expiresAt) {
+			delete(s.typing, key)
+		}
+	}
+
+	for username, state := range s.users {
+		if state.connections == 0 && now.Sub(state.lastActivity) > s.awayTimeout {
+			stale[username] = state.lastActivity
+			delete(s.users, username)
+		}
+	}
+	s.mu.Unlock()
+
+	for username, lastSeen := range stale {
+		if err := s.repos.UpdateLastSeen(username, lastSeen); err != nil {
+			log.Printf("presence: update last seen of %s: %s", username, err)
+		}
+	}
+}
+
+func (s *PresenceService) state(user string) *presenceState {
+	state, ok := s.users[user]
+	if !ok {
+		state = &presenceState{}
+		s.users[user] = state
+	}
+
+	return state
+}
+
+func (s *PresenceService) publish(user string, event entities.TypingEvent) {
+	for events := range s.subscribers[user] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+//go:generate mockgen -source=privacy_service.go -destination=mocks/privacy_repository_mock.go
+
+type PrivacyRepository interface {
+	GetPrivacySettings(user string) (entities.PrivacySettings, error)
+	UpdatePrivacySettings(user string, settings entities.PrivacySettings) error
+	BlockUser(user, blocked string) error
+	UnblockUser(user, blocked string) error
+	GetBlockedUsers(user string) ([]entities.BlockedUser, error)
+	IsBlocked(user, other string) (bool, error)
+	IsContact(user, other string) (bool, error)
+}
+
+// PrivacyGuard tells who may reach whom. The private service asks it
+// before sending a message, the public one before showing the feed.
+type PrivacyGuard interface {
+	CanMessage(sender, recipient string) error
+	HiddenSenders(viewer string) (map[string]bool, error)
+}
+
+type PrivacyService struct {
+	repos PrivacyRepository
+}
+
+func NewPrivacyService(r PrivacyRepository) *PrivacyService {
+	return &PrivacyService{repos: r}
+}
+
+func (s *PrivacyService) GetSettings(user string) (entities.PrivacySettings, error) {
+	return s.repos.GetPrivacySettings(user)
+}
+
+func (s *PrivacyService) UpdateSettings(user string, settings entities.PrivacySettings) error {
+	if !settings.DMPolicy.Valid() {
+		return entities.ErrInvalidDMPolicy
+	}
+
+	return s.repos.UpdatePrivacySettings(user, settings)
+}
+
+func (s *PrivacyService) Block(user, blocked string) error {
+	if user == blocked {
+		return entities.ErrBlockSelf
+	}
+
+	return s.repos.BlockUser(user, blocked)
+}
+
+func (s *PrivacyService) Unblock(user, blocked string) error {
+	return s.repos.UnblockUser(user, blocked)
+}
+
+func (s *PrivacyService) GetBlocked(user string) ([]entities.BlockedUser, error) {
+	return s.repos.GetBlockedUsers(user)
+}
+
+// CanMessage returns ErrMessageNotAllowed if the recipient does not accept
+// private messages from the sender. A block and the privacy settings give
+// the same error, so the sender can not tell that they were blocked.
+func (s *PrivacyService) CanMessage(sender, recipient string) error {
+	if sender == recipient {
+		return nil
+	}
+
+	blocked, err := s.repos.IsBlocked(recipient, sender)
+	if err != nil {
+		return err
+	}
+
+	if blocked {
+		return entities.ErrMessageNotAllowed
+	}
+
+	settings, err := s.repos.GetPrivacySettings(recipient)
+	if err != nil {
+		return err
+	}
+
+	switch settings.DMPolicy {
+	case entities.DMPolicyAnyone:
+		return nil
+	case entities.DMPolicyContacts:
+		contact, err := s.repos.IsContact(recipient, sender)
+		if err != nil {
+			return err
+		}
+
+		if !contact {
+			return entities.ErrMessageNotAllowed
+		}
+
+		return nil
+	default:
+		return entities.ErrMessageNotAllowed
+	}
+}
+
+// HiddenSenders returns the users whose public messages the viewer does not
+// want to see: the ones they blocked, unless they chose to still see them.
+func (s *PrivacyService) HiddenSenders(viewer string) (map[string]bool, error) {
+	settings, err := s.repos.GetPrivacySettings(viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.HideBlockedInFeed {
+		return nil, nil
+	}
+
+	blocked, err := s.repos.GetBlockedUsers(viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden := make(map[string]bool, len(blocked))
+	for _, b := range blocked {
+		hidden[b.Username] = true
+	}
+
+	return hidden, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+)
+
+func TestPrivacyService_CanMessage(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockPrivacyRepository)
+
+	testTable := []struct {
+		name          string
+		sender        string
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:         "self",
+			sender:       "tester",
+			mockBehavior: func(r *mock_service.MockPrivacyRepository) {},
+		},
+		{
+			name:   "anyone",
+			sender: "valera",
+			mockBehavior: func(r *mock_service.MockPrivacyRepository) {
+				r.EXPECT().IsBlocked("tester", "valera").Return(false, nil)
+				r.EXPECT().GetPrivacySettings("tester").Return(entities.DefaultPrivacySettings(), nil)
+			},
+		},
+		{
+			name:   "blocked",
+			sender: "valera",
+			mockBehavior: func(r *mock_service.MockPrivacyRepository) {
+				r.EXPECT().IsBlocked("tester", "valera").Return(true, nil)
+			},
+			expectedError: entities.ErrMessageNotAllowed,
+		},
+		{
+			name:   "contact",
+			sender: "valera",
+			mockBehavior: func(r *mock_service.MockPrivacyRepository) {
+				r.EXPECT().IsBlocked("tester", "valera").Return(false, nil)
+				r.EXPECT().GetPrivacySettings("tester").Return(entities.PrivacySettings{DMPolicy: entities.DMPolicyContacts}, nil)
+				r.EXPECT().IsContact("tester", "valera").Return(true, nil)
+			},
+		},
+		{
+			name:   "not_contact",
+			sender: "valera",
+			mockBehavior: func(r *mock_service.MockPrivacyRepository) {
+				r.EXPECT().IsBlocked("tester", "valera").Return(false, nil)
+				r.EXPECT().GetPrivacySettings("tester").Return(entities.PrivacySettings{DMPolicy: entities.DMPolicyContacts}, nil)
+				r.EXPECT().IsContact("tester", "valera").Return(false, nil)
+			},
+			expectedError: entities.ErrMessageNotAllowed,
+		},
+		{
+			name:   "nobody",
+			sender: "valera",
+			mockBehavior: func(r *mock_service.MockPrivacyRepository) {
+				r.EXPECT().IsBlocked("tester", "valera").Return(false, nil)
+				r.EXPECT().GetPrivacySettings("tester").Return(entities.PrivacySettings{DMPolicy: entities.DMPolicyNobody}, nil)
+			},
+			expectedError: entities.ErrMessageNotAllowed,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_service.NewMockPrivacyRepository(ctrl)
+			testCase.mockBehavior(repo)
+
+			err := NewPrivacyService(repo).CanMessage(testCase.sender, "tester")
+			assert.Equal(t, testCase.expectedError, err)
+		})
+	}
+}
+
+func TestPrivacyService_HiddenSenders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_service.NewMockPrivacyRepository(ctrl)
+	s := NewPrivacyService(repo)
+
+	repo.EXPECT().GetPrivacySettings("tester").Return(entities.DefaultPrivacySettings(), nil)
+	repo.EXPECT().GetBlockedUsers("tester").Return([]entities.BlockedUser{{Username: "valera"}}, nil)
+
+	hidden, err := s.HiddenSenders("tester")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"valera": true}, hidden)
+
+	// The blocked users stay in the feed if the viewer wants to see them.
+	repo.EXPECT().GetPrivacySettings("tester").Return(entities.PrivacySettings{DMPolicy: entities.DMPolicyAnyone}, nil)
+
+	hidden, err = s.HiddenSenders("tester")
+	assert.NoError(t, err)
+	assert.Empty(t, hidden)
+}
+
+func TestPrivacyService_Block(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_service.NewMockPrivacyRepository(ctrl)
+	s := NewPrivacyService(repo)
+
+	assert.Equal(t, entities.ErrBlockSelf, s.Block("tester", "tester"))
+	assert.Equal(t, entities.ErrInvalidDMPolicy, s.UpdateSettings("tester", entities.PrivacySettings{DMPolicy: "friends"}))
+}
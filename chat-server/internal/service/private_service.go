@@ -6,33 +6,87 @@ import (
 
 type PrivateRepository interface {
 	InsertMessage(m entities.Message) error
-	GetMessages(sender, recipient string, limit, offset int) ([]entities.Message, error)
+	GetMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error)
 	GetUsers(user string) ([]string, error)
+	MarkAsRead(reader, partner string, messageID int) error
+	GetConversations(user string, partners []string) ([]entities.Conversation, error)
+	GetInbox(user string, limit, offset int) ([]entities.Conversation, error)
+	GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error)
+	AcceptMessageRequest(user, partner string) error
+	DeclineMessageRequest(user, partner string) error
 }
 
 //go:generate mockgen -source=private_service.go -destination=mocks/private_repository_mock.go
 
 type PrivateService struct {
-	repos PrivateRepository
+	repos    PrivateRepository
+	privacy  PrivacyGuard
+	commands CommandDispatcher
 }
 
-func NewPrivateService(r PrivateRepository) *PrivateService {
-	return &PrivateService{repos: r}
+func NewPrivateService(r PrivateRepository, privacy PrivacyGuard, commands CommandDispatcher) *PrivateService {
+	return &PrivateService{repos: r, privacy: privacy, commands: commands}
 }
 
-func (s *PrivateService) SendPrivateMessage(m entities.Message) error {
+// SendPrivateMessage runs the command the message holds or sends it to the
+// recipient. Only the sender can post in-channel replies here. Nothing is
+// sent to a recipient who does not accept messages from the sender.
+func (s *PrivateService) SendPrivateMessage(m entities.Message) (entities.SendResult, error) {
+	if err := s.privacy.CanMessage(m.Sender, m.Recipient); err != nil {
+		return entities.SendResult{}, err
+	}
+
+	m, reply := s.commands.Dispatch(entities.PrivateChannel, m)
+	if reply == nil {
+		return entities.SendResult{}, s.repos.InsertMessage(m)
+	}
+
+	if reply.Visibility == entities.VisibilityInChannel && reply.Text != "" {
+		return entities.SendResult{Command: true}, s.repos.InsertMessage(entities.Message{Sender: m.Sender, Recipient: m.Recipient, Content: reply.Text})
+	}
+
+	return ephemeralResult(m, *reply), nil
+}
+
+// PostPrivateMessage sends the message to the recipient as is, if the
+// recipient accepts messages from the sender.
+func (s *PrivateService) PostPrivateMessage(m entities.Message) error {
+	if err := s.privacy.CanMessage(m.Sender, m.Recipient); err != nil {
+		return err
+	}
+
 	return s.repos.InsertMessage(m)
 }
 
-func (s *PrivateService) GetPrivateMessages(sender, recipient string, limit, offset int) ([]entities.Message, error) {
-	return s.repos.GetMessages(sender, recipient, limit, offset)
+func (s *PrivateService) GetPrivateMessages(sender, recipient string, limit, offset int, order entities.Order) ([]entities.Message, error) {
+	return s.repos.GetMessages(sender, recipient, limit, offset, order)
 }
 
-func (s *PrivateService) ViewUsers(user string) ([]string, error) {
+func (s *PrivateService) ViewUsers(user string) ([]entities.Conversation, error) {
 	list, err := s.repos.GetUsers(user)
 	if err != nil {
 		return nil, err
 	}
 
-	return list, nil
+	return s.repos.GetConversations(user, list)
+}
+
+func (s *PrivateService) ReadPrivateMessages(reader, partner string, messageID int) error {
+	return s.repos.MarkAsRead(reader, partner, messageID)
+}
+
+func (s *PrivateService) GetInbox(user string, limit, offset int) ([]entities.Conversation, error) {
+	return s.repos.GetInbox(user, limit, offset)
+}
+
+func (s *PrivateService) GetMessageRequests(user string, limit, offset int) ([]entities.Conversation, error) {
+	return s.repos.GetMessageRequests(user, limit, offset)
+}
+
+func (s *PrivateService) AcceptMessageRequest(user, partner string) error {
+	return s.repos.AcceptMessageRequest(user, partner)
+}
+
+func (s *PrivateService) DeclineMessageRequest(user, partner string) error {
+	return s.repos.DeclineMessageRequest(user, partner)
 }
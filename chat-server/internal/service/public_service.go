@@ -1,26 +1,134 @@
 package service
 
-import "github.com/vavelour/chat/internal/domain/entities"
+import (
+	"regexp"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+const maxMentions = 20
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w+(?:[.-]\w+)*)`)
 
 //go:generate mockgen -source=public_service.go -destination=mocks/public_repository_mock.go
 
 type PublicRepository interface {
 	InsertMessage(m entities.Message) error
-	GetMessages(limit, offset int) ([]entities.Message, error)
+	GetMessages(limit, offset int, order entities.Order) ([]entities.Message, error)
+}
+
+type CommandDispatcher interface {
+	Dispatch(channel string, m entities.Message) (entities.Message, *entities.CommandReply)
 }
 
 type PublicService struct {
-	repos PublicRepository
+	repos      PublicRepository
+	users      AuthRepository
+	privacy    PrivacyGuard
+	moderation ModerationGuard
+	commands   CommandDispatcher
 }
 
-func NewPublicService(r PublicRepository) *PublicService {
-	return &PublicService{repos: r}
+func NewPublicService(r PublicRepository, users AuthRepository, privacy PrivacyGuard, moderation ModerationGuard, commands CommandDispatcher) *PublicService {
+	return &PublicService{repos: r, users: users, privacy: privacy, moderation: moderation, commands: commands}
 }
 
-func (s *PublicService) SendPublicMessage(m entities.Message) error {
+// SendPublicMessage runs the command the message holds or posts it to the
+// public chat. A muted or banned sender can do neither.
+func (s *PublicService) SendPublicMessage(m entities.Message) (entities.SendResult, error) {
+	if err := s.moderation.CanPost(m.Sender); err != nil {
+		return entities.SendResult{}, err
+	}
+
+	m, reply := s.commands.Dispatch(entities.PublicChannel, m)
+	if reply == nil {
+		return entities.SendResult{}, s.PostPublicMessage(m)
+	}
+
+	if reply.Visibility == entities.VisibilityInChannel && reply.Text != "" {
+		return entities.SendResult{Command: true}, s.PostPublicMessage(entities.Message{Sender: reply.Sender, Content: reply.Text})
+	}
+
+	return ephemeralResult(m, *reply), nil
+}
+
+// PostPublicMessage posts the message to the public chat as is and notifies
+// the users mentioned in it. The scheduled messages come this way, so the
+// sanctions are checked here too.
+func (s *PublicService) PostPublicMessage(m entities.Message) error {
+	if err := s.moderation.CanPost(m.Sender); err != nil {
+		return err
+	}
+
+	m.Mentions = s.mentions(m)
+
 	return s.repos.InsertMessage(m)
 }
 
-func (s *PublicService) GetPublicMessages(limit, offset int) ([]entities.Message, error) {
-	return s.repos.GetMessages(limit, offset)
+// GetPublicMessages returns the page of the public chat without the
+// messages of the users the viewer hid. They are dropped from the page
+// rather than skipped, so the offsets are the same for every viewer.
+func (s *PublicService) GetPublicMessages(viewer string, limit, offset int, order entities.Order) ([]entities.Message, error) {
+	messages, err := s.repos.GetMessages(limit, offset, order)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden, err := s.privacy.HiddenSenders(viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hidden) == 0 {
+		return messages, nil
+	}
+
+	visible := make([]entities.Message, 0, len(messages))
+	for _, m := range messages {
+		if !hidden[m.Sender] {
+			visible = append(visible, m)
+		}
+	}
+
+	return visible, nil
+}
+
+// mentions returns the existing users mentioned in the message as @username,
+// each one once and without the sender. A mention that is not a user is
+// plain text, so lookup failures never fail the message.
+func (s *PublicService) mentions(m entities.Message) []string {
+	var usernames []string
+
+	seen := map[string]bool{m.Sender: true}
+	for _, match := range mentionPattern.FindAllStringSubmatch(m.Content, -1) {
+		username := match[1]
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		user, err := s.users.GetUser(username)
+		if err != nil || user.Username != username {
+			continue
+		}
+
+		usernames = append(usernames, username)
+		if len(usernames) == maxMentions {
+			break
+		}
+	}
+
+	return usernames
+}
+
+// ephemeralResult returns the reply to the command as a message only its
+// sender sees. It is not stored anywhere.
+func ephemeralResult(m entities.Message, reply entities.CommandReply) entities.SendResult {
+	res := entities.SendResult{Command: true}
+	if reply.Text != "" {
+		res.Ephemeral = append(res.Ephemeral, entities.Message{Sender: reply.Sender, Recipient: m.Sender, Content: reply.Text, CreatedAt: time.Now()})
+	}
+
+	return res
 }
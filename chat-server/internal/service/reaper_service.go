@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+//go:generate mockgen -source=reaper_service.go -destination=mocks/expiry_repository_mock.go
+
+type ExpiryRepository interface {
+	PurgeExpiredMessages(now time.Time, limit int) (int, []string, error)
+}
+
+type ReaperOptions struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// ReaperService purges the content of the expired self-destructing
+// messages. The read paths hide an expired message as soon as it expires,
+// the reaper removes what is left of it from the storage, the files of its
+// attachments included.
+type ReaperService struct {
+	repos ExpiryRepository
+	store blobstore.BlobStore
+	opts  ReaperOptions
+	now   func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewReaperService(r ExpiryRepository, store blobstore.BlobStore, opts ReaperOptions) *ReaperService {
+	return &ReaperService{
+		repos: r,
+		store: store,
+		opts:  opts,
+		now:   time.Now,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Run purges the expired messages in batches until ctx is done or Shutdown
+// is called.
+func (s *ReaperService) Run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			for n := s.purgeBatch(ctx); n > 0 && n == s.opts.BatchSize && ctx.Err() == nil && !s.stopped(); n = s.purgeBatch(ctx) {
+			}
+		}
+	}
+}
+
+// Shutdown stops Run and waits until the batch it is purging is done.
+func (s *ReaperService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ReaperService) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *ReaperService) purgeBatch(ctx context.Context) int {
+	purged, attachments, err := s.repos.PurgeExpiredMessages(s.now(), s.opts.BatchSize)
+	if err != nil {
+		log.Printf("reaper: purge expired messages: %s", err)
+		return 0
+	}
+
+	deleteBlobs(ctx, s.store, "reaper", attachments)
+
+	return purged
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+func TestReaperService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Put(context.Background(), "a1", strings.NewReader("png"), 3, "image/png"))
+
+	repo := mock_service.NewMockExpiryRepository(ctrl)
+
+	purged := make(chan struct{})
+
+	// A full batch is followed by the next one at once.
+	gomock.InOrder(
+		repo.EXPECT().PurgeExpiredMessages(now, 2).Return(2, []string{"a1"}, nil),
+		repo.EXPECT().PurgeExpiredMessages(now, 2).Return(1, nil, nil).Do(func(time.Time, int) { close(purged) }),
+	)
+	repo.EXPECT().PurgeExpiredMessages(now, 2).Return(0, nil, nil).AnyTimes()
+
+	s := NewReaperService(repo, store, ReaperOptions{PollInterval: time.Millisecond, BatchSize: 2})
+	s.now = func() time.Time { return now }
+
+	go s.Run(context.Background())
+
+	select {
+	case <-purged:
+	case <-time.After(time.Second):
+		t.Fatal("expired messages were not purged")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, s.Shutdown(ctx))
+
+	// The files of the purged attachments go with them.
+	_, err = store.Get(context.Background(), "a1")
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+}
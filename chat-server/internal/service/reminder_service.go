@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+	// The time zone database is embedded, so reminders work on hosts
+	// without one, like the scratch container images.
+	_ "time/tzdata"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/cron"
+)
+
+//go:generate mockgen -source=reminder_service.go -destination=mocks/reminder_repository_mock.go
+
+type ReminderRepository interface {
+	InsertReminder(rem entities.Reminder, senderPassword string) (entities.Reminder, error)
+	GetReminders() ([]entities.Reminder, error)
+	GetReminder(id int) (entities.Reminder, error)
+	PauseReminder(id int) (entities.Reminder, error)
+	ResumeReminder(id int, nextRunAt time.Time) (entities.Reminder, error)
+	DeleteReminder(id int) error
+	GetDueReminders(now time.Time, limit int) ([]entities.Reminder, error)
+	AdvanceReminder(id int, due, next time.Time, posted bool) (bool, error)
+}
+
+type ReminderOptions struct {
+	Sender       string
+	PollInterval time.Duration
+	BatchSize    int
+	// CatchUp is how late a run may still be posted. The runs missed for
+	// longer, e.g. while every server was down, are skipped.
+	CatchUp time.Duration
+}
+
+// ReminderService posts the reminders to the public chat on their cron
+// schedules. Every server runs it; the repository advances each run only
+// once, so a run is posted by one server however many there are.
+type ReminderService struct {
+	repos  ReminderRepository
+	public PublicMessageSender
+	opts   ReminderOptions
+	now    func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewReminderService(r ReminderRepository, public PublicMessageSender, opts ReminderOptions) *ReminderService {
+	return &ReminderService{
+		repos:  r,
+		public: public,
+		opts:   opts,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Create checks the schedule and the time zone and stores the reminder
+// with its first run. The reminders are posted by the system sender, which
+// is registered as a bot with a random password on the first use.
+func (s *ReminderService) Create(rem entities.Reminder) (entities.Reminder, error) {
+	if rem.TimeZone == "" {
+		rem.TimeZone = "UTC"
+	}
+
+	next, err := nextRun(rem, s.now())
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	password, err := randomToken()
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	rem.Sender = s.opts.Sender
+	rem.Paused = false
+	rem.NextRunAt = next
+
+	return s.repos.InsertReminder(rem, password)
+}
+
+func (s *ReminderService) List() ([]entities.Reminder, error) {
+	return s.repos.GetReminders()
+}
+
+func (s *ReminderService) Pause(id int) (entities.Reminder, error) {
+	return s.repos.PauseReminder(id)
+}
+
+// Resume turns the reminder back on from now: the runs missed while it was
+// paused are not posted.
+func (s *ReminderService) Resume(id int) (entities.Reminder, error) {
+	rem, err := s.repos.GetReminder(id)
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	next, err := nextRun(rem, s.now())
+	if err != nil {
+		return entities.Reminder{}, err
+	}
+
+	return s.repos.ResumeReminder(id, next)
+}
+
+func (s *ReminderService) Delete(id int) error {
+	return s.repos.DeleteReminder(id)
+}
+
+// Run posts the due reminders until ctx is done or Shutdown is called.
+func (s *ReminderService) Run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			for n := s.runBatch(); n > 0 && n == s.opts.BatchSize && ctx.Err() == nil && !s.stopped(); n = s.runBatch() {
+			}
+		}
+	}
+}
+
+// Shutdown stops Run and waits until the batch it is posting is done.
+func (s *ReminderService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ReminderService) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// runBatch posts one batch of due reminders and returns how many were due.
+// Each reminder is advanced to its next run before it is posted, and only
+// the server whose advance succeeded posts it.
+func (s *ReminderService) runBatch() int {
+	now := s.now()
+
+	reminders, err := s.repos.GetDueReminders(now, s.opts.BatchSize)
+	if err != nil {
+		log.Printf("reminders: get due reminders: %s", err)
+		return 0
+	}
+
+	for _, rem := range reminders {
+		s.run(rem, now)
+	}
+
+	return len(reminders)
+}
+
+func (s *ReminderService) run(rem entities.Reminder, now time.Time) {
+	post := s.opts.CatchUp <= 0 || now.Sub(rem.NextRunAt) <= s.opts.CatchUp
+
+	next, err := nextRun(rem, now)
+	if err != nil {
+		log.Printf("reminders: reminder %d: %s", rem.ID, err)
+
+		// A reminder that can not run any more is paused rather than left
+		// due forever.
+		if _, err := s.repos.PauseReminder(rem.ID); err != nil {
+			log.Printf("reminders: pause reminder %d: %s", rem.ID, err)
+		}
+
+		return
+	}
+
+	advanced, err := s.repos.AdvanceReminder(rem.ID, rem.NextRunAt, next, post)
+	if err != nil {
+		log.Printf("reminders: advance reminder %d: %s", rem.ID, err)
+		return
+	}
+
+	if !advanced || !post {
+		return
+	}
+
+	if err := s.public.PostPublicMessage(entities.Message{Sender: rem.Sender, Content: rem.Content}); err != nil {
+		log.Printf("reminders: post reminder %d: %s", rem.ID, err)
+	}
+}
+
+// nextRun returns the first run of the reminder after t.
+func nextRun(rem entities.Reminder, t time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(rem.TimeZone)
+	if err != nil {
+		return time.Time{}, entities.ErrInvalidTimeZone
+	}
+
+	schedule, err := cron.Parse(rem.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := schedule.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, entities.ErrReminderNeverFires
+	}
+
+	return next, nil
+}
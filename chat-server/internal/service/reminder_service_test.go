@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/cron"
+)
+
+func TestReminderService_Create(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockReminderRepository)
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	minsk, err := time.LoadLocation("Europe/Minsk")
+	assert.NoError(t, err)
+
+	testTable := []struct {
+		name          string
+		reminder      entities.Reminder
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:     "ok",
+			reminder: entities.Reminder{Name: "stand-up", Content: "stand-up!", Schedule: "55 9 * * mon-fri", TimeZone: "Europe/Minsk", CreatedBy: "admin"},
+			mockBehavior: func(r *mock_service.MockReminderRepository) {
+				r.EXPECT().InsertReminder(entities.Reminder{
+					Name: "stand-up", Content: "stand-up!", Schedule: "55 9 * * mon-fri", TimeZone: "Europe/Minsk", Sender: "reminders",
+					NextRunAt: time.Date(2026, time.October, 20, 9, 55, 0, 0, minsk), CreatedBy: "admin",
+				}, gomock.Any()).Return(entities.Reminder{ID: 1}, nil)
+			},
+		},
+		{
+			name:     "default_time_zone",
+			reminder: entities.Reminder{Name: "daily", Content: "hi", Schedule: "@daily", CreatedBy: "admin"},
+			mockBehavior: func(r *mock_service.MockReminderRepository) {
+				r.EXPECT().InsertReminder(entities.Reminder{
+					Name: "daily", Content: "hi", Schedule: "@daily", TimeZone: "UTC", Sender: "reminders",
+					NextRunAt: time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC), CreatedBy: "admin",
+				}, gomock.Any()).Return(entities.Reminder{ID: 2}, nil)
+			},
+		},
+		{
+			name:          "invalid_time_zone",
+			reminder:      entities.Reminder{Schedule: "@daily", TimeZone: "Mars/Olympus"},
+			mockBehavior:  func(r *mock_service.MockReminderRepository) {},
+			expectedError: entities.ErrInvalidTimeZone,
+		},
+		{
+			name:          "invalid_schedule",
+			reminder:      entities.Reminder{Schedule: "every day"},
+			mockBehavior:  func(r *mock_service.MockReminderRepository) {},
+			expectedError: cron.ErrInvalidExpression,
+		},
+		{
+			name:          "never_fires",
+			reminder:      entities.Reminder{Schedule: "0 0 31 2 *"},
+			mockBehavior:  func(r *mock_service.MockReminderRepository) {},
+			expectedError: entities.ErrReminderNeverFires,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_service.NewMockReminderRepository(ctrl)
+			testCase.mockBehavior(repo)
+
+			s := NewReminderService(repo, nil, ReminderOptions{Sender: "reminders"})
+			s.now = func() time.Time { return now }
+
+			_, err := s.Create(testCase.reminder)
+			assert.True(t, errors.Is(err, testCase.expectedError), "expected %v, got %v", testCase.expectedError, err)
+		})
+	}
+}
+
+func TestReminderService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, time.October, 19, 12, 0, 30, 0, time.UTC)
+	due := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	next := time.Date(2026, time.October, 19, 13, 0, 0, 0, time.UTC)
+
+	repo := mock_service.NewMockReminderRepository(ctrl)
+	public := mock_service.NewMockPublicMessageSender(ctrl)
+
+	done := make(chan struct{})
+
+	gomock.InOrder(
+		repo.EXPECT().GetDueReminders(now, 3).Return([]entities.Reminder{
+			{ID: 1, Content: "won", Schedule: "@hourly", TimeZone: "UTC", Sender: "reminders", NextRunAt: due},
+			{ID: 2, Content: "lost", Schedule: "@hourly", TimeZone: "UTC", Sender: "reminders", NextRunAt: due},
+			{ID: 3, Content: "too late", Schedule: "@hourly", TimeZone: "UTC", Sender: "reminders", NextRunAt: due.Add(-time.Hour)},
+		}, nil),
+		repo.EXPECT().GetDueReminders(now, 3).Return(nil, nil).Do(func(time.Time, int) { close(done) }),
+	)
+	repo.EXPECT().GetDueReminders(now, 3).Return(nil, nil).AnyTimes()
+
+	// Another server advanced the second reminder first.
+	repo.EXPECT().AdvanceReminder(1, due, next, true).Return(true, nil)
+	repo.EXPECT().AdvanceReminder(2, due, next, true).Return(false, nil)
+	// The third run is older than the catch-up window, so it is skipped.
+	repo.EXPECT().AdvanceReminder(3, due.Add(-time.Hour), next, false).Return(true, nil)
+
+	public.EXPECT().PostPublicMessage(entities.Message{Sender: "reminders", Content: "won"}).Return(nil)
+
+	s := NewReminderService(repo, public, ReminderOptions{PollInterval: time.Millisecond, BatchSize: 3, CatchUp: 10 * time.Minute})
+	s.now = func() time.Time { return now }
+
+	go s.Run(context.Background())
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reminders were not run")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, s.Shutdown(ctx))
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/blobstore"
+	"github.com/vavelour/chat/pkg/jsonl"
+)
+
+//go:generate mockgen -source=retention_service.go -destination=mocks/retention_repository_mock.go
+
+type RetentionRepository interface {
+	GetPublicMessagesBefore(before time.Time, limit int) ([]entities.Message, error)
+	DeletePublicMessages(ids []int, before time.Time) (int, []string, error)
+	GetPrivateMessagesBefore(before time.Time, limit int) ([]entities.Message, error)
+	DeletePrivateMessages(messages []entities.Message, before time.Time) (int, []string, error)
+	PlaceLegalHold(username string) error
+	LiftLegalHold(username string) error
+	GetLegalHolds() ([]entities.LegalHold, error)
+}
+
+const (
+	publicArchiveChat  = "public"
+	privateArchiveChat = "private"
+)
+
+type RetentionOptions struct {
+	// PublicMaxAge and PrivateMaxAge are how long the messages of the
+	// public chat and of the private conversations are kept. Zero keeps
+	// them forever.
+	PublicMaxAge  time.Duration
+	PrivateMaxAge time.Duration
+	PollInterval  time.Duration
+	BatchSize     int
+	// ArchiveDir is the directory the removed messages are archived to
+	// before they are removed. Empty turns the archival off.
+	ArchiveDir string
+}
+
+// archivedMessage is a line of an archive.
+type archivedMessage struct {
+	Chat        string               `json:"chat"`
+	ID          int                  `json:"id"`
+	Sender      string               `json:"sender"`
+	Recipient   string               `json:"recipient,omitempty"`
+	Content     string               `json:"content"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Deleted     bool                 `json:"deleted,omitempty"`
+	Expired     bool                 `json:"expired,omitempty"`
+	Attachments []archivedAttachment `json:"attachments,omitempty"`
+}
+
+type archivedAttachment struct {
+	ID          string `json:"id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// RetentionService removes the messages older than the retention policies
+// allow, in batches, except the ones of the users on legal hold. With an
+// archive directory every batch is first written to a gzip-compressed
+// JSON lines file of its own. A batch is archived before it is removed, so
+// an archive may also hold a message which was kept after all, when its
+// user was put on hold in between. The files of the attachments of the
+// removed messages are deleted from the store after them.
+type RetentionService struct {
+	repos RetentionRepository
+	store blobstore.BlobStore
+	opts  RetentionOptions
+	now   func() time.Time
+	// archived counts the archives written, to tell apart the ones written
+	// in the same second.
+	archived int
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewRetentionService(r RetentionRepository, store blobstore.BlobStore, opts RetentionOptions) *RetentionService {
+	return &RetentionService{
+		repos: r,
+		store: store,
+		opts:  opts,
+		now:   time.Now,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Run removes the messages past their retention in batches until ctx is
+// done or Shutdown is called.
+func (s *RetentionService) Run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if s.opts.PublicMaxAge > 0 {
+				for n := s.purgePublicBatch(ctx); n > 0 && n == s.opts.BatchSize && ctx.Err() == nil && !s.stopped(); n = s.purgePublicBatch(ctx) {
+				}
+			}
+
+			if s.opts.PrivateMaxAge > 0 {
+				for n := s.purgePrivateBatch(ctx); n > 0 && n == s.opts.BatchSize && ctx.Err() == nil && !s.stopped(); n = s.purgePrivateBatch(ctx) {
+				}
+			}
+		}
+	}
+}
+
+// Shutdown stops Run and waits until the batch it is removing is done.
+func (s *RetentionService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *RetentionService) PlaceLegalHold(username string) error {
+	return s.repos.PlaceLegalHold(username)
+}
+
+func (s *RetentionService) LiftLegalHold(username string) error {
+	return s.repos.LiftLegalHold(username)
+}
+
+func (s *RetentionService) LegalHolds() ([]entities.LegalHold, error) {
+	return s.repos.GetLegalHolds()
+}
+
+func (s *RetentionService) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *RetentionService) purgePublicBatch(ctx context.Context) int {
+	now := s.now()
+	before := now.Add(-s.opts.PublicMaxAge)
+
+	messages, err := s.repos.GetPublicMessagesBefore(before, s.opts.BatchSize)
+	if err != nil {
+		log.Printf("retention: get public messages: %s", err)
+		return 0
+	}
+
+	if len(messages) == 0 || !s.archive(publicArchiveChat, now, messages) {
+		return 0
+	}
+
+	ids := make([]int, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+
+	removed, attachments, err := s.repos.DeletePublicMessages(ids, before)
+	if err != nil {
+		log.Printf("retention: delete public messages: %s", err)
+		return 0
+	}
+
+	deleteBlobs(ctx, s.store, "retention", attachments)
+
+	return removed
+}
+
+func (s *RetentionService) purgePrivateBatch(ctx context.Context) int {
+	now := s.now()
+	before := now.Add(-s.opts.PrivateMaxAge)
+
+	messages, err := s.repos.GetPrivateMessagesBefore(before, s.opts.BatchSize)
+	if err != nil {
+		log.Printf("retention: get private messages: %s", err)
+		return 0
+	}
+
+	if len(messages) == 0 || !s.archive(privateArchiveChat, now, messages) {
+		return 0
+	}
+
+	removed, attachments, err := s.repos.DeletePrivateMessages(messages, before)
+	if err != nil {
+		log.Printf("retention: delete private messages: %s", err)
+		return 0
+	}
+
+	deleteBlobs(ctx, s.store, "retention", attachments)
+
+	return removed
+}
+
+// archive writes the messages to an archive of their own, and reports
+// whether they may be removed: a batch which failed to be archived is kept
+// until the next run.
+func (s *RetentionService) archive(chat string, now time.Time, messages []entities.Message) bool {
+	if s.opts.ArchiveDir == "" {
+		return true
+	}
+
+	records := make([]archivedMessage, 0, len(messages))
+	for _, m := range messages {
+		records = append(records, toArchivedMessage(chat, m))
+	}
+
+	s.archived++
+	name := fmt.Sprintf("%s-%s-%d.jsonl.gz", chat, now.UTC().Format("20060102T150405Z"), s.archived)
+
+	if err := jsonl.WriteGzipFile(filepath.Join(s.opts.ArchiveDir, name), records); err != nil {
+		log.Printf("retention: archive %s messages: %s", chat, err)
+		return false
+	}
+
+	return true
+}
+
+func toArchivedMessage(chat string, m entities.Message) archivedMessage {
+	record := archivedMessage{
+		Chat:      chat,
+		ID:        m.ID,
+		Sender:    m.Sender,
+		Recipient: m.Recipient,
+		Content:   m.Content,
+		CreatedAt: m.CreatedAt,
+		Deleted:   m.Deleted,
+		Expired:   m.Expired,
+	}
+
+	for _, attachment := range m.Attachments {
+		record.Attachments = append(record.Attachments, archivedAttachment{
+			ID:          attachment.ID,
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+		})
+	}
+
+	return record
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/blobstore"
+)
+
+func TestRetentionService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	publicBefore := now.Add(-30 * 24 * time.Hour)
+	privateBefore := now.Add(-90 * 24 * time.Hour)
+	dir := t.TempDir()
+
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Put(context.Background(), "a1", strings.NewReader("pdfdata"), 7, "application/pdf"))
+
+	public := []entities.Message{
+		{ID: 1, Sender: "tester", Content: "hello", CreatedAt: publicBefore.Add(-time.Hour)},
+		{ID: 2, Sender: "valera", Content: "hi", CreatedAt: publicBefore.Add(-time.Minute),
+			Attachments: []entities.Attachment{{ID: "a1", FileName: "doc.pdf", ContentType: "application/pdf", Size: 7}}},
+	}
+	private := []entities.Message{{ID: 7, Sender: "tester", Recipient: "valera", Content: "secret", CreatedAt: privateBefore.Add(-time.Hour)}}
+
+	repo := mock_service.NewMockRetentionRepository(ctrl)
+
+	purged := make(chan struct{})
+
+	// A full batch is followed by the next one at once, then the private
+	// conversations follow.
+	gomock.InOrder(
+		repo.EXPECT().GetPublicMessagesBefore(publicBefore, 2).Return(public, nil),
+		repo.EXPECT().DeletePublicMessages([]int{1, 2}, publicBefore).Return(2, []string{"a1"}, nil),
+		repo.EXPECT().GetPublicMessagesBefore(publicBefore, 2).Return(nil, nil),
+		repo.EXPECT().GetPrivateMessagesBefore(privateBefore, 2).Return(private, nil),
+		repo.EXPECT().DeletePrivateMessages(private, privateBefore).Return(1, nil, nil).
+			Do(func([]entities.Message, time.Time) { close(purged) }),
+	)
+	repo.EXPECT().GetPublicMessagesBefore(publicBefore, 2).Return(nil, nil).AnyTimes()
+	repo.EXPECT().GetPrivateMessagesBefore(privateBefore, 2).Return(nil, nil).AnyTimes()
+
+	s := NewRetentionService(repo, store, RetentionOptions{
+		PublicMaxAge:  30 * 24 * time.Hour,
+		PrivateMaxAge: 90 * 24 * time.Hour,
+		PollInterval:  time.Millisecond,
+		BatchSize:     2,
+		ArchiveDir:    dir,
+	})
+	s.now = func() time.Time { return now }
+
+	go s.Run(context.Background())
+
+	select {
+	case <-purged:
+	case <-time.After(time.Second):
+		t.Fatal("messages past their retention were not removed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, s.Shutdown(ctx))
+
+	records := readArchive(t, filepath.Join(dir, "public-20261019T120000Z-1.jsonl.gz"))
+	require.Len(t, records, 2)
+	assert.Equal(t, "public", records[0].Chat)
+	assert.Equal(t, "hello", records[0].Content)
+	assert.Equal(t, []archivedAttachment{{ID: "a1", FileName: "doc.pdf", ContentType: "application/pdf", Size: 7}}, records[1].Attachments)
+
+	records = readArchive(t, filepath.Join(dir, "private-20261019T120000Z-2.jsonl.gz"))
+	require.Len(t, records, 1)
+	assert.Equal(t, "valera", records[0].Recipient)
+	assert.Equal(t, "secret", records[0].Content)
+
+	// The files of the attachments go after the archival.
+	_, err = store.Get(context.Background(), "a1")
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+}
+
+func TestRetentionService_KeepsBatchNotArchived(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	// The archive directory can not be made under a file.
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	repo := mock_service.NewMockRetentionRepository(ctrl)
+	repo.EXPECT().GetPublicMessagesBefore(now.Add(-time.Hour), 10).
+		Return([]entities.Message{{ID: 1, Sender: "tester", Content: "hello"}}, nil)
+
+	s := NewRetentionService(repo, nil, RetentionOptions{PublicMaxAge: time.Hour, BatchSize: 10, ArchiveDir: filepath.Join(file, "archive")})
+	s.now = func() time.Time { return now }
+
+	assert.Equal(t, 0, s.purgePublicBatch(context.Background()))
+}
+
+func readArchive(t *testing.T, path string) []archivedMessage {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	var records []archivedMessage
+	dec := json.NewDecoder(zr)
+	for dec.More() {
+		var record archivedMessage
+		require.NoError(t, dec.Decode(&record))
+		records = append(records, record)
+	}
+
+	return records
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+)
+
+//go:generate mockgen -source=scheduled_service.go -destination=mocks/scheduled_repository_mock.go
+
+type ScheduledMessageRepository interface {
+	InsertScheduledMessage(m entities.ScheduledMessage) (entities.ScheduledMessage, error)
+	GetScheduledMessages(sender string, limit, offset int) ([]entities.ScheduledMessage, error)
+	DeleteScheduledMessage(sender string, id int) error
+	ClaimScheduledMessages(now time.Time, limit int) ([]entities.ScheduledMessage, error)
+	FinishScheduledMessage(id int, status entities.ScheduledStatus, lastError string) error
+}
+
+type PrivateMessageSender interface {
+	PostPrivateMessage(m entities.Message) error
+}
+
+type ScheduledOptions struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxDelay     time.Duration
+}
+
+// ScheduledService keeps messages to post later and posts them when they
+// are due. The messages are stored by the repository, so they survive a
+// restart of the server.
+type ScheduledService struct {
+	repos   ScheduledMessageRepository
+	users   AuthRepository
+	public  PublicMessageSender
+	private PrivateMessageSender
+	opts    ScheduledOptions
+	now     func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewScheduledService(r ScheduledMessageRepository, users AuthRepository, public PublicMessageSender, private PrivateMessageSender, opts ScheduledOptions) *ScheduledService {
+	return &ScheduledService{
+		repos:   r,
+		users:   users,
+		public:  public,
+		private: private,
+		opts:    opts,
+		now:     time.Now,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Schedule stores the message to post it at SendAt. Commands can not be
+// scheduled, since nobody would see their replies; a message starting with
+// "//" loses the first slash and is posted as text, like when it is sent
+// at once.
+func (s *ScheduledService) Schedule(m entities.ScheduledMessage) (entities.ScheduledMessage, error) {
+	now := s.now()
+	if !m.SendAt.After(now) {
+		return entities.ScheduledMessage{}, entities.ErrSendAtInPast
+	}
+
+	if s.opts.MaxDelay > 0 && m.SendAt.After(now.Add(s.opts.MaxDelay)) {
+		return entities.ScheduledMessage{}, entities.ErrSendAtTooFar
+	}
+
+	if strings.HasPrefix(m.Content, "//") {
+		m.Content = m.Content[1:]
+	} else if commandPattern.MatchString(m.Content) {
+		return entities.ScheduledMessage{}, entities.ErrScheduledCommand
+	}
+
+	if m.Channel == entities.PrivateChannel {
+		user, err := s.users.GetUser(m.Recipient)
+		if err != nil || user.Username != m.Recipient {
+			return entities.ScheduledMessage{}, entities.ErrRecipientNotFound
+		}
+	}
+
+	return s.repos.InsertScheduledMessage(m)
+}
+
+func (s *ScheduledService) List(sender string, limit, offset int) ([]entities.ScheduledMessage, error) {
+	return s.repos.GetScheduledMessages(sender, limit, offset)
+}
+
+func (s *ScheduledService) Cancel(sender string, id int) error {
+	return s.repos.DeleteScheduledMessage(sender, id)
+}
+
+// Run posts the due messages until ctx is done or Shutdown is called. The
+// messages are marked as being sent before they are posted, so a server
+// that dies in between loses them rather than posts them twice.
+func (s *ScheduledService) Run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			// A full batch means more messages are due, so go on at once.
+			for n := s.sendBatch(); n > 0 && n == s.opts.BatchSize && ctx.Err() == nil && !s.stopped(); n = s.sendBatch() {
+			}
+		}
+	}
+}
+
+// Shutdown stops Run and waits until the batch it is posting is done.
+func (s *ScheduledService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ScheduledService) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendBatch posts one batch of due messages and returns how many there
+// were. A claimed batch is always posted to the end, whatever happens to
+// the server meanwhile.
+func (s *ScheduledService) sendBatch() int {
+	messages, err := s.repos.ClaimScheduledMessages(s.now(), s.opts.BatchSize)
+	if err != nil {
+		log.Printf("scheduler: claim messages: %s", err)
+		return 0
+	}
+
+	for _, m := range messages {
+		status, lastError := entities.ScheduledSent, ""
+		if err := s.post(m); err != nil {
+			status, lastError = entities.ScheduledFailed, truncate(err.Error(), maxErrorLength)
+		}
+
+		if err := s.repos.FinishScheduledMessage(m.ID, status, lastError); err != nil {
+			log.Printf("scheduler: finish message %d: %s", m.ID, err)
+		}
+	}
+
+	return len(messages)
+}
+
+func (s *ScheduledService) post(m entities.ScheduledMessage) error {
+	if m.Channel == entities.PrivateChannel {
+		return s.private.PostPrivateMessage(m.Message())
+	}
+
+	return s.public.PostPublicMessage(m.Message())
+}
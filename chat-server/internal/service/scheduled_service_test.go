@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+)
+
+func TestScheduledService_Schedule(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockScheduledMessageRepository, u *mock_service.MockAuthRepository)
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		message       entities.ScheduledMessage
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:    "public",
+			message: entities.ScheduledMessage{Channel: entities.PublicChannel, Sender: "valera", Content: "stand-up!", SendAt: now.Add(time.Hour)},
+			mockBehavior: func(r *mock_service.MockScheduledMessageRepository, u *mock_service.MockAuthRepository) {
+				r.EXPECT().InsertScheduledMessage(entities.ScheduledMessage{Channel: entities.PublicChannel, Sender: "valera", Content: "stand-up!", SendAt: now.Add(time.Hour)}).
+					Return(entities.ScheduledMessage{ID: 1}, nil)
+			},
+		},
+		{
+			name:    "escaped_command",
+			message: entities.ScheduledMessage{Channel: entities.PublicChannel, Sender: "valera", Content: "//me is a command", SendAt: now.Add(time.Hour)},
+			mockBehavior: func(r *mock_service.MockScheduledMessageRepository, u *mock_service.MockAuthRepository) {
+				r.EXPECT().InsertScheduledMessage(entities.ScheduledMessage{Channel: entities.PublicChannel, Sender: "valera", Content: "/me is a command", SendAt: now.Add(time.Hour)}).
+					Return(entities.ScheduledMessage{ID: 1}, nil)
+			},
+		},
+		{
+			name:          "command",
+			message:       entities.ScheduledMessage{Channel: entities.PublicChannel, Sender: "valera", Content: "/me waves", SendAt: now.Add(time.Hour)},
+			mockBehavior:  func(r *mock_service.MockScheduledMessageRepository, u *mock_service.MockAuthRepository) {},
+			expectedError: entities.ErrScheduledCommand,
+		},
+		{
+			name:          "in_past",
+			message:       entities.ScheduledMessage{Channel: entities.PublicChannel, Sender: "valera", Content: "late", SendAt: now},
+			mockBehavior:  func(r *mock_service.MockScheduledMessageRepository, u *mock_service.MockAuthRepository) {},
+			expectedError: entities.ErrSendAtInPast,
+		},
+		{
+			name:          "too_far",
+			message:       entities.ScheduledMessage{Channel: entities.PublicChannel, Sender: "valera", Content: "next year", SendAt: now.Add(48 * time.Hour)},
+			mockBehavior:  func(r *mock_service.MockScheduledMessageRepository, u *mock_service.MockAuthRepository) {},
+			expectedError: entities.ErrSendAtTooFar,
+		},
+		{
+			name:    "unknown_recipient",
+			message: entities.ScheduledMessage{Channel: entities.PrivateChannel, Sender: "valera", Recipient: "ghost", Content: "hi", SendAt: now.Add(time.Hour)},
+			mockBehavior: func(r *mock_service.MockScheduledMessageRepository, u *mock_service.MockAuthRepository) {
+				u.EXPECT().GetUser("ghost").Return(entities.User{}, errors.New("unregistered user"))
+			},
+			expectedError: entities.ErrRecipientNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_service.NewMockScheduledMessageRepository(ctrl)
+			users := mock_service.NewMockAuthRepository(ctrl)
+			testCase.mockBehavior(repo, users)
+
+			s := NewScheduledService(repo, users, nil, nil, ScheduledOptions{MaxDelay: 24 * time.Hour})
+			s.now = func() time.Time { return now }
+
+			_, err := s.Schedule(testCase.message)
+			assert.Equal(t, testCase.expectedError, err)
+		})
+	}
+}
+
+func TestScheduledService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	repo := mock_service.NewMockScheduledMessageRepository(ctrl)
+	public := mock_service.NewMockPublicMessageSender(ctrl)
+	private := mock_service.NewMockPrivateMessageSender(ctrl)
+
+	sent := make(chan struct{})
+
+	gomock.InOrder(
+		repo.EXPECT().ClaimScheduledMessages(now, 2).Return([]entities.ScheduledMessage{
+			{ID: 1, Channel: entities.PublicChannel, Sender: "valera", Content: "stand-up!"},
+			{ID: 2, Channel: entities.PrivateChannel, Sender: "valera", Recipient: "dima", Content: "hi"},
+		}, nil),
+		repo.EXPECT().ClaimScheduledMessages(now, 2).Return(nil, nil).Do(func(time.Time, int) { close(sent) }),
+	)
+	repo.EXPECT().ClaimScheduledMessages(now, 2).Return(nil, nil).AnyTimes()
+
+	public.EXPECT().PostPublicMessage(entities.Message{Sender: "valera", Content: "stand-up!"}).Return(nil)
+	private.EXPECT().PostPrivateMessage(entities.Message{Sender: "valera", Recipient: "dima", Content: "hi"}).Return(errors.New("this user is not exist"))
+	repo.EXPECT().FinishScheduledMessage(1, entities.ScheduledSent, "").Return(nil)
+	repo.EXPECT().FinishScheduledMessage(2, entities.ScheduledFailed, "this user is not exist").Return(nil)
+
+	s := NewScheduledService(repo, nil, public, private, ScheduledOptions{PollInterval: time.Millisecond, BatchSize: 2})
+	s.now = func() time.Time { return now }
+
+	go s.Run(context.Background())
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled messages were not sent")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, s.Shutdown(ctx))
+	assert.NoError(t, s.Shutdown(ctx))
+}
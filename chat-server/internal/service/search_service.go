@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/textsearch"
+)
+
+const snippetWidth = 120
+
+//go:generate mockgen -source=search_service.go -destination=mocks/search_repository_mock.go
+
+type PublicSearchRepository interface {
+	SearchMessages(q entities.SearchQuery) ([]entities.SearchResult, error)
+}
+
+type PrivateSearchRepository interface {
+	SearchMessages(user string, q entities.SearchQuery) ([]entities.SearchResult, error)
+}
+
+// SearchService searches the public chat together with the private
+// conversations of the user and merges both into one page.
+type SearchService struct {
+	public  PublicSearchRepository
+	private PrivateSearchRepository
+}
+
+func NewSearchService(public PublicSearchRepository, private PrivateSearchRepository) *SearchService {
+	return &SearchService{public: public, private: private}
+}
+
+func (s *SearchService) Search(user string, q entities.SearchQuery) (entities.SearchPage, error) {
+	terms, err := textsearch.Parse(q.Text)
+	if errors.Is(err, textsearch.ErrEmptyQuery) {
+		return entities.SearchPage{}, entities.ErrEmptySearchQuery
+	} else if err != nil {
+		return entities.SearchPage{}, err
+	}
+
+	q.Terms = terms
+
+	// One extra result tells whether there is a next page.
+	limit := q.Limit
+	q.Limit++
+
+	public, err := s.public.SearchMessages(q)
+	if err != nil {
+		return entities.SearchPage{}, err
+	}
+
+	private, err := s.private.SearchMessages(user, q)
+	if err != nil {
+		return entities.SearchPage{}, err
+	}
+
+	results := mergeResults(public, private)
+
+	var page entities.SearchPage
+	if len(results) > limit {
+		results = results[:limit]
+		page.Next = &results[limit-1].Cursor
+	}
+
+	for i := range results {
+		results[i].Snippet = textsearch.Snippet(results[i].Message.Content, terms, snippetWidth)
+	}
+
+	page.Results = results
+
+	return page, nil
+}
+
+// mergeResults merges two lists which are already in the search order.
+func mergeResults(a, b []entities.SearchResult) []entities.SearchResult {
+	merged := make([]entities.SearchResult, 0, len(a)+len(b))
+
+	for len(a) > 0 && len(b) > 0 {
+		if b[0].Cursor.Before(a[0].Cursor) {
+			merged = append(merged, b[0])
+			b = b[1:]
+		} else {
+			merged = append(merged, a[0])
+			a = a[1:]
+		}
+	}
+
+	merged = append(merged, a...)
+
+	return append(merged, b...)
+}
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/mbox"
+)
+
+//go:generate mockgen -source=transfer_service.go -destination=mocks/transfer_repository_mock.go
+
+type TransferRepository interface {
+	GetUsers(after string, limit int) ([]entities.User, error)
+	GetPublicMessages(afterID, limit int) ([]entities.Message, error)
+	GetPartners(username string) ([]string, error)
+	GetPrivateMessages(user1, user2 string, afterID, limit int) ([]entities.Message, error)
+	ImportUser(user entities.User) error
+	ImportPublicMessages(messages []entities.Message) error
+	ImportPrivateMessages(messages []entities.Message) error
+}
+
+// transferVersion is the version of the export format. An import reads the
+// exports of this version only.
+const transferVersion = 1
+
+const (
+	headerRecord         = "header"
+	userRecord           = "user"
+	publicMessageRecord  = "public_message"
+	privateMessageRecord = "private_message"
+)
+
+type TransferOptions struct {
+	// BatchSize is how many records are read from or written to the
+	// database at once.
+	BatchSize int
+	// MailDomain makes the addresses of the users in the mailboxes.
+	MailDomain string
+}
+
+// transferRecord is a line of an export. The first line is the header,
+// which holds the version; the users come next, then the public messages
+// and the private ones, every kind in the order it was written in.
+type transferRecord struct {
+	Type            string     `json:"type"`
+	Version         int        `json:"version,omitempty"`
+	ExportedAt      *time.Time `json:"exported_at,omitempty"`
+	Username        string     `json:"username,omitempty"`
+	PasswordHash    string     `json:"password_hash,omitempty"`
+	ID              int        `json:"id,omitempty"`
+	Sender          string     `json:"sender,omitempty"`
+	Recipient       string     `json:"recipient,omitempty"`
+	Content         string     `json:"content,omitempty"`
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+	TTLSeconds      int        `json:"ttl_seconds,omitempty"`
+	ExpireAfterRead bool       `json:"expire_after_read,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	Expired         bool       `json:"expired,omitempty"`
+	Deleted         bool       `json:"deleted,omitempty"`
+}
+
+// TransferService moves the data between the databases as JSON lines: the
+// users with the hashes of their passwords, the public messages and the
+// private conversations. The messages keep their senders and times but get
+// the IDs of the database they are imported to; attachments, reactions
+// and other data tied to the messages stay behind.
+type TransferService struct {
+	repos TransferRepository
+	opts  TransferOptions
+	now   func() time.Time
+}
+
+func NewTransferService(r TransferRepository, opts TransferOptions) *TransferService {
+	return &TransferService{repos: r, opts: opts, now: time.Now}
+}
+
+// Export writes all the data of the database to w.
+func (s *TransferService) Export(w io.Writer) (entities.TransferSummary, error) {
+	enc := json.NewEncoder(w)
+
+	var summary entities.TransferSummary
+	if err := s.writeHeader(enc, ""); err != nil {
+		return summary, err
+	}
+
+	err := s.eachUser(func(user entities.User) error {
+		summary.Users++
+
+		return enc.Encode(transferRecord{Type: userRecord, Username: user.Username, PasswordHash: user.Password})
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	err = s.eachPublicMessage(func(m entities.Message) error {
+		summary.PublicMessages++
+
+		return enc.Encode(messageToRecord(publicMessageRecord, m))
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	// Every conversation is written once, by the member whose name goes
+	// first.
+	err = s.eachUser(func(user entities.User) error {
+		partners, err := s.repos.GetPartners(user.Username)
+		if err != nil {
+			return err
+		}
+
+		for _, partner := range partners {
+			if partner < user.Username {
+				continue
+			}
+
+			err := s.eachPrivateMessage(user.Username, partner, func(m entities.Message) error {
+				summary.PrivateMessages++
+
+				return enc.Encode(messageToRecord(privateMessageRecord, m))
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return summary, err
+}
+
+// ExportUser writes the data of the user to w: the user without the hash of
+// their password, the public messages they sent and their private
+// conversations. The export is of the same format, but it is a copy for the
+// user, not for an import.
+func (s *TransferService) ExportUser(w io.Writer, username string) error {
+	enc := json.NewEncoder(w)
+
+	if err := s.writeHeader(enc, username); err != nil {
+		return err
+	}
+
+	if err := enc.Encode(transferRecord{Type: userRecord, Username: username}); err != nil {
+		return err
+	}
+
+	err := s.eachPublicMessage(func(m entities.Message) error {
+		if m.Sender != username {
+			return nil
+		}
+
+		return enc.Encode(messageToRecord(publicMessageRecord, m))
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.eachConversation(username, func(m entities.Message) error {
+		return enc.Encode(messageToRecord(privateMessageRecord, m))
+	})
+}
+
+// ExportMbox writes the private conversations of the user to w as a
+// mailbox, a conversation after another.
+func (s *TransferService) ExportMbox(w io.Writer, username string) error {
+	box := mbox.NewWriter(w)
+
+	return s.eachConversation(username, func(m entities.Message) error {
+		partner := m.Recipient
+		if partner == username {
+			partner = m.Sender
+		}
+
+		body := m.Content
+		if m.Expired {
+			body = "(the message has expired)"
+		}
+
+		return box.Write(mbox.Message{
+			From:      s.address(m.Sender),
+			To:        s.address(m.Recipient),
+			Date:      m.CreatedAt,
+			Subject:   "Private chat with " + partner,
+			MessageID: strconv.Itoa(m.ID) + "." + m.Sender + "." + m.Recipient + "@" + s.opts.MailDomain,
+			Body:      body,
+		})
+	})
+}
+
+// Import reads an export from r and adds its data to the database. The
+// users of the export must not be in the database yet; an import stopped
+// by an error leaves the records read before it in place.
+func (s *TransferService) Import(r io.Reader) (entities.TransferSummary, error) {
+	dec := json.NewDecoder(r)
+
+	var summary entities.TransferSummary
+
+	var header transferRecord
+	if err := dec.Decode(&header); err != nil || header.Type != headerRecord {
+		return summary, fmt.Errorf("%w: the first line is not a header", entities.ErrInvalidExportFile)
+	}
+
+	if header.Version != transferVersion {
+		return summary, fmt.Errorf("%w: %d", entities.ErrUnsupportedExport, header.Version)
+	}
+
+	var public, private []entities.Message
+	flush := func() error {
+		if len(public) > 0 {
+			if err := s.repos.ImportPublicMessages(public); err != nil {
+				return err
+			}
+			summary.PublicMessages += len(public)
+			public = public[:0]
+		}
+
+		if len(private) > 0 {
+			if err := s.repos.ImportPrivateMessages(private); err != nil {
+				return err
+			}
+			summary.PrivateMessages += len(private)
+			private = private[:0]
+		}
+
+		return nil
+	}
+
+	for line := 2; ; line++ {
+		var record transferRecord
+		err := dec.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return summary, fmt.Errorf("%w: line %d: %s", entities.ErrInvalidExportFile, line, err)
+		}
+
+		switch record.Type {
+		case userRecord:
+			if err := flush(); err != nil {
+				return summary, err
+			}
+
+			user := entities.User{Username: record.Username, Password: record.PasswordHash}
+			if user.Username == "" || user.Password == "" {
+				return summary, fmt.Errorf("%w: line %d: a user without a name or a password", entities.ErrInvalidExportFile, line)
+			}
+
+			if err := s.repos.ImportUser(user); err != nil {
+				return summary, fmt.Errorf("import %s: %w", user.Username, err)
+			}
+			summary.Users++
+		case publicMessageRecord:
+			if record.Sender == "" || record.CreatedAt == nil {
+				return summary, fmt.Errorf("%w: line %d: a message without a sender or a time", entities.ErrInvalidExportFile, line)
+			}
+
+			if len(private) > 0 {
+				if err := flush(); err != nil {
+					return summary, err
+				}
+			}
+
+			public = append(public, recordToMessage(record))
+		case privateMessageRecord:
+			if record.Sender == "" || record.Recipient == "" || record.CreatedAt == nil {
+				return summary, fmt.Errorf("%w: line %d: a message without a sender, a recipient or a time", entities.ErrInvalidExportFile, line)
+			}
+
+			if len(public) > 0 {
+				if err := flush(); err != nil {
+					return summary, err
+				}
+			}
+
+			private = append(private, recordToMessage(record))
+		default:
+			return summary, fmt.Errorf("%w: line %d: unknown record %q", entities.ErrInvalidExportFile, line, record.Type)
+		}
+
+		if len(public)+len(private) >= s.opts.BatchSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	return summary, flush()
+}
+
+func (s *TransferService) writeHeader(enc *json.Encoder, username string) error {
+	now := s.now().UTC()
+
+	return enc.Encode(transferRecord{Type: headerRecord, Version: transferVersion, ExportedAt: &now, Username: username})
+}
+
+func (s *TransferService) address(username string) mail.Address {
+	return mail.Address{Name: username, Address: username + "@" + s.opts.MailDomain}
+}
+
+// eachUser calls fn for every user, in the order of their names.
+func (s *TransferService) eachUser(fn func(user entities.User) error) error {
+	var after string
+	for {
+		users, err := s.repos.GetUsers(after, s.opts.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < s.opts.BatchSize {
+			return nil
+		}
+		after = users[len(users)-1].Username
+	}
+}
+
+// eachPublicMessage calls fn for every public message, the oldest first.
+func (s *TransferService) eachPublicMessage(fn func(m entities.Message) error) error {
+	var afterID int
+	for {
+		messages, err := s.repos.GetPublicMessages(afterID, s.opts.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range messages {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+
+		if len(messages) < s.opts.BatchSize {
+			return nil
+		}
+		afterID = messages[len(messages)-1].ID
+	}
+}
+
+// eachPrivateMessage calls fn for every message of the private chat of the
+// two users, the oldest first.
+func (s *TransferService) eachPrivateMessage(user1, user2 string, fn func(m entities.Message) error) error {
+	var afterID int
+	for {
+		messages, err := s.repos.GetPrivateMessages(user1, user2, afterID, s.opts.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range messages {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+
+		if len(messages) < s.opts.BatchSize {
+			return nil
+		}
+		afterID = messages[len(messages)-1].ID
+	}
+}
+
+// eachConversation calls fn for every message of the private chats of the
+// user, a chat after another in the order of the partners' names.
+func (s *TransferService) eachConversation(username string, fn func(m entities.Message) error) error {
+	partners, err := s.repos.GetPartners(username)
+	if err != nil {
+		return err
+	}
+
+	for _, partner := range partners {
+		if err := s.eachPrivateMessage(username, partner, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func messageToRecord(kind string, m entities.Message) transferRecord {
+	createdAt := m.CreatedAt.UTC()
+	record := transferRecord{
+		Type:            kind,
+		ID:              m.ID,
+		Sender:          m.Sender,
+		Recipient:       m.Recipient,
+		Content:         m.Content,
+		CreatedAt:       &createdAt,
+		TTLSeconds:      int(m.TTL / time.Second),
+		ExpireAfterRead: m.ExpireAfterRead,
+		Expired:         m.Expired,
+		Deleted:         m.Deleted,
+	}
+
+	if !m.ExpiresAt.IsZero() {
+		expiresAt := m.ExpiresAt.UTC()
+		record.ExpiresAt = &expiresAt
+	}
+
+	return record
+}
+
+func recordToMessage(record transferRecord) entities.Message {
+	m := entities.Message{
+		Sender:          record.Sender,
+		Recipient:       record.Recipient,
+		Content:         record.Content,
+		CreatedAt:       *record.CreatedAt,
+		TTL:             time.Duration(record.TTLSeconds) * time.Second,
+		ExpireAfterRead: record.ExpireAfterRead,
+		Expired:         record.Expired,
+		Deleted:         record.Deleted,
+	}
+
+	if record.ExpiresAt != nil {
+		m.ExpiresAt = *record.ExpiresAt
+	}
+
+	return m
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+)
+
+func TestTransferService_ExportImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sentAt := time.Date(2024, time.March, 5, 14, 2, 26, 0, time.UTC)
+
+	tester := entities.User{Username: "tester", Password: "hash1"}
+	valera := entities.User{Username: "valera", Password: "hash2"}
+	public := []entities.Message{
+		{ID: 3, Sender: "tester", Content: "hello", CreatedAt: sentAt},
+		{ID: 4, Sender: "valera", CreatedAt: sentAt.Add(time.Minute), Deleted: true},
+	}
+	private := []entities.Message{
+		{ID: 1, Sender: "tester", Recipient: "valera", Content: "hi", CreatedAt: sentAt},
+		{ID: 2, Sender: "valera", Recipient: "tester", Content: "secret", CreatedAt: sentAt.Add(time.Minute),
+			TTL: time.Hour, ExpiresAt: sentAt.Add(time.Hour + time.Minute)},
+	}
+
+	repo := mock_service.NewMockTransferRepository(ctrl)
+
+	// The pages go on while they are full.
+	repo.EXPECT().GetUsers("", 2).Return([]entities.User{tester, valera}, nil).Times(2)
+	repo.EXPECT().GetUsers("valera", 2).Return(nil, nil).Times(2)
+	repo.EXPECT().GetPublicMessages(0, 2).Return(public, nil)
+	repo.EXPECT().GetPublicMessages(4, 2).Return(nil, nil)
+	repo.EXPECT().GetPartners("tester").Return([]string{"valera"}, nil)
+	repo.EXPECT().GetPartners("valera").Return([]string{"tester"}, nil)
+	repo.EXPECT().GetPrivateMessages("tester", "valera", 0, 2).Return(private, nil)
+	repo.EXPECT().GetPrivateMessages("tester", "valera", 2, 2).Return(nil, nil)
+
+	s := NewTransferService(repo, TransferOptions{BatchSize: 2, MailDomain: "chat"})
+	s.now = func() time.Time { return sentAt.Add(time.Hour) }
+
+	var buf bytes.Buffer
+	summary, err := s.Export(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TransferSummary{Users: 2, PublicMessages: 2, PrivateMessages: 2}, summary)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 7)
+	assert.JSONEq(t, `{"type":"header","version":1,"exported_at":"2024-03-05T15:02:26Z"}`, lines[0])
+	assert.JSONEq(t, `{"type":"user","username":"tester","password_hash":"hash1"}`, lines[1])
+	assert.JSONEq(t, `{"type":"public_message","id":4,"sender":"valera","created_at":"2024-03-05T14:03:26Z","deleted":true}`, lines[4])
+	assert.JSONEq(t, `{"type":"private_message","id":2,"sender":"valera","recipient":"tester","content":"secret",`+
+		`"created_at":"2024-03-05T14:03:26Z","ttl_seconds":3600,"expires_at":"2024-03-05T15:03:26Z"}`, lines[6])
+
+	// The import gets the messages without the IDs of the export.
+	imported := func(messages []entities.Message) []entities.Message {
+		res := make([]entities.Message, 0, len(messages))
+		for _, m := range messages {
+			m.ID = 0
+			res = append(res, m)
+		}
+
+		return res
+	}
+
+	target := mock_service.NewMockTransferRepository(ctrl)
+	gomock.InOrder(
+		target.EXPECT().ImportUser(tester).Return(nil),
+		target.EXPECT().ImportUser(valera).Return(nil),
+		target.EXPECT().ImportPublicMessages(imported(public)).Return(nil),
+		target.EXPECT().ImportPrivateMessages(imported(private)).Return(nil),
+	)
+
+	summary, err = NewTransferService(target, TransferOptions{BatchSize: 2}).Import(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TransferSummary{Users: 2, PublicMessages: 2, PrivateMessages: 2}, summary)
+}
+
+func TestTransferService_Import(t *testing.T) {
+	header := `{"type":"header","version":1}` + "\n"
+
+	type mockBehavior func(r *mock_service.MockTransferRepository)
+
+	tests := []struct {
+		name          string
+		input         string
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name:          "Without header",
+			input:         `{"type":"user","username":"tester","password_hash":"hash"}`,
+			mockBehavior:  func(r *mock_service.MockTransferRepository) {},
+			expectedError: entities.ErrInvalidExportFile,
+		},
+		{
+			name:          "Unsupported version",
+			input:         `{"type":"header","version":2}`,
+			mockBehavior:  func(r *mock_service.MockTransferRepository) {},
+			expectedError: entities.ErrUnsupportedExport,
+		},
+		{
+			name:          "Unknown record",
+			input:         header + `{"type":"reaction"}`,
+			mockBehavior:  func(r *mock_service.MockTransferRepository) {},
+			expectedError: entities.ErrInvalidExportFile,
+		},
+		{
+			name:          "Message without time",
+			input:         header + `{"type":"public_message","sender":"tester","content":"hi"}`,
+			mockBehavior:  func(r *mock_service.MockTransferRepository) {},
+			expectedError: entities.ErrInvalidExportFile,
+		},
+		{
+			name:  "Batches",
+			input: header + strings.Repeat(`{"type":"public_message","sender":"tester","created_at":"2024-03-05T14:02:26Z"}`+"\n", 3),
+			mockBehavior: func(r *mock_service.MockTransferRepository) {
+				gomock.InOrder(
+					r.EXPECT().ImportPublicMessages(gomock.Len(2)).Return(nil),
+					r.EXPECT().ImportPublicMessages(gomock.Len(1)).Return(nil),
+				)
+			},
+		},
+		{
+			name:  "Unknown sender",
+			input: header + `{"type":"private_message","sender":"tester","recipient":"nobody","created_at":"2024-03-05T14:02:26Z"}`,
+			mockBehavior: func(r *mock_service.MockTransferRepository) {
+				r.EXPECT().ImportPrivateMessages(gomock.Len(1)).Return(entities.ErrUserNotFound)
+			},
+			expectedError: entities.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_service.NewMockTransferRepository(ctrl)
+			tt.mockBehavior(repo)
+
+			_, err := NewTransferService(repo, TransferOptions{BatchSize: 2}).Import(strings.NewReader(tt.input))
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransferService_ExportMbox(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sentAt := time.Date(2024, time.March, 5, 14, 2, 26, 0, time.UTC)
+
+	repo := mock_service.NewMockTransferRepository(ctrl)
+	repo.EXPECT().GetPartners("valera").Return([]string{"tester"}, nil)
+	repo.EXPECT().GetPrivateMessages("valera", "tester", 0, 10).Return([]entities.Message{
+		{ID: 1, Sender: "tester", Recipient: "valera", Content: "hi", CreatedAt: sentAt},
+		{ID: 2, Sender: "valera", Recipient: "tester", CreatedAt: sentAt, Expired: true},
+	}, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, NewTransferService(repo, TransferOptions{BatchSize: 10, MailDomain: "chat.example"}).ExportMbox(&buf, "valera"))
+
+	out := buf.String()
+	assert.Equal(t, 2, strings.Count(out, "\nSubject: Private chat with tester\n"))
+	assert.Contains(t, out, "From tester@chat.example Tue Mar  5 14:02:26 2024\n")
+	assert.Contains(t, out, "From: \"tester\" <tester@chat.example>\nTo: \"valera\" <valera@chat.example>\n")
+	assert.Contains(t, out, "Message-ID: <1.tester.valera@chat.example>\n")
+	assert.Contains(t, out, "\n\n(the message has expired)\n")
+}
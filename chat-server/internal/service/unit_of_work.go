@@ -0,0 +1,23 @@
+package service
+
+// Repositories are the repositories a unit of work runs with.
+type Repositories struct {
+	Auth    AuthRepository
+	Privacy PrivacyRepository
+	Private PrivateRepository
+}
+
+// UnitOfWork runs fn with repositories whose changes are kept all together
+// or not at all: they are rolled back if fn returns an error or panics. The
+// repositories must not be used once fn returns.
+type UnitOfWork interface {
+	Do(fn func(r Repositories) error) error
+}
+
+// UnitOfWorkFunc lets a function run the units of work. The tests run fn
+// with the mocks this way, as a mock of UnitOfWork would import the package.
+type UnitOfWorkFunc func(fn func(r Repositories) error) error
+
+func (f UnitOfWorkFunc) Do(fn func(r Repositories) error) error {
+	return f(fn)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vavelour/chat/internal/domain/entities"
+	"github.com/vavelour/chat/pkg/webhook"
+)
+
+const (
+	webhookUserAgent = "chat-webhooks/1.0"
+	maxErrorLength   = 255
+)
+
+//go:generate mockgen -source=webhook_service.go -destination=mocks/webhook_repository_mock.go
+
+type WebhookRepository interface {
+	InsertWebhook(w entities.Webhook) (entities.Webhook, error)
+	GetWebhooks() ([]entities.Webhook, error)
+	GetWebhook(id int) (entities.Webhook, error)
+	DeleteWebhook(id int) error
+	SetWebhookActive(id int, active bool) error
+	GetDeliveries(webhookID, limit, offset int) ([]entities.WebhookDelivery, error)
+	ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]entities.WebhookDelivery, error)
+	RecordAttempt(d entities.WebhookDelivery, disableAfter int) error
+}
+
+type WebhookOptions struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	DisableAfter int
+	BatchSize    int
+	Lease        time.Duration
+}
+
+type webhookPayload struct {
+	Event     entities.WebhookEventType `json:"event"`
+	EventID   int                       `json:"event_id"`
+	Channel   string                    `json:"channel"`
+	CreatedAt time.Time                 `json:"created_at"`
+	Message   webhookMessage            `json:"message"`
+}
+
+type webhookMessage struct {
+	ID        int       `json:"id"`
+	Sender    string    `json:"sender"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookService manages webhook subscriptions and delivers the queued
+// events. Events are queued by the repositories together with the message,
+// so none is lost if the server stops before they are sent.
+type WebhookService struct {
+	repos  WebhookRepository
+	client *http.Client
+	opts   WebhookOptions
+	now    func() time.Time
+}
+
+func NewWebhookService(r WebhookRepository, client *http.Client, opts WebhookOptions) *WebhookService {
+	if client == nil {
+		client = &http.Client{Timeout: opts.Timeout}
+	}
+
+	return &WebhookService{repos: r, client: client, opts: opts, now: time.Now}
+}
+
+// Create registers the webhook. A secret is generated when none is given;
+// it is returned only here.
+func (s *WebhookService) Create(w entities.Webhook) (entities.Webhook, error) {
+	if w.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return entities.Webhook{}, err
+		}
+
+		w.Secret = hex.EncodeToString(secret)
+	}
+
+	w.Active = true
+
+	return s.repos.InsertWebhook(w)
+}
+
+func (s *WebhookService) List() ([]entities.Webhook, error) {
+	return s.repos.GetWebhooks()
+}
+
+func (s *WebhookService) Delete(id int) error {
+	return s.repos.DeleteWebhook(id)
+}
+
+// Enable turns a disabled webhook back on. Its pending deliveries are sent
+// again from where they stopped.
+func (s *WebhookService) Enable(id int) error {
+	return s.repos.SetWebhookActive(id, true)
+}
+
+func (s *WebhookService) Deliveries(webhookID, limit, offset int) ([]entities.WebhookDelivery, error) {
+	return s.repos.GetDeliveries(webhookID, limit, offset)
+}
+
+// Run delivers the queued events until ctx is done.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A full batch means the queue is backed up, so go on at once.
+			for n := s.deliverBatch(ctx); n > 0 && n == s.opts.BatchSize && ctx.Err() == nil; n = s.deliverBatch(ctx) {
+			}
+		}
+	}
+}
+
+// deliverBatch sends one batch of due deliveries concurrently and returns
+// how many there were.
+func (s *WebhookService) deliverBatch(ctx context.Context) int {
+	deliveries, err := s.repos.ClaimDeliveries(s.now(), s.opts.Lease, s.opts.BatchSize)
+	if err != nil {
+		log.Printf("webhooks: claim deliveries: %s", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func(d entities.WebhookDelivery) {
+			defer wg.Done()
+
+			if err := s.repos.RecordAttempt(s.deliver(ctx, d), s.opts.DisableAfter); err != nil {
+				log.Printf("webhooks: record delivery %d: %s", d.ID, err)
+			}
+		}(d)
+	}
+
+	wg.Wait()
+
+	return len(deliveries)
+}
+
+// deliver makes one attempt and returns the delivery updated with its
+// outcome. Failed attempts are retried with exponential back-off until
+// MaxAttempts is reached.
+func (s *WebhookService) deliver(ctx context.Context, d entities.WebhookDelivery) entities.WebhookDelivery {
+	d.Attempts++
+	d.ResponseStatus = 0
+	d.LastError = ""
+
+	status, err := s.send(ctx, d)
+	d.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		d.Status = entities.DeliverySucceeded
+	case d.Attempts >= s.opts.MaxAttempts:
+		d.Status = entities.DeliveryFailed
+		d.LastError = truncate(err.Error(), maxErrorLength)
+	default:
+		d.Status = entities.DeliveryPending
+		d.LastError = truncate(err.Error(), maxErrorLength)
+		d.NextAttemptAt = s.now().Add(s.backoff(d.Attempts))
+	}
+
+	return d
+}
+
+func (s *WebhookService) send(ctx context.Context, d entities.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(webhookPayload{
+		Event:     d.Event.Type,
+		EventID:   d.Event.ID,
+		Channel:   d.Event.Channel,
+		CreatedAt: d.Event.CreatedAt,
+		Message: webhookMessage{
+			ID:        d.Event.Message.ID,
+			Sender:    d.Event.Message.Sender,
+			Content:   d.Event.Message.Content,
+			CreatedAt: d.Event.Message.CreatedAt,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(webhook.EventHeader, string(d.Event.Type))
+	req.Header.Set(webhook.DeliveryHeader, strconv.Itoa(d.ID))
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(d.Webhook.Secret, now, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff doubles the delay after every failed attempt, up to BackoffMax.
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.opts.BackoffBase
+	for i := 1; i < attempts && delay < s.opts.BackoffMax; i++ {
+		delay *= 2
+	}
+
+	if delay > s.opts.BackoffMax {
+		delay = s.opts.BackoffMax
+	}
+
+	return delay
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vavelour/chat/internal/domain/entities"
+	mock_service "github.com/vavelour/chat/internal/service/mocks"
+	"github.com/vavelour/chat/pkg/webhook"
+)
+
+func TestWebhookService_deliverBatch(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	opts := WebhookOptions{MaxAttempts: 3, BackoffBase: 10 * time.Second, BackoffMax: time.Minute, DisableAfter: 5, BatchSize: 10, Lease: time.Minute}
+
+	testTable := []struct {
+		name           string
+		receiverStatus int
+		attempts       int
+		expected       entities.WebhookDelivery
+	}{
+		{
+			name:           "succeeded",
+			receiverStatus: http.StatusNoContent,
+			expected:       entities.WebhookDelivery{Status: entities.DeliverySucceeded, Attempts: 1, ResponseStatus: 204},
+		},
+		{
+			name:           "retry_with_backoff",
+			receiverStatus: http.StatusInternalServerError,
+			attempts:       1,
+			expected: entities.WebhookDelivery{Status: entities.DeliveryPending, Attempts: 2, ResponseStatus: 500,
+				LastError: "unexpected status 500", NextAttemptAt: now.Add(20 * time.Second)},
+		},
+		{
+			name:           "failed_after_max_attempts",
+			receiverStatus: http.StatusBadGateway,
+			attempts:       2,
+			expected:       entities.WebhookDelivery{Status: entities.DeliveryFailed, Attempts: 3, ResponseStatus: 502, LastError: "unexpected status 502"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var received webhookPayload
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+
+				err := webhook.Verify("topsecret", r.Header.Get(webhook.SignatureHeader), r.Header.Get(webhook.TimestampHeader), body, now, time.Minute)
+				assert.NoError(t, err)
+				assert.Equal(t, "message.created", r.Header.Get(webhook.EventHeader))
+				assert.Equal(t, "7", r.Header.Get(webhook.DeliveryHeader))
+				assert.NoError(t, json.Unmarshal(body, &received))
+
+				w.WriteHeader(testCase.receiverStatus)
+			}))
+			defer receiver.Close()
+
+			delivery := entities.WebhookDelivery{
+				ID:       7,
+				Webhook:  entities.Webhook{ID: 1, URL: receiver.URL, Secret: "topsecret", Active: true},
+				Event:    entities.WebhookEvent{ID: 3, Type: entities.EventMessageCreated, Channel: entities.PublicChannel, Message: entities.Message{ID: 5, Sender: "valera", Content: "hi"}, CreatedAt: now},
+				Status:   entities.DeliveryPending,
+				Attempts: testCase.attempts,
+			}
+
+			expected := testCase.expected
+			expected.ID, expected.Webhook, expected.Event = delivery.ID, delivery.Webhook, delivery.Event
+
+			repo := mock_service.NewMockWebhookRepository(ctrl)
+			repo.EXPECT().ClaimDeliveries(now, time.Minute, 10).Return([]entities.WebhookDelivery{delivery}, nil)
+			repo.EXPECT().RecordAttempt(expected, 5).Return(nil)
+
+			s := NewWebhookService(repo, receiver.Client(), opts)
+			s.now = func() time.Time { return now }
+
+			assert.Equal(t, 1, s.deliverBatch(context.Background()))
+			assert.Equal(t, 5, received.Message.ID)
+			assert.Equal(t, "hi", received.Message.Content)
+		})
+	}
+}
+
+func TestWebhookService_backoff(t *testing.T) {
+	s := NewWebhookService(nil, nil, WebhookOptions{BackoffBase: 10 * time.Second, BackoffMax: time.Minute})
+
+	assert.Equal(t, 10*time.Second, s.backoff(1))
+	assert.Equal(t, 40*time.Second, s.backoff(3))
+	assert.Equal(t, time.Minute, s.backoff(4))
+	assert.Equal(t, time.Minute, s.backoff(30))
+}
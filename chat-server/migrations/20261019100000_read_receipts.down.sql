@@ -0,0 +1,3 @@
+DROP INDEX private_chats_sender_recipient_id_idx;
+
+DROP TABLE private_chat_reads;
@@ -0,0 +1,9 @@
+CREATE TABLE private_chat_reads
+(
+    reader_id INTEGER REFERENCES users(id),
+    partner_id INTEGER REFERENCES users(id),
+    last_read_message_id INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (reader_id, partner_id)
+);
+
+CREATE INDEX private_chats_sender_recipient_id_idx ON private_chats (sender_id, recipient_id, id);
@@ -0,0 +1,5 @@
+DROP TABLE conversations;
+
+ALTER TABLE private_chats DROP COLUMN created_at;
+
+ALTER TABLE global_chat DROP COLUMN created_at;
@@ -0,0 +1,24 @@
+ALTER TABLE global_chat ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE private_chats ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE TABLE conversations
+(
+    user_id INTEGER REFERENCES users(id),
+    partner_id INTEGER REFERENCES users(id),
+    last_message_id INTEGER NOT NULL REFERENCES private_chats(id),
+    last_message_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, partner_id)
+);
+
+CREATE INDEX conversations_user_activity_idx ON conversations (user_id, last_message_at DESC, partner_id);
+
+INSERT INTO conversations(user_id, partner_id, last_message_id, last_message_at)
+SELECT user_id, partner_id, MAX(id), MAX(created_at)
+FROM (
+    SELECT sender_id AS user_id, recipient_id AS partner_id, id, created_at FROM private_chats
+    UNION ALL
+    SELECT recipient_id AS user_id, sender_id AS partner_id, id, created_at FROM private_chats
+) AS members
+WHERE user_id IS NOT NULL AND partner_id IS NOT NULL
+GROUP BY user_id, partner_id;
@@ -0,0 +1,16 @@
+CREATE TABLE attachments
+(
+    id VARCHAR(64) PRIMARY KEY,
+    global_message_id INTEGER REFERENCES global_chat(id) ON DELETE CASCADE,
+    private_message_id INTEGER REFERENCES private_chats(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((global_message_id IS NULL) <> (private_message_id IS NULL))
+);
+
+CREATE INDEX attachments_global_message_id_idx ON attachments (global_message_id, position) WHERE global_message_id IS NOT NULL;
+
+CREATE INDEX attachments_private_message_id_idx ON attachments (private_message_id, position) WHERE private_message_id IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    DROP COLUMN avatar_updated_at,
+    DROP COLUMN avatar_content_type,
+    DROP COLUMN avatar_id;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN avatar_id VARCHAR(64),
+    ADD COLUMN avatar_content_type VARCHAR(255),
+    ADD COLUMN avatar_updated_at TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+DROP INDEX private_chats_search_idx;
+
+ALTER TABLE private_chats DROP COLUMN search_vector;
+
+DROP INDEX global_chat_search_idx;
+
+ALTER TABLE global_chat DROP COLUMN search_vector;
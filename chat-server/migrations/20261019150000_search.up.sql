@@ -0,0 +1,9 @@
+ALTER TABLE global_chat
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', message)) STORED;
+
+CREATE INDEX global_chat_search_idx ON global_chat USING GIN (search_vector);
+
+ALTER TABLE private_chats
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', message)) STORED;
+
+CREATE INDEX private_chats_search_idx ON private_chats USING GIN (search_vector);
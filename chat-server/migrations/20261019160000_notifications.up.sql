@@ -0,0 +1,16 @@
+CREATE TABLE notifications
+(
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    global_message_id INTEGER REFERENCES global_chat(id) ON DELETE CASCADE,
+    private_message_id INTEGER REFERENCES private_chats(id) ON DELETE CASCADE,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((global_message_id IS NULL) <> (private_message_id IS NULL))
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, id DESC);
+
+CREATE INDEX notifications_unread_idx ON notifications (user_id, id DESC) WHERE read_at IS NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE bots
+(
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE incoming_webhooks
+(
+    id SERIAL PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    channel VARCHAR(64) NOT NULL,
+    bot_id INTEGER NOT NULL REFERENCES bots(user_id),
+    created_by VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ
+);
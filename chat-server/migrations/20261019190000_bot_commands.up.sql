@@ -0,0 +1,11 @@
+CREATE TABLE bot_commands
+(
+    id SERIAL PRIMARY KEY,
+    command VARCHAR(32) NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    bot_id INTEGER NOT NULL REFERENCES bots(user_id),
+    secret VARCHAR(255) NOT NULL,
+    created_by VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,17 @@
+CREATE TABLE scheduled_messages
+(
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(16) NOT NULL,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    send_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX scheduled_messages_due_idx ON scheduled_messages (send_at, id) WHERE status = 'pending';
+
+CREATE INDEX scheduled_messages_sender_id_idx ON scheduled_messages (sender_id, send_at, id) WHERE status <> 'sent';
@@ -0,0 +1,17 @@
+CREATE TABLE reminders
+(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    content TEXT NOT NULL,
+    schedule VARCHAR(128) NOT NULL,
+    time_zone VARCHAR(64) NOT NULL,
+    sender_id INTEGER NOT NULL REFERENCES bots(user_id),
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_by VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX reminders_due_idx ON reminders (next_run_at, id) WHERE NOT paused;
@@ -0,0 +1,7 @@
+DROP INDEX private_chats_expires_at_idx;
+
+ALTER TABLE private_chats
+    DROP COLUMN expired,
+    DROP COLUMN expires_at,
+    DROP COLUMN expire_after_read,
+    DROP COLUMN ttl_seconds;
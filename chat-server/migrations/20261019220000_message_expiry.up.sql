@@ -0,0 +1,7 @@
+ALTER TABLE private_chats
+    ADD COLUMN ttl_seconds INTEGER CHECK (ttl_seconds > 0),
+    ADD COLUMN expire_after_read BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN expires_at TIMESTAMPTZ,
+    ADD COLUMN expired BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX private_chats_expires_at_idx ON private_chats (expires_at, id) WHERE expires_at IS NOT NULL AND NOT expired;
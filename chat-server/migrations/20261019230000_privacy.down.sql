@@ -0,0 +1,7 @@
+ALTER TABLE conversations DROP COLUMN is_request;
+
+DROP TABLE contacts;
+
+DROP TABLE blocks;
+
+DROP TABLE privacy_settings;
@@ -0,0 +1,30 @@
+CREATE TABLE privacy_settings
+(
+    user_id INTEGER PRIMARY KEY REFERENCES users(id),
+    dm_policy TEXT NOT NULL DEFAULT 'anyone' CHECK (dm_policy IN ('anyone', 'contacts', 'nobody')),
+    hide_blocked_in_feed BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE blocks
+(
+    user_id INTEGER REFERENCES users(id),
+    blocked_id INTEGER REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, blocked_id),
+    CHECK (user_id <> blocked_id)
+);
+
+CREATE TABLE contacts
+(
+    user_id INTEGER REFERENCES users(id),
+    contact_id INTEGER REFERENCES users(id),
+    PRIMARY KEY (user_id, contact_id)
+);
+
+-- Everybody has already talked to the people they sent a message to, so the
+-- existing conversations are not turned into message requests.
+INSERT INTO contacts(user_id, contact_id)
+SELECT DISTINCT sender_id, recipient_id FROM private_chats
+WHERE sender_id IS NOT NULL AND recipient_id IS NOT NULL;
+
+ALTER TABLE conversations ADD COLUMN is_request BOOLEAN NOT NULL DEFAULT FALSE;
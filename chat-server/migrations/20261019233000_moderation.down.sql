@@ -0,0 +1,7 @@
+DROP TABLE moderation_log;
+
+DROP TABLE sanctions;
+
+DROP TABLE reports;
+
+ALTER TABLE global_chat DROP COLUMN deleted;
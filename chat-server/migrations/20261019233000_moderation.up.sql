@@ -0,0 +1,44 @@
+ALTER TABLE global_chat ADD COLUMN deleted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE reports
+(
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL REFERENCES global_chat(id) ON DELETE CASCADE,
+    reporter_id INTEGER NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_by INTEGER REFERENCES users(id),
+    resolved_at TIMESTAMPTZ
+);
+
+-- A user reports a message once until the report is closed.
+CREATE UNIQUE INDEX reports_open_idx ON reports (message_id, reporter_id) WHERE status = 'open';
+
+CREATE INDEX reports_status_idx ON reports (status, id);
+
+CREATE TABLE sanctions
+(
+    user_id INTEGER REFERENCES users(id),
+    kind TEXT CHECK (kind IN ('mute', 'ban', 'suspend')),
+    reason TEXT NOT NULL DEFAULT '',
+    until TIMESTAMPTZ,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, kind)
+);
+
+-- The log keeps the IDs of the messages and reports without foreign keys,
+-- so it outlives them.
+CREATE TABLE moderation_log
+(
+    id SERIAL PRIMARY KEY,
+    moderator_id INTEGER NOT NULL REFERENCES users(id),
+    action TEXT NOT NULL,
+    target_id INTEGER REFERENCES users(id),
+    message_id INTEGER,
+    report_id INTEGER,
+    reason TEXT NOT NULL DEFAULT '',
+    until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
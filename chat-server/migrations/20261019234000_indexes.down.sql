@@ -0,0 +1,117 @@
+DROP INDEX reports_reporter_id_idx;
+
+DROP INDEX notifications_sender_id_idx;
+
+DROP INDEX contacts_contact_id_idx;
+
+DROP INDEX blocks_blocked_id_idx;
+
+DROP INDEX private_chat_reads_partner_id_idx;
+
+DROP INDEX conversations_partner_id_idx;
+
+DROP INDEX private_chats_recipient_id_idx;
+
+DROP INDEX global_chat_sender_id_idx;
+
+DROP INDEX conversations_user_request_activity_idx;
+
+CREATE INDEX conversations_user_activity_idx ON conversations (user_id, last_message_at DESC, partner_id);
+
+DROP INDEX private_chats_pair_idx;
+
+ALTER TABLE private_chats
+    ALTER COLUMN sender_id DROP NOT NULL,
+    ALTER COLUMN recipient_id DROP NOT NULL;
+
+ALTER TABLE global_chat ALTER COLUMN sender_id DROP NOT NULL;
+
+ALTER TABLE global_chat
+    DROP CONSTRAINT global_chat_sender_id_fkey,
+    ADD CONSTRAINT global_chat_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES users(id);
+
+ALTER TABLE private_chats
+    DROP CONSTRAINT private_chats_sender_id_fkey,
+    ADD CONSTRAINT private_chats_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES users(id);
+
+ALTER TABLE private_chats
+    DROP CONSTRAINT private_chats_recipient_id_fkey,
+    ADD CONSTRAINT private_chats_recipient_id_fkey FOREIGN KEY (recipient_id) REFERENCES users(id);
+
+ALTER TABLE private_chat_reads
+    DROP CONSTRAINT private_chat_reads_reader_id_fkey,
+    ADD CONSTRAINT private_chat_reads_reader_id_fkey FOREIGN KEY (reader_id) REFERENCES users(id);
+
+ALTER TABLE private_chat_reads
+    DROP CONSTRAINT private_chat_reads_partner_id_fkey,
+    ADD CONSTRAINT private_chat_reads_partner_id_fkey FOREIGN KEY (partner_id) REFERENCES users(id);
+
+ALTER TABLE conversations
+    DROP CONSTRAINT conversations_user_id_fkey,
+    ADD CONSTRAINT conversations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+ALTER TABLE conversations
+    DROP CONSTRAINT conversations_partner_id_fkey,
+    ADD CONSTRAINT conversations_partner_id_fkey FOREIGN KEY (partner_id) REFERENCES users(id);
+
+ALTER TABLE conversations
+    DROP CONSTRAINT conversations_last_message_id_fkey,
+    ADD CONSTRAINT conversations_last_message_id_fkey FOREIGN KEY (last_message_id) REFERENCES private_chats(id);
+
+ALTER TABLE conversations ALTER COLUMN last_message_id SET NOT NULL;
+
+ALTER TABLE privacy_settings
+    DROP CONSTRAINT privacy_settings_user_id_fkey,
+    ADD CONSTRAINT privacy_settings_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+ALTER TABLE blocks
+    DROP CONSTRAINT blocks_user_id_fkey,
+    ADD CONSTRAINT blocks_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+ALTER TABLE blocks
+    DROP CONSTRAINT blocks_blocked_id_fkey,
+    ADD CONSTRAINT blocks_blocked_id_fkey FOREIGN KEY (blocked_id) REFERENCES users(id);
+
+ALTER TABLE contacts
+    DROP CONSTRAINT contacts_user_id_fkey,
+    ADD CONSTRAINT contacts_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+ALTER TABLE contacts
+    DROP CONSTRAINT contacts_contact_id_fkey,
+    ADD CONSTRAINT contacts_contact_id_fkey FOREIGN KEY (contact_id) REFERENCES users(id);
+
+ALTER TABLE incoming_webhooks
+    DROP CONSTRAINT incoming_webhooks_bot_id_fkey,
+    ADD CONSTRAINT incoming_webhooks_bot_id_fkey FOREIGN KEY (bot_id) REFERENCES bots(user_id);
+
+ALTER TABLE bot_commands
+    DROP CONSTRAINT bot_commands_bot_id_fkey,
+    ADD CONSTRAINT bot_commands_bot_id_fkey FOREIGN KEY (bot_id) REFERENCES bots(user_id);
+
+ALTER TABLE reminders
+    DROP CONSTRAINT reminders_sender_id_fkey,
+    ADD CONSTRAINT reminders_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES bots(user_id);
+
+ALTER TABLE reports
+    DROP CONSTRAINT reports_reporter_id_fkey,
+    ADD CONSTRAINT reports_reporter_id_fkey FOREIGN KEY (reporter_id) REFERENCES users(id);
+
+ALTER TABLE reports
+    DROP CONSTRAINT reports_resolved_by_fkey,
+    ADD CONSTRAINT reports_resolved_by_fkey FOREIGN KEY (resolved_by) REFERENCES users(id);
+
+ALTER TABLE sanctions
+    DROP CONSTRAINT sanctions_user_id_fkey,
+    ADD CONSTRAINT sanctions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+ALTER TABLE sanctions
+    DROP CONSTRAINT sanctions_created_by_fkey,
+    ADD CONSTRAINT sanctions_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id);
+
+ALTER TABLE moderation_log
+    DROP CONSTRAINT moderation_log_moderator_id_fkey,
+    ADD CONSTRAINT moderation_log_moderator_id_fkey FOREIGN KEY (moderator_id) REFERENCES users(id);
+
+ALTER TABLE moderation_log
+    DROP CONSTRAINT moderation_log_target_id_fkey,
+    ADD CONSTRAINT moderation_log_target_id_fkey FOREIGN KEY (target_id) REFERENCES users(id);
@@ -0,0 +1,147 @@
+-- Deleting a user deletes what belongs to them: their side of the
+-- conversations, their settings, blocks and contacts, and their bots'
+-- commands. The messages are not theirs alone: a user cannot be deleted
+-- while messages they sent or received are kept, which the retention purge
+-- removes unless a legal hold keeps them. The moderation log keeps the
+-- moderators, who cannot be deleted while it names them, and forgets the
+-- other users it names.
+ALTER TABLE global_chat
+    DROP CONSTRAINT global_chat_sender_id_fkey,
+    ADD CONSTRAINT global_chat_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE private_chats
+    DROP CONSTRAINT private_chats_sender_id_fkey,
+    ADD CONSTRAINT private_chats_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE private_chats
+    DROP CONSTRAINT private_chats_recipient_id_fkey,
+    ADD CONSTRAINT private_chats_recipient_id_fkey FOREIGN KEY (recipient_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE private_chat_reads
+    DROP CONSTRAINT private_chat_reads_reader_id_fkey,
+    ADD CONSTRAINT private_chat_reads_reader_id_fkey FOREIGN KEY (reader_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE private_chat_reads
+    DROP CONSTRAINT private_chat_reads_partner_id_fkey,
+    ADD CONSTRAINT private_chat_reads_partner_id_fkey FOREIGN KEY (partner_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT conversations_user_id_fkey,
+    ADD CONSTRAINT conversations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT conversations_partner_id_fkey,
+    ADD CONSTRAINT conversations_partner_id_fkey FOREIGN KEY (partner_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Removing the last message of a conversation does not remove the
+-- conversation: the repositories move it to the message before, and the
+-- reference is only cleared if they did not.
+ALTER TABLE conversations ALTER COLUMN last_message_id DROP NOT NULL;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT conversations_last_message_id_fkey,
+    ADD CONSTRAINT conversations_last_message_id_fkey FOREIGN KEY (last_message_id) REFERENCES private_chats(id) ON DELETE SET NULL;
+
+ALTER TABLE privacy_settings
+    DROP CONSTRAINT privacy_settings_user_id_fkey,
+    ADD CONSTRAINT privacy_settings_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE blocks
+    DROP CONSTRAINT blocks_user_id_fkey,
+    ADD CONSTRAINT blocks_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE blocks
+    DROP CONSTRAINT blocks_blocked_id_fkey,
+    ADD CONSTRAINT blocks_blocked_id_fkey FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE contacts
+    DROP CONSTRAINT contacts_user_id_fkey,
+    ADD CONSTRAINT contacts_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE contacts
+    DROP CONSTRAINT contacts_contact_id_fkey,
+    ADD CONSTRAINT contacts_contact_id_fkey FOREIGN KEY (contact_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE incoming_webhooks
+    DROP CONSTRAINT incoming_webhooks_bot_id_fkey,
+    ADD CONSTRAINT incoming_webhooks_bot_id_fkey FOREIGN KEY (bot_id) REFERENCES bots(user_id) ON DELETE CASCADE;
+
+ALTER TABLE bot_commands
+    DROP CONSTRAINT bot_commands_bot_id_fkey,
+    ADD CONSTRAINT bot_commands_bot_id_fkey FOREIGN KEY (bot_id) REFERENCES bots(user_id) ON DELETE CASCADE;
+
+ALTER TABLE reminders
+    DROP CONSTRAINT reminders_sender_id_fkey,
+    ADD CONSTRAINT reminders_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES bots(user_id) ON DELETE CASCADE;
+
+ALTER TABLE reports
+    DROP CONSTRAINT reports_reporter_id_fkey,
+    ADD CONSTRAINT reports_reporter_id_fkey FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE reports
+    DROP CONSTRAINT reports_resolved_by_fkey,
+    ADD CONSTRAINT reports_resolved_by_fkey FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE sanctions
+    DROP CONSTRAINT sanctions_user_id_fkey,
+    ADD CONSTRAINT sanctions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE sanctions
+    DROP CONSTRAINT sanctions_created_by_fkey,
+    ADD CONSTRAINT sanctions_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE moderation_log
+    DROP CONSTRAINT moderation_log_moderator_id_fkey,
+    ADD CONSTRAINT moderation_log_moderator_id_fkey FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE moderation_log
+    DROP CONSTRAINT moderation_log_target_id_fkey,
+    ADD CONSTRAINT moderation_log_target_id_fkey FOREIGN KEY (target_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- The repositories never write a message without a sender or a recipient.
+-- One written by hand is not deleted here: the migration stops, for it to be
+-- looked at first.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM global_chat WHERE sender_id IS NULL) THEN
+        RAISE EXCEPTION 'global_chat has messages without a sender';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM private_chats WHERE sender_id IS NULL OR recipient_id IS NULL) THEN
+        RAISE EXCEPTION 'private_chats has messages without a sender or a recipient';
+    END IF;
+END
+$$;
+
+ALTER TABLE global_chat ALTER COLUMN sender_id SET NOT NULL;
+
+ALTER TABLE private_chats
+    ALTER COLUMN sender_id SET NOT NULL,
+    ALTER COLUMN recipient_id SET NOT NULL;
+
+-- The history of a conversation, in both directions, in the order of the
+-- messages.
+CREATE INDEX private_chats_pair_idx ON private_chats ((LEAST(sender_id, recipient_id)), (GREATEST(sender_id, recipient_id)), id);
+
+-- The inbox and the message requests of a user, the latest first.
+DROP INDEX conversations_user_activity_idx;
+
+CREATE INDEX conversations_user_request_activity_idx ON conversations (user_id, is_request, last_message_at DESC, partner_id);
+
+-- The other sides of the foreign keys, for the deletes to cascade without
+-- scanning the tables.
+CREATE INDEX global_chat_sender_id_idx ON global_chat (sender_id);
+
+CREATE INDEX private_chats_recipient_id_idx ON private_chats (recipient_id);
+
+CREATE INDEX conversations_partner_id_idx ON conversations (partner_id);
+
+CREATE INDEX private_chat_reads_partner_id_idx ON private_chat_reads (partner_id);
+
+CREATE INDEX blocks_blocked_id_idx ON blocks (blocked_id);
+
+CREATE INDEX contacts_contact_id_idx ON contacts (contact_id);
+
+CREATE INDEX notifications_sender_id_idx ON notifications (sender_id);
+
+CREATE INDEX reports_reporter_id_idx ON reports (reporter_id);
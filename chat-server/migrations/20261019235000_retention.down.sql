@@ -0,0 +1,5 @@
+DROP INDEX private_chats_created_at_idx;
+
+DROP INDEX global_chat_created_at_idx;
+
+DROP TABLE legal_holds;
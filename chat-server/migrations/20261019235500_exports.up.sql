@@ -0,0 +1,11 @@
+-- The latest export of the data of every user. The archives are kept in the
+-- blob store under exports/, which the server sweeps of the expired ones.
+CREATE TABLE data_exports
+(
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    id TEXT NOT NULL UNIQUE,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'ready', 'failed')),
+    requested_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ,
+    size BIGINT NOT NULL DEFAULT 0
+);
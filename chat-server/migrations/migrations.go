@@ -0,0 +1,9 @@
+// Package migrations holds the schema of the Postgres database. The server
+// applies the up migrations at startup; the down migrations are for the
+// migrate CLI.
+package migrations
+
+import "embed"
+
+//go:embed *.up.sql
+var FS embed.FS
@@ -0,0 +1,83 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	err = store.Put(ctx, "2026/10/photo.png", strings.NewReader("content"), 7, "image/png")
+	assert.NoError(t, err)
+
+	r, err := store.Get(ctx, "2026/10/photo.png")
+	assert.NoError(t, err)
+
+	content, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "content", string(content))
+
+	assert.NoError(t, store.Delete(ctx, "2026/10/photo.png"))
+	assert.NoError(t, store.Delete(ctx, "2026/10/photo.png"))
+
+	_, err = store.Get(ctx, "2026/10/photo.png")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestLocalStore_InvalidKey(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "a/../../b", "a//b", `a\b`} {
+		t.Run(key, func(t *testing.T) {
+			err := store.Put(context.Background(), key, strings.NewReader("x"), 1, "")
+			assert.Equal(t, ErrInvalidKey, err)
+		})
+	}
+}
+
+func TestLocalStore_List(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	store, err := NewLocalStore(dir)
+	require.NoError(t, err)
+
+	blobs, err := store.List(ctx, "exports/")
+	require.NoError(t, err)
+	assert.Empty(t, blobs)
+
+	for _, key := range []string{"exports/a.zip", "exports/b.zip", "2026/10/photo.png"} {
+		require.NoError(t, store.Put(ctx, key, strings.NewReader("x"), 1, ""))
+	}
+
+	// An upload not finished yet is not a blob.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "exports", ".upload-1"), nil, 0o600))
+
+	blobs, err = store.List(ctx, "exports/")
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+	assert.Equal(t, "exports/a.zip", blobs[0].Key)
+	assert.Equal(t, "exports/b.zip", blobs[1].Key)
+	assert.False(t, blobs[0].Modified.IsZero())
+
+	blobs, err = store.List(ctx, "exports/a")
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+	assert.Equal(t, "exports/a.zip", blobs[0].Key)
+
+	_, err = store.List(ctx, "../")
+	assert.Equal(t, ErrInvalidKey, err)
+}
@@ -0,0 +1,379 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3DateFormat    = "20060102T150405Z"
+	s3UnsignedBody  = "UNSIGNED-PAYLOAD"
+	s3SignedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	// s3PartSize is the size of the parts of the uploads of unknown size:
+	// the smallest S3 takes for any part but the last.
+	s3PartSize = 5 << 20
+)
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store talks to any S3-compatible object storage (AWS S3, MinIO, ...)
+// using path-style addressing and Signature Version 4.
+type S3Store struct {
+	cfg      S3Config
+	client   *http.Client
+	now      func() time.Time
+	partSize int
+}
+
+func NewS3Store(cfg S3Config, client *http.Client) *S3Store {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &S3Store{cfg: cfg, client: client, now: time.Now, partSize: s3PartSize}
+}
+
+// Put uploads a blob of unknown size in parts, as S3 takes no upload
+// without its length.
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		return s.putMultipart(ctx, key, r, contentType)
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil, r)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// List pages through the keys with ListObjectsV2.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Blob, error) {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+
+	blobs := make([]Blob, 0)
+	for {
+		req, err := s.newBucketRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Contents []struct {
+				Key          string
+				LastModified time.Time
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if err := s.doXML(req, &page); err != nil {
+			return nil, err
+		}
+
+		for _, object := range page.Contents {
+			blobs = append(blobs, Blob{Key: object.Key, Modified: object.LastModified})
+		}
+
+		if !page.IsTruncated {
+			return blobs, nil
+		}
+
+		query.Set("continuation-token", page.NextContinuationToken)
+	}
+}
+
+type s3CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// putMultipart holds a part in memory at a time. A failed upload is
+// aborted, so that S3 does not keep its parts.
+func (s *S3Store) putMultipart(ctx context.Context, key string, r io.Reader, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	var upload struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := s.doXML(req, &upload); err != nil {
+		return err
+	}
+
+	if err := s.uploadParts(ctx, key, upload.UploadID, r); err != nil {
+		s.abortMultipart(context.WithoutCancel(ctx), key, upload.UploadID)
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Store) uploadParts(ctx context.Context, key, uploadID string, r io.Reader) error {
+	var parts []s3CompletedPart
+
+	buf := make([]byte, s.partSize)
+	for number := 1; ; number++ {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr == io.EOF && number > 1 {
+			break
+		} else if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return readErr
+		}
+
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+		req, err := s.newRequest(ctx, http.MethodPut, key, query, bytes.NewReader(buf[:n]))
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		parts = append(parts, s3CompletedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+
+		if readErr != nil {
+			// The part was the last one.
+			break
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// S3 may answer the completion with an error in a 200 OK.
+	var result struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+	if err := s.doXML(req, &result); err != nil {
+		return err
+	}
+
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, result.Code, result.Message)
+	}
+
+	return nil
+}
+
+func (s *S3Store) abortMultipart(ctx context.Context, key, uploadID string) {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil)
+	if err != nil {
+		return
+	}
+
+	if resp, err := s.do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	return s.newBucketRequest(ctx, method, "/"+key, query, body)
+}
+
+// newBucketRequest returns the signed request of the path in the bucket,
+// empty for the bucket itself.
+func (s *S3Store) newBucketRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	endpoint, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Path = "/" + s.cfg.Bucket + path
+	endpoint.RawPath = "/" + uriEncode(s.cfg.Bucket) + uriEncode(path)
+	endpoint.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sign(req)
+
+	return req, nil
+}
+
+// doXML decodes the body of the answer into v.
+func (s *S3Store) doXML(req *http.Request, v interface{}) error {
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("s3 %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	return nil
+}
+
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func (s *S3Store) sign(req *http.Request) {
+	now := s.now().UTC()
+	amzDate := now.Format(s3DateFormat)
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedBody)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + s3UnsignedBody + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		s3SignedHeaders,
+		s3UnsignedBody,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.cfg.Region, s3Service, "aws4_request"}, "/")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(canonicalHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKey, scope, s3SignedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes the query the way Signature Version 4 signs it:
+// sorted by the keys, with the spaces as %20.
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// uriEncode escapes everything except the RFC 3986 unreserved characters
+// and the path separator, as required for canonical S3 request paths.
+func uriEncode(path string) string {
+	var b strings.Builder
+
+	for _, c := range []byte(path) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,225 @@
+package blobstore
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal in-memory stand-in for an S3-compatible server.
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string]string
+	types    map[string]string
+	auth     []string
+	uploads  map[string]map[int]string
+	started  int
+	aborted  []string
+	pageSize int
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		objects:  make(map[string]string),
+		types:    make(map[string]string),
+		uploads:  make(map[string]map[int]string),
+		pageSize: 1000,
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.started++
+		uploadID = fmt.Sprintf("upload-%d", f.started)
+		f.uploads[uploadID] = make(map[int]string)
+		f.types[r.URL.EscapedPath()] = r.Header.Get("Content-Type")
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case r.Method == http.MethodPut && uploadID != "":
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		body, _ := io.ReadAll(r.Body)
+		f.uploads[uploadID][number] = string(body)
+		w.Header().Set("ETag", fmt.Sprintf(`"%s-%d"`, uploadID, number))
+	case r.Method == http.MethodPost && uploadID != "":
+		var complete struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		_ = xml.NewDecoder(r.Body).Decode(&complete)
+
+		var content strings.Builder
+		for _, part := range complete.Parts {
+			content.WriteString(f.uploads[uploadID][part.PartNumber])
+		}
+		f.objects[r.URL.EscapedPath()] = content.String()
+		delete(f.uploads, uploadID)
+		_, _ = io.WriteString(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && uploadID != "":
+		f.aborted = append(f.aborted, uploadID)
+		delete(f.uploads, uploadID)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		f.list(w, query.Get("prefix"), query.Get("continuation-token"))
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.EscapedPath()] = string(body)
+		f.types[r.URL.EscapedPath()] = r.Header.Get("Content-Type")
+	case r.Method == http.MethodGet:
+		body, ok := f.objects[r.URL.EscapedPath()]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, body)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// list answers a page of ListObjectsV2, the continuation token being the
+// last key of the page before.
+func (f *fakeS3) list(w http.ResponseWriter, prefix, after string) {
+	var keys []string
+	for path := range f.objects {
+		key, _ := url.PathUnescape(strings.TrimPrefix(path, "/chat/"))
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	truncated := len(keys) > f.pageSize
+	if truncated {
+		keys = keys[:f.pageSize]
+	}
+
+	fmt.Fprint(w, "<ListBucketResult>")
+	for _, key := range keys {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key><LastModified>2026-10-19T10:00:00.000Z</LastModified></Contents>", key)
+	}
+	if truncated {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[len(keys)-1])
+	}
+	fmt.Fprint(w, "</ListBucketResult>")
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeS3()
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store := NewS3Store(S3Config{Endpoint: srv.URL, Region: "us-east-1", Bucket: "chat", AccessKey: "tester", SecretKey: "secret"}, srv.Client())
+	store.now = func() time.Time { return time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC) }
+
+	err := store.Put(ctx, "2026/my photo.png", strings.NewReader("content"), 7, "image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, "content", fake.objects["/chat/2026/my%20photo.png"])
+	assert.Equal(t, "image/png", fake.types["/chat/2026/my%20photo.png"])
+
+	r, err := store.Get(ctx, "2026/my photo.png")
+	assert.NoError(t, err)
+
+	content, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "content", string(content))
+
+	assert.NoError(t, store.Delete(ctx, "2026/my photo.png"))
+
+	_, err = store.Get(ctx, "2026/my photo.png")
+	assert.Equal(t, ErrNotFound, err)
+
+	for _, auth := range fake.auth {
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=tester/20261019/us-east-1/s3/aws4_request, "+
+			"SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="), auth)
+	}
+}
+
+func TestS3Store_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	store := NewS3Store(S3Config{Endpoint: srv.URL, Bucket: "chat"}, srv.Client())
+
+	err := store.Put(context.Background(), "key", strings.NewReader("x"), 1, "")
+	assert.EqualError(t, err, "s3 PUT /chat/key: 403 Forbidden: AccessDenied")
+}
+
+func TestS3Store_PutUnknownSize(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeS3()
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store := NewS3Store(S3Config{Endpoint: srv.URL, Bucket: "chat"}, srv.Client())
+	store.partSize = 4
+
+	require.NoError(t, store.Put(ctx, "exports/a.zip", strings.NewReader("0123456789"), -1, "application/zip"))
+	assert.Equal(t, "0123456789", fake.objects["/chat/exports/a.zip"])
+	assert.Equal(t, "application/zip", fake.types["/chat/exports/a.zip"])
+	assert.Empty(t, fake.uploads)
+
+	// A blob which fills its parts has no empty part after them.
+	require.NoError(t, store.Put(ctx, "exports/b.zip", strings.NewReader("01234567"), -1, ""))
+	assert.Equal(t, "01234567", fake.objects["/chat/exports/b.zip"])
+
+	// A failed upload is aborted.
+	r := io.MultiReader(strings.NewReader("0123"), iotest.ErrReader(errors.New("archive failed")))
+	err := store.Put(ctx, "exports/c.zip", r, -1, "")
+	assert.EqualError(t, err, "archive failed")
+	assert.Equal(t, []string{"upload-3"}, fake.aborted)
+	assert.Empty(t, fake.uploads)
+	assert.NotContains(t, fake.objects, "/chat/exports/c.zip")
+}
+
+func TestS3Store_List(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeS3()
+	fake.pageSize = 1
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store := NewS3Store(S3Config{Endpoint: srv.URL, Bucket: "chat"}, srv.Client())
+
+	for _, key := range []string{"exports/a.zip", "exports/b c.zip", "2026/photo.png"} {
+		require.NoError(t, store.Put(ctx, key, strings.NewReader("x"), 1, ""))
+	}
+
+	blobs, err := store.List(ctx, "exports/")
+	require.NoError(t, err)
+
+	modified := time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, []Blob{{Key: "exports/a.zip", Modified: modified}, {Key: "exports/b c.zip", Modified: modified}}, blobs)
+}
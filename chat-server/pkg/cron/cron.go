@@ -0,0 +1,233 @@
+// Package cron parses the standard five-field cron expressions and finds
+// the times they fire at.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch bounds the search for the next time, so an expression which
+// never fires, like "0 0 30 2 *", does not loop forever.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+var ErrInvalidExpression = errors.New("invalid cron expression")
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Both 0 and 7 are Sunday.
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed expression. Every field is a bit set of the values
+// it matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// Like in Vixie cron, when both days are restricted a day matches if
+	// either of them does.
+	domStar, dowStar bool
+}
+
+// Parse reads an expression of five fields: minute, hour, day of month,
+// month and day of week. The fields take *, values, ranges, steps and
+// lists of them; months and days of week also take their English names.
+// The @yearly, @monthly, @weekly, @daily and @hourly macros are accepted
+// too.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(strings.ToLower(expr))
+	if val, ok := macros[expr]; ok {
+		expr = val
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidExpression, len(fields))
+	}
+
+	var (
+		s   Schedule
+		err error
+	)
+
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return Schedule{}, err
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return Schedule{}, err
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return Schedule{}, err
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return Schedule{}, err
+	}
+
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return s, nil
+}
+
+// Next returns the first time after t the schedule fires at, in the
+// location of t. Daylight saving changes are handled on the wall clock: a
+// time skipped when the clocks go forward does not fire that day, and a
+// time repeated when they go back fires once. The zero time is returned if
+// the schedule never fires.
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(maxSearch)
+	after := wallClock(t)
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0 || !wallClock(t).After(after):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// wallClock returns the time shown by the clock on the wall, without the
+// offset of the zone.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(expr, ",") {
+		bitsOfPart, err := parsePart(part, f)
+		if err != nil {
+			return 0, err
+		}
+
+		set |= bitsOfPart
+	}
+
+	return set, nil
+}
+
+// parsePart reads one element of a list: *, a value or a range, with an
+// optional step.
+func parsePart(part string, f field) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepExpr)
+		if err != nil || step < 1 {
+			return 0, fmt.Errorf("%w: bad step %q in %s", ErrInvalidExpression, stepExpr, f.name)
+		}
+	}
+
+	var lo, hi int
+	switch {
+	case rangeExpr == "*":
+		lo, hi = f.min, f.max
+		if f.name == dowField.name {
+			hi = 6
+		}
+	case strings.Contains(rangeExpr, "-"):
+		from, to, _ := strings.Cut(rangeExpr, "-")
+
+		var err error
+		if lo, err = parseValue(from, f); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(to, f); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("%w: empty range %q in %s", ErrInvalidExpression, rangeExpr, f.name)
+		}
+	default:
+		var err error
+		if lo, err = parseValue(rangeExpr, f); err != nil {
+			return 0, err
+		}
+
+		hi = lo
+		// "5/15" means from 5 to the end, every 15.
+		if hasStep {
+			hi = f.max
+		}
+	}
+
+	var set uint64
+	for val := lo; val <= hi; val += step {
+		set |= 1 << uint(val)
+	}
+
+	return set, nil
+}
+
+func parseValue(expr string, f field) (int, error) {
+	if val, ok := f.names[expr]; ok {
+		return val, nil
+	}
+
+	val, err := strconv.Atoi(expr)
+	if err != nil || val < f.min || val > f.max {
+		return 0, fmt.Errorf("%w: %q is not a valid %s", ErrInvalidExpression, expr, f.name)
+	}
+
+	return val, nil
+}
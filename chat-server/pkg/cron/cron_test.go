@@ -0,0 +1,113 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testTable := []struct {
+		name        string
+		expr        string
+		expectError bool
+	}{
+		{name: "every_minute", expr: "* * * * *"},
+		{name: "weekdays", expr: "55 9 * * mon-fri"},
+		{name: "lists_and_steps", expr: "0,30 */2 1-15/7 jan,jul 7"},
+		{name: "macro", expr: "@Daily"},
+		{name: "too_few_fields", expr: "0 9 * *", expectError: true},
+		{name: "out_of_range", expr: "60 9 * * *", expectError: true},
+		{name: "bad_name", expr: "0 9 * * funday", expectError: true},
+		{name: "empty_range", expr: "0 9 * * fri-mon", expectError: true},
+		{name: "zero_step", expr: "*/0 * * * *", expectError: true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Parse(testCase.expr)
+			if testCase.expectError {
+				assert.True(t, errors.Is(err, ErrInvalidExpression), err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	minsk := time.FixedZone("MSK", 3*60*60)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+
+	testTable := []struct {
+		name     string
+		expr     string
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "same_day",
+			expr:     "55 9 * * mon-fri",
+			after:    time.Date(2026, time.October, 19, 8, 0, 0, 0, minsk),
+			expected: time.Date(2026, time.October, 19, 9, 55, 0, 0, minsk),
+		},
+		{
+			name:     "skips_weekend",
+			expr:     "55 9 * * mon-fri",
+			after:    time.Date(2026, time.October, 23, 9, 55, 0, 0, minsk),
+			expected: time.Date(2026, time.October, 26, 9, 55, 0, 0, minsk),
+		},
+		{
+			name:     "day_of_month_or_week",
+			expr:     "0 12 1 * sun",
+			after:    time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, time.October, 25, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "next_year",
+			expr:     "@yearly",
+			after:    time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "leap_day",
+			expr:     "0 0 29 2 *",
+			after:    time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "never",
+			expr:  "0 0 30 2 *",
+			after: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "skipped_by_dst",
+			expr:     "30 2 * * *",
+			after:    time.Date(2026, time.March, 29, 0, 0, 0, 0, berlin),
+			expected: time.Date(2026, time.March, 30, 2, 30, 0, 0, berlin),
+		},
+		{
+			// 02:30 CEST, the first of the two 02:30 that day.
+			name:     "repeated_by_dst",
+			expr:     "30 2 * * *",
+			after:    time.Date(2026, time.October, 25, 0, 30, 0, 0, time.UTC).In(berlin),
+			expected: time.Date(2026, time.October, 26, 2, 30, 0, 0, berlin),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := Parse(testCase.expr)
+			assert.NoError(t, err)
+
+			next := s.Next(testCase.after)
+			assert.True(t, testCase.expected.Equal(next), "expected %s, got %s", testCase.expected, next)
+		})
+	}
+}
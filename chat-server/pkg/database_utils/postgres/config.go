@@ -1,5 +1,7 @@
 package postgres
 
+import "time"
+
 type SqlPostgresConfig struct {
 	Host     string
 	Port     string
@@ -7,4 +9,15 @@ type SqlPostgresConfig struct {
 	DBName   string
 	Password string
 	SSLMode  string
+	// The limits of the pool; zero keeps the default of database/sql.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout makes the database cancel the longer statements.
+	StatementTimeout time.Duration
+	// ConnectAttempts and ConnectBackoff make the server wait for a database
+	// which starts after it.
+	ConnectAttempts int
+	ConnectBackoff  time.Duration
 }
@@ -0,0 +1,5 @@
+package sqlite
+
+type SqliteConfig struct {
+	Path string
+}
@@ -10,6 +10,7 @@ import (
 
 type Server struct {
 	httpServer *http.Server
+	onShutdown []func(ctx context.Context) error
 }
 
 func NewServer(cfg HttpServerConfig, h http.Handler) *Server {
@@ -22,6 +23,12 @@ func NewServer(cfg HttpServerConfig, h http.Handler) *Server {
 	}}
 }
 
+// RegisterOnShutdown adds a function Shutdown calls once the server stopped
+// taking requests, so background work stops within the same deadline.
+func (s *Server) RegisterOnShutdown(f func(ctx context.Context) error) {
+	s.onShutdown = append(s.onShutdown, f)
+}
+
 func (s *Server) Run() error {
 	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("listening and serving: %w", err)
@@ -31,5 +38,13 @@ func (s *Server) Run() error {
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+
+	for _, f := range s.onShutdown {
+		if hookErr := f(ctx); hookErr != nil && err == nil {
+			err = hookErr
+		}
+	}
+
+	return err
 }
@@ -0,0 +1,80 @@
+package imaging
+
+import "encoding/binary"
+
+const (
+	jpegSOI         = 0xd8
+	jpegSOS         = 0xda
+	jpegAPP1        = 0xe1
+	orientationTag  = 0x0112
+	exifEntrySize   = 12
+	exifHeaderBytes = "Exif\x00\x00"
+)
+
+// exifOrientation returns the orientation tag of the JPEG EXIF metadata, or
+// 0 when there is none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != jpegSOI {
+		return 0
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return 0
+		}
+
+		marker := data[i+1]
+		if marker == jpegSOS {
+			return 0
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 0
+		}
+
+		segment := data[i+4 : i+2+length]
+		if marker == jpegAPP1 && len(segment) > len(exifHeaderBytes) && string(segment[:len(exifHeaderBytes)]) == exifHeaderBytes {
+			return tiffOrientation(segment[len(exifHeaderBytes):])
+		}
+
+		i += 2 + length
+	}
+
+	return 0
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+
+	entries := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < entries; n++ {
+		entry := offset + 2 + n*exifEntrySize
+		if entry+exifEntrySize > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:]) == orientationTag {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 0
+}
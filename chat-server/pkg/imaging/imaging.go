@@ -0,0 +1,147 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const jpegQuality = 85
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions are too large")
+)
+
+var supportedFormats = map[string]bool{"jpeg": true, "png": true, "gif": true, "webp": true}
+
+// Decode decodes a JPEG, PNG, GIF or WebP image whose sides do not exceed
+// maxSide pixels. JPEG images are rotated according to their EXIF
+// orientation. The returned image carries no metadata of the source file,
+// animated GIFs are reduced to their first frame.
+func Decode(data []byte, maxSide int) (image.Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !supportedFormats[format] {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if cfg.Width > maxSide || cfg.Height > maxSide {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+
+	return img, nil
+}
+
+// Thumbnail crops the centre square of the image and scales it to size x size.
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, image.Rect(x, y, x+side, y+side), draw.Src, nil)
+
+	return dst
+}
+
+// ContentType picks JPEG for fully opaque images and PNG for the ones which
+// need transparency.
+func ContentType(img image.Image) string {
+	if opaque(img) {
+		return "image/jpeg"
+	}
+
+	return "image/png"
+}
+
+// Encode writes the image in the format of the given content type.
+func Encode(w io.Writer, img image.Image, contentType string) error {
+	switch contentType {
+	case "image/jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		return png.Encode(w, img)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// orient applies one of the eight EXIF orientations so that the image is
+// displayed upright once the metadata is gone.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if orientation >= 5 {
+		w, h = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = w-1-y, x
+			case 7:
+				dx, dy = w-1-y, h-1-x
+			case 8:
+				dx, dy = y, h-1-x
+			}
+
+			dst.Set(dx, dy, color.RGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)))
+		}
+	}
+
+	return dst
+}
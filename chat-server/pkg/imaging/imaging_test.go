@@ -0,0 +1,101 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func halves(w, h int, left, right color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, left)
+			} else {
+				img.Set(x, y, right)
+			}
+		}
+	}
+
+	return img
+}
+
+// withOrientation inserts an EXIF segment carrying only the orientation tag
+// right after the SOI marker of the JPEG.
+func withOrientation(t *testing.T, data []byte, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a")
+	for _, val := range []interface{}{uint32(8), uint16(1), uint16(orientationTag), uint16(3), uint32(1), orientation, uint16(0), uint32(0)} {
+		assert.NoError(t, binary.Write(&tiff, binary.BigEndian, val))
+	}
+
+	segment := append([]byte(exifHeaderBytes), tiff.Bytes()...)
+
+	var out bytes.Buffer
+	out.Write(data[:2])
+	out.Write([]byte{0xff, jpegAPP1})
+	assert.NoError(t, binary.Write(&out, binary.BigEndian, uint16(len(segment)+2)))
+	out.Write(segment)
+	out.Write(data[2:])
+
+	return out.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	var pngData bytes.Buffer
+	assert.NoError(t, png.Encode(&pngData, halves(40, 20, color.White, color.Black)))
+
+	img, err := Decode(pngData.Bytes(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+
+	_, err = Decode(pngData.Bytes(), 30)
+	assert.Equal(t, ErrTooLarge, err)
+
+	_, err = Decode([]byte("not an image"), 100)
+	assert.Equal(t, ErrUnsupportedFormat, err)
+
+	_, err = Decode([]byte("%PDF-1.4"), 100)
+	assert.Equal(t, ErrUnsupportedFormat, err)
+}
+
+func TestDecode_Orientation(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	var jpegData bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&jpegData, halves(32, 16, red, blue), &jpeg.Options{Quality: 100}))
+
+	data := withOrientation(t, jpegData.Bytes(), 6)
+	assert.Equal(t, 6, exifOrientation(data))
+
+	img, err := Decode(data, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 32), img.Bounds())
+
+	r, _, b, _ := img.At(8, 4).RGBA()
+	assert.True(t, r > b, "top should be red")
+
+	r, _, b, _ = img.At(8, 27).RGBA()
+	assert.True(t, b > r, "bottom should be blue")
+
+	var out bytes.Buffer
+	assert.NoError(t, Encode(&out, img, ContentType(img)))
+	assert.False(t, bytes.Contains(out.Bytes(), []byte(exifHeaderBytes)), "metadata must be stripped")
+}
+
+func TestThumbnail(t *testing.T) {
+	thumb := Thumbnail(halves(300, 100, color.White, color.Black), 64)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), thumb.Bounds())
+	assert.Equal(t, "image/jpeg", ContentType(thumb))
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	assert.Equal(t, "image/png", ContentType(Thumbnail(transparent, 8)))
+}
@@ -0,0 +1,49 @@
+package jsonl
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID      int    `json:"id"`
+	Content string `json:"content"`
+}
+
+func TestWriteGzipFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	path := filepath.Join(dir, "public.jsonl.gz")
+
+	records := []record{{ID: 1, Content: "hello"}, {ID: 2, Content: "line\nbreak"}}
+	require.NoError(t, WriteGzipFile(path, records))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	var read []record
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		var r record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		read = append(read, r)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, records, read)
+
+	// Nothing but the file is left in the directory.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "public.jsonl.gz", entries[0].Name())
+}
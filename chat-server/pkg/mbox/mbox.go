@@ -0,0 +1,79 @@
+// Package mbox writes messages in the mboxrd format, which mail clients
+// import: every message starts with a From_ line, and the lines of its body
+// which look like one are quoted with one more >.
+package mbox
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// fromLine matches the lines of a body to be quoted.
+var fromLine = regexp.MustCompile(`^>*From `)
+
+type Message struct {
+	From      mail.Address
+	To        mail.Address
+	Date      time.Time
+	Subject   string
+	MessageID string
+	Body      string
+}
+
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Write appends the message to the mailbox. The body is plain UTF-8 text.
+func (w *Writer) Write(m Message) error {
+	date := m.Date.UTC()
+
+	w.w.WriteString("From " + envelope(m.From.Address) + " " + date.Format(time.ANSIC) + "\n")
+	w.header("From", m.From.String())
+	w.header("To", m.To.String())
+	w.header("Date", date.Format(time.RFC1123Z))
+	w.header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	if m.MessageID != "" {
+		w.header("Message-ID", "<"+m.MessageID+">")
+	}
+	w.header("MIME-Version", "1.0")
+	w.header("Content-Type", "text/plain; charset=utf-8")
+	w.header("Content-Transfer-Encoding", "8bit")
+	w.w.WriteString("\n")
+
+	body := strings.ReplaceAll(m.Body, "\r\n", "\n")
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+		if fromLine.MatchString(line) {
+			w.w.WriteString(">")
+		}
+		w.w.WriteString(line + "\n")
+	}
+
+	// A blank line parts the message from the next one.
+	w.w.WriteString("\n")
+
+	return w.w.Flush()
+}
+
+func (w *Writer) header(name, value string) {
+	w.w.WriteString(name + ": " + strings.NewReplacer("\r", " ", "\n", " ").Replace(value) + "\n")
+}
+
+// envelope makes the address fit for the From_ line, which ends at the
+// first space.
+func envelope(address string) string {
+	if address == "" {
+		return "MAILER-DAEMON"
+	}
+
+	return strings.Join(strings.Fields(address), "_")
+}
@@ -0,0 +1,59 @@
+package mbox
+
+import (
+	"bytes"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	require.NoError(t, w.Write(Message{
+		From:      mail.Address{Name: "tester", Address: "tester@chat"},
+		To:        mail.Address{Name: "valera", Address: "valera@chat"},
+		Date:      time.Date(2024, 3, 5, 14, 2, 26, 0, time.FixedZone("MSK", 3*60*60)),
+		Subject:   "Привет",
+		MessageID: "1.tester.valera@chat",
+		Body:      "hi\r\nFrom now on\n>From the start\n",
+	}))
+	require.NoError(t, w.Write(Message{
+		From: mail.Address{Address: "valera@chat"},
+		To:   mail.Address{Address: "tester@chat"},
+		Date: time.Date(2024, 3, 5, 11, 3, 0, 0, time.UTC),
+		Body: "hello",
+	}))
+
+	expected := "From tester@chat Tue Mar  5 11:02:26 2024\n" +
+		"From: \"tester\" <tester@chat>\n" +
+		"To: \"valera\" <valera@chat>\n" +
+		"Date: Tue, 05 Mar 2024 11:02:26 +0000\n" +
+		"Subject: =?utf-8?q?=D0=9F=D1=80=D0=B8=D0=B2=D0=B5=D1=82?=\n" +
+		"Message-ID: <1.tester.valera@chat>\n" +
+		"MIME-Version: 1.0\n" +
+		"Content-Type: text/plain; charset=utf-8\n" +
+		"Content-Transfer-Encoding: 8bit\n" +
+		"\n" +
+		"hi\n" +
+		">From now on\n" +
+		">>From the start\n" +
+		"\n" +
+		"From valera@chat Tue Mar  5 11:03:00 2024\n" +
+		"From: <valera@chat>\n" +
+		"To: <tester@chat>\n" +
+		"Date: Tue, 05 Mar 2024 11:03:00 +0000\n" +
+		"Subject: \n" +
+		"MIME-Version: 1.0\n" +
+		"Content-Type: text/plain; charset=utf-8\n" +
+		"Content-Transfer-Encoding: 8bit\n" +
+		"\n" +
+		"hello\n" +
+		"\n"
+
+	assert.Equal(t, expected, buf.String())
+}
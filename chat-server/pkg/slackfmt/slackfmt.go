@@ -0,0 +1,122 @@
+// Package slackfmt reads messages posted in Slack's incoming webhook format
+// and turns their markup into plain chat text.
+package slackfmt
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+var ErrInvalidPayload = errors.New("invalid_payload")
+
+// Payload is the part of a Slack message the chat understands. Blocks and
+// icons are ignored; attachments are used only for their fallback text.
+type Payload struct {
+	Text        string       `json:"text"`
+	Username    string       `json:"username"`
+	Channel     string       `json:"channel"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+type Attachment struct {
+	Fallback string `json:"fallback"`
+	Pretext  string `json:"pretext"`
+	Text     string `json:"text"`
+}
+
+// Decode reads the payload the way Slack does: either a JSON body or a form
+// with the JSON in its payload field.
+func Decode(contentType string, body []byte) (Payload, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil || !form.Has("payload") {
+			return Payload{}, ErrInvalidPayload
+		}
+
+		body = []byte(form.Get("payload"))
+	}
+
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return Payload{}, ErrInvalidPayload
+	}
+
+	return p, nil
+}
+
+// Content returns the text of the message in plain form. Messages sent with
+// attachments only fall back to the attachments' text.
+func (p Payload) Content() string {
+	parts := []string{p.Text}
+	if strings.TrimSpace(p.Text) == "" {
+		parts = parts[:0]
+		for _, val := range p.Attachments {
+			switch {
+			case val.Fallback != "":
+				parts = append(parts, val.Fallback)
+			case val.Pretext != "" || val.Text != "":
+				parts = append(parts, strings.TrimSpace(val.Pretext+"\n"+val.Text))
+			}
+		}
+	}
+
+	return strings.TrimSpace(Plain(strings.Join(parts, "\n")))
+}
+
+var entities = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// Plain rewrites Slack markup: links become "label (url)" or the bare url,
+// user and special mentions become @name, and the escaped &, < and > are
+// restored.
+func Plain(text string) string {
+	var b strings.Builder
+
+	for {
+		start := strings.IndexByte(text, '<')
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(text[start:], '>')
+		if end < 0 {
+			break
+		}
+
+		b.WriteString(entities.Replace(text[:start]))
+		b.WriteString(entities.Replace(token(text[start+1 : start+end])))
+		text = text[start+end+1:]
+	}
+
+	b.WriteString(entities.Replace(text))
+
+	return b.String()
+}
+
+func token(t string) string {
+	target, label, hasLabel := strings.Cut(t, "|")
+
+	switch {
+	case strings.HasPrefix(target, "@"), strings.HasPrefix(target, "#"):
+		if hasLabel {
+			return target[:1] + strings.TrimPrefix(label, target[:1])
+		}
+
+		return target
+	case strings.HasPrefix(target, "!"):
+		if hasLabel {
+			return label
+		}
+
+		name, _, _ := strings.Cut(target[1:], "^")
+
+		return "@" + name
+	case hasLabel && label != target:
+		return label + " (" + target + ")"
+	default:
+		return target
+	}
+}
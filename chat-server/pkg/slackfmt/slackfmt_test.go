@@ -0,0 +1,96 @@
+package slackfmt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	testTable := []struct {
+		name          string
+		contentType   string
+		body          string
+		expected      Payload
+		expectedError error
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `{"text": "deploy finished", "username": "ci"}`,
+			expected:    Payload{Text: "deploy finished", Username: "ci"},
+		},
+		{
+			name:     "no_content_type",
+			body:     `{"text": "hi"}`,
+			expected: Payload{Text: "hi"},
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			body:        `payload=%7B%22text%22%3A%22a+%26+b%22%7D`,
+			expected:    Payload{Text: "a & b"},
+		},
+		{
+			name:          "form_without_payload",
+			contentType:   "application/x-www-form-urlencoded",
+			body:          `text=hi`,
+			expectedError: ErrInvalidPayload,
+		},
+		{
+			name:          "broken_json",
+			contentType:   "application/json",
+			body:          `{"text": `,
+			expectedError: ErrInvalidPayload,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			p, err := Decode(testCase.contentType, []byte(testCase.body))
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expected, p)
+		})
+	}
+}
+
+func TestPlain(t *testing.T) {
+	testTable := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "escapes",
+			text:     "1 &lt; 2 &amp;&amp; 3 &gt; 2",
+			expected: "1 < 2 && 3 > 2",
+		},
+		{
+			name:     "links",
+			text:     "see <https://example.com/build/7|build 7> or <https://example.com>",
+			expected: "see build 7 (https://example.com/build/7) or https://example.com",
+		},
+		{
+			name:     "mentions",
+			text:     "<@valera> <#general|general> <!here> <!subteam^S1|@devs>",
+			expected: "@valera #general @here @devs",
+		},
+		{
+			name:     "unclosed",
+			text:     "a <b &amp; c",
+			expected: "a <b & c",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, Plain(testCase.text))
+		})
+	}
+}
+
+func TestPayload_Content(t *testing.T) {
+	assert.Equal(t, "hi @valera", Payload{Text: " hi <@valera> "}.Content())
+	assert.Equal(t, "build failed\nretry?", Payload{Attachments: []Attachment{{Fallback: "build failed"}, {Pretext: "retry?"}}}.Content())
+	assert.Equal(t, "", Payload{Text: "  "}.Content())
+}
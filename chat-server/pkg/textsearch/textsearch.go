@@ -0,0 +1,215 @@
+package textsearch
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxTerms      = 16
+	highlightOpen = "<mark>"
+	highlightEnd  = "</mark>"
+	ellipsis      = "…"
+)
+
+var ErrEmptyQuery = errors.New("search query has no words")
+
+type Token struct {
+	Word  string
+	Start int
+	End   int
+}
+
+// Term is a single word, or a phrase of consecutive words when it has more
+// than one. With Prefix set the last word matches any word it starts.
+type Term struct {
+	Words  []string
+	Prefix bool
+}
+
+// Tokenize splits the text into lower-cased words made of letters and
+// digits, keeping the byte offsets of every word in the original text.
+func Tokenize(text string) []Token {
+	var tokens []Token
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, Token{Word: strings.ToLower(text[start:i]), Start: start, End: i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, Token{Word: strings.ToLower(text[start:]), Start: start, End: len(text)})
+	}
+
+	return tokens
+}
+
+// Parse reads a query of words, "quoted phrases" and prefixes ending with
+// an asterisk. All terms have to match.
+func Parse(query string) ([]Term, error) {
+	var terms []Term
+
+	for i, chunk := range strings.Split(query, `"`) {
+		phrase := i%2 == 1
+
+		for _, field := range strings.Fields(chunk) {
+			if phrase {
+				field = chunk
+			}
+
+			words := Tokenize(field)
+			if len(words) > 0 {
+				term := Term{Prefix: strings.HasSuffix(strings.TrimSpace(field), "*")}
+				for _, w := range words {
+					term.Words = append(term.Words, w.Word)
+				}
+
+				terms = append(terms, term)
+			}
+
+			if phrase {
+				break
+			}
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	if len(terms) > maxTerms {
+		terms = terms[:maxTerms]
+	}
+
+	return terms, nil
+}
+
+// Match reports whether every term occurs in the tokens.
+func Match(tokens []Token, terms []Term) bool {
+	for _, term := range terms {
+		if len(Find(tokens, term)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Find returns the positions of the tokens where the term starts.
+func Find(tokens []Token, term Term) []int {
+	var positions []int
+
+	for i := 0; i+len(term.Words) <= len(tokens); i++ {
+		if term.matchesAt(tokens, i) {
+			positions = append(positions, i)
+		}
+	}
+
+	return positions
+}
+
+// MatchesWord reports whether a single indexed word can satisfy the
+// word at position n of the term.
+func (t Term) MatchesWord(n int, word string) bool {
+	if t.Prefix && n == len(t.Words)-1 {
+		return strings.HasPrefix(word, t.Words[n])
+	}
+
+	return word == t.Words[n]
+}
+
+func (t Term) matchesAt(tokens []Token, i int) bool {
+	for n := range t.Words {
+		if !t.MatchesWord(n, tokens[i+n].Word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Snippet cuts about width runes of the text around the first match and
+// wraps every matched word in <mark> tags. The rest of the text is HTML
+// escaped, so the snippet can be rendered as is.
+func Snippet(text string, terms []Term, width int) string {
+	tokens := Tokenize(text)
+
+	marked := make([]bool, len(tokens))
+	first := -1
+	for _, term := range terms {
+		for _, pos := range Find(tokens, term) {
+			for n := range term.Words {
+				marked[pos+n] = true
+			}
+
+			if first < 0 || pos < first {
+				first = pos
+			}
+		}
+	}
+
+	from, to := 0, len(text)
+	if utf8.RuneCountInString(text) > width {
+		center := 0
+		if first >= 0 {
+			center = tokens[first].Start
+		}
+
+		from = moveRunes(text, center, -width/4)
+		to = moveRunes(text, from, width)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString(ellipsis)
+	}
+
+	pos := from
+	for i, token := range tokens {
+		if !marked[i] || token.Start < from || token.End > to {
+			continue
+		}
+
+		b.WriteString(html.EscapeString(text[pos:token.Start]))
+		b.WriteString(highlightOpen)
+		b.WriteString(html.EscapeString(text[token.Start:token.End]))
+		b.WriteString(highlightEnd)
+		pos = token.End
+	}
+
+	b.WriteString(html.EscapeString(text[pos:to]))
+	if to < len(text) {
+		b.WriteString(ellipsis)
+	}
+
+	return b.String()
+}
+
+// moveRunes moves the byte offset by n runes, forward or backward, without
+// leaving the text.
+func moveRunes(text string, offset, n int) int {
+	for ; n < 0 && offset > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:offset])
+		offset -= size
+	}
+
+	for ; n > 0 && offset < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+
+	return offset
+}
@@ -0,0 +1,71 @@
+package textsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("Привет, World-42!")
+
+	assert.Equal(t, []Token{
+		{Word: "привет", Start: 0, End: 12},
+		{Word: "world", Start: 14, End: 19},
+		{Word: "42", Start: 20, End: 22},
+	}, tokens)
+}
+
+func TestParse(t *testing.T) {
+	testTable := []struct {
+		name          string
+		query         string
+		expectedTerms []Term
+		expectedError error
+	}{
+		{
+			name:          "words",
+			query:         "Hello  world",
+			expectedTerms: []Term{{Words: []string{"hello"}}, {Words: []string{"world"}}},
+		},
+		{
+			name:          "phrase_and_prefix",
+			query:         `"release notes" depl*`,
+			expectedTerms: []Term{{Words: []string{"release", "notes"}}, {Words: []string{"depl"}, Prefix: true}},
+		},
+		{
+			name:          "prefix_phrase",
+			query:         `"release no*"`,
+			expectedTerms: []Term{{Words: []string{"release", "no"}, Prefix: true}},
+		},
+		{
+			name:          "empty",
+			query:         ` "" ** `,
+			expectedError: ErrEmptyQuery,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			terms, err := Parse(testCase.query)
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedTerms, terms)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tokens := Tokenize("the release notes are ready")
+
+	assert.True(t, Match(tokens, []Term{{Words: []string{"release", "notes"}}}))
+	assert.True(t, Match(tokens, []Term{{Words: []string{"rea"}, Prefix: true}, {Words: []string{"the"}}}))
+	assert.False(t, Match(tokens, []Term{{Words: []string{"notes", "release"}}}))
+	assert.False(t, Match(tokens, []Term{{Words: []string{"rea"}}}))
+}
+
+func TestSnippet(t *testing.T) {
+	terms := []Term{{Words: []string{"release"}}}
+
+	assert.Equal(t, "the <mark>Release</mark> &lt;b&gt;", Snippet("the Release <b>", terms, 100))
+	assert.Equal(t, "…ipsum <mark>release</mark> dolor sit am…", Snippet("sit amet lorem ipsum release dolor sit amet", terms, 26))
+}
@@ -0,0 +1,62 @@
+// Package webhook signs outgoing webhook requests so that receivers can
+// check they come from the chat server and were not replayed.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Chat-Signature"
+	TimestampHeader = "X-Chat-Timestamp"
+	EventHeader     = "X-Chat-Event"
+	DeliveryHeader  = "X-Chat-Delivery"
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrExpiredTimestamp = errors.New("webhook timestamp is too old")
+)
+
+// Sign returns the value of the signature header: an HMAC-SHA256 of the
+// timestamp and the body joined with a dot, keyed with the secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a received request.
+// Requests older than tolerance are rejected to prevent replays.
+func Verify(secret, signature, timestamp string, body []byte, now time.Time, tolerance time.Duration) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	sent := time.Unix(sec, 0)
+	if now.Sub(sent) > tolerance || sent.Sub(now) > tolerance {
+		return ErrExpiredTimestamp
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, sent, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
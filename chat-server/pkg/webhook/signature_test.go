@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1792400000, 0)
+
+	// echo -n '1792400000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=5f06f035431f3a74a58e1efd4ad4cdd8468b0a869e38d010294c43edc22cd660", Sign("secret", ts, []byte(`{"a":1}`)))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1792400000, 0)
+	body := []byte(`{"event":"message.created"}`)
+	signature := Sign("secret", now, body)
+
+	testTable := []struct {
+		name          string
+		secret        string
+		signature     string
+		timestamp     string
+		body          []byte
+		expectedError error
+	}{
+		{
+			name:      "ok",
+			secret:    "secret",
+			signature: signature,
+			timestamp: "1792400000",
+			body:      body,
+		},
+		{
+			name:          "wrong_secret",
+			secret:        "other",
+			signature:     signature,
+			timestamp:     "1792400000",
+			body:          body,
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "tampered_body",
+			secret:        "secret",
+			signature:     signature,
+			timestamp:     "1792400000",
+			body:          []byte(`{"event":"message.deleted"}`),
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "replayed",
+			secret:        "secret",
+			signature:     signature,
+			timestamp:     "1792399000",
+			body:          body,
+			expectedError: ErrExpiredTimestamp,
+		},
+		{
+			name:          "malformed_timestamp",
+			secret:        "secret",
+			signature:     signature,
+			timestamp:     "yesterday",
+			body:          body,
+			expectedError: ErrInvalidSignature,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Verify(testCase.secret, testCase.signature, testCase.timestamp, testCase.body, now, 5*time.Minute)
+			assert.Equal(t, testCase.expectedError, err)
+		})
+	}
+}